@@ -1,8 +1,10 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	_ "github.com/joho/godotenv/autoload"
@@ -13,6 +15,11 @@ type Config struct {
 	PORT    string
 	APP_ENV string
 
+	// the reverse proxies whose X-Forwarded-For and X-Real-IP headers are trusted,
+	// comma separated ip addresses or cidr ranges, none by default
+	TRUSTED_PROXIES      []netip.Prefix
+	trustedProxiesParsed bool
+
 	DB_HOST     string
 	DB_PORT     string
 	DB_DATABASE string
@@ -31,10 +38,20 @@ type Config struct {
 	ENABLE_EMAILS   bool
 
 	OAUTH_STATE_SECRET           string
+	DOWNLOAD_LINK_SECRET         string
 	GOOGLE_OAUTH_CLIENT_ID       string
 	GOOGLE_OAUTH_CLIENT_SECRET   string
 	FACEBOOK_OAUTH_CLIENT_ID     string
 	FACEBOOK_OAUTH_CLIENT_SECRET string
+
+	// local or s3, local by default
+	BLOB_STORE           string
+	BLOB_LOCAL_DIR       string
+	S3_ENDPOINT          string
+	S3_REGION            string
+	S3_BUCKET            string
+	S3_ACCESS_KEY_ID     string
+	S3_SECRET_ACCESS_KEY string
 }
 
 var instance *Config
@@ -44,6 +61,7 @@ func LoadEnvVars() *Config {
 	once.Do(func() {
 		port := os.Getenv("PORT")
 		app_env := os.Getenv("APP_ENV")
+		trusted_proxies, trusted_proxies_parsed := parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
 		db_host := os.Getenv("DB_HOST")
 		db_port := os.Getenv("DB_PORT")
 		db_database := os.Getenv("DB_DATABASE")
@@ -58,14 +76,32 @@ func LoadEnvVars() *Config {
 		resend_api_test := os.Getenv("RESEND_API_TEST")
 		enable_emails, _ := strconv.ParseBool(os.Getenv("ENABLE_EMAILS"))
 		oauth_state_secret := os.Getenv("OAUTH_STATE_SECRET")
+		download_link_secret := os.Getenv("DOWNLOAD_LINK_SECRET")
 		google_oauth_client_id := os.Getenv("GOOGLE_OAUTH_CLIENT_ID")
 		google_oauth_client_secret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
 		facebook_oauth_client_id := os.Getenv("FACEBOOK_OAUTH_CLIENT_ID")
 		facebook_oauth_client_secret := os.Getenv("FACEBOOK_OAUTH_CLIENT_SECRET")
+		blob_store := os.Getenv("BLOB_STORE")
+		blob_local_dir := os.Getenv("BLOB_LOCAL_DIR")
+		s3_endpoint := os.Getenv("S3_ENDPOINT")
+		s3_region := os.Getenv("S3_REGION")
+		s3_bucket := os.Getenv("S3_BUCKET")
+		s3_access_key_id := os.Getenv("S3_ACCESS_KEY_ID")
+		s3_secret_access_key := os.Getenv("S3_SECRET_ACCESS_KEY")
+
+		if blob_store == "" {
+			blob_store = "local"
+		}
+
+		if blob_local_dir == "" {
+			blob_local_dir = "storage"
+		}
 
 		instance = &Config{
 			PORT:                         port,
 			APP_ENV:                      app_env,
+			TRUSTED_PROXIES:              trusted_proxies,
+			trustedProxiesParsed:         trusted_proxies_parsed,
 			DB_HOST:                      db_host,
 			DB_PORT:                      db_port,
 			DB_DATABASE:                  db_database,
@@ -80,10 +116,18 @@ func LoadEnvVars() *Config {
 			RESEND_API_TEST:              resend_api_test,
 			ENABLE_EMAILS:                enable_emails,
 			OAUTH_STATE_SECRET:           oauth_state_secret,
+			DOWNLOAD_LINK_SECRET:         download_link_secret,
 			GOOGLE_OAUTH_CLIENT_ID:       google_oauth_client_id,
 			GOOGLE_OAUTH_CLIENT_SECRET:   google_oauth_client_secret,
 			FACEBOOK_OAUTH_CLIENT_ID:     facebook_oauth_client_id,
 			FACEBOOK_OAUTH_CLIENT_SECRET: facebook_oauth_client_secret,
+			BLOB_STORE:                   blob_store,
+			BLOB_LOCAL_DIR:               blob_local_dir,
+			S3_ENDPOINT:                  s3_endpoint,
+			S3_REGION:                    s3_region,
+			S3_BUCKET:                    s3_bucket,
+			S3_ACCESS_KEY_ID:             s3_access_key_id,
+			S3_SECRET_ACCESS_KEY:         s3_secret_access_key,
 		}
 	})
 	return instance
@@ -92,6 +136,7 @@ func LoadEnvVars() *Config {
 func (c *Config) Validate() {
 	assert.True(c.PORT != "", "PORT environment variable could not be found")
 	assert.True(c.APP_ENV != "", "APP_ENV environment variable could not be found")
+	assert.True(c.trustedProxiesParsed, "TRUSTED_PROXIES environment variable should be a comma separated list of ip addresses or cidr ranges")
 	assert.True(c.DB_HOST != "", "DB_HOST environment variable could not be found")
 	assert.True(c.DB_PORT != "", "DB_PORT environment variable could not be found")
 	assert.True(c.DB_DATABASE != "", "DB_DATABASE environment variable could not be found")
@@ -110,4 +155,40 @@ func (c *Config) Validate() {
 	assert.True(c.GOOGLE_OAUTH_CLIENT_SECRET != "", "GOOGLE_OAUTH_CLIENT_SECRET environment variable could not be found")
 	assert.True(c.FACEBOOK_OAUTH_CLIENT_ID != "", "FACEBOOK_OAUTH_CLIENT_ID environment variable could not be found")
 	assert.True(c.FACEBOOK_OAUTH_CLIENT_SECRET != "", "FACEBOOK_OAUTH_CLIENT_SECRET environment variable could not be found")
+	assert.True(c.DOWNLOAD_LINK_SECRET != "", "DOWNLOAD_LINK_SECRET environment variable could not be found")
+	assert.True(c.BLOB_STORE == "local" || c.BLOB_STORE == "s3", "BLOB_STORE environment variable should be local or s3")
+
+	if c.BLOB_STORE == "s3" {
+		assert.True(c.S3_ENDPOINT != "", "S3_ENDPOINT environment variable could not be found")
+		assert.True(c.S3_REGION != "", "S3_REGION environment variable could not be found")
+		assert.True(c.S3_BUCKET != "", "S3_BUCKET environment variable could not be found")
+		assert.True(c.S3_ACCESS_KEY_ID != "", "S3_ACCESS_KEY_ID environment variable could not be found")
+		assert.True(c.S3_SECRET_ACCESS_KEY != "", "S3_SECRET_ACCESS_KEY environment variable could not be found")
+	}
+}
+
+// Parses a comma separated list of ip addresses and cidr ranges, single addresses become a prefix of their full length
+func parseTrustedProxies(value string) ([]netip.Prefix, bool) {
+	var prefixes []netip.Prefix
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, false
+		}
+
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, true
 }
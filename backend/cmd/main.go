@@ -23,6 +23,12 @@ func main() {
 	cfg := config.LoadEnvVars()
 	cfg.Validate()
 
+	// the asserts are left out of production builds, but export download links must never be signed with an empty secret
+	if cfg.DOWNLOAD_LINK_SECRET == "" {
+		slog.Error("DOWNLOAD_LINK_SECRET environment variable could not be found")
+		os.Exit(1)
+	}
+
 	ctx := context.Background()
 
 	application := app.New(ctx, cfg)
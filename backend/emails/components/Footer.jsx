@@ -3,7 +3,9 @@ import { Link, Section, Text } from "react-email";
 
 void React;
 
-export default function Footer() {
+export default function Footer({
+  unsubscribeLink = "http://reservations.local:3000/unsubscribe",
+}) {
   return (
     <Section className="px-5 pt-5 text-gray-500">
       <Text className="m-0 text-center text-[12px]">
@@ -27,10 +29,7 @@ export default function Footer() {
           <u>{"{{ T .Lang `Footer.terms_of_service` }}"}</u>
         </Link>
         {" • "}
-        <Link
-          href="http://reservations.local:3000/unsubscribe"
-          className="text-gray-500"
-        >
+        <Link href={unsubscribeLink} className="text-gray-500">
           <u>{"{{ T .Lang `Footer.unsubscribe` }}"}</u>
         </Link>
       </Text>
@@ -0,0 +1,51 @@
+import React from "react";
+import {
+  Body,
+  Container,
+  Head,
+  Hr,
+  Html,
+  Preview,
+  Tailwind,
+  Text,
+} from "react-email";
+import Footer from "../components/Footer";
+import LogoHeader from "../components/LogoHeader";
+
+void React;
+
+export default function Campaign() {
+  return (
+    <Tailwind>
+      <Html lang="hu" dir="ltr">
+        <Head />
+        <Preview>{"{{ T .Lang `Campaign.preview` . }}"}</Preview>
+        <Body className="bg-gray-100 font-sans text-black">
+          <Container
+            className="mx-auto max-w-md bg-white p-4"
+            style={{ borderRadius: "6px" }}
+          >
+            <LogoHeader />
+            <Text className="mb-4 text-sm text-black">
+              {
+                "{{ if .FirstName }}{{ T .Lang `Campaign.greeting` . }}{{ else }}{{ T .Lang `Campaign.greeting_no_name` . }}{{ end }}"
+              }
+            </Text>
+
+            {"{{ range .Paragraphs }}"}
+            <Text className="mb-4 text-sm text-black">{"{{ . }}"}</Text>
+            {"{{ end }}"}
+
+            <Text className="mb-6 text-xs text-gray-600">
+              {"{{ T .Lang `Campaign.unsubscribe_note` . }}"}
+            </Text>
+
+            <Hr className="mt-4" style={{ border: "1px solid #e5e7eb" }} />
+
+            <Footer unsubscribeLink="{{ .UnsubscribeLink }}" />
+          </Container>
+        </Body>
+      </Html>
+    </Tailwind>
+  );
+}
@@ -0,0 +1,116 @@
+import React from "react";
+import {
+  Body,
+  Button,
+  Column,
+  Container,
+  Head,
+  Heading,
+  Hr,
+  Html,
+  Preview,
+  Row,
+  Section,
+  Tailwind,
+  Text,
+} from "react-email";
+import Footer from "../components/Footer";
+import LogoHeader from "../components/LogoHeader";
+
+void React;
+
+export default function GiftCard() {
+  return (
+    <Tailwind>
+      <Html lang="hu" dir="ltr">
+        <Head />
+        <Preview>{"{{ T .Lang `GiftCard.preview` . }}"}</Preview>
+        <Body className="bg-gray-100 font-sans text-black">
+          <Container
+            className="mx-auto max-w-md bg-white p-4"
+            style={{ borderRadius: "6px" }}
+          >
+            <LogoHeader />
+            <Heading
+              as="h1"
+              className="mb-4 text-[22px] font-bold text-[#111111]"
+            >
+              {"{{ T .Lang `GiftCard.heading` . }}"}
+            </Heading>
+            <Text className="mb-6 text-sm text-black">
+              {
+                "{{ if .SenderName }}{{ T .Lang `GiftCard.main_text_from` . }}{{ else }}{{ T .Lang `GiftCard.main_text` . }}{{ end }}"
+              }
+            </Text>
+
+            {"{{ if .Message }}"}
+            <Section
+              className="mb-6 bg-gray-50 p-[16px]"
+              style={{
+                borderRadius: "6px",
+              }}
+            >
+              <Text className="m-0 text-sm italic">{"{{ .Message }}"}</Text>
+            </Section>
+            {"{{ end }}"}
+
+            <Section
+              className="mb-6 bg-gray-50 px-4 text-black"
+              style={{
+                borderLeft: "solid 2px #000000",
+                borderRadius: "6px",
+              }}
+            >
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `GiftCard.code` . }}"}
+                </Column>
+                <Column className="text-right font-mono text-sm">
+                  {"{{ .Code }}"}
+                </Column>
+              </Row>
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `GiftCard.value` . }}"}
+                </Column>
+                <Column className="text-right text-sm">{"{{ .Value }}"}</Column>
+              </Row>
+              {"{{ if .ExpiresAt }}"}
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `GiftCard.expires_at` . }}"}
+                </Column>
+                <Column className="text-right text-sm">
+                  {"{{ .ExpiresAt }}"}
+                </Column>
+              </Row>
+              {"{{ end }}"}
+            </Section>
+
+            <Text className="mb-6 text-sm text-black">
+              {"{{ T .Lang `GiftCard.redeem_note` . }}"}
+            </Text>
+
+            <Section className="my-8 text-center">
+              <Button
+                href="{{ .BookingLink }}"
+                className="bg-blue-600 px-4 py-3 text-center text-[14px]
+                  font-medium text-white"
+                style={{
+                  boxSizing: "border-box",
+                  borderRadius: "6px",
+                }}
+              >
+                {"{{ T .Lang `GiftCard.primary_button` . }}"}
+              </Button>
+            </Section>
+
+            <Hr className="mt-4" style={{ border: "1px solid #e5e7eb" }} />
+
+            <Footer />
+          </Container>
+        </Body>
+      </Html>
+    </Tailwind>
+  );
+}
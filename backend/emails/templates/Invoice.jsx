@@ -0,0 +1,84 @@
+import React from "react";
+import {
+  Body,
+  Column,
+  Container,
+  Head,
+  Heading,
+  Hr,
+  Html,
+  Preview,
+  Row,
+  Section,
+  Tailwind,
+  Text,
+} from "react-email";
+import Footer from "../components/Footer";
+import LogoHeader from "../components/LogoHeader";
+
+void React;
+
+export default function Invoice() {
+  return (
+    <Tailwind>
+      <Html lang="hu" dir="ltr">
+        <Head />
+        <Preview>{"{{ T .Lang `Invoice.preview` . }}"}</Preview>
+        <Body className="bg-gray-100 font-sans text-black">
+          <Container
+            className="mx-auto max-w-md bg-white p-4"
+            style={{ borderRadius: "6px" }}
+          >
+            <LogoHeader />
+            <Heading
+              as="h1"
+              className="mb-4 text-[22px] font-bold text-[#111111]"
+            >
+              {"{{ T .Lang `Invoice.heading` . }}"}
+            </Heading>
+            <Text className="mb-6 text-sm text-black">
+              {"{{ T .Lang `Invoice.main_text` . }}"}
+            </Text>
+
+            <Section
+              className="mb-6 bg-gray-50 px-4 text-black"
+              style={{
+                borderLeft: "solid 2px #000000",
+                borderRadius: "6px",
+              }}
+            >
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `Invoice.invoice_number` . }}"}
+                </Column>
+                <Column className="text-right text-sm">
+                  {"{{ .InvoiceNumber }}"}
+                </Column>
+              </Row>
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `Invoice.date` . }}"}
+                </Column>
+                <Column className="text-right text-sm">{"{{ .Date }}"}</Column>
+              </Row>
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T .Lang `Invoice.total` . }}"}
+                </Column>
+                <Column className="text-right text-sm">{"{{ .Total }}"}</Column>
+              </Row>
+            </Section>
+
+            <Text className="mb-6 text-xs text-gray-600">
+              {"{{ T .Lang `Invoice.attachment_note` . }}"}
+            </Text>
+
+            <Hr className="mt-4" style={{ border: "1px solid #e5e7eb" }} />
+
+            <Footer />
+          </Container>
+        </Body>
+      </Html>
+    </Tailwind>
+  );
+}
@@ -0,0 +1,90 @@
+import React from "react";
+import {
+  Body,
+  Button,
+  Column,
+  Container,
+  Head,
+  Heading,
+  Hr,
+  Html,
+  Preview,
+  Row,
+  Section,
+  Tailwind,
+  Text,
+} from "react-email";
+import Footer from "../components/Footer";
+import LogoHeader from "../components/LogoHeader";
+
+void React;
+
+export default function ReportSummary() {
+  return (
+    <Tailwind>
+      <Html lang="hu" dir="ltr">
+        <Head />
+        <Preview>{"{{ T .Lang `ReportSummary.preview` . }}"}</Preview>
+        <Body className="bg-gray-100 font-sans text-black">
+          <Container
+            className="mx-auto max-w-md bg-white p-4"
+            style={{ borderRadius: "6px" }}
+          >
+            <LogoHeader />
+            <Heading
+              as="h1"
+              className="mb-4 text-[22px] font-bold text-[#111111]"
+            >
+              {"{{ T .Lang `ReportSummary.heading` . }}"}
+            </Heading>
+            <Text className="mb-6 text-sm text-black">
+              {"{{ T .Lang `ReportSummary.main_text` . }}"}
+            </Text>
+
+            <Section
+              className="mb-6 bg-gray-50 px-4 text-black"
+              style={{
+                borderLeft: "solid 2px #000000",
+                borderRadius: "6px",
+              }}
+            >
+              {"{{ range .Metrics }}"}
+              <Row>
+                <Column className="text-sm font-semibold">
+                  {"{{ T $.Lang .Key }}"}
+                </Column>
+                <Column className="text-right text-sm">{"{{ .Value }}"}</Column>
+                <Column className="w-16 text-right text-xs text-gray-600">
+                  {"{{ .Change }}"}
+                </Column>
+              </Row>
+              {"{{ end }}"}
+            </Section>
+
+            <Text className="mb-6 text-xs text-gray-600">
+              {"{{ T .Lang `ReportSummary.comparison_note` . }}"}
+            </Text>
+
+            <Section className="mb-8 text-center">
+              <Button
+                href="{{ .ReportLink }}"
+                className="bg-blue-600 px-4 py-3 text-center text-[14px]
+                  font-medium text-white"
+                style={{
+                  boxSizing: "border-box",
+                  borderRadius: "6px",
+                }}
+              >
+                {"{{ T .Lang `ReportSummary.primary_button` . }}"}
+              </Button>
+            </Section>
+
+            <Hr className="mt-4" style={{ border: "1px solid #e5e7eb" }} />
+
+            <Footer />
+          </Container>
+        </Body>
+      </Html>
+    </Tailwind>
+  );
+}
@@ -1,13 +1,16 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
 	authServ "github.com/miketsu-inc/reservations/backend/internal/service/auth"
 	teamServ "github.com/miketsu-inc/reservations/backend/internal/service/team"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
@@ -32,11 +35,21 @@ func (h *Handler) Routes() chi.Router {
 	r.Group(func(r chi.Router) {
 		r.Use(h.middleware.Language)
 
-		r.Post("/login", h.Login)
-		r.Post("/forgot-password", h.ForgotPassword)
-		r.Post("/reset-password", h.ResetPassword)
+		r.With(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "login", Limit: 20, Window: time.Minute, KeyBy: ratelimit.ByIP},
+		)).Post("/login", h.Login)
 
-		r.Post("/users", h.UserSignup)
+		r.With(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "forgot_password", Limit: 5, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		)).Post("/forgot-password", h.ForgotPassword)
+
+		r.With(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "reset_password", Limit: 10, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		)).Post("/reset-password", h.ResetPassword)
+
+		r.With(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "user_signup", Limit: 10, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		)).Post("/users", h.UserSignup)
 
 		r.Get("/oauth/google", h.GoogleLogin)
 		r.Get("/oauth/google/callback", h.GoogleCallback)
@@ -72,8 +85,15 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.Login(r.Context(), mapToLoginInput(req))
+	tokens, err := h.service.Login(r.Context(), mapToLoginInput(req, r))
 	if err != nil {
+		var lockedErr authServ.ErrAccountLocked
+		if errors.As(err, &lockedErr) {
+			middleware.SetRetryAfter(w, lockedErr.RetryAfter)
+			httputil.Error(w, http.StatusTooManyRequests, err)
+			return
+		}
+
 		httputil.Error(w, http.StatusUnauthorized, err)
 		return
 	}
@@ -114,7 +134,7 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.ResetPassword(r.Context(), mapToResetPassordInput(req))
+	tokens, err := h.service.ResetPassword(r.Context(), mapToResetPassordInput(req, r))
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
@@ -140,7 +160,7 @@ func (h *Handler) UserSignup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.UserSignup(r.Context(), mapToUserSignupInput(req))
+	tokens, err := h.service.UserSignup(r.Context(), mapToUserSignupInput(req, r))
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
@@ -153,9 +173,10 @@ func (h *Handler) UserSignup(w http.ResponseWriter, r *http.Request) {
 }
 
 type merchantSignupReq struct {
-	Name         string `json:"name" validate:"required"`
-	ContactEmail string `json:"contact_email" validate:"required,email"`
-	Timezone     string `json:"timezone" validate:"required,timezone"`
+	Name         string  `json:"name" validate:"required"`
+	ContactEmail string  `json:"contact_email" validate:"required,email"`
+	Timezone     string  `json:"timezone" validate:"required,timezone"`
+	Currency     *string `json:"currency" validate:"omitempty,iso4217"`
 }
 
 func (h *Handler) MerchantSignup(w http.ResponseWriter, r *http.Request) {
@@ -201,24 +222,31 @@ func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
 	httputil.Success(w, http.StatusOK, mapToMeResp(result))
 }
 
+// The cookies are deleted even if the session could not be revoked, so the client is always logged out
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	jwt.DeleteJwts(w)
+
+	err := h.service.Logout(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
 }
 
 func (h *Handler) LogoutAllDevices(w http.ResponseWriter, r *http.Request) {
+	jwt.DeleteJwts(w)
+
 	err := h.service.LogoutAllDevices(r.Context())
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
 	}
-
-	jwt.DeleteJwts(w)
 }
 
 func (h *Handler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 	url, state, err := h.service.GoogleLogin(r.Context())
 	if err != nil {
-		httputil.Error(w, http.StatusInternalServerError, err)
+		httputil.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -235,7 +263,7 @@ func (h *Handler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.GoogleCallback(r.Context(), code)
+	tokens, err := h.service.GoogleCallback(r.Context(), code, mapToSessionMetadata(r))
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
@@ -250,7 +278,7 @@ func (h *Handler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) FacebookLogin(w http.ResponseWriter, r *http.Request) {
 	url, state, err := h.service.FacebookLogin(r.Context())
 	if err != nil {
-		httputil.Error(w, http.StatusInternalServerError, err)
+		httputil.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -267,7 +295,7 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.FacebookCallback(r.Context(), code)
+	tokens, err := h.service.FacebookCallback(r.Context(), code, mapToSessionMetadata(r))
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
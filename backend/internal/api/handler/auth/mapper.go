@@ -1,14 +1,25 @@
 package auth
 
 import (
+	"net/http"
+
 	authServ "github.com/miketsu-inc/reservations/backend/internal/service/auth"
 	teamServ "github.com/miketsu-inc/reservations/backend/internal/service/team"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
 )
 
-func mapToLoginInput(in loginReq) authServ.LoginInput {
+func mapToSessionMetadata(r *http.Request) authServ.SessionMetadata {
+	return authServ.SessionMetadata{
+		IpAddress: httputil.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func mapToLoginInput(in loginReq, r *http.Request) authServ.LoginInput {
 	return authServ.LoginInput{
 		Email:    in.Email,
 		Password: in.Password,
+		Metadata: mapToSessionMetadata(r),
 	}
 }
 
@@ -18,20 +29,22 @@ func mapToForgotPasswordInput(in forgotPasswordReq) authServ.ForgotPasswordInput
 	}
 }
 
-func mapToResetPassordInput(in resetPasswordReq) authServ.ResetPasswordInput {
+func mapToResetPassordInput(in resetPasswordReq, r *http.Request) authServ.ResetPasswordInput {
 	return authServ.ResetPasswordInput{
 		Token:    in.Token,
 		Password: in.Password,
+		Metadata: mapToSessionMetadata(r),
 	}
 }
 
-func mapToUserSignupInput(in userSignupReq) authServ.UserSignupInput {
+func mapToUserSignupInput(in userSignupReq, r *http.Request) authServ.UserSignupInput {
 	return authServ.UserSignupInput{
 		FirstName:   in.FirstName,
 		LastName:    in.LastName,
 		Email:       in.Email,
 		PhoneNumber: in.PhoneNumber,
 		Password:    in.Password,
+		Metadata:    mapToSessionMetadata(r),
 	}
 }
 
@@ -40,6 +53,7 @@ func mapToMerchantSignupInput(in merchantSignupReq) authServ.MerchantSignupInput
 		Name:         in.Name,
 		ContactEmail: in.ContactEmail,
 		Timezone:     in.Timezone,
+		Currency:     in.Currency,
 	}
 }
 
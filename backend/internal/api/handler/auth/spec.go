@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	oauthCallbackParams := []openapi.Param{
+		{Name: "code", In: openapi.InQuery, Required: true, Description: "Authorization code of the provider"},
+		{Name: "state", In: openapi.InQuery, Required: true},
+	}
+
+	return openapi.WithTag("Auth",
+		openapi.Operation{Handler: h.Login, Summary: "Log in with email and password", Request: loginReq{}},
+		openapi.Operation{Handler: h.ForgotPassword, Summary: "Send a password reset email", Request: forgotPasswordReq{}},
+		openapi.Operation{Handler: h.ResetPassword, Summary: "Reset the password with a reset token", Request: resetPasswordReq{}},
+		openapi.Operation{Handler: h.UserSignup, Summary: "Sign up a user", Request: userSignupReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.MerchantSignup, Summary: "Create a merchant for the user", Request: merchantSignupReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Me, Summary: "Get the user and its memberships", Response: meResp{}},
+		openapi.Operation{Handler: h.Logout, Summary: "Log out of the current session"},
+		openapi.Operation{Handler: h.LogoutAllDevices, Summary: "Log out of every session"},
+		openapi.Operation{Handler: h.GoogleLogin, Summary: "Redirect to google login", Status: http.StatusTemporaryRedirect},
+		openapi.Operation{Handler: h.GoogleCallback, Summary: "Google login callback", Status: http.StatusPermanentRedirect, Params: oauthCallbackParams},
+		openapi.Operation{Handler: h.FacebookLogin, Summary: "Redirect to facebook login", Status: http.StatusTemporaryRedirect},
+		openapi.Operation{Handler: h.FacebookCallback, Summary: "Facebook login callback", Status: http.StatusPermanentRedirect, Params: oauthCallbackParams},
+	)
+}
@@ -0,0 +1,30 @@
+package integrations
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Integrations",
+		openapi.Operation{
+			Handler: h.GoogleCalendarCallback,
+			Summary: "Google calendar authorization callback",
+			Status:  http.StatusPermanentRedirect,
+			Params: []openapi.Param{
+				{Name: "code", In: openapi.InQuery, Required: true},
+				{Name: "state", In: openapi.InQuery, Required: true},
+			},
+		},
+		openapi.Operation{
+			Handler: h.GoogleCalendarWatch,
+			Summary: "Google calendar change notification",
+			Params: []openapi.Param{
+				{Name: "X-Goog-Channel-ID", In: openapi.InHeader, Required: true},
+				{Name: "X-Goog-Resource-ID", In: openapi.InHeader, Required: true},
+				{Name: "X-Goog-Resource-State", In: openapi.InHeader, Required: true},
+			},
+		},
+	)
+}
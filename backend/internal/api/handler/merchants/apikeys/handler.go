@@ -0,0 +1,109 @@
+package apikeys
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	apiKeyServ "github.com/miketsu-inc/reservations/backend/internal/service/apikey"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *apiKeyServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *apiKeyServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionMerchantManage))
+
+	r.Get("/", h.GetAll)
+	r.Post("/", h.New)
+	r.Get("/scopes", h.GetScopes)
+	r.Delete("/{id}", h.Revoke)
+
+	return r
+}
+
+type newReq struct {
+	Name      string              `json:"name" validate:"required,max=50"`
+	Scopes    []types.ApiKeyScope `json:"scopes" validate:"required,min=1"`
+	RateLimit *int                `json:"rate_limit" validate:"omitempty,gte=1"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+type newResp struct {
+	Id  int    `json:"id"`
+	Key string `json:"key"`
+}
+
+func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
+	var req newReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.NewKey(r.Context(), mapToNewKeyInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newResp{
+		Id:  result.Id,
+		Key: result.Key,
+	})
+}
+
+type apiKeyResp struct {
+	Id         int                 `json:"id"`
+	EmployeeId int                 `json:"employee_id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []types.ApiKeyScope `json:"scopes"`
+	RateLimit  int                 `json:"rate_limit"`
+	LastUsedAt *time.Time          `json:"last_used_at"`
+	ExpiresAt  *time.Time          `json:"expires_at"`
+	RevokedAt  *time.Time          `json:"revoked_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := h.service.GetKeys(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToApiKeysResp(apiKeys))
+}
+
+func (h *Handler) GetScopes(w http.ResponseWriter, r *http.Request) {
+	httputil.Success(w, http.StatusOK, types.ApiKeyScopes)
+}
+
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	urlApiKeyId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.RevokeKey(r.Context(), urlApiKeyId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
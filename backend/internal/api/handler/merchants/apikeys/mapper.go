@@ -0,0 +1,36 @@
+package apikeys
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	apiKeyServ "github.com/miketsu-inc/reservations/backend/internal/service/apikey"
+)
+
+func mapToNewKeyInput(in newReq) apiKeyServ.NewKeyInput {
+	return apiKeyServ.NewKeyInput{
+		Name:      in.Name,
+		Scopes:    in.Scopes,
+		RateLimit: in.RateLimit,
+		ExpiresAt: in.ExpiresAt,
+	}
+}
+
+func mapToApiKeysResp(in []domain.ApiKey) []apiKeyResp {
+	out := make([]apiKeyResp, len(in))
+
+	for i, k := range in {
+		out[i] = apiKeyResp{
+			Id:         k.Id,
+			EmployeeId: k.EmployeeId,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scopes:     k.Scopes,
+			RateLimit:  k.RateLimit,
+			LastUsedAt: k.LastUsedAt,
+			ExpiresAt:  k.ExpiresAt,
+			RevokedAt:  k.RevokedAt,
+			CreatedAt:  k.CreatedAt,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,17 @@
+package apikeys
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Api keys",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the api keys of the merchant", Response: []apiKeyResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create an api key, the key is only returned here", Request: newReq{}, Response: newResp{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.GetScopes, Summary: "Get the scopes an api key can have", Response: []types.ApiKeyScope{}},
+		openapi.Operation{Handler: h.Revoke, Summary: "Revoke an api key", Params: []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}},
+	)
+}
@@ -0,0 +1,98 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	auditServ "github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+type Handler struct {
+	service    *auditServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *auditServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionAuditLogView))
+
+	r.Get("/", h.GetEntries)
+
+	return r
+}
+
+const maxPageSize = 100
+
+type auditLogEntryResp struct {
+	Id                int64                 `json:"id"`
+	EmployeeId        *int                  `json:"employee_id"`
+	EmployeeFirstName *string               `json:"employee_first_name"`
+	EmployeeLastName  *string               `json:"employee_last_name"`
+	Action            string                `json:"action"`
+	EntityType        types.AuditEntityType `json:"entity_type"`
+	EntityId          string                `json:"entity_id"`
+	Before            json.RawMessage       `json:"before"`
+	After             json.RawMessage       `json:"after"`
+	RequestId         *string               `json:"request_id"`
+	CreatedAt         time.Time             `json:"created_at"`
+}
+
+type getEntriesResp struct {
+	Entries     []auditLogEntryResp `json:"entries"`
+	HasNextPage bool                `json:"has_next_page"`
+	NextCursor  *string             `json:"next_cursor"`
+}
+
+func (h *Handler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToGetEntriesInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.GetEntries(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetEntriesResp(entries))
+}
+
+func parseOptionalTime(value string, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, err.Error())
+	}
+
+	return &t, nil
+}
+
+func parseOptionalInt(value string, name string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, err.Error())
+	}
+
+	return &i, nil
+}
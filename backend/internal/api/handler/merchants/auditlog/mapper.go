@@ -0,0 +1,96 @@
+package auditlog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	auditServ "github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+func mapToGetEntriesInput(r *http.Request) (auditServ.GetEntriesInput, error) {
+	query := r.URL.Query()
+
+	pageSize := 50
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+
+		pageSize, err = strconv.Atoi(limit)
+		if err != nil || pageSize < 1 {
+			return auditServ.GetEntriesInput{}, fmt.Errorf("invalid limit query parameter")
+		}
+
+		if pageSize > maxPageSize {
+			return auditServ.GetEntriesInput{}, fmt.Errorf("limit cannot be higher than %d", maxPageSize)
+		}
+	}
+
+	var filter domain.AuditLogFilter
+	var err error
+
+	filter.EmployeeId, err = parseOptionalInt(query.Get("employee_id"), "employee_id")
+	if err != nil {
+		return auditServ.GetEntriesInput{}, err
+	}
+
+	if action := query.Get("action"); action != "" {
+		filter.Action = &action
+	}
+
+	if entityType := query.Get("entity_type"); entityType != "" {
+		t, err := types.NewAuditEntityType(entityType)
+		if err != nil {
+			return auditServ.GetEntriesInput{}, err
+		}
+
+		filter.EntityType = &t
+	}
+
+	if entityId := query.Get("entity_id"); entityId != "" {
+		filter.EntityId = &entityId
+	}
+
+	filter.From, err = parseOptionalTime(query.Get("from"), "from")
+	if err != nil {
+		return auditServ.GetEntriesInput{}, err
+	}
+
+	filter.To, err = parseOptionalTime(query.Get("to"), "to")
+	if err != nil {
+		return auditServ.GetEntriesInput{}, err
+	}
+
+	return auditServ.GetEntriesInput{
+		Filter:   filter,
+		Cursor:   query.Get("cursor"),
+		PageSize: pageSize,
+	}, nil
+}
+
+func mapToGetEntriesResp(in auditServ.GetEntriesResult) getEntriesResp {
+	entries := make([]auditLogEntryResp, len(in.Entries))
+
+	for i, e := range in.Entries {
+		entries[i] = auditLogEntryResp{
+			Id:                e.Id,
+			EmployeeId:        e.EmployeeId,
+			EmployeeFirstName: e.EmployeeFirstName,
+			EmployeeLastName:  e.EmployeeLastName,
+			Action:            e.Action,
+			EntityType:        e.EntityType,
+			EntityId:          e.EntityId,
+			Before:            e.Before,
+			After:             e.After,
+			RequestId:         e.RequestId,
+			CreatedAt:         e.CreatedAt,
+		}
+	}
+
+	return getEntriesResp{
+		Entries:     entries,
+		HasNextPage: in.HasNextPage,
+		NextCursor:  in.NextCursor,
+	}
+}
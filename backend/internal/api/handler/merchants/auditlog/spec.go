@@ -0,0 +1,28 @@
+package auditlog
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Audit log",
+		openapi.Operation{
+			Handler:  h.GetEntries,
+			Summary:  "Get the audit log entries of the merchant, newest first",
+			Response: getEntriesResp{},
+			Params: []openapi.Param{
+				{Name: "limit", In: openapi.InQuery, Type: 0, Description: "Page size, 50 by default"},
+				{Name: "cursor", In: openapi.InQuery},
+				{Name: "employee_id", In: openapi.InQuery, Type: 0},
+				{Name: "action", In: openapi.InQuery},
+				{Name: "entity_type", In: openapi.InQuery, Type: types.AuditEntityType{}},
+				{Name: "entity_id", In: openapi.InQuery},
+				{Name: "from", In: openapi.InQuery, Type: time.Time{}},
+				{Name: "to", In: openapi.InQuery, Type: time.Time{}},
+			},
+		},
+	)
+}
@@ -6,25 +6,32 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	blockedtimeServ "github.com/miketsu-inc/reservations/backend/internal/service/blockedtime"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
 	"github.com/miketsu-inc/reservations/backend/pkg/validate"
 )
 
 type Handler struct {
-	service *blockedtimeServ.Service
+	service    *blockedtimeServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *blockedtimeServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *blockedtimeServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.New)
-	r.Put("/{id}", h.Update)
-	r.Delete("/{id}", h.Delete)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionBlockedTimesManageOwn, types.PermissionBlockedTimesManageAll))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
 
 	return r
 }
@@ -0,0 +1,17 @@
+package blockedtimes
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Blocked times",
+		openapi.Operation{Handler: h.New, Summary: "Block time for employees", Request: newReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a blocked time", Request: updateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a blocked time", Params: idParam},
+	)
+}
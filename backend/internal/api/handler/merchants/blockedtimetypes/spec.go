@@ -0,0 +1,18 @@
+package blockedtimetypes
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Blocked time types",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the blocked time types of the merchant", Response: []getTypesResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create a blocked time type", Request: newReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a blocked time type", Request: updateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a blocked time type", Params: idParam},
+	)
+}
@@ -30,6 +30,7 @@ func (h *Handler) Routes() chi.Router {
 		r.Use(h.middleware.JwtAuthentication)
 		r.Use(h.middleware.EmployeeAuthentication)
 		r.Use(h.middleware.Language)
+		r.Use(h.middleware.RequirePermission(types.PermissionBookingsManageOwn, types.PermissionBookingsManageAll))
 
 		r.Post("/", h.CreateByMerchant)
 		r.Patch("/{id}", h.UpdateByMerchant)
@@ -41,6 +42,22 @@ func (h *Handler) Routes() chi.Router {
 	return r
 }
 
+// Routes of the public api, should be mounted behind the api key authentication middleware
+func (h *Handler) ExternalRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequireScope(types.ApiKeyScopeBookingsWrite))
+	r.Use(h.middleware.RequirePermission(types.PermissionBookingsManageOwn, types.PermissionBookingsManageAll))
+
+	r.Post("/", h.CreateByMerchant)
+	r.Patch("/{id}", h.UpdateByMerchant)
+	r.Delete("/{id}", h.CancelByMerchant)
+
+	r.Patch("/{b_id}/participants/{p_id}", h.UpdateParticipantStatus)
+
+	return r
+}
+
 type createByMerchantReq struct {
 	Customers    []customerReq     `json:"customers"`
 	ServiceId    int               `json:"service_id" validate:"required"`
@@ -0,0 +1,26 @@
+package bookings
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Bookings",
+		openapi.Operation{Handler: h.CreateByMerchant, Summary: "Create a booking", Request: createByMerchantReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.UpdateByMerchant, Summary: "Update a booking", Request: updateByMerchantReq{}, Params: idParam},
+		openapi.Operation{Handler: h.CancelByMerchant, Summary: "Cancel a booking", Request: cancelByMerchantReq{}, Params: idParam},
+		openapi.Operation{
+			Handler: h.UpdateParticipantStatus,
+			Summary: "Update the status of a participant of a booking",
+			Request: updatePaticipantStatusReq{},
+			Params: []openapi.Param{
+				{Name: "b_id", In: openapi.InPath, Type: 0, Description: "Id of the booking"},
+				{Name: "p_id", In: openapi.InPath, Type: 0, Description: "Id of the participant"},
+			},
+		},
+	)
+}
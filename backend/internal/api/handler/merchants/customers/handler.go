@@ -1,42 +1,119 @@
 package customers
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
 	customerServ "github.com/miketsu-inc/reservations/backend/internal/service/customer"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/csvx"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
 	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+	"github.com/miketsu-inc/reservations/backend/pkg/xlsx"
 )
 
 type Handler struct {
-	service *customerServ.Service
+	service    *customerServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *customerServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *customerServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.New)
-	r.Put("/{id}", h.Update)
-	r.Delete("/{id}", h.Delete)
-	r.Get("/{id}", h.Get)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCustomersView))
 
-	r.Get("/{id}/stats", h.GetStats)
-	r.Put("/{id}/blacklist", h.Blacklist)
-	r.Delete("/{id}/blacklist", h.UnBlacklist)
+		r.Get("/{id}", h.Get)
+		r.Get("/{id}/stats", h.GetStats)
+		r.Get("/{id}/merges", h.GetMerges)
 
-	r.Get("/", h.GetAll)
-	r.Put("/transfer", h.TransferBookings)
-	r.Get("/blacklist", h.GetAllBlacklisted)
+		r.Get("/", h.GetAll)
+		r.Get("/blacklist", h.GetAllBlacklisted)
+		r.Get("/export", h.Export)
+		r.Get("/tags", h.GetTags)
+		r.Get("/fields", h.GetCustomFields)
+		r.Get("/segments", h.GetSegments)
+
+		r.Get("/{id}/consents", h.GetConsents)
+
+		r.Get("/campaigns", h.GetCampaigns)
+		r.Get("/campaigns/{id}", h.GetCampaign)
+
+		r.Get("/policy-rules", h.GetPolicyRules)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCustomersManage))
+
+		r.Post("/imports/preview", h.PreviewImport)
+		r.Post("/imports", h.StartImport)
+		r.Get("/imports/{id}", h.GetImport)
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+
+		r.Put("/{id}/blacklist", h.Blacklist)
+		r.Delete("/{id}/blacklist", h.UnBlacklist)
+		r.Delete("/{id}/approval-requirement", h.RemoveApprovalRequirement)
+
+		r.Put("/transfer", h.TransferBookings)
+
+		r.Get("/duplicates", h.FindDuplicates)
+		r.Post("/{id}/merge", h.Merge)
+
+		r.Post("/fields", h.NewCustomField)
+		r.Put("/fields/{id}", h.UpdateCustomField)
+		r.Delete("/fields/{id}", h.DeleteCustomField)
+
+		r.Post("/segments", h.NewSegment)
+		r.Put("/segments/{id}", h.UpdateSegment)
+		r.Delete("/segments/{id}", h.DeleteSegment)
+
+		r.Put("/{id}/consents", h.SetConsent)
+
+		r.Post("/campaigns", h.NewCampaign)
+		r.Post("/campaigns/{id}/cancel", h.CancelCampaign)
+
+		r.Post("/policy-rules", h.NewPolicyRule)
+		r.Put("/policy-rules/{id}", h.UpdatePolicyRule)
+		r.Delete("/policy-rules/{id}", h.DeletePolicyRule)
+	})
+
+	return r
+}
+
+// Routes of the public api, should be mounted behind the api key authentication middleware
+func (h *Handler) ExternalRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequireScope(types.ApiKeyScopeCustomersRead))
+		r.Use(h.middleware.RequirePermission(types.PermissionCustomersView))
+
+		r.Get("/", h.GetAllExternal)
+		r.Get("/{id}", h.Get)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequireScope(types.ApiKeyScopeCustomersWrite))
+		r.Use(h.middleware.RequirePermission(types.PermissionCustomersManage))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+	})
 
 	return r
 }
@@ -48,6 +125,9 @@ type newReq struct {
 	PhoneNumber *string    `json:"phone_number"`
 	Birthday    *time.Time `json:"birthday"`
 	Note        *string    `json:"note"`
+	Tags        []string   `json:"tags"`
+	// Values of the custom fields keyed by the id of the field
+	CustomFields map[string]any `json:"custom_fields"`
 }
 
 func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
@@ -75,6 +155,9 @@ type updateReq struct {
 	PhoneNumber *string    `json:"phone_number"`
 	Birthday    *time.Time `json:"birthday"`
 	Note        *string    `json:"note"`
+	Tags        []string   `json:"tags"`
+	// Values of the custom fields keyed by the id of the field
+	CustomFields map[string]any `json:"custom_fields"`
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
@@ -105,7 +188,15 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(r.Context(), urlCustomerId)
+	switch r.URL.Query().Get("mode") {
+	case "", "delete":
+		err = h.service.Delete(r.Context(), urlCustomerId)
+	case "anonymize":
+		err = h.service.Anonymize(r.Context(), urlCustomerId)
+	default:
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid mode query parameter"))
+		return
+	}
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
@@ -113,14 +204,16 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 type getResp struct {
-	Id          uuid.UUID  `json:"id"`
-	FirstName   *string    `json:"first_name"`
-	LastName    *string    `json:"last_name"`
-	Email       *string    `json:"email"`
-	PhoneNumber *string    `json:"phone_number"`
-	Birthday    *time.Time `json:"birthday"`
-	Note        *string    `json:"note"`
-	IsDummy     bool       `json:"is_dummy"`
+	Id           uuid.UUID      `json:"id"`
+	FirstName    *string        `json:"first_name"`
+	LastName     *string        `json:"last_name"`
+	Email        *string        `json:"email"`
+	PhoneNumber  *string        `json:"phone_number"`
+	Birthday     *time.Time     `json:"birthday"`
+	Note         *string        `json:"note"`
+	Tags         []string       `json:"tags"`
+	CustomFields map[string]any `json:"custom_fields"`
+	IsDummy      bool           `json:"is_dummy"`
 }
 
 func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
@@ -147,9 +240,13 @@ type getStatsResp struct {
 	PhoneNumber          *string                `json:"phone_number"`
 	Birthday             *time.Time             `json:"birthday"`
 	Note                 *string                `json:"note"`
+	Tags                 []string               `json:"tags"`
+	CustomFields         map[string]any         `json:"custom_fields"`
 	IsDummy              bool                   `json:"is_dummy"`
 	IsBlacklisted        bool                   `json:"is_blacklisted"`
 	BlacklistReason      *string                `json:"blacklist_reason"`
+	RequiresApproval     bool                   `json:"requires_approval"`
+	ApprovalReason       *string                `json:"approval_reason"`
 	TimesBooked          int                    `json:"times_booked"`
 	TimesCancelledByUser int                    `json:"times_cancelled_by_user"`
 	TimesUpcoming        int                    `json:"times_upcoming"`
@@ -182,7 +279,7 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetStatsResp(customerStats))
+	httputil.Success(w, http.StatusOK, mapToGetStatsResp(customerStats, lang.LangFromContext(r.Context())))
 }
 
 type blacklistReq struct {
@@ -226,28 +323,64 @@ func (h *Handler) UnBlacklist(w http.ResponseWriter, r *http.Request) {
 }
 
 type getAllResp struct {
-	Id              uuid.UUID  `json:"id"`
-	FirstName       *string    `json:"first_name"`
-	LastName        *string    `json:"last_name"`
-	Email           *string    `json:"email"`
-	PhoneNumber     *string    `json:"phone_number"`
-	Birthday        *time.Time `json:"birthday"`
-	Note            *string    `json:"note"`
-	IsDummy         bool       `json:"is_dummy"`
-	IsBlacklisted   bool       `json:"is_blacklisted"`
-	BlacklistReason *string    `json:"blacklist_reason"`
-	TimesBooked     int        `json:"times_booked"`
-	TimesCancelled  int        `json:"times_cancelled"`
+	Id              uuid.UUID      `json:"id"`
+	FirstName       *string        `json:"first_name"`
+	LastName        *string        `json:"last_name"`
+	Email           *string        `json:"email"`
+	PhoneNumber     *string        `json:"phone_number"`
+	Birthday        *time.Time     `json:"birthday"`
+	Note            *string        `json:"note"`
+	IsDummy         bool           `json:"is_dummy"`
+	IsBlacklisted   bool           `json:"is_blacklisted"`
+	BlacklistReason *string        `json:"blacklist_reason"`
+	Tags            []string       `json:"tags"`
+	CustomFields    map[string]any `json:"custom_fields"`
+	TimesBooked     int            `json:"times_booked"`
+	TimesCancelled  int            `json:"times_cancelled"`
+	NoShows         int            `json:"no_shows"`
+	LastVisit       *time.Time     `json:"last_visit"`
+}
+
+type getAllPageResp struct {
+	Customers []getAllResp `json:"customers"`
+	// Number of customers matching the filter on every page
+	Total int `json:"total"`
 }
 
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
-	customers, err := h.service.GetAll(r.Context())
+	input, err := mapToGetAllInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.GetAll(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, getAllPageResp{
+		Customers: mapToGetAllResp(result.Customers),
+		Total:     result.Total,
+	})
+}
+
+// The public api returned a bare list before paging was added, it has to stay that way in this version
+func (h *Handler) GetAllExternal(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToGetAllInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.GetAll(r.Context(), input)
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetAllResp(customers))
+	httputil.Success(w, http.StatusOK, mapToGetAllResp(result.Customers))
 }
 
 type transferBookingsReq struct {
@@ -279,3 +412,673 @@ func (h *Handler) GetAllBlacklisted(w http.ResponseWriter, r *http.Request) {
 
 	httputil.Success(w, http.StatusOK, mapToGetAllResp(blacklistedCustomers))
 }
+
+type importReq struct {
+	Content            string           `json:"content" validate:"required"`
+	Mapping            importMappingReq `json:"mapping"`
+	DefaultCountryCode string           `json:"default_country_code"`
+}
+
+type importMappingReq struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Birthday    string `json:"birthday"`
+	Note        string `json:"note"`
+}
+
+type importRowErrorResp struct {
+	Row     int     `json:"row"`
+	Field   *string `json:"field"`
+	Message string  `json:"message"`
+}
+
+type previewImportResp struct {
+	Columns        []string               `json:"columns"`
+	TotalRows      int                    `json:"total_rows"`
+	ValidCount     int                    `json:"valid_count"`
+	DuplicateCount int                    `json:"duplicate_count"`
+	InvalidCount   int                    `json:"invalid_count"`
+	Rows           []previewImportRowResp `json:"rows"`
+}
+
+type previewImportRowResp struct {
+	Row                 int                  `json:"row"`
+	FirstName           *string              `json:"first_name"`
+	LastName            *string              `json:"last_name"`
+	Email               *string              `json:"email"`
+	PhoneNumber         *string              `json:"phone_number"`
+	Birthday            *time.Time           `json:"birthday"`
+	Note                *string              `json:"note"`
+	IsDuplicate         bool                 `json:"is_duplicate"`
+	DuplicateCustomerId *uuid.UUID           `json:"duplicate_customer_id"`
+	DuplicateRow        *int                 `json:"duplicate_row"`
+	Errors              []importRowErrorResp `json:"errors"`
+}
+
+func (h *Handler) PreviewImport(w http.ResponseWriter, r *http.Request) {
+	var req importReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := h.service.PreviewImport(r.Context(), mapToImportInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToPreviewImportResp(preview))
+}
+
+type startImportResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) StartImport(w http.ResponseWriter, r *http.Request) {
+	var req importReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	importId, err := h.service.StartImport(r.Context(), mapToImportInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusAccepted, startImportResp{Id: importId})
+}
+
+type getImportResp struct {
+	Id             int                        `json:"id"`
+	Status         types.CustomerImportStatus `json:"status"`
+	TotalRows      int                        `json:"total_rows"`
+	ProcessedRows  int                        `json:"processed_rows"`
+	CreatedCount   int                        `json:"created_count"`
+	DuplicateCount int                        `json:"duplicate_count"`
+	InvalidCount   int                        `json:"invalid_count"`
+	RowErrors      []importRowErrorResp       `json:"row_errors"`
+	Error          *string                    `json:"error"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	FinishedAt     *time.Time                 `json:"finished_at"`
+}
+
+func (h *Handler) GetImport(w http.ResponseWriter, r *http.Request) {
+	urlImportId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid import id provided"))
+		return
+	}
+
+	customerImport, err := h.service.GetImport(r.Context(), urlImportId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetImportResp(customerImport))
+}
+
+// Downloads the customers as a csv or xlsx file, the ones GetAll returns with the same filters on every page
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	if format != "csv" && format != "xlsx" {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid format query parameter"))
+		return
+	}
+
+	input, err := mapToGetAllInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	input.Limit = nil
+	input.Offset = 0
+
+	result, err := h.service.GetAll(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows := mapToExportRows(result.Customers)
+
+	var file bytes.Buffer
+	contentType := csvx.ContentType
+
+	if format == "xlsx" {
+		contentType = xlsx.ContentType
+		err = xlsx.Write(&file, "Customers", rows)
+	} else {
+		err = csvx.Write(&file, rows)
+	}
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, fmt.Errorf("could not create export file: %s", err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("customers-%s.%s", time.Now().Format(time.DateOnly), format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file.Bytes()) // nolint:errcheck
+}
+
+type duplicateCustomerResp struct {
+	Id            uuid.UUID `json:"id"`
+	FirstName     *string   `json:"first_name"`
+	LastName      *string   `json:"last_name"`
+	Email         *string   `json:"email"`
+	PhoneNumber   *string   `json:"phone_number"`
+	IsDummy       bool      `json:"is_dummy"`
+	IsBlacklisted bool      `json:"is_blacklisted"`
+}
+
+type findDuplicatesResp struct {
+	Customer  duplicateCustomerResp `json:"customer"`
+	Duplicate duplicateCustomerResp `json:"duplicate"`
+	Score     float64               `json:"score"`
+	Reasons   []string              `json:"reasons"`
+}
+
+func (h *Handler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	duplicates, err := h.service.FindDuplicates(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToFindDuplicatesResp(duplicates))
+}
+
+type mergeReq struct {
+	DuplicateId uuid.UUID `json:"duplicate_id" validate:"required,uuid"`
+}
+
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	var req mergeReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.Merge(r.Context(), urlCustomerId, customerServ.MergeInput{DuplicateId: req.DuplicateId})
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type mergedCustomerResp struct {
+	Id              uuid.UUID  `json:"id"`
+	FirstName       *string    `json:"first_name"`
+	LastName        *string    `json:"last_name"`
+	Email           *string    `json:"email"`
+	PhoneNumber     *string    `json:"phone_number"`
+	Birthday        *time.Time `json:"birthday"`
+	Note            *string    `json:"note"`
+	IsBlacklisted   bool       `json:"is_blacklisted"`
+	BlacklistReason *string    `json:"blacklist_reason"`
+}
+
+type getMergesResp struct {
+	Id                      int                `json:"id"`
+	EmployeeId              *int               `json:"employee_id"`
+	MergedCustomer          mergedCustomerResp `json:"merged_customer"`
+	MovedParticipants       int                `json:"moved_participants"`
+	MovedSeriesParticipants int                `json:"moved_series_participants"`
+	CreatedAt               time.Time          `json:"created_at"`
+}
+
+func (h *Handler) GetMerges(w http.ResponseWriter, r *http.Request) {
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	merges, err := h.service.GetMerges(r.Context(), urlCustomerId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetMergesResp(merges))
+}
+
+type getTagsResp struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.GetTags(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetTagsResp(tags))
+}
+
+type customFieldReq struct {
+	Name       string                `json:"name" validate:"required,max=50"`
+	FieldType  types.CustomFieldType `json:"field_type" validate:"required"`
+	Options    []string              `json:"options"`
+	IsRequired bool                  `json:"is_required"`
+}
+
+type newCustomFieldResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewCustomField(w http.ResponseWriter, r *http.Request) {
+	var req customFieldReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fieldId, err := h.service.NewCustomField(r.Context(), mapToCustomFieldInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newCustomFieldResp{Id: fieldId})
+}
+
+func (h *Handler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	var req customFieldReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fieldId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid custom field id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.UpdateCustomField(r.Context(), fieldId, mapToCustomFieldInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	fieldId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid custom field id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeleteCustomField(r.Context(), fieldId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type getCustomFieldsResp struct {
+	Id         int                   `json:"id"`
+	Name       string                `json:"name"`
+	FieldType  types.CustomFieldType `json:"field_type"`
+	Options    []string              `json:"options"`
+	IsRequired bool                  `json:"is_required"`
+}
+
+func (h *Handler) GetCustomFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.service.GetCustomFields(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetCustomFieldsResp(fields))
+}
+
+type customerFilterReq struct {
+	Search            *string        `json:"search"`
+	IsBlacklisted     *bool          `json:"is_blacklisted"`
+	Tags              []string       `json:"tags"`
+	NoVisitInDays     *int           `json:"no_visit_in_days" validate:"omitempty,min=0"`
+	BirthdayThisMonth bool           `json:"birthday_this_month"`
+	MinNoShows        *int           `json:"min_no_shows" validate:"omitempty,min=0"`
+	MinTimesBooked    *int           `json:"min_times_booked" validate:"omitempty,min=0"`
+	CustomFields      map[string]any `json:"custom_fields"`
+}
+
+type segmentReq struct {
+	Name   string            `json:"name" validate:"required,max=50"`
+	Filter customerFilterReq `json:"filter"`
+}
+
+type newSegmentResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewSegment(w http.ResponseWriter, r *http.Request) {
+	var req segmentReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	segmentId, err := h.service.NewSegment(r.Context(), mapToSegmentInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newSegmentResp{Id: segmentId})
+}
+
+func (h *Handler) UpdateSegment(w http.ResponseWriter, r *http.Request) {
+	var req segmentReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	segmentId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid segment id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.UpdateSegment(r.Context(), segmentId, mapToSegmentInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeleteSegment(w http.ResponseWriter, r *http.Request) {
+	segmentId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid segment id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeleteSegment(r.Context(), segmentId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type getSegmentsResp struct {
+	Id        int               `json:"id"`
+	Name      string            `json:"name"`
+	Filter    customerFilterReq `json:"filter"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (h *Handler) GetSegments(w http.ResponseWriter, r *http.Request) {
+	segments, err := h.service.GetSegments(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetSegmentsResp(segments))
+}
+
+type consentReq struct {
+	Channel   types.MarketingChannel `json:"channel" validate:"required"`
+	IsGranted *bool                  `json:"is_granted" validate:"required"`
+}
+
+func (h *Handler) SetConsent(w http.ResponseWriter, r *http.Request) {
+	var req consentReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.SetMarketingConsent(r.Context(), urlCustomerId, mapToConsentInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type consentResp struct {
+	Channel    types.MarketingChannel `json:"channel"`
+	IsGranted  bool                   `json:"is_granted"`
+	Source     types.ConsentSource    `json:"source"`
+	EmployeeId *int                   `json:"employee_id"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+func (h *Handler) GetConsents(w http.ResponseWriter, r *http.Request) {
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	consents, err := h.service.GetMarketingConsents(r.Context(), urlCustomerId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToConsentsResp(consents))
+}
+
+type newCampaignReq struct {
+	Name      string            `json:"name" validate:"required,max=50"`
+	Subject   string            `json:"subject" validate:"required,max=200"`
+	Body      string            `json:"body" validate:"required,max=10000"`
+	Filter    customerFilterReq `json:"filter"`
+	SegmentId *int              `json:"segment_id"`
+}
+
+type newCampaignResp struct {
+	Id             int `json:"id"`
+	RecipientCount int `json:"recipient_count"`
+}
+
+func (h *Handler) NewCampaign(w http.ResponseWriter, r *http.Request) {
+	var req newCampaignReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.NewCampaign(r.Context(), mapToCampaignInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusAccepted, newCampaignResp{Id: result.Id, RecipientCount: result.RecipientCount})
+}
+
+type campaignResp struct {
+	Id             int                  `json:"id"`
+	EmployeeId     *int                 `json:"employee_id"`
+	Name           string               `json:"name"`
+	Subject        string               `json:"subject"`
+	Body           string               `json:"body"`
+	Status         types.CampaignStatus `json:"status"`
+	Filter         customerFilterReq    `json:"filter"`
+	RecipientCount int                  `json:"recipient_count"`
+	PendingCount   int                  `json:"pending_count"`
+	SentCount      int                  `json:"sent_count"`
+	SkippedCount   int                  `json:"skipped_count"`
+	FailedCount    int                  `json:"failed_count"`
+	CreatedAt      time.Time            `json:"created_at"`
+	FinishedAt     *time.Time           `json:"finished_at"`
+}
+
+func (h *Handler) GetCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.service.GetCampaigns(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToCampaignsResp(campaigns))
+}
+
+func (h *Handler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid campaign id: %s", err.Error()))
+		return
+	}
+
+	campaign, err := h.service.GetCampaign(r.Context(), campaignId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToCampaignResp(campaign))
+}
+
+func (h *Handler) CancelCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid campaign id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.CancelCampaign(r.Context(), campaignId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) RemoveApprovalRequirement(w http.ResponseWriter, r *http.Request) {
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.RemoveApprovalRequirement(r.Context(), urlCustomerId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+type policyRuleReq struct {
+	Event      types.CustomerPolicyEvent  `json:"event" validate:"required"`
+	Threshold  int                        `json:"threshold" validate:"required,min=1,max=100"`
+	PeriodDays int                        `json:"period_days" validate:"required,min=1,max=3650"`
+	Action     types.CustomerPolicyAction `json:"action" validate:"required"`
+}
+
+type newPolicyRuleResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewPolicyRule(w http.ResponseWriter, r *http.Request) {
+	var req policyRuleReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ruleId, err := h.service.NewPolicyRule(r.Context(), mapToPolicyRuleInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newPolicyRuleResp{Id: ruleId})
+}
+
+func (h *Handler) UpdatePolicyRule(w http.ResponseWriter, r *http.Request) {
+	var req policyRuleReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ruleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid policy rule id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.UpdatePolicyRule(r.Context(), ruleId, mapToPolicyRuleInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeletePolicyRule(w http.ResponseWriter, r *http.Request) {
+	ruleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid policy rule id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeletePolicyRule(r.Context(), ruleId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+type policyRuleResp struct {
+	Id         int                        `json:"id"`
+	Event      types.CustomerPolicyEvent  `json:"event"`
+	Threshold  int                        `json:"threshold"`
+	PeriodDays int                        `json:"period_days"`
+	Action     types.CustomerPolicyAction `json:"action"`
+	CreatedAt  time.Time                  `json:"created_at"`
+}
+
+func (h *Handler) GetPolicyRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetPolicyRules(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToPolicyRulesResp(rules))
+}
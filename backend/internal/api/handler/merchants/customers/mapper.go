@@ -1,47 +1,61 @@
 package customers
 
 import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	customerServ "github.com/miketsu-inc/reservations/backend/internal/service/customer"
+	"golang.org/x/text/language"
 )
 
 func mapToNewInput(in newReq) customerServ.NewInput {
 	return customerServ.NewInput{
-		FirstName:   in.FirstName,
-		LastName:    in.LastName,
-		Email:       in.Email,
-		PhoneNumber: in.PhoneNumber,
-		Birthday:    in.Birthday,
-		Note:        in.Note,
+		FirstName:    in.FirstName,
+		LastName:     in.LastName,
+		Email:        in.Email,
+		PhoneNumber:  in.PhoneNumber,
+		Birthday:     in.Birthday,
+		Note:         in.Note,
+		Tags:         in.Tags,
+		CustomFields: in.CustomFields,
 	}
 }
 
 func mapToUpdateInput(in updateReq) customerServ.UpdateInput {
 	return customerServ.UpdateInput{
-		Id:          in.Id,
-		FirstName:   in.FirstName,
-		LastName:    in.LastName,
-		Email:       in.Email,
-		PhoneNumber: in.PhoneNumber,
-		Birthday:    in.Birthday,
-		Note:        in.Note,
+		Id:           in.Id,
+		FirstName:    in.FirstName,
+		LastName:     in.LastName,
+		Email:        in.Email,
+		PhoneNumber:  in.PhoneNumber,
+		Birthday:     in.Birthday,
+		Note:         in.Note,
+		Tags:         in.Tags,
+		CustomFields: in.CustomFields,
 	}
 }
 
 func mapToGetResp(in domain.CustomerInfo) getResp {
 	return getResp{
-		Id:          in.Id,
-		FirstName:   in.FirstName,
-		LastName:    in.LastName,
-		Email:       in.Email,
-		PhoneNumber: in.PhoneNumber,
-		Birthday:    in.Birthday,
-		Note:        in.Note,
-		IsDummy:     in.IsDummy,
+		Id:           in.Id,
+		FirstName:    in.FirstName,
+		LastName:     in.LastName,
+		Email:        in.Email,
+		PhoneNumber:  in.PhoneNumber,
+		Birthday:     in.Birthday,
+		Note:         in.Note,
+		Tags:         in.Tags,
+		CustomFields: in.CustomFields,
+		IsDummy:      in.IsDummy,
 	}
 }
 
-func mapToGetStatsResp(in domain.CustomerStatistics) getStatsResp {
+func mapToGetStatsResp(in domain.CustomerStatistics, locale language.Tag) getStatsResp {
 	bookings := make([]customerBookingsResp, len(in.Bookings))
 
 	for i, b := range in.Bookings {
@@ -51,7 +65,7 @@ func mapToGetStatsResp(in domain.CustomerStatistics) getStatsResp {
 			ServiceName:       b.ServiceName,
 			CancelDeadline:    b.CancelDeadline,
 			FormattedLocation: b.FormattedLocation,
-			Price:             b.Price.ToFormatted(),
+			Price:             b.Price.ToFormatted(locale),
 			PriceType:         b.PriceType,
 			MerchantName:      b.MerchantName,
 			Status:            b.Status,
@@ -66,9 +80,13 @@ func mapToGetStatsResp(in domain.CustomerStatistics) getStatsResp {
 		PhoneNumber:          in.PhoneNumber,
 		Birthday:             in.Birthday,
 		Note:                 in.Note,
+		Tags:                 in.Tags,
+		CustomFields:         in.CustomFields,
 		IsDummy:              in.IsDummy,
 		IsBlacklisted:        in.IsBlacklisted,
 		BlacklistReason:      in.BlacklistReason,
+		RequiresApproval:     in.RequiresApproval,
+		ApprovalReason:       in.ApprovalReason,
 		TimesBooked:          in.TimesBooked,
 		TimesCancelledByUser: in.TimesCancelledByUser,
 		TimesUpcoming:        in.TimesUpcoming,
@@ -99,8 +117,12 @@ func mapToGetAllResp(in []domain.PublicCustomer) []getAllResp {
 			IsDummy:         c.IsDummy,
 			IsBlacklisted:   c.IsBlacklisted,
 			BlacklistReason: c.BlacklistReason,
+			Tags:            c.Tags,
+			CustomFields:    c.CustomFields,
 			TimesBooked:     c.TimesBooked,
 			TimesCancelled:  c.TimesCancelled,
+			NoShows:         c.NoShows,
+			LastVisit:       c.LastVisit,
 		}
 	}
 
@@ -113,3 +135,466 @@ func mapToTransferBookingsInput(in transferBookingsReq) customerServ.TransferBoo
 		ToCustomerId:   in.ToCustomerId,
 	}
 }
+
+func mapToImportInput(in importReq) customerServ.ImportInput {
+	return customerServ.ImportInput{
+		Content: in.Content,
+		Options: domain.CustomerImportOptions{
+			Mapping: domain.CustomerImportMapping{
+				FirstName:   in.Mapping.FirstName,
+				LastName:    in.Mapping.LastName,
+				Email:       in.Mapping.Email,
+				PhoneNumber: in.Mapping.PhoneNumber,
+				Birthday:    in.Mapping.Birthday,
+				Note:        in.Mapping.Note,
+			},
+			DefaultCountryCode: in.DefaultCountryCode,
+		},
+	}
+}
+
+func mapToImportRowErrorsResp(in []domain.CustomerImportRowError) []importRowErrorResp {
+	out := make([]importRowErrorResp, len(in))
+
+	for i, e := range in {
+		out[i] = importRowErrorResp{
+			Row:     e.Row,
+			Field:   e.Field,
+			Message: e.Message,
+		}
+	}
+
+	return out
+}
+
+func mapToPreviewImportResp(in customerServ.ImportPreview) previewImportResp {
+	rows := make([]previewImportRowResp, len(in.Rows))
+
+	for i, row := range in.Rows {
+		rows[i] = previewImportRowResp{
+			Row:         row.Row,
+			FirstName:   row.Customer.FirstName,
+			LastName:    row.Customer.LastName,
+			Email:       row.Customer.Email,
+			PhoneNumber: row.Customer.PhoneNumber,
+			Birthday:    row.Customer.Birthday,
+			Note:        row.Customer.Note,
+			IsDuplicate: row.Duplicate != nil,
+			Errors:      mapToImportRowErrorsResp(row.Errors),
+		}
+
+		if row.Duplicate != nil {
+			rows[i].DuplicateCustomerId = row.Duplicate.CustomerId
+			rows[i].DuplicateRow = row.Duplicate.Row
+		}
+	}
+
+	return previewImportResp{
+		Columns:        in.Columns,
+		TotalRows:      in.TotalRows,
+		ValidCount:     in.ValidCount,
+		DuplicateCount: in.DuplicateCount,
+		InvalidCount:   in.InvalidCount,
+		Rows:           rows,
+	}
+}
+
+func mapToGetImportResp(in domain.CustomerImport) getImportResp {
+	return getImportResp{
+		Id:             in.Id,
+		Status:         in.Status,
+		TotalRows:      in.TotalRows,
+		ProcessedRows:  in.ProcessedRows,
+		CreatedCount:   in.CreatedCount,
+		DuplicateCount: in.DuplicateCount,
+		InvalidCount:   in.InvalidCount,
+		RowErrors:      mapToImportRowErrorsResp(in.RowErrors),
+		Error:          in.Error,
+		CreatedAt:      in.CreatedAt,
+		FinishedAt:     in.FinishedAt,
+	}
+}
+
+// The columns are named like the fields of the import, so an exported file can be imported again
+func mapToExportRows(in []domain.PublicCustomer) [][]string {
+	rows := [][]string{{"id", "first_name", "last_name", "email", "phone_number", "birthday", "note",
+		"is_blacklisted", "blacklist_reason", "tags", "times_booked", "times_cancelled", "no_shows"}}
+
+	for _, c := range in {
+		birthday := ""
+		if c.Birthday != nil {
+			birthday = c.Birthday.Format(time.DateOnly)
+		}
+
+		tags := strings.Join(c.Tags, ", ")
+
+		rows = append(rows, []string{
+			c.Id.String(),
+			exportText(c.FirstName),
+			exportText(c.LastName),
+			deref(c.Email),
+			deref(c.PhoneNumber),
+			birthday,
+			exportText(c.Note),
+			strconv.FormatBool(c.IsBlacklisted),
+			exportText(c.BlacklistReason),
+			exportText(&tags),
+			strconv.Itoa(c.TimesBooked),
+			strconv.Itoa(c.TimesCancelled),
+			strconv.Itoa(c.NoShows),
+		})
+	}
+
+	return rows
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// Free text could be evaluated as a formula by spreadsheet programs,
+// so values starting like one are prefixed with an apostrophe
+func exportText(s *string) string {
+	value := deref(s)
+
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func mapToDuplicateCustomerResp(in domain.DuplicateCandidate) duplicateCustomerResp {
+	return duplicateCustomerResp{
+		Id:            in.Id,
+		FirstName:     in.FirstName,
+		LastName:      in.LastName,
+		Email:         in.Email,
+		PhoneNumber:   in.PhoneNumber,
+		IsDummy:       in.IsDummy,
+		IsBlacklisted: in.IsBlacklisted,
+	}
+}
+
+func mapToFindDuplicatesResp(in []customerServ.Duplicate) []findDuplicatesResp {
+	out := make([]findDuplicatesResp, len(in))
+
+	for i, d := range in {
+		out[i] = findDuplicatesResp{
+			Customer:  mapToDuplicateCustomerResp(d.Customer),
+			Duplicate: mapToDuplicateCustomerResp(d.Duplicate),
+			Score:     d.Score,
+			Reasons:   d.Reasons,
+		}
+	}
+
+	return out
+}
+
+func mapToGetMergesResp(in []domain.CustomerMerge) []getMergesResp {
+	out := make([]getMergesResp, len(in))
+
+	for i, m := range in {
+		out[i] = getMergesResp{
+			Id:         m.Id,
+			EmployeeId: m.EmployeeId,
+			MergedCustomer: mergedCustomerResp{
+				Id:              m.MergedCustomer.Id,
+				FirstName:       m.MergedCustomer.FirstName,
+				LastName:        m.MergedCustomer.LastName,
+				Email:           m.MergedCustomer.Email,
+				PhoneNumber:     m.MergedCustomer.PhoneNumber,
+				Birthday:        m.MergedCustomer.Birthday,
+				Note:            m.MergedCustomer.Note,
+				IsBlacklisted:   m.MergedCustomer.IsBlacklisted,
+				BlacklistReason: m.MergedCustomer.BlacklistReason,
+			},
+			MovedParticipants:       m.MovedParticipants,
+			MovedSeriesParticipants: m.MovedSeriesParticipants,
+			CreatedAt:               m.CreatedAt,
+		}
+	}
+
+	return out
+}
+
+// Custom fields are filtered by the field_<id> query parameters, e.g. field_3=gold
+func mapToGetAllInput(r *http.Request) (customerServ.GetAllInput, error) {
+	query := r.URL.Query()
+
+	var input customerServ.GetAllInput
+	var err error
+
+	if search := query.Get("search"); search != "" {
+		input.Filter.Search = &search
+	}
+
+	if blacklisted := query.Get("blacklisted"); blacklisted != "" {
+		isBlacklisted, err := strconv.ParseBool(blacklisted)
+		if err != nil {
+			return customerServ.GetAllInput{}, fmt.Errorf("invalid blacklisted query parameter")
+		}
+
+		input.Filter.IsBlacklisted = &isBlacklisted
+	}
+
+	if birthdayThisMonth := query.Get("birthday_this_month"); birthdayThisMonth != "" {
+		input.Filter.BirthdayThisMonth, err = strconv.ParseBool(birthdayThisMonth)
+		if err != nil {
+			return customerServ.GetAllInput{}, fmt.Errorf("invalid birthday_this_month query parameter")
+		}
+	}
+
+	input.Filter.Tags = query["tag"]
+
+	for _, param := range []struct {
+		name  string
+		value **int
+	}{
+		{"no_visit_in_days", &input.Filter.NoVisitInDays},
+		{"min_no_shows", &input.Filter.MinNoShows},
+		{"min_times_booked", &input.Filter.MinTimesBooked},
+		{"segment_id", &input.SegmentId},
+		{"limit", &input.Limit},
+	} {
+		*param.value, err = parseOptionalInt(query.Get(param.name), param.name)
+		if err != nil {
+			return customerServ.GetAllInput{}, err
+		}
+	}
+
+	offset, err := parseOptionalInt(query.Get("offset"), "offset")
+	if err != nil {
+		return customerServ.GetAllInput{}, err
+	}
+
+	if offset != nil {
+		input.Offset = *offset
+	}
+
+	for key, values := range query {
+		fieldId, ok := strings.CutPrefix(key, "field_")
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if input.Filter.CustomFields == nil {
+			input.Filter.CustomFields = domain.CustomFieldValues{}
+		}
+
+		input.Filter.CustomFields[fieldId] = values[0]
+	}
+
+	input.SortBy = query.Get("sort")
+	if input.SortBy != "" && !slices.Contains(sortFields, input.SortBy) {
+		return customerServ.GetAllInput{}, fmt.Errorf("invalid sort query parameter")
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		input.Descending = true
+	default:
+		return customerServ.GetAllInput{}, fmt.Errorf("invalid order query parameter")
+	}
+
+	return input, nil
+}
+
+var sortFields = []string{domain.CustomerSortName, domain.CustomerSortTimesBooked, domain.CustomerSortNoShows, domain.CustomerSortLastVisit}
+
+func parseOptionalInt(value string, name string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s query parameter: %s", name, err.Error())
+	}
+
+	return &i, nil
+}
+
+func mapToGetTagsResp(in []domain.CustomerTag) []getTagsResp {
+	out := make([]getTagsResp, len(in))
+
+	for i, t := range in {
+		out[i] = getTagsResp{
+			Tag:   t.Tag,
+			Count: t.Count,
+		}
+	}
+
+	return out
+}
+
+func mapToCustomFieldInput(in customFieldReq) customerServ.CustomFieldInput {
+	return customerServ.CustomFieldInput{
+		Name:       in.Name,
+		FieldType:  in.FieldType,
+		Options:    in.Options,
+		IsRequired: in.IsRequired,
+	}
+}
+
+func mapToGetCustomFieldsResp(in []domain.CustomerField) []getCustomFieldsResp {
+	out := make([]getCustomFieldsResp, len(in))
+
+	for i, f := range in {
+		out[i] = getCustomFieldsResp{
+			Id:         f.Id,
+			Name:       f.Name,
+			FieldType:  f.FieldType,
+			Options:    f.Options,
+			IsRequired: f.IsRequired,
+		}
+	}
+
+	return out
+}
+
+func mapToCustomerFilter(in customerFilterReq) domain.CustomerFilter {
+	return domain.CustomerFilter{
+		Search:            in.Search,
+		IsBlacklisted:     in.IsBlacklisted,
+		Tags:              in.Tags,
+		NoVisitInDays:     in.NoVisitInDays,
+		BirthdayThisMonth: in.BirthdayThisMonth,
+		MinNoShows:        in.MinNoShows,
+		MinTimesBooked:    in.MinTimesBooked,
+		CustomFields:      in.CustomFields,
+	}
+}
+
+func mapToSegmentInput(in segmentReq) customerServ.SegmentInput {
+	return customerServ.SegmentInput{
+		Name:   in.Name,
+		Filter: mapToCustomerFilter(in.Filter),
+	}
+}
+
+func mapToGetSegmentsResp(in []domain.CustomerSegment) []getSegmentsResp {
+	out := make([]getSegmentsResp, len(in))
+
+	for i, s := range in {
+		out[i] = getSegmentsResp{
+			Id:        s.Id,
+			Name:      s.Name,
+			Filter:    mapToCustomerFilterResp(s.Filter),
+			CreatedAt: s.CreatedAt,
+		}
+	}
+
+	return out
+}
+
+func mapToCustomerFilterResp(in domain.CustomerFilter) customerFilterReq {
+	tags := in.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return customerFilterReq{
+		Search:            in.Search,
+		IsBlacklisted:     in.IsBlacklisted,
+		Tags:              tags,
+		NoVisitInDays:     in.NoVisitInDays,
+		BirthdayThisMonth: in.BirthdayThisMonth,
+		MinNoShows:        in.MinNoShows,
+		MinTimesBooked:    in.MinTimesBooked,
+		CustomFields:      in.CustomFields,
+	}
+}
+
+func mapToConsentInput(in consentReq) customerServ.ConsentInput {
+	return customerServ.ConsentInput{
+		Channel:   in.Channel,
+		IsGranted: *in.IsGranted,
+	}
+}
+
+func mapToConsentsResp(in []domain.MarketingConsent) []consentResp {
+	out := make([]consentResp, len(in))
+
+	for i, c := range in {
+		out[i] = consentResp{
+			Channel:    c.Channel,
+			IsGranted:  c.IsGranted,
+			Source:     c.Source,
+			EmployeeId: c.EmployeeId,
+			UpdatedAt:  c.UpdatedAt,
+		}
+	}
+
+	return out
+}
+
+func mapToCampaignInput(in newCampaignReq) customerServ.CampaignInput {
+	return customerServ.CampaignInput{
+		Name:      in.Name,
+		Subject:   in.Subject,
+		Body:      in.Body,
+		Filter:    mapToCustomerFilter(in.Filter),
+		SegmentId: in.SegmentId,
+	}
+}
+
+func mapToCampaignResp(in domain.CampaignWithStats) campaignResp {
+	return campaignResp{
+		Id:             in.Id,
+		EmployeeId:     in.EmployeeId,
+		Name:           in.Name,
+		Subject:        in.Subject,
+		Body:           in.Body,
+		Status:         in.Status,
+		Filter:         mapToCustomerFilterResp(in.Filter),
+		RecipientCount: in.RecipientCount,
+		PendingCount:   in.PendingCount,
+		SentCount:      in.SentCount,
+		SkippedCount:   in.SkippedCount,
+		FailedCount:    in.FailedCount,
+		CreatedAt:      in.CreatedAt,
+		FinishedAt:     in.FinishedAt,
+	}
+}
+
+func mapToCampaignsResp(in []domain.CampaignWithStats) []campaignResp {
+	out := make([]campaignResp, len(in))
+
+	for i, c := range in {
+		out[i] = mapToCampaignResp(c)
+	}
+
+	return out
+}
+
+func mapToPolicyRuleInput(in policyRuleReq) customerServ.PolicyRuleInput {
+	return customerServ.PolicyRuleInput{
+		Event:      in.Event,
+		Threshold:  in.Threshold,
+		PeriodDays: in.PeriodDays,
+		Action:     in.Action,
+	}
+}
+
+func mapToPolicyRulesResp(in []domain.CustomerPolicyRule) []policyRuleResp {
+	out := make([]policyRuleResp, len(in))
+
+	for i, r := range in {
+		out[i] = policyRuleResp{
+			Id:         r.Id,
+			Event:      r.Event,
+			Threshold:  r.Threshold,
+			PeriodDays: r.PeriodDays,
+			Action:     r.Action,
+			CreatedAt:  r.CreatedAt,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,145 @@
+package customers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+	"github.com/miketsu-inc/reservations/backend/pkg/xlsx"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: uuid.UUID{}}}
+	intIdParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	filterParams := []openapi.Param{
+		{Name: "search", In: openapi.InQuery, Description: "Part of the name, email or phone number"},
+		{Name: "blacklisted", In: openapi.InQuery, Type: false, Description: "false by default"},
+		{Name: "tag", In: openapi.InQuery, Description: "Can be repeated, the customers have every tag"},
+		{Name: "segment_id", In: openapi.InQuery, Type: 0, Description: "The other filters are combined with the segment's"},
+		{Name: "no_visit_in_days", In: openapi.InQuery, Type: 0},
+		{Name: "birthday_this_month", In: openapi.InQuery, Type: false},
+		{Name: "min_no_shows", In: openapi.InQuery, Type: 0},
+		{Name: "min_times_booked", In: openapi.InQuery, Type: 0},
+		{Name: "field_{id}", In: openapi.InQuery, Description: "Replace {id} with the id of a custom field to filter by its value"},
+		{Name: "sort", In: openapi.InQuery, Enum: sortFields},
+		{Name: "order", In: openapi.InQuery, Enum: []string{"asc", "desc"}},
+	}
+
+	listParams := append(slices.Clone(filterParams),
+		openapi.Param{Name: "limit", In: openapi.InQuery, Type: 0, Description: "Every customer is returned if not set"},
+		openapi.Param{Name: "offset", In: openapi.InQuery, Type: 0},
+	)
+
+	return openapi.WithTag("Customers",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get a page of the customers matching the filters", Response: getAllPageResp{}, Params: listParams},
+		openapi.Operation{Handler: h.GetAllExternal, Summary: "Get the customers matching the filters", Response: []getAllResp{}, Params: listParams},
+		openapi.Operation{Handler: h.GetAllBlacklisted, Summary: "Get the blacklisted customers of the merchant", Response: []getAllResp{}},
+		openapi.Operation{Handler: h.Get, Summary: "Get a customer", Response: getResp{}, Params: idParam},
+		openapi.Operation{Handler: h.GetStats, Summary: "Get the statistics and bookings of a customer", Response: getStatsResp{}, Params: idParam},
+		openapi.Operation{Handler: h.New, Summary: "Create a customer", Request: newReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a customer", Request: updateReq{}, Params: idParam},
+		openapi.Operation{
+			Handler: h.Delete,
+			Summary: "Delete a customer or erase its personal data",
+			Params: append(slices.Clone(idParam), openapi.Param{
+				Name:        "mode",
+				In:          openapi.InQuery,
+				Enum:        []string{"delete", "anonymize"},
+				Description: "delete removes the customer with its bookings, anonymize keeps the bookings for the statistics. delete by default",
+			}),
+		},
+		openapi.Operation{Handler: h.Blacklist, Summary: "Blacklist a customer", Request: blacklistReq{}, Params: idParam},
+		openapi.Operation{Handler: h.UnBlacklist, Summary: "Remove a customer from the blacklist", Params: idParam},
+		openapi.Operation{Handler: h.TransferBookings, Summary: "Move the bookings of a customer to another one", Request: transferBookingsReq{}},
+		openapi.Operation{Handler: h.FindDuplicates, Summary: "Find customers who are probably the same person", Response: []findDuplicatesResp{}},
+		openapi.Operation{
+			Handler: h.Merge,
+			Summary: "Merge a duplicate into the customer, moving its bookings and deleting it",
+			Request: mergeReq{},
+			Params:  idParam,
+		},
+		openapi.Operation{Handler: h.GetTags, Summary: "Get the tags used by the merchant's customers", Response: []getTagsResp{}},
+		openapi.Operation{Handler: h.GetCustomFields, Summary: "Get the custom fields of the customers", Response: []getCustomFieldsResp{}},
+		openapi.Operation{
+			Handler:  h.NewCustomField,
+			Summary:  "Create a custom field",
+			Request:  customFieldReq{},
+			Response: newCustomFieldResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{
+			Handler: h.UpdateCustomField,
+			Summary: "Update a custom field, its type can not be changed",
+			Request: customFieldReq{},
+			Params:  intIdParam,
+		},
+		openapi.Operation{Handler: h.DeleteCustomField, Summary: "Delete a custom field and its values", Params: intIdParam},
+		openapi.Operation{Handler: h.GetSegments, Summary: "Get the saved customer segments", Response: []getSegmentsResp{}},
+		openapi.Operation{
+			Handler:  h.NewSegment,
+			Summary:  "Save a customer segment",
+			Request:  segmentReq{},
+			Response: newSegmentResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{Handler: h.UpdateSegment, Summary: "Update a customer segment", Request: segmentReq{}, Params: intIdParam},
+		openapi.Operation{Handler: h.DeleteSegment, Summary: "Delete a customer segment", Params: intIdParam},
+		openapi.Operation{Handler: h.GetMerges, Summary: "Get the customers merged into a customer", Response: []getMergesResp{}, Params: idParam},
+		openapi.Operation{
+			Handler: h.Export,
+			Summary: "Download the customers as a spreadsheet",
+			Files:   []string{"text/csv", xlsx.ContentType},
+			Params: append([]openapi.Param{
+				{Name: "format", In: openapi.InQuery, Enum: []string{"csv", "xlsx"}, Description: "csv by default"},
+			}, filterParams...),
+		},
+		openapi.Operation{
+			Handler:  h.PreviewImport,
+			Summary:  "Check what an import would do without creating any customer",
+			Request:  importReq{},
+			Response: previewImportResp{},
+		},
+		openapi.Operation{
+			Handler:  h.StartImport,
+			Summary:  "Import the customers of a csv file in the background",
+			Request:  importReq{},
+			Response: startImportResp{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Operation{
+			Handler:  h.GetImport,
+			Summary:  "Get the progress of an import",
+			Response: getImportResp{},
+			Params:   intIdParam,
+		},
+		openapi.Operation{Handler: h.GetConsents, Summary: "Get the marketing consents of a customer", Response: []consentResp{}, Params: idParam},
+		openapi.Operation{Handler: h.SetConsent, Summary: "Grant or withdraw a marketing consent of a customer", Request: consentReq{}, Params: idParam},
+		openapi.Operation{Handler: h.GetCampaigns, Summary: "Get the email campaigns of the merchant", Response: []campaignResp{}},
+		openapi.Operation{Handler: h.GetCampaign, Summary: "Get an email campaign with its delivery statistics", Response: campaignResp{}, Params: intIdParam},
+		openapi.Operation{
+			Handler:  h.NewCampaign,
+			Summary:  "Send an email campaign in the background to the consenting customers matching the filter",
+			Request:  newCampaignReq{},
+			Response: newCampaignResp{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Operation{Handler: h.CancelCampaign, Summary: "Stop sending the remaining emails of a campaign", Params: intIdParam},
+		openapi.Operation{
+			Handler: h.RemoveApprovalRequirement,
+			Summary: "Let the bookings of a customer flagged by a policy rule follow the approval policy again",
+			Params:  idParam,
+		},
+		openapi.Operation{Handler: h.GetPolicyRules, Summary: "Get the no-show and cancellation policy rules", Response: []policyRuleResp{}},
+		openapi.Operation{
+			Handler:  h.NewPolicyRule,
+			Summary:  "Create a policy rule which restricts customers after too many no-shows or cancellations",
+			Request:  policyRuleReq{},
+			Response: newPolicyRuleResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{Handler: h.UpdatePolicyRule, Summary: "Update a policy rule", Request: policyRuleReq{}, Params: intIdParam},
+		openapi.Operation{Handler: h.DeletePolicyRule, Summary: "Delete a policy rule", Params: intIdParam},
+	)
+}
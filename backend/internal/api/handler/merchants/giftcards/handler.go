@@ -0,0 +1,156 @@
+package giftcards
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	giftCardServ "github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *giftCardServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *giftCardServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+// Every employee can redeem gift cards, issuing them and seeing their codes needs the gift_cards.manage permission.
+// The ledger records who issued and redeemed them
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/redeem", h.Redeem)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionGiftCardsView, types.PermissionGiftCardsManage))
+
+		r.Get("/", h.GetAll)
+		r.Get("/{id}", h.Get)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionGiftCardsManage))
+
+		r.Post("/", h.Issue)
+	})
+
+	return r
+}
+
+type giftCardResp struct {
+	Id int `json:"id"`
+	// nil without the gift_cards.manage permission
+	Code           *string                  `json:"code"`
+	InitialValue   currencyx.FormattedPrice `json:"initial_value"`
+	Balance        currencyx.FormattedPrice `json:"balance"`
+	ExpiresAt      *time.Time               `json:"expires_at"`
+	IsExpired      bool                     `json:"is_expired"`
+	IssuedBy       *int                     `json:"issued_by"`
+	RecipientName  *string                  `json:"recipient_name"`
+	RecipientEmail *string                  `json:"recipient_email"`
+	Message        *string                  `json:"message"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	giftCards, err := h.service.GetGiftCards(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGiftCardsResp(giftCards, lang.LangFromContext(r.Context()), time.Now()))
+}
+
+type issueReq struct {
+	Value          currencyx.Price `json:"value"`
+	ExpiresAt      *time.Time      `json:"expires_at"`
+	RecipientName  *string         `json:"recipient_name" validate:"omitempty,max=60"`
+	RecipientEmail *string         `json:"recipient_email" validate:"omitempty,email"`
+	Message        *string         `json:"message" validate:"omitempty,max=300"`
+}
+
+func (h *Handler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req issueReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	giftCard, err := h.service.Issue(r.Context(), mapToIssueInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, mapToGiftCardResp(giftCard, lang.LangFromContext(r.Context()), time.Now()))
+}
+
+type transactionResp struct {
+	Id         int                      `json:"id"`
+	Amount     currencyx.FormattedPrice `json:"amount"`
+	BookingId  *int                     `json:"booking_id"`
+	EmployeeId *int                     `json:"employee_id"`
+	CreatedAt  time.Time                `json:"created_at"`
+}
+
+type getResp struct {
+	giftCardResp
+	Transactions []transactionResp `json:"transactions"`
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	urlGiftCardId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid gift card id provided"))
+		return
+	}
+
+	giftCard, err := h.service.GetGiftCard(r.Context(), urlGiftCardId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetResp(giftCard, lang.LangFromContext(r.Context()), time.Now()))
+}
+
+type redeemReq struct {
+	Code      string `json:"code" validate:"required"`
+	BookingId int    `json:"booking_id" validate:"required"`
+	// as much as the balance and the booking allow if it's missing
+	Amount *currencyx.Price `json:"amount"`
+}
+
+type redeemResp struct {
+	Redeemed currencyx.FormattedPrice `json:"redeemed"`
+}
+
+func (h *Handler) Redeem(w http.ResponseWriter, r *http.Request) {
+	var req redeemReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	redeemed, err := h.service.Redeem(r.Context(), mapToRedeemInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, redeemResp{Redeemed: redeemed.ToFormatted(lang.LangFromContext(r.Context()))})
+}
@@ -0,0 +1,75 @@
+package giftcards
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	giftCardServ "github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"golang.org/x/text/language"
+)
+
+func mapToIssueInput(req issueReq) giftCardServ.IssueInput {
+	return giftCardServ.IssueInput{
+		Value:          req.Value,
+		ExpiresAt:      req.ExpiresAt,
+		RecipientName:  req.RecipientName,
+		RecipientEmail: req.RecipientEmail,
+		Message:        req.Message,
+	}
+}
+
+func mapToRedeemInput(req redeemReq) giftCardServ.RedeemInput {
+	return giftCardServ.RedeemInput{
+		Code:      req.Code,
+		BookingId: req.BookingId,
+		Amount:    req.Amount,
+	}
+}
+
+func mapToGiftCardResp(giftCard domain.GiftCard, locale language.Tag, now time.Time) giftCardResp {
+	var code *string
+	if giftCard.Code != "" {
+		code = &giftCard.Code
+	}
+
+	return giftCardResp{
+		Id:             giftCard.Id,
+		Code:           code,
+		InitialValue:   giftCard.InitialValue.ToFormatted(locale),
+		Balance:        giftCard.Balance.ToFormatted(locale),
+		ExpiresAt:      giftCard.ExpiresAt,
+		IsExpired:      giftCard.IsExpired(now),
+		IssuedBy:       giftCard.IssuedBy,
+		RecipientName:  giftCard.RecipientName,
+		RecipientEmail: giftCard.RecipientEmail,
+		Message:        giftCard.Message,
+		CreatedAt:      giftCard.CreatedAt,
+	}
+}
+
+func mapToGiftCardsResp(giftCards []domain.GiftCard, locale language.Tag, now time.Time) []giftCardResp {
+	result := make([]giftCardResp, len(giftCards))
+	for i, giftCard := range giftCards {
+		result[i] = mapToGiftCardResp(giftCard, locale, now)
+	}
+
+	return result
+}
+
+func mapToGetResp(giftCard giftCardServ.GiftCardWithTransactions, locale language.Tag, now time.Time) getResp {
+	transactions := make([]transactionResp, len(giftCard.Transactions))
+	for i, transaction := range giftCard.Transactions {
+		transactions[i] = transactionResp{
+			Id:         transaction.Id,
+			Amount:     transaction.Amount.ToFormatted(locale),
+			BookingId:  transaction.BookingId,
+			EmployeeId: transaction.EmployeeId,
+			CreatedAt:  transaction.CreatedAt,
+		}
+	}
+
+	return getResp{
+		giftCardResp: mapToGiftCardResp(giftCard.GiftCard, locale, now),
+		Transactions: transactions,
+	}
+}
@@ -0,0 +1,29 @@
+package giftcards
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Gift cards",
+		openapi.Operation{Handler: h.GetAll, Summary: "List the gift cards of the merchant, the latest first", Response: []giftCardResp{}},
+		openapi.Operation{
+			Handler:  h.Issue,
+			Summary:  "Issue a gift card sold over the counter, it is emailed to the recipient if they have an email",
+			Request:  issueReq{},
+			Response: giftCardResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{
+			Handler:  h.Redeem,
+			Summary:  "Pay a part of the total price of a booking with a gift card",
+			Request:  redeemReq{},
+			Response: redeemResp{},
+		},
+		openapi.Operation{Handler: h.Get, Summary: "Get a gift card with its ledger", Response: getResp{}, Params: idParam},
+	)
+}
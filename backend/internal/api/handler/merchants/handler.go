@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
 	externalcalendarServ "github.com/miketsu-inc/reservations/backend/internal/service/externalcalendar"
 	merchantServ "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
@@ -29,11 +30,12 @@ func NewHandler(s *merchantServ.Service, extcalendarServ *externalcalendarServ.S
 }
 
 type meResp struct {
-	UserId     uuid.UUID          `json:"user_id"`
-	MerchantId uuid.UUID          `json:"merchant_id"`
-	LocationId int                `json:"location_id"`
-	EmployeeId int                `json:"employee_id"`
-	Role       types.EmployeeRole `json:"role"`
+	UserId      uuid.UUID          `json:"user_id"`
+	MerchantId  uuid.UUID          `json:"merchant_id"`
+	LocationId  int                `json:"location_id"`
+	EmployeeId  int                `json:"employee_id"`
+	Role        types.EmployeeRole `json:"role"`
+	Permissions []types.Permission `json:"permissions"`
 }
 
 func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
@@ -69,12 +71,26 @@ func (h *Handler) UpdateName(w http.ResponseWriter, r *http.Request) {
 }
 
 type getDashboardResp struct {
-	PeriodStart      time.Time               `json:"period_start"`
-	PeriodEnd        time.Time               `json:"period_end"`
-	UpcomingBookings []bookingDetailsResp    `json:"upcoming_bookings"`
-	LatestBookings   []bookingDetailsResp    `json:"latest_bookings"`
-	LowStockProducts []lowStockProductResp   `json:"low_stock_products"`
-	Statistics       dashboardStatisticsResp `json:"statistics"`
+	PeriodStart      time.Time                `json:"period_start"`
+	PeriodEnd        time.Time                `json:"period_end"`
+	UpcomingBookings []bookingDetailsResp     `json:"upcoming_bookings"`
+	LatestBookings   []bookingDetailsResp     `json:"latest_bookings"`
+	LowStockProducts []lowStockProductResp    `json:"low_stock_products"`
+	Statistics       dashboardStatisticsResp  `json:"statistics"`
+	Utilization      dashboardUtilizationResp `json:"utilization"`
+}
+
+type dashboardEmployeeUtilizationResp struct {
+	EmployeeId  int    `json:"employee_id"`
+	Name        string `json:"name"`
+	Utilization int    `json:"utilization"`
+}
+
+type dashboardUtilizationResp struct {
+	Utilization    int                                `json:"utilization"`
+	RevenuePerHour currencyx.FormattedPrice           `json:"revenue_per_hour"`
+	IdleGapMinutes int                                `json:"idle_gap_minutes"`
+	Employees      []dashboardEmployeeUtilizationResp `json:"employees"`
 }
 
 type bookingDetailsResp struct {
@@ -103,15 +119,23 @@ type lowStockProductResp struct {
 }
 
 type dashboardStatisticsResp struct {
-	Revenue               []revenueStatResp `json:"revenue"`
-	RevenueSum            string            `json:"revenue_sum"`
-	RevenueChange         int               `json:"revenue_change"`
-	Bookings              int               `json:"bookings"`
-	BookingsChange        int               `json:"bookings_change"`
-	Cancellations         int               `json:"cancellations"`
-	CancellationsChange   int               `json:"cancellations_change"`
-	AverageDuration       int               `json:"average_duration"`
-	AverageDurationChange int               `json:"average_duration_change"`
+	Revenue               []revenueStatResp        `json:"revenue"`
+	RevenueSum            currencyx.FormattedPrice `json:"revenue_sum"`
+	RevenueChange         int                      `json:"revenue_change"`
+	Taxes                 []taxStatResp            `json:"taxes"`
+	Bookings              int                      `json:"bookings"`
+	BookingsChange        int                      `json:"bookings_change"`
+	Cancellations         int                      `json:"cancellations"`
+	CancellationsChange   int                      `json:"cancellations_change"`
+	AverageDuration       int                      `json:"average_duration"`
+	AverageDurationChange int                      `json:"average_duration_change"`
+}
+
+// Tax included in the revenue of the period per tax rate
+type taxStatResp struct {
+	Name    string                   `json:"name"`
+	Percent string                   `json:"percent"`
+	Amount  currencyx.FormattedPrice `json:"amount"`
 }
 
 // TODO: value is of numeric type so float might not be the best
@@ -140,7 +164,7 @@ func (h *Handler) GetDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetDashboardResp(dashboard))
+	httputil.Success(w, http.StatusOK, mapToGetDashboardResp(dashboard, lang.LangFromContext(r.Context())))
 }
 
 type checkUrlReq struct {
@@ -355,7 +379,7 @@ func (h *Handler) GetServicesForCalendar(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetServicesForCalendarResp(services))
+	httputil.Success(w, http.StatusOK, mapToGetServicesForCalendarResp(services, lang.LangFromContext(r.Context())))
 }
 
 type getCustomersForCalendarResp struct {
@@ -433,7 +457,7 @@ func (h *Handler) GetCalendarEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetCalendarEventsResp(bookings))
+	httputil.Success(w, http.StatusOK, mapToGetCalendarEventsResp(bookings, lang.LangFromContext(r.Context())))
 }
 
 func (h *Handler) GoogleCalendar(w http.ResponseWriter, r *http.Request) {
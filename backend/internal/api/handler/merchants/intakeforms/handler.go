@@ -0,0 +1,195 @@
+package intakeforms
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	intakeServ "github.com/miketsu-inc/reservations/backend/internal/service/intake"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *intakeServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *intakeServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
+
+		r.Get("/", h.GetAll)
+		r.Post("/", h.New)
+		r.Get("/{id}", h.Get)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+
+	r.With(h.middleware.RequirePermission(types.PermissionCustomersView)).Get("/responses", h.GetResponses)
+
+	return r
+}
+
+type intakeConditionReq struct {
+	QuestionId string   `json:"question_id"`
+	Values     []string `json:"values"`
+}
+
+type intakeQuestionReq struct {
+	Id         string                   `json:"id"`
+	Label      string                   `json:"label"`
+	Type       types.IntakeQuestionType `json:"type"`
+	IsRequired bool                     `json:"is_required"`
+	// only for single and multiple choice questions
+	Options []string `json:"options"`
+	// the question is only shown if the answer to an earlier yes/no or choice question is one of the values
+	VisibleIf *intakeConditionReq `json:"visible_if"`
+}
+
+type intakeFormReq struct {
+	Name       string               `json:"name" validate:"required,max=50"`
+	Mode       types.IntakeFormMode `json:"mode" validate:"required"`
+	Questions  []intakeQuestionReq  `json:"questions" validate:"required"`
+	ServiceIds []int                `json:"service_ids"`
+}
+
+type newIntakeFormResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
+	var req intakeFormReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	formId, err := h.service.NewForm(r.Context(), mapToFormInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newIntakeFormResp{Id: formId})
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req intakeFormReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	formId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid intake form id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.UpdateForm(r.Context(), formId, mapToFormInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	formId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid intake form id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeleteForm(r.Context(), formId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+type intakeFormResp struct {
+	Id         int                     `json:"id"`
+	Name       string                  `json:"name"`
+	Mode       types.IntakeFormMode    `json:"mode"`
+	Questions  []domain.IntakeQuestion `json:"questions"`
+	ServiceIds []int                   `json:"service_ids"`
+	CreatedAt  time.Time               `json:"created_at"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	formId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid intake form id: %s", err.Error()))
+		return
+	}
+
+	form, err := h.service.GetForm(r.Context(), formId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("intake form not found: %s", err.Error()))
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToIntakeFormResp(form))
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	forms, err := h.service.GetForms(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]intakeFormResp, len(forms))
+	for i, form := range forms {
+		resp[i] = mapToIntakeFormResp(form)
+	}
+
+	httputil.Success(w, http.StatusOK, resp)
+}
+
+type intakeResponseResp struct {
+	Id          int       `json:"id"`
+	FormId      *int      `json:"form_id"`
+	BookingId   int       `json:"booking_id"`
+	ServiceName string    `json:"service_name"`
+	FromDate    time.Time `json:"from_date"`
+	// the form as it was when the customer answered it
+	FormName  string                  `json:"form_name"`
+	Questions []domain.IntakeQuestion `json:"questions"`
+	Answers   map[string]any          `json:"answers"`
+	Signature *string                 `json:"signature"`
+	SignedAt  *time.Time              `json:"signed_at"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+func (h *Handler) GetResponses(w http.ResponseWriter, r *http.Request) {
+	customerId, err := uuid.Parse(r.URL.Query().Get("customer_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	responses, err := h.service.GetCustomerResponses(r.Context(), customerId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToIntakeResponsesResp(responses))
+}
@@ -0,0 +1,75 @@
+package intakeforms
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	intakeServ "github.com/miketsu-inc/reservations/backend/internal/service/intake"
+)
+
+func mapToFormInput(in intakeFormReq) intakeServ.FormInput {
+	questions := make([]domain.IntakeQuestion, len(in.Questions))
+
+	for i, q := range in.Questions {
+		var visibleIf *domain.IntakeCondition
+		if q.VisibleIf != nil {
+			visibleIf = &domain.IntakeCondition{
+				QuestionId: q.VisibleIf.QuestionId,
+				Values:     q.VisibleIf.Values,
+			}
+		}
+
+		questions[i] = domain.IntakeQuestion{
+			Id:         q.Id,
+			Label:      q.Label,
+			Type:       q.Type,
+			IsRequired: q.IsRequired,
+			Options:    q.Options,
+			VisibleIf:  visibleIf,
+		}
+	}
+
+	serviceIds := in.ServiceIds
+	if serviceIds == nil {
+		serviceIds = []int{}
+	}
+
+	return intakeServ.FormInput{
+		Name:       in.Name,
+		Mode:       in.Mode,
+		Questions:  questions,
+		ServiceIds: serviceIds,
+	}
+}
+
+func mapToIntakeFormResp(in domain.IntakeForm) intakeFormResp {
+	return intakeFormResp{
+		Id:         in.Id,
+		Name:       in.Name,
+		Mode:       in.Mode,
+		Questions:  in.Questions,
+		ServiceIds: in.ServiceIds,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+}
+
+func mapToIntakeResponsesResp(in []domain.CustomerIntakeResponse) []intakeResponseResp {
+	out := make([]intakeResponseResp, len(in))
+
+	for i, r := range in {
+		out[i] = intakeResponseResp{
+			Id:          r.Id,
+			FormId:      r.FormId,
+			BookingId:   r.BookingId,
+			ServiceName: r.ServiceName,
+			FromDate:    r.FromDate,
+			FormName:    r.FormName,
+			Questions:   r.Questions,
+			Answers:     r.Answers,
+			Signature:   r.Signature,
+			SignedAt:    r.SignedAt,
+			CreatedAt:   r.CreatedAt,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,37 @@
+package intakeforms
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Intake forms",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the intake forms of the merchant", Response: []intakeFormResp{}},
+		openapi.Operation{
+			Handler:  h.New,
+			Summary:  "Create an intake form and attach it to services, they are detached from their previous form",
+			Request:  intakeFormReq{},
+			Response: newIntakeFormResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{Handler: h.Get, Summary: "Get an intake form", Response: intakeFormResp{}, Params: idParam},
+		openapi.Operation{
+			Handler: h.Update,
+			Summary: "Update an intake form, the answers given before keep the questions they were answered to",
+			Request: intakeFormReq{},
+			Params:  idParam,
+		},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete an intake form, its responses are kept", Params: idParam},
+		openapi.Operation{
+			Handler:  h.GetResponses,
+			Summary:  "Get the intake form responses of a customer from the bookings the employee can see",
+			Response: []intakeResponseResp{},
+			Params:   []openapi.Param{{Name: "customer_id", In: openapi.InQuery, Type: uuid.UUID{}, Required: true}},
+		},
+	)
+}
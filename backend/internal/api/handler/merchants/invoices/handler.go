@@ -0,0 +1,178 @@
+package invoices
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	invoiceServ "github.com/miketsu-inc/reservations/backend/internal/service/invoice"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/pdf"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *invoiceServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *invoiceServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionReportsView))
+
+	r.Get("/settings", h.GetSettings)
+	r.With(h.middleware.RequirePermission(types.PermissionSettingsManage)).Put("/settings", h.UpdateSettings)
+
+	r.Get("/", h.GetInvoices)
+	r.Get("/{id}", h.GetInvoice)
+	r.Get("/{id}/download", h.Download)
+
+	return r
+}
+
+const dateLayout = "2006-01-02"
+
+type invoiceSettingsResp struct {
+	MerchantName       string  `json:"merchant_name"`
+	ContactEmail       string  `json:"contact_email"`
+	LegalName          *string `json:"legal_name"`
+	TaxNumber          *string `json:"tax_number"`
+	RegistrationNumber *string `json:"registration_number"`
+	Address            *string `json:"address"`
+	Prefix             string  `json:"prefix"`
+	Footer             *string `json:"footer"`
+	NextSequence       int     `json:"next_sequence"`
+}
+
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.GetSettings(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToInvoiceSettingsResp(settings))
+}
+
+type updateSettingsReq struct {
+	LegalName          *string `json:"legal_name" validate:"omitempty,max=100"`
+	TaxNumber          *string `json:"tax_number" validate:"omitempty,max=30"`
+	RegistrationNumber *string `json:"registration_number" validate:"omitempty,max=30"`
+	Address            *string `json:"address" validate:"omitempty,max=200"`
+	Prefix             string  `json:"prefix" validate:"required,max=10"`
+	Footer             *string `json:"footer" validate:"omitempty,max=300"`
+}
+
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req updateSettingsReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err := h.service.UpdateSettings(r.Context(), mapToUpdateSettingsInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type invoiceResp struct {
+	Id              int                      `json:"id"`
+	InvoiceNumber   string                   `json:"invoice_number"`
+	BookingId       *int                     `json:"booking_id"`
+	ParticipantId   *int                     `json:"participant_id"`
+	IssuedAt        time.Time                `json:"issued_at"`
+	ServiceDate     time.Time                `json:"service_date"`
+	BuyerName       string                   `json:"buyer_name"`
+	BuyerEmail      *string                  `json:"buyer_email"`
+	NetTotal        currencyx.FormattedPrice `json:"net_total"`
+	TaxTotal        currencyx.FormattedPrice `json:"tax_total"`
+	Total           currencyx.FormattedPrice `json:"total"`
+	EmailedAt       *time.Time               `json:"emailed_at"`
+	SellerName      string                   `json:"seller_name"`
+	SellerTaxNumber *string                  `json:"seller_tax_number"`
+}
+
+func (h *Handler) GetInvoices(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToGetInvoicesInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	invoices, err := h.service.GetInvoices(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToInvoicesResp(invoices, lang.LangFromContext(r.Context())))
+}
+
+type invoiceLineResp struct {
+	Description string                   `json:"description"`
+	Quantity    int                      `json:"quantity"`
+	UnitPrice   currencyx.FormattedPrice `json:"unit_price"`
+	NetAmount   currencyx.FormattedPrice `json:"net_amount"`
+	TaxName     *string                  `json:"tax_name"`
+	TaxPercent  *string                  `json:"tax_percent"`
+	TaxAmount   currencyx.FormattedPrice `json:"tax_amount"`
+	GrossAmount currencyx.FormattedPrice `json:"gross_amount"`
+}
+
+type getInvoiceResp struct {
+	invoiceResp
+	SellerRegistrationNumber *string           `json:"seller_registration_number"`
+	SellerAddress            *string           `json:"seller_address"`
+	SellerEmail              string            `json:"seller_email"`
+	Footer                   *string           `json:"footer"`
+	Lines                    []invoiceLineResp `json:"lines"`
+}
+
+func (h *Handler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	urlInvoiceId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid invoice id provided"))
+		return
+	}
+
+	invoice, err := h.service.GetInvoice(r.Context(), urlInvoiceId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetInvoiceResp(invoice, lang.LangFromContext(r.Context())))
+}
+
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	urlInvoiceId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid invoice id provided"))
+		return
+	}
+
+	invoice, file, err := h.service.DownloadInvoice(r.Context(), urlInvoiceId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", pdf.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoiceServ.FileName(invoice.InvoiceNumber)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file) // nolint:errcheck
+}
@@ -0,0 +1,105 @@
+package invoices
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	invoiceServ "github.com/miketsu-inc/reservations/backend/internal/service/invoice"
+	"golang.org/x/text/language"
+)
+
+func mapToInvoiceSettingsResp(settings domain.InvoiceSettings) invoiceSettingsResp {
+	return invoiceSettingsResp{
+		MerchantName:       settings.MerchantName,
+		ContactEmail:       settings.ContactEmail,
+		LegalName:          settings.LegalName,
+		TaxNumber:          settings.TaxNumber,
+		RegistrationNumber: settings.RegistrationNumber,
+		Address:            settings.Address,
+		Prefix:             settings.Prefix,
+		Footer:             settings.Footer,
+		NextSequence:       settings.NextSequence,
+	}
+}
+
+func mapToUpdateSettingsInput(req updateSettingsReq) invoiceServ.UpdateSettingsInput {
+	return invoiceServ.UpdateSettingsInput{
+		LegalName:          req.LegalName,
+		TaxNumber:          req.TaxNumber,
+		RegistrationNumber: req.RegistrationNumber,
+		Address:            req.Address,
+		Prefix:             req.Prefix,
+		Footer:             req.Footer,
+	}
+}
+
+func mapToGetInvoicesInput(r *http.Request) (invoiceServ.GetInvoicesInput, error) {
+	query := r.URL.Query()
+
+	start, err := time.Parse(dateLayout, query.Get("start"))
+	if err != nil {
+		return invoiceServ.GetInvoicesInput{}, fmt.Errorf("invalid start: %s", err.Error())
+	}
+
+	end, err := time.Parse(dateLayout, query.Get("end"))
+	if err != nil {
+		return invoiceServ.GetInvoicesInput{}, fmt.Errorf("invalid end: %s", err.Error())
+	}
+
+	return invoiceServ.GetInvoicesInput{Start: start, End: end}, nil
+}
+
+func mapToInvoiceResp(invoice domain.Invoice, locale language.Tag) invoiceResp {
+	return invoiceResp{
+		Id:              invoice.Id,
+		InvoiceNumber:   invoice.InvoiceNumber,
+		BookingId:       invoice.BookingId,
+		ParticipantId:   invoice.ParticipantId,
+		IssuedAt:        invoice.IssuedAt,
+		ServiceDate:     invoice.ServiceDate,
+		BuyerName:       invoice.BuyerName,
+		BuyerEmail:      invoice.BuyerEmail,
+		NetTotal:        invoice.NetTotal.ToFormatted(locale),
+		TaxTotal:        invoice.TaxTotal.ToFormatted(locale),
+		Total:           invoice.Total.ToFormatted(locale),
+		EmailedAt:       invoice.EmailedAt,
+		SellerName:      invoice.SellerName,
+		SellerTaxNumber: invoice.SellerTaxNumber,
+	}
+}
+
+func mapToInvoicesResp(invoices []domain.Invoice, locale language.Tag) []invoiceResp {
+	result := make([]invoiceResp, len(invoices))
+	for i, invoice := range invoices {
+		result[i] = mapToInvoiceResp(invoice, locale)
+	}
+
+	return result
+}
+
+func mapToGetInvoiceResp(invoice invoiceServ.InvoiceWithLines, locale language.Tag) getInvoiceResp {
+	lines := make([]invoiceLineResp, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = invoiceLineResp{
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice.ToFormatted(locale),
+			NetAmount:   line.NetAmount.ToFormatted(locale),
+			TaxName:     line.TaxName,
+			TaxPercent:  line.TaxPercent,
+			TaxAmount:   line.TaxAmount.ToFormatted(locale),
+			GrossAmount: line.GrossAmount.ToFormatted(locale),
+		}
+	}
+
+	return getInvoiceResp{
+		invoiceResp:              mapToInvoiceResp(invoice.Invoice, locale),
+		SellerRegistrationNumber: invoice.SellerRegistrationNumber,
+		SellerAddress:            invoice.SellerAddress,
+		SellerEmail:              invoice.SellerEmail,
+		Footer:                   invoice.Footer,
+		Lines:                    lines,
+	}
+}
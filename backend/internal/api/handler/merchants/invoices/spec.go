@@ -0,0 +1,26 @@
+package invoices
+
+import (
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+	"github.com/miketsu-inc/reservations/backend/pkg/pdf"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Invoices",
+		openapi.Operation{Handler: h.GetSettings, Summary: "Get the seller details and numbering used on the invoices of the merchant", Response: invoiceSettingsResp{}},
+		openapi.Operation{Handler: h.UpdateSettings, Summary: "Update the seller details and numbering used on new invoices", Request: updateSettingsReq{}},
+		openapi.Operation{
+			Handler:  h.GetInvoices,
+			Summary:  "Get the invoices issued in a period, the latest first",
+			Response: []invoiceResp{},
+			Params: []openapi.Param{
+				{Name: "start", In: openapi.InQuery, Required: true, Description: "First day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "end", In: openapi.InQuery, Required: true, Description: "Last day of the period in the timezone of the merchant, YYYY-MM-DD"},
+			},
+		},
+		openapi.Operation{Handler: h.GetInvoice, Summary: "Get an invoice with its lines", Response: getInvoiceResp{}, Params: idParam},
+		openapi.Operation{Handler: h.Download, Summary: "Download the PDF of an invoice", Params: idParam, Files: []string{pdf.ContentType}},
+	)
+}
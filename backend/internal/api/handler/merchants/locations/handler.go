@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	merchantServ "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
@@ -11,18 +12,23 @@ import (
 )
 
 type Handler struct {
-	service *merchantServ.Service
+	service    *merchantServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *merchantServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *merchantServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	// TODO: temp until signup flow is figured out?
-	r.Post("/", h.New)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionSettingsManage))
+
+		// TODO: temp until signup flow is figured out?
+		r.Post("/", h.New)
+	})
 
 	return r
 }
@@ -0,0 +1,13 @@
+package locations
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Locations",
+		openapi.Operation{Handler: h.New, Summary: "Create a location", Request: newReq{}, Status: http.StatusCreated},
+	)
+}
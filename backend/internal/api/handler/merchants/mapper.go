@@ -7,15 +7,17 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	merchantServ "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"golang.org/x/text/language"
 )
 
 func mapToMeResp(in actor.EmployeeContext) meResp {
 	return meResp{
-		UserId:     in.UserId,
-		MerchantId: in.MerchantId,
-		LocationId: in.LocationId,
-		EmployeeId: in.EmployeeId,
-		Role:       in.Role,
+		UserId:      in.UserId,
+		MerchantId:  in.MerchantId,
+		LocationId:  in.LocationId,
+		EmployeeId:  in.EmployeeId,
+		Role:        in.Role,
+		Permissions: in.Permissions,
 	}
 }
 
@@ -31,7 +33,7 @@ func mapToCheckUrlInput(in checkUrlReq) merchantServ.CheckUrlInput {
 	}
 }
 
-func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
+func mapToGetDashboardResp(in domain.DashboardData, locale language.Tag) getDashboardResp {
 	upcomingBookings := make([]bookingDetailsResp, len(in.UpcomingBookings))
 
 	for i, b := range in.UpcomingBookings {
@@ -45,7 +47,7 @@ func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
 			ServiceName:     b.ServiceName,
 			ServiceColor:    b.ServiceColor,
 			ServiceDuration: int(b.ToDate.Sub(b.FromDate).Minutes()),
-			Price:           b.Price.ToFormatted(),
+			Price:           b.Price.ToFormatted(locale),
 			FirstName:       b.FirstName,
 			LastName:        b.LastName,
 			PhoneNumber:     b.PhoneNumber,
@@ -65,7 +67,7 @@ func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
 			ServiceName:     b.ServiceName,
 			ServiceColor:    b.ServiceColor,
 			ServiceDuration: int(b.ToDate.Sub(b.FromDate).Minutes()),
-			Price:           b.Price.ToFormatted(),
+			Price:           b.Price.ToFormatted(locale),
 			FirstName:       b.FirstName,
 			LastName:        b.LastName,
 			PhoneNumber:     b.PhoneNumber,
@@ -94,6 +96,26 @@ func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
 		}
 	}
 
+	taxStats := make([]taxStatResp, len(in.Statistics.Taxes))
+
+	for i, t := range in.Statistics.Taxes {
+		taxStats[i] = taxStatResp{
+			Name:    t.Name,
+			Percent: t.Percent,
+			Amount:  currencyx.FormattedPrice{Amount: t.Amount, Locale: locale},
+		}
+	}
+
+	employeeUtilizations := make([]dashboardEmployeeUtilizationResp, len(in.Utilization.Employees))
+
+	for i, e := range in.Utilization.Employees {
+		employeeUtilizations[i] = dashboardEmployeeUtilizationResp{
+			EmployeeId:  e.EmployeeId,
+			Name:        e.Name,
+			Utilization: e.Utilization,
+		}
+	}
+
 	return getDashboardResp{
 		PeriodStart:      in.PeriodStart,
 		PeriodEnd:        in.PeriodEnd,
@@ -102,8 +124,9 @@ func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
 		LowStockProducts: lowStockProducts,
 		Statistics: dashboardStatisticsResp{
 			Revenue:               revenueStats,
-			RevenueSum:            in.Statistics.RevenueSum,
+			RevenueSum:            currencyx.FormattedPrice{Amount: in.Statistics.RevenueSum, Locale: locale},
 			RevenueChange:         in.Statistics.RevenueChange,
+			Taxes:                 taxStats,
 			Bookings:              in.Statistics.Bookings,
 			BookingsChange:        in.Statistics.BookingsChange,
 			Cancellations:         in.Statistics.Cancellations,
@@ -111,6 +134,12 @@ func mapToGetDashboardResp(in domain.DashboardData) getDashboardResp {
 			AverageDuration:       in.Statistics.AverageDuration,
 			AverageDurationChange: in.Statistics.AverageDurationChange,
 		},
+		Utilization: dashboardUtilizationResp{
+			Utilization:    in.Utilization.Utilization,
+			RevenuePerHour: currencyx.FormattedPrice{Amount: in.Utilization.RevenuePerHour, Locale: locale},
+			IdleGapMinutes: in.Utilization.IdleGapMinutes,
+			Employees:      employeeUtilizations,
+		},
 	}
 }
 
@@ -267,7 +296,7 @@ func mapToGetTeamMembersForCalendarResp(in []domain.PublicEmployee) []getTeamMem
 	return teamMembers
 }
 
-func mapToGetServicesForCalendarResp(in []domain.ServicesGroupedByCategoriesForCalendar) []getServicesForCalendarResp {
+func mapToGetServicesForCalendarResp(in []domain.ServicesGroupedByCategoriesForCalendar, locale language.Tag) []getServicesForCalendarResp {
 	servicesGroupedByCategories := make([]getServicesForCalendarResp, len(in))
 
 	for i, c := range in {
@@ -278,7 +307,7 @@ func mapToGetServicesForCalendarResp(in []domain.ServicesGroupedByCategoriesForC
 				Id:              s.Id,
 				Name:            s.Name,
 				Duration:        s.Duration,
-				Price:           currencyx.FormatPrice(s.Price),
+				Price:           currencyx.FormatPrice(s.Price, locale),
 				PriceType:       s.PriceType,
 				Color:           s.Color,
 				BookingType:     s.BookingType,
@@ -315,7 +344,7 @@ func mapToGetCustomersForCalendarResp(in []domain.CustomerForCalendar) []getCust
 	return customers
 }
 
-func mapToGetCalendarEventsResp(in domain.CalendarEvents) getCalendarEventsResp {
+func mapToGetCalendarEventsResp(in domain.CalendarEvents, locale language.Tag) getCalendarEventsResp {
 	bookings := make([]bookingForCalendar, len(in.Bookings))
 
 	for i, b := range in.Bookings {
@@ -333,7 +362,7 @@ func mapToGetCalendarEventsResp(in domain.CalendarEvents) getCalendarEventsResp
 			ServiceName:     b.ServiceName,
 			ServiceColor:    b.ServiceColor,
 			MaxParticipants: b.MaxParticipants,
-			Price:           b.Price.ToFormatted(),
+			Price:           b.Price.ToFormatted(locale),
 			PriceType:       b.PriceType,
 		}
 
@@ -0,0 +1,189 @@
+package passes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	passServ "github.com/miketsu-inc/reservations/backend/internal/service/pass"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *passServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *passServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+// Every employee can see the passes which can be sold, the credits are used automatically when a customer joins a class
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/products", h.GetProducts)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
+
+		r.Post("/products", h.NewProduct)
+		r.Put("/products/{id}", h.UpdateProduct)
+	})
+
+	r.With(h.middleware.RequirePermission(types.PermissionCustomersManage)).Post("/", h.Assign)
+	r.With(h.middleware.RequirePermission(types.PermissionCustomersView)).Get("/customers/{id}", h.GetCustomerPasses)
+
+	return r
+}
+
+type productReq struct {
+	Name string `json:"name" validate:"required,max=50"`
+	// unlimited if it's missing
+	Credits      *int            `json:"credits"`
+	ValidityDays int             `json:"validity_days" validate:"required"`
+	Price        currencyx.Price `json:"price"`
+	IsActive     bool            `json:"is_active"`
+	ServiceIds   []int           `json:"service_ids"`
+	CategoryIds  []int           `json:"category_ids"`
+}
+
+type newProductResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewProduct(w http.ResponseWriter, r *http.Request) {
+	var req productReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	productId, err := h.service.NewProduct(r.Context(), mapToProductInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newProductResp{Id: productId})
+}
+
+func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	var req productReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlProductId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid pass id provided"))
+		return
+	}
+
+	err = h.service.UpdateProduct(r.Context(), urlProductId, mapToProductInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type productResp struct {
+	Id           int                      `json:"id"`
+	Name         string                   `json:"name"`
+	Credits      *int                     `json:"credits"`
+	ValidityDays int                      `json:"validity_days"`
+	Price        currencyx.FormattedPrice `json:"price"`
+	IsActive     bool                     `json:"is_active"`
+	ServiceIds   []int                    `json:"service_ids"`
+	CategoryIds  []int                    `json:"category_ids"`
+}
+
+func (h *Handler) GetProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.GetProducts(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToProductsResp(products, lang.LangFromContext(r.Context())))
+}
+
+type assignReq struct {
+	CustomerId    uuid.UUID `json:"customer_id" validate:"required"`
+	PassProductId int       `json:"pass_product_id" validate:"required"`
+	// valid from now if it's missing
+	ValidFrom *time.Time `json:"valid_from"`
+}
+
+type customerPassResp struct {
+	Id               int        `json:"id"`
+	PassProductId    int        `json:"pass_product_id"`
+	Name             string     `json:"name"`
+	Credits          *int       `json:"credits"`
+	RemainingCredits *int       `json:"remaining_credits"`
+	ValidFrom        time.Time  `json:"valid_from"`
+	ValidUntil       time.Time  `json:"valid_until"`
+	IsExpired        bool       `json:"is_expired"`
+	AssignedBy       *int       `json:"assigned_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiredAt        *time.Time `json:"expired_at"`
+}
+
+func (h *Handler) Assign(w http.ResponseWriter, r *http.Request) {
+	var req assignReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pass, err := h.service.Assign(r.Context(), mapToAssignInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, mapToCustomerPassResp(pass, time.Now()))
+}
+
+type usageResp struct {
+	Id             int        `json:"id"`
+	CustomerPassId int        `json:"customer_pass_id"`
+	BookingId      int        `json:"booking_id"`
+	ServiceName    string     `json:"service_name"`
+	FromDate       time.Time  `json:"from_date"`
+	UsedAt         time.Time  `json:"used_at"`
+	RefundedAt     *time.Time `json:"refunded_at"`
+}
+
+type customerPassesResp struct {
+	Passes []customerPassResp `json:"passes"`
+	Usages []usageResp        `json:"usages"`
+}
+
+func (h *Handler) GetCustomerPasses(w http.ResponseWriter, r *http.Request) {
+	urlCustomerId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	passes, err := h.service.GetCustomerPasses(r.Context(), urlCustomerId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToCustomerPassesResp(passes, time.Now()))
+}
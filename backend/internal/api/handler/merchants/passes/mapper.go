@@ -0,0 +1,85 @@
+package passes
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	passServ "github.com/miketsu-inc/reservations/backend/internal/service/pass"
+	"golang.org/x/text/language"
+)
+
+func mapToProductInput(req productReq) passServ.ProductInput {
+	return passServ.ProductInput{
+		Name:         req.Name,
+		Credits:      req.Credits,
+		ValidityDays: req.ValidityDays,
+		Price:        req.Price,
+		IsActive:     req.IsActive,
+		ServiceIds:   req.ServiceIds,
+		CategoryIds:  req.CategoryIds,
+	}
+}
+
+func mapToAssignInput(req assignReq) passServ.AssignInput {
+	return passServ.AssignInput{
+		CustomerId:    req.CustomerId,
+		PassProductId: req.PassProductId,
+		ValidFrom:     req.ValidFrom,
+	}
+}
+
+func mapToProductsResp(products []domain.PassProduct, locale language.Tag) []productResp {
+	result := make([]productResp, len(products))
+	for i, product := range products {
+		result[i] = productResp{
+			Id:           product.Id,
+			Name:         product.Name,
+			Credits:      product.Credits,
+			ValidityDays: product.ValidityDays,
+			Price:        product.Price.ToFormatted(locale),
+			IsActive:     product.IsActive,
+			ServiceIds:   product.ServiceIds,
+			CategoryIds:  product.CategoryIds,
+		}
+	}
+
+	return result
+}
+
+func mapToCustomerPassResp(pass domain.CustomerPass, now time.Time) customerPassResp {
+	return customerPassResp{
+		Id:               pass.Id,
+		PassProductId:    pass.PassProductId,
+		Name:             pass.Name,
+		Credits:          pass.Credits,
+		RemainingCredits: pass.RemainingCredits,
+		ValidFrom:        pass.ValidFrom,
+		ValidUntil:       pass.ValidUntil,
+		IsExpired:        pass.IsExpired(now),
+		AssignedBy:       pass.AssignedBy,
+		CreatedAt:        pass.CreatedAt,
+		ExpiredAt:        pass.ExpiredAt,
+	}
+}
+
+func mapToCustomerPassesResp(passes passServ.CustomerPasses, now time.Time) customerPassesResp {
+	passesResp := make([]customerPassResp, len(passes.Passes))
+	for i, pass := range passes.Passes {
+		passesResp[i] = mapToCustomerPassResp(pass, now)
+	}
+
+	usages := make([]usageResp, len(passes.Usages))
+	for i, usage := range passes.Usages {
+		usages[i] = usageResp{
+			Id:             usage.Id,
+			CustomerPassId: usage.CustomerPassId,
+			BookingId:      usage.BookingId,
+			ServiceName:    usage.ServiceName,
+			FromDate:       usage.FromDate,
+			UsedAt:         usage.UsedAt,
+			RefundedAt:     usage.RefundedAt,
+		}
+	}
+
+	return customerPassesResp{Passes: passesResp, Usages: usages}
+}
@@ -0,0 +1,43 @@
+package passes
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+	customerIdParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: uuid.UUID{}}}
+
+	return openapi.WithTag("Passes",
+		openapi.Operation{Handler: h.GetProducts, Summary: "List the class passes and memberships of the merchant", Response: []productResp{}},
+		openapi.Operation{
+			Handler:  h.NewProduct,
+			Summary:  "Create a class pass or membership, it is unlimited without credits",
+			Request:  productReq{},
+			Response: newProductResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{
+			Handler: h.UpdateProduct,
+			Summary: "Update a class pass or membership, the passes already assigned to customers are not affected",
+			Request: productReq{},
+			Params:  idParam,
+		},
+		openapi.Operation{
+			Handler:  h.Assign,
+			Summary:  "Assign a pass to a customer, its credits are used when the customer joins a class it is valid for",
+			Request:  assignReq{},
+			Response: customerPassResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{
+			Handler:  h.GetCustomerPasses,
+			Summary:  "Get the passes of a customer and the classes they were used for",
+			Response: customerPassesResp{},
+			Params:   customerIdParam,
+		},
+	)
+}
@@ -6,26 +6,33 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	productServ "github.com/miketsu-inc/reservations/backend/internal/service/product"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
 	"github.com/miketsu-inc/reservations/backend/pkg/validate"
 )
 
 type Handler struct {
-	service *productServ.Service
+	service    *productServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *productServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *productServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.New)
-	r.Put("/{id}", h.Update)
-	r.Delete("/{id}", h.Delete)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
 
 	r.Get("/", h.GetAll)
 
@@ -39,6 +46,7 @@ type newReq struct {
 	Unit          string           `json:"unit" validate:"required"`
 	MaxAmount     int              `json:"max_amount" validate:"min=0,max=10000000000"`
 	CurrentAmount int              `json:"current_amount" validate:"min=0,max=10000000000"`
+	TaxRateId     *int             `json:"tax_rate_id"`
 }
 
 func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +74,7 @@ type updateReq struct {
 	Unit          string           `json:"unit" validate:"required"`
 	MaxAmount     int              `json:"max_amount" validate:"min=0,max=10000000000"`
 	CurrentAmount int              `json:"current_amount" validate:"min=0,max=10000000000"`
+	TaxRateId     *int             `json:"tax_rate_id"`
 }
 
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
@@ -111,6 +120,7 @@ type getAllResp struct {
 	Unit          string                   `json:"unit"`
 	MaxAmount     int                      `json:"max_amount"`
 	CurrentAmount int                      `json:"current_amount"`
+	TaxRateId     *int                     `json:"tax_rate_id"`
 	Services      []servicesForProdcutResp `json:"services"`
 }
 
@@ -13,6 +13,7 @@ func mapToNewInput(in newReq) productServ.NewInput {
 		Unit:          in.Unit,
 		MaxAmount:     in.MaxAmount,
 		CurrentAmount: in.CurrentAmount,
+		TaxRateId:     in.TaxRateId,
 	}
 }
 
@@ -25,6 +26,7 @@ func mapToUpdateInput(in updateReq) productServ.UpdateInput {
 		Unit:          in.Unit,
 		MaxAmount:     in.MaxAmount,
 		CurrentAmount: in.CurrentAmount,
+		TaxRateId:     in.TaxRateId,
 	}
 }
 
@@ -50,6 +52,7 @@ func mapToGetAllResp(in []domain.ProductInfo) []getAllResp {
 			Unit:          product.Unit,
 			MaxAmount:     product.MaxAmount,
 			CurrentAmount: product.CurrentAmount,
+			TaxRateId:     product.TaxRateId,
 			Services:      s,
 		}
 	}
@@ -0,0 +1,18 @@
+package products
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Products",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the products of the merchant", Response: []getAllResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create a product", Request: newReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a product", Request: updateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a product", Params: idParam},
+	)
+}
@@ -0,0 +1,155 @@
+package promotions
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	promotionServ "github.com/miketsu-inc/reservations/backend/internal/service/promotion"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *promotionServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *promotionServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+// Every employee can see the promotions, the discounts are applied when a customer books
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetAll)
+	r.Get("/{id}", h.Get)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+	})
+
+	return r
+}
+
+type promotionReq struct {
+	Name string `json:"name" validate:"required,max=50"`
+	// an automatic promotion if it's missing, like happy hour pricing
+	Code *string `json:"code"`
+	// exactly one of them has to be given
+	Percent            *string          `json:"percent"`
+	Amount             *currencyx.Price `json:"amount"`
+	FirstVisitOnly     bool             `json:"first_visit_only"`
+	MaxUses            *int             `json:"max_uses"`
+	MaxUsesPerCustomer *int             `json:"max_uses_per_customer"`
+	ValidFrom          *time.Time       `json:"valid_from"`
+	ValidUntil         *time.Time       `json:"valid_until"`
+	// 0 is sunday, every day if it's empty
+	Weekdays []int `json:"weekdays"`
+	// like 14:00 in the timezone of the merchant, the whole day if they are missing
+	StartTime   *string `json:"start_time"`
+	EndTime     *string `json:"end_time"`
+	IsActive    bool    `json:"is_active"`
+	ServiceIds  []int   `json:"service_ids"`
+	CategoryIds []int   `json:"category_ids"`
+}
+
+type newPromotionResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
+	var req promotionReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	promotionId, err := h.service.New(r.Context(), mapToInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newPromotionResp{Id: promotionId})
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req promotionReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlPromotionId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid promotion id provided"))
+		return
+	}
+
+	err = h.service.Update(r.Context(), urlPromotionId, mapToInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type promotionResp struct {
+	Id                 int                       `json:"id"`
+	Name               string                    `json:"name"`
+	Code               *string                   `json:"code"`
+	Percent            *string                   `json:"percent"`
+	Amount             *currencyx.FormattedPrice `json:"amount"`
+	FirstVisitOnly     bool                      `json:"first_visit_only"`
+	MaxUses            *int                      `json:"max_uses"`
+	MaxUsesPerCustomer *int                      `json:"max_uses_per_customer"`
+	ValidFrom          *time.Time                `json:"valid_from"`
+	ValidUntil         *time.Time                `json:"valid_until"`
+	Weekdays           []int                     `json:"weekdays"`
+	StartTime          *string                   `json:"start_time"`
+	EndTime            *string                   `json:"end_time"`
+	IsActive           bool                      `json:"is_active"`
+	ServiceIds         []int                     `json:"service_ids"`
+	CategoryIds        []int                     `json:"category_ids"`
+	// the bookings which used the promotion and are not cancelled
+	Uses      int       `json:"uses"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.GetAll(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToPromotionsResp(promotions, lang.LangFromContext(r.Context())))
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	urlPromotionId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid promotion id provided"))
+		return
+	}
+
+	promotion, err := h.service.Get(r.Context(), urlPromotionId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToPromotionResp(promotion, lang.LangFromContext(r.Context())))
+}
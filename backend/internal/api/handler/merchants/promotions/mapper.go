@@ -0,0 +1,66 @@
+package promotions
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	promotionServ "github.com/miketsu-inc/reservations/backend/internal/service/promotion"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"golang.org/x/text/language"
+)
+
+func mapToInput(req promotionReq) promotionServ.Input {
+	return promotionServ.Input{
+		Name:               req.Name,
+		Code:               req.Code,
+		Percent:            req.Percent,
+		Amount:             req.Amount,
+		FirstVisitOnly:     req.FirstVisitOnly,
+		MaxUses:            req.MaxUses,
+		MaxUsesPerCustomer: req.MaxUsesPerCustomer,
+		ValidFrom:          req.ValidFrom,
+		ValidUntil:         req.ValidUntil,
+		Weekdays:           req.Weekdays,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		IsActive:           req.IsActive,
+		ServiceIds:         req.ServiceIds,
+		CategoryIds:        req.CategoryIds,
+	}
+}
+
+func mapToPromotionResp(promotion domain.Promotion, locale language.Tag) promotionResp {
+	var amount *currencyx.FormattedPrice
+	if promotion.Amount != nil {
+		formatted := promotion.Amount.ToFormatted(locale)
+		amount = &formatted
+	}
+
+	return promotionResp{
+		Id:                 promotion.Id,
+		Name:               promotion.Name,
+		Code:               promotion.Code,
+		Percent:            promotion.Percent,
+		Amount:             amount,
+		FirstVisitOnly:     promotion.FirstVisitOnly,
+		MaxUses:            promotion.MaxUses,
+		MaxUsesPerCustomer: promotion.MaxUsesPerCustomer,
+		ValidFrom:          promotion.ValidFrom,
+		ValidUntil:         promotion.ValidUntil,
+		Weekdays:           promotion.Weekdays,
+		StartTime:          promotionServ.FormatMinute(promotion.StartMinute),
+		EndTime:            promotionServ.FormatMinute(promotion.EndMinute),
+		IsActive:           promotion.IsActive,
+		ServiceIds:         promotion.ServiceIds,
+		CategoryIds:        promotion.CategoryIds,
+		Uses:               promotion.Uses,
+		CreatedAt:          promotion.CreatedAt,
+	}
+}
+
+func mapToPromotionsResp(promotions []domain.Promotion, locale language.Tag) []promotionResp {
+	result := make([]promotionResp, len(promotions))
+	for i, promotion := range promotions {
+		result[i] = mapToPromotionResp(promotion, locale)
+	}
+
+	return result
+}
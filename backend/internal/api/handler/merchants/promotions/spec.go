@@ -0,0 +1,29 @@
+package promotions
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Promotions",
+		openapi.Operation{Handler: h.GetAll, Summary: "List the promo codes and automatic promotions of the merchant", Response: []promotionResp{}},
+		openapi.Operation{Handler: h.Get, Summary: "Get a promotion and how many times it was used", Response: promotionResp{}, Params: idParam},
+		openapi.Operation{
+			Handler:  h.New,
+			Summary:  "Create a promotion, it is applied automatically to the matching bookings without a code",
+			Request:  promotionReq{},
+			Response: newPromotionResp{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Operation{
+			Handler: h.Update,
+			Summary: "Update a promotion, the bookings it was already used for keep their price",
+			Request: promotionReq{},
+			Params:  idParam,
+		},
+	)
+}
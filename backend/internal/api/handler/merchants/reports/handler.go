@@ -0,0 +1,435 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	reportServ "github.com/miketsu-inc/reservations/backend/internal/service/report"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *reportServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *reportServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionReportsView))
+
+	r.Get("/", h.GetReport)
+	r.Get("/utilization", h.GetUtilization)
+	r.Get("/retention", h.GetRetention)
+
+	r.Get("/subscriptions", h.GetSubscriptions)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionSettingsManage))
+
+		r.Post("/subscriptions", h.NewSubscription)
+		r.Put("/subscriptions/{id}", h.UpdateSubscription)
+		r.Delete("/subscriptions/{id}", h.DeleteSubscription)
+	})
+
+	r.Post("/exports", h.NewExport)
+	r.Get("/exports", h.GetExports)
+	r.Get("/exports/{id}", h.GetExport)
+
+	return r
+}
+
+const dateLayout = "2006-01-02"
+
+type reportPeriodResp struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type reportMetricsResp struct {
+	Bookings         int                      `json:"bookings"`
+	Cancellations    int                      `json:"cancellations"`
+	NoShows          int                      `json:"no_shows"`
+	Participants     int                      `json:"participants"`
+	Revenue          float64                  `json:"revenue"`
+	FormattedRevenue currencyx.FormattedPrice `json:"formatted_revenue"`
+	AverageDuration  int                      `json:"average_duration"`
+}
+
+type reportChangesResp struct {
+	Bookings        int `json:"bookings"`
+	Cancellations   int `json:"cancellations"`
+	NoShows         int `json:"no_shows"`
+	Participants    int `json:"participants"`
+	Revenue         int `json:"revenue"`
+	AverageDuration int `json:"average_duration"`
+}
+
+type reportBucketResp struct {
+	Start   string            `json:"start"`
+	End     string            `json:"end"`
+	Metrics reportMetricsResp `json:"metrics"`
+}
+
+type reportGroupResp struct {
+	Id       *int              `json:"id"`
+	Name     string            `json:"name"`
+	Current  reportMetricsResp `json:"current"`
+	Previous reportMetricsResp `json:"previous"`
+	Changes  reportChangesResp `json:"changes"`
+}
+
+type reportTaxResp struct {
+	Name            string                   `json:"name"`
+	Percent         string                   `json:"percent"`
+	Amount          float64                  `json:"amount"`
+	FormattedAmount currencyx.FormattedPrice `json:"formatted_amount"`
+}
+
+type getReportResp struct {
+	Period         reportPeriodResp        `json:"period"`
+	PreviousPeriod reportPeriodResp        `json:"previous_period"`
+	Granularity    types.ReportGranularity `json:"granularity"`
+	GroupBy        *types.ReportGroupBy    `json:"group_by"`
+	Currency       string                  `json:"currency"`
+	Current        reportMetricsResp       `json:"current"`
+	Previous       reportMetricsResp       `json:"previous"`
+	Changes        reportChangesResp       `json:"changes"`
+	Series         []reportBucketResp      `json:"series"`
+	PreviousSeries []reportBucketResp      `json:"previous_series"`
+	Groups         []reportGroupResp       `json:"groups"`
+	Taxes          []reportTaxResp         `json:"taxes"`
+	RefreshedAt    *time.Time              `json:"refreshed_at"`
+}
+
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToReportInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetReportResp(report, lang.LangFromContext(r.Context())))
+}
+
+type employeeUtilizationResp struct {
+	EmployeeId     int     `json:"employee_id"`
+	Name           string  `json:"name"`
+	WorkingMinutes int     `json:"working_minutes"`
+	BookedMinutes  int     `json:"booked_minutes"`
+	Utilization    int     `json:"utilization"`
+	IdleGaps       int     `json:"idle_gaps"`
+	IdleGapMinutes int     `json:"idle_gap_minutes"`
+	Revenue        float64 `json:"revenue"`
+	RevenuePerHour float64 `json:"revenue_per_hour"`
+}
+
+type locationOccupancyResp struct {
+	LocationId      int    `json:"location_id"`
+	Name            string `json:"name"`
+	BookedMinutes   int    `json:"booked_minutes"`
+	CapacityMinutes int    `json:"capacity_minutes"`
+	Occupancy       int    `json:"occupancy"`
+}
+
+type heatmapCellResp struct {
+	DayOfWeek      int `json:"day_of_week"`
+	Hour           int `json:"hour"`
+	WorkingMinutes int `json:"working_minutes"`
+	BookedMinutes  int `json:"booked_minutes"`
+	Occupancy      int `json:"occupancy"`
+}
+
+type getUtilizationResp struct {
+	Period                  reportPeriodResp          `json:"period"`
+	Currency                string                    `json:"currency"`
+	WorkingMinutes          int                       `json:"working_minutes"`
+	BookedMinutes           int                       `json:"booked_minutes"`
+	Utilization             int                       `json:"utilization"`
+	IdleGaps                int                       `json:"idle_gaps"`
+	IdleGapMinutes          int                       `json:"idle_gap_minutes"`
+	Revenue                 float64                   `json:"revenue"`
+	RevenuePerHour          float64                   `json:"revenue_per_hour"`
+	FormattedRevenuePerHour currencyx.FormattedPrice  `json:"formatted_revenue_per_hour"`
+	Employees               []employeeUtilizationResp `json:"employees"`
+	Locations               []locationOccupancyResp   `json:"locations"`
+	Heatmap                 []heatmapCellResp         `json:"heatmap"`
+}
+
+func (h *Handler) GetUtilization(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToUtilizationInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utilization, err := h.service.GetUtilization(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetUtilizationResp(utilization, lang.LangFromContext(r.Context())))
+}
+
+type retentionBucketResp struct {
+	Start              string `json:"start"`
+	End                string `json:"end"`
+	NewCustomers       int    `json:"new_customers"`
+	ReturningCustomers int    `json:"returning_customers"`
+}
+
+type cohortRetentionResp struct {
+	Month     string `json:"month"`
+	Customers int    `json:"customers"`
+	Retained  []int  `json:"retained"`
+	Retention []int  `json:"retention"`
+}
+
+type rebookingIntervalResp struct {
+	ServiceId   *int   `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	Rebookings  int    `json:"rebookings"`
+	AverageDays int    `json:"average_days"`
+}
+
+type churnRiskCustomerResp struct {
+	CustomerId             uuid.UUID                `json:"customer_id"`
+	Name                   string                   `json:"name"`
+	LastVisit              time.Time                `json:"last_visit"`
+	Visits                 int                      `json:"visits"`
+	UsualIntervalDays      int                      `json:"usual_interval_days"`
+	DaysSinceLastVisit     int                      `json:"days_since_last_visit"`
+	LifetimeValue          float64                  `json:"lifetime_value"`
+	FormattedLifetimeValue currencyx.FormattedPrice `json:"formatted_lifetime_value"`
+}
+
+type getRetentionResp struct {
+	Period                        reportPeriodResp         `json:"period"`
+	Granularity                   types.ReportGranularity  `json:"granularity"`
+	Currency                      string                   `json:"currency"`
+	NewCustomers                  int                      `json:"new_customers"`
+	ReturningCustomers            int                      `json:"returning_customers"`
+	NoShowRate                    int                      `json:"no_show_rate"`
+	CancellationRate              int                      `json:"cancellation_rate"`
+	Series                        []retentionBucketResp    `json:"series"`
+	Cohorts                       []cohortRetentionResp    `json:"cohorts"`
+	RebookingIntervals            []rebookingIntervalResp  `json:"rebooking_intervals"`
+	ChurnRisk                     []churnRiskCustomerResp  `json:"churn_risk"`
+	Customers                     int                      `json:"customers"`
+	AverageVisits                 float64                  `json:"average_visits"`
+	AverageLifetimeValue          float64                  `json:"average_lifetime_value"`
+	FormattedAverageLifetimeValue currencyx.FormattedPrice `json:"formatted_average_lifetime_value"`
+}
+
+func (h *Handler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	input, err := mapToRetentionInput(r)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	retention, err := h.service.GetRetention(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetRetentionResp(retention, lang.LangFromContext(r.Context())))
+}
+
+type reportSubscriptionRecipientResp struct {
+	EmployeeId int     `json:"employee_id"`
+	FirstName  *string `json:"first_name"`
+	LastName   *string `json:"last_name"`
+	Email      *string `json:"email"`
+}
+
+type reportSubscriptionResp struct {
+	Id            int                               `json:"id"`
+	Frequency     types.ReportFrequency             `json:"frequency"`
+	IsActive      bool                              `json:"is_active"`
+	LastSentUntil *string                           `json:"last_sent_until"`
+	CreatedAt     time.Time                         `json:"created_at"`
+	Recipients    []reportSubscriptionRecipientResp `json:"recipients"`
+}
+
+func (h *Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.service.GetSubscriptions(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToReportSubscriptionsResp(subscriptions))
+}
+
+type newSubscriptionReq struct {
+	Frequency   types.ReportFrequency `json:"frequency" validate:"required"`
+	EmployeeIds []int                 `json:"employee_ids" validate:"required"`
+}
+
+type newSubscriptionResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewSubscription(w http.ResponseWriter, r *http.Request) {
+	var req newSubscriptionReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subscriptionId, err := h.service.NewSubscription(r.Context(), mapToReportSubscriptionInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newSubscriptionResp{Id: subscriptionId})
+}
+
+type updateSubscriptionReq struct {
+	IsActive    bool  `json:"is_active"`
+	EmployeeIds []int `json:"employee_ids" validate:"required"`
+}
+
+func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req updateSubscriptionReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlSubscriptionId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid subscription id provided"))
+		return
+	}
+
+	err = h.service.UpdateSubscription(r.Context(), urlSubscriptionId, mapToUpdateSubscriptionInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	urlSubscriptionId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid subscription id provided"))
+		return
+	}
+
+	err = h.service.DeleteSubscription(r.Context(), urlSubscriptionId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type newExportReq struct {
+	Dataset types.ReportExportDataset `json:"dataset" validate:"required"`
+	Format  types.ReportExportFormat  `json:"format" validate:"required"`
+	Start   string                    `json:"start" validate:"required"`
+	End     string                    `json:"end" validate:"required"`
+}
+
+type newExportResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewExport(w http.ResponseWriter, r *http.Request) {
+	var req newExportReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	input, err := mapToExportInput(req)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	exportId, err := h.service.RequestExport(r.Context(), input)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusAccepted, newExportResp{Id: exportId})
+}
+
+type reportExportResp struct {
+	Id           int                       `json:"id"`
+	Dataset      types.ReportExportDataset `json:"dataset"`
+	Format       types.ReportExportFormat  `json:"format"`
+	Start        string                    `json:"start"`
+	End          string                    `json:"end"`
+	Status       types.DataExportStatus    `json:"status"`
+	Error        *string                   `json:"error"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	FinishedAt   *time.Time                `json:"finished_at"`
+	ExpiresAt    *time.Time                `json:"expires_at"`
+	DownloadLink *string                   `json:"download_link"`
+}
+
+func (h *Handler) GetExports(w http.ResponseWriter, r *http.Request) {
+	exports, err := h.service.GetExports(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp, err := h.mapToReportExportsResp(exports)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	urlExportId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid export id provided"))
+		return
+	}
+
+	export, err := h.service.GetExport(r.Context(), urlExportId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.mapToReportExportResp(export)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, resp)
+}
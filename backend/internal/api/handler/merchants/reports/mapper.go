@@ -0,0 +1,400 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	reportServ "github.com/miketsu-inc/reservations/backend/internal/service/report"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"golang.org/x/text/language"
+)
+
+func parsePeriod(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+
+	start, err := time.Parse(dateLayout, query.Get("start"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %s", err.Error())
+	}
+
+	end, err := time.Parse(dateLayout, query.Get("end"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %s", err.Error())
+	}
+
+	return start, end, nil
+}
+
+func mapToReportInput(r *http.Request) (reportServ.ReportInput, error) {
+	query := r.URL.Query()
+
+	start, end, err := parsePeriod(r)
+	if err != nil {
+		return reportServ.ReportInput{}, err
+	}
+
+	granularity := types.ReportGranularityDay
+	if g := query.Get("granularity"); g != "" {
+		granularity, err = types.NewReportGranularity(g)
+		if err != nil {
+			return reportServ.ReportInput{}, err
+		}
+	}
+
+	input := reportServ.ReportInput{
+		Start:       start,
+		End:         end,
+		Granularity: granularity,
+	}
+
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		g, err := types.NewReportGroupBy(groupBy)
+		if err != nil {
+			return reportServ.ReportInput{}, err
+		}
+
+		input.GroupBy = &g
+	}
+
+	return input, nil
+}
+
+func mapToReportPeriodResp(period domain.ReportPeriod) reportPeriodResp {
+	return reportPeriodResp{
+		Start: period.Start.Format(dateLayout),
+		End:   period.End.Format(dateLayout),
+	}
+}
+
+func mapToReportMetricsResp(metrics domain.ReportMetrics, locale language.Tag) reportMetricsResp {
+	return reportMetricsResp{
+		Bookings:         metrics.Bookings,
+		Cancellations:    metrics.Cancellations,
+		NoShows:          metrics.NoShows,
+		Participants:     metrics.Participants,
+		Revenue:          amountToFloat(metrics.Revenue),
+		FormattedRevenue: currencyx.FormattedPrice{Amount: metrics.Revenue, Locale: locale},
+		AverageDuration:  metrics.AverageDuration(),
+	}
+}
+
+func mapToReportChangesResp(changes domain.ReportChanges) reportChangesResp {
+	return reportChangesResp{
+		Bookings:        changes.Bookings,
+		Cancellations:   changes.Cancellations,
+		NoShows:         changes.NoShows,
+		Participants:    changes.Participants,
+		Revenue:         changes.Revenue,
+		AverageDuration: changes.AverageDuration,
+	}
+}
+
+func mapToReportSeriesResp(series []domain.ReportBucket, locale language.Tag) []reportBucketResp {
+	result := make([]reportBucketResp, len(series))
+	for i, bucket := range series {
+		result[i] = reportBucketResp{
+			Start:   bucket.Period.Start.Format(dateLayout),
+			End:     bucket.Period.End.Format(dateLayout),
+			Metrics: mapToReportMetricsResp(bucket.Metrics, locale),
+		}
+	}
+
+	return result
+}
+
+func mapToGetReportResp(report domain.Report, locale language.Tag) getReportResp {
+	groups := make([]reportGroupResp, len(report.Groups))
+	for i, group := range report.Groups {
+		groups[i] = reportGroupResp{
+			Id:       group.Id,
+			Name:     group.Name,
+			Current:  mapToReportMetricsResp(group.Current, locale),
+			Previous: mapToReportMetricsResp(group.Previous, locale),
+			Changes:  mapToReportChangesResp(group.Changes),
+		}
+	}
+
+	taxes := make([]reportTaxResp, len(report.Taxes))
+	for i, tax := range report.Taxes {
+		taxes[i] = reportTaxResp{
+			Name:            tax.Name,
+			Percent:         tax.Percent,
+			Amount:          amountToFloat(tax.Amount),
+			FormattedAmount: currencyx.FormattedPrice{Amount: tax.Amount, Locale: locale},
+		}
+	}
+
+	return getReportResp{
+		Period:         mapToReportPeriodResp(report.Period),
+		PreviousPeriod: mapToReportPeriodResp(report.PreviousPeriod),
+		Granularity:    report.Granularity,
+		GroupBy:        report.GroupBy,
+		Currency:       report.Currency,
+		Current:        mapToReportMetricsResp(report.Current, locale),
+		Previous:       mapToReportMetricsResp(report.Previous, locale),
+		Changes:        mapToReportChangesResp(report.Changes),
+		Series:         mapToReportSeriesResp(report.Series, locale),
+		PreviousSeries: mapToReportSeriesResp(report.PreviousSeries, locale),
+		Groups:         groups,
+		Taxes:          taxes,
+		RefreshedAt:    report.RefreshedAt,
+	}
+}
+
+func mapToUtilizationInput(r *http.Request) (reportServ.UtilizationInput, error) {
+	start, end, err := parsePeriod(r)
+	if err != nil {
+		return reportServ.UtilizationInput{}, err
+	}
+
+	return reportServ.UtilizationInput{Start: start, End: end}, nil
+}
+
+func amountToFloat(amount currency.Amount) float64 {
+	value, _ := strconv.ParseFloat(amount.Number(), 64)
+	return value
+}
+
+func mapToGetUtilizationResp(utilization domain.Utilization, locale language.Tag) getUtilizationResp {
+	employees := make([]employeeUtilizationResp, len(utilization.Employees))
+	for i, e := range utilization.Employees {
+		employees[i] = employeeUtilizationResp{
+			EmployeeId:     e.EmployeeId,
+			Name:           e.Name,
+			WorkingMinutes: e.WorkingMinutes,
+			BookedMinutes:  e.BookedMinutes,
+			Utilization:    e.Utilization,
+			IdleGaps:       e.IdleGaps,
+			IdleGapMinutes: e.IdleGapMinutes,
+			Revenue:        amountToFloat(e.Revenue),
+			RevenuePerHour: amountToFloat(e.RevenuePerHour),
+		}
+	}
+
+	locations := make([]locationOccupancyResp, len(utilization.Locations))
+	for i, l := range utilization.Locations {
+		locations[i] = locationOccupancyResp{
+			LocationId:      l.LocationId,
+			Name:            l.Name,
+			BookedMinutes:   l.BookedMinutes,
+			CapacityMinutes: l.CapacityMinutes,
+			Occupancy:       l.Occupancy,
+		}
+	}
+
+	heatmap := make([]heatmapCellResp, len(utilization.Heatmap))
+	for i, c := range utilization.Heatmap {
+		heatmap[i] = heatmapCellResp{
+			DayOfWeek:      c.DayOfWeek,
+			Hour:           c.Hour,
+			WorkingMinutes: c.WorkingMinutes,
+			BookedMinutes:  c.BookedMinutes,
+			Occupancy:      c.Occupancy,
+		}
+	}
+
+	return getUtilizationResp{
+		Period:                  mapToReportPeriodResp(utilization.Period),
+		Currency:                utilization.Currency,
+		WorkingMinutes:          utilization.WorkingMinutes,
+		BookedMinutes:           utilization.BookedMinutes,
+		Utilization:             utilization.Utilization,
+		IdleGaps:                utilization.IdleGaps,
+		IdleGapMinutes:          utilization.IdleGapMinutes,
+		Revenue:                 amountToFloat(utilization.Revenue),
+		RevenuePerHour:          amountToFloat(utilization.RevenuePerHour),
+		FormattedRevenuePerHour: currencyx.FormattedPrice{Amount: utilization.RevenuePerHour, Locale: locale},
+		Employees:               employees,
+		Locations:               locations,
+		Heatmap:                 heatmap,
+	}
+}
+
+func mapToRetentionInput(r *http.Request) (reportServ.RetentionInput, error) {
+	start, end, err := parsePeriod(r)
+	if err != nil {
+		return reportServ.RetentionInput{}, err
+	}
+
+	granularity := types.ReportGranularityMonth
+	if g := r.URL.Query().Get("granularity"); g != "" {
+		granularity, err = types.NewReportGranularity(g)
+		if err != nil {
+			return reportServ.RetentionInput{}, err
+		}
+	}
+
+	return reportServ.RetentionInput{Start: start, End: end, Granularity: granularity}, nil
+}
+
+func mapToGetRetentionResp(retention domain.CustomerRetention, locale language.Tag) getRetentionResp {
+	series := make([]retentionBucketResp, len(retention.Series))
+	for i, b := range retention.Series {
+		series[i] = retentionBucketResp{
+			Start:              b.Period.Start.Format(dateLayout),
+			End:                b.Period.End.Format(dateLayout),
+			NewCustomers:       b.NewCustomers,
+			ReturningCustomers: b.ReturningCustomers,
+		}
+	}
+
+	cohorts := make([]cohortRetentionResp, len(retention.Cohorts))
+	for i, c := range retention.Cohorts {
+		cohorts[i] = cohortRetentionResp{
+			Month:     c.Month.Format("2006-01"),
+			Customers: c.Customers,
+			Retained:  c.Retained,
+			Retention: c.Retention,
+		}
+	}
+
+	intervals := make([]rebookingIntervalResp, len(retention.RebookingIntervals))
+	for i, ri := range retention.RebookingIntervals {
+		intervals[i] = rebookingIntervalResp{
+			ServiceId:   ri.ServiceId,
+			ServiceName: ri.ServiceName,
+			Rebookings:  ri.Rebookings,
+			AverageDays: ri.AverageDays,
+		}
+	}
+
+	churnRisk := make([]churnRiskCustomerResp, len(retention.ChurnRisk))
+	for i, c := range retention.ChurnRisk {
+		churnRisk[i] = churnRiskCustomerResp{
+			CustomerId:             c.CustomerId,
+			Name:                   c.Name,
+			LastVisit:              c.LastVisit,
+			Visits:                 c.Visits,
+			UsualIntervalDays:      c.UsualIntervalDays,
+			DaysSinceLastVisit:     c.DaysSinceLastVisit,
+			LifetimeValue:          amountToFloat(c.LifetimeValue),
+			FormattedLifetimeValue: currencyx.FormattedPrice{Amount: c.LifetimeValue, Locale: locale},
+		}
+	}
+
+	return getRetentionResp{
+		Period:                        mapToReportPeriodResp(retention.Period),
+		Granularity:                   retention.Granularity,
+		Currency:                      retention.Currency,
+		NewCustomers:                  retention.NewCustomers,
+		ReturningCustomers:            retention.ReturningCustomers,
+		NoShowRate:                    retention.NoShowRate,
+		CancellationRate:              retention.CancellationRate,
+		Series:                        series,
+		Cohorts:                       cohorts,
+		RebookingIntervals:            intervals,
+		ChurnRisk:                     churnRisk,
+		Customers:                     retention.Customers,
+		AverageVisits:                 retention.AverageVisits,
+		AverageLifetimeValue:          amountToFloat(retention.AverageLifetimeValue),
+		FormattedAverageLifetimeValue: currencyx.FormattedPrice{Amount: retention.AverageLifetimeValue, Locale: locale},
+	}
+}
+
+func mapToReportSubscriptionsResp(subscriptions []domain.ReportSubscription) []reportSubscriptionResp {
+	result := make([]reportSubscriptionResp, len(subscriptions))
+	for i, s := range subscriptions {
+		recipients := make([]reportSubscriptionRecipientResp, len(s.Recipients))
+		for j, rec := range s.Recipients {
+			recipients[j] = reportSubscriptionRecipientResp{
+				EmployeeId: rec.EmployeeId,
+				FirstName:  rec.FirstName,
+				LastName:   rec.LastName,
+				Email:      rec.Email,
+			}
+		}
+
+		var lastSentUntil *string
+		if s.LastSentUntil != nil {
+			day := s.LastSentUntil.Format(dateLayout)
+			lastSentUntil = &day
+		}
+
+		result[i] = reportSubscriptionResp{
+			Id:            s.Id,
+			Frequency:     s.Frequency,
+			IsActive:      s.IsActive,
+			LastSentUntil: lastSentUntil,
+			CreatedAt:     s.CreatedAt,
+			Recipients:    recipients,
+		}
+	}
+
+	return result
+}
+
+func mapToReportSubscriptionInput(req newSubscriptionReq) reportServ.ReportSubscriptionInput {
+	return reportServ.ReportSubscriptionInput{
+		Frequency:   req.Frequency,
+		EmployeeIds: req.EmployeeIds,
+	}
+}
+
+func mapToUpdateSubscriptionInput(req updateSubscriptionReq) reportServ.UpdateSubscriptionInput {
+	return reportServ.UpdateSubscriptionInput{
+		IsActive:    req.IsActive,
+		EmployeeIds: req.EmployeeIds,
+	}
+}
+
+func mapToExportInput(req newExportReq) (reportServ.ExportInput, error) {
+	start, err := time.Parse(dateLayout, req.Start)
+	if err != nil {
+		return reportServ.ExportInput{}, fmt.Errorf("invalid start: %s", err.Error())
+	}
+
+	end, err := time.Parse(dateLayout, req.End)
+	if err != nil {
+		return reportServ.ExportInput{}, fmt.Errorf("invalid end: %s", err.Error())
+	}
+
+	return reportServ.ExportInput{
+		Dataset: req.Dataset,
+		Format:  req.Format,
+		Start:   start,
+		End:     end,
+	}, nil
+}
+
+func (h *Handler) mapToReportExportResp(export domain.ReportExport) (reportExportResp, error) {
+	var downloadLink *string
+	if export.Status == types.DataExportCompleted {
+		link, err := h.service.ExportDownloadLink(export)
+		if err != nil {
+			return reportExportResp{}, err
+		}
+		downloadLink = &link
+	}
+
+	return reportExportResp{
+		Id:           export.Id,
+		Dataset:      export.Dataset,
+		Format:       export.Format,
+		Start:        export.StartDay.Format(dateLayout),
+		End:          export.EndDay.Format(dateLayout),
+		Status:       export.Status,
+		Error:        export.Error,
+		CreatedAt:    export.CreatedAt,
+		FinishedAt:   export.FinishedAt,
+		ExpiresAt:    export.ExpiresAt,
+		DownloadLink: downloadLink,
+	}, nil
+}
+
+func (h *Handler) mapToReportExportsResp(exports []domain.ReportExport) ([]reportExportResp, error) {
+	result := make([]reportExportResp, len(exports))
+	for i, export := range exports {
+		resp, err := h.mapToReportExportResp(export)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = resp
+	}
+
+	return result, nil
+}
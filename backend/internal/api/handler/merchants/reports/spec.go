@@ -0,0 +1,52 @@
+package reports
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Reports",
+		openapi.Operation{
+			Handler:  h.GetReport,
+			Summary:  "Get the booking statistics of a period compared to the period before it",
+			Response: getReportResp{},
+			Params: []openapi.Param{
+				{Name: "start", In: openapi.InQuery, Required: true, Description: "First day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "end", In: openapi.InQuery, Required: true, Description: "Last day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "granularity", In: openapi.InQuery, Type: types.ReportGranularity{}, Description: "Size of the buckets of the series, day by default"},
+				{Name: "group_by", In: openapi.InQuery, Type: types.ReportGroupBy{}, Description: "Breaks the totals down by the given dimension"},
+			},
+		},
+		openapi.Operation{
+			Handler:  h.GetUtilization,
+			Summary:  "Get how busy the employees were during their working hours, with the occupancy of the locations and the hours of the week",
+			Response: getUtilizationResp{},
+			Params: []openapi.Param{
+				{Name: "start", In: openapi.InQuery, Required: true, Description: "First day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "end", In: openapi.InQuery, Required: true, Description: "Last day of the period in the timezone of the merchant, YYYY-MM-DD"},
+			},
+		},
+		openapi.Operation{
+			Handler:  h.GetRetention,
+			Summary:  "Get how the customers come back, with first visit cohorts, rebooking intervals, customers at risk of churning and lifetime value",
+			Response: getRetentionResp{},
+			Params: []openapi.Param{
+				{Name: "start", In: openapi.InQuery, Required: true, Description: "First day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "end", In: openapi.InQuery, Required: true, Description: "Last day of the period in the timezone of the merchant, YYYY-MM-DD"},
+				{Name: "granularity", In: openapi.InQuery, Type: types.ReportGranularity{}, Description: "Size of the buckets of the new and returning customers, month by default"},
+			},
+		},
+		openapi.Operation{Handler: h.GetSubscriptions, Summary: "Get the scheduled report emails of the merchant", Response: []reportSubscriptionResp{}},
+		openapi.Operation{Handler: h.NewSubscription, Summary: "Schedule a report email to employees", Request: newSubscriptionReq{}, Response: newSubscriptionResp{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.UpdateSubscription, Summary: "Update the recipients of a scheduled report email or pause it", Request: updateSubscriptionReq{}, Params: idParam},
+		openapi.Operation{Handler: h.DeleteSubscription, Summary: "Delete a scheduled report email", Params: idParam},
+		openapi.Operation{Handler: h.NewExport, Summary: "Request a data export which is generated in the background", Request: newExportReq{}, Response: newExportResp{}, Status: http.StatusAccepted},
+		openapi.Operation{Handler: h.GetExports, Summary: "Get the recent data exports of the merchant", Response: []reportExportResp{}},
+		openapi.Operation{Handler: h.GetExport, Summary: "Get the status of a data export with its download link once it is completed", Response: reportExportResp{}, Params: idParam},
+	)
+}
@@ -6,27 +6,34 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	catalogServ "github.com/miketsu-inc/reservations/backend/internal/service/catalog"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
 	"github.com/miketsu-inc/reservations/backend/pkg/validate"
 )
 
 type Handler struct {
-	service *catalogServ.Service
+	service    *catalogServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *catalogServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *catalogServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.New)
-	r.Put("/{id}", h.Update)
-	r.Delete("/{id}", h.Delete)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
 
-	r.Put("/reorder", h.ReorderCategories)
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+
+		r.Put("/reorder", h.ReorderCategories)
+	})
 
 	return r
 }
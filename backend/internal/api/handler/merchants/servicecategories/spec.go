@@ -0,0 +1,16 @@
+package servicecategories
+
+import (
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Service categories",
+		openapi.Operation{Handler: h.New, Summary: "Create a service category", Request: newReq{}},
+		openapi.Operation{Handler: h.Update, Summary: "Rename a service category", Request: updateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a service category", Params: idParam},
+		openapi.Operation{Handler: h.ReorderCategories, Summary: "Set the order of the service categories", Request: reorderCategoriesReq{}},
+	)
+}
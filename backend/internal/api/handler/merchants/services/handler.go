@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	catalogServ "github.com/miketsu-inc/reservations/backend/internal/service/catalog"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
@@ -15,33 +16,52 @@ import (
 )
 
 type Handler struct {
-	service *catalogServ.Service
+	service    *catalogServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *catalogServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *catalogServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.New)
-	r.Put("/{id}", h.Update)
-	r.Delete("/{id}", h.Delete)
-	r.Get("/{id}", h.Get)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionCatalogEdit))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
 
-	r.Put("/{id}/products", h.UpdateServiceProduct)
-	// TODO: maybe replace these by a unified status route?
-	r.Patch("/{id}/activate", h.Activate)
-	r.Patch("/{id}/deactivate", h.Deactivate)
+		r.Put("/{id}/products", h.UpdateServiceProduct)
+		// TODO: maybe replace these by a unified status route?
+		r.Patch("/{id}/activate", h.Activate)
+		r.Patch("/{id}/deactivate", h.Deactivate)
 
+		r.Put("/reorder", h.Reorder)
+	})
+
+	r.Get("/{id}", h.Get)
 	r.Get("/", h.GetAll)
-	r.Put("/reorder", h.Reorder)
 	r.Get("/form-options", h.GetFormOptions)
 
 	return r
 }
 
+// Routes of the public api, should be mounted behind the api key authentication middleware
+func (h *Handler) ExternalRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	// like for employees, reading the services does not need a permission
+	r.Use(h.middleware.RequireScope(types.ApiKeyScopeServicesRead))
+
+	r.Get("/", h.GetAll)
+	r.Get("/{id}", h.Get)
+
+	return r
+}
+
 type newReq struct {
 	BookingType     types.BookingType      `json:"booking_type" validate:"required"`
 	Name            string                 `json:"name" validate:"required"`
@@ -53,6 +73,7 @@ type newReq struct {
 	MinParticipants *int                   `json:"min_participants"`
 	MaxParticipants *int                   `json:"max_participants"`
 	IsActive        bool                   `json:"is_active"`
+	TaxRateId       *int                   `json:"tax_rate_id"`
 	Settings        serviceSettingsReq     `json:"settings"`
 	Phases          []newPhaseReq          `json:"phases" validate:"required"`
 	UsedProducts    []connectedProductsReq `json:"used_products" validate:"required"`
@@ -107,6 +128,7 @@ type updateReq struct {
 	MinParticipants *int               `json:"min_participants"`
 	MaxParticipants *int               `json:"max_participants"`
 	IsActive        bool               `json:"is_active"`
+	TaxRateId       *int               `json:"tax_rate_id"`
 	Settings        serviceSettingsReq `json:"settings"`
 	Phases          []phaseReq         `json:"phases" validate:"required"`
 }
@@ -170,6 +192,7 @@ type getResp struct {
 	Price           *currencyx.Price   `json:"price"`
 	PriceType       types.PriceType    `json:"price_type"`
 	IsActive        bool               `json:"is_active"`
+	TaxRateId       *int               `json:"tax_rate_id"`
 	Sequence        int                `json:"sequence"`
 	MinParicipants  int                `json:"min_participants"`
 	MaxParticipants int                `json:"max_participants"`
@@ -314,6 +337,7 @@ func (h *Handler) Reorder(w http.ResponseWriter, r *http.Request) {
 type getFormOptionsResp struct {
 	Products   []minimalProductResp  `json:"products"`
 	Categories []serviceCategoryResp `json:"categories"`
+	TaxRates   []taxRateResp         `json:"tax_rates"`
 }
 
 type minimalProductResp struct {
@@ -330,6 +354,13 @@ type serviceCategoryResp struct {
 	Sequence   int       `json:"sequence"`
 }
 
+type taxRateResp struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Percent     string `json:"percent"`
+	IsInclusive bool   `json:"is_inclusive"`
+}
+
 func (h *Handler) GetFormOptions(w http.ResponseWriter, r *http.Request) {
 	formOptions, err := h.service.GetFormOptions(r.Context())
 	if err != nil {
@@ -37,6 +37,7 @@ func mapToNewInput(in newReq) catalogServ.NewInput {
 		MinParticipants: in.MinParticipants,
 		MaxParticipants: in.MaxParticipants,
 		IsActive:        in.IsActive,
+		TaxRateId:       in.TaxRateId,
 		Settings: catalogServ.ServiceSettingsInput{
 			CancelDeadline:   in.Settings.CancelDeadline,
 			BookingWindowMin: in.Settings.BookingWindowMin,
@@ -74,6 +75,7 @@ func mapToUpdateInput(in updateReq) catalogServ.UpdateInput {
 		MinParticipants: in.MinParticipants,
 		MaxParticipants: in.MaxParticipants,
 		IsActive:        in.IsActive,
+		TaxRateId:       in.TaxRateId,
 		Settings: catalogServ.ServiceSettingsInput{
 			CancelDeadline:   in.Settings.CancelDeadline,
 			BookingWindowMin: in.Settings.BookingWindowMin,
@@ -120,6 +122,7 @@ func mapToGetResp(in domain.ServicePageData) getResp {
 		Price:           in.Price,
 		PriceType:       in.PriceType,
 		IsActive:        in.IsActive,
+		TaxRateId:       in.TaxRateId,
 		Sequence:        in.Sequence,
 		MinParicipants:  in.MinParicipants,
 		MaxParticipants: in.MaxParticipants,
@@ -230,8 +233,20 @@ func mapToGetFormOptionsResp(in domain.ServicePageFormOptions) getFormOptionsRes
 		}
 	}
 
+	taxRates := make([]taxRateResp, len(in.TaxRates))
+
+	for i, t := range in.TaxRates {
+		taxRates[i] = taxRateResp{
+			Id:          t.Id,
+			Name:        t.Name,
+			Percent:     t.Percent,
+			IsInclusive: t.IsInclusive,
+		}
+	}
+
 	return getFormOptionsResp{
 		Products:   products,
 		Categories: categories,
+		TaxRates:   taxRates,
 	}
 }
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Services",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the services grouped by category", Response: []getAllResp{}},
+		openapi.Operation{Handler: h.Get, Summary: "Get a service", Response: getResp{}, Params: idParam},
+		openapi.Operation{Handler: h.GetFormOptions, Summary: "Get the options of the service form", Response: getFormOptionsResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create a service", Request: newReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a service", Request: updateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a service", Params: idParam},
+		openapi.Operation{Handler: h.UpdateServiceProduct, Summary: "Set the products used by a service", Request: updateServiceProductReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Activate, Summary: "Make a service bookable", Params: idParam},
+		openapi.Operation{Handler: h.Deactivate, Summary: "Make a service unbookable", Params: idParam},
+		openapi.Operation{Handler: h.Reorder, Summary: "Set the order of the services in a category", Request: reorderReq{}},
+	)
+}
@@ -0,0 +1,44 @@
+package merchants
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Merchants",
+		openapi.Operation{Handler: h.Me, Summary: "Get the employee of the user at the merchant", Response: meResp{}},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete the merchant"},
+		openapi.Operation{Handler: h.UpdateName, Summary: "Rename the merchant", Request: updateNameReq{}},
+		openapi.Operation{
+			Handler:  h.GetDashboard,
+			Summary:  "Get the dashboard of the merchant",
+			Response: getDashboardResp{},
+			Params: []openapi.Param{
+				{Name: "date", In: openapi.InQuery, Required: true, Type: time.Time{}},
+				{Name: "period", In: openapi.InQuery, Required: true, Type: 0, Description: "Length of the period in days"},
+			},
+		},
+		openapi.Operation{Handler: h.CheckUrl, Summary: "Check if the url of a merchant name is free", Request: checkUrlReq{}, Response: checkUrlResp{}},
+		openapi.Operation{Handler: h.GetSettings, Summary: "Get the settings of the merchant", Response: getSettingsResp{}},
+		openapi.Operation{Handler: h.UpdateSettings, Summary: "Update the settings of the merchant", Request: updateSettingsReq{}},
+		openapi.Operation{Handler: h.GetNormalizedBusinessHours, Summary: "Get the business hours by day of the week", Response: map[int]timeSlotResp{}},
+		openapi.Operation{Handler: h.GetPreferences, Summary: "Get the calendar preferences", Response: getPreferencesResp{}},
+		openapi.Operation{Handler: h.UpdatePreferences, Summary: "Update the calendar preferences", Request: updatePreferencesReq{}},
+		openapi.Operation{Handler: h.GetTeamForCalendar, Summary: "Get the team members shown in the calendar", Response: []getTeamMembersForCalendarResp{}},
+		openapi.Operation{Handler: h.GetServicesForCalendar, Summary: "Get the services shown in the calendar", Response: []getServicesForCalendarResp{}},
+		openapi.Operation{Handler: h.GetCustomersForCalendar, Summary: "Get the customers shown in the calendar", Response: []getCustomersForCalendarResp{}},
+		openapi.Operation{
+			Handler:  h.GetCalendarEvents,
+			Summary:  "Get the bookings and blocked times of a period",
+			Response: getCalendarEventsResp{},
+			Params: []openapi.Param{
+				{Name: "start", In: openapi.InQuery, Required: true, Type: time.Time{}},
+				{Name: "end", In: openapi.InQuery, Required: true, Type: time.Time{}},
+			},
+		},
+		openapi.Operation{Handler: h.GoogleCalendar, Summary: "Redirect to the google calendar authorization", Status: http.StatusTemporaryRedirect},
+	)
+}
@@ -0,0 +1,118 @@
+package taxrates
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	catalogServ "github.com/miketsu-inc/reservations/backend/internal/service/catalog"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *catalogServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *catalogServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetAll)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionSettingsManage))
+
+		r.Post("/", h.New)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+
+	return r
+}
+
+type taxRateReq struct {
+	Name        string `json:"name" validate:"required,max=30"`
+	Percent     string `json:"percent" validate:"required"`
+	IsInclusive bool   `json:"is_inclusive"`
+}
+
+type newResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
+	var req taxRateReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	taxRateId, err := h.service.NewTaxRate(r.Context(), mapToTaxRateInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newResp{Id: taxRateId})
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req taxRateReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlTaxRateId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid tax rate id provided"))
+		return
+	}
+
+	err = h.service.UpdateTaxRate(r.Context(), urlTaxRateId, mapToTaxRateInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	urlTaxRateId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid tax rate id provided"))
+		return
+	}
+
+	err = h.service.DeleteTaxRate(r.Context(), urlTaxRateId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type taxRateResp struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Percent     string `json:"percent"`
+	IsInclusive bool   `json:"is_inclusive"`
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	taxRates, err := h.service.GetTaxRates(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToTaxRatesResp(taxRates))
+}
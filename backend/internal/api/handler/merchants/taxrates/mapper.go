@@ -0,0 +1,29 @@
+package taxrates
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	catalogServ "github.com/miketsu-inc/reservations/backend/internal/service/catalog"
+)
+
+func mapToTaxRateInput(in taxRateReq) catalogServ.TaxRateInput {
+	return catalogServ.TaxRateInput{
+		Name:        in.Name,
+		Percent:     in.Percent,
+		IsInclusive: in.IsInclusive,
+	}
+}
+
+func mapToTaxRatesResp(in []domain.TaxRate) []taxRateResp {
+	out := make([]taxRateResp, len(in))
+
+	for i, t := range in {
+		out[i] = taxRateResp{
+			Id:          t.Id,
+			Name:        t.Name,
+			Percent:     t.Percent,
+			IsInclusive: t.IsInclusive,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,18 @@
+package taxrates
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Tax rates",
+		openapi.Operation{Handler: h.GetAll, Summary: "List the tax rates of the merchant", Response: []taxRateResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create a tax rate", Request: taxRateReq{}, Response: newResp{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.Update, Summary: "Update a tax rate", Request: taxRateReq{}, Params: idParam},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a tax rate", Params: idParam},
+	)
+}
@@ -8,6 +8,7 @@ import (
 func mapToNewMemberInput(in newMemberReq) teamServ.NewMemberInput {
 	return teamServ.NewMemberInput{
 		Role:        in.Role,
+		RoleId:      in.RoleId,
 		FirstName:   in.FirstName,
 		LastName:    in.LastName,
 		Email:       in.Email,
@@ -19,6 +20,7 @@ func mapToNewMemberInput(in newMemberReq) teamServ.NewMemberInput {
 func mapToUpdateMemberInput(in updateMemberReq) teamServ.UpdateMemberInput {
 	return teamServ.UpdateMemberInput{
 		Role:        in.Role,
+		RoleId:      in.RoleId,
 		FirstName:   in.FirstName,
 		LastName:    in.LastName,
 		Email:       in.Email,
@@ -31,6 +33,7 @@ func mapToGetMemberResp(in domain.PublicEmployee) getMemberResp {
 	return getMemberResp{
 		Id:          in.Id,
 		Role:        in.Role,
+		RoleId:      in.RoleId,
 		FirstName:   in.FirstName,
 		LastName:    in.LastName,
 		Email:       in.Email,
@@ -38,3 +41,24 @@ func mapToGetMemberResp(in domain.PublicEmployee) getMemberResp {
 		IsActive:    in.IsActive,
 	}
 }
+
+func mapToRoleInput(in roleReq) teamServ.RoleInput {
+	return teamServ.RoleInput{
+		Name:        in.Name,
+		Permissions: in.Permissions,
+	}
+}
+
+func mapToRolesResp(in []domain.Role) []roleResp {
+	roles := make([]roleResp, len(in))
+
+	for i, r := range in {
+		roles[i] = roleResp{
+			Id:          r.Id,
+			Name:        r.Name,
+			Permissions: r.Permissions,
+		}
+	}
+
+	return roles
+}
@@ -0,0 +1,25 @@
+package team
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Team",
+		openapi.Operation{Handler: h.GetTeam, Summary: "Get the team members of the merchant", Response: []getMemberResp{}},
+		openapi.Operation{Handler: h.GetMember, Summary: "Get a team member", Response: getMemberResp{}, Params: idParam},
+		openapi.Operation{Handler: h.NewMember, Summary: "Add a team member", Request: newMemberReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.UpdateMember, Summary: "Update a team member", Request: updateMemberReq{}, Params: idParam},
+		openapi.Operation{Handler: h.DeleteMember, Summary: "Remove a team member", Params: idParam},
+		openapi.Operation{Handler: h.GetRoles, Summary: "Get the roles of the merchant", Response: []roleResp{}},
+		openapi.Operation{Handler: h.NewRole, Summary: "Create a custom role", Request: roleReq{}, Response: newRoleResp{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.UpdateRole, Summary: "Update a custom role", Request: roleReq{}, Params: idParam},
+		openapi.Operation{Handler: h.DeleteRole, Summary: "Delete a custom role", Params: idParam},
+		openapi.Operation{Handler: h.GetPermissions, Summary: "Get the permissions a role can have", Response: []types.Permission{}},
+	)
+}
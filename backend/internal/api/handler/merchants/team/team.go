@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	teamServ "github.com/miketsu-inc/reservations/backend/internal/service/team"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
@@ -12,28 +13,40 @@ import (
 )
 
 type Handler struct {
-	service *teamServ.Service
+	service    *teamServ.Service
+	middleware *middleware.Manager
 }
 
-func NewHandler(s *teamServ.Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *teamServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", h.NewMember)
-	r.Put("/{id}", h.UpdateMember)
-	r.Delete("/{id}", h.DeleteMember)
-	r.Get("/{id}", h.GetMember)
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RequirePermission(types.PermissionTeamManage))
+
+		r.Post("/", h.NewMember)
+		r.Put("/{id}", h.UpdateMember)
+		r.Delete("/{id}", h.DeleteMember)
 
+		r.Get("/roles", h.GetRoles)
+		r.Post("/roles", h.NewRole)
+		r.Put("/roles/{id}", h.UpdateRole)
+		r.Delete("/roles/{id}", h.DeleteRole)
+	})
+
+	r.Get("/{id}", h.GetMember)
 	r.Get("/", h.GetTeam)
+	r.Get("/permissions", h.GetPermissions)
 
 	return r
 }
 
 type newMemberReq struct {
 	Role        types.EmployeeRole `json:"role" validate:"required"`
+	RoleId      *int               `json:"role_id"`
 	FirstName   string             `json:"first_name" validate:"required"`
 	LastName    string             `json:"last_name" validate:"required"`
 	Email       *string            `json:"email"`
@@ -60,6 +73,7 @@ func (h *Handler) NewMember(w http.ResponseWriter, r *http.Request) {
 
 type updateMemberReq struct {
 	Role        types.EmployeeRole `json:"role" validate:"required"`
+	RoleId      *int               `json:"role_id"`
 	FirstName   string             `json:"first_name" validate:"required"`
 	LastName    string             `json:"last_name" validate:"required"`
 	Email       *string            `json:"email"`
@@ -105,6 +119,7 @@ func (h *Handler) DeleteMember(w http.ResponseWriter, r *http.Request) {
 type getMemberResp struct {
 	Id          int                `json:"id"`
 	Role        types.EmployeeRole `json:"role"`
+	RoleId      *int               `json:"role_id"`
 	FirstName   *string            `json:"first_name"`
 	LastName    *string            `json:"last_name"`
 	Email       *string            `json:"email"`
@@ -142,3 +157,85 @@ func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
 
 	httputil.Success(w, http.StatusOK, result)
 }
+
+type roleReq struct {
+	Name        string             `json:"name" validate:"required,max=50"`
+	Permissions []types.Permission `json:"permissions" validate:"required"`
+}
+
+type newRoleResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewRole(w http.ResponseWriter, r *http.Request) {
+	var req roleReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	roleId, err := h.service.NewRole(r.Context(), mapToRoleInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newRoleResp{Id: roleId})
+}
+
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var req roleReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlRoleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.UpdateRole(r.Context(), urlRoleId, mapToRoleInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	urlRoleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.DeleteRole(r.Context(), urlRoleId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type roleResp struct {
+	Id          int                `json:"id"`
+	Name        string             `json:"name"`
+	Permissions []types.Permission `json:"permissions"`
+}
+
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.service.GetRoles(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToRolesResp(roles))
+}
+
+// Every permission that can be assigned to a custom role
+func (h *Handler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	httputil.Success(w, http.StatusOK, types.AssignablePermissions)
+}
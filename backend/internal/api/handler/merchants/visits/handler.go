@@ -0,0 +1,260 @@
+package visits
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	visitServ "github.com/miketsu-inc/reservations/backend/internal/service/visit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *visitServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *visitServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+// Visits are identified by the id of the booking participant. Access to the notes and attachments of a visit
+// depends on the booking's employee, it's checked by the service
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionCustomersView))
+
+	r.Get("/", h.GetByCustomer)
+
+	r.Post("/{id}/notes", h.NewNote)
+	r.Put("/{id}/notes/{note_id}", h.UpdateNote)
+	r.Delete("/{id}/notes/{note_id}", h.DeleteNote)
+
+	r.Post("/{id}/attachments", h.NewAttachment)
+	r.Get("/{id}/attachments/{attachment_id}", h.GetAttachment)
+	r.Delete("/{id}/attachments/{attachment_id}", h.DeleteAttachment)
+
+	return r
+}
+
+type visitNoteResp struct {
+	Id         int       `json:"id"`
+	EmployeeId *int      `json:"employee_id"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type visitAttachmentResp struct {
+	Id          int       `json:"id"`
+	EmployeeId  *int      `json:"employee_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type visitResp struct {
+	Id          int                   `json:"id"`
+	BookingId   int                   `json:"booking_id"`
+	EmployeeId  *int                  `json:"employee_id"`
+	ServiceName string                `json:"service_name"`
+	Status      types.BookingStatus   `json:"status"`
+	FromDate    time.Time             `json:"from_date"`
+	ToDate      time.Time             `json:"to_date"`
+	Notes       []visitNoteResp       `json:"notes"`
+	Attachments []visitAttachmentResp `json:"attachments"`
+}
+
+func (h *Handler) GetByCustomer(w http.ResponseWriter, r *http.Request) {
+	customerId, err := uuid.Parse(r.URL.Query().Get("customer_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid customer id: %s", err.Error()))
+		return
+	}
+
+	visits, err := h.service.GetCustomerVisits(r.Context(), customerId)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToVisitsResp(visits))
+}
+
+type noteReq struct {
+	Note string `json:"note" validate:"required"`
+}
+
+type newNoteResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) NewNote(w http.ResponseWriter, r *http.Request) {
+	var req noteReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	noteId, err := h.service.NewNote(r.Context(), participantId, req.Note)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newNoteResp{Id: noteId})
+}
+
+func (h *Handler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	var req noteReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	noteId, err := strconv.Atoi(chi.URLParam(r, "note_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid note id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.UpdateNote(r.Context(), participantId, noteId, req.Note)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	noteId, err := strconv.Atoi(chi.URLParam(r, "note_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid note id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeleteNote(r.Context(), participantId, noteId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type newAttachmentResp struct {
+	Id int `json:"id"`
+}
+
+// Expects a multipart form with the file in the file field
+func (h *Handler) NewAttachment(w http.ResponseWriter, r *http.Request) {
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	// leaves room for the other parts of the form
+	r.Body = http.MaxBytesReader(w, r.Body, visitServ.MaxAttachmentSize+1<<20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			httputil.Error(w, http.StatusRequestEntityTooLarge, fmt.Errorf("attachments can be at most %d MB", visitServ.MaxAttachmentSize>>20))
+			return
+		}
+
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("the attachment could not be read: %s", err.Error()))
+		return
+	}
+	defer file.Close()
+
+	attachmentId, err := h.service.NewAttachment(r.Context(), participantId, visitServ.AttachmentInput{
+		FileName: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	})
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newAttachmentResp{Id: attachmentId})
+}
+
+func (h *Handler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	attachmentId, err := strconv.Atoi(chi.URLParam(r, "attachment_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid attachment id: %s", err.Error()))
+		return
+	}
+
+	attachment, content, err := h.service.GetAttachment(r.Context(), participantId, attachmentId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, content) // nolint:errcheck
+}
+
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	participantId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid visit id: %s", err.Error()))
+		return
+	}
+
+	attachmentId, err := strconv.Atoi(chi.URLParam(r, "attachment_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid attachment id: %s", err.Error()))
+		return
+	}
+
+	err = h.service.DeleteAttachment(r.Context(), participantId, attachmentId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
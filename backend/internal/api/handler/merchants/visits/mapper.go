@@ -0,0 +1,46 @@
+package visits
+
+import "github.com/miketsu-inc/reservations/backend/internal/domain"
+
+func mapToVisitsResp(in []domain.VisitRecord) []visitResp {
+	out := make([]visitResp, len(in))
+
+	for i, v := range in {
+		notes := make([]visitNoteResp, len(v.Notes))
+		for j, n := range v.Notes {
+			notes[j] = visitNoteResp{
+				Id:         n.Id,
+				EmployeeId: n.EmployeeId,
+				Note:       n.Note,
+				CreatedAt:  n.CreatedAt,
+				UpdatedAt:  n.UpdatedAt,
+			}
+		}
+
+		attachments := make([]visitAttachmentResp, len(v.Attachments))
+		for j, a := range v.Attachments {
+			attachments[j] = visitAttachmentResp{
+				Id:          a.Id,
+				EmployeeId:  a.EmployeeId,
+				FileName:    a.FileName,
+				ContentType: a.ContentType,
+				Size:        a.Size,
+				CreatedAt:   a.CreatedAt,
+			}
+		}
+
+		out[i] = visitResp{
+			Id:          v.ParticipantId,
+			BookingId:   v.BookingId,
+			EmployeeId:  v.EmployeeId,
+			ServiceName: v.ServiceName,
+			Status:      v.Status,
+			FromDate:    v.FromDate,
+			ToDate:      v.ToDate,
+			Notes:       notes,
+			Attachments: attachments,
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,48 @@
+package visits
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0, Description: "Id of the booking participant"}}
+	noteParams := append([]openapi.Param{{Name: "note_id", In: openapi.InPath, Type: 0}}, idParam...)
+	attachmentParams := append([]openapi.Param{{Name: "attachment_id", In: openapi.InPath, Type: 0}}, idParam...)
+
+	return openapi.WithTag("Visits",
+		openapi.Operation{
+			Handler:  h.GetByCustomer,
+			Summary:  "Get the past visits of a customer, with the notes and attachments the employee can access",
+			Response: []visitResp{},
+			Params:   []openapi.Param{{Name: "customer_id", In: openapi.InQuery, Type: uuid.UUID{}, Required: true}},
+		},
+		openapi.Operation{
+			Handler:  h.NewNote,
+			Summary:  "Add a private note to a visit",
+			Request:  noteReq{},
+			Response: newNoteResp{},
+			Status:   http.StatusCreated,
+			Params:   idParam,
+		},
+		openapi.Operation{Handler: h.UpdateNote, Summary: "Update a note of a visit", Request: noteReq{}, Params: noteParams},
+		openapi.Operation{Handler: h.DeleteNote, Summary: "Delete a note of a visit", Params: noteParams},
+		openapi.Operation{
+			Handler:  h.NewAttachment,
+			Summary:  "Attach a jpeg, png, webp, gif image or pdf file of at most 10 MB to a visit",
+			Upload:   "file",
+			Response: newAttachmentResp{},
+			Status:   http.StatusCreated,
+			Params:   idParam,
+		},
+		openapi.Operation{
+			Handler: h.GetAttachment,
+			Summary: "Download an attachment of a visit",
+			Files:   []string{"image/jpeg", "image/png", "image/webp", "image/gif", "application/pdf"},
+			Params:  attachmentParams,
+		},
+		openapi.Operation{Handler: h.DeleteAttachment, Summary: "Delete an attachment of a visit", Params: attachmentParams},
+	)
+}
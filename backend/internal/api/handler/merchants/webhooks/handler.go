@@ -0,0 +1,208 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	webhookServ "github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+type Handler struct {
+	service    *webhookServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *webhookServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(h.middleware.RequirePermission(types.PermissionSettingsManage))
+
+	r.Get("/", h.GetAll)
+	r.Post("/", h.New)
+	r.Get("/events", h.GetEventTypes)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+	r.Get("/{id}/deliveries", h.GetDeliveries)
+	r.Post("/{id}/deliveries/{deliveryId}/replay", h.ReplayDelivery)
+
+	return r
+}
+
+const maxPageSize = 100
+
+type endpointReq struct {
+	Url         string                   `json:"url" validate:"required,url,max=2048"`
+	Description *string                  `json:"description" validate:"omitempty,max=255"`
+	Events      []types.WebhookEventType `json:"events" validate:"required,min=1"`
+	IsActive    bool                     `json:"is_active"`
+}
+
+type newEndpointResp struct {
+	Id     int    `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func (h *Handler) New(w http.ResponseWriter, r *http.Request) {
+	var req endpointReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.NewEndpoint(r.Context(), mapToEndpointInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusCreated, newEndpointResp{
+		Id:     result.Id,
+		Secret: result.Secret,
+	})
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req endpointReq
+
+	if err := validate.ParseStruct(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlEndpointId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.UpdateEndpoint(r.Context(), urlEndpointId, mapToEndpointInput(req))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	urlEndpointId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.DeleteEndpoint(r.Context(), urlEndpointId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+}
+
+type endpointResp struct {
+	Id          int                      `json:"id"`
+	Url         string                   `json:"url"`
+	Description *string                  `json:"description"`
+	Events      []types.WebhookEventType `json:"events"`
+	IsActive    bool                     `json:"is_active"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.service.GetEndpoints(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToEndpointsResp(endpoints))
+}
+
+func (h *Handler) GetEventTypes(w http.ResponseWriter, r *http.Request) {
+	httputil.Success(w, http.StatusOK, types.WebhookEventTypes)
+}
+
+type deliveryResp struct {
+	Id             int64                       `json:"id"`
+	EventId        uuid.UUID                   `json:"event_id"`
+	EventType      types.WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage             `json:"payload"`
+	Status         types.WebhookDeliveryStatus `json:"status"`
+	Attempts       int                         `json:"attempts"`
+	ResponseStatus *int                        `json:"response_status"`
+	Error          *string                     `json:"error"`
+	CreatedAt      time.Time                   `json:"created_at"`
+	LastAttemptAt  *time.Time                  `json:"last_attempt_at"`
+	DeliveredAt    *time.Time                  `json:"delivered_at"`
+}
+
+type getDeliveriesResp struct {
+	Deliveries  []deliveryResp `json:"deliveries"`
+	HasNextPage bool           `json:"has_next_page"`
+	NextCursor  *string        `json:"next_cursor"`
+}
+
+func (h *Handler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	urlEndpointId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	pageSize := 50
+	if limit := query.Get("limit"); limit != "" {
+		pageSize, err = strconv.Atoi(limit)
+		if err != nil || pageSize < 1 {
+			httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid limit query parameter"))
+			return
+		}
+
+		if pageSize > maxPageSize {
+			httputil.Error(w, http.StatusBadRequest, fmt.Errorf("limit cannot be higher than %d", maxPageSize))
+			return
+		}
+	}
+
+	deliveries, err := h.service.GetDeliveries(r.Context(), urlEndpointId, query.Get("cursor"), pageSize)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetDeliveriesResp(deliveries))
+}
+
+func (h *Handler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	urlEndpointId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	urlDeliveryId, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.ReplayDelivery(r.Context(), urlEndpointId, urlDeliveryId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	webhookServ "github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+)
+
+func mapToEndpointInput(in endpointReq) webhookServ.EndpointInput {
+	return webhookServ.EndpointInput{
+		Url:         in.Url,
+		Description: in.Description,
+		Events:      in.Events,
+		IsActive:    in.IsActive,
+	}
+}
+
+func mapToEndpointsResp(in []domain.WebhookEndpoint) []endpointResp {
+	out := make([]endpointResp, len(in))
+
+	for i, e := range in {
+		out[i] = endpointResp{
+			Id:          e.Id,
+			Url:         e.Url,
+			Description: e.Description,
+			Events:      e.Events,
+			IsActive:    e.IsActive,
+			CreatedAt:   e.CreatedAt,
+		}
+	}
+
+	return out
+}
+
+func mapToGetDeliveriesResp(in webhookServ.GetDeliveriesResult) getDeliveriesResp {
+	deliveries := make([]deliveryResp, len(in.Deliveries))
+
+	for i, d := range in.Deliveries {
+		deliveries[i] = deliveryResp{
+			Id:             d.Id,
+			EventId:        d.EventId,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			LastAttemptAt:  d.LastAttemptAt,
+			DeliveredAt:    d.DeliveredAt,
+		}
+	}
+
+	return getDeliveriesResp{
+		Deliveries:  deliveries,
+		HasNextPage: in.HasNextPage,
+		NextCursor:  in.NextCursor,
+	}
+}
@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := openapi.Param{Name: "id", In: openapi.InPath, Type: 0}
+
+	return openapi.WithTag("Webhooks",
+		openapi.Operation{Handler: h.GetAll, Summary: "Get the webhook endpoints of the merchant", Response: []endpointResp{}},
+		openapi.Operation{Handler: h.New, Summary: "Create a webhook endpoint, the secret is only returned here", Request: endpointReq{}, Response: newEndpointResp{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.GetEventTypes, Summary: "Get the events a webhook endpoint can subscribe to", Response: []types.WebhookEventType{}},
+		openapi.Operation{Handler: h.Update, Summary: "Update a webhook endpoint", Request: endpointReq{}, Params: []openapi.Param{idParam}},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete a webhook endpoint", Params: []openapi.Param{idParam}},
+		openapi.Operation{
+			Handler:  h.GetDeliveries,
+			Summary:  "Get the deliveries of a webhook endpoint, newest first",
+			Response: getDeliveriesResp{},
+			Params: []openapi.Param{
+				idParam,
+				{Name: "limit", In: openapi.InQuery, Type: 0, Description: "Page size, 50 by default"},
+				{Name: "cursor", In: openapi.InQuery},
+			},
+		},
+		openapi.Operation{
+			Handler: h.ReplayDelivery,
+			Summary: "Send a delivery again",
+			Status:  http.StatusAccepted,
+			Params:  []openapi.Param{idParam, {Name: "deliveryId", In: openapi.InPath, Type: int64(0)}},
+		},
+	)
+}
@@ -8,6 +8,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	bookingServ "github.com/miketsu-inc/reservations/backend/internal/service/booking"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
@@ -31,7 +34,11 @@ func (h *Handler) Routes() chi.Router {
 		r.Use(h.middleware.JwtAuthentication)
 		r.Use(h.middleware.Language)
 
-		r.Post("/", h.CreateByCustomer)
+		r.With(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "public_booking_user", Limit: 10, Window: time.Hour, KeyBy: ratelimit.ByUser},
+			ratelimit.Policy{Name: "public_booking_ip", Limit: 30, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		)).Post("/", h.CreateByCustomer)
+		r.Get("/intake-form", h.GetIntakeForm)
 		r.Delete("/{id}", h.CancelByCustomer)
 		r.Get("/{id}", h.GetByCustomer)
 	})
@@ -47,6 +54,16 @@ type createBookingByCustomerReq struct {
 	CustomerNote string `json:"customer_note"`
 	// only present on group bookings
 	BookingId *int `json:"booking_id"`
+	// agreeing to receive marketing emails from the merchant
+	MarketingConsent *bool `json:"marketing_consent"`
+	// answers to the intake form of the service keyed by the id of the question
+	IntakeAnswers map[string]any `json:"intake_answers"`
+	// full name of the customer, required if they agreed to a consent question of the intake form
+	IntakeSignature string `json:"intake_signature"`
+	// pays the price of the booking as far as the balance of the gift card allows
+	GiftCardCode *string `json:"gift_card_code"`
+	// the best automatic promotion is applied if it's missing
+	PromoCode *string `json:"promo_code"`
 }
 
 func (h *Handler) CreateByCustomer(w http.ResponseWriter, r *http.Request) {
@@ -129,5 +146,39 @@ func (h *Handler) GetByCustomer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetByCustomerResp(publicBooking))
+	httputil.Success(w, http.StatusOK, mapToGetByCustomerResp(publicBooking, lang.LangFromContext(r.Context())))
+}
+
+type intakeFormResp struct {
+	Id        int                     `json:"id"`
+	Name      string                  `json:"name"`
+	Mode      types.IntakeFormMode    `json:"mode"`
+	Questions []domain.IntakeQuestion `json:"questions"`
+}
+
+type getIntakeFormResp struct {
+	// null if the service has no form or the customer already answered the form which is asked only once
+	Form *intakeFormResp `json:"form"`
+}
+
+func (h *Handler) GetIntakeForm(w http.ResponseWriter, r *http.Request) {
+	merchantName := r.URL.Query().Get("merchant_name")
+	if merchantName == "" {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("merchant name is required"))
+		return
+	}
+
+	serviceId, err := strconv.Atoi(r.URL.Query().Get("service_id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid service id: %w", err))
+		return
+	}
+
+	form, err := h.service.GetIntakeFormForBooking(r.Context(), merchantName, serviceId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToGetIntakeFormResp(form))
 }
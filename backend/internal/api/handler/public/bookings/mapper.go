@@ -6,6 +6,8 @@ import (
 
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	bookingServ "github.com/miketsu-inc/reservations/backend/internal/service/booking"
+	"github.com/miketsu-inc/reservations/backend/internal/service/intake"
+	"golang.org/x/text/language"
 )
 
 func mapToCreateByCustomerInput(in createBookingByCustomerReq) (bookingServ.CreateByCustomerInput, error) {
@@ -14,13 +16,25 @@ func mapToCreateByCustomerInput(in createBookingByCustomerReq) (bookingServ.Crea
 		return bookingServ.CreateByCustomerInput{}, fmt.Errorf("timestamp could not be converted to time: %w", err)
 	}
 
+	var intakeInput *intake.ResponseInput
+	if in.IntakeAnswers != nil {
+		intakeInput = &intake.ResponseInput{
+			Answers:   in.IntakeAnswers,
+			Signature: in.IntakeSignature,
+		}
+	}
+
 	return bookingServ.CreateByCustomerInput{
-		MerchantName: in.MerchantName,
-		ServiceId:    in.ServiceId,
-		LocationId:   in.LocationId,
-		TimeStamp:    timeStamp,
-		CustomerNote: in.CustomerNote,
-		BookingId:    in.BookingId,
+		MerchantName:     in.MerchantName,
+		ServiceId:        in.ServiceId,
+		LocationId:       in.LocationId,
+		TimeStamp:        timeStamp,
+		CustomerNote:     in.CustomerNote,
+		BookingId:        in.BookingId,
+		MarketingConsent: in.MarketingConsent,
+		Intake:           intakeInput,
+		GiftCardCode:     in.GiftCardCode,
+		PromoCode:        in.PromoCode,
 	}, nil
 }
 
@@ -31,16 +45,31 @@ func mapToCancelByCustomerInput(in cancelByCustomerReq) bookingServ.CancelByCust
 	}
 }
 
-func mapToGetByCustomerResp(in domain.PublicBooking) getByCustomerResp {
+func mapToGetByCustomerResp(in domain.PublicBooking, locale language.Tag) getByCustomerResp {
 	return getByCustomerResp{
 		FromDate:          in.FromDate,
 		ToDate:            in.ToDate,
 		ServiceName:       in.ServiceName,
 		CancelDeadline:    in.CancelDeadline,
 		FormattedLocation: in.FormattedLocation,
-		Price:             in.Price.ToFormatted(),
+		Price:             in.Price.ToFormatted(locale),
 		PriceType:         in.PriceType,
 		MerchantName:      in.MerchantName,
 		Status:            in.Status,
 	}
 }
+
+func mapToGetIntakeFormResp(in *domain.IntakeForm) getIntakeFormResp {
+	if in == nil {
+		return getIntakeFormResp{}
+	}
+
+	return getIntakeFormResp{
+		Form: &intakeFormResp{
+			Id:        in.Id,
+			Name:      in.Name,
+			Mode:      in.Mode,
+			Questions: in.Questions,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package bookings
+
+import (
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	idParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Public bookings",
+		openapi.Operation{Handler: h.CreateByCustomer, Summary: "Book an appointment as a customer", Request: createBookingByCustomerReq{}, Status: http.StatusCreated},
+		openapi.Operation{Handler: h.CancelByCustomer, Summary: "Cancel a booking of the customer", Request: cancelByCustomerReq{}, Params: idParam},
+		openapi.Operation{
+			Handler:  h.GetIntakeForm,
+			Summary:  "Get the intake form the customer has to fill out to book the service",
+			Response: getIntakeFormResp{},
+			Params: []openapi.Param{
+				{Name: "merchant_name", In: openapi.InQuery, Required: true},
+				{Name: "service_id", In: openapi.InQuery, Type: 0, Required: true},
+			},
+		},
+		openapi.Operation{Handler: h.GetByCustomer, Summary: "Get a booking of the customer", Response: getByCustomerResp{}, Params: idParam},
+	)
+}
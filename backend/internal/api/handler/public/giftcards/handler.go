@@ -0,0 +1,55 @@
+package giftcards
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	giftCardServ "github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+type Handler struct {
+	service    *giftCardServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *giftCardServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// the codes can not be guessed, the limits keep it that way even when guessing from many addresses
+	r.Use(h.middleware.Language)
+	r.Use(h.middleware.RateLimit(
+		ratelimit.Policy{Name: "gift_card_balance_ip", Limit: 30, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		ratelimit.Policy{Name: "gift_card_balance_merchant", Limit: 300, Window: time.Hour, KeyBy: ratelimit.ByMerchant},
+	))
+
+	r.Get("/{code}", h.GetBalance)
+
+	return r
+}
+
+type balanceResp struct {
+	InitialValue currencyx.FormattedPrice `json:"initial_value"`
+	Balance      currencyx.FormattedPrice `json:"balance"`
+	ExpiresAt    *time.Time               `json:"expires_at"`
+	IsExpired    bool                     `json:"is_expired"`
+}
+
+func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	giftCard, err := h.service.GetBalance(r.Context(), chi.URLParam(r, "merchantName"), chi.URLParam(r, "code"))
+	if err != nil {
+		httputil.Error(w, http.StatusNotFound, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToBalanceResp(giftCard, lang.LangFromContext(r.Context()), time.Now()))
+}
@@ -0,0 +1,17 @@
+package giftcards
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"golang.org/x/text/language"
+)
+
+func mapToBalanceResp(giftCard domain.GiftCard, locale language.Tag, now time.Time) balanceResp {
+	return balanceResp{
+		InitialValue: giftCard.InitialValue.ToFormatted(locale),
+		Balance:      giftCard.Balance.ToFormatted(locale),
+		ExpiresAt:    giftCard.ExpiresAt,
+		IsExpired:    giftCard.IsExpired(now),
+	}
+}
@@ -0,0 +1,16 @@
+package giftcards
+
+import (
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Public gift cards",
+		openapi.Operation{
+			Handler:  h.GetBalance,
+			Summary:  "Look up the balance of a gift card by its code",
+			Response: balanceResp{},
+			Params:   []openapi.Param{{Name: "code", In: openapi.InPath, Description: "The code of the gift card, the case, spaces and dashes do not matter"}},
+		},
+	)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
 	merchantServ "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
@@ -128,7 +129,7 @@ func (h *Handler) GetServices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetServicesGroupedByCategories(services))
+	httputil.Success(w, http.StatusOK, mapToGetServicesGroupedByCategories(services, lang.LangFromContext(r.Context())))
 }
 
 type teamResponse struct {
@@ -228,7 +229,7 @@ func (h *Handler) GetServiceDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetServiceDetailsResp(serviceDetails))
+	httputil.Success(w, http.StatusOK, mapToGetServiceDetailsResp(serviceDetails, lang.LangFromContext(r.Context())))
 }
 
 type getSummaryResp struct {
@@ -280,7 +281,7 @@ func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetSummaryResp(summaryInfo))
+	httputil.Success(w, http.StatusOK, mapToGetSummaryResp(summaryInfo, lang.LangFromContext(r.Context())))
 }
 
 type getAvailabilityResp struct {
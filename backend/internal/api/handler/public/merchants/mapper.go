@@ -4,6 +4,7 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	merchantServ "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"golang.org/x/text/language"
 )
 
 func mapToGetInfo(in domain.MerchantInfo) getInfoResp {
@@ -50,7 +51,7 @@ func mapToGetInfo(in domain.MerchantInfo) getInfoResp {
 	}
 }
 
-func mapToGetServicesGroupedByCategories(in []domain.MerchantPageServicesGroupedByCategory) []servicesGroupedByCategoryResp {
+func mapToGetServicesGroupedByCategories(in []domain.MerchantPageServicesGroupedByCategory, locale language.Tag) []servicesGroupedByCategoryResp {
 	servicesGroupedByCategory := make([]servicesGroupedByCategoryResp, len(in))
 
 	for i, serv := range in {
@@ -63,7 +64,7 @@ func mapToGetServicesGroupedByCategories(in []domain.MerchantPageServicesGrouped
 				Name:            s.Name,
 				Description:     s.Description,
 				TotalDuration:   s.TotalDuration,
-				Price:           currencyx.FormatPrice(s.Price),
+				Price:           currencyx.FormatPrice(s.Price, locale),
 				PriceType:       s.PriceType,
 				MaxParticipants: s.MaxParticipants,
 				BookingType:     s.BookingType,
@@ -112,7 +113,7 @@ func mapToGetNormalizedBusinessHoursResp(in domain.BusinessHours) map[int]timeSl
 	return businessHours
 }
 
-func mapToGetServiceDetailsResp(in domain.PublicServiceDetails) getServiceDetailsResp {
+func mapToGetServiceDetailsResp(in domain.PublicServiceDetails, locale language.Tag) getServiceDetailsResp {
 	phases := make([]phaseResp, len(in.Phases))
 
 	for i, p := range in.Phases {
@@ -130,7 +131,7 @@ func mapToGetServiceDetailsResp(in domain.PublicServiceDetails) getServiceDetail
 		Name:              in.Name,
 		Description:       in.Description,
 		TotalDuration:     in.TotalDuration,
-		Price:             currencyx.FormatPrice(in.Price),
+		Price:             currencyx.FormatPrice(in.Price, locale),
 		PriceType:         in.PriceType,
 		FormattedLocation: in.FormattedLocation,
 		GeoPoint:          in.GeoPoint,
@@ -138,7 +139,7 @@ func mapToGetServiceDetailsResp(in domain.PublicServiceDetails) getServiceDetail
 	}
 }
 
-func mapToGetSummaryResp(in merchantServ.BookingSummary) getSummaryResp {
+func mapToGetSummaryResp(in merchantServ.BookingSummary, locale language.Tag) getSummaryResp {
 	resp := getSummaryResp{
 		MerchantName:      in.MerchantName,
 		FormattedLocation: in.Location,
@@ -146,7 +147,7 @@ func mapToGetSummaryResp(in merchantServ.BookingSummary) getSummaryResp {
 
 	if in.Service != nil {
 		resp.ServiceName = &in.Service.Name
-		resp.Price = currencyx.FormatPrice(in.Service.Price)
+		resp.Price = currencyx.FormatPrice(in.Service.Price, locale)
 		resp.PriceType = &in.Service.PriceType
 		resp.TotalDuration = &in.Service.TotalDuration
 	}
@@ -0,0 +1,63 @@
+package merchants
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	locationParam := openapi.Param{Name: "locationId", In: openapi.InPath, Type: 0}
+	serviceParam := openapi.Param{Name: "serviceId", In: openapi.InPath, Type: 0}
+
+	return openapi.WithTag("Public merchants",
+		openapi.Operation{Handler: h.GetInfo, Summary: "Get the public page of the merchant", Response: getInfoResp{}},
+		openapi.Operation{Handler: h.GetServices, Summary: "Get the bookable services grouped by category", Response: []servicesGroupedByCategoryResp{}},
+		openapi.Operation{Handler: h.GetTeam, Summary: "Get the public team members", Response: []teamResponse{}},
+		openapi.Operation{
+			Handler:  h.GetNormalizedBusinessHours,
+			Summary:  "Get the business hours of a location by day of the week",
+			Response: map[int]timeSlotResp{},
+			Params:   []openapi.Param{locationParam},
+		},
+		openapi.Operation{
+			Handler:  h.GetServiceDetails,
+			Summary:  "Get the details of a service",
+			Response: getServiceDetailsResp{},
+			Params:   []openapi.Param{locationParam, serviceParam},
+		},
+		openapi.Operation{
+			Handler:  h.GetSummary,
+			Summary:  "Get the summary of a booking before it's created",
+			Response: getSummaryResp{},
+			Params: []openapi.Param{
+				locationParam,
+				{Name: "serviceId", In: openapi.InQuery, Type: 0},
+				{Name: "employeeId", In: openapi.InQuery, Type: 0},
+			},
+		},
+		openapi.Operation{
+			Handler:  h.GetAvailability,
+			Summary:  "Get the available times of a service",
+			Response: []getAvailabilityResp{},
+			Params: []openapi.Param{
+				locationParam,
+				serviceParam,
+				{Name: "start", In: openapi.InQuery, Required: true, Type: time.Time{}},
+				{Name: "end", In: openapi.InQuery, Required: true, Type: time.Time{}},
+			},
+		},
+		openapi.Operation{
+			Handler:  h.GetNextAvailability,
+			Summary:  "Get the next available time of a service",
+			Response: getNextAvailabilityResp{},
+			Params:   []openapi.Param{locationParam, serviceParam},
+		},
+		openapi.Operation{
+			Handler:  h.GetDisabledDays,
+			Summary:  "Get the days when a service can not be booked",
+			Response: getDisabledDaysResp{},
+			Params:   []openapi.Param{locationParam, serviceParam},
+		},
+	)
+}
@@ -0,0 +1,57 @@
+package reportexports
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	reportServ "github.com/miketsu-inc/reservations/backend/internal/service/report"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/csvx"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/xlsx"
+)
+
+type Handler struct {
+	service    *reportServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *reportServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "report_export_download_ip", Limit: 30, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		))
+
+		r.Get("/{token}", h.Download)
+	})
+
+	return r
+}
+
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	export, file, err := h.service.DownloadExport(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		httputil.Error(w, http.StatusNotFound, err)
+		return
+	}
+
+	contentType := csvx.ContentType
+	if export.Format == types.ReportExportFormatXlsx {
+		contentType = xlsx.ContentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", reportServ.ExportFileName(export)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file) // nolint:errcheck
+}
@@ -0,0 +1,17 @@
+package reportexports
+
+import (
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+	"github.com/miketsu-inc/reservations/backend/pkg/xlsx"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	return openapi.WithTag("Report exports",
+		openapi.Operation{
+			Handler: h.Download,
+			Summary: "Download a completed data export with the signed link of the export",
+			Params:  []openapi.Param{{Name: "token", In: openapi.InPath, Description: "The token from the download link of the export"}},
+			Files:   []string{"text/csv", xlsx.ContentType},
+		},
+	)
+}
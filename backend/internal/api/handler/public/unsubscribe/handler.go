@@ -0,0 +1,59 @@
+package unsubscribe
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	customerServ "github.com/miketsu-inc/reservations/backend/internal/service/customer"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+type Handler struct {
+	service    *customerServ.Service
+	middleware *middleware.Manager
+}
+
+func NewHandler(s *customerServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, middleware: m}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.middleware.RateLimit(
+			ratelimit.Policy{Name: "unsubscribe_ip", Limit: 30, Window: time.Hour, KeyBy: ratelimit.ByIP},
+		))
+
+		r.Get("/{token}", h.Get)
+		// email clients send the one-click unsubscribe to this route as well
+		r.Post("/{token}", h.Unsubscribe)
+	})
+
+	return r
+}
+
+type getResp struct {
+	MerchantName string `json:"merchant_name"`
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	target, err := h.service.GetUnsubscribeTarget(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		httputil.Error(w, http.StatusNotFound, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, getResp{MerchantName: target.MerchantName})
+}
+
+func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	err := h.service.Unsubscribe(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		httputil.Error(w, http.StatusNotFound, err)
+		return
+	}
+}
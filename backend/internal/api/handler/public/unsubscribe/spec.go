@@ -0,0 +1,14 @@
+package unsubscribe
+
+import (
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	tokenParam := []openapi.Param{{Name: "token", In: openapi.InPath, Description: "The token from the unsubscribe link of a campaign email"}}
+
+	return openapi.WithTag("Unsubscribe",
+		openapi.Operation{Handler: h.Get, Summary: "Get the merchant the customer would unsubscribe from", Response: getResp{}, Params: tokenParam},
+		openapi.Operation{Handler: h.Unsubscribe, Summary: "Withdraw the email marketing consent of the customer", Params: tokenParam},
+	)
+}
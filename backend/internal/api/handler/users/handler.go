@@ -7,13 +7,18 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
 	authServ "github.com/miketsu-inc/reservations/backend/internal/service/auth"
 	bookingServ "github.com/miketsu-inc/reservations/backend/internal/service/booking"
+	invoiceServ "github.com/miketsu-inc/reservations/backend/internal/service/invoice"
 	userServ "github.com/miketsu-inc/reservations/backend/internal/service/user"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"github.com/miketsu-inc/reservations/backend/pkg/pdf"
 	"github.com/miketsu-inc/reservations/backend/pkg/validate"
 )
 
@@ -21,11 +26,12 @@ type Handler struct {
 	service     *userServ.Service
 	bookingServ *bookingServ.Service
 	authServ    *authServ.Service
+	invoiceServ *invoiceServ.Service
 	middleware  *middleware.Manager
 }
 
-func NewHandler(s *userServ.Service, b *bookingServ.Service, a *authServ.Service, m *middleware.Manager) *Handler {
-	return &Handler{service: s, bookingServ: b, authServ: a, middleware: m}
+func NewHandler(s *userServ.Service, b *bookingServ.Service, a *authServ.Service, i *invoiceServ.Service, m *middleware.Manager) *Handler {
+	return &Handler{service: s, bookingServ: b, authServ: a, invoiceServ: i, middleware: m}
 }
 
 func (h *Handler) Routes() chi.Router {
@@ -39,7 +45,16 @@ func (h *Handler) Routes() chi.Router {
 		r.Delete("/", h.Delete)
 
 		r.Get("/bookings", h.GetBookings)
+		r.Get("/bookings/{id}/invoice", h.DownloadInvoice)
 		r.Put("/password", h.UpdatePassword)
+
+		r.Get("/sessions", h.GetSessions)
+		r.Delete("/sessions/{id}", h.RevokeSession)
+
+		r.Post("/data-exports", h.RequestDataExport)
+		r.Get("/data-exports", h.GetDataExports)
+		r.Get("/data-exports/{id}", h.GetDataExport)
+		r.Get("/data-exports/{id}/download", h.DownloadDataExport)
 	})
 
 	return r
@@ -97,6 +112,7 @@ type bookingForUser struct {
 	ServiceName       string                   `json:"service_name"`
 	EmployeeFirstName *string                  `json:"employee_first_name"`
 	EmployeeLastName  *string                  `json:"employee_last_name"`
+	InvoiceNumber     *string                  `json:"invoice_number"`
 }
 
 func (h *Handler) GetBookings(w http.ResponseWriter, r *http.Request) {
@@ -125,7 +141,7 @@ func (h *Handler) GetBookings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.Success(w, http.StatusOK, mapToGetBookingsResp(bookings))
+	httputil.Success(w, http.StatusOK, mapToGetBookingsResp(bookings, lang.LangFromContext(r.Context())))
 }
 
 type updatePasswordReq struct {
@@ -141,7 +157,7 @@ func (h *Handler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.authServ.UpdatePassword(r.Context(), mapToUpdatePasswordInput(req))
+	tokens, err := h.authServ.UpdatePassword(r.Context(), mapToUpdatePasswordInput(req, r))
 	if err != nil {
 		httputil.Error(w, http.StatusBadRequest, err)
 		return
@@ -150,3 +166,131 @@ func (h *Handler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	jwt.SetJwtCookie(w, jwt.AccessToken, tokens.AccessToken)
 	jwt.SetJwtCookie(w, jwt.RefreshToken, tokens.RefreshToken)
 }
+
+type sessionResp struct {
+	Id         uuid.UUID `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IpAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+func (h *Handler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.authServ.GetSessions(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	currentSessionId, _ := jwt.GetSessionIDFromContext(r.Context())
+
+	httputil.Success(w, http.StatusOK, mapToSessionsResp(sessions, currentSessionId))
+}
+
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	sessionId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid session id provided"))
+		return
+	}
+
+	err = h.authServ.RevokeSession(r.Context(), sessionId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	currentSessionId, ok := jwt.GetSessionIDFromContext(r.Context())
+	if ok && currentSessionId == sessionId {
+		jwt.DeleteJwts(w)
+	}
+}
+
+type requestDataExportResp struct {
+	Id int `json:"id"`
+}
+
+func (h *Handler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	exportId, err := h.service.RequestDataExport(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusAccepted, requestDataExportResp{Id: exportId})
+}
+
+type dataExportResp struct {
+	Id         int                    `json:"id"`
+	Status     types.DataExportStatus `json:"status"`
+	Error      *string                `json:"error"`
+	CreatedAt  time.Time              `json:"created_at"`
+	FinishedAt *time.Time             `json:"finished_at"`
+	ExpiresAt  *time.Time             `json:"expires_at"`
+}
+
+func (h *Handler) GetDataExports(w http.ResponseWriter, r *http.Request) {
+	exports, err := h.service.GetDataExports(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToDataExportsResp(exports))
+}
+
+func (h *Handler) GetDataExport(w http.ResponseWriter, r *http.Request) {
+	exportId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid data export id provided"))
+		return
+	}
+
+	export, err := h.service.GetDataExport(r.Context(), exportId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	httputil.Success(w, http.StatusOK, mapToDataExportResp(export))
+}
+
+func (h *Handler) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	exportId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid data export id provided"))
+		return
+	}
+
+	file, err := h.service.DownloadDataExport(r.Context(), exportId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("data-export-%d.zip", exportId)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file) // nolint:errcheck
+}
+
+func (h *Handler) DownloadInvoice(w http.ResponseWriter, r *http.Request) {
+	bookingId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, fmt.Errorf("invalid booking id provided"))
+		return
+	}
+
+	invoice, file, err := h.invoiceServ.DownloadForUser(r.Context(), bookingId)
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", pdf.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoiceServ.FileName(invoice.InvoiceNumber)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file) // nolint:errcheck
+}
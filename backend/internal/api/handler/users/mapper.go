@@ -1,9 +1,15 @@
 package users
 
 import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	authServ "github.com/miketsu-inc/reservations/backend/internal/service/auth"
 	bookingServ "github.com/miketsu-inc/reservations/backend/internal/service/booking"
 	userServ "github.com/miketsu-inc/reservations/backend/internal/service/user"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+	"golang.org/x/text/language"
 )
 
 func mapToEditInput(in editReq) userServ.EditInput {
@@ -15,7 +21,7 @@ func mapToEditInput(in editReq) userServ.EditInput {
 	}
 }
 
-func mapToGetBookingsResp(in bookingServ.GetForUserResult) getBookingsResp {
+func mapToGetBookingsResp(in bookingServ.GetForUserResult, locale language.Tag) getBookingsResp {
 	bookings := make([]bookingForUser, len(in.Bookings))
 
 	for i, b := range in.Bookings {
@@ -26,13 +32,14 @@ func mapToGetBookingsResp(in bookingServ.GetForUserResult) getBookingsResp {
 			IsRecurring:       b.IsRecurring,
 			FromDate:          b.FromDate,
 			ToDate:            b.ToDate,
-			Price:             b.PricePerPerson.ToFormatted(),
+			Price:             b.PricePerPerson.ToFormatted(locale),
 			MerchantName:      b.MerchantName,
 			MerchantUrl:       b.MerchantUrl,
 			FormattedLocation: b.FormattedLocation,
 			ServiceName:       b.ServiceName,
 			EmployeeFirstName: b.EmployeeFirstName,
 			EmployeeLastName:  b.EmployeeLastName,
+			InvoiceNumber:     b.InvoiceNumber,
 		}
 	}
 
@@ -43,9 +50,52 @@ func mapToGetBookingsResp(in bookingServ.GetForUserResult) getBookingsResp {
 	}
 }
 
-func mapToUpdatePasswordInput(in updatePasswordReq) authServ.UpdatePasswordInput {
+func mapToUpdatePasswordInput(in updatePasswordReq, r *http.Request) authServ.UpdatePasswordInput {
 	return authServ.UpdatePasswordInput{
 		OldPassword: in.OldPassword,
 		NewPassword: in.NewPassword,
+		Metadata: authServ.SessionMetadata{
+			IpAddress: httputil.ClientIP(r),
+			UserAgent: r.UserAgent(),
+		},
+	}
+}
+
+func mapToSessionsResp(in []domain.Session, currentSessionId uuid.UUID) []sessionResp {
+	sessions := make([]sessionResp, len(in))
+
+	for i, s := range in {
+		sessions[i] = sessionResp{
+			Id:         s.Id,
+			DeviceName: s.DeviceName,
+			IpAddress:  s.IpAddress,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			IsCurrent:  s.Id == currentSessionId,
+		}
 	}
+
+	return sessions
+}
+
+func mapToDataExportResp(in domain.DataExport) dataExportResp {
+	return dataExportResp{
+		Id:         in.Id,
+		Status:     in.Status,
+		Error:      in.Error,
+		CreatedAt:  in.CreatedAt,
+		FinishedAt: in.FinishedAt,
+		ExpiresAt:  in.ExpiresAt,
+	}
+}
+
+func mapToDataExportsResp(in []domain.DataExport) []dataExportResp {
+	exports := make([]dataExportResp, len(in))
+
+	for i, e := range in {
+		exports[i] = mapToDataExportResp(e)
+	}
+
+	return exports
 }
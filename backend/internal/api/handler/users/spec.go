@@ -0,0 +1,51 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+	"github.com/miketsu-inc/reservations/backend/pkg/pdf"
+)
+
+func (h *Handler) Spec() []openapi.Operation {
+	exportIdParam := []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}}
+
+	return openapi.WithTag("Users",
+		openapi.Operation{Handler: h.Edit, Summary: "Edit the user", Request: editReq{}},
+		openapi.Operation{Handler: h.Delete, Summary: "Delete the user and anonymize its customer records at the merchants"},
+		openapi.Operation{
+			Handler:  h.GetBookings,
+			Summary:  "Get the bookings of the user",
+			Response: getBookingsResp{},
+			Params: []openapi.Param{
+				{Name: "status", In: openapi.InQuery, Required: true, Enum: []string{"upcoming", "completed", "cancelled"}},
+				{Name: "limit", In: openapi.InQuery, Required: true, Type: 0, Description: "At most 10"},
+				{Name: "cursor", In: openapi.InQuery},
+			},
+		},
+		openapi.Operation{
+			Handler: h.DownloadInvoice,
+			Summary: "Download the receipt of the participation of the user in a completed booking",
+			Files:   []string{pdf.ContentType},
+			Params:  []openapi.Param{{Name: "id", In: openapi.InPath, Type: 0}},
+		},
+		openapi.Operation{Handler: h.UpdatePassword, Summary: "Change the password of the user", Request: updatePasswordReq{}},
+		openapi.Operation{Handler: h.GetSessions, Summary: "Get the active sessions of the user", Response: []sessionResp{}},
+		openapi.Operation{Handler: h.RevokeSession, Summary: "Revoke a session of the user", Params: []openapi.Param{{Name: "id", In: openapi.InPath, Type: uuid.UUID{}}}},
+		openapi.Operation{
+			Handler:  h.RequestDataExport,
+			Summary:  "Export every data of the user into a zip archive in the background",
+			Response: requestDataExportResp{},
+			Status:   http.StatusAccepted,
+		},
+		openapi.Operation{Handler: h.GetDataExports, Summary: "Get the data exports of the user", Response: []dataExportResp{}},
+		openapi.Operation{Handler: h.GetDataExport, Summary: "Get the status of a data export", Response: dataExportResp{}, Params: exportIdParam},
+		openapi.Operation{
+			Handler: h.DownloadDataExport,
+			Summary: "Download the archive of a completed data export",
+			Files:   []string{"application/zip"},
+			Params:  exportIdParam,
+		},
+	)
+}
@@ -2,6 +2,7 @@ package actor
 
 import (
 	"context"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
@@ -17,13 +18,19 @@ var merchantIDCtxKey = &contextKey{"MerchantID"}
 var employeeIDCtxKey = &contextKey{"EmployeeID"}
 var locationIDCtxKey = &contextKey{"LocationID"}
 var employeeRoleCtxKey = &contextKey{"EmployeeRole"}
+var permissionsCtxKey = &contextKey{"Permissions"}
 
 type EmployeeContext struct {
-	UserId     uuid.UUID
-	MerchantId uuid.UUID
-	LocationId int
-	EmployeeId int
-	Role       types.EmployeeRole
+	UserId      uuid.UUID
+	MerchantId  uuid.UUID
+	LocationId  int
+	EmployeeId  int
+	Role        types.EmployeeRole
+	Permissions []types.Permission
+}
+
+func (e EmployeeContext) HasPermission(permission types.Permission) bool {
+	return slices.Contains(e.Permissions, permission)
 }
 
 // Get employee details from the request's context. Panics if not present!
@@ -40,11 +47,12 @@ func MustGetFromContext(ctx context.Context) EmployeeContext {
 	assert.True(hasMerchId, "merchant id not in context", ctx.Value(merchantIDCtxKey), merchantId, hasMerchId)
 
 	return EmployeeContext{
-		UserId:     userId,
-		MerchantId: merchantId,
-		LocationId: locationId,
-		EmployeeId: employeeId,
-		Role:       role,
+		UserId:      userId,
+		MerchantId:  merchantId,
+		LocationId:  locationId,
+		EmployeeId:  employeeId,
+		Role:        role,
+		Permissions: permissionsFromContext(ctx),
 	}
 }
 
@@ -61,11 +69,12 @@ func GetFromContext(ctx context.Context) (EmployeeContext, bool) {
 	}
 
 	return EmployeeContext{
-		UserId:     userId,
-		MerchantId: merchantId,
-		LocationId: locationId,
-		EmployeeId: employeeId,
-		Role:       role,
+		UserId:      userId,
+		MerchantId:  merchantId,
+		LocationId:  locationId,
+		EmployeeId:  employeeId,
+		Role:        role,
+		Permissions: permissionsFromContext(ctx),
 	}, true
 }
 
@@ -84,3 +93,16 @@ func SetLocationIdInContext(ctx context.Context, locationId int) context.Context
 func SetEmployeeRoleInContext(ctx context.Context, employeeRole types.EmployeeRole) context.Context {
 	return context.WithValue(ctx, employeeRoleCtxKey, employeeRole)
 }
+
+func SetPermissionsInContext(ctx context.Context, permissions []types.Permission) context.Context {
+	return context.WithValue(ctx, permissionsCtxKey, permissions)
+}
+
+func permissionsFromContext(ctx context.Context) []types.Permission {
+	permissions, ok := ctx.Value(permissionsCtxKey).([]types.Permission)
+	if !ok {
+		return []types.Permission{}
+	}
+
+	return permissions
+}
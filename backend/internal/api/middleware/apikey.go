@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/apikey"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/keys"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+const apiKeyRateLimitWindow = time.Minute
+
+// Api key authentication middleware for machine access. The key is sent in the
+// Authorization: Bearer header and the request acts as the employee who created the key,
+// with only the permissions granted by the key's scopes. Every key has it's own rate limit.
+func (m *Manager) ApiKeyAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !apikey.IsApiKey(token) {
+			httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("missing or malformed api key"))
+			return
+		}
+
+		info, err := m.apiKeyRepo.GetApiKeyAuthInfo(ctx, apikey.Hash(token))
+		if err != nil {
+			httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("invalid api key"))
+			return
+		}
+
+		if !info.IsActive(time.Now().UTC()) {
+			httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("api key is revoked or expired"))
+			return
+		}
+
+		if info.UserId == nil || !info.IsEmployeeActive {
+			httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("the employee of the api key is no longer active"))
+			return
+		}
+
+		key := keys.RateLimit{Policy: "api_key", Subject: strconv.Itoa(info.Id)}.String()
+
+		res, err := m.limiter.Hit(ctx, key, info.RateLimit, apiKeyRateLimitWindow)
+		if err != nil {
+			slog.ErrorContext(ctx, "rate limiter unavailable", "policy", "api_key", "error", err)
+		} else {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+
+			if !res.Allowed {
+				SetRetryAfter(w, res.RetryAfter)
+				httputil.Error(w, http.StatusTooManyRequests, fmt.Errorf("too many requests, please try again later"))
+				return
+			}
+		}
+
+		err = m.apiKeyRepo.TouchApiKey(ctx, info.Id)
+		if err != nil {
+			slog.ErrorContext(ctx, "could not update api key last used time", "api_key_id", info.Id, "error", err)
+		}
+
+		ctx = jwt.SetUserIdInContext(ctx, *info.UserId)
+		ctx = apikey.SetInContext(ctx, info.Id, info.Scopes)
+		ctx = actor.SetMerchantIdInContext(ctx, info.MerchantId)
+		ctx = actor.SetLocationIdInContext(ctx, info.Employee.LocationId)
+		ctx = actor.SetEmployeeIdInContext(ctx, info.EmployeeId)
+		ctx = actor.SetEmployeeRoleInContext(ctx, info.Employee.Role)
+		ctx = actor.SetPermissionsInContext(ctx, info.Permissions())
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Checks that the request's api key has the scope, should be called after the api key authentication middleware
+func (m *Manager) RequireScope(scope types.ApiKeyScope) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !apikey.HasScope(r.Context(), scope) {
+				httputil.Error(w, http.StatusForbidden, fmt.Errorf("this resource requires the %s scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
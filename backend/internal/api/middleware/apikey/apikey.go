@@ -0,0 +1,76 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Every api key starts with this, so leaked keys are easy to recognize
+const KeyPrefix = "rsv_"
+
+// Length of the start of the key that is stored to help identifying it
+const displayPrefixLength = 12
+
+type contextKey struct {
+	name string
+}
+
+var apiKeyIDCtxKey = &contextKey{"ApiKeyID"}
+var scopesCtxKey = &contextKey{"ApiKeyScopes"}
+
+// Creates a new random api key, only it's hash is stored in the database
+func New() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unexpected error when creating api key: %s", err.Error())
+	}
+
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash of the api key as it is stored in the database. The keys have enough entropy
+// that a slow password hash is not needed
+func Hash(key string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+}
+
+// Start of the key which is shown to the merchant, so they can tell their keys apart
+func DisplayPrefix(key string) string {
+	if len(key) < displayPrefixLength {
+		return key
+	}
+
+	return key[:displayPrefixLength]
+}
+
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, KeyPrefix)
+}
+
+func SetInContext(ctx context.Context, apiKeyId int, scopes []types.ApiKeyScope) context.Context {
+	ctx = context.WithValue(ctx, apiKeyIDCtxKey, apiKeyId)
+	return context.WithValue(ctx, scopesCtxKey, scopes)
+}
+
+// Returns the id of the api key the request was authenticated with
+func GetIdFromContext(ctx context.Context) (int, bool) {
+	apiKeyId, ok := ctx.Value(apiKeyIDCtxKey).(int)
+	return apiKeyId, ok
+}
+
+// Reports wether the request was authenticated with an api key which has the scope
+func HasScope(ctx context.Context, scope types.ApiKeyScope) bool {
+	scopes, ok := ctx.Value(scopesCtxKey).([]types.ApiKeyScope)
+	if !ok {
+		return false
+	}
+
+	return slices.Contains(scopes, scope)
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/apikey"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeApiKeyRepo struct {
+	keys    map[string]domain.ApiKeyAuthInfo
+	touched []int
+}
+
+func (f *fakeApiKeyRepo) WithTx(tx db.DBTX) domain.ApiKeyRepository { return f }
+
+func (f *fakeApiKeyRepo) NewApiKey(ctx context.Context, apiKey domain.ApiKey) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeApiKeyRepo) GetApiKeys(ctx context.Context, merchantId uuid.UUID) ([]domain.ApiKey, error) {
+	return nil, nil
+}
+
+func (f *fakeApiKeyRepo) RevokeApiKey(ctx context.Context, merchantId uuid.UUID, apiKeyId int) error {
+	return nil
+}
+
+func (f *fakeApiKeyRepo) GetApiKeyAuthInfo(ctx context.Context, keyHash string) (domain.ApiKeyAuthInfo, error) {
+	info, ok := f.keys[keyHash]
+	if !ok {
+		return domain.ApiKeyAuthInfo{}, fmt.Errorf("not found")
+	}
+
+	return info, nil
+}
+
+func (f *fakeApiKeyRepo) TouchApiKey(ctx context.Context, apiKeyId int) error {
+	f.touched = append(f.touched, apiKeyId)
+	return nil
+}
+
+func TestApiKeyAuthentication(t *testing.T) {
+	merchantId := uuid.New()
+	userId := uuid.New()
+	revokedAt := time.Now().Add(-time.Hour)
+
+	newInfo := func(id int, scopes []types.ApiKeyScope, role types.EmployeeRole) domain.ApiKeyAuthInfo {
+		return domain.ApiKeyAuthInfo{
+			ApiKey: domain.ApiKey{
+				Id:         id,
+				MerchantId: merchantId,
+				EmployeeId: 7,
+				Scopes:     scopes,
+				RateLimit:  2,
+			},
+			UserId:           &userId,
+			IsEmployeeActive: true,
+			Employee:         domain.EmployeeAuthInfo{Id: 7, LocationId: 3, MerchantId: merchantId, Role: role},
+		}
+	}
+
+	revoked := newInfo(2, []types.ApiKeyScope{types.ApiKeyScopeServicesRead}, types.EmployeeRoleOwner)
+	revoked.RevokedAt = &revokedAt
+
+	repo := &fakeApiKeyRepo{keys: map[string]domain.ApiKeyAuthInfo{
+		apikey.Hash("rsv_owner"):   newInfo(1, []types.ApiKeyScope{types.ApiKeyScopeBookingsWrite, types.ApiKeyScopeServicesRead}, types.EmployeeRoleOwner),
+		apikey.Hash("rsv_revoked"): revoked,
+		apikey.Hash("rsv_staff"):   newInfo(3, []types.ApiKeyScope{types.ApiKeyScopeBookingsWrite}, types.EmployeeRoleStaff),
+	}}
+
+	m := &Manager{apiKeyRepo: repo, limiter: ratelimit.NewMemoryStore()}
+
+	var got actor.EmployeeContext
+
+	h := m.ApiKeyAuthentication(m.RequireScope(types.ApiKeyScopeServicesRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = actor.MustGetFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	send := func(authorization string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	t.Run("missing or malformed header", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(""))
+		assert.Equal(t, http.StatusUnauthorized, send("rsv_owner"))
+		assert.Equal(t, http.StatusUnauthorized, send("Bearer not-an-api-key"))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send("Bearer rsv_unknown"))
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send("Bearer rsv_revoked"))
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send("Bearer rsv_staff"))
+	})
+
+	t.Run("valid key populates the employee context", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("Bearer rsv_owner"))
+
+		assert.Equal(t, merchantId, got.MerchantId)
+		assert.Equal(t, userId, got.UserId)
+		assert.Equal(t, 7, got.EmployeeId)
+		assert.Equal(t, 3, got.LocationId)
+		assert.Equal(t, []types.Permission{types.PermissionBookingsManageAll}, got.Permissions)
+		assert.Contains(t, repo.touched, 1)
+	})
+
+	t.Run("per key rate limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("Bearer rsv_owner"))
+		assert.Equal(t, http.StatusTooManyRequests, send("Bearer rsv_owner"))
+	})
+}
+
+func TestApiKeyPermissions(t *testing.T) {
+	info := domain.ApiKeyAuthInfo{
+		ApiKey: domain.ApiKey{
+			Scopes: []types.ApiKeyScope{types.ApiKeyScopeBookingsWrite, types.ApiKeyScopeCustomersRead},
+		},
+		Employee: domain.EmployeeAuthInfo{Role: types.EmployeeRoleStaff},
+	}
+
+	// staff can view customers but can not manage every booking
+	assert.Equal(t, []types.Permission{types.PermissionCustomersView}, info.Permissions())
+}
+
+func TestApiKeyScopeWithoutPermission(t *testing.T) {
+	userId := uuid.New()
+
+	repo := &fakeApiKeyRepo{keys: map[string]domain.ApiKeyAuthInfo{
+		apikey.Hash("rsv_staff"): {
+			ApiKey:           domain.ApiKey{Id: 1, EmployeeId: 7, Scopes: []types.ApiKeyScope{types.ApiKeyScopeBookingsWrite}, RateLimit: 10},
+			UserId:           &userId,
+			IsEmployeeActive: true,
+			Employee:         domain.EmployeeAuthInfo{Id: 7, Role: types.EmployeeRoleStaff},
+		},
+	}}
+
+	m := &Manager{apiKeyRepo: repo, limiter: ratelimit.NewMemoryStore()}
+
+	h := m.ApiKeyAuthentication(m.RequireScope(types.ApiKeyScopeBookingsWrite)(
+		m.RequirePermission(types.PermissionBookingsManageOwn, types.PermissionBookingsManageAll)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Authorization", "Bearer rsv_staff")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	// the key has the scope, but its employee can not manage every booking
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
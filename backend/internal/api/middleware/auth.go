@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	jwtlib "github.com/golang-jwt/jwt/v5"
@@ -23,7 +24,15 @@ func (m *Manager) JwtAuthentication(next http.Handler) http.Handler {
 
 		// try to verify request with access token
 		claims, err := verifyRequest(r, jwt.AccessToken, getTokenFromCookie)
-		if err != nil {
+		if err == nil {
+			// the session is checked on every request, so revoking it logs out right away
+			err = m.checkAccessSession(r, claims)
+			if err != nil {
+				jwt.DeleteJwts(w)
+				httputil.Error(w, http.StatusUnauthorized, err)
+				return
+			}
+		} else {
 			// if access token could not be found in cookies it means it's either expired or did not exist
 			// if it is found but invalid unauthorized status can be returned
 			if !errors.Is(err, ErrJwtNotFound) {
@@ -64,13 +73,12 @@ func (m *Manager) JwtAuthentication(next http.Handler) http.Handler {
 				return
 			}
 
-			token, err := jwt.NewAccessToken(userID)
+			err = m.refreshSession(w, r, userID, tokenRefreshVersion, claims)
 			if err != nil {
-				httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("could not create new access jwt"))
+				jwt.DeleteJwts(w)
+				httputil.Error(w, http.StatusUnauthorized, err)
 				return
 			}
-
-			jwt.SetJwtCookie(w, jwt.AccessToken, token)
 		}
 
 		userID, err := getUserIdFromClaims(claims)
@@ -81,6 +89,11 @@ func (m *Manager) JwtAuthentication(next http.Handler) http.Handler {
 
 		ctx = jwt.SetUserIdInContext(ctx, userID)
 
+		sessionID, err := getSessionIdFromClaims(claims)
+		if err == nil {
+			ctx = jwt.SetSessionIdInContext(ctx, sessionID)
+		}
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -107,11 +120,126 @@ func (m *Manager) EmployeeAuthentication(next http.Handler) http.Handler {
 		ctx = actor.SetLocationIdInContext(ctx, authInfo.LocationId)
 		ctx = actor.SetEmployeeIdInContext(ctx, authInfo.Id)
 		ctx = actor.SetEmployeeRoleInContext(ctx, authInfo.Role)
+		ctx = actor.SetPermissionsInContext(ctx, authInfo.Permissions())
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// Access tokens are only valid while the session they were issued for is active
+func (m *Manager) checkAccessSession(r *http.Request, claims jwtlib.MapClaims) error {
+	userID, err := getUserIdFromClaims(claims)
+	if err != nil {
+		return fmt.Errorf("could not parse jwt claims: %s", err.Error())
+	}
+
+	sessionID, err := getSessionIdFromClaims(claims)
+	if err != nil {
+		return fmt.Errorf("access token does not belong to a session")
+	}
+
+	session, err := m.userRepo.GetSession(r.Context(), sessionID)
+	if err != nil {
+		return fmt.Errorf("session could not be found")
+	}
+
+	if session.UserId != userID || !session.IsActive(time.Now().UTC()) {
+		return fmt.Errorf("session is no longer active")
+	}
+
+	return nil
+}
+
+// A refresh token that was already rotated is still accepted for this long,
+// so concurrent requests sent with the same refresh token do not log the user out
+const refreshReuseGracePeriod = 30 * time.Second
+
+// Check the refresh token against its session and issue new tokens.
+// The refresh token is rotated on every use, if an already rotated token is presented
+// outside of the grace period the session is considered stolen and is revoked.
+func (m *Manager) refreshSession(w http.ResponseWriter, r *http.Request, userID uuid.UUID, refreshVersion int, claims jwtlib.MapClaims) error {
+	ctx := r.Context()
+
+	sessionID, err := getSessionIdFromClaims(claims)
+	if err != nil {
+		return fmt.Errorf("refresh token does not belong to a session")
+	}
+
+	tokenId, ok := claims["jti"].(string)
+	if !ok || tokenId == "" {
+		return fmt.Errorf("refresh token does not have an id")
+	}
+
+	session, err := m.userRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("session could not be found")
+	}
+
+	now := time.Now().UTC()
+	if session.UserId != userID || !session.IsActive(now) {
+		return fmt.Errorf("session is no longer active")
+	}
+
+	ipAddress := httputil.ClientIP(r)
+	userAgent := r.UserAgent()
+	tokenHash := jwt.HashTokenId(tokenId)
+
+	switch {
+	case tokenHash == session.RefreshTokenHash:
+		newTokenId, err := jwt.NewTokenId()
+		if err != nil {
+			return err
+		}
+
+		rotated, err := m.userRepo.RotateSession(ctx, sessionID, tokenHash, jwt.HashTokenId(newTokenId), ipAddress, userAgent, jwt.RefreshTokenExpiry())
+		if err != nil {
+			return fmt.Errorf("unexpected error when rotating session: %s", err.Error())
+		}
+
+		// another request rotated the session in the meantime, it's cookies will be used
+		if !rotated {
+			break
+		}
+
+		refreshToken, err := jwt.NewRefreshToken(userID, refreshVersion, sessionID, newTokenId)
+		if err != nil {
+			return fmt.Errorf("could not create new refresh jwt")
+		}
+
+		jwt.SetJwtCookie(w, jwt.RefreshToken, refreshToken)
+
+	case session.PreviousTokenHash != nil && tokenHash == *session.PreviousTokenHash && now.Sub(session.RotatedAt) < refreshReuseGracePeriod:
+		err = m.userRepo.TouchSession(ctx, sessionID, ipAddress, userAgent)
+		if err != nil {
+			return fmt.Errorf("unexpected error when updating session: %s", err.Error())
+		}
+
+	default:
+		// nolint:errcheck
+		m.userRepo.RevokeSession(ctx, userID, sessionID)
+
+		return fmt.Errorf("refresh token reuse detected, session has been revoked")
+	}
+
+	accessToken, err := jwt.NewAccessToken(userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("could not create new access jwt")
+	}
+
+	jwt.SetJwtCookie(w, jwt.AccessToken, accessToken)
+
+	return nil
+}
+
+func getSessionIdFromClaims(claims jwtlib.MapClaims) (uuid.UUID, error) {
+	sid, ok := claims["sid"].(string)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("sid claim is missing")
+	}
+
+	return uuid.Parse(sid)
+}
+
 func getUserIdFromClaims(claims jwtlib.MapClaims) (uuid.UUID, error) {
 	uuidStr, err := claims.GetSubject()
 	if err != nil {
@@ -2,6 +2,9 @@ package jwt
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"time"
@@ -32,6 +35,7 @@ type contextKey struct {
 }
 
 var userIDCtxKey = &contextKey{"UserID"}
+var sessionIDCtxKey = &contextKey{"SessionID"}
 
 // Returns UserID from the request's context. Panics if not present!
 func MustGetUserIDFromContext(ctx context.Context) uuid.UUID {
@@ -55,6 +59,35 @@ func SetUserIdInContext(ctx context.Context, userId uuid.UUID) context.Context {
 	return context.WithValue(ctx, userIDCtxKey, userId)
 }
 
+// Returns the SessionID of the current request from the context
+func GetSessionIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	sessionID, ok := ctx.Value(sessionIDCtxKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	return sessionID, true
+}
+
+func SetSessionIdInContext(ctx context.Context, sessionId uuid.UUID) context.Context {
+	return context.WithValue(ctx, sessionIDCtxKey, sessionId)
+}
+
+// Create a new random refresh token id, only it's hash is stored in the database
+func NewTokenId() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unexpected error when creating token id: %s", err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash of the refresh token id as it is stored in the database
+func HashTokenId(tokenId string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(tokenId)))
+}
+
 // Create a new jwt, with HS256 signing method
 func new(secret []byte, claims jwtlib.MapClaims) (string, error) {
 	token := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
@@ -68,13 +101,15 @@ func new(secret []byte, claims jwtlib.MapClaims) (string, error) {
 	return tokenString, nil
 }
 
-// Create a new access token
-func NewAccessToken(userID uuid.UUID) (string, error) {
+// Create a new access token, the session id is included so the
+// current session can be identified without the refresh token
+func NewAccessToken(userID uuid.UUID, sessionID uuid.UUID) (string, error) {
 	expMin := config.LoadEnvVars().JWT_ACCESS_EXP_MIN
 	expMinDuration := time.Minute * time.Duration(expMin)
 
 	claims := jwtlib.MapClaims{
 		"sub": userID,
+		"sid": sessionID,
 		"exp": time.Now().Add(expMinDuration).Unix(),
 	}
 
@@ -86,13 +121,16 @@ func NewAccessToken(userID uuid.UUID) (string, error) {
 	return token, nil
 }
 
-// Create a new refresh token
-func NewRefreshToken(userID uuid.UUID, refreshVersion int) (string, error) {
+// Create a new refresh token belonging to a session, the token id
+// changes on every rotation
+func NewRefreshToken(userID uuid.UUID, refreshVersion int, sessionID uuid.UUID, tokenId string) (string, error) {
 	expMin := config.LoadEnvVars().JWT_REFRESH_EXP_MIN
 	expMinDuration := time.Minute * time.Duration(expMin)
 
 	claims := jwtlib.MapClaims{
 		"sub":             userID,
+		"sid":             sessionID,
+		"jti":             tokenId,
 		"exp":             time.Now().Add(expMinDuration).Unix(),
 		"refresh_version": refreshVersion,
 	}
@@ -105,6 +143,12 @@ func NewRefreshToken(userID uuid.UUID, refreshVersion int) (string, error) {
 	return token, nil
 }
 
+// Returns the time at which a newly issued refresh token expires
+func RefreshTokenExpiry() time.Time {
+	expMin := config.LoadEnvVars().JWT_REFRESH_EXP_MIN
+	return time.Now().UTC().Add(time.Minute * time.Duration(expMin))
+}
+
 // Set a jwt cookie
 func SetJwtCookie(w http.ResponseWriter, name JwtType, token string) {
 	var cookieName string
@@ -1,16 +1,23 @@
 package middleware
 
-import "github.com/miketsu-inc/reservations/backend/internal/domain"
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+)
 
 type Manager struct {
 	merchantRepo domain.MerchantRepository
 	userRepo     domain.UserRepository
+	apiKeyRepo   domain.ApiKeyRepository
+	limiter      ratelimit.Store
 }
 
 // Creates a new middleware manager that holds the middlewares repository dependencies
-func NewManager(merchant domain.MerchantRepository, user domain.UserRepository) *Manager {
+func NewManager(merchant domain.MerchantRepository, user domain.UserRepository, apiKey domain.ApiKeyRepository, limiter ratelimit.Store) *Manager {
 	return &Manager{
 		merchantRepo: merchant,
 		userRepo:     user,
+		apiKeyRepo:   apiKey,
+		limiter:      limiter,
 	}
 }
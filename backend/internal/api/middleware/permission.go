@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+// Permission based access control middleware that checks wether an employee has at least
+// one of the permissions to access a resource, should be called after the authentication middleware
+func (m *Manager) RequirePermission(permissions ...types.Permission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			employee, ok := actor.GetFromContext(r.Context())
+			if !ok {
+				httputil.Error(w, http.StatusUnauthorized, fmt.Errorf("you need an employee account to access this"))
+				return
+			}
+
+			for _, p := range permissions {
+				if employee.HasPermission(p) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			httputil.Error(w, http.StatusForbidden, fmt.Errorf("this resource requires one of the following permissions: %s", permissions))
+		})
+	}
+}
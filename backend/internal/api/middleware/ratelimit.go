@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/miketsu-inc/reservations/backend/internal/keys"
+	"github.com/miketsu-inc/reservations/backend/pkg/assert"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+// Rate limit middleware using a sliding window for every policy. Policies that
+// key by user or by the merchant of the employee should be used after the authentication middlewares.
+// If the limiter backend is unavailable requests are let through.
+func (m *Manager) RateLimit(policies ...ratelimit.Policy) func(next http.Handler) http.Handler {
+	for _, p := range policies {
+		err := p.Validate()
+		assert.Nil(err, "invalid rate limit policy", p.Name, err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			for _, p := range policies {
+				subject, ok := p.KeyBy(r)
+				if !ok {
+					continue
+				}
+
+				key := keys.RateLimit{Policy: p.Name, Subject: subject}.String()
+
+				res, err := m.limiter.Hit(ctx, key, p.Limit, p.Window)
+				if err != nil {
+					slog.ErrorContext(ctx, "rate limiter unavailable", "policy", p.Name, "error", err)
+					continue
+				}
+
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+
+				if !res.Allowed {
+					SetRetryAfter(w, res.RetryAfter)
+					httputil.Error(w, http.StatusTooManyRequests, fmt.Errorf("too many requests, please try again later"))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Sets the Retry-After header in whole seconds, rounded up
+func SetRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	hits map[string][]time.Time
+	now  func() time.Time
+}
+
+// In process sliding window log, used in tests and when redis is not available
+func NewMemoryStore() Store {
+	return newMemoryStore(time.Now)
+}
+
+func newMemoryStore(now func() time.Time) *memoryStore {
+	return &memoryStore{
+		hits: map[string][]time.Time{},
+		now:  now,
+	}
+}
+
+func (s *memoryStore) Hit(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	return s.run(key, limit, window, true), nil
+}
+
+func (s *memoryStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	return s.run(key, limit, window, false), nil
+}
+
+func (s *memoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.hits, key)
+
+	return nil
+}
+
+func (s *memoryStore) run(key string, limit int, window time.Duration, record bool) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	// drop hits that are outside of the window, hits are always in order
+	hits := s.hits[key]
+	i := 0
+	for i < len(hits) && !hits[i].After(now.Add(-window)) {
+		i++
+	}
+	hits = hits[i:]
+
+	if len(hits) >= limit {
+		s.hits[key] = hits
+		return newResult(false, len(hits), limit, hits[0], window, now)
+	}
+
+	if record {
+		hits = append(hits, now)
+	}
+
+	if len(hits) == 0 {
+		delete(s.hits, key)
+	} else {
+		s.hits[key] = hits
+	}
+
+	return newResult(true, len(hits), limit, time.Time{}, window, now)
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestMemoryStoreSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Blocks after limit", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		store := newMemoryStore(clock.Now)
+
+		for i := range 3 {
+			res, err := store.Hit(ctx, "key", 3, time.Minute)
+			assert.Nil(t, err)
+			assert.True(t, res.Allowed, "hit %d shall be allowed", i)
+			assert.Equal(t, 2-i, res.Remaining)
+			clock.Advance(10 * time.Second)
+		}
+
+		res, err := store.Hit(ctx, "key", 3, time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+		// oldest hit was 30 seconds ago
+		assert.Equal(t, 30*time.Second, res.RetryAfter)
+	})
+
+	t.Run("Window slides", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		store := newMemoryStore(clock.Now)
+
+		_, _ = store.Hit(ctx, "key", 2, time.Minute)
+		clock.Advance(30 * time.Second)
+		_, _ = store.Hit(ctx, "key", 2, time.Minute)
+
+		res, _ := store.Hit(ctx, "key", 2, time.Minute)
+		assert.False(t, res.Allowed)
+
+		// the first hit leaves the window, the second one is still inside
+		clock.Advance(30 * time.Second)
+		res, _ = store.Hit(ctx, "key", 2, time.Minute)
+		assert.True(t, res.Allowed)
+
+		res, _ = store.Hit(ctx, "key", 2, time.Minute)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 30*time.Second, res.RetryAfter)
+	})
+
+	t.Run("Blocked hits are not recorded", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		store := newMemoryStore(clock.Now)
+
+		_, _ = store.Hit(ctx, "key", 1, time.Minute)
+		for range 5 {
+			clock.Advance(10 * time.Second)
+			_, _ = store.Hit(ctx, "key", 1, time.Minute)
+		}
+
+		clock.Advance(10 * time.Second)
+		res, _ := store.Hit(ctx, "key", 1, time.Minute)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Peek and reset", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		store := newMemoryStore(clock.Now)
+
+		res, _ := store.Peek(ctx, "key", 1, time.Minute)
+		assert.True(t, res.Allowed)
+
+		res, _ = store.Peek(ctx, "key", 1, time.Minute)
+		assert.True(t, res.Allowed, "peek shall not record a hit")
+
+		_, _ = store.Hit(ctx, "key", 1, time.Minute)
+		res, _ = store.Peek(ctx, "key", 1, time.Minute)
+		assert.False(t, res.Allowed)
+
+		_ = store.Reset(ctx, "key")
+		res, _ = store.Peek(ctx, "key", 1, time.Minute)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Keys are separate", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, _ = store.Hit(ctx, "a", 1, time.Minute)
+		res, _ := store.Hit(ctx, "b", 1, time.Minute)
+		assert.True(t, res.Allowed)
+	})
+}
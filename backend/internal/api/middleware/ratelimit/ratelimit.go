@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/pkg/httputil"
+)
+
+// Outcome of a single rate limit check
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Only set if the request was not allowed
+	RetryAfter time.Duration
+}
+
+// Sliding window limiter backend. A hit is only recorded if it was allowed,
+// so the window of a blocked client is not extended by further attempts.
+type Store interface {
+	// Record a hit for key if there were less than limit hits in the last window
+	Hit(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Same as Hit but does not record anything
+	Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Returns the subject a request is limited by,
+// false means the policy does not apply to the request
+type KeyFunc func(r *http.Request) (string, bool)
+
+// Rate limit configuration of a route group
+type Policy struct {
+	// Name of the policy, separates the counters of different route groups
+	Name   string
+	Limit  int
+	Window time.Duration
+	KeyBy  KeyFunc
+}
+
+func (p Policy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("rate limit policy must have a name")
+	}
+
+	if p.Limit <= 0 || p.Window <= 0 {
+		return fmt.Errorf("rate limit policy %s must have a positive limit and window", p.Name)
+	}
+
+	if p.KeyBy == nil {
+		return fmt.Errorf("rate limit policy %s must have a key function", p.Name)
+	}
+
+	return nil
+}
+
+// The forwarded headers are only used if the request came from a trusted proxy, so clients can not pick their own key
+func ByIP(r *http.Request) (string, bool) {
+	ip := httputil.ClientIP(r)
+
+	return "ip:" + ip, ip != ""
+}
+
+// Should be used after the jwt authentication middleware
+func ByUser(r *http.Request) (string, bool) {
+	userId, ok := jwt.GetUserIDFromContext(r.Context())
+	if !ok {
+		return "", false
+	}
+
+	return "user:" + userId.String(), true
+}
+
+// The merchant of the authenticated employee or api key, otherwise the one in the url,
+// so a merchant's limit is shared by every user and address
+func ByMerchant(r *http.Request) (string, bool) {
+	if employee, ok := actor.GetFromContext(r.Context()); ok {
+		return "merchant:" + employee.MerchantId.String(), true
+	}
+
+	if merchantId := chi.URLParam(r, "merchantId"); merchantId != "" {
+		return "merchant:" + merchantId, true
+	}
+
+	merchantName := chi.URLParam(r, "merchantName")
+
+	return "merchant_name:" + merchantName, merchantName != ""
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStore struct {
+	kv *redis.Client
+}
+
+// Sliding window log stored in a sorted set, scored by the hit's unix milliseconds
+func NewRedisStore(kv *redis.Client) Store {
+	return &redisStore{kv: kv}
+}
+
+// KEYS[1] key
+// ARGV[1] now in ms, ARGV[2] window in ms, ARGV[3] limit, ARGV[4] member, ARGV[5] record hit (1 or 0)
+//
+// returns {allowed, count, oldest hit in ms}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, count, tonumber(oldest[2])}
+end
+
+if ARGV[5] == '1' then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	count = count + 1
+end
+
+return {1, count, 0}
+`)
+
+func (s *redisStore) Hit(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	return s.run(ctx, key, limit, window, true)
+}
+
+func (s *redisStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	return s.run(ctx, key, limit, window, false)
+}
+
+func (s *redisStore) Reset(ctx context.Context, key string) error {
+	err := s.kv.Del(ctx, key).Err()
+	if err != nil {
+		return fmt.Errorf("error resetting rate limit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *redisStore) run(ctx context.Context, key string, limit int, window time.Duration, record bool) (Result, error) {
+	now := time.Now().UnixMilli()
+
+	recordArg := "0"
+	if record {
+		recordArg = "1"
+	}
+
+	vals, err := slidingWindowScript.Run(ctx, s.kv, []string{key}, now, window.Milliseconds(), limit, uuid.NewString(), recordArg).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("error evaluating rate limit: %w", err)
+	}
+
+	if len(vals) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", vals)
+	}
+
+	return newResult(vals[0] == 1, int(vals[1]), limit, time.UnixMilli(vals[2]), window, time.UnixMilli(now)), nil
+}
+
+func newResult(allowed bool, count int, limit int, oldest time.Time, window time.Duration, now time.Time) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-count, 0),
+	}
+
+	if !allowed {
+		res.RetryAfter = max(oldest.Add(window).Sub(now), 0)
+	}
+
+	return res
+}
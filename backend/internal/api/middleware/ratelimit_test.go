@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Hit(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	return ratelimit.Result{}, assert.AnError
+}
+
+func (failingStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (ratelimit.Result, error) {
+	return ratelimit.Result{}, assert.AnError
+}
+
+func (failingStore) Reset(ctx context.Context, key string) error {
+	return assert.AnError
+}
+
+func newRateLimitedHandler(store ratelimit.Store, policies ...ratelimit.Policy) http.Handler {
+	m := &Manager{limiter: store}
+
+	return m.RateLimit(policies...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func sendRequest(h http.Handler, remoteAddr string, userId *uuid.UUID) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.RemoteAddr = remoteAddr
+
+	if userId != nil {
+		r = r.WithContext(jwt.SetUserIdInContext(r.Context(), *userId))
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+
+	t.Run("By ip", func(t *testing.T) {
+		h := newRateLimitedHandler(ratelimit.NewMemoryStore(),
+			ratelimit.Policy{Name: "test", Limit: 2, Window: time.Minute, KeyBy: ratelimit.ByIP})
+
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+
+		w := sendRequest(h, "10.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.2:1234", nil).Code, "other ips shall not be limited")
+	})
+
+	t.Run("By user", func(t *testing.T) {
+		h := newRateLimitedHandler(ratelimit.NewMemoryStore(),
+			ratelimit.Policy{Name: "test", Limit: 1, Window: time.Minute, KeyBy: ratelimit.ByUser})
+
+		userA := uuid.New()
+		userB := uuid.New()
+
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", &userA).Code)
+		assert.Equal(t, http.StatusTooManyRequests, sendRequest(h, "10.0.0.2:1234", &userA).Code)
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", &userB).Code)
+
+		// policy does not apply to unauthenticated requests
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+	})
+
+	t.Run("By merchant", func(t *testing.T) {
+		h := newRateLimitedHandler(ratelimit.NewMemoryStore(),
+			ratelimit.Policy{Name: "test", Limit: 1, Window: time.Minute, KeyBy: ratelimit.ByMerchant})
+
+		sendToMerchant := func(remoteAddr string, merchantName string) int {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("merchantName", merchantName)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = remoteAddr
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, sendToMerchant("10.0.0.1:1234", "merchant-a"))
+		assert.Equal(t, http.StatusTooManyRequests, sendToMerchant("10.0.0.2:1234", "merchant-a"))
+		assert.Equal(t, http.StatusOK, sendToMerchant("10.0.0.1:1234", "merchant-b"))
+
+		// policy does not apply to requests without a merchant
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+	})
+
+	t.Run("Multiple policies", func(t *testing.T) {
+		h := newRateLimitedHandler(ratelimit.NewMemoryStore(),
+			ratelimit.Policy{Name: "user", Limit: 5, Window: time.Minute, KeyBy: ratelimit.ByUser},
+			ratelimit.Policy{Name: "ip", Limit: 2, Window: time.Minute, KeyBy: ratelimit.ByIP})
+
+		userA := uuid.New()
+		userB := uuid.New()
+
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", &userA).Code)
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", &userB).Code)
+		assert.Equal(t, http.StatusTooManyRequests, sendRequest(h, "10.0.0.1:1234", &userA).Code)
+	})
+
+	t.Run("Fails open", func(t *testing.T) {
+		h := newRateLimitedHandler(failingStore{},
+			ratelimit.Policy{Name: "test", Limit: 1, Window: time.Minute, KeyBy: ratelimit.ByIP})
+
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+		assert.Equal(t, http.StatusOK, sendRequest(h, "10.0.0.1:1234", nil).Code)
+	})
+}
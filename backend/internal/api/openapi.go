@@ -0,0 +1,123 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+)
+
+func init() {
+	openapi.RegisterEnum(types.ApiKeyScopes...)
+	openapi.RegisterEnum(types.AuditEntityBooking, types.AuditEntityBookingParticipant, types.AuditEntityCustomer,
+		types.AuditEntityService, types.AuditEntityMerchantSettings, types.AuditEntityEmployee)
+	openapi.RegisterEnum(types.BookingStatusBooked, types.BookingStatusConfirmed, types.BookingStatusCompleted,
+		types.BookingStatusCancelled, types.BookingStatusNoShow)
+	openapi.RegisterEnum(types.CustomerImportPending, types.CustomerImportRunning, types.CustomerImportCompleted, types.CustomerImportFailed)
+	openapi.RegisterEnum(types.CustomFieldText, types.CustomFieldNumber, types.CustomFieldDate, types.CustomFieldSelect)
+	openapi.RegisterEnum(types.IntakeQuestionText, types.IntakeQuestionLongText, types.IntakeQuestionNumber, types.IntakeQuestionDate,
+		types.IntakeQuestionYesNo, types.IntakeQuestionSingleChoice, types.IntakeQuestionMultipleChoice, types.IntakeQuestionConsent)
+	openapi.RegisterEnum(types.IntakeFormOnce, types.IntakeFormEveryTime)
+	openapi.RegisterEnum(types.MarketingChannelEmail, types.MarketingChannelSms)
+	openapi.RegisterEnum(types.ConsentSourceBooking, types.ConsentSourceMerchant, types.ConsentSourceUnsubscribeLink)
+	openapi.RegisterEnum(types.CampaignPending, types.CampaignSending, types.CampaignCompleted, types.CampaignCancelled)
+	openapi.RegisterEnum(types.CustomerPolicyEventNoShow, types.CustomerPolicyEventCancellation)
+	openapi.RegisterEnum(types.CustomerPolicyActionRequireApproval, types.CustomerPolicyActionBlacklist)
+	openapi.RegisterEnum(types.DataExportPending, types.DataExportRunning, types.DataExportCompleted, types.DataExportFailed)
+	openapi.RegisterEnum(types.BookingTypeAppointment, types.BookingTypeEvent, types.BookingTypeClass)
+	openapi.RegisterEnum(types.EmployeeRoleStaff, types.EmployeeRoleAdmin, types.EmployeeRoleOwner)
+	openapi.RegisterEnum(types.EventSourceInternal, types.EventSourceGoogle)
+	openapi.RegisterEnum(types.EventInternalTypeBooking, types.EventInternalTypeBlockedTime)
+	openapi.RegisterEnum(append(slices.Clone(types.AssignablePermissions), types.PermissionMerchantManage)...)
+	openapi.RegisterEnum(types.ApprovalTypeAuto, types.ApprovalTypeManual, types.ApprovalTypeManualForNew)
+	openapi.RegisterEnum(types.ServicePhaseTypeActive, types.ServicePhaseTypeWait)
+	openapi.RegisterEnum(types.PriceTypeFree, types.PriceTypeFrom, types.PriceTypeFixed)
+	openapi.RegisterEnum(types.SubTierFree, types.SubTierPro, types.SubTierEnterprise)
+	openapi.RegisterEnum(types.AuthProviderTypeGoogle, types.AuthProviderTypeFacebook)
+	openapi.RegisterEnum(types.WebhookEventTypes...)
+	openapi.RegisterEnum(types.ReportGranularityDay, types.ReportGranularityWeek, types.ReportGranularityMonth)
+	openapi.RegisterEnum(types.ReportGroupByService, types.ReportGroupByCategory, types.ReportGroupByEmployee, types.ReportGroupByLocation)
+	openapi.RegisterEnum(types.ReportFrequencyDaily, types.ReportFrequencyWeekly, types.ReportFrequencyMonthly)
+	openapi.RegisterEnum(types.ReportExportDatasetBookings, types.ReportExportDatasetParticipants, types.ReportExportDatasetRevenue)
+	openapi.RegisterEnum(types.ReportExportFormatCsv, types.ReportExportFormatXlsx)
+	openapi.RegisterEnum(types.WebhookDeliveryPending, types.WebhookDeliverySucceeded, types.WebhookDeliveryFailed)
+
+	// prices are encoded the same way as bojanz/currency amounts
+	openapi.RegisterSchema(currencyx.Price{}, func() *openapi.Schema {
+		return &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"number":   {Type: "string"},
+				"currency": {Type: "string"},
+			},
+			Required: []string{"number", "currency"},
+		}
+	})
+}
+
+const (
+	cookieAuthScheme = "cookieAuth"
+	apiKeyAuthScheme = "apiKeyAuth"
+)
+
+func openapiConfig(h *Handlers) openapi.Config {
+	jwtAuthentication := openapi.FuncName(h.Middleware.JwtAuthentication)
+	apiKeyAuthentication := openapi.FuncName(h.Middleware.ApiKeyAuthentication)
+
+	return openapi.Config{
+		Info: openapi.Info{Title: "Reservations API", Version: "1.0.0"},
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			cookieAuthScheme: {Type: "apiKey", In: "cookie", Name: jwt.AccessCookieName},
+			apiKeyAuthScheme: {Type: "http", Scheme: "bearer"},
+		},
+		Security: func(method, path string, middlewares []string) []openapi.SecurityRequirement {
+			switch {
+			case slices.Contains(middlewares, apiKeyAuthentication):
+				return []openapi.SecurityRequirement{{apiKeyAuthScheme: {}}}
+			case slices.Contains(middlewares, jwtAuthentication):
+				return []openapi.SecurityRequirement{{cookieAuthScheme: {}}}
+			default:
+				return nil
+			}
+		},
+	}
+}
+
+// Operations of every route, a route without one makes the openapi test fail
+func (h *Handlers) operations() []openapi.Operation {
+	var ops []openapi.Operation
+
+	ops = append(ops, h.Auth.Spec()...)
+	ops = append(ops, h.Integrations.Spec()...)
+	ops = append(ops, h.Users.Spec()...)
+	ops = append(ops, h.PublicMerchants.Spec()...)
+	ops = append(ops, h.PublicBookings.Spec()...)
+	ops = append(ops, h.PublicGiftCards.Spec()...)
+	ops = append(ops, h.Unsubscribe.Spec()...)
+	ops = append(ops, h.ReportExports.Spec()...)
+	ops = append(ops, h.Merchants.Spec()...)
+	ops = append(ops, h.ApiKeys.Spec()...)
+	ops = append(ops, h.AuditLog.Spec()...)
+	ops = append(ops, h.Bookings.Spec()...)
+	ops = append(ops, h.BlockedTimes.Spec()...)
+	ops = append(ops, h.BlockedTimeTypes.Spec()...)
+	ops = append(ops, h.Customers.Spec()...)
+	ops = append(ops, h.GiftCards.Spec()...)
+	ops = append(ops, h.Visits.Spec()...)
+	ops = append(ops, h.IntakeForms.Spec()...)
+	ops = append(ops, h.Locations.Spec()...)
+	ops = append(ops, h.Passes.Spec()...)
+	ops = append(ops, h.Promotions.Spec()...)
+	ops = append(ops, h.Products.Spec()...)
+	ops = append(ops, h.Reports.Spec()...)
+	ops = append(ops, h.Invoices.Spec()...)
+	ops = append(ops, h.Services.Spec()...)
+	ops = append(ops, h.ServiceCategories.Spec()...)
+	ops = append(ops, h.TaxRates.Spec()...)
+	ops = append(ops, h.Team.Spec()...)
+	ops = append(ops, h.Webhooks.Spec()...)
+
+	return ops
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/auth"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/integrations"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/apikeys"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/auditlog"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimes"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimetypes"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/bookings"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/customers"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/giftcards"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/intakeforms"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/invoices"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/locations"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/passes"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/products"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/promotions"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/reports"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/servicecategories"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/services"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/taxrates"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/team"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/visits"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/webhooks"
+	publicBookings "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/bookings"
+	publicGiftCards "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/giftcards"
+	publicMerchants "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/reportexports"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/unsubscribe"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/users"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// The handlers are only routed, never called, so they don't need services
+func newTestHandlers() *Handlers {
+	m := middleware.NewManager(nil, nil, nil, nil)
+
+	return &Handlers{
+		Auth:              auth.NewHandler(nil, nil, m),
+		ApiKeys:           apikeys.NewHandler(nil, m),
+		AuditLog:          auditlog.NewHandler(nil, m),
+		Bookings:          bookings.NewHandler(nil, m),
+		PublicMerchants:   publicMerchants.NewHandler(nil, m),
+		PublicBookings:    publicBookings.NewHandler(nil, m),
+		PublicGiftCards:   publicGiftCards.NewHandler(nil, m),
+		Unsubscribe:       unsubscribe.NewHandler(nil, m),
+		ReportExports:     reportexports.NewHandler(nil, m),
+		Merchants:         merchants.NewHandler(nil, nil),
+		BlockedTimes:      blockedtimes.NewHandler(nil, m),
+		BlockedTimeTypes:  blockedtimetypes.NewHandler(nil, m),
+		Customers:         customers.NewHandler(nil, m),
+		GiftCards:         giftcards.NewHandler(nil, m),
+		IntakeForms:       intakeforms.NewHandler(nil, m),
+		Integrations:      integrations.NewHandler(nil),
+		Users:             users.NewHandler(nil, nil, nil, nil, m),
+		Locations:         locations.NewHandler(nil, m),
+		Passes:            passes.NewHandler(nil, m),
+		Promotions:        promotions.NewHandler(nil, m),
+		Products:          products.NewHandler(nil, m),
+		Reports:           reports.NewHandler(nil, m),
+		Invoices:          invoices.NewHandler(nil, m),
+		Services:          services.NewHandler(nil, m),
+		ServiceCategories: servicecategories.NewHandler(nil, m),
+		TaxRates:          taxrates.NewHandler(nil, m),
+		Team:              team.NewHandler(nil, m),
+		Visits:            visits.NewHandler(nil, m),
+		Webhooks:          webhooks.NewHandler(nil, m),
+		Middleware:        m,
+	}
+}
+
+func TestOpenApiCoversEveryRoute(t *testing.T) {
+	h := newTestHandlers()
+	router := NewRouter(h)
+
+	doc, err := openapi.Generate(router, openapiConfig(h), h.operations()...)
+	assert.NoError(t, err, "add the operation of the new route to the Spec of it's handler")
+
+	assert.Contains(t, doc.Paths, "/api/v1/openapi.json")
+	assert.Contains(t, doc.Paths, "/api/external/v1/bookings/{id}")
+}
+
+func TestOpenApiSecurity(t *testing.T) {
+	h := newTestHandlers()
+
+	doc, err := openapi.Generate(NewRouter(h), openapiConfig(h), h.operations()...)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected []openapi.SecurityRequirement
+	}{
+		{"post", "/api/v1/auth/login", nil},
+		{"get", "/api/v1/public/merchants/{merchantName}/services", nil},
+		{"get", "/api/v1/auth/me", []openapi.SecurityRequirement{{cookieAuthScheme: {}}}},
+		{"get", "/api/v1/merchants/{merchantId}/customers", []openapi.SecurityRequirement{{cookieAuthScheme: {}}}},
+		{"get", "/api/external/v1/customers", []openapi.SecurityRequirement{{apiKeyAuthScheme: {}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			operation := doc.Paths[tt.path][tt.method]
+
+			if assert.NotNil(t, operation) {
+				assert.Equal(t, tt.expected, operation.Security)
+			}
+		})
+	}
+}
+
+func TestOpenApiServed(t *testing.T) {
+	router := NewRouter(newTestHandlers())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var doc openapi.Document
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenApi)
+	assert.Contains(t, doc.Paths, "/api/v1/merchants/{merchantId}/bookings/{b_id}/participant/{p_id}")
+}
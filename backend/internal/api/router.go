@@ -3,54 +3,87 @@ package api
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/auth"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/integrations"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/apikeys"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/auditlog"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimetypes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/bookings"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/customers"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/giftcards"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/intakeforms"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/invoices"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/locations"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/passes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/products"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/promotions"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/reports"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/servicecategories"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/services"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/taxrates"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/team"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/visits"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/webhooks"
 	publicBookings "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/bookings"
+	publicGiftCards "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/giftcards"
 	publicMerchants "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/reportexports"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/unsubscribe"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/users"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/openapi"
 	"github.com/miketsu-inc/reservations/frontend/apps/jabulani"
 	"github.com/miketsu-inc/reservations/frontend/apps/tango"
 )
 
 type Handlers struct {
 	Auth              *auth.Handler
+	ApiKeys           *apikeys.Handler
+	AuditLog          *auditlog.Handler
 	Bookings          *bookings.Handler
 	PublicMerchants   *publicMerchants.Handler
 	PublicBookings    *publicBookings.Handler
+	PublicGiftCards   *publicGiftCards.Handler
+	Unsubscribe       *unsubscribe.Handler
+	ReportExports     *reportexports.Handler
 	Merchants         *merchants.Handler
 	BlockedTimes      *blockedtimes.Handler
 	BlockedTimeTypes  *blockedtimetypes.Handler
 	Customers         *customers.Handler
+	GiftCards         *giftcards.Handler
+	IntakeForms       *intakeforms.Handler
 	Integrations      *integrations.Handler
+	Invoices          *invoices.Handler
 	Users             *users.Handler
 	Locations         *locations.Handler
+	Passes            *passes.Handler
+	Promotions        *promotions.Handler
 	Products          *products.Handler
+	Reports           *reports.Handler
 	Services          *services.Handler
 	ServiceCategories *servicecategories.Handler
+	TaxRates          *taxrates.Handler
 	Team              *team.Handler
+	Visits            *visits.Handler
+	Webhooks          *webhooks.Handler
 	Middleware        *middleware.Manager
 }
 
 func NewRouter(h *Handlers) *chi.Mux {
 	r := chi.NewRouter()
 
+	r.Use(chiMiddleware.RequestID)
 	r.Use(chiMiddleware.Logger)
-	r.Use(chiMiddleware.AllowContentType("application/json"))
+	// email clients post the one-click unsubscribe as a form
+	r.Use(chiMiddleware.AllowContentType("application/json", "application/x-www-form-urlencoded", "multipart/form-data"))
 	// r.Use(chiMiddleware.Recoverer)
 
 	r.Route("/api/v1", func(r chi.Router) {
@@ -58,7 +91,10 @@ func NewRouter(h *Handlers) *chi.Mux {
 		r.Mount("/integrations", h.Integrations.Routes())
 		r.Mount("/users", h.Users.Routes())
 		r.Mount("/public/merchants/{merchantName}", h.PublicMerchants.Routes())
+		r.Mount("/public/merchants/{merchantName}/gift-cards", h.PublicGiftCards.Routes())
 		r.Mount("/public/bookings", h.PublicBookings.Routes())
+		r.Mount("/public/unsubscribe", h.Unsubscribe.Routes())
+		r.Mount("/public/report-exports", h.ReportExports.Routes())
 		r.Route("/merchants", func(r chi.Router) {
 			r.Use(h.Middleware.JwtAuthentication)
 			r.Use(h.Middleware.Language)
@@ -73,44 +109,72 @@ func NewRouter(h *Handlers) *chi.Mux {
 			r.Get("/me", h.Merchants.Me)
 
 			r.Group(func(r chi.Router) {
-				r.Use(h.Middleware.RoleBasedAccessControl(types.EmployeeRoleOwner))
+				r.Use(h.Middleware.RequirePermission(types.PermissionMerchantManage))
 
 				r.Delete("/", h.Merchants.Delete)
 				r.Patch("/name", h.Merchants.UpdateName)
 			})
 
-			r.Group(func(r chi.Router) {
-				r.Use(h.Middleware.RoleBasedAccessControl(types.EmployeeRoleStaff, types.EmployeeRoleAdmin, types.EmployeeRoleOwner))
+			r.With(h.Middleware.RequirePermission(types.PermissionReportsView)).Get("/dashboard", h.Merchants.GetDashboard)
 
-				r.Get("/dashboard", h.Merchants.GetDashboard)
+			r.Get("/settings", h.Merchants.GetSettings)
+			r.Get("/settings/business-hours/normalized", h.Merchants.GetNormalizedBusinessHours)
+			r.Get("/preferences", h.Merchants.GetPreferences)
 
-				r.Get("/settings", h.Merchants.GetSettings)
-				r.Patch("/settings", h.Merchants.UpdateSettings)
-				r.Get("/settings/business-hours/normalized", h.Merchants.GetNormalizedBusinessHours)
+			r.Group(func(r chi.Router) {
+				r.Use(h.Middleware.RequirePermission(types.PermissionSettingsManage))
 
-				r.Get("/preferences", h.Merchants.GetPreferences)
+				r.Patch("/settings", h.Merchants.UpdateSettings)
 				r.Patch("/preferences", h.Merchants.UpdatePreferences)
 
-				r.Get("/calendar/team", h.Merchants.GetTeamForCalendar)
-				r.Get("/calendar/services", h.Merchants.GetServicesForCalendar)
-				r.Get("/calendar/customers", h.Merchants.GetCustomersForCalendar)
-				r.Get("/calendar/events", h.Merchants.GetCalendarEvents)
-
 				r.Get("/integrations/google/calendar", h.Merchants.GoogleCalendar)
 			})
 
+			r.Get("/calendar/team", h.Merchants.GetTeamForCalendar)
+			r.Get("/calendar/services", h.Merchants.GetServicesForCalendar)
+			r.Get("/calendar/customers", h.Merchants.GetCustomersForCalendar)
+			r.Get("/calendar/events", h.Merchants.GetCalendarEvents)
+
 			r.Mount("/bookings", h.Bookings.Routes())
 			r.Mount("/blocked-times", h.BlockedTimes.Routes())
 			r.Mount("/blocked-time-types", h.BlockedTimeTypes.Routes())
 			r.Mount("/customers", h.Customers.Routes())
+			r.Mount("/gift-cards", h.GiftCards.Routes())
+			r.Mount("/visits", h.Visits.Routes())
+			r.Mount("/intake-forms", h.IntakeForms.Routes())
+			r.Mount("/invoices", h.Invoices.Routes())
 			r.Mount("/locations", h.Locations.Routes())
+			r.Mount("/passes", h.Passes.Routes())
+			r.Mount("/promotions", h.Promotions.Routes())
 			r.Mount("/products", h.Products.Routes())
+			r.Mount("/reports", h.Reports.Routes())
 			r.Mount("/services", h.Services.Routes())
 			r.Mount("/service-categories", h.ServiceCategories.Routes())
+			r.Mount("/tax-rates", h.TaxRates.Routes())
 			r.Mount("/team", h.Team.Routes())
+			r.Mount("/audit-log", h.AuditLog.Routes())
+			r.Mount("/webhooks", h.Webhooks.Routes())
+			r.Mount("/api-keys", h.ApiKeys.Routes())
 		})
 	})
 
+	// Public api for integrations, authenticated with merchant api keys.
+	// It's versioned separately, breaking changes need a new version
+	r.Route("/api/external/v1", func(r chi.Router) {
+		r.Use(h.Middleware.ApiKeyAuthentication)
+		// every key has it's own limit, this one stops a merchant from adding them up with more keys
+		r.Use(h.Middleware.RateLimit(
+			ratelimit.Policy{Name: "external_api_merchant", Limit: 2400, Window: time.Minute, KeyBy: ratelimit.ByMerchant},
+		))
+
+		r.Mount("/bookings", h.Bookings.ExternalRoutes())
+		r.Mount("/customers", h.Customers.ExternalRoutes())
+		r.Mount("/services", h.Services.ExternalRoutes())
+	})
+
+	// Described routes are the ones of the whole router, not only the /api/v1 ones
+	r.Get("/api/v1/openapi.json", openapi.Handler(r, openapiConfig(h), h.operations()...))
+
 	jabulani := jabulaniRouter()
 	tango := tangoRouter()
 
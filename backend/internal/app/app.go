@@ -13,21 +13,39 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/auth"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/integrations"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/apikeys"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/auditlog"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/blockedtimetypes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/bookings"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/customers"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/giftcards"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/intakeforms"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/invoices"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/locations"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/passes"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/products"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/promotions"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/reports"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/servicecategories"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/services"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/taxrates"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/team"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/visits"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/merchants/webhooks"
 	publicBookings "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/bookings"
+	publicGiftCards "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/giftcards"
 	publicMerchants "github.com/miketsu-inc/reservations/backend/internal/api/handler/public/merchants"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/reportexports"
+	"github.com/miketsu-inc/reservations/backend/internal/api/handler/public/unsubscribe"
 	"github.com/miketsu-inc/reservations/backend/internal/api/handler/users"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/workers"
 	repos "github.com/miketsu-inc/reservations/backend/internal/repository/db"
+	apiKeySrv "github.com/miketsu-inc/reservations/backend/internal/service/apikey"
+	auditSrv "github.com/miketsu-inc/reservations/backend/internal/service/audit"
 	authSrv "github.com/miketsu-inc/reservations/backend/internal/service/auth"
 	blockedtimeSrv "github.com/miketsu-inc/reservations/backend/internal/service/blockedtime"
 	bookingSrv "github.com/miketsu-inc/reservations/backend/internal/service/booking"
@@ -35,11 +53,20 @@ import (
 	customerSrv "github.com/miketsu-inc/reservations/backend/internal/service/customer"
 	emailSrv "github.com/miketsu-inc/reservations/backend/internal/service/email"
 	externalcalendarSrv "github.com/miketsu-inc/reservations/backend/internal/service/externalcalendar"
+	giftcardSrv "github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	intakeSrv "github.com/miketsu-inc/reservations/backend/internal/service/intake"
+	invoiceSrv "github.com/miketsu-inc/reservations/backend/internal/service/invoice"
 	merchantSrv "github.com/miketsu-inc/reservations/backend/internal/service/merchant"
+	passSrv "github.com/miketsu-inc/reservations/backend/internal/service/pass"
 	productSrv "github.com/miketsu-inc/reservations/backend/internal/service/product"
+	promotionSrv "github.com/miketsu-inc/reservations/backend/internal/service/promotion"
+	reportSrv "github.com/miketsu-inc/reservations/backend/internal/service/report"
 	teamSrv "github.com/miketsu-inc/reservations/backend/internal/service/team"
 	userSrv "github.com/miketsu-inc/reservations/backend/internal/service/user"
+	visitSrv "github.com/miketsu-inc/reservations/backend/internal/service/visit"
+	webhookSrv "github.com/miketsu-inc/reservations/backend/internal/service/webhook"
 	"github.com/miketsu-inc/reservations/backend/pkg/assert"
+	"github.com/miketsu-inc/reservations/backend/pkg/blob"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 	"github.com/miketsu-inc/reservations/backend/pkg/kv"
@@ -58,36 +85,68 @@ type App struct {
 func New(ctx context.Context, cfg *config.Config) *App {
 	dbConn := db.New(ctx, registerTypes)
 
+	apiKeyRepo := repos.NewApiKeyRepository(dbConn)
+	auditLogRepo := repos.NewAuditLogRepository(dbConn)
 	blockedTimeRepo := repos.NewBlockedTimeRepository(dbConn)
 	bookingRepo := repos.NewBookingRepository(dbConn)
 	catalogRepo := repos.NewCatalogRepository(dbConn)
 	customerRep := repos.NewCustomerRepository(dbConn)
 	externalCalendarRepo := repos.NewExternalCalendarRepository(dbConn)
+	giftCardRepo := repos.NewGiftCardRepository(dbConn)
+	intakeRepo := repos.NewIntakeRepository(dbConn)
 	merchantRepo := repos.NewMerchantRepository(dbConn)
+	passRepo := repos.NewPassRepository(dbConn)
+	promotionRepo := repos.NewPromotionRepository(dbConn)
 	productRepo := repos.NewProductRepository(dbConn)
+	reportRepo := repos.NewReportRepository(dbConn)
+	invoiceRepo := repos.NewInvoiceRepository(dbConn)
 	teamRepo := repos.NewTeamRepository(dbConn)
 	userRepo := repos.NewUserRepository(dbConn)
+	visitRepo := repos.NewVisitRepository(dbConn)
+	webhookRepo := repos.NewWebhookRepository(dbConn)
 
 	transactionManager := db.NewTransactionManager(dbConn)
 
 	kvClient := kv.NewClient()
+	limiter := ratelimit.NewRedisStore(kvClient)
+
+	blobStore, err := newBlobStore(cfg)
+	assert.Nil(err, "Failed to create blob store")
 
 	emailService := emailSrv.NewService(cfg.RESEND_API_TEST, cfg.ENABLE_EMAILS)
-	authService := authSrv.NewService(merchantRepo, userRepo, teamRepo, kvClient, nil, transactionManager)
-	catalogService := catalog.NewService(catalogRepo, merchantRepo, transactionManager)
+	apiKeyService := apiKeySrv.NewService(apiKeyRepo)
+	auditService := auditSrv.NewService(auditLogRepo)
+	authService := authSrv.NewService(merchantRepo, userRepo, teamRepo, kvClient, limiter, nil, transactionManager)
+	catalogService := catalog.NewService(catalogRepo, merchantRepo, auditLogRepo, transactionManager)
 	blockedTimeService := blockedtimeSrv.NewService(blockedTimeRepo, teamRepo, nil, transactionManager)
-	bookingService := bookingSrv.NewService(bookingRepo, catalogRepo, merchantRepo, userRepo, customerRep, blockedTimeRepo, emailService, nil, transactionManager)
-	customerService := customerSrv.NewService(customerRep, bookingRepo, transactionManager)
+	bookingService := bookingSrv.NewService(bookingRepo, catalogRepo, merchantRepo, userRepo, customerRep, blockedTimeRepo, intakeRepo, giftCardRepo, passRepo, promotionRepo, auditLogRepo, emailService, nil, transactionManager)
+	customerService := customerSrv.NewService(customerRep, bookingRepo, passRepo, invoiceRepo, giftCardRepo, auditLogRepo, emailService, transactionManager)
 	externalCalendarService := externalcalendarSrv.NewService(externalCalendarRepo, blockedTimeRepo, merchantRepo, bookingRepo, teamRepo, nil, transactionManager)
-	merchantService := merchantSrv.NewService(bookingRepo, catalogRepo, merchantRepo, customerRep, blockedTimeRepo, teamRepo, productRepo, transactionManager)
-	productService := productSrv.NewService(productRepo, merchantRepo)
-	teamService := teamSrv.NewService(teamRepo, userRepo)
-	userService := userSrv.NewService(userRepo)
+	giftCardService := giftcardSrv.NewService(giftCardRepo, merchantRepo, emailService, nil, transactionManager)
+	intakeService := intakeSrv.NewService(intakeRepo)
+	reportService := reportSrv.NewService(reportRepo, merchantRepo, teamRepo, emailService, nil, transactionManager)
+	invoiceService := invoiceSrv.NewService(invoiceRepo, merchantRepo, emailService, nil, transactionManager)
+	merchantService := merchantSrv.NewService(bookingRepo, catalogRepo, merchantRepo, customerRep, blockedTimeRepo, teamRepo, productRepo, auditLogRepo, reportService, transactionManager)
+	passService := passSrv.NewService(passRepo, merchantRepo, transactionManager)
+	promotionService := promotionSrv.NewService(promotionRepo, merchantRepo, transactionManager)
+	productService := productSrv.NewService(productRepo, merchantRepo, catalogRepo)
+	teamService := teamSrv.NewService(teamRepo, userRepo, auditLogRepo, transactionManager)
+	userService := userSrv.NewService(userRepo, customerRep, transactionManager)
+	visitService := visitSrv.NewService(visitRepo, blobStore)
+	webhookService := webhookSrv.NewService(webhookRepo, nil, transactionManager)
 
 	enqueuer, err := queue.NewClient(dbConn, workers.Deps{
 		BookingService:     bookingService,
+		CustomerService:    customerService,
 		EmailService:       emailService,
 		ExtCalendarService: externalCalendarService,
+		GiftCardService:    giftCardService,
+		ReportService:      reportService,
+		InvoiceService:     invoiceService,
+		PassService:        passService,
+		UserService:        userService,
+		VisitService:       visitService,
+		WebhookService:     webhookService,
 		BookingRepo:        bookingRepo,
 		CatalogRepo:        catalogRepo,
 		UserRepo:           userRepo,
@@ -100,25 +159,45 @@ func New(ctx context.Context, cfg *config.Config) *App {
 	bookingService.SetEnqueuer(enqueuer)
 	externalCalendarService.SetEnqueuer(enqueuer)
 	blockedTimeService.SetEnqueuer(enqueuer)
+	customerService.SetEnqueuer(enqueuer)
+	giftCardService.SetEnqueuer(enqueuer)
+	reportService.SetEnqueuer(enqueuer)
+	invoiceService.SetEnqueuer(enqueuer)
+	userService.SetEnqueuer(enqueuer)
+	webhookService.SetEnqueuer(enqueuer)
 
-	middlewareManager := middleware.NewManager(merchantRepo, userRepo)
+	middlewareManager := middleware.NewManager(merchantRepo, userRepo, apiKeyRepo, limiter)
 
 	router := api.NewRouter(&api.Handlers{
 		Auth:              auth.NewHandler(authService, teamService, middlewareManager),
+		ApiKeys:           apikeys.NewHandler(apiKeyService, middlewareManager),
+		AuditLog:          auditlog.NewHandler(auditService, middlewareManager),
 		Bookings:          bookings.NewHandler(bookingService, middlewareManager),
 		PublicBookings:    publicBookings.NewHandler(bookingService, middlewareManager),
+		PublicGiftCards:   publicGiftCards.NewHandler(giftCardService, middlewareManager),
 		PublicMerchants:   publicMerchants.NewHandler(merchantService, middlewareManager),
+		Unsubscribe:       unsubscribe.NewHandler(customerService, middlewareManager),
+		ReportExports:     reportexports.NewHandler(reportService, middlewareManager),
 		Merchants:         merchants.NewHandler(merchantService, externalCalendarService),
-		BlockedTimes:      blockedtimes.NewHandler(blockedTimeService),
-		BlockedTimeTypes:  blockedtimetypes.NewHandler(blockedTimeService),
-		Customers:         customers.NewHandler(customerService),
+		BlockedTimes:      blockedtimes.NewHandler(blockedTimeService, middlewareManager),
+		BlockedTimeTypes:  blockedtimetypes.NewHandler(blockedTimeService, middlewareManager),
+		Customers:         customers.NewHandler(customerService, middlewareManager),
+		GiftCards:         giftcards.NewHandler(giftCardService, middlewareManager),
+		IntakeForms:       intakeforms.NewHandler(intakeService, middlewareManager),
 		Integrations:      integrations.NewHandler(externalCalendarService),
-		Users:             users.NewHandler(userService, bookingService, authService, middlewareManager),
-		Locations:         locations.NewHandler(merchantService),
-		Products:          products.NewHandler(productService),
-		Services:          services.NewHandler(catalogService),
-		ServiceCategories: servicecategories.NewHandler(catalogService),
-		Team:              team.NewHandler(teamService),
+		Users:             users.NewHandler(userService, bookingService, authService, invoiceService, middlewareManager),
+		Locations:         locations.NewHandler(merchantService, middlewareManager),
+		Passes:            passes.NewHandler(passService, middlewareManager),
+		Promotions:        promotions.NewHandler(promotionService, middlewareManager),
+		Products:          products.NewHandler(productService, middlewareManager),
+		Reports:           reports.NewHandler(reportService, middlewareManager),
+		Invoices:          invoices.NewHandler(invoiceService, middlewareManager),
+		Services:          services.NewHandler(catalogService, middlewareManager),
+		ServiceCategories: servicecategories.NewHandler(catalogService, middlewareManager),
+		TaxRates:          taxrates.NewHandler(catalogService, middlewareManager),
+		Team:              team.NewHandler(teamService, middlewareManager),
+		Visits:            visits.NewHandler(visitService, middlewareManager),
+		Webhooks:          webhooks.NewHandler(webhookService, middlewareManager),
 		Middleware:        middlewareManager,
 	})
 
@@ -153,6 +232,20 @@ func (a *App) Stop(ctx context.Context) {
 	a.dbConn.Close()
 }
 
+func newBlobStore(cfg *config.Config) (domain.BlobStore, error) {
+	if cfg.BLOB_STORE == "s3" {
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:        cfg.S3_ENDPOINT,
+			Region:          cfg.S3_REGION,
+			Bucket:          cfg.S3_BUCKET,
+			AccessKeyId:     cfg.S3_ACCESS_KEY_ID,
+			SecretAccessKey: cfg.S3_SECRET_ACCESS_KEY,
+		})
+	}
+
+	return blob.NewLocalStore(cfg.BLOB_LOCAL_DIR)
+}
+
 func registerTypes(ctx context.Context, conn *pgx.Conn) error {
 	types, err := conn.LoadTypes(ctx, []string{"price", "_price"})
 	if err != nil {
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type ApiKeyRepository interface {
+	WithTx(tx db.DBTX) ApiKeyRepository
+
+	NewApiKey(ctx context.Context, apiKey ApiKey) (int, error)
+	GetApiKeys(ctx context.Context, merchantId uuid.UUID) ([]ApiKey, error)
+	RevokeApiKey(ctx context.Context, merchantId uuid.UUID, apiKeyId int) error
+	// returns the key with the hash together with the employee it acts as
+	GetApiKeyAuthInfo(ctx context.Context, keyHash string) (ApiKeyAuthInfo, error)
+	// updates last_used_at, at most once a minute
+	TouchApiKey(ctx context.Context, apiKeyId int) error
+}
+
+type ApiKey struct {
+	Id         int                 `db:"id"`
+	MerchantId uuid.UUID           `db:"merchant_id"`
+	EmployeeId int                 `db:"employee_id"`
+	Name       string              `db:"name"`
+	Prefix     string              `db:"prefix"`
+	KeyHash    string              `db:"key_hash"`
+	Scopes     []types.ApiKeyScope `db:"scopes"`
+	// requests per minute
+	RateLimit  int        `db:"rate_limit"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func (a ApiKey) IsActive(now time.Time) bool {
+	if a.RevokedAt != nil {
+		return false
+	}
+
+	return a.ExpiresAt == nil || now.Before(*a.ExpiresAt)
+}
+
+type ApiKeyAuthInfo struct {
+	ApiKey
+	// nil if the employee's user was deleted
+	UserId           *uuid.UUID
+	IsEmployeeActive bool
+	Employee         EmployeeAuthInfo
+}
+
+// The key can only grant permissions its employee still has,
+// so removing a permission from the employee also removes it from their keys
+func (a ApiKeyAuthInfo) Permissions() []types.Permission {
+	employeePermissions := a.Employee.Permissions()
+
+	permissions := []types.Permission{}
+	for _, scope := range a.Scopes {
+		for _, p := range scope.Permissions() {
+			if slices.Contains(employeePermissions, p) && !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+
+	return permissions
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+// The audit log is append-only, entries are never updated or deleted
+// apart from when the merchant itself is deleted
+type AuditLogRepository interface {
+	WithTx(tx db.DBTX) AuditLogRepository
+
+	NewAuditLogEntry(ctx context.Context, entry AuditLogEntry) error
+	GetAuditLogEntries(ctx context.Context, merchantId uuid.UUID, filter AuditLogFilter, limit int) ([]AuditLogEntryWithEmployee, error)
+}
+
+type AuditLogEntry struct {
+	Id         int64                 `db:"id"`
+	MerchantId uuid.UUID             `db:"merchant_id"`
+	EmployeeId *int                  `db:"employee_id"`
+	Action     string                `db:"action"`
+	EntityType types.AuditEntityType `db:"entity_type"`
+	EntityId   string                `db:"entity_id"`
+	// Only contains the fields which changed
+	Before    json.RawMessage `db:"before"`
+	After     json.RawMessage `db:"after"`
+	RequestId *string         `db:"request_id"`
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+type AuditLogEntryWithEmployee struct {
+	AuditLogEntry
+	EmployeeFirstName *string `db:"employee_first_name"`
+	EmployeeLastName  *string `db:"employee_last_name"`
+}
+
+type AuditLogFilter struct {
+	EmployeeId *int
+	Action     *string
+	EntityType *types.AuditEntityType
+	EntityId   *string
+	From       *time.Time
+	To         *time.Time
+	// Entries older than the cursor are returned
+	CursorId *int64
+}
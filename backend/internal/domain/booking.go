@@ -37,6 +37,8 @@ type BookingRepository interface {
 	// decrements the participant count on every booking related to the customer
 	DecrementEveryParticipantCountForCustomer(ctx context.Context, customerId uuid.UUID, merchantId uuid.UUID) error
 	TransferDummyBookings(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+	MergeCustomerParticipants(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) (int, error)
+	MergeCustomerSeriesParticipants(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) (int, error)
 	UpdateBookingPhasesBatch(ctx context.Context, bookingPhases []BookingPhase) error
 
 	CancelBookingByMerchant(ctx context.Context, merchantId uuid.UUID, bookingId int, cancellationReason string) error
@@ -114,6 +116,8 @@ type Booking struct {
 	CancellationReason    *string             `db:"cancellation_reason"`
 	OccurrenceIndex       *int                `db:"occurrence_index"`
 	SeriesVersion         *int                `db:"series_version"`
+	TaxName               *string             `db:"tax_name"`
+	TaxPercent            *string             `db:"tax_percent"`
 }
 
 func (b Booking) IsCancelled() bool {
@@ -261,6 +265,9 @@ type BookingParticipant struct {
 	CancelledOn        *time.Time
 	CancellationReason *string
 	TransferredTo      *uuid.UUID
+	PromotionId        *int
+	// nil if the participant pays the price per person of the booking
+	PricePerPerson *currencyx.Price
 }
 
 func (bp BookingParticipant) IsCancelled() bool {
@@ -402,6 +409,8 @@ type BookingSeries struct {
 	MaxParticipants     int               `db:"max_participants"`
 	CurrentParticipants int               `db:"current_participants"`
 	Version             int               `db:"version"`
+	TaxName             *string           `db:"tax_name"`
+	TaxPercent          *string           `db:"tax_percent"`
 }
 
 type BookingSeriesParticipant struct {
@@ -452,4 +461,5 @@ type BookingForUser struct {
 	ServiceName       string              `db:"service_name"`
 	EmployeeFirstName *string             `db:"employee_first_name"`
 	EmployeeLastName  *string             `db:"employee_last_name"`
+	InvoiceNumber     *string             `db:"invoice_number"`
 }
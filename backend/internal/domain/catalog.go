@@ -8,6 +8,7 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/pricing"
 )
 
 type CatalogRepository interface {
@@ -48,8 +49,16 @@ type CatalogRepository interface {
 	UpdateServiceProducts(ctx context.Context, serviceId int, connectedProducts []ConnectedProducts) error
 	DeleteServiceProducts(ctx context.Context, serviceId int, productIds []int) error
 	GetServiceProducts(ctx context.Context, serviceId int) ([]ConnectedProducts, error)
+
+	NewTaxRate(ctx context.Context, merchantId uuid.UUID, taxRate TaxRate) (int, error)
+	UpdateTaxRate(ctx context.Context, merchantId uuid.UUID, taxRate TaxRate) error
+	// The services and products of the rate are left without a tax rate
+	DeleteTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId int) error
+	GetTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId int) (TaxRate, error)
+	GetTaxRates(ctx context.Context, merchantId uuid.UUID) ([]TaxRate, error)
 }
 
+// The price is the net price if the tax rate of the service is exclusive, the gross price otherwise
 type Service struct {
 	Id               int                 `db:"id" json:"id"`
 	MerchantId       uuid.UUID           `db:"merchant_id" json:"merchant_id"`
@@ -70,6 +79,7 @@ type Service struct {
 	BookingWindowMax *int                `db:"booking_window_max" json:"booking_window_max"`
 	BufferTime       *int                `db:"buffer_time" json:"buffer_time"`
 	ApprovalPolicy   *types.ApprovalType `db:"approval_policy" json:"approval_policy"`
+	TaxRateId        *int                `db:"tax_rate_id" json:"tax_rate_id"`
 	// for convenience we do not really query the service without the phases anyway
 	Phases []ServicePhase
 }
@@ -186,6 +196,7 @@ type ServicePageData struct {
 	Sequence        int                           `db:"sequence"`
 	MinParicipants  int                           `db:"min_participants"`
 	MaxParticipants int                           `db:"max_participants"`
+	TaxRateId       *int                          `db:"tax_rate_id"`
 	Settings        ServiceSettings               `db:"settings"`
 	Phases          []ServicePhase                `db:"phases"`
 	Products        []MinimalProductInfoWithUsage `db:"used_products"`
@@ -194,6 +205,7 @@ type ServicePageData struct {
 type ServicePageFormOptions struct {
 	Products   []MinimalProductInfo `json:"products"`
 	Categories []ServiceCategory    `json:"categories"`
+	TaxRates   []TaxRate            `json:"tax_rates"`
 }
 
 type ConnectedProducts struct {
@@ -244,3 +256,16 @@ type CalendarService struct {
 	BookingType     types.BookingType `json:"booking_type"`
 	MaxParticipants int               `json:"max_participants"`
 }
+
+type TaxRate struct {
+	Id         int       `db:"id" json:"id"`
+	MerchantId uuid.UUID `db:"merchant_id" json:"merchant_id"`
+	Name       string    `db:"name" json:"name"`
+	// decimal number with at most two decimals
+	Percent     string `db:"percent" json:"percent"`
+	IsInclusive bool   `db:"is_inclusive" json:"is_inclusive"`
+}
+
+func (t TaxRate) Rate() *pricing.Rate {
+	return &pricing.Rate{Percent: t.Percent, Inclusive: t.IsInclusive}
+}
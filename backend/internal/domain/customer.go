@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 )
 
@@ -12,18 +13,79 @@ type CustomerRepository interface {
 	WithTx(tx db.DBTX) CustomerRepository
 
 	NewCustomer(ctx context.Context, merchantId uuid.UUID, customer Customer) error
-	NewCustomerFromUser(ctx context.Context, customerId, merchantId, userId uuid.UUID) (uuid.UUID, bool, bool, error)
+	NewCustomerFromUser(ctx context.Context, customerId, merchantId, userId uuid.UUID) (CustomerFromUser, error)
 	UpdateCustomer(ctx context.Context, merchantId uuid.UUID, customer Customer) error
 	DeleteCustomer(ctx context.Context, customerId uuid.UUID, merchantId uuid.UUID) error
+	GetCustomerIdsByUser(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error)
+	// Erases the personal data of the customers, wherever it was copied to, but keeps their bookings
+	AnonymizeCustomers(ctx context.Context, customerIds []uuid.UUID) error
 
-	GetCustomers(ctx context.Context, merchantId uuid.UUID, isBlacklisted bool) ([]PublicCustomer, error)
+	// Returns a page of the customers matching the query and the number of matching customers
+	GetCustomers(ctx context.Context, merchantId uuid.UUID, query CustomerQuery) ([]PublicCustomer, int, error)
 	GetCustomerInfo(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (CustomerInfo, error)
 	GetCustomerStats(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (CustomerStatistics, error)
 	GetCustomersForCalendar(ctx context.Context, merchantId uuid.UUID) ([]CustomerForCalendar, error)
 
 	SetBlacklistStatusForCustomer(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, isBlacklisted bool, blacklistReason *string) error
+	GetCustomerBlacklistStatus(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (CustomerBlacklistStatus, error)
+	SetApprovalStatusForCustomer(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, requiresApproval bool, approvalReason *string) error
+	GetCustomerApprovalStatus(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (CustomerApprovalStatus, error)
+
+	NewCustomerPolicyRule(ctx context.Context, rule CustomerPolicyRule) (int, error)
+	UpdateCustomerPolicyRule(ctx context.Context, rule CustomerPolicyRule) error
+	DeleteCustomerPolicyRule(ctx context.Context, merchantId uuid.UUID, ruleId int) error
+	GetCustomerPolicyRules(ctx context.Context, merchantId uuid.UUID) ([]CustomerPolicyRule, error)
+	// Number of the customer's no-shows or cancellations at the merchant since the given time
+	CountCustomerPolicyEvents(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, event types.CustomerPolicyEvent, since time.Time) (int, error)
 
 	GetCustomerEmailById(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (*string, error)
+
+	NewCustomers(ctx context.Context, merchantId uuid.UUID, customers []Customer) error
+	GetCustomerContacts(ctx context.Context, merchantId uuid.UUID) ([]CustomerContact, error)
+
+	NewCustomerImport(ctx context.Context, customerImport CustomerImport) (int, error)
+	GetCustomerImport(ctx context.Context, merchantId uuid.UUID, importId int) (CustomerImport, error)
+	UpdateCustomerImportProgress(ctx context.Context, importId int, progress CustomerImportProgress) error
+
+	GetCustomerTags(ctx context.Context, merchantId uuid.UUID) ([]CustomerTag, error)
+
+	NewCustomerField(ctx context.Context, field CustomerField) (int, error)
+	UpdateCustomerField(ctx context.Context, field CustomerField) error
+	DeleteCustomerField(ctx context.Context, merchantId uuid.UUID, fieldId int) error
+	GetCustomerFields(ctx context.Context, merchantId uuid.UUID) ([]CustomerField, error)
+
+	NewCustomerSegment(ctx context.Context, segment CustomerSegment) (int, error)
+	UpdateCustomerSegment(ctx context.Context, segment CustomerSegment) error
+	DeleteCustomerSegment(ctx context.Context, merchantId uuid.UUID, segmentId int) error
+	GetCustomerSegment(ctx context.Context, merchantId uuid.UUID, segmentId int) (CustomerSegment, error)
+	GetCustomerSegments(ctx context.Context, merchantId uuid.UUID) ([]CustomerSegment, error)
+
+	GetDuplicateCandidates(ctx context.Context, merchantId uuid.UUID) ([]DuplicateCandidate, error)
+	GetCustomerForMergeWithLock(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (CustomerForMerge, error)
+	UpdateMergedCustomer(ctx context.Context, merchantId uuid.UUID, customer CustomerForMerge) error
+	NewCustomerMerge(ctx context.Context, merge CustomerMerge) error
+	MoveCustomerMerges(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+	GetCustomerMerges(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]CustomerMerge, error)
+	// Has to run before the customer it moves from is deleted
+	MoveMarketingConsents(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+
+	UpsertMarketingConsent(ctx context.Context, consent MarketingConsent) error
+	GetMarketingConsents(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]MarketingConsent, error)
+	// Ids of the merchant's customers who agreed to marketing messages on the channel
+	GetConsentedCustomerIds(ctx context.Context, merchantId uuid.UUID, channel types.MarketingChannel) ([]uuid.UUID, error)
+
+	NewCampaign(ctx context.Context, campaign Campaign, recipients []NewCampaignRecipient) (int, error)
+	GetCampaign(ctx context.Context, merchantId uuid.UUID, campaignId int) (CampaignWithStats, error)
+	GetCampaigns(ctx context.Context, merchantId uuid.UUID) ([]CampaignWithStats, error)
+	// Sets the status of the campaign, the pending recipients are skipped if it's cancelled
+	UpdateCampaignStatus(ctx context.Context, campaignId int, status types.CampaignStatus) error
+	// Number of the merchant's campaign emails which were sent or are waiting to be sent since the given time
+	CountCampaignEmails(ctx context.Context, merchantId uuid.UUID, since time.Time) (int, error)
+	// Number of the merchant's campaign emails which were sent since the given time
+	CountSentCampaignEmails(ctx context.Context, merchantId uuid.UUID, since time.Time) (int, error)
+	GetPendingCampaignRecipients(ctx context.Context, campaignId int, limit int) ([]CampaignRecipient, error)
+	UpdateCampaignRecipients(ctx context.Context, results []CampaignRecipientResult) error
+	GetUnsubscribeTarget(ctx context.Context, unsubscribeToken string) (UnsubscribeTarget, error)
 }
 
 type Customer struct {
@@ -34,15 +96,57 @@ type Customer struct {
 	PhoneNumber *string    `json:"phone_number" db:"phone_number"`
 	Birthday    *time.Time `json:"birthday" db:"birthday"`
 	Note        *string    `json:"note" db:"note"`
+	Tags        []string   `json:"tags" db:"tags"`
+	// Values of the merchant's custom fields keyed by the id of the field
+	CustomFields CustomFieldValues `json:"custom_fields" db:"custom_fields"`
+}
+
+// Removes the contact details for employees who are not allowed to see them
+func (c Customer) WithoutPii() Customer {
+	c.Email = nil
+	c.PhoneNumber = nil
+	c.Birthday = nil
+
+	return c
 }
 
 type PublicCustomer struct {
 	Customer
-	IsDummy         bool    `json:"is_dummy" db:"is_dummy"`
+	IsDummy         bool       `json:"is_dummy" db:"is_dummy"`
+	IsBlacklisted   bool       `json:"is_blacklisted" db:"is_blacklisted"`
+	BlacklistReason *string    `json:"blacklist_reason" db:"blacklist_reason"`
+	TimesBooked     int        `json:"times_booked" db:"times_booked"`
+	TimesCancelled  int        `json:"times_cancelled" db:"times_cancelled"`
+	NoShows         int        `json:"no_shows" db:"no_shows"`
+	LastVisit       *time.Time `json:"last_visit" db:"last_visit"`
+}
+
+type CustomerBlacklistStatus struct {
 	IsBlacklisted   bool    `json:"is_blacklisted" db:"is_blacklisted"`
 	BlacklistReason *string `json:"blacklist_reason" db:"blacklist_reason"`
-	TimesBooked     int     `json:"times_booked" db:"times_booked"`
-	TimesCancelled  int     `json:"times_cancelled" db:"times_cancelled"`
+}
+
+type CustomerFromUser struct {
+	Id               uuid.UUID
+	IsBlacklisted    bool
+	RequiresApproval bool
+	// the user did not book with the merchant before
+	IsNew bool
+}
+
+type CustomerApprovalStatus struct {
+	RequiresApproval bool    `json:"requires_approval" db:"requires_approval"`
+	ApprovalReason   *string `json:"approval_reason" db:"approval_reason"`
+}
+
+type CustomerPolicyRule struct {
+	Id         int                        `db:"id"`
+	MerchantId uuid.UUID                  `db:"merchant_id"`
+	Event      types.CustomerPolicyEvent  `db:"event"`
+	Threshold  int                        `db:"threshold"`
+	PeriodDays int                        `db:"period_days"`
+	Action     types.CustomerPolicyAction `db:"action"`
+	CreatedAt  time.Time                  `db:"created_at"`
 }
 
 type CustomerInfo struct {
@@ -55,6 +159,8 @@ type CustomerStatistics struct {
 	IsDummy              bool            `json:"is_dummy"`
 	IsBlacklisted        bool            `json:"is_blacklisted"`
 	BlacklistReason      *string         `json:"blacklist_reason"`
+	RequiresApproval     bool            `json:"requires_approval"`
+	ApprovalReason       *string         `json:"approval_reason"`
 	TimesBooked          int             `json:"times_booked"`
 	TimesCancelledByUser int             `json:"times_cancelled_by_user"`
 	TimesUpcoming        int             `json:"times_upcoming"`
@@ -72,3 +178,231 @@ type CustomerForCalendar struct {
 	IsDummy     bool       `json:"is_dummy" db:"is_dummy"`
 	LastVisited *time.Time `json:"last_visited" db:"last_visited"`
 }
+
+func (c CustomerForCalendar) WithoutPii() CustomerForCalendar {
+	c.Email = nil
+	c.PhoneNumber = nil
+	c.BirthDay = nil
+
+	return c
+}
+
+// Contact details used to find customers who already exist
+type CustomerContact struct {
+	Id          uuid.UUID `db:"id"`
+	Email       *string   `db:"email"`
+	PhoneNumber *string   `db:"phone_number"`
+}
+
+// Names of the csv columns holding the customer's fields,
+// a field without a column name is read from the column named after the field
+type CustomerImportMapping struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Birthday    string `json:"birthday"`
+	Note        string `json:"note"`
+}
+
+type CustomerImportOptions struct {
+	Mapping CustomerImportMapping `json:"mapping"`
+	// Calling code of the phone numbers written without one, e.g. +36
+	DefaultCountryCode string `json:"default_country_code"`
+}
+
+// Reason why a row of the file was not imported
+type CustomerImportRowError struct {
+	// Line of the row in the file, the header is the first line
+	Row int `json:"row"`
+	// Customer field of the invalid value, nil if the whole row is affected
+	Field   *string `json:"field"`
+	Message string  `json:"message"`
+}
+
+type CustomerImport struct {
+	Id             int                        `db:"id"`
+	MerchantId     uuid.UUID                  `db:"merchant_id"`
+	EmployeeId     *int                       `db:"employee_id"`
+	Status         types.CustomerImportStatus `db:"status"`
+	Content        string                     `db:"content"`
+	Options        CustomerImportOptions      `db:"options"`
+	TotalRows      int                        `db:"total_rows"`
+	ProcessedRows  int                        `db:"processed_rows"`
+	CreatedCount   int                        `db:"created_count"`
+	DuplicateCount int                        `db:"duplicate_count"`
+	InvalidCount   int                        `db:"invalid_count"`
+	RowErrors      []CustomerImportRowError   `db:"row_errors"`
+	Error          *string                    `db:"error"`
+	CreatedAt      time.Time                  `db:"created_at"`
+	FinishedAt     *time.Time                 `db:"finished_at"`
+}
+
+type CustomerImportProgress struct {
+	Status         types.CustomerImportStatus
+	ProcessedRows  int
+	CreatedCount   int
+	DuplicateCount int
+	InvalidCount   int
+	RowErrors      []CustomerImportRowError
+	Error          *string
+}
+
+// Customer compared with the other customers of the merchant when looking for duplicates
+type DuplicateCandidate struct {
+	Id            uuid.UUID `json:"id" db:"id"`
+	FirstName     *string   `json:"first_name" db:"first_name"`
+	LastName      *string   `json:"last_name" db:"last_name"`
+	Email         *string   `json:"email" db:"email"`
+	PhoneNumber   *string   `json:"phone_number" db:"phone_number"`
+	IsDummy       bool      `json:"is_dummy" db:"is_dummy"`
+	IsBlacklisted bool      `json:"is_blacklisted" db:"is_blacklisted"`
+}
+
+// The stored fields of a customer, without the details of the linked user
+type CustomerForMerge struct {
+	Customer
+	UserId          *uuid.UUID `json:"user_id" db:"user_id"`
+	IsBlacklisted   bool       `json:"is_blacklisted" db:"is_blacklisted"`
+	BlacklistReason *string    `json:"blacklist_reason" db:"blacklist_reason"`
+}
+
+type CustomerMerge struct {
+	Id         int       `db:"id"`
+	MerchantId uuid.UUID `db:"merchant_id"`
+	CustomerId uuid.UUID `db:"customer_id"`
+	EmployeeId *int      `db:"employee_id"`
+	// The duplicate as it was before being merged, it no longer exists
+	MergedCustomer          CustomerForMerge `db:"merged_customer"`
+	MovedParticipants       int              `db:"moved_participants"`
+	MovedSeriesParticipants int              `db:"moved_series_participants"`
+	CreatedAt               time.Time        `db:"created_at"`
+}
+
+// Values of custom fields keyed by the field's id. Text, date and select values are strings,
+// dates are formatted as 2006-01-02, numbers are float64
+type CustomFieldValues map[string]any
+
+// Field defined by the merchant to store extra details of their customers
+type CustomerField struct {
+	Id         int                   `json:"id" db:"id"`
+	MerchantId uuid.UUID             `json:"merchant_id" db:"merchant_id"`
+	Name       string                `json:"name" db:"name"`
+	FieldType  types.CustomFieldType `json:"field_type" db:"field_type"`
+	// The choices of select fields
+	Options    []string `json:"options" db:"options"`
+	IsRequired bool     `json:"is_required" db:"is_required"`
+}
+
+type CustomerTag struct {
+	Tag   string `db:"tag"`
+	Count int    `db:"count"`
+}
+
+// Conditions a customer has to match, conditions which are not set match every customer
+type CustomerFilter struct {
+	// Part of the name, email or phone number
+	Search        *string `json:"search,omitempty"`
+	IsBlacklisted *bool   `json:"is_blacklisted,omitempty"`
+	// The customer has all of the tags
+	Tags []string `json:"tags,omitempty"`
+	// No completed booking in the last given days, including customers who never visited
+	NoVisitInDays *int `json:"no_visit_in_days,omitempty"`
+	// The birthday is in the current month of the merchant's timezone
+	BirthdayThisMonth bool `json:"birthday_this_month,omitempty"`
+	MinNoShows        *int `json:"min_no_shows,omitempty"`
+	MinTimesBooked    *int `json:"min_times_booked,omitempty"`
+	// The custom fields have exactly these values
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
+}
+
+// Saved filter of customers
+type CustomerSegment struct {
+	Id         int            `db:"id"`
+	MerchantId uuid.UUID      `db:"merchant_id"`
+	Name       string         `db:"name"`
+	Filter     CustomerFilter `db:"filter"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+const (
+	CustomerSortName        = "name"
+	CustomerSortTimesBooked = "times_booked"
+	CustomerSortNoShows     = "no_shows"
+	CustomerSortLastVisit   = "last_visit"
+)
+
+type CustomerQuery struct {
+	Filter CustomerFilter
+	// One of the CustomerSort constants, sorted by name if empty
+	SortBy     string
+	Descending bool
+	// Every matching customer is returned if nil
+	Limit  *int
+	Offset int
+}
+
+type MarketingConsent struct {
+	CustomerId uuid.UUID              `db:"customer_id"`
+	Channel    types.MarketingChannel `db:"channel"`
+	IsGranted  bool                   `db:"is_granted"`
+	Source     types.ConsentSource    `db:"source"`
+	EmployeeId *int                   `db:"employee_id"`
+	UpdatedAt  time.Time              `db:"updated_at"`
+}
+
+type Campaign struct {
+	Id         int                  `db:"id"`
+	MerchantId uuid.UUID            `db:"merchant_id"`
+	EmployeeId *int                 `db:"employee_id"`
+	Name       string               `db:"name"`
+	Subject    string               `db:"subject"`
+	Body       string               `db:"body"`
+	Status     types.CampaignStatus `db:"status"`
+	Filter     CustomerFilter       `db:"filter"`
+	CreatedAt  time.Time            `db:"created_at"`
+	FinishedAt *time.Time           `db:"finished_at"`
+}
+
+// Delivery counts of the campaign's recipients, opens and clicks are not tracked
+type CampaignStats struct {
+	RecipientCount int `db:"recipient_count"`
+	PendingCount   int `db:"pending_count"`
+	SentCount      int `db:"sent_count"`
+	SkippedCount   int `db:"skipped_count"`
+	FailedCount    int `db:"failed_count"`
+}
+
+type CampaignWithStats struct {
+	Campaign
+	CampaignStats
+	MerchantName string `db:"merchant_name"`
+}
+
+type NewCampaignRecipient struct {
+	CustomerId       uuid.UUID
+	UnsubscribeToken string
+}
+
+// Recipient waiting for the campaign email with the customer's current details
+type CampaignRecipient struct {
+	Id               int64     `db:"id"`
+	CustomerId       uuid.UUID `db:"customer_id"`
+	UnsubscribeToken string    `db:"unsubscribe_token"`
+	Email            *string   `db:"email"`
+	FirstName        *string   `db:"first_name"`
+	UserLanguage     *string   `db:"user_language"`
+	// whether the customer still agrees to marketing emails
+	HasConsent bool `db:"has_consent"`
+}
+
+type CampaignRecipientResult struct {
+	Id     int64
+	Status types.CampaignRecipientStatus
+	Error  *string
+}
+
+type UnsubscribeTarget struct {
+	CustomerId   uuid.UUID `db:"customer_id"`
+	MerchantName string    `db:"merchant_name"`
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type GiftCardRepository interface {
+	WithTx(tx db.DBTX) GiftCardRepository
+
+	// Inserts the card together with the transaction of its initial value
+	NewGiftCard(ctx context.Context, giftCard NewGiftCard) (int, error)
+
+	GetGiftCard(ctx context.Context, merchantId uuid.UUID, giftCardId int) (GiftCard, error)
+	// All gift cards of the merchant, the latest first
+	GetGiftCards(ctx context.Context, merchantId uuid.UUID) ([]GiftCard, error)
+	GetGiftCardByCode(ctx context.Context, merchantId uuid.UUID, code string) (GiftCard, error)
+	// Locks the card until the transaction ends, so concurrent redemptions of it wait for each other
+	GetGiftCardByCodeForUpdate(ctx context.Context, merchantId uuid.UUID, code string) (GiftCard, error)
+	GetGiftCardTransactions(ctx context.Context, giftCardId int) ([]GiftCardTransaction, error)
+	GetGiftCardForEmail(ctx context.Context, giftCardId int) (GiftCardForEmail, error)
+
+	// Locks the booking until the transaction ends, so two cards can not pay for the same part of it
+	GetGiftCardBookingForUpdate(ctx context.Context, merchantId uuid.UUID, bookingId int) (GiftCardBooking, error)
+	// Subtracts the amount from the balance of the card and records it in the ledger,
+	// fails if the balance is not enough
+	RedeemGiftCard(ctx context.Context, redemption GiftCardRedemption) error
+	// Gives back what was paid with gift cards for the bookings, as positive ledger entries of the same booking.
+	// Only the redemptions of the given customers are given back, unless customerIds is nil
+	RefundGiftCardRedemptions(ctx context.Context, bookingIds []int, customerIds []uuid.UUID) error
+	// Moves the ledger entries of a customer merged into another one, so cancelling their participation still refunds the card
+	MergeCustomerGiftCardTransactions(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+}
+
+type GiftCard struct {
+	Id             int             `db:"id"`
+	MerchantId     uuid.UUID       `db:"merchant_id"`
+	Code           string          `db:"code"`
+	InitialValue   currencyx.Price `db:"initial_value"`
+	Balance        currencyx.Price `db:"balance"`
+	ExpiresAt      *time.Time      `db:"expires_at"`
+	IssuedBy       *int            `db:"issued_by"`
+	RecipientName  *string         `db:"recipient_name"`
+	RecipientEmail *string         `db:"recipient_email"`
+	Message        *string         `db:"message"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+func (g GiftCard) WithoutCode() GiftCard {
+	g.Code = ""
+
+	return g
+}
+
+func (g GiftCard) IsExpired(now time.Time) bool {
+	return g.ExpiresAt != nil && !now.Before(*g.ExpiresAt)
+}
+
+type NewGiftCard struct {
+	MerchantId     uuid.UUID
+	Code           string
+	InitialValue   currencyx.Price
+	ExpiresAt      *time.Time
+	IssuedBy       *int
+	RecipientName  *string
+	RecipientEmail *string
+	Message        *string
+}
+
+type GiftCardTransaction struct {
+	Id         int             `db:"id"`
+	GiftCardId int             `db:"gift_card_id"`
+	Amount     currencyx.Price `db:"amount"`
+	BookingId  *int            `db:"booking_id"`
+	EmployeeId *int            `db:"employee_id"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
+
+// The price of a booking and the part of it which was already paid with gift cards
+type GiftCardBooking struct {
+	Id         int                 `db:"id"`
+	Status     types.BookingStatus `db:"status"`
+	EmployeeId *int                `db:"employee_id"`
+	TotalPrice currencyx.Price     `db:"total_price"`
+	Redeemed   currencyx.Price     `db:"redeemed"`
+}
+
+type GiftCardRedemption struct {
+	GiftCardId int
+	Amount     currencyx.Price
+	BookingId  int
+	EmployeeId *int
+	CustomerId *uuid.UUID
+}
+
+type GiftCardForEmail struct {
+	Id              int             `db:"id"`
+	Code            string          `db:"code"`
+	InitialValue    currencyx.Price `db:"initial_value"`
+	ExpiresAt       *time.Time      `db:"expires_at"`
+	RecipientName   *string         `db:"recipient_name"`
+	RecipientEmail  *string         `db:"recipient_email"`
+	Message         *string         `db:"message"`
+	MerchantName    string          `db:"merchant_name"`
+	MerchantUrlName string          `db:"merchant_url_name"`
+	Timezone        string          `db:"timezone"`
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type IntakeRepository interface {
+	WithTx(tx db.DBTX) IntakeRepository
+
+	// Attaches the form to the given services of the merchant, they are detached from their previous form
+	NewIntakeForm(ctx context.Context, form IntakeForm) (int, error)
+	// The services not given are detached from the form
+	UpdateIntakeForm(ctx context.Context, form IntakeForm) error
+	DeleteIntakeForm(ctx context.Context, merchantId uuid.UUID, formId int) error
+	GetIntakeForm(ctx context.Context, merchantId uuid.UUID, formId int) (IntakeForm, error)
+	GetIntakeForms(ctx context.Context, merchantId uuid.UUID) ([]IntakeForm, error)
+	// Returns pgx.ErrNoRows if the service has no form
+	GetIntakeFormForService(ctx context.Context, merchantId uuid.UUID, serviceId int) (IntakeForm, error)
+
+	// Whether any of the user's bookings at the merchant has a response to the form
+	HasUserAnsweredIntakeForm(ctx context.Context, formId int, userId uuid.UUID) (bool, error)
+	// The response belongs to the customer's participation in the booking
+	NewIntakeResponse(ctx context.Context, bookingId int, customerId uuid.UUID, response IntakeResponse) error
+	GetCustomerIntakeResponses(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]CustomerIntakeResponse, error)
+}
+
+// Shows the question only if the answer to an earlier question is one of the values.
+// Yes/no answers are compared as "yes" and "no", multiple choice answers match if any of the choices does
+type IntakeCondition struct {
+	QuestionId string   `json:"question_id"`
+	Values     []string `json:"values"`
+}
+
+type IntakeQuestion struct {
+	// Chosen by the merchant, conditions and answers refer to the question by it
+	Id         string                   `json:"id"`
+	Label      string                   `json:"label"`
+	Type       types.IntakeQuestionType `json:"type"`
+	IsRequired bool                     `json:"is_required"`
+	// The choices of single and multiple choice questions
+	Options   []string         `json:"options"`
+	VisibleIf *IntakeCondition `json:"visible_if"`
+}
+
+// Questionnaire the customer fills out when booking the services it's attached to
+type IntakeForm struct {
+	Id         int                  `db:"id"`
+	MerchantId uuid.UUID            `db:"merchant_id"`
+	Name       string               `db:"name"`
+	Mode       types.IntakeFormMode `db:"mode"`
+	Questions  []IntakeQuestion     `db:"questions"`
+	ServiceIds []int                `db:"service_ids"`
+	CreatedAt  time.Time            `db:"created_at"`
+	UpdatedAt  time.Time            `db:"updated_at"`
+}
+
+// Answers keyed by the id of the question, hidden and unanswered questions are left out
+type IntakeAnswers map[string]any
+
+type IntakeResponse struct {
+	Id            int       `db:"id"`
+	MerchantId    uuid.UUID `db:"merchant_id"`
+	FormId        *int      `db:"form_id"`
+	ParticipantId int       `db:"participant_id"`
+	// The name and questions of the form when it was answered
+	FormName  string           `db:"form_name"`
+	Questions []IntakeQuestion `db:"questions"`
+	Answers   IntakeAnswers    `db:"answers"`
+	// Only set if the customer agreed to a consent question
+	Signature *string    `db:"signature"`
+	SignedAt  *time.Time `db:"signed_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type CustomerIntakeResponse struct {
+	IntakeResponse
+	BookingId   int       `db:"booking_id"`
+	EmployeeId  *int      `db:"employee_id"`
+	ServiceName string    `db:"service_name"`
+	FromDate    time.Time `db:"from_date"`
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type InvoiceRepository interface {
+	WithTx(tx db.DBTX) InvoiceRepository
+
+	// The settings of a merchant who did not set them yet are the defaults with the name and contact email of the merchant
+	GetInvoiceSettings(ctx context.Context, merchantId uuid.UUID) (InvoiceSettings, error)
+	UpdateInvoiceSettings(ctx context.Context, merchantId uuid.UUID, settings UpdateInvoiceSettings) error
+	// Reserves the next number of the merchant, the numbers stay without gaps as the row is locked until the transaction ends
+	NextInvoiceSequence(ctx context.Context, merchantId uuid.UUID) (int, error)
+
+	// Completed participants of the booking who did not get an invoice yet and did not pay with a pass
+	GetUninvoicedParticipants(ctx context.Context, bookingId int) ([]InvoiceableParticipant, error)
+	NewInvoice(ctx context.Context, invoice NewInvoice) (int, error)
+	SetInvoiceEmailed(ctx context.Context, invoiceId int, emailedAt time.Time) error
+
+	GetInvoice(ctx context.Context, merchantId uuid.UUID, invoiceId int) (Invoice, error)
+	// Invoices issued between the two times, the latest first
+	GetInvoices(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]Invoice, error)
+	GetInvoiceLines(ctx context.Context, invoiceId int) ([]InvoiceLine, error)
+	GetInvoiceFile(ctx context.Context, merchantId uuid.UUID, invoiceId int) ([]byte, error)
+	GetInvoiceForEmail(ctx context.Context, invoiceId int) (InvoiceForEmail, error)
+	// The invoice of the participation of the user in the booking
+	GetInvoiceForUser(ctx context.Context, userId uuid.UUID, bookingId int) (Invoice, error)
+	// Moves the invoices of a customer merged into another one, the buyer details printed on them stay the same
+	MergeCustomerInvoices(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+}
+
+type InvoiceSettings struct {
+	MerchantName       string  `db:"merchant_name"`
+	ContactEmail       string  `db:"contact_email"`
+	LegalName          *string `db:"legal_name"`
+	TaxNumber          *string `db:"tax_number"`
+	RegistrationNumber *string `db:"registration_number"`
+	Address            *string `db:"address"`
+	Prefix             string  `db:"prefix"`
+	Footer             *string `db:"footer"`
+	NextSequence       int     `db:"next_sequence"`
+}
+
+type UpdateInvoiceSettings struct {
+	LegalName          *string
+	TaxNumber          *string
+	RegistrationNumber *string
+	Address            *string
+	Prefix             string
+	Footer             *string
+}
+
+type InvoiceableParticipant struct {
+	ParticipantId  int                 `db:"participant_id"`
+	BookingId      int                 `db:"booking_id"`
+	MerchantId     uuid.UUID           `db:"merchant_id"`
+	CustomerId     uuid.UUID           `db:"customer_id"`
+	FirstName      *string             `db:"first_name"`
+	LastName       *string             `db:"last_name"`
+	Email          *string             `db:"email"`
+	ServiceId      *int                `db:"service_id"`
+	ServiceName    string              `db:"service_name"`
+	PricePerPerson currencyx.Price     `db:"price_per_person"`
+	PriceType      types.PriceType     `db:"price_type"`
+	TaxName        *string             `db:"tax_name"`
+	TaxPercent     *string             `db:"tax_percent"`
+	FromDate       time.Time           `db:"from_date"`
+	Status         types.BookingStatus `db:"status"`
+}
+
+type InvoiceLine struct {
+	Description string          `db:"description"`
+	Quantity    int             `db:"quantity"`
+	UnitPrice   currencyx.Price `db:"unit_price"`
+	NetAmount   currencyx.Price `db:"net_amount"`
+	TaxName     *string         `db:"tax_name"`
+	TaxPercent  *string         `db:"tax_percent"`
+	TaxAmount   currencyx.Price `db:"tax_amount"`
+	GrossAmount currencyx.Price `db:"gross_amount"`
+}
+
+type InvoiceSeller struct {
+	Name               string
+	TaxNumber          *string
+	RegistrationNumber *string
+	Address            *string
+	Email              string
+}
+
+type NewInvoice struct {
+	MerchantId    uuid.UUID
+	BookingId     int
+	ParticipantId int
+	CustomerId    uuid.UUID
+	Sequence      int
+	InvoiceNumber string
+	IssuedAt      time.Time
+	ServiceDate   time.Time
+	Seller        InvoiceSeller
+	BuyerName     string
+	BuyerEmail    *string
+	NetTotal      currencyx.Price
+	TaxTotal      currencyx.Price
+	Total         currencyx.Price
+	Footer        *string
+	Lines         []InvoiceLine
+	File          []byte
+}
+
+type Invoice struct {
+	Id                       int             `db:"id"`
+	MerchantId               uuid.UUID       `db:"merchant_id"`
+	BookingId                *int            `db:"booking_id"`
+	ParticipantId            *int            `db:"participant_id"`
+	CustomerId               *uuid.UUID      `db:"customer_id"`
+	Sequence                 int             `db:"sequence"`
+	InvoiceNumber            string          `db:"invoice_number"`
+	IssuedAt                 time.Time       `db:"issued_at"`
+	ServiceDate              time.Time       `db:"service_date"`
+	SellerName               string          `db:"seller_name"`
+	SellerTaxNumber          *string         `db:"seller_tax_number"`
+	SellerRegistrationNumber *string         `db:"seller_registration_number"`
+	SellerAddress            *string         `db:"seller_address"`
+	SellerEmail              string          `db:"seller_email"`
+	BuyerName                string          `db:"buyer_name"`
+	BuyerEmail               *string         `db:"buyer_email"`
+	NetTotal                 currencyx.Price `db:"net_total"`
+	TaxTotal                 currencyx.Price `db:"tax_total"`
+	Total                    currencyx.Price `db:"total"`
+	Footer                   *string         `db:"footer"`
+	EmailedAt                *time.Time      `db:"emailed_at"`
+}
+
+type InvoiceForEmail struct {
+	Id            int             `db:"id"`
+	InvoiceNumber string          `db:"invoice_number"`
+	SellerName    string          `db:"seller_name"`
+	BuyerName     string          `db:"buyer_name"`
+	BuyerEmail    *string         `db:"buyer_email"`
+	ServiceDate   time.Time       `db:"service_date"`
+	Total         currencyx.Price `db:"total"`
+	Timezone      string          `db:"timezone"`
+	UserLanguage  *string         `db:"user_language"`
+	EmailedAt     *time.Time      `db:"emailed_at"`
+	File          []byte          `db:"file"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/bojanz/currency"
 	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
@@ -30,9 +31,6 @@ type MerchantRepository interface {
 	GetBookingSettingsByMerchantAndService(ctx context.Context, merchantId uuid.UUID, serviceId int) (MerchantBookingSettings, error)
 	GetMerchantNameAndLocation(ctx context.Context, merchantId uuid.UUID, locationId int) (string, string, error)
 
-	GetDashboardStats(ctx context.Context, merchantId uuid.UUID, startDate time.Time, endDate time.Time, prevStartDate time.Time) (DashboardStatistics, error)
-	GetRevenueStats(ctx context.Context, merchantId uuid.UUID, startDate time.Time, endDate time.Time) ([]RevenueStat, error)
-
 	NewBusinessHours(ctx context.Context, merchantId uuid.UUID, businessHours BusinessHours) error
 	DeleteOutdatedBusinessHours(ctx context.Context, merchantId uuid.UUID, businessHours BusinessHours) error
 	GetBusinessHours(ctx context.Context, merchantId uuid.UUID) (BusinessHours, error)
@@ -150,16 +148,23 @@ type RevenueStat struct {
 	Day   time.Time `json:"day" db:"day"`
 }
 
+type TaxStat struct {
+	Name    string          `json:"name"`
+	Percent string          `json:"percent"`
+	Amount  currency.Amount `json:"amount"`
+}
+
 type DashboardStatistics struct {
-	Revenue               []RevenueStat `json:"revenue"`
-	RevenueSum            string        `json:"revenue_sum"`
-	RevenueChange         int           `json:"revenue_change"`
-	Bookings              int           `json:"bookings"`
-	BookingsChange        int           `json:"bookings_change"`
-	Cancellations         int           `json:"cancellations"`
-	CancellationsChange   int           `json:"cancellations_change"`
-	AverageDuration       int           `json:"average_duration"`
-	AverageDurationChange int           `json:"average_duration_change"`
+	Revenue               []RevenueStat   `json:"revenue"`
+	RevenueSum            currency.Amount `json:"revenue_sum"`
+	RevenueChange         int             `json:"revenue_change"`
+	Taxes                 []TaxStat       `json:"taxes"`
+	Bookings              int             `json:"bookings"`
+	BookingsChange        int             `json:"bookings_change"`
+	Cancellations         int             `json:"cancellations"`
+	CancellationsChange   int             `json:"cancellations_change"`
+	AverageDuration       int             `json:"average_duration"`
+	AverageDurationChange int             `json:"average_duration_change"`
 }
 
 type LowStockProduct struct {
@@ -178,6 +183,20 @@ type DashboardData struct {
 	LatestBookings   []PublicBookingDetails `json:"latest_bookings"`
 	LowStockProducts []LowStockProduct      `json:"low_stock_products"`
 	Statistics       DashboardStatistics    `json:"statistics"`
+	Utilization      DashboardUtilization   `json:"utilization"`
+}
+
+type DashboardEmployeeUtilization struct {
+	EmployeeId  int    `json:"employee_id"`
+	Name        string `json:"name"`
+	Utilization int    `json:"utilization"`
+}
+
+type DashboardUtilization struct {
+	Utilization    int                            `json:"utilization"`
+	RevenuePerHour currency.Amount                `json:"revenue_per_hour"`
+	IdleGapMinutes int                            `json:"idle_gap_minutes"`
+	Employees      []DashboardEmployeeUtilization `json:"employees"`
 }
 
 type Location struct {
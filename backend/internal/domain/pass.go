@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type PassRepository interface {
+	WithTx(tx db.DBTX) PassRepository
+
+	// Inserts the product together with its services and categories, the ones of other merchants are left out
+	NewPassProduct(ctx context.Context, product PassProduct) (int, error)
+	// Updates the product and replaces its services and categories
+	UpdatePassProduct(ctx context.Context, product PassProduct) error
+	GetPassProduct(ctx context.Context, merchantId uuid.UUID, productId int) (PassProduct, error)
+	GetPassProducts(ctx context.Context, merchantId uuid.UUID) ([]PassProduct, error)
+
+	// Fails with pgx.ErrNoRows if the customer does not belong to the merchant
+	NewCustomerPass(ctx context.Context, pass NewCustomerPass) (int, error)
+	GetCustomerPass(ctx context.Context, merchantId uuid.UUID, customerPassId int) (CustomerPass, error)
+	// All passes of the customer, the latest first
+	GetCustomerPasses(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]CustomerPass, error)
+	GetCustomerPassUsages(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]PassUsage, error)
+
+	// The pass of the customer which should pay for the service at the given time, preferring unlimited
+	// passes and then the ones which expire sooner. Locks the pass until the transaction ends
+	GetUsablePassForUpdate(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, serviceId int, at time.Time) (CustomerPass, error)
+	// Takes a credit from the pass for the booking, fails if the pass has none left
+	UsePass(ctx context.Context, customerPassId int, bookingId int) error
+	// Gives back the credits used for the bookings by the customers, or by everyone if customerIds is nil
+	RefundPassUsages(ctx context.Context, bookingIds []int, customerIds []uuid.UUID) error
+	// Marks the passes which are not valid anymore as expired, returns how many were marked
+	ExpirePasses(ctx context.Context, now time.Time) (int, error)
+
+	// Moves the passes of a customer to another one. Credits used by both of them for
+	// the same booking are given back to the pass of the merged customer
+	MergeCustomerPasses(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error
+}
+
+type PassProduct struct {
+	Id         int       `db:"id"`
+	MerchantId uuid.UUID `db:"merchant_id"`
+	Name       string    `db:"name"`
+	// nil if the pass is unlimited
+	Credits      *int            `db:"credits"`
+	ValidityDays int             `db:"validity_days"`
+	Price        currencyx.Price `db:"price"`
+	IsActive     bool            `db:"is_active"`
+	ServiceIds   []int           `db:"service_ids"`
+	CategoryIds  []int           `db:"category_ids"`
+	CreatedAt    time.Time       `db:"created_at"`
+}
+
+type NewCustomerPass struct {
+	MerchantId    uuid.UUID
+	CustomerId    uuid.UUID
+	PassProductId int
+	Name          string
+	Credits       *int
+	ValidFrom     time.Time
+	ValidUntil    time.Time
+	AssignedBy    *int
+}
+
+type CustomerPass struct {
+	Id            int       `db:"id"`
+	MerchantId    uuid.UUID `db:"merchant_id"`
+	CustomerId    uuid.UUID `db:"customer_id"`
+	PassProductId int       `db:"pass_product_id"`
+	Name          string    `db:"name"`
+	// both are nil if the pass is unlimited
+	Credits          *int       `db:"credits"`
+	RemainingCredits *int       `db:"remaining_credits"`
+	ValidFrom        time.Time  `db:"valid_from"`
+	ValidUntil       time.Time  `db:"valid_until"`
+	ExpiredAt        *time.Time `db:"expired_at"`
+	AssignedBy       *int       `db:"assigned_by"`
+	CreatedAt        time.Time  `db:"created_at"`
+}
+
+func (p CustomerPass) IsUnlimited() bool {
+	return p.Credits == nil
+}
+
+func (p CustomerPass) IsExpired(now time.Time) bool {
+	return p.ExpiredAt != nil || !now.Before(p.ValidUntil)
+}
+
+type PassUsage struct {
+	Id             int        `db:"id"`
+	CustomerPassId int        `db:"customer_pass_id"`
+	BookingId      int        `db:"booking_id"`
+	ServiceName    string     `db:"service_name"`
+	FromDate       time.Time  `db:"from_date"`
+	UsedAt         time.Time  `db:"used_at"`
+	RefundedAt     *time.Time `db:"refunded_at"`
+}
@@ -20,6 +20,7 @@ type ProductRepository interface {
 	GetLowStockProducts(ctx context.Context, merchantId uuid.UUID) ([]LowStockProduct, error)
 }
 
+// The price is the net price if the tax rate of the product is exclusive, the gross price otherwise
 type Product struct {
 	Id            int              `json:"ID"`
 	MerchantId    uuid.UUID        `json:"merchant_id"`
@@ -30,6 +31,7 @@ type Product struct {
 	MaxAmount     int              `json:"max_amount"`
 	CurrentAmount int              `json:"current_amount"`
 	DeletedOn     *string          `json:"deleted_on"`
+	TaxRateId     *int             `json:"tax_rate_id"`
 }
 
 type ProductInfo struct {
@@ -40,6 +42,7 @@ type ProductInfo struct {
 	Unit          string                   `json:"unit" db:"unit"`
 	MaxAmount     int                      `json:"max_amount" db:"max_amount"`
 	CurrentAmount int                      `json:"current_amount" db:"current_amount"`
+	TaxRateId     *int                     `json:"tax_rate_id" db:"tax_rate_id"`
 	Services      []ServiceInfoForProducts `json:"services" db:"services"`
 }
 
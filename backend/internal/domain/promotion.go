@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type PromotionRepository interface {
+	WithTx(tx db.DBTX) PromotionRepository
+
+	// Inserts the promotion together with its services and categories, the ones of other merchants are left out
+	NewPromotion(ctx context.Context, promotion Promotion) (int, error)
+	// Updates the promotion and replaces its services and categories
+	UpdatePromotion(ctx context.Context, promotion Promotion) error
+	GetPromotion(ctx context.Context, merchantId uuid.UUID, promotionId int) (Promotion, error)
+	GetPromotions(ctx context.Context, merchantId uuid.UUID) ([]Promotion, error)
+
+	// Locks the promotion until the transaction ends, so concurrent bookings can not exceed its usage limits
+	GetPromotionByCodeForUpdate(ctx context.Context, merchantId uuid.UUID, code string) (Promotion, error)
+	// Same as GetPromotionByCodeForUpdate for the automatic promotions, which have no code
+	GetPromotionForUpdate(ctx context.Context, merchantId uuid.UUID, promotionId int) (Promotion, error)
+	// The active promotions without a code, they are not locked
+	GetAutomaticPromotions(ctx context.Context, merchantId uuid.UUID) ([]Promotion, error)
+	// How many times the customer used the promotion, cancelled participations are not counted
+	GetCustomerPromotionUses(ctx context.Context, promotionId int, customerId uuid.UUID) (int, error)
+	// Whether the customer has any participation at the merchant which is not cancelled
+	HasCustomerVisited(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (bool, error)
+	// Records the promotion and the discounted price on the participation of the customer
+	SetParticipantPromotion(ctx context.Context, bookingId int, customerId uuid.UUID, promotionId int, pricePerPerson currencyx.Price) error
+}
+
+type Promotion struct {
+	Id         int       `db:"id"`
+	MerchantId uuid.UUID `db:"merchant_id"`
+	Name       string    `db:"name"`
+	// nil for automatic rules
+	Code *string `db:"code"`
+	// exactly one of them is set
+	Percent            *string          `db:"percent"`
+	Amount             *currencyx.Price `db:"amount"`
+	FirstVisitOnly     bool             `db:"first_visit_only"`
+	MaxUses            *int             `db:"max_uses"`
+	MaxUsesPerCustomer *int             `db:"max_uses_per_customer"`
+	ValidFrom          *time.Time       `db:"valid_from"`
+	ValidUntil         *time.Time       `db:"valid_until"`
+	// 0 is sunday, empty if the promotion applies on every day
+	Weekdays []int `db:"weekdays"`
+	// minutes since midnight in the timezone of the merchant, nil if the promotion applies the whole day
+	StartMinute *int      `db:"start_minute"`
+	EndMinute   *int      `db:"end_minute"`
+	IsActive    bool      `db:"is_active"`
+	ServiceIds  []int     `db:"service_ids"`
+	CategoryIds []int     `db:"category_ids"`
+	CreatedAt   time.Time `db:"created_at"`
+	// the participations which used the promotion and are not cancelled
+	Uses int `db:"uses"`
+}
+
+func (p Promotion) IsAutomatic() bool {
+	return p.Code == nil
+}
+
+// A promotion without services and categories applies to every service
+func (p Promotion) AppliesToService(serviceId int, categoryId *int) bool {
+	if len(p.ServiceIds) == 0 && len(p.CategoryIds) == 0 {
+		return true
+	}
+
+	if slices.Contains(p.ServiceIds, serviceId) {
+		return true
+	}
+
+	return categoryId != nil && slices.Contains(p.CategoryIds, *categoryId)
+}
+
+// Whether the slot starting at the given time, in the timezone of the merchant, falls on the days and hours of the promotion
+func (p Promotion) AppliesToSlot(slot time.Time) bool {
+	if len(p.Weekdays) != 0 && !slices.Contains(p.Weekdays, int(slot.Weekday())) {
+		return false
+	}
+
+	if p.StartMinute == nil || p.EndMinute == nil {
+		return true
+	}
+
+	minute := slot.Hour()*60 + slot.Minute()
+
+	return minute >= *p.StartMinute && minute < *p.EndMinute
+}
+
+func (p Promotion) IsValidAt(now time.Time) bool {
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return false
+	}
+
+	return p.ValidUntil == nil || now.Before(*p.ValidUntil)
+}
@@ -0,0 +1,392 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type ReportRepository interface {
+	WithTx(tx db.DBTX) ReportRepository
+
+	// Merchants which had or will have a booking between the two dates, used to refresh their rollups
+	GetMerchantsWithBookings(ctx context.Context, from time.Time, to time.Time) ([]uuid.UUID, error)
+	// Recomputes the daily rollups of the merchant between the two inclusive dates in the timezone of the merchant
+	RefreshBookingRollups(ctx context.Context, merchantId uuid.UUID, startDay time.Time, endDay time.Time) error
+	// The rollups between the two inclusive dates summed up per day and per the group if it is given
+	GetReportRows(ctx context.Context, merchantId uuid.UUID, currency string, startDay time.Time, endDay time.Time, groupBy *types.ReportGroupBy) ([]ReportRow, error)
+	// The tax included in the revenue between the two inclusive dates summed up per tax rate
+	GetReportTaxes(ctx context.Context, merchantId uuid.UUID, currency string, startDay time.Time, endDay time.Time) ([]ReportTaxRow, error)
+	// The time when the oldest rollup between the two dates was computed, nil if there is none
+	GetRollupRefreshedAt(ctx context.Context, merchantId uuid.UUID, startDay time.Time, endDay time.Time) (*time.Time, error)
+
+	// Bookings which are not cancelled and overlap with the given time range
+	GetUtilizationBookings(ctx context.Context, merchantId uuid.UUID, currency string, from time.Time, to time.Time) ([]UtilizationBooking, error)
+	GetEmployeeBlockedTimes(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]EmployeeBlockedTime, error)
+
+	// Every participation of the customers which started before the given time, and the upcoming ones, ordered by their start
+	GetCustomerVisits(ctx context.Context, merchantId uuid.UUID, currency string, to time.Time) ([]CustomerVisit, error)
+
+	// Only the employees of the merchant are added as recipients
+	NewReportSubscription(ctx context.Context, merchantId uuid.UUID, subscription NewReportSubscription) (int, error)
+	UpdateReportSubscription(ctx context.Context, merchantId uuid.UUID, subscriptionId int, subscription UpdateReportSubscription) error
+	DeleteReportSubscription(ctx context.Context, merchantId uuid.UUID, subscriptionId int) error
+	GetReportSubscription(ctx context.Context, subscriptionId int) (ReportSubscription, error)
+	GetReportSubscriptions(ctx context.Context, merchantId uuid.UUID) ([]ReportSubscription, error)
+	// Active subscriptions of every merchant with at least one recipient
+	GetActiveReportSubscriptions(ctx context.Context) ([]ActiveReportSubscription, error)
+	// Also clears the per recipient deliveries of the sent periods
+	SetReportSubscriptionSent(ctx context.Context, subscriptionId int, sentUntil time.Time) error
+	// Employees who already got the report of the period ending on the given day
+	GetReportSubscriptionDeliveries(ctx context.Context, subscriptionId int, periodEnd time.Time) ([]int, error)
+	NewReportSubscriptionDelivery(ctx context.Context, subscriptionId int, employeeId int, periodEnd time.Time) error
+
+	NewReportExport(ctx context.Context, export NewReportExport) (int, error)
+	// The file is not returned, it can be large
+	GetReportExport(ctx context.Context, merchantId uuid.UUID, exportId int) (ReportExport, error)
+	GetReportExports(ctx context.Context, merchantId uuid.UUID) ([]ReportExport, error)
+	GetReportExportFile(ctx context.Context, merchantId uuid.UUID, exportId int) ([]byte, error)
+	UpdateReportExport(ctx context.Context, exportId int, result DataExportResult) error
+	DeleteExpiredReportExports(ctx context.Context, now time.Time) error
+	// Bookings and participants starting between the two times, the ones cancelled by the merchant included
+	GetExportBookings(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]ExportBooking, error)
+	// The email and phone number are left empty unless includePii is set
+	GetExportParticipants(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time, includePii bool) ([]ExportParticipant, error)
+}
+
+type ReportRow struct {
+	Day           time.Time `db:"day"`
+	GroupId       *int      `db:"group_id"`
+	GroupName     *string   `db:"group_name"`
+	Bookings      int       `db:"bookings"`
+	Cancellations int       `db:"cancellations"`
+	NoShows       int       `db:"no_shows"`
+	Participants  int       `db:"participants"`
+	// numeric sum of the prices in the currency of the report
+	Revenue       string `db:"revenue"`
+	BookedMinutes int    `db:"booked_minutes"`
+}
+
+type ReportTaxRow struct {
+	Name    string `db:"name"`
+	Percent string `db:"percent"`
+	// numeric sum of the tax in the currency of the report, not rounded
+	Tax string `db:"tax"`
+}
+
+// Dates of the report are days in the timezone of the merchant, stored as midnight in UTC
+type ReportPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+type ReportMetrics struct {
+	Bookings      int
+	Cancellations int
+	NoShows       int
+	Participants  int
+	Revenue       currency.Amount
+	BookedMinutes int
+}
+
+// Percent changes compared to the previous period
+type ReportChanges struct {
+	Bookings        int
+	Cancellations   int
+	NoShows         int
+	Participants    int
+	Revenue         int
+	AverageDuration int
+}
+
+type ReportBucket struct {
+	Period  ReportPeriod
+	Metrics ReportMetrics
+}
+
+type ReportGroup struct {
+	// nil if the service, category or employee was deleted since
+	Id       *int
+	Name     string
+	Current  ReportMetrics
+	Previous ReportMetrics
+	Changes  ReportChanges
+}
+
+// Tax collected with a rate in the period of the report
+type ReportTax struct {
+	Name    string
+	Percent string
+	Amount  currency.Amount
+}
+
+type Report struct {
+	Period         ReportPeriod
+	PreviousPeriod ReportPeriod
+	Granularity    types.ReportGranularity
+	GroupBy        *types.ReportGroupBy
+	Currency       string
+	Current        ReportMetrics
+	Previous       ReportMetrics
+	Changes        ReportChanges
+	Series         []ReportBucket
+	PreviousSeries []ReportBucket
+	Groups         []ReportGroup
+	Taxes          []ReportTax
+	RefreshedAt    *time.Time
+}
+
+// Average length of the bookings which took place in minutes
+func (m ReportMetrics) AverageDuration() int {
+	attended := m.Bookings - m.NoShows
+	if attended <= 0 {
+		return 0
+	}
+
+	return m.BookedMinutes / attended
+}
+
+type TimeRange struct {
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+type UtilizationBooking struct {
+	Id           int       `db:"id"`
+	EmployeeId   *int      `db:"employee_id"`
+	LocationId   int       `db:"location_id"`
+	LocationName string    `db:"location_name"`
+	FromDate     time.Time `db:"from_date"`
+	// numeric price in the currency of the merchant, 0 for no-shows
+	Revenue string `db:"revenue"`
+	// the phases the employee is working in, the whole booking if it has no phases
+	ActivePhases []TimeRange `db:"active_phases"`
+}
+
+type EmployeeBlockedTime struct {
+	EmployeeId int       `db:"employee_id"`
+	FromDate   time.Time `db:"from_date"`
+	ToDate     time.Time `db:"to_date"`
+	AllDay     bool      `db:"all_day"`
+}
+
+type EmployeeUtilization struct {
+	EmployeeId int
+	Name       string
+	// business hours minus the blocked times of the employee
+	WorkingMinutes int
+	// active phases of the bookings during the working minutes
+	BookedMinutes int
+	// percent of the working minutes which are booked
+	Utilization int
+	// free time between two bookings of the same working hours
+	IdleGaps       int
+	IdleGapMinutes int
+	Revenue        currency.Amount
+	RevenuePerHour currency.Amount
+}
+
+type LocationOccupancy struct {
+	LocationId    int
+	Name          string
+	BookedMinutes int
+	// working minutes of the employees who had bookings at the location
+	CapacityMinutes int
+	Occupancy       int
+}
+
+// Minutes summed up for an hour of the week in the timezone of the merchant, the day of week starts with sunday as 0
+type HeatmapCell struct {
+	DayOfWeek      int
+	Hour           int
+	WorkingMinutes int
+	BookedMinutes  int
+	Occupancy      int
+}
+
+type Utilization struct {
+	Period         ReportPeriod
+	Currency       string
+	WorkingMinutes int
+	BookedMinutes  int
+	Utilization    int
+	IdleGaps       int
+	IdleGapMinutes int
+	Revenue        currency.Amount
+	RevenuePerHour currency.Amount
+	Employees      []EmployeeUtilization
+	Locations      []LocationOccupancy
+	Heatmap        []HeatmapCell
+}
+
+type CustomerVisit struct {
+	CustomerId  uuid.UUID           `db:"customer_id"`
+	FirstName   *string             `db:"first_name"`
+	LastName    *string             `db:"last_name"`
+	ServiceId   *int                `db:"service_id"`
+	ServiceName string              `db:"service_name"`
+	FromDate    time.Time           `db:"from_date"`
+	Status      types.BookingStatus `db:"status"`
+	// numeric price per person in the currency of the merchant, 0 if the visit is not completed
+	Price string `db:"price"`
+}
+
+type RetentionBucket struct {
+	Period             ReportPeriod
+	NewCustomers       int
+	ReturningCustomers int
+}
+
+// Customers grouped by the month of their first completed visit
+type CohortRetention struct {
+	Month     time.Time
+	Customers int
+	// customers of the cohort who visited in the first, second... month from the month of the cohort
+	Retained  []int
+	Retention []int
+}
+
+type RebookingInterval struct {
+	ServiceId   *int
+	ServiceName string
+	Rebookings  int
+	AverageDays int
+}
+
+type ChurnRiskCustomer struct {
+	CustomerId         uuid.UUID
+	Name               string
+	LastVisit          time.Time
+	Visits             int
+	UsualIntervalDays  int
+	DaysSinceLastVisit int
+	LifetimeValue      currency.Amount
+}
+
+type CustomerRetention struct {
+	Period      ReportPeriod
+	Granularity types.ReportGranularity
+	Currency    string
+	// customers with a completed visit in the period, new ones had their first visit in it
+	NewCustomers       int
+	ReturningCustomers int
+	// percent of the participations in the period
+	NoShowRate         int
+	CancellationRate   int
+	Series             []RetentionBucket
+	Cohorts            []CohortRetention
+	RebookingIntervals []RebookingInterval
+	// customers who are overdue compared to their usual interval and have no upcoming booking
+	ChurnRisk []ChurnRiskCustomer
+	// every completed visit of the customers, not only the ones in the period
+	Customers            int
+	AverageVisits        float64
+	AverageLifetimeValue currency.Amount
+}
+
+type ReportSubscriptionRecipient struct {
+	EmployeeId   int                `json:"employee_id"`
+	FirstName    *string            `json:"first_name"`
+	LastName     *string            `json:"last_name"`
+	Email        *string            `json:"email"`
+	UserLanguage *string            `json:"user_language"`
+	Role         types.EmployeeRole `json:"role"`
+	RoleId       *int               `json:"role_id"`
+	// permissions of the custom role, nil if the employee does not have one
+	CustomPermissions []string `json:"custom_permissions"`
+}
+
+// The recipient's current permissions, resolved the same way as for a signed in employee
+func (r ReportSubscriptionRecipient) Permissions() []types.Permission {
+	return EmployeeAuthInfo{Role: r.Role, RoleId: r.RoleId, CustomPermissions: r.CustomPermissions}.Permissions()
+}
+
+type ReportSubscription struct {
+	Id            int                           `db:"id"`
+	MerchantId    uuid.UUID                     `db:"merchant_id"`
+	MerchantName  string                        `db:"merchant_name"`
+	Frequency     types.ReportFrequency         `db:"frequency"`
+	IsActive      bool                          `db:"is_active"`
+	LastSentUntil *time.Time                    `db:"last_sent_until"`
+	CreatedAt     time.Time                     `db:"created_at"`
+	Recipients    []ReportSubscriptionRecipient `db:"recipients"`
+}
+
+type NewReportSubscription struct {
+	Frequency   types.ReportFrequency
+	EmployeeIds []int
+}
+
+type UpdateReportSubscription struct {
+	IsActive    bool
+	EmployeeIds []int
+}
+
+type ActiveReportSubscription struct {
+	Id            int                   `db:"id"`
+	MerchantId    uuid.UUID             `db:"merchant_id"`
+	Frequency     types.ReportFrequency `db:"frequency"`
+	Timezone      string                `db:"timezone"`
+	LastSentUntil *time.Time            `db:"last_sent_until"`
+	CreatedAt     time.Time             `db:"created_at"`
+}
+
+type ReportExport struct {
+	Id         int                       `db:"id"`
+	MerchantId uuid.UUID                 `db:"merchant_id"`
+	EmployeeId *int                      `db:"employee_id"`
+	Dataset    types.ReportExportDataset `db:"dataset"`
+	Format     types.ReportExportFormat  `db:"format"`
+	StartDay   time.Time                 `db:"start_day"`
+	EndDay     time.Time                 `db:"end_day"`
+	IncludePii bool                      `db:"include_pii"`
+	Status     types.DataExportStatus    `db:"status"`
+	Error      *string                   `db:"error"`
+	CreatedAt  time.Time                 `db:"created_at"`
+	FinishedAt *time.Time                `db:"finished_at"`
+	ExpiresAt  *time.Time                `db:"expires_at"`
+}
+
+type NewReportExport struct {
+	MerchantId uuid.UUID
+	EmployeeId int
+	Dataset    types.ReportExportDataset
+	Format     types.ReportExportFormat
+	StartDay   time.Time
+	EndDay     time.Time
+	IncludePii bool
+}
+
+type ExportBooking struct {
+	Id                  int                 `db:"id"`
+	Status              types.BookingStatus `db:"status"`
+	BookingType         types.BookingType   `db:"booking_type"`
+	FromDate            time.Time           `db:"from_date"`
+	ToDate              time.Time           `db:"to_date"`
+	ServiceName         string              `db:"service_name"`
+	EmployeeName        *string             `db:"employee_name"`
+	Location            string              `db:"formatted_location"`
+	Participants        int                 `db:"current_participants"`
+	PricePerPerson      currencyx.Price     `db:"price_per_person"`
+	TotalPrice          currencyx.Price     `db:"total_price"`
+	CancelledByMerchant bool                `db:"cancelled_by_merchant"`
+}
+
+type ExportParticipant struct {
+	BookingId   int                 `db:"booking_id"`
+	FromDate    time.Time           `db:"from_date"`
+	ServiceName string              `db:"service_name"`
+	CustomerId  *uuid.UUID          `db:"customer_id"`
+	FirstName   *string             `db:"first_name"`
+	LastName    *string             `db:"last_name"`
+	Email       *string             `db:"email"`
+	PhoneNumber *string             `db:"phone_number"`
+	Status      types.BookingStatus `db:"status"`
+	Price       currencyx.Price     `db:"price_per_person"`
+	CancelledOn *time.Time          `db:"cancelled_on"`
+}
@@ -12,6 +12,7 @@ type TeamRepository interface {
 	WithTx(tx db.DBTX) TeamRepository
 
 	NewEmployee(ctx context.Context, merchantId uuid.UUID, employee PublicEmployee) error
+	// The owner can not be updated, returns pgx.ErrNoRows for them
 	UpdateEmployee(ctx context.Context, merchantId uuid.UUID, employee PublicEmployee) error
 	DeleteEmployee(ctx context.Context, merchantId uuid.UUID, employeeId int) error
 	GetEmployee(ctx context.Context, merchantId uuid.UUID, employeeId int) (PublicEmployee, error)
@@ -21,15 +22,30 @@ type TeamRepository interface {
 	GetActiveEmployees(ctx context.Context, merchantId uuid.UUID) ([]PublicEmployee, error)
 
 	GetMerchantIdByEmployee(ctx context.Context, employeeId int) (uuid.UUID, error)
+
+	NewRole(ctx context.Context, merchantId uuid.UUID, role Role) (int, error)
+	UpdateRole(ctx context.Context, merchantId uuid.UUID, role Role) error
+	DeleteRole(ctx context.Context, merchantId uuid.UUID, roleId int) error
+	GetRole(ctx context.Context, merchantId uuid.UUID, roleId int) (Role, error)
+	GetRoles(ctx context.Context, merchantId uuid.UUID) ([]Role, error)
 }
 
 type PublicEmployee struct {
 	Id          int                `json:"id" db:"id"`
 	UserId      *uuid.UUID         `db:"user_id"`
 	Role        types.EmployeeRole `json:"role" db:"role"`
+	RoleId      *int               `json:"role_id" db:"role_id"`
 	FirstName   *string            `json:"first_name" db:"first_name"`
 	LastName    *string            `json:"last_name" db:"last_name"`
 	Email       *string            `json:"email" db:"email"`
 	PhoneNumber *string            `json:"phone_number" db:"phone_number"`
 	IsActive    bool               `json:"is_active" db:"is_active"`
 }
+
+// Custom role of a merchant
+type Role struct {
+	Id          int                `db:"id"`
+	MerchantId  uuid.UUID          `db:"merchant_id"`
+	Name        string             `db:"name"`
+	Permissions []types.Permission `db:"permissions"`
+}
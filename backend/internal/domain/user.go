@@ -2,9 +2,11 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 	"golang.org/x/text/language"
 )
@@ -32,6 +34,29 @@ type UserRepository interface {
 	IncrementUserJwtRefreshVersion(ctx context.Context, userId uuid.UUID) (int, error)
 
 	FindOauthUser(ctx context.Context, authProviderType types.AuthProviderType, providerId string) (uuid.UUID, error)
+
+	NewSession(ctx context.Context, session Session) error
+	GetSession(ctx context.Context, sessionId uuid.UUID) (Session, error)
+	GetActiveSessions(ctx context.Context, userId uuid.UUID) ([]Session, error)
+	// Replace the session's refresh token hash if oldHash is still the current one.
+	// Returns false if the session was rotated concurrently or is no longer active.
+	RotateSession(ctx context.Context, sessionId uuid.UUID, oldHash string, newHash string, ipAddress string, userAgent string, expiresAt time.Time) (bool, error)
+	TouchSession(ctx context.Context, sessionId uuid.UUID, ipAddress string, userAgent string) error
+	RevokeSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userId uuid.UUID) error
+	DeleteExpiredSessions(ctx context.Context, before time.Time) error
+
+	// Customer records of the User at every merchant, custom fields are keyed by the field's name
+	GetUserDataCustomers(ctx context.Context, userId uuid.UUID) ([]UserDataCustomer, error)
+	GetUserDataBookings(ctx context.Context, userId uuid.UUID) ([]UserDataBooking, error)
+
+	NewDataExport(ctx context.Context, userId uuid.UUID) (int, error)
+	// The archive is not returned, it can be large
+	GetDataExport(ctx context.Context, userId uuid.UUID, exportId int) (DataExport, error)
+	GetDataExports(ctx context.Context, userId uuid.UUID) ([]DataExport, error)
+	GetDataExportFile(ctx context.Context, userId uuid.UUID, exportId int) ([]byte, error)
+	UpdateDataExport(ctx context.Context, exportId int, result DataExportResult) error
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) error
 }
 
 type User struct {
@@ -64,4 +89,106 @@ type EmployeeAuthInfo struct {
 	LocationId int                `db:"location_id"`
 	MerchantId uuid.UUID          `db:"merchant_id"`
 	Role       types.EmployeeRole `db:"role"`
+	RoleId     *int               `db:"role_id"`
+	// permissions of the custom role, nil if the employee does not have one
+	CustomPermissions []string `db:"custom_permissions"`
+}
+
+// The owner always has every permission, other employees get their custom role's
+// permissions if they have one, otherwise the defaults of their built in role
+func (e EmployeeAuthInfo) Permissions() []types.Permission {
+	if e.Role == types.EmployeeRoleOwner || e.RoleId == nil {
+		return e.Role.DefaultPermissions()
+	}
+
+	permissions := []types.Permission{}
+	for _, p := range e.CustomPermissions {
+		permission, err := types.NewPermission(p)
+		if err != nil || !permission.IsAssignable() {
+			continue
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions
+}
+
+type Session struct {
+	Id                uuid.UUID  `db:"id"`
+	UserId            uuid.UUID  `db:"user_id"`
+	RefreshTokenHash  string     `db:"refresh_token_hash"`
+	PreviousTokenHash *string    `db:"previous_token_hash"`
+	DeviceName        string     `db:"device_name"`
+	IpAddress         string     `db:"ip_address"`
+	UserAgent         string     `db:"user_agent"`
+	CreatedAt         time.Time  `db:"created_at"`
+	LastSeenAt        time.Time  `db:"last_seen_at"`
+	RotatedAt         time.Time  `db:"rotated_at"`
+	ExpiresAt         time.Time  `db:"expires_at"`
+	RevokedOn         *time.Time `db:"revoked_on"`
+}
+
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedOn == nil && now.Before(s.ExpiresAt)
+}
+
+type UserDataCustomer struct {
+	Id              uuid.UUID      `db:"id"`
+	MerchantName    string         `db:"merchant_name"`
+	FirstName       *string        `db:"first_name"`
+	LastName        *string        `db:"last_name"`
+	Email           *string        `db:"email"`
+	PhoneNumber     *string        `db:"phone_number"`
+	Birthday        *time.Time     `db:"birthday"`
+	Note            *string        `db:"note"`
+	IsBlacklisted   bool           `db:"is_blacklisted"`
+	BlacklistReason *string        `db:"blacklist_reason"`
+	Tags            []string       `db:"tags"`
+	CustomFields    map[string]any `db:"custom_fields"`
+}
+
+// The User's participation in a booking
+type UserDataBooking struct {
+	BookingId          int                      `db:"booking_id"`
+	CustomerId         uuid.UUID                `db:"customer_id"`
+	MerchantName       string                   `db:"merchant_name"`
+	ServiceName        string                   `db:"service_name"`
+	BookingType        types.BookingType        `db:"booking_type"`
+	BookingStatus      types.BookingStatus      `db:"booking_status"`
+	Status             types.BookingStatus      `db:"status"`
+	FromDate           time.Time                `db:"from_date"`
+	ToDate             time.Time                `db:"to_date"`
+	PricePerPerson     currencyx.Price          `db:"price_per_person"`
+	FormattedLocation  string                   `db:"formatted_location"`
+	CustomerNote       *string                  `db:"customer_note"`
+	CancelledOn        *time.Time               `db:"cancelled_on"`
+	CancellationReason *string                  `db:"cancellation_reason"`
+	IntakeResponses    []UserDataIntakeResponse `db:"intake_responses"`
+}
+
+// Answers of the user to the intake form of a booking
+type UserDataIntakeResponse struct {
+	FormName  string           `json:"form_name"`
+	Questions []IntakeQuestion `json:"questions"`
+	Answers   IntakeAnswers    `json:"answers"`
+	Signature *string          `json:"signature"`
+	SignedAt  *time.Time       `json:"signed_at"`
+}
+
+type DataExport struct {
+	Id         int                    `db:"id"`
+	UserId     uuid.UUID              `db:"user_id"`
+	Status     types.DataExportStatus `db:"status"`
+	Error      *string                `db:"error"`
+	CreatedAt  time.Time              `db:"created_at"`
+	FinishedAt *time.Time             `db:"finished_at"`
+	ExpiresAt  *time.Time             `db:"expires_at"`
+}
+
+type DataExportResult struct {
+	Status    types.DataExportStatus
+	File      []byte
+	Error     *string
+	ExpiresAt *time.Time
 }
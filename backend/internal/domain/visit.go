@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type VisitRepository interface {
+	WithTx(tx db.DBTX) VisitRepository
+
+	// Participations of the customer in bookings which already started, the latest first
+	GetCustomerVisits(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]Visit, error)
+	GetVisit(ctx context.Context, merchantId uuid.UUID, participantId int) (Visit, error)
+
+	NewVisitNote(ctx context.Context, note VisitNote) (int, error)
+	UpdateVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int, note string) error
+	DeleteVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int) error
+	GetVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int) (VisitNote, error)
+	GetVisitNotes(ctx context.Context, merchantId uuid.UUID, participantIds []int) ([]VisitNote, error)
+
+	NewVisitAttachment(ctx context.Context, attachment VisitAttachment) (int, error)
+	GetVisitAttachment(ctx context.Context, merchantId uuid.UUID, attachmentId int) (VisitAttachment, error)
+	GetVisitAttachments(ctx context.Context, merchantId uuid.UUID, participantIds []int) ([]VisitAttachment, error)
+	CountVisitAttachments(ctx context.Context, merchantId uuid.UUID, participantId int) (int, error)
+	// Marks the attachment as deleted, its blob is removed later by the cleanup job
+	DeleteVisitAttachment(ctx context.Context, merchantId uuid.UUID, attachmentId int) error
+	// Attachments which were deleted or lost their participant
+	GetDeletedVisitAttachments(ctx context.Context, limit int) ([]VisitAttachment, error)
+	PurgeVisitAttachments(ctx context.Context, attachmentIds []int) error
+}
+
+// Stores the content of uploaded files
+type BlobStore interface {
+	// The size has to match the length of the content
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+type Visit struct {
+	ParticipantId int                 `db:"participant_id"`
+	BookingId     int                 `db:"booking_id"`
+	CustomerId    uuid.UUID           `db:"customer_id"`
+	EmployeeId    *int                `db:"employee_id"`
+	ServiceName   string              `db:"service_name"`
+	Status        types.BookingStatus `db:"status"`
+	FromDate      time.Time           `db:"from_date"`
+	ToDate        time.Time           `db:"to_date"`
+}
+
+type VisitNote struct {
+	Id            int       `db:"id"`
+	MerchantId    uuid.UUID `db:"merchant_id"`
+	ParticipantId int       `db:"participant_id"`
+	EmployeeId    *int      `db:"employee_id"`
+	Note          string    `db:"note"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+type VisitAttachment struct {
+	Id            int       `db:"id"`
+	MerchantId    uuid.UUID `db:"merchant_id"`
+	ParticipantId *int      `db:"participant_id"`
+	EmployeeId    *int      `db:"employee_id"`
+	FileName      string    `db:"file_name"`
+	ContentType   string    `db:"content_type"`
+	Size          int64     `db:"size"`
+	StorageKey    string    `db:"storage_key"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// A visit with the notes and attachments the employee can see
+type VisitRecord struct {
+	Visit
+	Notes       []VisitNote
+	Attachments []VisitAttachment
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type WebhookRepository interface {
+	WithTx(tx db.DBTX) WebhookRepository
+
+	NewWebhookEndpoint(ctx context.Context, endpoint WebhookEndpoint) (int, error)
+	UpdateWebhookEndpoint(ctx context.Context, endpoint WebhookEndpoint) error
+	DeleteWebhookEndpoint(ctx context.Context, merchantId uuid.UUID, endpointId int) error
+	GetWebhookEndpoint(ctx context.Context, merchantId uuid.UUID, endpointId int) (WebhookEndpoint, error)
+	GetWebhookEndpointById(ctx context.Context, endpointId int) (WebhookEndpoint, error)
+	GetWebhookEndpoints(ctx context.Context, merchantId uuid.UUID) ([]WebhookEndpoint, error)
+	// returns the active endpoints of the merchant which are subscribed to the event
+	GetSubscribedWebhookEndpoints(ctx context.Context, merchantId uuid.UUID, eventType types.WebhookEventType) ([]WebhookEndpoint, error)
+
+	NewWebhookDelivery(ctx context.Context, delivery WebhookDelivery) (int64, error)
+	GetWebhookDelivery(ctx context.Context, deliveryId int64) (WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, endpointId int, limit int, cursorId *int64) ([]WebhookDelivery, error)
+	UpdateWebhookDeliveryAttempt(ctx context.Context, deliveryId int64, attempt WebhookDeliveryAttempt) error
+}
+
+type WebhookEndpoint struct {
+	Id          int                      `db:"id"`
+	MerchantId  uuid.UUID                `db:"merchant_id"`
+	Url         string                   `db:"url"`
+	Description *string                  `db:"description"`
+	Secret      string                   `db:"secret"`
+	Events      []types.WebhookEventType `db:"events"`
+	IsActive    bool                     `db:"is_active"`
+	CreatedAt   time.Time                `db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	Id                int64                       `db:"id"`
+	WebhookEndpointId int                         `db:"webhook_endpoint_id"`
+	EventId           uuid.UUID                   `db:"event_id"`
+	EventType         types.WebhookEventType      `db:"event_type"`
+	Payload           json.RawMessage             `db:"payload"`
+	Status            types.WebhookDeliveryStatus `db:"status"`
+	Attempts          int                         `db:"attempts"`
+	ResponseStatus    *int                        `db:"response_status"`
+	Error             *string                     `db:"error"`
+	CreatedAt         time.Time                   `db:"created_at"`
+	LastAttemptAt     *time.Time                  `db:"last_attempt_at"`
+	DeliveredAt       *time.Time                  `db:"delivered_at"`
+}
+
+// Outcome of a single delivery attempt
+type WebhookDeliveryAttempt struct {
+	Status         types.WebhookDeliveryStatus
+	ResponseStatus *int
+	Error          *string
+	AttemptedAt    time.Time
+}
@@ -0,0 +1,37 @@
+package args
+
+import (
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+type ImportCustomers struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	ImportId   int       `json:"import_id"`
+}
+
+func (ImportCustomers) Kind() string { return "import_customers" }
+
+func (ImportCustomers) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+type SendCampaign struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	CampaignId int       `json:"campaign_id"`
+}
+
+func (SendCampaign) Kind() string { return "send_campaign" }
+
+func (SendCampaign) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "email",
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
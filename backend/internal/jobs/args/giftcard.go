@@ -0,0 +1,20 @@
+package args
+
+import (
+	"github.com/riverqueue/river"
+)
+
+type GiftCardEmail struct {
+	GiftCardId int `json:"gift_card_id"`
+}
+
+func (GiftCardEmail) Kind() string { return "gift_card_email" }
+
+func (GiftCardEmail) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "email",
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
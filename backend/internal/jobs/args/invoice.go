@@ -0,0 +1,27 @@
+package args
+
+import (
+	"github.com/riverqueue/river"
+)
+
+// Not unique, the participants of a booking can be completed one by one
+type IssueInvoices struct {
+	BookingId int `json:"booking_id"`
+}
+
+func (IssueInvoices) Kind() string { return "issue_invoices" }
+
+type InvoiceEmail struct {
+	InvoiceId int `json:"invoice_id"`
+}
+
+func (InvoiceEmail) Kind() string { return "invoice_email" }
+
+func (InvoiceEmail) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "email",
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
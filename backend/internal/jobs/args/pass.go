@@ -0,0 +1,19 @@
+package args
+
+import (
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+type ExpirePasses struct{}
+
+func (ExpirePasses) Kind() string { return "expire_passes" }
+
+func (ExpirePasses) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour,
+		},
+	}
+}
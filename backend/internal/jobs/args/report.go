@@ -0,0 +1,107 @@
+package args
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+// Schedules a rollup refresh for every merchant with bookings in the window around the current day
+type ScheduleBookingRollups struct {
+	PastDays   int `json:"past_days"`
+	FutureDays int `json:"future_days"`
+}
+
+func (ScheduleBookingRollups) Kind() string { return "schedule_booking_rollups" }
+
+func (ScheduleBookingRollups) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Hour,
+		},
+	}
+}
+
+type RefreshBookingRollups struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	PastDays   int       `json:"past_days"`
+	FutureDays int       `json:"future_days"`
+}
+
+func (RefreshBookingRollups) Kind() string { return "refresh_booking_rollups" }
+
+func (RefreshBookingRollups) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByArgs:   true,
+			ByPeriod: time.Hour,
+		},
+	}
+}
+
+// Recomputes the rollups of the day of a booking after it changed
+type RefreshBookingDayRollups struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	Date       time.Time `json:"date"`
+}
+
+func (RefreshBookingDayRollups) Kind() string { return "refresh_booking_day_rollups" }
+
+// Schedules the emails of the report subscriptions whose period ended
+type ScheduleReportSubscriptions struct{}
+
+func (ScheduleReportSubscriptions) Kind() string { return "schedule_report_subscriptions" }
+
+func (ScheduleReportSubscriptions) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour,
+		},
+	}
+}
+
+type SendReportSubscription struct {
+	SubscriptionId int       `json:"subscription_id"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+}
+
+func (SendReportSubscription) Kind() string { return "send_report_subscription" }
+
+func (SendReportSubscription) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "email",
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+type ReportExport struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	ExportId   int       `json:"export_id"`
+}
+
+func (ReportExport) Kind() string { return "report_export" }
+
+func (ReportExport) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+type ReportExportCleanup struct{}
+
+func (ReportExportCleanup) Kind() string { return "report_export_cleanup" }
+
+func (ReportExportCleanup) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour * 24,
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package args
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+type SessionCleanup struct{}
+
+func (SessionCleanup) Kind() string { return "session_cleanup" }
+
+func (SessionCleanup) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour * 24,
+		},
+	}
+}
+
+type UserDataExport struct {
+	UserId   uuid.UUID `json:"user_id"`
+	ExportId int       `json:"export_id"`
+}
+
+func (UserDataExport) Kind() string { return "user_data_export" }
+
+func (UserDataExport) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+		},
+	}
+}
+
+type DataExportCleanup struct{}
+
+func (DataExportCleanup) Kind() string { return "data_export_cleanup" }
+
+func (DataExportCleanup) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour * 24,
+		},
+	}
+}
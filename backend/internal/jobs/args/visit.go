@@ -0,0 +1,19 @@
+package args
+
+import (
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+type VisitAttachmentCleanup struct{}
+
+func (VisitAttachmentCleanup) Kind() string { return "visit_attachment_cleanup" }
+
+func (VisitAttachmentCleanup) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		UniqueOpts: river.UniqueOpts{
+			ByPeriod: time.Hour * 24,
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package args
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+// Creates a delivery for every webhook endpoint of the merchant which is subscribed to the event
+type DispatchWebhookEvent struct {
+	MerchantId uuid.UUID       `json:"merchant_id"`
+	EventId    uuid.UUID       `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func (DispatchWebhookEvent) Kind() string { return "dispatch_webhook_event" }
+
+func (DispatchWebhookEvent) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "webhook",
+	}
+}
+
+type DeliverWebhook struct {
+	DeliveryId int64 `json:"delivery_id"`
+}
+
+func (DeliverWebhook) Kind() string { return "deliver_webhook" }
+
+func (DeliverWebhook) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       "webhook",
+		MaxAttempts: 10,
+	}
+}
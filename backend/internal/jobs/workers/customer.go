@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/customer"
+	"github.com/riverqueue/river"
+)
+
+type ImportCustomers struct {
+	river.WorkerDefaults[args.ImportCustomers]
+
+	customerService *customer.Service
+}
+
+func NewImportCustomers(customerService *customer.Service) *ImportCustomers {
+	return &ImportCustomers{customerService: customerService}
+}
+
+func (w *ImportCustomers) Work(ctx context.Context, job *river.Job[args.ImportCustomers]) error {
+	return w.customerService.RunImport(ctx, job.Args.MerchantId, job.Args.ImportId)
+}
+
+func (w *ImportCustomers) Timeout(job *river.Job[args.ImportCustomers]) time.Duration {
+	return 10 * time.Minute
+}
+
+type SendCampaign struct {
+	river.WorkerDefaults[args.SendCampaign]
+
+	customerService *customer.Service
+}
+
+func NewSendCampaign(customerService *customer.Service) *SendCampaign {
+	return &SendCampaign{customerService: customerService}
+}
+
+// Sends one batch of the campaign and snoozes until the next one is allowed
+func (w *SendCampaign) Work(ctx context.Context, job *river.Job[args.SendCampaign]) error {
+	wait, err := w.customerService.SendCampaignBatch(ctx, job.Args.MerchantId, job.Args.CampaignId)
+	if err != nil {
+		return err
+	}
+
+	if wait > 0 {
+		return river.JobSnooze(wait)
+	}
+
+	return nil
+}
+
+func (w *SendCampaign) Timeout(job *river.Job[args.SendCampaign]) time.Duration {
+	return 5 * time.Minute
+}
@@ -0,0 +1,23 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"github.com/riverqueue/river"
+)
+
+type GiftCardEmail struct {
+	river.WorkerDefaults[args.GiftCardEmail]
+
+	giftCardService *giftcard.Service
+}
+
+func NewGiftCardEmail(giftCardService *giftcard.Service) *GiftCardEmail {
+	return &GiftCardEmail{giftCardService: giftCardService}
+}
+
+func (w *GiftCardEmail) Work(ctx context.Context, job *river.Job[args.GiftCardEmail]) error {
+	return w.giftCardService.SendGiftCard(ctx, job.Args.GiftCardId)
+}
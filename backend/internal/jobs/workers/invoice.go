@@ -0,0 +1,37 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/invoice"
+	"github.com/riverqueue/river"
+)
+
+type IssueInvoices struct {
+	river.WorkerDefaults[args.IssueInvoices]
+
+	invoiceService *invoice.Service
+}
+
+func NewIssueInvoices(invoiceService *invoice.Service) *IssueInvoices {
+	return &IssueInvoices{invoiceService: invoiceService}
+}
+
+func (w *IssueInvoices) Work(ctx context.Context, job *river.Job[args.IssueInvoices]) error {
+	return w.invoiceService.IssueForBooking(ctx, job.Args.BookingId)
+}
+
+type InvoiceEmail struct {
+	river.WorkerDefaults[args.InvoiceEmail]
+
+	invoiceService *invoice.Service
+}
+
+func NewInvoiceEmail(invoiceService *invoice.Service) *InvoiceEmail {
+	return &InvoiceEmail{invoiceService: invoiceService}
+}
+
+func (w *InvoiceEmail) Work(ctx context.Context, job *river.Job[args.InvoiceEmail]) error {
+	return w.invoiceService.SendInvoice(ctx, job.Args.InvoiceId)
+}
@@ -0,0 +1,23 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/pass"
+	"github.com/riverqueue/river"
+)
+
+type ExpirePasses struct {
+	river.WorkerDefaults[args.ExpirePasses]
+
+	passService *pass.Service
+}
+
+func NewExpirePasses(passService *pass.Service) *ExpirePasses {
+	return &ExpirePasses{passService: passService}
+}
+
+func (w *ExpirePasses) Work(ctx context.Context, job *river.Job[args.ExpirePasses]) error {
+	return w.passService.ExpirePasses(ctx)
+}
@@ -0,0 +1,116 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/report"
+	"github.com/riverqueue/river"
+)
+
+type ScheduleBookingRollups struct {
+	river.WorkerDefaults[args.ScheduleBookingRollups]
+
+	reportService *report.Service
+}
+
+func NewScheduleBookingRollups(reportService *report.Service) *ScheduleBookingRollups {
+	return &ScheduleBookingRollups{reportService: reportService}
+}
+
+func (w *ScheduleBookingRollups) Work(ctx context.Context, job *river.Job[args.ScheduleBookingRollups]) error {
+	return w.reportService.ScheduleRollupRefresh(ctx, job.Args.PastDays, job.Args.FutureDays)
+}
+
+type RefreshBookingRollups struct {
+	river.WorkerDefaults[args.RefreshBookingRollups]
+
+	reportService *report.Service
+}
+
+func NewRefreshBookingRollups(reportService *report.Service) *RefreshBookingRollups {
+	return &RefreshBookingRollups{reportService: reportService}
+}
+
+func (w *RefreshBookingRollups) Work(ctx context.Context, job *river.Job[args.RefreshBookingRollups]) error {
+	return w.reportService.RefreshRollups(ctx, job.Args.MerchantId, job.Args.PastDays, job.Args.FutureDays)
+}
+
+func (w *RefreshBookingRollups) Timeout(job *river.Job[args.RefreshBookingRollups]) time.Duration {
+	return 5 * time.Minute
+}
+
+type RefreshBookingDayRollups struct {
+	river.WorkerDefaults[args.RefreshBookingDayRollups]
+
+	reportService *report.Service
+}
+
+func NewRefreshBookingDayRollups(reportService *report.Service) *RefreshBookingDayRollups {
+	return &RefreshBookingDayRollups{reportService: reportService}
+}
+
+func (w *RefreshBookingDayRollups) Work(ctx context.Context, job *river.Job[args.RefreshBookingDayRollups]) error {
+	return w.reportService.RefreshDayRollups(ctx, job.Args.MerchantId, job.Args.Date)
+}
+
+type ScheduleReportSubscriptions struct {
+	river.WorkerDefaults[args.ScheduleReportSubscriptions]
+
+	reportService *report.Service
+}
+
+func NewScheduleReportSubscriptions(reportService *report.Service) *ScheduleReportSubscriptions {
+	return &ScheduleReportSubscriptions{reportService: reportService}
+}
+
+func (w *ScheduleReportSubscriptions) Work(ctx context.Context, job *river.Job[args.ScheduleReportSubscriptions]) error {
+	return w.reportService.ScheduleSubscriptionReports(ctx)
+}
+
+type SendReportSubscription struct {
+	river.WorkerDefaults[args.SendReportSubscription]
+
+	reportService *report.Service
+}
+
+func NewSendReportSubscription(reportService *report.Service) *SendReportSubscription {
+	return &SendReportSubscription{reportService: reportService}
+}
+
+func (w *SendReportSubscription) Work(ctx context.Context, job *river.Job[args.SendReportSubscription]) error {
+	return w.reportService.SendSubscriptionReport(ctx, job.Args.SubscriptionId, job.Args.Start, job.Args.End)
+}
+
+type ReportExport struct {
+	river.WorkerDefaults[args.ReportExport]
+
+	reportService *report.Service
+}
+
+func NewReportExport(reportService *report.Service) *ReportExport {
+	return &ReportExport{reportService: reportService}
+}
+
+func (w *ReportExport) Work(ctx context.Context, job *river.Job[args.ReportExport]) error {
+	return w.reportService.RunExport(ctx, job.Args.MerchantId, job.Args.ExportId)
+}
+
+func (w *ReportExport) Timeout(job *river.Job[args.ReportExport]) time.Duration {
+	return 5 * time.Minute
+}
+
+type ReportExportCleanup struct {
+	river.WorkerDefaults[args.ReportExportCleanup]
+
+	reportService *report.Service
+}
+
+func NewReportExportCleanup(reportService *report.Service) *ReportExportCleanup {
+	return &ReportExportCleanup{reportService: reportService}
+}
+
+func (w *ReportExportCleanup) Work(ctx context.Context, job *river.Job[args.ReportExportCleanup]) error {
+	return w.reportService.DeleteExpiredExports(ctx)
+}
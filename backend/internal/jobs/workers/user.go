@@ -0,0 +1,58 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/user"
+	"github.com/riverqueue/river"
+)
+
+type SessionCleanup struct {
+	river.WorkerDefaults[args.SessionCleanup]
+
+	userRepo domain.UserRepository
+}
+
+func NewSessionCleanup(userRepo domain.UserRepository) *SessionCleanup {
+	return &SessionCleanup{userRepo: userRepo}
+}
+
+// Revoked and expired sessions are kept for a week so they can still be inspected
+func (w *SessionCleanup) Work(ctx context.Context, job *river.Job[args.SessionCleanup]) error {
+	return w.userRepo.DeleteExpiredSessions(ctx, time.Now().UTC().AddDate(0, 0, -7))
+}
+
+type UserDataExport struct {
+	river.WorkerDefaults[args.UserDataExport]
+
+	userService *user.Service
+}
+
+func NewUserDataExport(userService *user.Service) *UserDataExport {
+	return &UserDataExport{userService: userService}
+}
+
+func (w *UserDataExport) Work(ctx context.Context, job *river.Job[args.UserDataExport]) error {
+	return w.userService.RunDataExport(ctx, job.Args.UserId, job.Args.ExportId)
+}
+
+func (w *UserDataExport) Timeout(job *river.Job[args.UserDataExport]) time.Duration {
+	return 5 * time.Minute
+}
+
+type DataExportCleanup struct {
+	river.WorkerDefaults[args.DataExportCleanup]
+
+	userRepo domain.UserRepository
+}
+
+func NewDataExportCleanup(userRepo domain.UserRepository) *DataExportCleanup {
+	return &DataExportCleanup{userRepo: userRepo}
+}
+
+func (w *DataExportCleanup) Work(ctx context.Context, job *river.Job[args.DataExportCleanup]) error {
+	return w.userRepo.DeleteExpiredDataExports(ctx, time.Now().UTC())
+}
@@ -0,0 +1,28 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/visit"
+	"github.com/riverqueue/river"
+)
+
+type VisitAttachmentCleanup struct {
+	river.WorkerDefaults[args.VisitAttachmentCleanup]
+
+	visitService *visit.Service
+}
+
+func NewVisitAttachmentCleanup(visitService *visit.Service) *VisitAttachmentCleanup {
+	return &VisitAttachmentCleanup{visitService: visitService}
+}
+
+func (w *VisitAttachmentCleanup) Work(ctx context.Context, job *river.Job[args.VisitAttachmentCleanup]) error {
+	return w.visitService.CleanupAttachments(ctx)
+}
+
+func (w *VisitAttachmentCleanup) Timeout(job *river.Job[args.VisitAttachmentCleanup]) time.Duration {
+	return 10 * time.Minute
+}
@@ -0,0 +1,46 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+	"github.com/riverqueue/river"
+)
+
+type DispatchWebhookEvent struct {
+	river.WorkerDefaults[args.DispatchWebhookEvent]
+
+	webhookService *webhook.Service
+}
+
+func NewDispatchWebhookEvent(webhookService *webhook.Service) *DispatchWebhookEvent {
+	return &DispatchWebhookEvent{webhookService: webhookService}
+}
+
+func (w *DispatchWebhookEvent) Work(ctx context.Context, job *river.Job[args.DispatchWebhookEvent]) error {
+	return w.webhookService.Dispatch(ctx, job.Args)
+}
+
+type DeliverWebhook struct {
+	river.WorkerDefaults[args.DeliverWebhook]
+
+	webhookService *webhook.Service
+}
+
+func NewDeliverWebhook(webhookService *webhook.Service) *DeliverWebhook {
+	return &DeliverWebhook{webhookService: webhookService}
+}
+
+func (w *DeliverWebhook) Work(ctx context.Context, job *river.Job[args.DeliverWebhook]) error {
+	return w.webhookService.Deliver(ctx, job.Args.DeliveryId, job.Attempt >= job.MaxAttempts)
+}
+
+func (w *DeliverWebhook) NextRetry(job *river.Job[args.DeliverWebhook]) time.Time {
+	return time.Now().Add(webhook.RetryDelay(job.Attempt))
+}
+
+func (w *DeliverWebhook) Timeout(job *river.Job[args.DeliverWebhook]) time.Duration {
+	return 30 * time.Second
+}
@@ -7,16 +7,32 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/schedule"
 	"github.com/miketsu-inc/reservations/backend/internal/service/booking"
+	"github.com/miketsu-inc/reservations/backend/internal/service/customer"
 	"github.com/miketsu-inc/reservations/backend/internal/service/email"
 	"github.com/miketsu-inc/reservations/backend/internal/service/externalcalendar"
+	"github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"github.com/miketsu-inc/reservations/backend/internal/service/invoice"
+	"github.com/miketsu-inc/reservations/backend/internal/service/pass"
+	"github.com/miketsu-inc/reservations/backend/internal/service/report"
+	"github.com/miketsu-inc/reservations/backend/internal/service/user"
+	"github.com/miketsu-inc/reservations/backend/internal/service/visit"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 	"github.com/riverqueue/river"
 )
 
 type Deps struct {
 	BookingService     *booking.Service
+	CustomerService    *customer.Service
 	EmailService       *email.Service
 	ExtCalendarService *externalcalendar.Service
+	GiftCardService    *giftcard.Service
+	InvoiceService     *invoice.Service
+	PassService        *pass.Service
+	ReportService      *report.Service
+	UserService        *user.Service
+	VisitService       *visit.Service
+	WebhookService     *webhook.Service
 	BookingRepo        domain.BookingRepository
 	CatalogRepo        domain.CatalogRepository
 	UserRepo           domain.UserRepository
@@ -31,6 +47,8 @@ func RegisterWorkers(workers *river.Workers, deps Deps) {
 	river.AddWorker(workers, NewBookingCancellationEmail(deps.EmailService, deps.BookingRepo))
 	river.AddWorker(workers, NewBookingModificationEmail(deps.EmailService, deps.BookingRepo))
 	river.AddWorker(workers, NewForgotPasswordEmail(deps.EmailService, deps.UserRepo))
+	river.AddWorker(workers, NewInvoiceEmail(deps.InvoiceService))
+	river.AddWorker(workers, NewGiftCardEmail(deps.GiftCardService))
 
 	river.AddWorker(workers, NewIncrementalCalendarSync(deps.ExtCalendarService, deps.ExtCalendarRepo))
 	river.AddWorker(workers, NewSyncNewBooking(deps.ExtCalendarService))
@@ -47,6 +65,28 @@ func RegisterWorkers(workers *river.Workers, deps Deps) {
 	river.AddWorker(workers, NewRecurringBookingScheduler(deps.BookingRepo))
 	river.AddWorker(workers, NewBookingOccurrenceGenerator(deps.BookingService, deps.BookingRepo))
 	river.AddWorker(workers, NewUpdateFutureBookingOccurrences(deps.BookingService, deps.BookingRepo, deps.CatalogRepo))
+	river.AddWorker(workers, NewIssueInvoices(deps.InvoiceService))
+	river.AddWorker(workers, NewExpirePasses(deps.PassService))
+
+	river.AddWorker(workers, NewSessionCleanup(deps.UserRepo))
+	river.AddWorker(workers, NewUserDataExport(deps.UserService))
+	river.AddWorker(workers, NewDataExportCleanup(deps.UserRepo))
+
+	river.AddWorker(workers, NewDispatchWebhookEvent(deps.WebhookService))
+	river.AddWorker(workers, NewDeliverWebhook(deps.WebhookService))
+
+	river.AddWorker(workers, NewImportCustomers(deps.CustomerService))
+	river.AddWorker(workers, NewSendCampaign(deps.CustomerService))
+
+	river.AddWorker(workers, NewVisitAttachmentCleanup(deps.VisitService))
+
+	river.AddWorker(workers, NewScheduleBookingRollups(deps.ReportService))
+	river.AddWorker(workers, NewRefreshBookingRollups(deps.ReportService))
+	river.AddWorker(workers, NewRefreshBookingDayRollups(deps.ReportService))
+	river.AddWorker(workers, NewScheduleReportSubscriptions(deps.ReportService))
+	river.AddWorker(workers, NewSendReportSubscription(deps.ReportService))
+	river.AddWorker(workers, NewReportExport(deps.ReportService))
+	river.AddWorker(workers, NewReportExportCleanup(deps.ReportService))
 }
 
 func GetPeriodicJobs() []*river.PeriodicJob {
@@ -61,5 +101,49 @@ func GetPeriodicJobs() []*river.PeriodicJob {
 				return args.RecurringBookingScheduler{}, nil
 			}, &river.PeriodicJobOpts{RunOnStart: true},
 		),
+		river.NewPeriodicJob(schedule.NewDailyMidnight(time.UTC),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.SessionCleanup{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(schedule.NewDailyMidnight(time.UTC),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.DataExportCleanup{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(schedule.NewDailyMidnight(time.UTC),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.VisitAttachmentCleanup{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		// the recent days change the most, they are kept up to date through the day
+		river.NewPeriodicJob(river.PeriodicInterval(time.Hour),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.ScheduleBookingRollups{PastDays: 7, FutureDays: 60}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		// every day which can be in a report or its comparison is refreshed nightly, this also backfills new rollups
+		river.NewPeriodicJob(schedule.NewDailyMidnight(time.UTC),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.ScheduleBookingRollups{PastDays: 4 * 366, FutureDays: 366}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		// the merchants are in different timezones, so the subscriptions are checked every hour
+		river.NewPeriodicJob(river.PeriodicInterval(time.Hour),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.ScheduleReportSubscriptions{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		river.NewPeriodicJob(schedule.NewDailyMidnight(time.UTC),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.ReportExportCleanup{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
+		// passes expire at the time they were bought, not at midnight
+		river.NewPeriodicJob(river.PeriodicInterval(time.Hour),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return args.ExpirePasses{}, nil
+			}, &river.PeriodicJobOpts{RunOnStart: true},
+		),
 	}
 }
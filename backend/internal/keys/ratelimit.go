@@ -0,0 +1,14 @@
+package keys
+
+import (
+	"fmt"
+)
+
+type RateLimit struct {
+	Policy  string
+	Subject string
+}
+
+func (k RateLimit) String() string {
+	return fmt.Sprintf("rate_limit:%s:%s", k.Policy, k.Subject)
+}
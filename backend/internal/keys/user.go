@@ -11,3 +11,24 @@ type PasswordReset struct {
 func (k PasswordReset) String() string {
 	return fmt.Sprintf("password_reset:%s", k.Token)
 }
+
+// Failed logins are counted per address, so failing from one address
+// does not lock the account's owner out everywhere else
+type LoginFailures struct {
+	Email     string
+	IpAddress string
+}
+
+func (k LoginFailures) String() string {
+	return fmt.Sprintf("login_failures:%s:%s", k.Email, k.IpAddress)
+}
+
+// Failed logins of an account from every address, this catches guessing
+// spread over many addresses which the per address count does not see
+type AccountLoginFailures struct {
+	Email string
+}
+
+func (k AccountLoginFailures) String() string {
+	return fmt.Sprintf("account_login_failures:%s", k.Email)
+}
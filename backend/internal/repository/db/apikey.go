@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type apiKeyRepository struct {
+	db db.DBTX
+}
+
+func NewApiKeyRepository(db db.DBTX) domain.ApiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) WithTx(tx db.DBTX) domain.ApiKeyRepository {
+	return &apiKeyRepository{db: tx}
+}
+
+func (r *apiKeyRepository) NewApiKey(ctx context.Context, apiKey domain.ApiKey) (int, error) {
+	query := `
+	insert into "ApiKey" (merchant_id, employee_id, name, prefix, key_hash, scopes, rate_limit, expires_at)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	returning id
+	`
+
+	var apiKeyId int
+	err := r.db.QueryRow(ctx, query, apiKey.MerchantId, apiKey.EmployeeId, apiKey.Name, apiKey.Prefix, apiKey.KeyHash,
+		apiKeyScopesToStrings(apiKey.Scopes), apiKey.RateLimit, apiKey.ExpiresAt).Scan(&apiKeyId)
+	if err != nil {
+		return 0, fmt.Errorf("NewApiKey: %w", err)
+	}
+
+	return apiKeyId, nil
+}
+
+func (r *apiKeyRepository) GetApiKeys(ctx context.Context, merchantId uuid.UUID) ([]domain.ApiKey, error) {
+	query := `
+	select id, merchant_id, employee_id, name, prefix, key_hash, scopes, rate_limit, last_used_at, expires_at, revoked_at, created_at
+	from "ApiKey"
+	where merchant_id = $1
+	order by created_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	apiKeys, err := pgx.CollectRows(rows, scanApiKey)
+	if err != nil {
+		return []domain.ApiKey{}, fmt.Errorf("GetApiKeys: %w", err)
+	}
+
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) RevokeApiKey(ctx context.Context, merchantId uuid.UUID, apiKeyId int) error {
+	query := `
+	update "ApiKey"
+	set revoked_at = now()
+	where merchant_id = $1 and id = $2 and revoked_at is null
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, apiKeyId)
+	if err != nil {
+		return fmt.Errorf("RevokeApiKey: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("RevokeApiKey: api key not found")
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) GetApiKeyAuthInfo(ctx context.Context, keyHash string) (domain.ApiKeyAuthInfo, error) {
+	query := `
+	select ak.id, ak.merchant_id, ak.employee_id, ak.name, ak.prefix, ak.key_hash, ak.scopes, ak.rate_limit, ak.last_used_at,
+		ak.expires_at, ak.revoked_at, ak.created_at, e.user_id, e.is_active,
+		(select l.id from "Location" l where l.merchant_id = ak.merchant_id order by l.id limit 1) as location_id,
+		e.role, e.role_id, r.permissions as custom_permissions
+	from "ApiKey" ak
+	join "Employee" e on e.id = ak.employee_id
+	left join "Role" r on r.id = e.role_id
+	where ak.key_hash = $1
+	`
+
+	var info domain.ApiKeyAuthInfo
+	var scopes []string
+
+	err := r.db.QueryRow(ctx, query, keyHash).Scan(&info.Id, &info.MerchantId, &info.EmployeeId, &info.Name, &info.Prefix,
+		&info.KeyHash, &scopes, &info.RateLimit, &info.LastUsedAt, &info.ExpiresAt, &info.RevokedAt, &info.CreatedAt,
+		&info.UserId, &info.IsEmployeeActive, &info.Employee.LocationId, &info.Employee.Role, &info.Employee.RoleId,
+		&info.Employee.CustomPermissions)
+	if err != nil {
+		return domain.ApiKeyAuthInfo{}, fmt.Errorf("GetApiKeyAuthInfo: %w", err)
+	}
+
+	info.Scopes = stringsToApiKeyScopes(scopes)
+	info.Employee.Id = info.EmployeeId
+	info.Employee.MerchantId = info.MerchantId
+
+	return info, nil
+}
+
+func (r *apiKeyRepository) TouchApiKey(ctx context.Context, apiKeyId int) error {
+	query := `
+	update "ApiKey"
+	set last_used_at = now()
+	where id = $1 and (last_used_at is null or last_used_at < now() - interval '1 minute')
+	`
+
+	_, err := r.db.Exec(ctx, query, apiKeyId)
+	if err != nil {
+		return fmt.Errorf("TouchApiKey: %w", err)
+	}
+
+	return nil
+}
+
+func scanApiKey(row pgx.CollectableRow) (domain.ApiKey, error) {
+	var apiKey domain.ApiKey
+	var scopes []string
+
+	err := row.Scan(&apiKey.Id, &apiKey.MerchantId, &apiKey.EmployeeId, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, &scopes,
+		&apiKey.RateLimit, &apiKey.LastUsedAt, &apiKey.ExpiresAt, &apiKey.RevokedAt, &apiKey.CreatedAt)
+	apiKey.Scopes = stringsToApiKeyScopes(scopes)
+
+	return apiKey, err
+}
+
+func apiKeyScopesToStrings(scopes []types.ApiKeyScope) []string {
+	result := make([]string, len(scopes))
+	for i, s := range scopes {
+		result[i] = s.String()
+	}
+
+	return result
+}
+
+// unknown scopes are dropped, so removed scopes do not grant anything
+func stringsToApiKeyScopes(scopes []string) []types.ApiKeyScope {
+	result := make([]types.ApiKeyScope, 0, len(scopes))
+	for _, s := range scopes {
+		scope, err := types.NewApiKeyScope(s)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, scope)
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type auditLogRepository struct {
+	db db.DBTX
+}
+
+func NewAuditLogRepository(db db.DBTX) domain.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) WithTx(tx db.DBTX) domain.AuditLogRepository {
+	return &auditLogRepository{db: tx}
+}
+
+func (r *auditLogRepository) NewAuditLogEntry(ctx context.Context, entry domain.AuditLogEntry) error {
+	query := `
+	insert into "AuditLog" (merchant_id, employee_id, action, entity_type, entity_id, before, after, request_id)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(ctx, query, entry.MerchantId, entry.EmployeeId, entry.Action, entry.EntityType, entry.EntityId,
+		entry.Before, entry.After, entry.RequestId)
+	if err != nil {
+		return fmt.Errorf("NewAuditLogEntry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *auditLogRepository) GetAuditLogEntries(ctx context.Context, merchantId uuid.UUID, filter domain.AuditLogFilter, limit int) ([]domain.AuditLogEntryWithEmployee, error) {
+	query := `
+	select al.id, al.merchant_id, al.employee_id, al.action, al.entity_type, al.entity_id, al.before, al.after, al.request_id,
+		al.created_at, e.first_name as employee_first_name, e.last_name as employee_last_name
+	from "AuditLog" al
+	left join "Employee" e on al.employee_id = e.id
+	where al.merchant_id = $1 and ($2::int is null or al.employee_id = $2) and ($3::text is null or al.action = $3)
+		and ($4::text is null or al.entity_type = $4) and ($5::text is null or al.entity_id = $5)
+		and ($6::timestamptz is null or al.created_at >= $6) and ($7::timestamptz is null or al.created_at < $7)
+		and ($8::bigint is null or al.id < $8)
+	order by al.id desc
+	limit $9
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, filter.EmployeeId, filter.Action, filter.EntityType, filter.EntityId,
+		filter.From, filter.To, filter.CursorId, limit)
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.AuditLogEntryWithEmployee])
+	if err != nil {
+		return []domain.AuditLogEntryWithEmployee{}, fmt.Errorf("GetAuditLogEntries: %w", err)
+	}
+
+	return entries, nil
+}
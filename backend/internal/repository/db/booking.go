@@ -31,8 +31,8 @@ func (r *bookingRepository) NewBooking(ctx context.Context, booking domain.Booki
 	query := `
 	insert into "Booking" (status, booking_type, is_recurring, merchant_id, employee_id, service_id, location_id, booking_series_id, series_original_date, from_date, to_date,
 		service_name, price_per_person, total_price, price_type, formatted_location, merchant_note, min_participants, max_participants, current_participants,
-		occurrence_index, series_version)
-	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		occurrence_index, series_version, tax_name, tax_percent)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24::text::numeric)
 	returning id
 	`
 
@@ -41,7 +41,7 @@ func (r *bookingRepository) NewBooking(ctx context.Context, booking domain.Booki
 	err := r.db.QueryRow(ctx, query, booking.Status, booking.BookingType, booking.IsRecurring, booking.MerchantId, booking.EmployeeId, booking.ServiceId, booking.LocationId,
 		booking.BookingSeriesId, booking.SeriesOriginalDate, booking.FromDate, booking.ToDate, booking.ServiceName, booking.PricePerPerson, booking.TotalPrice,
 		booking.PriceType, booking.FormattedLocation, booking.MerchantNote, booking.MinParticipants, booking.MaxParticipants, booking.CurrentParticipants,
-		booking.OccurrenceIndex, booking.SeriesVersion).Scan(&bookingId)
+		booking.OccurrenceIndex, booking.SeriesVersion, booking.TaxName, booking.TaxPercent).Scan(&bookingId)
 	if err != nil {
 		return 0, fmt.Errorf("NewBooking: %w", err)
 	}
@@ -52,11 +52,12 @@ func (r *bookingRepository) NewBooking(ctx context.Context, booking domain.Booki
 func (r *bookingRepository) NewBookings(ctx context.Context, bookings []domain.Booking) ([]int, error) {
 	query := `
 	insert into "Booking" (status, booking_type, is_recurring, merchant_id, employee_id, service_id, location_id, booking_series_id, series_original_date, from_date, to_date,
-		service_name, price_per_person, total_price, price_type, formatted_location, merchant_note, min_participants, max_participants, current_participants, occurrence_index, series_version)
+		service_name, price_per_person, total_price, price_type, formatted_location, merchant_note, min_participants, max_participants, current_participants, occurrence_index, series_version,
+		tax_name, tax_percent)
 	select unnest($1::text[]), unnest($2::text[]), unnest($3::boolean[]), unnest($4::uuid[]), unnest($5::int[]), unnest($6::int[]), unnest($7::int[]),
 		unnest($8::int[]), unnest($9::timestamptz[]), unnest($10::timestamptz[]), unnest($11::timestamptz[]), unnest($12::text[]), unnest($13::price[]),
 		unnest($14::price[]), unnest($15::text[]), unnest($16::text[]), unnest($17::text[]), unnest($18::int[]), unnest($19::int[]), unnest($20::int[]),
-		unnest($21::int[]), unnest($22::int[])
+		unnest($21::int[]), unnest($22::int[]), unnest($23::text[]), unnest($24::text[])::numeric
 	returning id
 	`
 
@@ -86,6 +87,8 @@ func (r *bookingRepository) NewBookings(ctx context.Context, bookings []domain.B
 	currentParicipants := make([]int, bookingsCount)
 	occurrenceIndexes := make([]pgtype.Int4, bookingsCount)
 	seriesVersions := make([]pgtype.Int4, bookingsCount)
+	taxNames := make([]pgtype.Text, bookingsCount)
+	taxPercents := make([]pgtype.Text, bookingsCount)
 
 	for i, b := range bookings {
 		statuses[i] = b.Status.String()
@@ -138,11 +141,21 @@ func (r *bookingRepository) NewBookings(ctx context.Context, bookings []domain.B
 		} else {
 			seriesVersions[i] = pgtype.Int4{Int32: int32(*b.SeriesVersion), Valid: true}
 		}
+		if b.TaxName == nil {
+			taxNames[i] = pgtype.Text{Valid: false}
+		} else {
+			taxNames[i] = pgtype.Text{String: *b.TaxName, Valid: true}
+		}
+		if b.TaxPercent == nil {
+			taxPercents[i] = pgtype.Text{Valid: false}
+		} else {
+			taxPercents[i] = pgtype.Text{String: *b.TaxPercent, Valid: true}
+		}
 	}
 
 	rows, _ := r.db.Query(ctx, query, statuses, types, isRecurrings, merchantIds, employeeIds, serviceIds, locationIds, seriesIds, seriesOriginalDates,
 		fromDates, toDates, serviceNames, pricePerPersons, totalPrices, priceTypes, formattedLocations, merchantNotes, minParicipants, maxParicipants, currentParicipants,
-		occurrenceIndexes, seriesVersions)
+		occurrenceIndexes, seriesVersions, taxNames, taxPercents)
 	bookingIds, err := pgx.CollectRows(rows, pgx.RowTo[int])
 	if err != nil {
 		return []int{}, fmt.Errorf("NewBookings: %w", err)
@@ -502,6 +515,121 @@ func (r *bookingRepository) TransferDummyBookings(ctx context.Context, merchantI
 	return nil
 }
 
+// Moves the participations of a customer to another one. Bookings which both of them
+// participate in keep the participation of the other customer, the visit notes, attachments and
+// intake responses of the duplicate participation are moved to it and the booking loses a participant
+// if the duplicate was not cancelled
+func (r *bookingRepository) MergeCustomerParticipants(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) (int, error) {
+	// has to be a separate statement, the cascading delete of the duplicates would not see the moved rows otherwise
+	recordsQuery := `
+	with pairs as (
+		select bp.id as duplicate_id, kept.id as kept_id
+		from "BookingParticipant" bp
+		join "Booking" b on b.id = bp.booking_id
+		join "BookingParticipant" kept on kept.booking_id = bp.booking_id and kept.customer_id = $3
+		where b.merchant_id = $1 and bp.customer_id = $2
+	), visit_notes as (
+		update "VisitNote" vn
+		set participant_id = p.kept_id
+		from pairs p
+		where vn.participant_id = p.duplicate_id
+	), visit_attachments as (
+		update "VisitAttachment" va
+		set participant_id = p.kept_id
+		from pairs p
+		where va.participant_id = p.duplicate_id
+	)
+	update "IntakeResponse" ir
+	set participant_id = p.kept_id
+	from pairs p
+	where ir.participant_id = p.duplicate_id
+	`
+
+	_, err := r.db.Exec(ctx, recordsQuery, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return 0, fmt.Errorf("MergeCustomerParticipants: %w", err)
+	}
+
+	query := `
+	with duplicate as (
+		delete from "BookingParticipant" bp
+		using "Booking" b, "BookingParticipant" kept
+		where bp.booking_id = b.id and b.merchant_id = $1 and bp.customer_id = $2
+			and kept.booking_id = bp.booking_id and kept.customer_id = $3
+		returning bp.booking_id, bp.status, coalesce(bp.price_per_person, b.price_per_person) as price_per_person
+	), recounted as (
+		update "Booking" b
+		set current_participants = b.current_participants - 1,
+			total_price = row((b.total_price).number - (d.price_per_person).number, (b.total_price).currency)::price
+		from duplicate d
+		where b.id = d.booking_id and b.booking_type in ('event', 'class') and d.status <> 'cancelled'
+	), transferred as (
+		update "BookingParticipant" bp
+		set transferred_to = $3
+		from "Booking" b
+		where bp.booking_id = b.id and b.merchant_id = $1 and bp.transferred_to = $2
+	), moved as (
+		update "BookingParticipant" bp
+		set customer_id = $3
+		from "Booking" b
+		where bp.booking_id = b.id and b.merchant_id = $1 and bp.customer_id = $2
+			and not exists (select 1 from "BookingParticipant" kept where kept.booking_id = bp.booking_id and kept.customer_id = $3)
+		returning bp.id
+	)
+	select count(*) from moved
+	`
+
+	var moved int
+	err = r.db.QueryRow(ctx, query, merchantId, fromCustomerId, toCustomerId).Scan(&moved)
+	if err != nil {
+		return 0, fmt.Errorf("MergeCustomerParticipants: %w", err)
+	}
+
+	return moved, nil
+}
+
+// Moves the series participations of a customer to another one. Series which both of them
+// participate in keep the participation of the other customer and lose one participant
+func (r *bookingRepository) MergeCustomerSeriesParticipants(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) (int, error) {
+	query := `
+	with duplicate as (
+		delete from "BookingSeriesParticipant" sp
+		using "BookingSeries" bs, "BookingSeriesParticipant" kept
+		where sp.booking_series_id = bs.id and bs.merchant_id = $1 and sp.customer_id = $2
+			and kept.booking_series_id = sp.booking_series_id and kept.customer_id = $3
+		returning sp.booking_series_id, sp.is_active, kept.is_active as kept_is_active
+	), reactivated as (
+		update "BookingSeriesParticipant" kept
+		set is_active = true, dropped_out_on = null
+		from duplicate d
+		where kept.booking_series_id = d.booking_series_id and kept.customer_id = $3 and d.is_active and not kept.is_active
+	), recounted as (
+		update "BookingSeries" bs
+		set current_participants = bs.current_participants - 1,
+			total_price = row((bs.total_price).number - (bs.price_per_person).number, (bs.total_price).currency)::price
+		from duplicate d
+		-- an inactive participant was not counted, and a reactivated one takes the place of the duplicate
+		where bs.id = d.booking_series_id and bs.booking_type in ('event', 'class') and d.is_active and d.kept_is_active
+	), moved as (
+		update "BookingSeriesParticipant" sp
+		set customer_id = $3
+		from "BookingSeries" bs
+		where sp.booking_series_id = bs.id and bs.merchant_id = $1 and sp.customer_id = $2
+			and not exists (select 1 from "BookingSeriesParticipant" kept where kept.booking_series_id = sp.booking_series_id and kept.customer_id = $3)
+		returning sp.id
+	)
+	select count(*) from moved
+	`
+
+	var moved int
+	err := r.db.QueryRow(ctx, query, merchantId, fromCustomerId, toCustomerId).Scan(&moved)
+	if err != nil {
+		return 0, fmt.Errorf("MergeCustomerSeriesParticipants: %w", err)
+	}
+
+	return moved, nil
+}
+
 func (r *bookingRepository) UpdateBookingPhasesBatch(ctx context.Context, bookingPhases []domain.BookingPhase) error {
 	query := `
 	update "BookingPhase" bp
@@ -618,7 +746,7 @@ func (r *bookingRepository) GetBooking(ctx context.Context, bookingId int) (doma
 
 func (r *bookingRepository) GetPublicBooking(ctx context.Context, bookingId int, userId uuid.UUID) (domain.PublicBooking, error) {
 	query := `
-	select b.from_date, b.to_date, b.price_per_person as price, m.name as merchant_name, b.service_name, m.cancel_deadline, b.price_type,
+	select b.from_date, b.to_date, coalesce(bp.price_per_person, b.price_per_person) as price, m.name as merchant_name, b.service_name, m.cancel_deadline, b.price_type,
 		b.status, b.formatted_location
 	from "BookingParticipant" bp
 	join "Customer" c on c.id = bp.customer_id
@@ -849,13 +977,15 @@ func (r *bookingRepository) GetParticipantCustomerIdsForBookings(ctx context.Con
 func (r *bookingRepository) GetUpcomingBookingsForUser(ctx context.Context, userId uuid.UUID, limit int, cursorStart time.Time, cursorId int) ([]domain.BookingForUser, error) {
 	query := `
 	select b.id, b.status, b.booking_type, b.is_recurring, b.from_date, b.to_date, b.price_per_person, m.name as merchant_name,
-		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name
+		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name,
+		inv.invoice_number
 	from "Booking" b
 	join "BookingParticipant" bp on bp.booking_id = b.id
 	join "Customer" c on bp.customer_id = c.id
 	join "User" u on c.user_id = u.id
 	join "Merchant" m on b.merchant_id = m.id
 	left join "Employee" e on b.employee_id = e.id
+	left join "Invoice" inv on inv.participant_id = bp.id
 	where u.id = $1 and b.from_date > now() and b.status in ('booked', 'confirmed') and (b.from_date, b.id) > ($3, $4)
 	order by b.from_date asc, b.id asc
 	limit $2
@@ -873,13 +1003,15 @@ func (r *bookingRepository) GetUpcomingBookingsForUser(ctx context.Context, user
 func (r *bookingRepository) GetCompletedBookingsForUser(ctx context.Context, userId uuid.UUID, limit int, cursorStart time.Time, cursorId int) ([]domain.BookingForUser, error) {
 	query := `
 	select b.id, b.status, b.booking_type, b.is_recurring, b.from_date, b.to_date, b.price_per_person, m.name as merchant_name,
-		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name
+		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name,
+		inv.invoice_number
 	from "Booking" b
 	join "BookingParticipant" bp on bp.booking_id = b.id
 	join "Customer" c on bp.customer_id = c.id
 	join "User" u on c.user_id = u.id
 	join "Merchant" m on b.merchant_id = m.id
 	left join "Employee" e on b.employee_id = e.id
+	left join "Invoice" inv on inv.participant_id = bp.id
 	where u.id = $1 and b.to_date < now() and b.status not in ('cancelled', 'no-show') and (b.from_date, b.id) > ($3, $4)
 	order by b.from_date desc, b.id desc
 	limit $2
@@ -898,13 +1030,15 @@ func (r *bookingRepository) GetCompletedBookingsForUser(ctx context.Context, use
 func (r *bookingRepository) GetCancelledBookingsForUser(ctx context.Context, userId uuid.UUID, limit int, cursorStart time.Time, cursorId int) ([]domain.BookingForUser, error) {
 	query := `
 	select b.id, b.status, b.booking_type, b.is_recurring, b.from_date, b.to_date, b.price_per_person, m.name as merchant_name,
-		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name
+		m.url_name as merchant_url, b.formatted_location, b.service_name, e.first_name as employee_first_name, e.last_name as employee_last_name,
+		inv.invoice_number
 	from "Booking" b
 	join "BookingParticipant" bp on bp.booking_id = b.id
 	join "Customer" c on bp.customer_id = c.id
 	join "User" u on c.user_id = u.id
 	join "Merchant" m on b.merchant_id = m.id
 	left join "Employee" e on b.employee_id = e.id
+	left join "Invoice" inv on inv.participant_id = bp.id
 	where u.id = $1 and b.status in ('cancelled') and b.cancelled_by_merchant_on is not null and (b.from_date, b.id) > ($3, $4)
 	order by b.id asc
 	limit $2
@@ -1026,13 +1160,15 @@ func (r *bookingRepository) GetClosestAvailableGroupBooking(ctx context.Context,
 func (r *bookingRepository) NewBookingSeries(ctx context.Context, bs domain.BookingSeries) (domain.BookingSeries, error) {
 	query := `
 	insert into "BookingSeries" (booking_type, merchant_id, employee_id, service_id, location_id, rrule, dstart, timezone, is_active, generated_until,
-		service_name, price_per_person, total_price, price_type, formatted_location, min_participants, max_participants, current_participants)
-	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		service_name, price_per_person, total_price, price_type, formatted_location, min_participants, max_participants, current_participants,
+		tax_name, tax_percent)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20::text::numeric)
 	returning *
 	`
 
 	rows, _ := r.db.Query(ctx, query, bs.BookingType, bs.MerchantId, bs.EmployeeId, bs.ServiceId, bs.LocationId, bs.Rrule, bs.Dstart, bs.Timezone, true, bs.GeneratedUntil,
-		bs.ServiceName, bs.PricePerPerson, bs.TotalPrice, bs.PriceType, bs.FormattedLocation, bs.MinParticipants, bs.MaxParticipants, bs.CurrentParticipants)
+		bs.ServiceName, bs.PricePerPerson, bs.TotalPrice, bs.PriceType, bs.FormattedLocation, bs.MinParticipants, bs.MaxParticipants, bs.CurrentParticipants,
+		bs.TaxName, bs.TaxPercent)
 	bookingSeries, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[domain.BookingSeries])
 	if err != nil {
 		return domain.BookingSeries{}, fmt.Errorf("NewBookingSeries: %w", err)
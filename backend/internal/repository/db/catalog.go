@@ -23,20 +23,29 @@ func (r *catalogRepository) WithTx(tx db.DBTX) domain.CatalogRepository {
 	return &catalogRepository{db: tx}
 }
 
+// The price of the service the customers pay, the tax of exclusive rates is added on top of it.
+// The number is not rounded, the prices are rounded when they are formatted.
+const servicePriceWithTax = `case
+		when tr.is_inclusive = false and s.price_per_person is not null
+		then row((s.price_per_person).number * (100 + tr.percent) / 100, (s.price_per_person).currency)::price
+		else s.price_per_person
+	end`
+
 func (r *catalogRepository) NewService(ctx context.Context, serv domain.Service) (int, error) {
 	query := `
 	insert into "Service" (merchant_id, category_id, booking_type, name, description, color, total_duration, price_per_person,
-		price_type, is_active, sequence, min_participants, max_participants, cancel_deadline, booking_window_min, booking_window_max, buffer_time, approval_policy)
+		price_type, is_active, sequence, min_participants, max_participants, cancel_deadline, booking_window_min, booking_window_max, buffer_time, approval_policy,
+		tax_rate_id)
 	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, coalesce((
 		select max(sequence) + 1 from "Service" where category_id is not distinct from $2 and merchant_id = $1
-		), 1), $11, $12, $13, $14, $15, $16, $17)
+		), 1), $11, $12, $13, $14, $15, $16, $17, $18)
 	returning id
 	`
 
 	var serviceId int
 	err := r.db.QueryRow(ctx, query, serv.MerchantId, serv.CategoryId, serv.BookingType, serv.Name, serv.Description, serv.Color,
 		serv.TotalDuration, serv.Price, serv.PriceType, serv.IsActive, serv.MinParticipants, serv.MaxParticipants,
-		serv.CancelDeadline, serv.BookingWindowMin, serv.BookingWindowMax, serv.BufferTime, serv.ApprovalPolicy).Scan(&serviceId)
+		serv.CancelDeadline, serv.BookingWindowMin, serv.BookingWindowMax, serv.BufferTime, serv.ApprovalPolicy, serv.TaxRateId).Scan(&serviceId)
 	if err != nil {
 		return 0, fmt.Errorf("NewService: %w", err)
 	}
@@ -53,7 +62,7 @@ func (r *catalogRepository) UpdateService(ctx context.Context, s domain.Service)
 	update "Service"
 	set category_id = $3, name = $4, description = $5, color = $6, total_duration = $7, price_per_person = $8,
 		price_type = $9, is_active = $10, cancel_deadline = $11, booking_window_min = $12, booking_window_max = $13, buffer_time = $14,
-		approval_policy = $15, min_participants = $16, max_participants = $17,
+		approval_policy = $15, min_participants = $16, max_participants = $17, tax_rate_id = $18,
 		sequence = case
 			when old.category_id is distinct from $3 then (
 				coalesce((
@@ -70,7 +79,7 @@ func (r *catalogRepository) UpdateService(ctx context.Context, s domain.Service)
 	var oldCategoryId *int
 	err := r.db.QueryRow(ctx, query, s.Id, s.MerchantId, s.CategoryId, s.Name, s.Description, s.Color, s.TotalDuration,
 		s.Price, s.PriceType, s.IsActive, s.CancelDeadline, s.BookingWindowMin, s.BookingWindowMax, s.BufferTime,
-		s.ApprovalPolicy, s.MinParticipants, s.MaxParticipants).Scan(&oldCategoryId)
+		s.ApprovalPolicy, s.MinParticipants, s.MaxParticipants, s.TaxRateId).Scan(&oldCategoryId)
 	if err != nil {
 		return nil, fmt.Errorf("UpdateService: %w", err)
 	}
@@ -167,7 +176,7 @@ func (r *catalogRepository) GetServicesGroupedByCategory(ctx context.Context, me
 	with services as (
 		select s.id, s.merchant_id, s.category_id, s.booking_type, s.name, s.description, s.color, s.total_duration, s.price_per_person, s.price_type,
 			s.is_active, s.sequence, s.min_participants, s.max_participants, s.cancel_deadline, s.booking_window_min, s.booking_window_max,
-			s.buffer_time, s.approval_policy,
+			s.buffer_time, s.approval_policy, s.tax_rate_id,
 		coalesce (
 			jsonb_agg(
 				jsonb_build_object(
@@ -207,6 +216,7 @@ func (r *catalogRepository) GetServicesGroupedByCategory(ctx context.Context, me
 				'booking_window_max', s.booking_window_max,
 				'buffer_time', s.buffer_time,
 				'approval_policy', s.approval_policy,
+				'tax_rate_id', s.tax_rate_id,
 				'phases', s.phases
 			) order by s.sequence
 		) filter (where s.id is not null),
@@ -261,7 +271,7 @@ func (r *catalogRepository) GetServicesForCalendar(ctx context.Context, merchant
 				'id', s.id,
 				'name', s.name,
 				'duration', s.total_duration,
-				'price', s.price_per_person,
+				'price', ` + servicePriceWithTax + `,
 				'price_type', s.price_type,
 				'color', s.color,
 				'booking_type', s.booking_type,
@@ -271,6 +281,7 @@ func (r *catalogRepository) GetServicesForCalendar(ctx context.Context, merchant
 	'[]'::jsonb) as services
 	from "Service" s
 	left join "ServiceCategory" sc on s.category_id = sc.id
+	left join "TaxRate" tr on tr.id = s.tax_rate_id
 	where s.merchant_id = $1 and s.is_active = true
 	group by sc.id, sc.name
 	order by sc.sequence, sc.name
@@ -314,7 +325,7 @@ func (r *catalogRepository) GetServiceWithPhases(ctx context.Context, serviceID
 	query := `
 	select s.id, s.merchant_id, s.category_id, s.booking_type, s.name, s.description, s.color, s.total_duration, s.price_per_person, s.price_type,
 		s.is_active, s.sequence, s.min_participants, s.max_participants, s.cancel_deadline, s.booking_window_min, s.booking_window_max,
-		s.buffer_time, s.approval_policy,
+		s.buffer_time, s.approval_policy, s.tax_rate_id,
 	coalesce (
 		jsonb_agg(
 			jsonb_build_object(
@@ -338,7 +349,7 @@ func (r *catalogRepository) GetServiceWithPhases(ctx context.Context, serviceID
 
 	err := r.db.QueryRow(ctx, query, serviceID, merchantId).Scan(&s.Id, &s.MerchantId, &s.CategoryId, &s.BookingType, &s.Name, &s.Description, &s.Color, &s.TotalDuration,
 		&s.Price, &s.PriceType, &s.IsActive, &s.Sequence, &s.MinParticipants, &s.MaxParticipants, &s.CancelDeadline, &s.BookingWindowMin,
-		&s.BookingWindowMax, &s.BufferTime, &s.ApprovalPolicy, &phasesJson)
+		&s.BookingWindowMax, &s.BufferTime, &s.ApprovalPolicy, &s.TaxRateId, &phasesJson)
 	if err != nil {
 		return domain.Service{}, fmt.Errorf("GetServiceWithPhases: %w", err)
 	}
@@ -365,7 +376,7 @@ func (r *catalogRepository) GetServicesForMerchantPage(ctx context.Context, merc
 				'name', s.name,
 				'description', s.description,
 				'total_duration', s.total_duration,
-				'price', s.price_per_person,
+				'price', ` + servicePriceWithTax + `,
 				'price_type', s.price_type,
 				'max_participants', s.max_participants,
 				'booking_type', s.booking_type,
@@ -375,6 +386,7 @@ func (r *catalogRepository) GetServicesForMerchantPage(ctx context.Context, merc
 	'[]'::jsonb) as services
 	from "Service" s
 	left join "ServiceCategory" sc on s.category_id = sc.id
+	left join "TaxRate" tr on tr.id = s.tax_rate_id
 	where s.merchant_id = $1 and s.is_active = true
 	group by sc.id, sc.name
 	order by sc.sequence, sc.name
@@ -416,7 +428,7 @@ func (r *catalogRepository) GetServicesForMerchantPage(ctx context.Context, merc
 
 func (r *catalogRepository) GetServiceDetailsForMerchantPage(ctx context.Context, merchantId uuid.UUID, serviceId int, locationId int) (domain.PublicServiceDetails, error) {
 	query := `
-	select s.id, s.name, s.description, s.total_duration, s.price_per_person as price, s.price_type, l.formatted_location, l.geo_point,
+	select s.id, s.name, s.description, s.total_duration, ` + servicePriceWithTax + ` as price, s.price_type, l.formatted_location, l.geo_point,
 	coalesce(
 		jsonb_agg(
 			jsonb_build_object(
@@ -433,8 +445,9 @@ func (r *catalogRepository) GetServiceDetailsForMerchantPage(ctx context.Context
 	from "Service" s
 	left join "ServicePhase" sp on s.id = sp.service_id
 	left join "Location" l on l.merchant_id = $2 and l.id = $3
+	left join "TaxRate" tr on tr.id = s.tax_rate_id
 	where s.id = $1 and s.merchant_id = $2
-	group by s.id, l.formatted_location, l.geo_point`
+	group by s.id, l.formatted_location, l.geo_point, tr.id`
 
 	var data domain.PublicServiceDetails
 	var phaseJson []byte
@@ -489,7 +502,7 @@ func (r *catalogRepository) GetAllServicePageData(ctx context.Context, serviceId
 		group by sprod.service_id
 	)
 	select s.id, s.name, s.booking_type, s.category_id, s.description, s.color, s.total_duration, s.price_per_person, s.price_type, s.is_active, s.sequence,
-		min_participants, max_participants,
+		min_participants, max_participants, s.tax_rate_id,
 		jsonb_build_object(
 		 	'cancel_deadline', s.cancel_deadline,
          	'booking_window_min', s.booking_window_min,
@@ -512,7 +525,7 @@ func (r *catalogRepository) GetAllServicePageData(ctx context.Context, serviceId
 
 	err := r.db.QueryRow(ctx, query, serviceId, merchantId).Scan(&spd.Id, &spd.Name, &spd.BookingType, &spd.CategoryId, &spd.Description,
 		&spd.Color, &spd.TotalDuration, &spd.Price, &spd.PriceType, &spd.IsActive, &spd.Sequence, &spd.MinParicipants, &spd.MaxParticipants,
-		&settingsJson, &phaseJson, &productJson)
+		&spd.TaxRateId, &settingsJson, &phaseJson, &productJson)
 	if err != nil {
 		return domain.ServicePageData{}, fmt.Errorf("GetAllServicePageData: %w", err)
 	}
@@ -551,17 +564,22 @@ func (r *catalogRepository) GetServicePageFormOptions(ctx context.Context, merch
 	),
 	category as (
 		select id, name from "ServiceCategory" where merchant_id = $1
+	),
+	tax_rate as (
+		select id, merchant_id, name, percent::text as percent, is_inclusive from "TaxRate" where merchant_id = $1 order by name
 	)
 	select
 		coalesce((select jsonb_agg(p) from product p), '[]'::jsonb) as products,
-		coalesce((select jsonb_agg(c) from category c), '[]'::jsonb) as categories
+		coalesce((select jsonb_agg(c) from category c), '[]'::jsonb) as categories,
+		coalesce((select jsonb_agg(t) from tax_rate t), '[]'::jsonb) as tax_rates
 	`
 
 	var spfo domain.ServicePageFormOptions
 	var products []byte
 	var categories []byte
+	var taxRates []byte
 
-	err := r.db.QueryRow(ctx, query, merchantId).Scan(&products, &categories)
+	err := r.db.QueryRow(ctx, query, merchantId).Scan(&products, &categories, &taxRates)
 	if err != nil {
 		return domain.ServicePageFormOptions{}, fmt.Errorf("GetServicePageFormOptions: %w", err)
 	}
@@ -584,14 +602,24 @@ func (r *catalogRepository) GetServicePageFormOptions(ctx context.Context, merch
 		spfo.Categories = []domain.ServiceCategory{}
 	}
 
+	if len(taxRates) > 0 {
+		err = json.Unmarshal(taxRates, &spfo.TaxRates)
+		if err != nil {
+			return domain.ServicePageFormOptions{}, fmt.Errorf("GetServicePageFormOptions: %w", err)
+		}
+	} else {
+		spfo.TaxRates = []domain.TaxRate{}
+	}
+
 	return spfo, nil
 }
 
 func (r *catalogRepository) GetMinimalServiceInfo(ctx context.Context, merchantId uuid.UUID, serviceId, locationId int) (domain.MinimalServiceInfo, error) {
 	query := `
-	select s.name, s.total_duration, s.price_per_person as price, s.price_type, l.formatted_location
+	select s.name, s.total_duration, ` + servicePriceWithTax + ` as price, s.price_type, l.formatted_location
 	from "Service" s
 	left join "Location" l on l.merchant_id = $1 and l.id = $3
+	left join "TaxRate" tr on tr.id = s.tax_rate_id
 	where s.merchant_id = $1 and s.id = $2
 	`
 	var msi domain.MinimalServiceInfo
@@ -857,3 +885,91 @@ func (r *catalogRepository) GetServiceProducts(ctx context.Context, serviceId in
 
 	return connectedProducts, nil
 }
+
+func (r *catalogRepository) NewTaxRate(ctx context.Context, merchantId uuid.UUID, taxRate domain.TaxRate) (int, error) {
+	query := `
+	insert into "TaxRate" (merchant_id, name, percent, is_inclusive)
+	values ($1, $2, $3::text::numeric, $4)
+	returning id
+	`
+
+	var taxRateId int
+	err := r.db.QueryRow(ctx, query, merchantId, taxRate.Name, taxRate.Percent, taxRate.IsInclusive).Scan(&taxRateId)
+	if err != nil {
+		return 0, fmt.Errorf("NewTaxRate: %w", err)
+	}
+
+	return taxRateId, nil
+}
+
+func (r *catalogRepository) UpdateTaxRate(ctx context.Context, merchantId uuid.UUID, taxRate domain.TaxRate) error {
+	query := `
+	update "TaxRate"
+	set name = $3, percent = $4::text::numeric, is_inclusive = $5
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, taxRate.Id, taxRate.Name, taxRate.Percent, taxRate.IsInclusive)
+	if err != nil {
+		return fmt.Errorf("UpdateTaxRate: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UpdateTaxRate: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *catalogRepository) DeleteTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId int) error {
+	query := `
+	delete from "TaxRate"
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, taxRateId)
+	if err != nil {
+		return fmt.Errorf("DeleteTaxRate: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteTaxRate: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+const taxRateColumns = `id, merchant_id, name, percent::text as percent, is_inclusive`
+
+func (r *catalogRepository) GetTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId int) (domain.TaxRate, error) {
+	query := `
+	select ` + taxRateColumns + `
+	from "TaxRate"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, taxRateId)
+	taxRate, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.TaxRate])
+	if err != nil {
+		return domain.TaxRate{}, fmt.Errorf("GetTaxRate: %w", err)
+	}
+
+	return taxRate, nil
+}
+
+func (r *catalogRepository) GetTaxRates(ctx context.Context, merchantId uuid.UUID) ([]domain.TaxRate, error) {
+	query := `
+	select ` + taxRateColumns + `
+	from "TaxRate"
+	where merchant_id = $1
+	order by name, id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	taxRates, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.TaxRate])
+	if err != nil {
+		return []domain.TaxRate{}, fmt.Errorf("GetTaxRates: %w", err)
+	}
+
+	return taxRates, nil
+}
@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 )
 
@@ -29,11 +31,12 @@ func (r *customerRepository) WithTx(tx db.DBTX) domain.CustomerRepository {
 
 func (r *customerRepository) NewCustomer(ctx context.Context, merchantId uuid.UUID, customer domain.Customer) error {
 	query := `
-	insert into "Customer" (id, merchant_id, first_name, last_name, email, phone_number, birthday, note)
-	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	insert into "Customer" (id, merchant_id, first_name, last_name, email, phone_number, birthday, note, tags, custom_fields)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, coalesce($9, '{}'::text[]), coalesce($10, '{}'::jsonb))
 	`
 
-	_, err := r.db.Exec(ctx, query, customer.Id, merchantId, customer.FirstName, customer.LastName, customer.Email, customer.PhoneNumber, customer.Birthday, customer.Note)
+	_, err := r.db.Exec(ctx, query, customer.Id, merchantId, customer.FirstName, customer.LastName, customer.Email, customer.PhoneNumber, customer.Birthday,
+		customer.Note, customer.Tags, customer.CustomFields)
 	if err != nil {
 		return fmt.Errorf("NewCustomer: %w", err)
 	}
@@ -41,23 +44,21 @@ func (r *customerRepository) NewCustomer(ctx context.Context, merchantId uuid.UU
 	return nil
 }
 
-func (r *customerRepository) NewCustomerFromUser(ctx context.Context, customerId, merchantId, userId uuid.UUID) (uuid.UUID, bool, bool, error) {
+func (r *customerRepository) NewCustomerFromUser(ctx context.Context, customerId, merchantId, userId uuid.UUID) (domain.CustomerFromUser, error) {
 	query := `
 	insert into "Customer" (id, merchant_id, user_id) values ($1, $2, $3)
 	on conflict (merchant_id, user_id) do update
 	set merchant_id = excluded.merchant_id
-	returning id, is_blacklisted, (xmax = 0) as is_new`
+	returning id, is_blacklisted, requires_approval, (xmax = 0) as is_new`
 
-	var IsBlacklisted bool
-	var custId uuid.UUID
-	var isNew bool
+	var customer domain.CustomerFromUser
 
-	err := r.db.QueryRow(ctx, query, customerId, merchantId, userId).Scan(&custId, &IsBlacklisted, &isNew)
+	err := r.db.QueryRow(ctx, query, customerId, merchantId, userId).Scan(&customer.Id, &customer.IsBlacklisted, &customer.RequiresApproval, &customer.IsNew)
 	if err != nil {
-		return uuid.UUID{}, false, false, fmt.Errorf("NewCustomerFromUser: %w", err)
+		return domain.CustomerFromUser{}, fmt.Errorf("NewCustomerFromUser: %w", err)
 	}
 
-	return custId, IsBlacklisted, isNew, nil
+	return customer, nil
 }
 
 // TODO: do we want patch or put here?
@@ -74,6 +75,8 @@ func (r *customerRepository) UpdateCustomer(ctx context.Context, merchantId uuid
 		{"phone_number", customer.PhoneNumber},
 		{"birthday", customer.Birthday},
 		{"note", customer.Note},
+		{"tags", customer.Tags},
+		{"custom_fields", customer.CustomFields},
 	}
 
 	setClauses := []string{}
@@ -120,47 +123,202 @@ func (r *customerRepository) DeleteCustomer(ctx context.Context, customerId uuid
 	return nil
 }
 
-func (r *customerRepository) GetCustomers(ctx context.Context, merchantId uuid.UUID, isBlacklisted bool) ([]domain.PublicCustomer, error) {
+func (r *customerRepository) GetCustomerIdsByUser(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
 	query := `
-	select c.id,
-		   coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
-		   coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number, c.birthday, c.note,
-		   c.user_id is null as is_dummy, c.is_blacklisted, c.blacklist_reason,
-		count(b.id) as times_booked, count(distinct bp.status = 'cancelled') as times_cancelled
-	from "Customer" c
-	left join "User" u on c.user_id = u.id
-	left join "BookingParticipant" bp on c.id = coalesce(bp.transferred_to, bp.customer_id)
-	left join "Booking" b on bp.booking_id = b.id and b.merchant_id = $1
-	where c.merchant_id = $1 and c.is_blacklisted = $2
-	group by c.id, u.first_name, u.last_name, u.email, u.phone_number
+	select id
+	from "Customer"
+	where user_id = $1
 	`
 
-	rows, _ := r.db.Query(ctx, query, merchantId, isBlacklisted)
-	customers, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.PublicCustomer])
+	rows, _ := r.db.Query(ctx, query, userId)
+	customerIds, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
 	if err != nil {
-		return []domain.PublicCustomer{}, fmt.Errorf("GetCustomers: %w", err)
+		return []uuid.UUID{}, fmt.Errorf("GetCustomerIdsByUser: %w", err)
 	}
 
-	// if customers array is empty the encoded json field will be null
-	// unless an empty slice is supplied to it
-	if len(customers) == 0 {
-		customers = []domain.PublicCustomer{}
+	return customerIds, nil
+}
+
+// The personal data is removed from the customers, their participant rows (the ones transferred to them included),
+// the audit log entries and webhook payloads of those and the gift cards sent to their email address,
+// while the customers and their bookings are kept for the statistics.
+// The snapshots of the duplicates merged into the customers and the marketing consents are deleted
+func (r *customerRepository) AnonymizeCustomers(ctx context.Context, customerIds []uuid.UUID) error {
+	query := `
+	with originals as (
+		select c.id, c.merchant_id, lower(coalesce(c.email, u.email)) as email
+		from "Customer" c
+		left join "User" u on u.id = c.user_id
+		where c.id = any($1)
+	), anonymized as (
+		update "Customer"
+		set user_id = null, first_name = null, last_name = null, email = null, phone_number = null, birthday = null, note = null,
+			is_blacklisted = false, blacklist_reason = null, requires_approval = false, approval_reason = null, tags = '{}',
+			custom_fields = '{}', anonymized_on = now()
+		where id = any($1)
+		returning id
+	), participants as (
+		update "BookingParticipant"
+		set customer_note = null, cancellation_reason = null
+		where customer_id in (select id from anonymized) or transferred_to in (select id from anonymized)
+		returning id
+	), visit_notes as (
+		delete from "VisitNote"
+		where participant_id in (select id from participants)
+	), intake_responses as (
+		delete from "IntakeResponse"
+		where participant_id in (select id from participants)
+	), visit_attachments as (
+		-- the cleanup job removes the files
+		update "VisitAttachment"
+		set deleted_at = now()
+		where participant_id in (select id from participants) and deleted_at is null
+	), merges as (
+		delete from "CustomerMerge"
+		where customer_id in (select id from anonymized)
+	), consents as (
+		delete from "MarketingConsent"
+		where customer_id in (select id from anonymized)
+	), gift_cards as (
+		update "GiftCard" g
+		set recipient_name = null, recipient_email = null, message = null
+		from originals o
+		where g.merchant_id = o.merchant_id and o.email is not null and lower(g.recipient_email) = o.email
+	), webhook_deliveries as (
+		-- only the id of the customer is kept from the event data
+		update "WebhookDelivery"
+		set payload = jsonb_set(payload, '{data}', jsonb_build_object('id', payload->'data'->'id'))
+		where event_type like 'customer.%' and payload->'data'->>'id' in (select id::text from anonymized)
+	)
+	update "AuditLog"
+	set before = null, after = null
+	where (entity_type = 'customer' and entity_id in (select id::text from anonymized))
+		or (entity_type = 'booking_participant' and entity_id in (select id::text from participants))
+	`
+
+	_, err := r.db.Exec(ctx, query, customerIds)
+	if err != nil {
+		return fmt.Errorf("AnonymizeCustomers: %w", err)
 	}
 
-	return customers, nil
+	return nil
+}
+
+var customerSortColumns = map[string]string{
+	domain.CustomerSortName:        "lower(last_name) %[1]s nulls last, lower(first_name) %[1]s nulls last",
+	domain.CustomerSortTimesBooked: "times_booked %s",
+	domain.CustomerSortNoShows:     "no_shows %s",
+	domain.CustomerSortLastVisit:   "last_visit %s nulls last",
+}
+
+func (r *customerRepository) GetCustomers(ctx context.Context, merchantId uuid.UUID, customerQuery domain.CustomerQuery) ([]domain.PublicCustomer, int, error) {
+	sortColumn, ok := customerSortColumns[customerQuery.SortBy]
+	if !ok {
+		sortColumn = customerSortColumns[domain.CustomerSortName]
+	}
+
+	direction := "asc"
+	if customerQuery.Descending {
+		direction = "desc"
+	}
+
+	filter := customerQuery.Filter
+
+	customFields := filter.CustomFields
+	if customFields == nil {
+		customFields = domain.CustomFieldValues{}
+	}
+
+	tags := filter.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	query := fmt.Sprintf(`
+	with stats as (
+		select coalesce(bp.transferred_to, bp.customer_id) as customer_id, count(*) as times_booked,
+			count(*) filter (where bp.status = 'cancelled') as times_cancelled,
+			count(*) filter (where bp.status = 'no-show') as no_shows,
+			max(b.from_date) filter (where bp.status = 'completed') as last_visit
+		from "BookingParticipant" bp
+		join "Booking" b on bp.booking_id = b.id
+		where b.merchant_id = $1
+		group by coalesce(bp.transferred_to, bp.customer_id)
+	), customers as (
+		select c.id, coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
+			coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number, c.birthday, c.note,
+			c.tags, c.custom_fields, c.user_id is null as is_dummy, c.is_blacklisted, c.blacklist_reason,
+			coalesce(s.times_booked, 0) as times_booked, coalesce(s.times_cancelled, 0) as times_cancelled,
+			coalesce(s.no_shows, 0) as no_shows, s.last_visit
+		from "Customer" c
+		join "Merchant" m on c.merchant_id = m.id
+		left join "User" u on c.user_id = u.id
+		left join stats s on c.id = s.customer_id
+		where c.merchant_id = $1 and c.anonymized_on is null and ($2::boolean is null or c.is_blacklisted = $2) and c.tags @> $3::text[] and c.custom_fields @> $4::jsonb
+			and (not $5::boolean or extract(month from c.birthday) = extract(month from now() at time zone coalesce(m.timezone, 'UTC')))
+	)
+	select *, count(*) over () as total_count
+	from customers
+	where ($6::text is null or strpos(lower(concat_ws(' ', first_name, last_name, email, phone_number)), lower($6)) > 0)
+		and ($7::int is null or last_visit is null or last_visit < now() - make_interval(days => $7))
+		and ($8::int is null or no_shows >= $8) and ($9::int is null or times_booked >= $9)
+	order by %s, id
+	limit $10 offset $11
+	`, fmt.Sprintf(sortColumn, direction))
+
+	rows, _ := r.db.Query(ctx, query, merchantId, filter.IsBlacklisted, tags, customFields, filter.BirthdayThisMonth, filter.Search,
+		filter.NoVisitInDays, filter.MinNoShows, filter.MinTimesBooked, customerQuery.Limit, customerQuery.Offset)
+	customers, err := pgx.CollectRows(rows, pgx.RowToStructByName[customerWithTotal])
+	if err != nil {
+		return []domain.PublicCustomer{}, 0, fmt.Errorf("GetCustomers: %w", err)
+	}
+
+	out := make([]domain.PublicCustomer, len(customers))
+	total := 0
+
+	for i, c := range customers {
+		out[i] = c.PublicCustomer
+		total = c.TotalCount
+	}
+
+	return out, total, nil
+}
+
+type customerWithTotal struct {
+	domain.PublicCustomer
+	TotalCount int `db:"total_count"`
+}
+
+func (r *customerRepository) GetCustomerTags(ctx context.Context, merchantId uuid.UUID) ([]domain.CustomerTag, error) {
+	query := `
+	select tag, count(*) as count
+	from "Customer" c, unnest(c.tags) as tag
+	where c.merchant_id = $1
+	group by tag
+	order by count desc, tag
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	tags, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerTag])
+	if err != nil {
+		return []domain.CustomerTag{}, fmt.Errorf("GetCustomerTags: %w", err)
+	}
+
+	return tags, nil
 }
 
 func (r *customerRepository) GetCustomerInfo(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (domain.CustomerInfo, error) {
 	query := `
 	select c.id, coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
-	coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number, c.birthday, c.note, c.user_id is null as is_dummy
+	coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number, c.birthday, c.note, c.tags, c.custom_fields,
+	c.user_id is null as is_dummy
 	from "Customer" c
 	left join "User" u on u.id = c.user_id
 	where c.id = $1 and c.merchant_id = $2`
 
 	var customer domain.CustomerInfo
 	err := r.db.QueryRow(ctx, query, customerId, merchantId).Scan(&customer.Id, &customer.FirstName, &customer.LastName,
-		&customer.Email, &customer.PhoneNumber, &customer.Birthday, &customer.Note, &customer.IsDummy)
+		&customer.Email, &customer.PhoneNumber, &customer.Birthday, &customer.Note, &customer.Tags, &customer.CustomFields, &customer.IsDummy)
 	if err != nil {
 		return domain.CustomerInfo{}, fmt.Errorf("GetCustomerInfo: %w", err)
 	}
@@ -185,7 +343,8 @@ func (r *customerRepository) GetCustomerStats(ctx context.Context, merchantId uu
 				) order by b.from_date desc
 			) as bookings
 		from (
-			select bp.customer_id, b.id, b.from_date, b.to_date, b.merchant_id, b.location_id, b.service_id, b.price_per_person, bp.status
+			select bp.customer_id, b.id, b.from_date, b.to_date, b.merchant_id, b.location_id, b.service_id,
+				coalesce(bp.price_per_person, b.price_per_person) as price_per_person, bp.status
 			from "Booking" b
 			left join "BookingParticipant" bp on bp.booking_id = b.id and (bp.customer_id = $2 or bp.transferred_to = $2)
 			where b.merchant_id = $1 and b.cancelled_by_merchant_on is null
@@ -196,8 +355,8 @@ func (r *customerRepository) GetCustomerStats(ctx context.Context, merchantId uu
 		group by b.customer_id
 	)
 	select c.id, coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
-		coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number,birthday, note, c.user_id is null as is_dummy, c.is_blacklisted, c.blacklist_reason,
-		count(b.id) as times_booked, count(distinct case when bp.status in ('cancelled', 'no-show') then b.id end) as times_cancelled_by_user,
+		coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number,birthday, note, c.tags, c.custom_fields, c.user_id is null as is_dummy, c.is_blacklisted, c.blacklist_reason,
+		c.requires_approval, c.approval_reason, count(b.id) as times_booked, count(distinct case when bp.status in ('cancelled', 'no-show') then b.id end) as times_cancelled_by_user,
 		count(distinct case when bp.status in ('booked', 'confirmed') then b.id end) as times_upcoming, count(distinct case when bp.status in ('completed') then b.id end) as times_completed,
 		coalesce(ca.bookings, '[]'::jsonb) as bookings
 	from "Customer" c
@@ -213,7 +372,7 @@ func (r *customerRepository) GetCustomerStats(ctx context.Context, merchantId uu
 	var bookingsJSON []byte
 
 	err := r.db.QueryRow(ctx, query, merchantId, customerId).Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.Email, &customer.PhoneNumber, &customer.Birthday,
-		&customer.Note, &customer.IsDummy, &customer.IsBlacklisted, &customer.BlacklistReason, &customer.TimesBooked, &customer.TimesCancelledByUser, &customer.TimesUpcoming,
+		&customer.Note, &customer.Tags, &customer.CustomFields, &customer.IsDummy, &customer.IsBlacklisted, &customer.BlacklistReason, &customer.RequiresApproval, &customer.ApprovalReason, &customer.TimesBooked, &customer.TimesCancelledByUser, &customer.TimesUpcoming,
 		&customer.TimesCompleted, &bookingsJSON)
 	if err != nil {
 		return domain.CustomerStatistics{}, fmt.Errorf("GetCustomerStats: %w", err)
@@ -239,7 +398,7 @@ func (r *customerRepository) GetCustomersForCalendar(ctx context.Context, mercha
 	left join "User" u on c.user_id = u.id
 	left join "BookingParticipant" bp on bp.customer_id = c.id and bp.status = 'completed'
 	left join "Booking" b on bp.booking_id = b.id and b.merchant_id = $1 and b.from_date < now()
-	where c.merchant_id = $1
+	where c.merchant_id = $1 and c.anonymized_on is null
 	group by c.id, u.first_name, u.last_name, u.email, u.phone_number
 	`
 
@@ -266,6 +425,51 @@ func (r *customerRepository) SetBlacklistStatusForCustomer(ctx context.Context,
 
 }
 
+func (r *customerRepository) GetCustomerBlacklistStatus(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (domain.CustomerBlacklistStatus, error) {
+	query := `
+	select is_blacklisted, blacklist_reason
+	from "Customer"
+	where merchant_id = $1 and id = $2`
+
+	var status domain.CustomerBlacklistStatus
+	err := r.db.QueryRow(ctx, query, merchantId, customerId).Scan(&status.IsBlacklisted, &status.BlacklistReason)
+	if err != nil {
+		return domain.CustomerBlacklistStatus{}, fmt.Errorf("GetCustomerBlacklistStatus: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *customerRepository) SetApprovalStatusForCustomer(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, requiresApproval bool, approvalReason *string) error {
+	query := `
+	update "Customer"
+	set requires_approval = $3, approval_reason = $4
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, customerId, requiresApproval, approvalReason)
+	if err != nil {
+		return fmt.Errorf("SetApprovalStatusForCustomer: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerApprovalStatus(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (domain.CustomerApprovalStatus, error) {
+	query := `
+	select requires_approval, approval_reason from "Customer"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	status, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerApprovalStatus])
+	if err != nil {
+		return domain.CustomerApprovalStatus{}, fmt.Errorf("GetCustomerApprovalStatus: %w", err)
+	}
+
+	return status, nil
+}
+
 func (r *customerRepository) GetCustomerEmailById(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (*string, error) {
 	query := `
 	select coalesce(c.email, u.email)
@@ -285,3 +489,709 @@ func (r *customerRepository) GetCustomerEmailById(ctx context.Context, merchantI
 
 	return email, nil
 }
+
+func (r *customerRepository) NewCustomers(ctx context.Context, merchantId uuid.UUID, customers []domain.Customer) error {
+	query := `
+	insert into "Customer" (id, merchant_id, first_name, last_name, email, phone_number, birthday, note)
+	select unnest($2::uuid[]), $1, unnest($3::text[]), unnest($4::text[]), unnest($5::text[]), unnest($6::text[]), unnest($7::date[]), unnest($8::text[])
+	`
+
+	count := len(customers)
+
+	ids := make([]uuid.UUID, count)
+	firstNames := make([]*string, count)
+	lastNames := make([]*string, count)
+	emails := make([]*string, count)
+	phoneNumbers := make([]*string, count)
+	birthdays := make([]*time.Time, count)
+	notes := make([]*string, count)
+
+	for i, customer := range customers {
+		ids[i] = customer.Id
+		firstNames[i] = customer.FirstName
+		lastNames[i] = customer.LastName
+		emails[i] = customer.Email
+		phoneNumbers[i] = customer.PhoneNumber
+		birthdays[i] = customer.Birthday
+		notes[i] = customer.Note
+	}
+
+	_, err := r.db.Exec(ctx, query, merchantId, ids, firstNames, lastNames, emails, phoneNumbers, birthdays, notes)
+	if err != nil {
+		return fmt.Errorf("NewCustomers: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerContacts(ctx context.Context, merchantId uuid.UUID) ([]domain.CustomerContact, error) {
+	query := `
+	select c.id, coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number
+	from "Customer" c
+	left join "User" u on u.id = c.user_id
+	where c.merchant_id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	contacts, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerContact])
+	if err != nil {
+		return []domain.CustomerContact{}, fmt.Errorf("GetCustomerContacts: %w", err)
+	}
+
+	return contacts, nil
+}
+
+func (r *customerRepository) NewCustomerImport(ctx context.Context, customerImport domain.CustomerImport) (int, error) {
+	query := `
+	insert into "CustomerImport" (merchant_id, employee_id, status, content, options, total_rows)
+	values ($1, $2, $3, $4, $5, $6)
+	returning id
+	`
+
+	var importId int
+	err := r.db.QueryRow(ctx, query, customerImport.MerchantId, customerImport.EmployeeId, customerImport.Status, customerImport.Content,
+		customerImport.Options, customerImport.TotalRows).Scan(&importId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCustomerImport: %w", err)
+	}
+
+	return importId, nil
+}
+
+func (r *customerRepository) GetCustomerImport(ctx context.Context, merchantId uuid.UUID, importId int) (domain.CustomerImport, error) {
+	query := `
+	select id, merchant_id, employee_id, status, content, options, total_rows, processed_rows, created_count,
+		duplicate_count, invalid_count, row_errors, error, created_at, finished_at
+	from "CustomerImport"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, importId)
+	customerImport, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerImport])
+	if err != nil {
+		return domain.CustomerImport{}, fmt.Errorf("GetCustomerImport: %w", err)
+	}
+
+	return customerImport, nil
+}
+
+// The uploaded file is not needed anymore once the import finished
+func (r *customerRepository) UpdateCustomerImportProgress(ctx context.Context, importId int, progress domain.CustomerImportProgress) error {
+	query := `
+	update "CustomerImport"
+	set status = $2, processed_rows = $3, created_count = $4, duplicate_count = $5, invalid_count = $6, row_errors = $7, error = $8,
+		finished_at = case when $2 in ('completed', 'failed') then now() end,
+		content = case when $2 in ('completed', 'failed') then '' else content end
+	where id = $1
+	`
+
+	rowErrors := progress.RowErrors
+	if rowErrors == nil {
+		rowErrors = []domain.CustomerImportRowError{}
+	}
+
+	_, err := r.db.Exec(ctx, query, importId, progress.Status, progress.ProcessedRows, progress.CreatedCount, progress.DuplicateCount,
+		progress.InvalidCount, rowErrors, progress.Error)
+	if err != nil {
+		return fmt.Errorf("UpdateCustomerImportProgress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetDuplicateCandidates(ctx context.Context, merchantId uuid.UUID) ([]domain.DuplicateCandidate, error) {
+	query := `
+	select c.id, coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
+		coalesce(c.email, u.email) as email, coalesce(c.phone_number, u.phone_number) as phone_number,
+		c.user_id is null as is_dummy, c.is_blacklisted
+	from "Customer" c
+	left join "User" u on c.user_id = u.id
+	where c.merchant_id = $1 and c.anonymized_on is null
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	candidates, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.DuplicateCandidate])
+	if err != nil {
+		return []domain.DuplicateCandidate{}, fmt.Errorf("GetDuplicateCandidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func (r *customerRepository) GetCustomerForMergeWithLock(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (domain.CustomerForMerge, error) {
+	query := `
+	select id, first_name, last_name, email, phone_number, birthday, note, tags, custom_fields, user_id, is_blacklisted, blacklist_reason
+	from "Customer"
+	where merchant_id = $1 and id = $2
+	for update
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	customer, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerForMerge])
+	if err != nil {
+		return domain.CustomerForMerge{}, fmt.Errorf("GetCustomerForMergeWithLock: %w", err)
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) UpdateMergedCustomer(ctx context.Context, merchantId uuid.UUID, customer domain.CustomerForMerge) error {
+	query := `
+	update "Customer"
+	set first_name = $3, last_name = $4, email = $5, phone_number = $6, birthday = $7, note = $8, tags = $9, custom_fields = $10,
+		is_blacklisted = $11, blacklist_reason = $12
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, customer.Id, customer.FirstName, customer.LastName, customer.Email, customer.PhoneNumber,
+		customer.Birthday, customer.Note, customer.Tags, customer.CustomFields, customer.IsBlacklisted, customer.BlacklistReason)
+	if err != nil {
+		return fmt.Errorf("UpdateMergedCustomer: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) NewCustomerMerge(ctx context.Context, merge domain.CustomerMerge) error {
+	query := `
+	insert into "CustomerMerge" (merchant_id, customer_id, employee_id, merged_customer, moved_participants, moved_series_participants)
+	values ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query, merge.MerchantId, merge.CustomerId, merge.EmployeeId, merge.MergedCustomer,
+		merge.MovedParticipants, merge.MovedSeriesParticipants)
+	if err != nil {
+		return fmt.Errorf("NewCustomerMerge: %w", err)
+	}
+
+	return nil
+}
+
+// Keeps the merge history of a customer who is merged into another one
+func (r *customerRepository) MoveCustomerMerges(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error {
+	query := `
+	update "CustomerMerge"
+	set customer_id = $3
+	where merchant_id = $1 and customer_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return fmt.Errorf("MoveCustomerMerges: %w", err)
+	}
+
+	return nil
+}
+
+// The newer consent of the two customers is kept for every channel, as it is the last decision of the person
+func (r *customerRepository) MoveMarketingConsents(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error {
+	query := `
+	insert into "MarketingConsent" (customer_id, channel, is_granted, source, employee_id, updated_at)
+	select $3, mc.channel, mc.is_granted, mc.source, mc.employee_id, mc.updated_at
+	from "MarketingConsent" mc
+	join "Customer" c on c.id = mc.customer_id
+	where c.merchant_id = $1 and mc.customer_id = $2
+	on conflict (customer_id, channel) do update
+	set is_granted = excluded.is_granted, source = excluded.source, employee_id = excluded.employee_id, updated_at = excluded.updated_at
+	where "MarketingConsent".updated_at < excluded.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return fmt.Errorf("MoveMarketingConsents: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerMerges(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.CustomerMerge, error) {
+	query := `
+	select * from "CustomerMerge"
+	where merchant_id = $1 and customer_id = $2
+	order by created_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	merges, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerMerge])
+	if err != nil {
+		return []domain.CustomerMerge{}, fmt.Errorf("GetCustomerMerges: %w", err)
+	}
+
+	return merges, nil
+}
+
+func (r *customerRepository) NewCustomerField(ctx context.Context, field domain.CustomerField) (int, error) {
+	query := `
+	insert into "CustomerField" (merchant_id, name, field_type, options, is_required)
+	values ($1, $2, $3, $4, $5)
+	returning id
+	`
+
+	var fieldId int
+	err := r.db.QueryRow(ctx, query, field.MerchantId, field.Name, field.FieldType, field.Options, field.IsRequired).Scan(&fieldId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCustomerField: %w", err)
+	}
+
+	return fieldId, nil
+}
+
+// The type of a field can not be changed, as the stored values would not match it
+func (r *customerRepository) UpdateCustomerField(ctx context.Context, field domain.CustomerField) error {
+	query := `
+	update "CustomerField"
+	set name = $3, options = $4, is_required = $5
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, field.MerchantId, field.Id, field.Name, field.Options, field.IsRequired)
+	if err != nil {
+		return fmt.Errorf("UpdateCustomerField: %w", err)
+	}
+
+	return nil
+}
+
+// Deletes the field and its values from every customer
+func (r *customerRepository) DeleteCustomerField(ctx context.Context, merchantId uuid.UUID, fieldId int) error {
+	query := `
+	with deleted as (
+		delete from "CustomerField"
+		where merchant_id = $1 and id = $2
+		returning id
+	)
+	update "Customer" c
+	set custom_fields = c.custom_fields - d.id::text
+	from deleted d
+	where c.merchant_id = $1 and c.custom_fields ? d.id::text
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fieldId)
+	if err != nil {
+		return fmt.Errorf("DeleteCustomerField: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerFields(ctx context.Context, merchantId uuid.UUID) ([]domain.CustomerField, error) {
+	query := `
+	select * from "CustomerField"
+	where merchant_id = $1
+	order by id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	fields, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerField])
+	if err != nil {
+		return []domain.CustomerField{}, fmt.Errorf("GetCustomerFields: %w", err)
+	}
+
+	return fields, nil
+}
+
+func (r *customerRepository) NewCustomerSegment(ctx context.Context, segment domain.CustomerSegment) (int, error) {
+	query := `
+	insert into "CustomerSegment" (merchant_id, name, filter)
+	values ($1, $2, $3)
+	returning id
+	`
+
+	var segmentId int
+	err := r.db.QueryRow(ctx, query, segment.MerchantId, segment.Name, segment.Filter).Scan(&segmentId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCustomerSegment: %w", err)
+	}
+
+	return segmentId, nil
+}
+
+func (r *customerRepository) UpdateCustomerSegment(ctx context.Context, segment domain.CustomerSegment) error {
+	query := `
+	update "CustomerSegment"
+	set name = $3, filter = $4
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, segment.MerchantId, segment.Id, segment.Name, segment.Filter)
+	if err != nil {
+		return fmt.Errorf("UpdateCustomerSegment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) DeleteCustomerSegment(ctx context.Context, merchantId uuid.UUID, segmentId int) error {
+	query := `
+	delete from "CustomerSegment"
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, segmentId)
+	if err != nil {
+		return fmt.Errorf("DeleteCustomerSegment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerSegment(ctx context.Context, merchantId uuid.UUID, segmentId int) (domain.CustomerSegment, error) {
+	query := `
+	select * from "CustomerSegment"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, segmentId)
+	segment, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerSegment])
+	if err != nil {
+		return domain.CustomerSegment{}, fmt.Errorf("GetCustomerSegment: %w", err)
+	}
+
+	return segment, nil
+}
+
+func (r *customerRepository) GetCustomerSegments(ctx context.Context, merchantId uuid.UUID) ([]domain.CustomerSegment, error) {
+	query := `
+	select * from "CustomerSegment"
+	where merchant_id = $1
+	order by name
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	segments, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerSegment])
+	if err != nil {
+		return []domain.CustomerSegment{}, fmt.Errorf("GetCustomerSegments: %w", err)
+	}
+
+	return segments, nil
+}
+
+func (r *customerRepository) UpsertMarketingConsent(ctx context.Context, consent domain.MarketingConsent) error {
+	query := `
+	insert into "MarketingConsent" (customer_id, channel, is_granted, source, employee_id)
+	values ($1, $2, $3, $4, $5)
+	on conflict (customer_id, channel) do update
+	set is_granted = excluded.is_granted, source = excluded.source, employee_id = excluded.employee_id, updated_at = now()
+	`
+
+	_, err := r.db.Exec(ctx, query, consent.CustomerId, consent.Channel, consent.IsGranted, consent.Source, consent.EmployeeId)
+	if err != nil {
+		return fmt.Errorf("UpsertMarketingConsent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetMarketingConsents(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.MarketingConsent, error) {
+	query := `
+	select mc.customer_id, mc.channel, mc.is_granted, mc.source, mc.employee_id, mc.updated_at
+	from "MarketingConsent" mc
+	join "Customer" c on mc.customer_id = c.id
+	where c.merchant_id = $1 and c.id = $2
+	order by mc.channel
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	consents, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.MarketingConsent])
+	if err != nil {
+		return []domain.MarketingConsent{}, fmt.Errorf("GetMarketingConsents: %w", err)
+	}
+
+	return consents, nil
+}
+
+func (r *customerRepository) GetConsentedCustomerIds(ctx context.Context, merchantId uuid.UUID, channel types.MarketingChannel) ([]uuid.UUID, error) {
+	query := `
+	select mc.customer_id
+	from "MarketingConsent" mc
+	join "Customer" c on mc.customer_id = c.id
+	where c.merchant_id = $1 and mc.channel = $2 and mc.is_granted
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, channel)
+	customerIds, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return []uuid.UUID{}, fmt.Errorf("GetConsentedCustomerIds: %w", err)
+	}
+
+	return customerIds, nil
+}
+
+func (r *customerRepository) NewCampaign(ctx context.Context, campaign domain.Campaign, recipients []domain.NewCampaignRecipient) (int, error) {
+	query := `
+	with campaign as (
+		insert into "Campaign" (merchant_id, employee_id, name, subject, body, filter)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id
+	), recipients as (
+		insert into "CampaignRecipient" (campaign_id, customer_id, unsubscribe_token)
+		select c.id, r.customer_id, r.unsubscribe_token
+		from campaign c, unnest($7::uuid[], $8::text[]) as r(customer_id, unsubscribe_token)
+	)
+	select id from campaign
+	`
+
+	customerIds := make([]uuid.UUID, len(recipients))
+	tokens := make([]string, len(recipients))
+
+	for i, recipient := range recipients {
+		customerIds[i] = recipient.CustomerId
+		tokens[i] = recipient.UnsubscribeToken
+	}
+
+	var campaignId int
+	err := r.db.QueryRow(ctx, query, campaign.MerchantId, campaign.EmployeeId, campaign.Name, campaign.Subject, campaign.Body,
+		campaign.Filter, customerIds, tokens).Scan(&campaignId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCampaign: %w", err)
+	}
+
+	return campaignId, nil
+}
+
+const campaignWithStatsQuery = `
+	select c.id, c.merchant_id, c.employee_id, c.name, c.subject, c.body, c.status, c.filter, c.created_at, c.finished_at,
+		m.name as merchant_name, count(cr.id) as recipient_count,
+		count(cr.id) filter (where cr.status = 'pending') as pending_count,
+		count(cr.id) filter (where cr.status = 'sent') as sent_count,
+		count(cr.id) filter (where cr.status = 'skipped') as skipped_count,
+		count(cr.id) filter (where cr.status = 'failed') as failed_count
+	from "Campaign" c
+	join "Merchant" m on c.merchant_id = m.id
+	left join "CampaignRecipient" cr on cr.campaign_id = c.id
+`
+
+func (r *customerRepository) GetCampaign(ctx context.Context, merchantId uuid.UUID, campaignId int) (domain.CampaignWithStats, error) {
+	query := campaignWithStatsQuery + `
+	where c.merchant_id = $1 and c.id = $2
+	group by c.id, m.name
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, campaignId)
+	campaign, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CampaignWithStats])
+	if err != nil {
+		return domain.CampaignWithStats{}, fmt.Errorf("GetCampaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+func (r *customerRepository) GetCampaigns(ctx context.Context, merchantId uuid.UUID) ([]domain.CampaignWithStats, error) {
+	query := campaignWithStatsQuery + `
+	where c.merchant_id = $1
+	group by c.id, m.name
+	order by c.created_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	campaigns, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CampaignWithStats])
+	if err != nil {
+		return []domain.CampaignWithStats{}, fmt.Errorf("GetCampaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *customerRepository) UpdateCampaignStatus(ctx context.Context, campaignId int, status types.CampaignStatus) error {
+	query := `
+	with campaign as (
+		update "Campaign"
+		set status = $2, finished_at = case when $2 in ('completed', 'cancelled') then now() end
+		where id = $1
+		returning id, status
+	)
+	update "CampaignRecipient"
+	set status = 'skipped'
+	where campaign_id in (select id from campaign where status = 'cancelled') and status = 'pending'
+	`
+
+	_, err := r.db.Exec(ctx, query, campaignId, status)
+	if err != nil {
+		return fmt.Errorf("UpdateCampaignStatus: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) CountCampaignEmails(ctx context.Context, merchantId uuid.UUID, since time.Time) (int, error) {
+	query := `
+	select count(*)
+	from "CampaignRecipient" cr
+	join "Campaign" c on cr.campaign_id = c.id
+	where c.merchant_id = $1 and ((cr.status = 'sent' and cr.sent_at >= $2) or (cr.status = 'pending' and c.status in ('pending', 'sending')))
+	`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, merchantId, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("CountCampaignEmails: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *customerRepository) CountSentCampaignEmails(ctx context.Context, merchantId uuid.UUID, since time.Time) (int, error) {
+	query := `
+	select count(*)
+	from "CampaignRecipient" cr
+	join "Campaign" c on cr.campaign_id = c.id
+	where c.merchant_id = $1 and cr.status = 'sent' and cr.sent_at >= $2
+	`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, merchantId, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("CountSentCampaignEmails: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *customerRepository) GetPendingCampaignRecipients(ctx context.Context, campaignId int, limit int) ([]domain.CampaignRecipient, error) {
+	query := `
+	select cr.id, cr.customer_id, cr.unsubscribe_token, coalesce(c.email, u.email) as email,
+		coalesce(c.first_name, u.first_name) as first_name, u.language as user_language, coalesce(mc.is_granted, false) as has_consent
+	from "CampaignRecipient" cr
+	join "Customer" c on cr.customer_id = c.id
+	left join "User" u on c.user_id = u.id
+	left join "MarketingConsent" mc on mc.customer_id = c.id and mc.channel = 'email'
+	where cr.campaign_id = $1 and cr.status = 'pending'
+	order by cr.id
+	limit $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, campaignId, limit)
+	recipients, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CampaignRecipient])
+	if err != nil {
+		return []domain.CampaignRecipient{}, fmt.Errorf("GetPendingCampaignRecipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+func (r *customerRepository) UpdateCampaignRecipients(ctx context.Context, results []domain.CampaignRecipientResult) error {
+	query := `
+	update "CampaignRecipient" cr
+	set status = u.status, error = u.error, sent_at = case when u.status = 'sent' then now() end
+	from unnest($1::bigint[], $2::text[], $3::text[])
+	as u(id, status, error)
+	where cr.id = u.id
+	`
+
+	ids := make([]int64, len(results))
+	statuses := make([]string, len(results))
+	messages := make([]*string, len(results))
+
+	for i, result := range results {
+		ids[i] = result.Id
+		statuses[i] = result.Status.String()
+		messages[i] = result.Error
+	}
+
+	_, err := r.db.Exec(ctx, query, ids, statuses, messages)
+	if err != nil {
+		return fmt.Errorf("UpdateCampaignRecipients: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetUnsubscribeTarget(ctx context.Context, unsubscribeToken string) (domain.UnsubscribeTarget, error) {
+	query := `
+	select cr.customer_id, m.name as merchant_name
+	from "CampaignRecipient" cr
+	join "Campaign" c on cr.campaign_id = c.id
+	join "Merchant" m on c.merchant_id = m.id
+	where cr.unsubscribe_token = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, unsubscribeToken)
+	target, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.UnsubscribeTarget])
+	if err != nil {
+		return domain.UnsubscribeTarget{}, fmt.Errorf("GetUnsubscribeTarget: %w", err)
+	}
+
+	return target, nil
+}
+
+func (r *customerRepository) NewCustomerPolicyRule(ctx context.Context, rule domain.CustomerPolicyRule) (int, error) {
+	query := `
+	insert into "CustomerPolicyRule" (merchant_id, event, threshold, period_days, action)
+	values ($1, $2, $3, $4, $5)
+	returning id
+	`
+
+	var ruleId int
+	err := r.db.QueryRow(ctx, query, rule.MerchantId, rule.Event, rule.Threshold, rule.PeriodDays, rule.Action).Scan(&ruleId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCustomerPolicyRule: %w", err)
+	}
+
+	return ruleId, nil
+}
+
+func (r *customerRepository) UpdateCustomerPolicyRule(ctx context.Context, rule domain.CustomerPolicyRule) error {
+	query := `
+	update "CustomerPolicyRule"
+	set event = $3, threshold = $4, period_days = $5, action = $6
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, rule.MerchantId, rule.Id, rule.Event, rule.Threshold, rule.PeriodDays, rule.Action)
+	if err != nil {
+		return fmt.Errorf("UpdateCustomerPolicyRule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) DeleteCustomerPolicyRule(ctx context.Context, merchantId uuid.UUID, ruleId int) error {
+	query := `
+	delete from "CustomerPolicyRule"
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, ruleId)
+	if err != nil {
+		return fmt.Errorf("DeleteCustomerPolicyRule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerRepository) GetCustomerPolicyRules(ctx context.Context, merchantId uuid.UUID) ([]domain.CustomerPolicyRule, error) {
+	query := `
+	select * from "CustomerPolicyRule"
+	where merchant_id = $1
+	order by id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	rules, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerPolicyRule])
+	if err != nil {
+		return []domain.CustomerPolicyRule{}, fmt.Errorf("GetCustomerPolicyRules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// No-shows are counted by the start of the booking, cancellations by the time they were cancelled.
+// Bookings cancelled by the merchant are not counted
+func (r *customerRepository) CountCustomerPolicyEvents(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, event types.CustomerPolicyEvent, since time.Time) (int, error) {
+	query := `
+	select count(*) from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	where b.merchant_id = $1 and coalesce(bp.transferred_to, bp.customer_id) = $2 and b.cancelled_by_merchant_on is null and (
+		($3::text = 'no-show' and bp.status = 'no-show' and b.from_date >= $4) or
+		($3::text = 'cancellation' and bp.status = 'cancelled' and coalesce(bp.cancelled_on, b.from_date) >= $4)
+	)
+	`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, merchantId, customerId, event, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("CountCustomerPolicyEvents: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/stretchr/testify/assert"
+)
+
+// How long the second transaction of a race has to be blocked before the first one commits
+const lockWait = 200 * time.Millisecond
+
+// Connects to the database of TEST_DATABASE_URL, which needs the schema applied,
+// the tests which need a database are skipped without it
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	config, err := pgxpool.ParseConfig(url)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		types, err := conn.LoadTypes(ctx, []string{"price", "_price"})
+		if err != nil {
+			return err
+		}
+
+		conn.TypeMap().RegisterTypes(types)
+		conn.TypeMap().RegisterDefaultPgType(currencyx.Price{}, "price")
+		conn.TypeMap().RegisterDefaultPgType([]currencyx.Price{}, "_price")
+
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(t.Context(), config)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// A merchant with a customer and a booking of the customer, everything is deleted with the merchant after the test
+type fixture struct {
+	merchantId uuid.UUID
+	customerId uuid.UUID
+	bookingId  int
+}
+
+func newFixture(t *testing.T, pool *pgxpool.Pool) fixture {
+	t.Helper()
+
+	ctx := t.Context()
+	f := fixture{merchantId: uuid.New(), customerId: uuid.New()}
+
+	_, err := pool.Exec(ctx, `
+	insert into "Merchant" (id, name, url_name, contact_email, currency_code, subscription_tier)
+	values ($1, 'Test', $2, 'test@example.com', 'EUR', 'free')
+	`, f.merchantId, "test-"+f.merchantId.String()[:8])
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	t.Cleanup(func() {
+		_, err := pool.Exec(context.Background(), `delete from "Merchant" where id = $1`, f.merchantId)
+		assert.NoError(t, err)
+	})
+
+	_, err = pool.Exec(ctx, `insert into "Customer" (id, merchant_id) values ($1, $2)`, f.customerId, f.merchantId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	err = pool.QueryRow(ctx, `
+	with location as (
+		insert into "Location" (merchant_id, geo_point, formatted_location, is_primary)
+		values ($1, 'POINT(0 0)', 'Test street 1', true)
+		returning id
+	)
+	insert into "Booking" (booking_type, merchant_id, location_id, from_date, to_date, service_name, price_per_person, total_price,
+		formatted_location, min_participants, max_participants, current_participants)
+	select 'appointment', $1, id, now() + interval '1 day', now() + interval '1 day 1 hour', 'Haircut', row(10, 'EUR')::price,
+		row(10, 'EUR')::price, 'Test street 1', 1, 1, 1
+	from location
+	returning id
+	`, f.merchantId).Scan(&f.bookingId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	_, err = pool.Exec(ctx, `
+	insert into "BookingParticipant" (status, booking_id, customer_id)
+	values ('booked', $1, $2)
+	`, f.bookingId, f.customerId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return f
+}
+
+// Runs first in an open transaction, then second in another one which has to wait for the first to commit.
+// Returns the errors of both, the second transaction is committed if it succeeded
+func race(t *testing.T, pool *pgxpool.Pool, first func(tx pgx.Tx) error, second func(tx pgx.Tx) error) (error, error) {
+	t.Helper()
+
+	ctx := t.Context()
+
+	tx, err := pool.Begin(ctx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+
+	firstErr := first(tx)
+
+	done := make(chan error, 1)
+	go func() {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer tx.Rollback(ctx) // nolint:errcheck
+
+		err = second(tx)
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		done <- err
+	}()
+
+	select {
+	case secondErr := <-done:
+		assert.Fail(t, "the second transaction did not wait for the first one")
+		return firstErr, secondErr
+	case <-time.After(lockWait):
+	}
+
+	err = tx.Commit(ctx)
+	assert.NoError(t, err)
+
+	return firstErr, <-done
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type giftCardRepository struct {
+	db db.DBTX
+}
+
+func NewGiftCardRepository(db db.DBTX) domain.GiftCardRepository {
+	return &giftCardRepository{db: db}
+}
+
+func (r *giftCardRepository) WithTx(tx db.DBTX) domain.GiftCardRepository {
+	return &giftCardRepository{db: tx}
+}
+
+func (r *giftCardRepository) NewGiftCard(ctx context.Context, giftCard domain.NewGiftCard) (int, error) {
+	query := `
+	with card as (
+		insert into "GiftCard" (merchant_id, code, initial_value, balance, expires_at, issued_by, recipient_name,
+			recipient_email, message)
+		values ($1, $2, $3, $3, $4, $5, $6, $7, $8)
+		returning id, initial_value
+	)
+	insert into "GiftCardTransaction" (gift_card_id, amount, employee_id)
+	select id, initial_value, $5 from card
+	returning gift_card_id
+	`
+
+	var giftCardId int
+	err := r.db.QueryRow(ctx, query, giftCard.MerchantId, giftCard.Code, giftCard.InitialValue, giftCard.ExpiresAt,
+		giftCard.IssuedBy, giftCard.RecipientName, giftCard.RecipientEmail, giftCard.Message).Scan(&giftCardId)
+	if err != nil {
+		return 0, fmt.Errorf("NewGiftCard: %w", err)
+	}
+
+	return giftCardId, nil
+}
+
+const giftCardColumns = `g.id, g.merchant_id, g.code, g.initial_value, g.balance, g.expires_at, g.issued_by,
+	g.recipient_name, g.recipient_email, g.message, g.created_at`
+
+func (r *giftCardRepository) GetGiftCard(ctx context.Context, merchantId uuid.UUID, giftCardId int) (domain.GiftCard, error) {
+	query := `
+	select ` + giftCardColumns + `
+	from "GiftCard" g
+	where g.merchant_id = $1 and g.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, giftCardId)
+	giftCard, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.GiftCard])
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("GetGiftCard: %w", err)
+	}
+
+	return giftCard, nil
+}
+
+func (r *giftCardRepository) GetGiftCards(ctx context.Context, merchantId uuid.UUID) ([]domain.GiftCard, error) {
+	query := `
+	select ` + giftCardColumns + `
+	from "GiftCard" g
+	where g.merchant_id = $1
+	order by g.created_at desc, g.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	giftCards, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.GiftCard])
+	if err != nil {
+		return []domain.GiftCard{}, fmt.Errorf("GetGiftCards: %w", err)
+	}
+
+	return giftCards, nil
+}
+
+func (r *giftCardRepository) GetGiftCardByCode(ctx context.Context, merchantId uuid.UUID, code string) (domain.GiftCard, error) {
+	query := `
+	select ` + giftCardColumns + `
+	from "GiftCard" g
+	where g.merchant_id = $1 and g.code = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, code)
+	giftCard, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.GiftCard])
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("GetGiftCardByCode: %w", err)
+	}
+
+	return giftCard, nil
+}
+
+func (r *giftCardRepository) GetGiftCardByCodeForUpdate(ctx context.Context, merchantId uuid.UUID, code string) (domain.GiftCard, error) {
+	query := `
+	select ` + giftCardColumns + `
+	from "GiftCard" g
+	where g.merchant_id = $1 and g.code = $2
+	for update
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, code)
+	giftCard, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.GiftCard])
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("GetGiftCardByCodeForUpdate: %w", err)
+	}
+
+	return giftCard, nil
+}
+
+func (r *giftCardRepository) GetGiftCardTransactions(ctx context.Context, giftCardId int) ([]domain.GiftCardTransaction, error) {
+	query := `
+	select id, gift_card_id, amount, booking_id, employee_id, created_at
+	from "GiftCardTransaction"
+	where gift_card_id = $1
+	order by created_at, id
+	`
+
+	rows, _ := r.db.Query(ctx, query, giftCardId)
+	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.GiftCardTransaction])
+	if err != nil {
+		return []domain.GiftCardTransaction{}, fmt.Errorf("GetGiftCardTransactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (r *giftCardRepository) GetGiftCardForEmail(ctx context.Context, giftCardId int) (domain.GiftCardForEmail, error) {
+	query := `
+	select g.id, g.code, g.initial_value, g.expires_at, g.recipient_name, g.recipient_email, g.message,
+		m.name as merchant_name, m.url_name as merchant_url_name, m.timezone
+	from "GiftCard" g
+	join "Merchant" m on m.id = g.merchant_id
+	where g.id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, giftCardId)
+	giftCard, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.GiftCardForEmail])
+	if err != nil {
+		return domain.GiftCardForEmail{}, fmt.Errorf("GetGiftCardForEmail: %w", err)
+	}
+
+	return giftCard, nil
+}
+
+func (r *giftCardRepository) GetGiftCardBookingForUpdate(ctx context.Context, merchantId uuid.UUID, bookingId int) (domain.GiftCardBooking, error) {
+	query := `
+	select b.id, b.status, b.employee_id, b.total_price,
+		row(coalesce((
+			select -sum((t.amount).number)
+			from "GiftCardTransaction" t
+			where t.booking_id = b.id
+		), 0), (b.total_price).currency)::price as redeemed
+	from "Booking" b
+	where b.merchant_id = $1 and b.id = $2
+	for update of b
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, bookingId)
+	booking, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.GiftCardBooking])
+	if err != nil {
+		return domain.GiftCardBooking{}, fmt.Errorf("GetGiftCardBookingForUpdate: %w", err)
+	}
+
+	return booking, nil
+}
+
+func (r *giftCardRepository) RedeemGiftCard(ctx context.Context, redemption domain.GiftCardRedemption) error {
+	query := `
+	with card as (
+		update "GiftCard"
+		set balance = row((balance).number - ($2::price).number, (balance).currency)::price
+		where id = $1 and (balance).currency = ($2::price).currency and (balance).number >= ($2::price).number
+		returning id
+	)
+	insert into "GiftCardTransaction" (gift_card_id, amount, booking_id, employee_id, customer_id)
+	select id, row(-($2::price).number, ($2::price).currency)::price, $3, $4, $5 from card
+	`
+
+	tag, err := r.db.Exec(ctx, query, redemption.GiftCardId, redemption.Amount, redemption.BookingId, redemption.EmployeeId,
+		redemption.CustomerId)
+	if err != nil {
+		return fmt.Errorf("RedeemGiftCard: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("RedeemGiftCard: the balance of the gift card is not enough")
+	}
+
+	return nil
+}
+
+func (r *giftCardRepository) RefundGiftCardRedemptions(ctx context.Context, bookingIds []int, customerIds []uuid.UUID) error {
+	query := `
+	with redeemed as (
+		select gift_card_id, booking_id, customer_id, -sum((amount).number) as number, min((amount).currency) as currency
+		from "GiftCardTransaction"
+		where booking_id = any($1::int[]) and ($2::uuid[] is null or customer_id = any($2::uuid[]))
+		group by gift_card_id, booking_id, customer_id
+		having sum((amount).number) < 0
+	), refunded as (
+		insert into "GiftCardTransaction" (gift_card_id, amount, booking_id, customer_id)
+		select gift_card_id, row(number, currency)::price, booking_id, customer_id from redeemed
+		returning gift_card_id, (amount).number as number
+	)
+	update "GiftCard" g
+	set balance = row((g.balance).number + r.number, (g.balance).currency)::price
+	from (select gift_card_id, sum(number) as number from refunded group by gift_card_id) r
+	where g.id = r.gift_card_id
+	`
+
+	_, err := r.db.Exec(ctx, query, bookingIds, customerIds)
+	if err != nil {
+		return fmt.Errorf("RefundGiftCardRedemptions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *giftCardRepository) MergeCustomerGiftCardTransactions(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID,
+	toCustomerId uuid.UUID) error {
+	query := `
+	update "GiftCardTransaction" gct
+	set customer_id = $3
+	from "GiftCard" g
+	where g.id = gct.gift_card_id and g.merchant_id = $1 and gct.customer_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return fmt.Errorf("MergeCustomerGiftCardTransactions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedeemGiftCard(t *testing.T) {
+	pool := testPool(t)
+	f := newFixture(t, pool)
+	repo := NewGiftCardRepository(pool)
+
+	giftCardId, err := repo.NewGiftCard(t.Context(), domain.NewGiftCard{
+		MerchantId:   f.merchantId,
+		Code:         "TEST-0000-0000-0001",
+		InitialValue: currencyxtest.Price(t, "100", "EUR"),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	redemption := func(number string, currencyCode string) domain.GiftCardRedemption {
+		return domain.GiftCardRedemption{
+			GiftCardId: giftCardId,
+			Amount:     currencyxtest.Price(t, number, currencyCode),
+			BookingId:  f.bookingId,
+			CustomerId: &f.customerId,
+		}
+	}
+
+	t.Run("more than the balance", func(t *testing.T) {
+		assert.Error(t, repo.RedeemGiftCard(t.Context(), redemption("100.01", "EUR")))
+	})
+
+	t.Run("other currency", func(t *testing.T) {
+		assert.Error(t, repo.RedeemGiftCard(t.Context(), redemption("10", "HUF")))
+	})
+
+	t.Run("double spend", func(t *testing.T) {
+		firstErr, secondErr := race(t, pool, func(tx pgx.Tx) error {
+			return repo.WithTx(tx).RedeemGiftCard(t.Context(), redemption("60", "EUR"))
+		}, func(tx pgx.Tx) error {
+			return repo.WithTx(tx).RedeemGiftCard(t.Context(), redemption("60", "EUR"))
+		})
+		assert.NoError(t, firstErr)
+		assert.Error(t, secondErr, "the balance was spent by the first transaction")
+
+		giftCard, err := repo.GetGiftCard(t.Context(), f.merchantId, giftCardId)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, giftCard.Balance.Equal(currencyxtest.Price(t, "40", "EUR").Amount))
+
+		transactions, err := repo.GetGiftCardTransactions(t.Context(), giftCardId)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, transactions, 2, "the issue and a single redemption")
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		for range 2 {
+			err := repo.RefundGiftCardRedemptions(t.Context(), []int{f.bookingId}, nil)
+			assert.NoError(t, err)
+		}
+
+		giftCard, err := repo.GetGiftCard(t.Context(), f.merchantId, giftCardId)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, giftCard.Balance.Equal(currencyxtest.Price(t, "100", "EUR").Amount), "a redemption is only refunded once")
+	})
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type intakeRepository struct {
+	db db.DBTX
+}
+
+func NewIntakeRepository(db db.DBTX) domain.IntakeRepository {
+	return &intakeRepository{db: db}
+}
+
+func (r *intakeRepository) WithTx(tx db.DBTX) domain.IntakeRepository {
+	return &intakeRepository{db: tx}
+}
+
+func (r *intakeRepository) NewIntakeForm(ctx context.Context, form domain.IntakeForm) (int, error) {
+	query := `
+	with new_form as (
+		insert into "IntakeForm" (merchant_id, name, mode, questions)
+		values ($1, $2, $3, $4)
+		returning id
+	), attached as (
+		insert into "ServiceIntakeForm" (service_id, form_id)
+		select s.id, (select id from new_form)
+		from "Service" s
+		where s.merchant_id = $1 and s.id = any($5)
+		on conflict (service_id) do update set form_id = excluded.form_id
+	)
+	select id from new_form
+	`
+
+	var formId int
+	err := r.db.QueryRow(ctx, query, form.MerchantId, form.Name, form.Mode, form.Questions, form.ServiceIds).Scan(&formId)
+	if err != nil {
+		return 0, fmt.Errorf("NewIntakeForm: %w", err)
+	}
+
+	return formId, nil
+}
+
+func (r *intakeRepository) UpdateIntakeForm(ctx context.Context, form domain.IntakeForm) error {
+	query := `
+	with updated as (
+		update "IntakeForm"
+		set name = $3, mode = $4, questions = $5, updated_at = now()
+		where merchant_id = $1 and id = $2
+		returning id
+	), detached as (
+		delete from "ServiceIntakeForm"
+		where form_id in (select id from updated) and service_id <> all($6)
+	)
+	insert into "ServiceIntakeForm" (service_id, form_id)
+	select s.id, u.id
+	from "Service" s
+	cross join updated u
+	where s.merchant_id = $1 and s.id = any($6)
+	on conflict (service_id) do update set form_id = excluded.form_id
+	`
+
+	_, err := r.db.Exec(ctx, query, form.MerchantId, form.Id, form.Name, form.Mode, form.Questions, form.ServiceIds)
+	if err != nil {
+		return fmt.Errorf("UpdateIntakeForm: %w", err)
+	}
+
+	return nil
+}
+
+// The responses keep a copy of the form, so they are not deleted with it
+func (r *intakeRepository) DeleteIntakeForm(ctx context.Context, merchantId uuid.UUID, formId int) error {
+	query := `
+	delete from "IntakeForm"
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, formId)
+	if err != nil {
+		return fmt.Errorf("DeleteIntakeForm: %w", err)
+	}
+
+	return nil
+}
+
+const intakeFormColumns = `f.id, f.merchant_id, f.name, f.mode, f.questions, f.created_at, f.updated_at,
+	coalesce((select array_agg(sif.service_id order by sif.service_id) from "ServiceIntakeForm" sif where sif.form_id = f.id), '{}') as service_ids`
+
+func (r *intakeRepository) GetIntakeForm(ctx context.Context, merchantId uuid.UUID, formId int) (domain.IntakeForm, error) {
+	query := `
+	select ` + intakeFormColumns + `
+	from "IntakeForm" f
+	where f.merchant_id = $1 and f.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, formId)
+	form, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.IntakeForm])
+	if err != nil {
+		return domain.IntakeForm{}, fmt.Errorf("GetIntakeForm: %w", err)
+	}
+
+	return form, nil
+}
+
+func (r *intakeRepository) GetIntakeForms(ctx context.Context, merchantId uuid.UUID) ([]domain.IntakeForm, error) {
+	query := `
+	select ` + intakeFormColumns + `
+	from "IntakeForm" f
+	where f.merchant_id = $1
+	order by f.name
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	forms, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.IntakeForm])
+	if err != nil {
+		return []domain.IntakeForm{}, fmt.Errorf("GetIntakeForms: %w", err)
+	}
+
+	return forms, nil
+}
+
+func (r *intakeRepository) GetIntakeFormForService(ctx context.Context, merchantId uuid.UUID, serviceId int) (domain.IntakeForm, error) {
+	query := `
+	select ` + intakeFormColumns + `
+	from "IntakeForm" f
+	join "ServiceIntakeForm" sif on sif.form_id = f.id
+	where f.merchant_id = $1 and sif.service_id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, serviceId)
+	form, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.IntakeForm])
+	if err != nil {
+		return domain.IntakeForm{}, fmt.Errorf("GetIntakeFormForService: %w", err)
+	}
+
+	return form, nil
+}
+
+func (r *intakeRepository) HasUserAnsweredIntakeForm(ctx context.Context, formId int, userId uuid.UUID) (bool, error) {
+	query := `
+	select exists (
+		select 1 from "IntakeResponse" ir
+		join "BookingParticipant" bp on bp.id = ir.participant_id
+		join "Customer" c on c.id = coalesce(bp.transferred_to, bp.customer_id)
+		where ir.form_id = $1 and c.user_id = $2
+	)
+	`
+
+	var answered bool
+	err := r.db.QueryRow(ctx, query, formId, userId).Scan(&answered)
+	if err != nil {
+		return false, fmt.Errorf("HasUserAnsweredIntakeForm: %w", err)
+	}
+
+	return answered, nil
+}
+
+func (r *intakeRepository) NewIntakeResponse(ctx context.Context, bookingId int, customerId uuid.UUID, response domain.IntakeResponse) error {
+	query := `
+	insert into "IntakeResponse" (merchant_id, form_id, participant_id, form_name, questions, answers, signature, signed_at)
+	select $1, $2, bp.id, $5, $6, $7, $8, $9
+	from "BookingParticipant" bp
+	where bp.booking_id = $3 and bp.customer_id = $4
+	`
+
+	tag, err := r.db.Exec(ctx, query, response.MerchantId, response.FormId, bookingId, customerId, response.FormName,
+		response.Questions, response.Answers, response.Signature, response.SignedAt)
+	if err != nil {
+		return fmt.Errorf("NewIntakeResponse: %w", err)
+	}
+
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("NewIntakeResponse: participant of booking %d not found", bookingId)
+	}
+
+	return nil
+}
+
+func (r *intakeRepository) GetCustomerIntakeResponses(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.CustomerIntakeResponse, error) {
+	query := `
+	select ir.id, ir.merchant_id, ir.form_id, ir.participant_id, ir.form_name, ir.questions, ir.answers, ir.signature, ir.signed_at,
+		ir.created_at, b.id as booking_id, b.employee_id, b.service_name, b.from_date
+	from "IntakeResponse" ir
+	join "BookingParticipant" bp on bp.id = ir.participant_id
+	join "Booking" b on b.id = bp.booking_id
+	where ir.merchant_id = $1 and coalesce(bp.transferred_to, bp.customer_id) = $2
+	order by b.from_date desc, ir.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	responses, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerIntakeResponse])
+	if err != nil {
+		return []domain.CustomerIntakeResponse{}, fmt.Errorf("GetCustomerIntakeResponses: %w", err)
+	}
+
+	return responses, nil
+}
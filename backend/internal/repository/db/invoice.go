@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type invoiceRepository struct {
+	db db.DBTX
+}
+
+func NewInvoiceRepository(db db.DBTX) domain.InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+func (r *invoiceRepository) WithTx(tx db.DBTX) domain.InvoiceRepository {
+	return &invoiceRepository{db: tx}
+}
+
+func (r *invoiceRepository) GetInvoiceSettings(ctx context.Context, merchantId uuid.UUID) (domain.InvoiceSettings, error) {
+	query := `
+	select m.name as merchant_name, m.contact_email, s.legal_name, s.tax_number, s.registration_number, s.address,
+		coalesce(s.prefix, 'INV') as prefix, s.footer, coalesce(s.next_sequence, 1) as next_sequence
+	from "Merchant" m
+	left join "InvoiceSettings" s on s.merchant_id = m.id
+	where m.id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	settings, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.InvoiceSettings])
+	if err != nil {
+		return domain.InvoiceSettings{}, fmt.Errorf("GetInvoiceSettings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *invoiceRepository) UpdateInvoiceSettings(ctx context.Context, merchantId uuid.UUID, settings domain.UpdateInvoiceSettings) error {
+	query := `
+	insert into "InvoiceSettings" (merchant_id, legal_name, tax_number, registration_number, address, prefix, footer)
+	values ($1, $2, $3, $4, $5, $6, $7)
+	on conflict (merchant_id) do update
+	set legal_name = excluded.legal_name, tax_number = excluded.tax_number, registration_number = excluded.registration_number,
+		address = excluded.address, prefix = excluded.prefix, footer = excluded.footer
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, settings.LegalName, settings.TaxNumber, settings.RegistrationNumber,
+		settings.Address, settings.Prefix, settings.Footer)
+	if err != nil {
+		return fmt.Errorf("UpdateInvoiceSettings: %w", err)
+	}
+
+	return nil
+}
+
+func (r *invoiceRepository) NextInvoiceSequence(ctx context.Context, merchantId uuid.UUID) (int, error) {
+	query := `
+	insert into "InvoiceSettings" (merchant_id, next_sequence)
+	values ($1, 2)
+	on conflict (merchant_id) do update
+	set next_sequence = "InvoiceSettings".next_sequence + 1
+	returning next_sequence - 1
+	`
+
+	var sequence int
+	err := r.db.QueryRow(ctx, query, merchantId).Scan(&sequence)
+	if err != nil {
+		return 0, fmt.Errorf("NextInvoiceSequence: %w", err)
+	}
+
+	return sequence, nil
+}
+
+func (r *invoiceRepository) GetUninvoicedParticipants(ctx context.Context, bookingId int) ([]domain.InvoiceableParticipant, error) {
+	query := `
+	select bp.id as participant_id, b.id as booking_id, b.merchant_id, c.id as customer_id,
+		coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
+		coalesce(c.email, u.email) as email, b.service_id, b.service_name,
+		coalesce(bp.price_per_person, b.price_per_person) as price_per_person, b.price_type,
+		b.tax_name, b.tax_percent::text as tax_percent, b.from_date, bp.status
+	from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	join "Customer" c on c.id = coalesce(bp.transferred_to, bp.customer_id)
+	left join "User" u on u.id = c.user_id
+	left join "Invoice" i on i.participant_id = bp.id
+	where b.id = $1 and i.id is null and b.cancelled_by_merchant_on is null
+		and (bp.status = 'completed' or (b.status = 'completed' and bp.status in ('booked', 'confirmed')))
+		-- participants who paid with a pass credit were already paid for when the pass was sold
+		and not exists (
+			select 1 from "PassUsage" pu
+			join "CustomerPass" cp on cp.id = pu.customer_pass_id
+			where pu.booking_id = b.id and cp.customer_id = bp.customer_id and pu.refunded_at is null
+		)
+	order by bp.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, bookingId)
+	participants, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.InvoiceableParticipant])
+	if err != nil {
+		return []domain.InvoiceableParticipant{}, fmt.Errorf("GetUninvoicedParticipants: %w", err)
+	}
+
+	return participants, nil
+}
+
+func (r *invoiceRepository) NewInvoice(ctx context.Context, invoice domain.NewInvoice) (int, error) {
+	query := `
+	insert into "Invoice" (merchant_id, booking_id, participant_id, customer_id, sequence, invoice_number, issued_at, service_date,
+		seller_name, seller_tax_number, seller_registration_number, seller_address, seller_email, buyer_name, buyer_email,
+		net_total, tax_total, total, footer, file)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+	returning id
+	`
+
+	var invoiceId int
+	err := r.db.QueryRow(ctx, query, invoice.MerchantId, invoice.BookingId, invoice.ParticipantId, invoice.CustomerId, invoice.Sequence,
+		invoice.InvoiceNumber, invoice.IssuedAt, invoice.ServiceDate, invoice.Seller.Name, invoice.Seller.TaxNumber,
+		invoice.Seller.RegistrationNumber, invoice.Seller.Address, invoice.Seller.Email, invoice.BuyerName, invoice.BuyerEmail,
+		invoice.NetTotal, invoice.TaxTotal, invoice.Total, invoice.Footer, invoice.File).Scan(&invoiceId)
+	if err != nil {
+		return 0, fmt.Errorf("NewInvoice: %w", err)
+	}
+
+	if len(invoice.Lines) == 0 {
+		return invoiceId, nil
+	}
+
+	descriptions := make([]string, len(invoice.Lines))
+	quantities := make([]int, len(invoice.Lines))
+	unitPrices := make([]currencyx.Price, len(invoice.Lines))
+	netAmounts := make([]currencyx.Price, len(invoice.Lines))
+	taxNames := make([]*string, len(invoice.Lines))
+	taxPercents := make([]*string, len(invoice.Lines))
+	taxAmounts := make([]currencyx.Price, len(invoice.Lines))
+	grossAmounts := make([]currencyx.Price, len(invoice.Lines))
+
+	for i, line := range invoice.Lines {
+		descriptions[i] = line.Description
+		quantities[i] = line.Quantity
+		unitPrices[i] = line.UnitPrice
+		netAmounts[i] = line.NetAmount
+		taxNames[i] = line.TaxName
+		taxPercents[i] = line.TaxPercent
+		taxAmounts[i] = line.TaxAmount
+		grossAmounts[i] = line.GrossAmount
+	}
+
+	linesQuery := `
+	insert into "InvoiceLine" (invoice_id, description, quantity, unit_price, net_amount, tax_name, tax_percent, tax_amount, gross_amount)
+	select $1, unnest($2::text[]), unnest($3::int[]), unnest($4::price[]), unnest($5::price[]), unnest($6::text[]),
+		unnest($7::text[])::numeric, unnest($8::price[]), unnest($9::price[])
+	`
+
+	_, err = r.db.Exec(ctx, linesQuery, invoiceId, descriptions, quantities, unitPrices, netAmounts, taxNames, taxPercents,
+		taxAmounts, grossAmounts)
+	if err != nil {
+		return 0, fmt.Errorf("NewInvoice: %w", err)
+	}
+
+	return invoiceId, nil
+}
+
+func (r *invoiceRepository) SetInvoiceEmailed(ctx context.Context, invoiceId int, emailedAt time.Time) error {
+	query := `
+	update "Invoice"
+	set emailed_at = $2
+	where id = $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, invoiceId, emailedAt)
+	if err != nil {
+		return fmt.Errorf("SetInvoiceEmailed: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("SetInvoiceEmailed: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+const invoiceColumns = `i.id, i.merchant_id, i.booking_id, i.participant_id, i.customer_id, i.sequence, i.invoice_number, i.issued_at,
+	i.service_date, i.seller_name, i.seller_tax_number, i.seller_registration_number, i.seller_address, i.seller_email, i.buyer_name,
+	i.buyer_email, i.net_total, i.tax_total, i.total, i.footer, i.emailed_at`
+
+func (r *invoiceRepository) GetInvoice(ctx context.Context, merchantId uuid.UUID, invoiceId int) (domain.Invoice, error) {
+	query := `
+	select ` + invoiceColumns + `
+	from "Invoice" i
+	where i.merchant_id = $1 and i.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, invoiceId)
+	invoice, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Invoice])
+	if err != nil {
+		return domain.Invoice{}, fmt.Errorf("GetInvoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) GetInvoices(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]domain.Invoice, error) {
+	query := `
+	select ` + invoiceColumns + `
+	from "Invoice" i
+	where i.merchant_id = $1 and i.issued_at >= $2 and i.issued_at < $3
+	order by i.sequence desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, from, to)
+	invoices, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Invoice])
+	if err != nil {
+		return []domain.Invoice{}, fmt.Errorf("GetInvoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) GetInvoiceLines(ctx context.Context, invoiceId int) ([]domain.InvoiceLine, error) {
+	query := `
+	select description, quantity, unit_price, net_amount, tax_name, tax_percent::text as tax_percent, tax_amount, gross_amount
+	from "InvoiceLine"
+	where invoice_id = $1
+	order by id
+	`
+
+	rows, _ := r.db.Query(ctx, query, invoiceId)
+	lines, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.InvoiceLine])
+	if err != nil {
+		return []domain.InvoiceLine{}, fmt.Errorf("GetInvoiceLines: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *invoiceRepository) GetInvoiceFile(ctx context.Context, merchantId uuid.UUID, invoiceId int) ([]byte, error) {
+	query := `
+	select file from "Invoice"
+	where merchant_id = $1 and id = $2
+	`
+
+	var file []byte
+	err := r.db.QueryRow(ctx, query, merchantId, invoiceId).Scan(&file)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvoiceFile: %w", err)
+	}
+
+	return file, nil
+}
+
+func (r *invoiceRepository) GetInvoiceForEmail(ctx context.Context, invoiceId int) (domain.InvoiceForEmail, error) {
+	query := `
+	select i.id, i.invoice_number, i.seller_name, i.buyer_name, i.buyer_email, i.service_date, i.total, m.timezone,
+		u.language as user_language, i.emailed_at, i.file
+	from "Invoice" i
+	join "Merchant" m on m.id = i.merchant_id
+	left join "Customer" c on c.id = i.customer_id
+	left join "User" u on u.id = c.user_id
+	where i.id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, invoiceId)
+	invoice, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.InvoiceForEmail])
+	if err != nil {
+		return domain.InvoiceForEmail{}, fmt.Errorf("GetInvoiceForEmail: %w", err)
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) GetInvoiceForUser(ctx context.Context, userId uuid.UUID, bookingId int) (domain.Invoice, error) {
+	query := `
+	select ` + invoiceColumns + `
+	from "Invoice" i
+	join "Customer" c on c.id = i.customer_id
+	where c.user_id = $1 and i.booking_id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId, bookingId)
+	invoice, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Invoice])
+	if err != nil {
+		return domain.Invoice{}, fmt.Errorf("GetInvoiceForUser: %w", err)
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) MergeCustomerInvoices(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error {
+	query := `
+	update "Invoice"
+	set customer_id = $3
+	where merchant_id = $1 and customer_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return fmt.Errorf("MergeCustomerInvoices: %w", err)
+	}
+
+	return nil
+}
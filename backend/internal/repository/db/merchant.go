@@ -2,19 +2,14 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
-	"github.com/bojanz/currency"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
-	"github.com/miketsu-inc/reservations/backend/internal/utils"
-	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 )
 
@@ -278,146 +273,6 @@ func (r *merchantRepository) GetMerchantNameAndLocation(ctx context.Context, mer
 	return name, loc, nil
 }
 
-func (r *merchantRepository) GetDashboardStats(ctx context.Context, merchantId uuid.UUID, startDate, endDate, prevStartDate time.Time) (domain.DashboardStatistics, error) {
-	query := `
-	WITH participant as (
-		SELECT
-			booking_id,
-			BOOL_OR(status = 'cancelled') as cancelled_by_user
-		FROM "BookingParticipant"
-		GROUP BY booking_id
-	),
-	base AS (
-		SELECT
-			to_date,
-			(total_price).number as price,
-			(total_price).currency as currency,
-			EXTRACT(EPOCH FROM (to_date - from_date)) / 60 AS duration,
-			COALESCE(bp.cancelled_by_user, FALSE) as cancelled_by_user,
-			(b.status in ('cancelled')) as cancelled
-		FROM "Booking" b
-		left join participant bp on bp.booking_id = b.id
-		WHERE merchant_id = $1
-		order by b.id
-	),
-	current AS (
-		SELECT
-			SUM(price) FILTER (WHERE NOT cancelled) AS revenue,
-			COUNT(*) FILTER (WHERE NOT cancelled) AS bookings,
-			COUNT(*) FILTER (WHERE cancelled_by_user) AS cancellations,
-			AVG(duration) FILTER (WHERE NOT cancelled) AS avg_duration
-		FROM base
-		WHERE to_date >= $2 AND to_date < $3
-	),
-	current_totals AS (
-		SELECT
-			COALESCE(SUM(revenue), 0) AS revenue_sum,
-			COALESCE(SUM(bookings), 0) AS bookings,
-			COALESCE(SUM(cancellations), 0) AS cancellations,
-			COALESCE(CAST(AVG(avg_duration) AS INTEGER), 0) AS average_duration
-		FROM current
-	),
-	previous AS (
-		SELECT
-			COALESCE(SUM(price) FILTER (WHERE NOT cancelled), 0) AS revenue_sum,
-			COUNT(*) FILTER (WHERE NOT cancelled) AS bookings,
-			COUNT(*) FILTER (WHERE cancelled_by_user) AS cancellations,
-			CAST(AVG(duration) FILTER (WHERE NOT cancelled) AS INTEGER) AS average_duration
-		FROM base
-		WHERE to_date >= $4 AND to_date < $5
-	),
-	single_currency as (
-		select currency
-		from base
-		group by currency
-		order by count(*) desc, currency asc
-		limit 1
-	)
-	SELECT
-		ct.revenue_sum, p.revenue_sum,
-		ct.bookings, p.bookings,
-		ct.cancellations, p.cancellations,
-		COALESCE(ct.average_duration, 0), COALESCE(p.average_duration, 0),
-		sc.currency
-	FROM current_totals ct
-	cross join previous p
-	cross join single_currency sc
-	`
-
-	var stats domain.DashboardStatistics
-
-	var (
-		currRevenue, prevRevenue             int
-		currBookings, prevBookings           int
-		currCancellations, prevCancellations int
-		currAvgDuration, prevAvgDuration     int
-		curr                                 string
-	)
-
-	err := r.db.QueryRow(ctx, query, merchantId, startDate, endDate, prevStartDate, startDate.AddDate(0, 0, 1)).Scan(
-		&currRevenue, &prevRevenue,
-		&currBookings, &prevBookings,
-		&currCancellations, &prevCancellations,
-		&currAvgDuration, &prevAvgDuration,
-		&curr,
-	)
-	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			return domain.DashboardStatistics{}, fmt.Errorf("GetDashboardStats: %w", err)
-		}
-	}
-
-	var formattedRevenue string
-
-	// if no rows are returned
-	if curr != "" {
-		amount, err := currency.NewAmount(strconv.Itoa(currRevenue), curr)
-		if err != nil {
-			return domain.DashboardStatistics{}, fmt.Errorf("GetDashboardStats: %w", err)
-		}
-		formattedRevenue = currencyx.Format(amount)
-	} else {
-		formattedRevenue = "0"
-	}
-
-	stats.RevenueSum = formattedRevenue
-	stats.Bookings = currBookings
-	stats.Cancellations = currCancellations
-	stats.AverageDuration = currAvgDuration
-
-	stats.RevenueChange = utils.CalculatePercentChange(prevRevenue, currRevenue)
-	stats.BookingsChange = utils.CalculatePercentChange(prevBookings, currBookings)
-	stats.CancellationsChange = utils.CalculatePercentChange(prevCancellations, currCancellations) * -1
-	stats.AverageDurationChange = utils.CalculatePercentChange(prevAvgDuration, currAvgDuration)
-
-	return stats, nil
-}
-
-// TODO: in the future only completed bookings should count towards revenue
-func (r *merchantRepository) GetRevenueStats(ctx context.Context, merchantId uuid.UUID, startDate, endDate time.Time) ([]domain.RevenueStat, error) {
-	query := `
-	SELECT
-		DATE(bookings.from_date) AS day,
-		COALESCE(SUM(bookings.price), 0) AS value
-	FROM (
-		select b.from_date, (b.total_price).number as price
-		from "Booking" b
-		where b.merchant_id = $1 AND b.from_date >= $2 AND b.from_date < $3 and b.status not in ('cancelled')
-		order by b.id
-	) as bookings
-	GROUP BY day
-	ORDER BY day
-	`
-
-	rows, _ := r.db.Query(ctx, query, merchantId, startDate, endDate)
-	revenue, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.RevenueStat])
-	if err != nil {
-		return []domain.RevenueStat{}, fmt.Errorf("GetRevenueStats: %w", err)
-	}
-
-	return revenue, nil
-}
-
 func (r *merchantRepository) NewBusinessHours(ctx context.Context, merchantId uuid.UUID, businessHours domain.BusinessHours) error {
 	query := `
 	insert into "BusinessHours" (merchant_id, day_of_week, start_time, end_time)
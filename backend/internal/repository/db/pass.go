@@ -0,0 +1,323 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type passRepository struct {
+	db db.DBTX
+}
+
+func NewPassRepository(db db.DBTX) domain.PassRepository {
+	return &passRepository{db: db}
+}
+
+func (r *passRepository) WithTx(tx db.DBTX) domain.PassRepository {
+	return &passRepository{db: tx}
+}
+
+func (r *passRepository) NewPassProduct(ctx context.Context, product domain.PassProduct) (int, error) {
+	query := `
+	with product as (
+		insert into "PassProduct" (merchant_id, name, credits, validity_days, price, is_active)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id
+	), services as (
+		insert into "PassProductService" (pass_product_id, service_id)
+		select p.id, s.id from product p, "Service" s
+		where s.merchant_id = $1 and s.id = any($7::int[])
+	), categories as (
+		insert into "PassProductCategory" (pass_product_id, category_id)
+		select p.id, sc.id from product p, "ServiceCategory" sc
+		where sc.merchant_id = $1 and sc.id = any($8::int[])
+	)
+	select id from product
+	`
+
+	var productId int
+	err := r.db.QueryRow(ctx, query, product.MerchantId, product.Name, product.Credits, product.ValidityDays, product.Price,
+		product.IsActive, product.ServiceIds, product.CategoryIds).Scan(&productId)
+	if err != nil {
+		return 0, fmt.Errorf("NewPassProduct: %w", err)
+	}
+
+	return productId, nil
+}
+
+func (r *passRepository) UpdatePassProduct(ctx context.Context, product domain.PassProduct) error {
+	query := `
+	with product as (
+		update "PassProduct"
+		set name = $3, credits = $4, validity_days = $5, price = $6, is_active = $7
+		where merchant_id = $1 and id = $2
+		returning id
+	), deleted_services as (
+		delete from "PassProductService" ps
+		using product p
+		where ps.pass_product_id = p.id and ps.service_id <> all($8::int[])
+	), deleted_categories as (
+		delete from "PassProductCategory" pc
+		using product p
+		where pc.pass_product_id = p.id and pc.category_id <> all($9::int[])
+	), services as (
+		insert into "PassProductService" (pass_product_id, service_id)
+		select p.id, s.id from product p, "Service" s
+		where s.merchant_id = $1 and s.id = any($8::int[])
+		on conflict do nothing
+	), categories as (
+		insert into "PassProductCategory" (pass_product_id, category_id)
+		select p.id, sc.id from product p, "ServiceCategory" sc
+		where sc.merchant_id = $1 and sc.id = any($9::int[])
+		on conflict do nothing
+	)
+	select id from product
+	`
+
+	var productId int
+	err := r.db.QueryRow(ctx, query, product.MerchantId, product.Id, product.Name, product.Credits, product.ValidityDays,
+		product.Price, product.IsActive, product.ServiceIds, product.CategoryIds).Scan(&productId)
+	if err != nil {
+		return fmt.Errorf("UpdatePassProduct: %w", err)
+	}
+
+	return nil
+}
+
+const passProductColumns = `p.id, p.merchant_id, p.name, p.credits, p.validity_days, p.price, p.is_active, p.created_at,
+	coalesce((select array_agg(ps.service_id order by ps.service_id) from "PassProductService" ps where ps.pass_product_id = p.id), '{}') as service_ids,
+	coalesce((select array_agg(pc.category_id order by pc.category_id) from "PassProductCategory" pc where pc.pass_product_id = p.id), '{}') as category_ids`
+
+func (r *passRepository) GetPassProduct(ctx context.Context, merchantId uuid.UUID, productId int) (domain.PassProduct, error) {
+	query := `
+	select ` + passProductColumns + `
+	from "PassProduct" p
+	where p.merchant_id = $1 and p.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, productId)
+	product, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.PassProduct])
+	if err != nil {
+		return domain.PassProduct{}, fmt.Errorf("GetPassProduct: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r *passRepository) GetPassProducts(ctx context.Context, merchantId uuid.UUID) ([]domain.PassProduct, error) {
+	query := `
+	select ` + passProductColumns + `
+	from "PassProduct" p
+	where p.merchant_id = $1
+	order by p.is_active desc, p.name, p.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	products, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.PassProduct])
+	if err != nil {
+		return []domain.PassProduct{}, fmt.Errorf("GetPassProducts: %w", err)
+	}
+
+	return products, nil
+}
+
+func (r *passRepository) NewCustomerPass(ctx context.Context, pass domain.NewCustomerPass) (int, error) {
+	query := `
+	insert into "CustomerPass" (merchant_id, customer_id, pass_product_id, name, credits, remaining_credits, valid_from,
+		valid_until, assigned_by)
+	select c.merchant_id, c.id, $3, $4, $5, $5, $6, $7, $8
+	from "Customer" c
+	where c.merchant_id = $1 and c.id = $2
+	returning id
+	`
+
+	var passId int
+	err := r.db.QueryRow(ctx, query, pass.MerchantId, pass.CustomerId, pass.PassProductId, pass.Name, pass.Credits,
+		pass.ValidFrom, pass.ValidUntil, pass.AssignedBy).Scan(&passId)
+	if err != nil {
+		return 0, fmt.Errorf("NewCustomerPass: %w", err)
+	}
+
+	return passId, nil
+}
+
+const customerPassColumns = `cp.id, cp.merchant_id, cp.customer_id, cp.pass_product_id, cp.name, cp.credits, cp.remaining_credits,
+	cp.valid_from, cp.valid_until, cp.expired_at, cp.assigned_by, cp.created_at`
+
+func (r *passRepository) GetCustomerPass(ctx context.Context, merchantId uuid.UUID, customerPassId int) (domain.CustomerPass, error) {
+	query := `
+	select ` + customerPassColumns + `
+	from "CustomerPass" cp
+	where cp.merchant_id = $1 and cp.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerPassId)
+	pass, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerPass])
+	if err != nil {
+		return domain.CustomerPass{}, fmt.Errorf("GetCustomerPass: %w", err)
+	}
+
+	return pass, nil
+}
+
+func (r *passRepository) GetCustomerPasses(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.CustomerPass, error) {
+	query := `
+	select ` + customerPassColumns + `
+	from "CustomerPass" cp
+	where cp.merchant_id = $1 and cp.customer_id = $2
+	order by cp.valid_from desc, cp.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	passes, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerPass])
+	if err != nil {
+		return []domain.CustomerPass{}, fmt.Errorf("GetCustomerPasses: %w", err)
+	}
+
+	return passes, nil
+}
+
+func (r *passRepository) GetCustomerPassUsages(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.PassUsage, error) {
+	query := `
+	select pu.id, pu.customer_pass_id, pu.booking_id, b.service_name, b.from_date, pu.used_at, pu.refunded_at
+	from "PassUsage" pu
+	join "CustomerPass" cp on cp.id = pu.customer_pass_id
+	join "Booking" b on b.id = pu.booking_id
+	where cp.merchant_id = $1 and cp.customer_id = $2
+	order by b.from_date desc, pu.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	usages, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.PassUsage])
+	if err != nil {
+		return []domain.PassUsage{}, fmt.Errorf("GetCustomerPassUsages: %w", err)
+	}
+
+	return usages, nil
+}
+
+func (r *passRepository) GetUsablePassForUpdate(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID, serviceId int, at time.Time) (domain.CustomerPass, error) {
+	query := `
+	select ` + customerPassColumns + `
+	from "CustomerPass" cp
+	where cp.merchant_id = $1 and cp.customer_id = $2 and cp.expired_at is null
+		and cp.valid_from <= $4 and cp.valid_until > $4
+		and (cp.remaining_credits is null or cp.remaining_credits > 0)
+		and (
+			exists (select 1 from "PassProductService" ps where ps.pass_product_id = cp.pass_product_id and ps.service_id = $3)
+			or exists (
+				select 1 from "PassProductCategory" pc
+				join "Service" s on s.category_id = pc.category_id
+				where pc.pass_product_id = cp.pass_product_id and s.id = $3
+			)
+		)
+	order by cp.remaining_credits is not null, cp.valid_until, cp.id
+	limit 1
+	for update of cp
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId, serviceId, at)
+	pass, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.CustomerPass])
+	if err != nil {
+		return domain.CustomerPass{}, fmt.Errorf("GetUsablePassForUpdate: %w", err)
+	}
+
+	return pass, nil
+}
+
+func (r *passRepository) UsePass(ctx context.Context, customerPassId int, bookingId int) error {
+	query := `
+	with pass as (
+		update "CustomerPass"
+		set remaining_credits = remaining_credits - 1
+		where id = $1 and (remaining_credits is null or remaining_credits > 0)
+		returning id
+	)
+	insert into "PassUsage" (customer_pass_id, booking_id)
+	select id, $2 from pass
+	`
+
+	tag, err := r.db.Exec(ctx, query, customerPassId, bookingId)
+	if err != nil {
+		return fmt.Errorf("UsePass: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UsePass: the pass has no credits left")
+	}
+
+	return nil
+}
+
+func (r *passRepository) RefundPassUsages(ctx context.Context, bookingIds []int, customerIds []uuid.UUID) error {
+	query := `
+	with refunded as (
+		update "PassUsage" pu
+		set refunded_at = now()
+		from "CustomerPass" cp
+		where pu.customer_pass_id = cp.id and pu.booking_id = any($1::int[]) and pu.refunded_at is null
+			and ($2::uuid[] is null or cp.customer_id = any($2::uuid[]))
+		returning pu.customer_pass_id
+	)
+	update "CustomerPass" cp
+	set remaining_credits = cp.remaining_credits + r.count
+	from (select customer_pass_id, count(*) as count from refunded group by customer_pass_id) r
+	where cp.id = r.customer_pass_id
+	`
+
+	_, err := r.db.Exec(ctx, query, bookingIds, customerIds)
+	if err != nil {
+		return fmt.Errorf("RefundPassUsages: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passRepository) ExpirePasses(ctx context.Context, now time.Time) (int, error) {
+	query := `
+	update "CustomerPass"
+	set expired_at = $1
+	where expired_at is null and valid_until <= $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("ExpirePasses: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// Has to run before the participations are merged, as it looks for the bookings both customers participate in
+func (r *passRepository) MergeCustomerPasses(ctx context.Context, merchantId uuid.UUID, fromCustomerId uuid.UUID, toCustomerId uuid.UUID) error {
+	query := `
+	with refunded as (
+		update "PassUsage" pu
+		set refunded_at = now()
+		from "CustomerPass" cp
+		where pu.customer_pass_id = cp.id and cp.merchant_id = $1 and cp.customer_id = $2 and pu.refunded_at is null
+			and exists (
+				select 1 from "BookingParticipant" kept
+				where kept.booking_id = pu.booking_id and kept.customer_id = $3
+			)
+		returning pu.customer_pass_id
+	)
+	update "CustomerPass" cp
+	set customer_id = $3,
+		remaining_credits = cp.remaining_credits + (select count(*) from refunded r where r.customer_pass_id = cp.id)
+	where cp.merchant_id = $1 and cp.customer_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, fromCustomerId, toCustomerId)
+	if err != nil {
+		return fmt.Errorf("MergeCustomerPasses: %w", err)
+	}
+
+	return nil
+}
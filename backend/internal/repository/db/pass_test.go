@@ -0,0 +1,88 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsePass(t *testing.T) {
+	pool := testPool(t)
+	f := newFixture(t, pool)
+	repo := NewPassRepository(pool)
+
+	credits := 1
+	productId, err := repo.NewPassProduct(t.Context(), domain.PassProduct{
+		MerchantId:   f.merchantId,
+		Name:         "Single",
+		Credits:      &credits,
+		ValidityDays: 30,
+		Price:        currencyxtest.Price(t, "10", "EUR"),
+		IsActive:     true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	newPass := func(credits *int) int {
+		now := time.Now()
+		passId, err := repo.NewCustomerPass(t.Context(), domain.NewCustomerPass{
+			MerchantId:    f.merchantId,
+			CustomerId:    f.customerId,
+			PassProductId: productId,
+			Name:          "Single",
+			Credits:       credits,
+			ValidFrom:     now,
+			ValidUntil:    now.AddDate(0, 0, 30),
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		return passId
+	}
+
+	t.Run("double spend", func(t *testing.T) {
+		passId := newPass(&credits)
+
+		firstErr, secondErr := race(t, pool, func(tx pgx.Tx) error {
+			return repo.WithTx(tx).UsePass(t.Context(), passId, f.bookingId)
+		}, func(tx pgx.Tx) error {
+			return repo.WithTx(tx).UsePass(t.Context(), passId, f.bookingId)
+		})
+		assert.NoError(t, firstErr)
+		assert.Error(t, secondErr, "the only credit was used by the first transaction")
+
+		pass, err := repo.GetCustomerPass(t.Context(), f.merchantId, passId)
+		if !assert.NoError(t, err) || !assert.NotNil(t, pass.RemainingCredits) {
+			return
+		}
+		assert.Equal(t, 0, *pass.RemainingCredits)
+
+		t.Run("refund", func(t *testing.T) {
+			for range 2 {
+				err := repo.RefundPassUsages(t.Context(), []int{f.bookingId}, nil)
+				assert.NoError(t, err)
+			}
+
+			pass, err := repo.GetCustomerPass(t.Context(), f.merchantId, passId)
+			if !assert.NoError(t, err) || !assert.NotNil(t, pass.RemainingCredits) {
+				return
+			}
+			assert.Equal(t, 1, *pass.RemainingCredits, "a usage is only refunded once")
+		})
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		passId := newPass(nil)
+
+		for range 3 {
+			err := repo.UsePass(t.Context(), passId, f.bookingId)
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -27,10 +27,10 @@ func (r *productRepository) WithTx(tx db.DBTX) domain.ProductRepository {
 func (r *productRepository) NewProduct(ctx context.Context, prod domain.Product) error {
 
 	query := `
-	insert into "Product" (merchant_id, name, description, price, unit, max_amount, current_amount)
-	values ($1, $2, $3, $4, $5, $6, $7)`
+	insert into "Product" (merchant_id, name, description, price, unit, max_amount, current_amount, tax_rate_id)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	_, err := r.db.Exec(ctx, query, prod.MerchantId, prod.Name, prod.Description, prod.Price, prod.Unit, prod.MaxAmount, prod.CurrentAmount)
+	_, err := r.db.Exec(ctx, query, prod.MerchantId, prod.Name, prod.Description, prod.Price, prod.Unit, prod.MaxAmount, prod.CurrentAmount, prod.TaxRateId)
 	if err != nil {
 		return fmt.Errorf("NewProduct: %w", err)
 	}
@@ -42,10 +42,11 @@ func (r *productRepository) UpdateProduct(ctx context.Context, newProduct domain
 
 	query := `
 	update "Product"
-	set name = $3, description = $4, price = $5, unit = $6, max_amount = $7, current_amount = $8
+	set name = $3, description = $4, price = $5, unit = $6, max_amount = $7, current_amount = $8, tax_rate_id = $9
 	where merchant_id = $1 and id = $2 and deleted_on is null
 	`
-	_, err := r.db.Exec(ctx, query, newProduct.MerchantId, newProduct.Id, newProduct.Name, newProduct.Description, newProduct.Price, newProduct.Unit, newProduct.MaxAmount, newProduct.CurrentAmount)
+	_, err := r.db.Exec(ctx, query, newProduct.MerchantId, newProduct.Id, newProduct.Name, newProduct.Description, newProduct.Price, newProduct.Unit, newProduct.MaxAmount, newProduct.CurrentAmount,
+		newProduct.TaxRateId)
 	if err != nil {
 		return fmt.Errorf("UpdateProduct: %w", err)
 	}
@@ -76,7 +77,7 @@ func (r *productRepository) DeleteProduct(ctx context.Context, merchantId uuid.U
 // TODO: this should use pgx helpers
 func (r *productRepository) GetProducts(ctx context.Context, merchantId uuid.UUID) ([]domain.ProductInfo, error) {
 	query := `
-	select p.id, p.name, p.description, p.price, p.unit, p.max_amount, p.current_amount,
+	select p.id, p.name, p.description, p.price, p.unit, p.max_amount, p.current_amount, p.tax_rate_id,
 	coalesce(
         json_agg(
 		    json_build_object(
@@ -105,6 +106,7 @@ func (r *productRepository) GetProducts(ctx context.Context, merchantId uuid.UUI
 			&product.Unit,
 			&product.MaxAmount,
 			&product.CurrentAmount,
+			&product.TaxRateId,
 			&servicesJSON,
 		)
 		if err != nil {
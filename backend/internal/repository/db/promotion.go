@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type promotionRepository struct {
+	db db.DBTX
+}
+
+func NewPromotionRepository(db db.DBTX) domain.PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) WithTx(tx db.DBTX) domain.PromotionRepository {
+	return &promotionRepository{db: tx}
+}
+
+func (r *promotionRepository) NewPromotion(ctx context.Context, promotion domain.Promotion) (int, error) {
+	query := `
+	with promotion as (
+		insert into "Promotion" (merchant_id, name, code, percent, amount, first_visit_only, max_uses, max_uses_per_customer,
+			valid_from, valid_until, weekdays, start_minute, end_minute, is_active)
+		values ($1, $2, $3, $4::text::numeric, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		returning id
+	), services as (
+		insert into "PromotionService" (promotion_id, service_id)
+		select p.id, s.id from promotion p, "Service" s
+		where s.merchant_id = $1 and s.id = any($15::int[])
+	), categories as (
+		insert into "PromotionCategory" (promotion_id, category_id)
+		select p.id, sc.id from promotion p, "ServiceCategory" sc
+		where sc.merchant_id = $1 and sc.id = any($16::int[])
+	)
+	select id from promotion
+	`
+
+	var promotionId int
+	err := r.db.QueryRow(ctx, query, promotion.MerchantId, promotion.Name, promotion.Code, promotion.Percent, promotion.Amount,
+		promotion.FirstVisitOnly, promotion.MaxUses, promotion.MaxUsesPerCustomer, promotion.ValidFrom, promotion.ValidUntil,
+		promotion.Weekdays, promotion.StartMinute, promotion.EndMinute, promotion.IsActive, promotion.ServiceIds,
+		promotion.CategoryIds).Scan(&promotionId)
+	if err != nil {
+		return 0, fmt.Errorf("NewPromotion: %w", err)
+	}
+
+	return promotionId, nil
+}
+
+func (r *promotionRepository) UpdatePromotion(ctx context.Context, promotion domain.Promotion) error {
+	query := `
+	with promotion as (
+		update "Promotion"
+		set name = $3, code = $4, percent = $5::text::numeric, amount = $6, first_visit_only = $7, max_uses = $8,
+			max_uses_per_customer = $9, valid_from = $10, valid_until = $11, weekdays = $12, start_minute = $13,
+			end_minute = $14, is_active = $15
+		where merchant_id = $1 and id = $2
+		returning id
+	), deleted_services as (
+		delete from "PromotionService" ps
+		using promotion p
+		where ps.promotion_id = p.id and ps.service_id <> all($16::int[])
+	), deleted_categories as (
+		delete from "PromotionCategory" pc
+		using promotion p
+		where pc.promotion_id = p.id and pc.category_id <> all($17::int[])
+	), services as (
+		insert into "PromotionService" (promotion_id, service_id)
+		select p.id, s.id from promotion p, "Service" s
+		where s.merchant_id = $1 and s.id = any($16::int[])
+		on conflict do nothing
+	), categories as (
+		insert into "PromotionCategory" (promotion_id, category_id)
+		select p.id, sc.id from promotion p, "ServiceCategory" sc
+		where sc.merchant_id = $1 and sc.id = any($17::int[])
+		on conflict do nothing
+	)
+	select id from promotion
+	`
+
+	var promotionId int
+	err := r.db.QueryRow(ctx, query, promotion.MerchantId, promotion.Id, promotion.Name, promotion.Code, promotion.Percent,
+		promotion.Amount, promotion.FirstVisitOnly, promotion.MaxUses, promotion.MaxUsesPerCustomer, promotion.ValidFrom,
+		promotion.ValidUntil, promotion.Weekdays, promotion.StartMinute, promotion.EndMinute, promotion.IsActive,
+		promotion.ServiceIds, promotion.CategoryIds).Scan(&promotionId)
+	if err != nil {
+		return fmt.Errorf("UpdatePromotion: %w", err)
+	}
+
+	return nil
+}
+
+const promotionColumns = `p.id, p.merchant_id, p.name, p.code, p.percent::text, p.amount, p.first_visit_only, p.max_uses,
+	p.max_uses_per_customer, p.valid_from, p.valid_until, p.weekdays, p.start_minute, p.end_minute, p.is_active, p.created_at,
+	coalesce((select array_agg(ps.service_id order by ps.service_id) from "PromotionService" ps where ps.promotion_id = p.id), '{}') as service_ids,
+	coalesce((select array_agg(pc.category_id order by pc.category_id) from "PromotionCategory" pc where pc.promotion_id = p.id), '{}') as category_ids,
+	(select count(*) from "BookingParticipant" bp where bp.promotion_id = p.id and bp.status <> 'cancelled') as uses`
+
+func (r *promotionRepository) GetPromotion(ctx context.Context, merchantId uuid.UUID, promotionId int) (domain.Promotion, error) {
+	query := `
+	select ` + promotionColumns + `
+	from "Promotion" p
+	where p.merchant_id = $1 and p.id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, promotionId)
+	promotion, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Promotion])
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("GetPromotion: %w", err)
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) GetPromotions(ctx context.Context, merchantId uuid.UUID) ([]domain.Promotion, error) {
+	query := `
+	select ` + promotionColumns + `
+	from "Promotion" p
+	where p.merchant_id = $1
+	order by p.is_active desc, p.created_at desc, p.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	promotions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Promotion])
+	if err != nil {
+		return []domain.Promotion{}, fmt.Errorf("GetPromotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) GetPromotionByCodeForUpdate(ctx context.Context, merchantId uuid.UUID, code string) (domain.Promotion, error) {
+	lockQuery := `
+	select id
+	from "Promotion"
+	where merchant_id = $1 and code = $2
+	for update
+	`
+
+	var promotionId int
+	err := r.db.QueryRow(ctx, lockQuery, merchantId, code).Scan(&promotionId)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("GetPromotionByCodeForUpdate: %w", err)
+	}
+
+	// the uses are counted by a new statement, the locking one would not see the participants
+	// of the transaction it waited for
+	promotion, err := r.GetPromotion(ctx, merchantId, promotionId)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("GetPromotionByCodeForUpdate: %w", err)
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) GetPromotionForUpdate(ctx context.Context, merchantId uuid.UUID, promotionId int) (domain.Promotion, error) {
+	lockQuery := `
+	select id
+	from "Promotion"
+	where merchant_id = $1 and id = $2
+	for update
+	`
+
+	tag, err := r.db.Exec(ctx, lockQuery, merchantId, promotionId)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("GetPromotionForUpdate: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.Promotion{}, fmt.Errorf("GetPromotionForUpdate: %w", pgx.ErrNoRows)
+	}
+
+	// the uses are counted by a new statement, the locking one would not see the participants
+	// of the transaction it waited for
+	promotion, err := r.GetPromotion(ctx, merchantId, promotionId)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("GetPromotionForUpdate: %w", err)
+	}
+
+	return promotion, nil
+}
+
+func (r *promotionRepository) GetAutomaticPromotions(ctx context.Context, merchantId uuid.UUID) ([]domain.Promotion, error) {
+	query := `
+	select ` + promotionColumns + `
+	from "Promotion" p
+	where p.merchant_id = $1 and p.code is null and p.is_active
+	order by p.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	promotions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Promotion])
+	if err != nil {
+		return []domain.Promotion{}, fmt.Errorf("GetAutomaticPromotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) GetCustomerPromotionUses(ctx context.Context, promotionId int, customerId uuid.UUID) (int, error) {
+	query := `
+	select count(*)
+	from "BookingParticipant"
+	where promotion_id = $1 and customer_id = $2 and status <> 'cancelled'
+	`
+
+	var uses int
+	err := r.db.QueryRow(ctx, query, promotionId, customerId).Scan(&uses)
+	if err != nil {
+		return 0, fmt.Errorf("GetCustomerPromotionUses: %w", err)
+	}
+
+	return uses, nil
+}
+
+func (r *promotionRepository) HasCustomerVisited(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) (bool, error) {
+	query := `
+	select exists (
+		select 1
+		from "BookingParticipant" bp
+		join "Booking" b on b.id = bp.booking_id
+		where b.merchant_id = $1 and bp.customer_id = $2 and bp.status <> 'cancelled' and b.status <> 'cancelled'
+	)
+	`
+
+	var visited bool
+	err := r.db.QueryRow(ctx, query, merchantId, customerId).Scan(&visited)
+	if err != nil {
+		return false, fmt.Errorf("HasCustomerVisited: %w", err)
+	}
+
+	return visited, nil
+}
+
+func (r *promotionRepository) SetParticipantPromotion(ctx context.Context, bookingId int, customerId uuid.UUID, promotionId int,
+	pricePerPerson currencyx.Price) error {
+	query := `
+	update "BookingParticipant"
+	set promotion_id = $3, price_per_person = $4
+	where booking_id = $1 and customer_id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, bookingId, customerId, promotionId, pricePerPerson)
+	if err != nil {
+		return fmt.Errorf("SetParticipantPromotion: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("SetParticipantPromotion: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
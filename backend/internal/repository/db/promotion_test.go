@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPromotionByCodeForUpdate(t *testing.T) {
+	pool := testPool(t)
+	f := newFixture(t, pool)
+	repo := NewPromotionRepository(pool)
+
+	code, percent, maxUses := "SPRING", "10", 1
+	promotionId, err := repo.NewPromotion(t.Context(), domain.Promotion{
+		MerchantId: f.merchantId,
+		Name:       "Spring",
+		Code:       &code,
+		Percent:    &percent,
+		MaxUses:    &maxUses,
+		Weekdays:   []int{},
+		IsActive:   true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var locked domain.Promotion
+
+	firstErr, secondErr := race(t, pool, func(tx pgx.Tx) error {
+		promotion, err := repo.WithTx(tx).GetPromotionByCodeForUpdate(t.Context(), f.merchantId, code)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, 0, promotion.Uses)
+
+		return repo.WithTx(tx).SetParticipantPromotion(t.Context(), f.bookingId, f.customerId, promotionId,
+			currencyxtest.Price(t, "9", "EUR"))
+	}, func(tx pgx.Tx) error {
+		var err error
+		locked, err = repo.WithTx(tx).GetPromotionByCodeForUpdate(t.Context(), f.merchantId, code)
+		return err
+	})
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+
+	assert.Equal(t, promotionId, locked.Id)
+	assert.Equal(t, 1, locked.Uses, "the use of the transaction it waited for has to be counted")
+}
+
+func TestGetPromotionForUpdate(t *testing.T) {
+	pool := testPool(t)
+	f := newFixture(t, pool)
+	repo := NewPromotionRepository(pool)
+
+	newAutomatic := func(name string) int {
+		percent := "10"
+		promotionId, err := repo.NewPromotion(t.Context(), domain.Promotion{
+			MerchantId: f.merchantId,
+			Name:       name,
+			Percent:    &percent,
+			Weekdays:   []int{},
+			IsActive:   true,
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		return promotionId
+	}
+
+	chosen := newAutomatic("Chosen")
+	other := newAutomatic("Other")
+
+	tx, err := pool.Begin(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tx.Rollback(t.Context()) // nolint:errcheck
+
+	_, err = repo.WithTx(tx).GetPromotionForUpdate(t.Context(), f.merchantId, chosen)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// neither the listing nor the lock of another promotion waits for the chosen one
+	ctx, cancel := context.WithTimeout(t.Context(), lockWait)
+	defer cancel()
+
+	otherTx, err := pool.Begin(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer otherTx.Rollback(t.Context()) // nolint:errcheck
+
+	promotions, err := repo.WithTx(otherTx).GetAutomaticPromotions(ctx, f.merchantId)
+	assert.NoError(t, err)
+	assert.Len(t, promotions, 2)
+
+	_, err = repo.WithTx(otherTx).GetPromotionForUpdate(ctx, f.merchantId, other)
+	assert.NoError(t, err)
+
+	_, err = repo.WithTx(otherTx).GetPromotionForUpdate(ctx, uuid.New(), other)
+	assert.ErrorIs(t, err, pgx.ErrNoRows, "the promotion of another merchant")
+}
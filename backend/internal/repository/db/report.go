@@ -0,0 +1,592 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type reportRepository struct {
+	db db.DBTX
+}
+
+func NewReportRepository(db db.DBTX) domain.ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) WithTx(tx db.DBTX) domain.ReportRepository {
+	return &reportRepository{db: tx}
+}
+
+func (r *reportRepository) GetMerchantsWithBookings(ctx context.Context, from time.Time, to time.Time) ([]uuid.UUID, error) {
+	query := `
+	select distinct b.merchant_id
+	from "Booking" b
+	where b.from_date >= $1 and b.from_date < $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, from, to)
+	merchantIds, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return []uuid.UUID{}, fmt.Errorf("GetMerchantsWithBookings: %w", err)
+	}
+
+	return merchantIds, nil
+}
+
+// Should be called in a transaction so the rollups of the days are never missing while they are recomputed
+func (r *reportRepository) RefreshBookingRollups(ctx context.Context, merchantId uuid.UUID, startDay time.Time, endDay time.Time) error {
+	// serializes the refreshes of a merchant so overlapping ones can not insert the same rows twice
+	_, err := r.db.Exec(ctx, `select pg_advisory_xact_lock(hashtextextended($1::text, 0))`, merchantId)
+	if err != nil {
+		return fmt.Errorf("RefreshBookingRollups: %w", err)
+	}
+
+	deleteQuery := `
+	delete from "BookingDailyRollup"
+	where merchant_id = $1 and day >= $2 and day <= $3
+	`
+
+	_, err = r.db.Exec(ctx, deleteQuery, merchantId, startDay, endDay)
+	if err != nil {
+		return fmt.Errorf("RefreshBookingRollups: %w", err)
+	}
+
+	insertQuery := `
+	with merchant as (
+		select coalesce(m.timezone, 'UTC') as timezone
+		from "Merchant" m
+		where m.id = $1
+	)
+	insert into "BookingDailyRollup" (merchant_id, day, service_id, category_id, employee_id, location_id, currency,
+		bookings, cancellations, no_shows, participants, revenue, tax_name, tax_percent, tax, booked_minutes)
+	select b.merchant_id, (b.from_date at time zone m.timezone)::date as day, b.service_id, s.category_id, b.employee_id, b.location_id,
+		(b.total_price).currency,
+		count(*) filter (where b.status <> 'cancelled'),
+		count(*) filter (where b.status = 'cancelled'),
+		count(*) filter (where b.status = 'no-show'),
+		coalesce(sum(b.current_participants) filter (where b.status not in ('cancelled', 'no-show')), 0),
+		coalesce(sum((b.total_price).number) filter (where b.status not in ('cancelled', 'no-show')), 0),
+		b.tax_name, b.tax_percent,
+		coalesce(sum((b.total_price).number * b.tax_percent / (100 + b.tax_percent)) filter (where b.status not in ('cancelled', 'no-show')), 0),
+		coalesce(sum(extract(epoch from b.to_date - b.from_date) / 60) filter (where b.status not in ('cancelled', 'no-show')), 0)::integer
+	from "Booking" b
+	cross join merchant m
+	left join "Service" s on s.id = b.service_id
+	where b.merchant_id = $1
+		and b.from_date >= ($2::date)::timestamp at time zone m.timezone
+		and b.from_date < ($3::date + 1)::timestamp at time zone m.timezone
+	group by b.merchant_id, day, b.service_id, s.category_id, b.employee_id, b.location_id, (b.total_price).currency, b.tax_name, b.tax_percent
+	`
+
+	_, err = r.db.Exec(ctx, insertQuery, merchantId, startDay, endDay)
+	if err != nil {
+		return fmt.Errorf("RefreshBookingRollups: %w", err)
+	}
+
+	return nil
+}
+
+// the columns and the join used for the group of the report, the names are only read from trusted constants
+func reportGroupColumns(groupBy *types.ReportGroupBy) (string, string, string) {
+	if groupBy == nil {
+		return "null::integer", "null::text", ""
+	}
+
+	switch *groupBy {
+	case types.ReportGroupByService:
+		return "r.service_id", "s.name", `left join "Service" s on s.id = r.service_id`
+	case types.ReportGroupByCategory:
+		return "r.category_id", "sc.name", `left join "ServiceCategory" sc on sc.id = r.category_id`
+	case types.ReportGroupByEmployee:
+		return "r.employee_id", "concat_ws(' ', coalesce(e.first_name, u.first_name), coalesce(e.last_name, u.last_name))",
+			`left join "Employee" e on e.id = r.employee_id left join "User" u on u.id = e.user_id`
+	case types.ReportGroupByLocation:
+		return "r.location_id", "l.formatted_location", `left join "Location" l on l.id = r.location_id`
+	default:
+		return "null::integer", "null::text", ""
+	}
+}
+
+func (r *reportRepository) GetReportRows(ctx context.Context, merchantId uuid.UUID, currency string, startDay time.Time, endDay time.Time,
+	groupBy *types.ReportGroupBy) ([]domain.ReportRow, error) {
+	groupId, groupName, join := reportGroupColumns(groupBy)
+
+	query := `
+	select r.day, ` + groupId + ` as group_id, ` + groupName + ` as group_name,
+		sum(r.bookings)::integer as bookings, sum(r.cancellations)::integer as cancellations, sum(r.no_shows)::integer as no_shows,
+		sum(r.participants)::integer as participants, sum(r.revenue)::text as revenue, sum(r.booked_minutes)::integer as booked_minutes
+	from "BookingDailyRollup" r
+	` + join + `
+	where r.merchant_id = $1 and r.currency = $2 and r.day >= $3 and r.day <= $4
+	group by r.day, 2, 3
+	order by r.day
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, currency, startDay, endDay)
+	reportRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ReportRow])
+	if err != nil {
+		return []domain.ReportRow{}, fmt.Errorf("GetReportRows: %w", err)
+	}
+
+	return reportRows, nil
+}
+
+func (r *reportRepository) GetReportTaxes(ctx context.Context, merchantId uuid.UUID, currency string, startDay time.Time, endDay time.Time) ([]domain.ReportTaxRow, error) {
+	query := `
+	select coalesce(r.tax_name, '') as name, r.tax_percent::text as percent, sum(r.tax)::text as tax
+	from "BookingDailyRollup" r
+	where r.merchant_id = $1 and r.currency = $2 and r.day >= $3 and r.day <= $4 and r.tax_percent is not null
+	group by r.tax_name, r.tax_percent
+	order by r.tax_percent desc, r.tax_name
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, currency, startDay, endDay)
+	taxRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ReportTaxRow])
+	if err != nil {
+		return []domain.ReportTaxRow{}, fmt.Errorf("GetReportTaxes: %w", err)
+	}
+
+	return taxRows, nil
+}
+
+func (r *reportRepository) GetRollupRefreshedAt(ctx context.Context, merchantId uuid.UUID, startDay time.Time, endDay time.Time) (*time.Time, error) {
+	query := `
+	select min(refreshed_at)
+	from "BookingDailyRollup"
+	where merchant_id = $1 and day >= $2 and day <= $3
+	`
+
+	var refreshedAt *time.Time
+	err := r.db.QueryRow(ctx, query, merchantId, startDay, endDay).Scan(&refreshedAt)
+	if err != nil {
+		return nil, fmt.Errorf("GetRollupRefreshedAt: %w", err)
+	}
+
+	return refreshedAt, nil
+}
+
+func (r *reportRepository) GetUtilizationBookings(ctx context.Context, merchantId uuid.UUID, currency string, from time.Time, to time.Time) ([]domain.UtilizationBooking, error) {
+	query := `
+	select b.id, b.employee_id, b.location_id, l.formatted_location as location_name, b.from_date,
+		(case when b.status <> 'no-show' and (b.total_price).currency = $2 then (b.total_price).number else 0 end)::text as revenue,
+		coalesce(
+			(select jsonb_agg(jsonb_build_object('from_date', bp.from_date, 'to_date', bp.to_date) order by bp.from_date)
+			from "BookingPhase" bp
+			where bp.booking_id = b.id and bp.phase_type = 'active'),
+			jsonb_build_array(jsonb_build_object('from_date', b.from_date, 'to_date', b.to_date))
+		) as active_phases
+	from "Booking" b
+	join "Location" l on l.id = b.location_id
+	where b.merchant_id = $1 and b.status <> 'cancelled' and b.from_date < $4 and b.to_date > $3
+	order by b.from_date
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, currency, from, to)
+	bookings, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.UtilizationBooking])
+	if err != nil {
+		return []domain.UtilizationBooking{}, fmt.Errorf("GetUtilizationBookings: %w", err)
+	}
+
+	return bookings, nil
+}
+
+func (r *reportRepository) GetEmployeeBlockedTimes(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]domain.EmployeeBlockedTime, error) {
+	query := `
+	select ebt.employee_id, bt.from_date, bt.to_date, bt.all_day
+	from "BlockedTime" bt
+	join "EmployeeBlockedTime" ebt on ebt.blocked_time_id = bt.id
+	where bt.merchant_id = $1 and bt.from_date < $3 and bt.to_date >= $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, from, to)
+	blockedTimes, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.EmployeeBlockedTime])
+	if err != nil {
+		return []domain.EmployeeBlockedTime{}, fmt.Errorf("GetEmployeeBlockedTimes: %w", err)
+	}
+
+	return blockedTimes, nil
+}
+
+func (r *reportRepository) GetCustomerVisits(ctx context.Context, merchantId uuid.UUID, currency string, to time.Time) ([]domain.CustomerVisit, error) {
+	query := `
+	select coalesce(bp.transferred_to, bp.customer_id) as customer_id, coalesce(c.first_name, u.first_name) as first_name,
+		coalesce(c.last_name, u.last_name) as last_name, b.service_id, b.service_name, b.from_date, bp.status,
+		(case when bp.status = 'completed' and (coalesce(bp.price_per_person, b.price_per_person)).currency = $2
+			then (coalesce(bp.price_per_person, b.price_per_person)).number else 0 end)::text as price
+	from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	join "Customer" c on c.id = coalesce(bp.transferred_to, bp.customer_id)
+	left join "User" u on u.id = c.user_id
+	where b.merchant_id = $1 and b.cancelled_by_merchant_on is null
+		and (b.from_date < $3 or bp.status in ('booked', 'confirmed'))
+	order by b.from_date
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, currency, to)
+	visits, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.CustomerVisit])
+	if err != nil {
+		return []domain.CustomerVisit{}, fmt.Errorf("GetCustomerVisits: %w", err)
+	}
+
+	return visits, nil
+}
+
+func (r *reportRepository) NewReportSubscription(ctx context.Context, merchantId uuid.UUID, subscription domain.NewReportSubscription) (int, error) {
+	query := `
+	with subscription as (
+		insert into "ReportSubscription" (merchant_id, frequency)
+		values ($1, $2)
+		returning id
+	), recipients as (
+		insert into "ReportSubscriptionRecipient" (subscription_id, employee_id)
+		select s.id, e.id
+		from subscription s
+		join "Employee" e on e.merchant_id = $1 and e.id = any($3::int[])
+	)
+	select id from subscription
+	`
+
+	var subscriptionId int
+	err := r.db.QueryRow(ctx, query, merchantId, subscription.Frequency, subscription.EmployeeIds).Scan(&subscriptionId)
+	if err != nil {
+		return 0, fmt.Errorf("NewReportSubscription: %w", err)
+	}
+
+	return subscriptionId, nil
+}
+
+// Should be called in a transaction so the recipients are replaced at once
+func (r *reportRepository) UpdateReportSubscription(ctx context.Context, merchantId uuid.UUID, subscriptionId int, subscription domain.UpdateReportSubscription) error {
+	updateQuery := `
+	update "ReportSubscription"
+	set is_active = $3
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, updateQuery, merchantId, subscriptionId, subscription.IsActive)
+	if err != nil {
+		return fmt.Errorf("UpdateReportSubscription: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UpdateReportSubscription: %w", pgx.ErrNoRows)
+	}
+
+	deleteQuery := `
+	delete from "ReportSubscriptionRecipient"
+	where subscription_id = $1
+	`
+
+	_, err = r.db.Exec(ctx, deleteQuery, subscriptionId)
+	if err != nil {
+		return fmt.Errorf("UpdateReportSubscription: %w", err)
+	}
+
+	insertQuery := `
+	insert into "ReportSubscriptionRecipient" (subscription_id, employee_id)
+	select $2, e.id
+	from "Employee" e
+	where e.merchant_id = $1 and e.id = any($3::int[])
+	`
+
+	_, err = r.db.Exec(ctx, insertQuery, merchantId, subscriptionId, subscription.EmployeeIds)
+	if err != nil {
+		return fmt.Errorf("UpdateReportSubscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) DeleteReportSubscription(ctx context.Context, merchantId uuid.UUID, subscriptionId int) error {
+	query := `
+	delete from "ReportSubscription"
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, subscriptionId)
+	if err != nil {
+		return fmt.Errorf("DeleteReportSubscription: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteReportSubscription: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+const reportSubscriptionColumns = `rs.id, rs.merchant_id, m.name as merchant_name, rs.frequency, rs.is_active, rs.last_sent_until, rs.created_at,
+	coalesce((
+		select jsonb_agg(jsonb_build_object(
+			'employee_id', e.id,
+			'first_name', coalesce(e.first_name, u.first_name),
+			'last_name', coalesce(e.last_name, u.last_name),
+			'email', coalesce(e.email, u.email),
+			'user_language', u.language,
+			'role', e.role,
+			'role_id', e.role_id,
+			'custom_permissions', r.permissions
+		) order by e.id)
+		from "ReportSubscriptionRecipient" rsr
+		join "Employee" e on e.id = rsr.employee_id
+		left join "User" u on u.id = e.user_id
+		left join "Role" r on r.id = e.role_id
+		where rsr.subscription_id = rs.id and e.is_active
+	), '[]'::jsonb) as recipients`
+
+func (r *reportRepository) GetReportSubscription(ctx context.Context, subscriptionId int) (domain.ReportSubscription, error) {
+	query := `
+	select ` + reportSubscriptionColumns + `
+	from "ReportSubscription" rs
+	join "Merchant" m on m.id = rs.merchant_id
+	where rs.id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, subscriptionId)
+	subscription, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.ReportSubscription])
+	if err != nil {
+		return domain.ReportSubscription{}, fmt.Errorf("GetReportSubscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *reportRepository) GetReportSubscriptions(ctx context.Context, merchantId uuid.UUID) ([]domain.ReportSubscription, error) {
+	query := `
+	select ` + reportSubscriptionColumns + `
+	from "ReportSubscription" rs
+	join "Merchant" m on m.id = rs.merchant_id
+	where rs.merchant_id = $1
+	order by rs.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ReportSubscription])
+	if err != nil {
+		return []domain.ReportSubscription{}, fmt.Errorf("GetReportSubscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *reportRepository) GetActiveReportSubscriptions(ctx context.Context) ([]domain.ActiveReportSubscription, error) {
+	query := `
+	select rs.id, rs.merchant_id, rs.frequency, coalesce(m.timezone, 'UTC') as timezone, rs.last_sent_until, rs.created_at
+	from "ReportSubscription" rs
+	join "Merchant" m on m.id = rs.merchant_id
+	where rs.is_active and exists (
+		select 1 from "ReportSubscriptionRecipient" rsr
+		where rsr.subscription_id = rs.id
+	)
+	`
+
+	rows, _ := r.db.Query(ctx, query)
+	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ActiveReportSubscription])
+	if err != nil {
+		return []domain.ActiveReportSubscription{}, fmt.Errorf("GetActiveReportSubscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *reportRepository) SetReportSubscriptionSent(ctx context.Context, subscriptionId int, sentUntil time.Time) error {
+	query := `
+	with deleted as (
+		delete from "ReportSubscriptionDelivery"
+		where subscription_id = $1 and period_end <= $2
+	)
+	update "ReportSubscription"
+	set last_sent_until = $2
+	where id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, subscriptionId, sentUntil)
+	if err != nil {
+		return fmt.Errorf("SetReportSubscriptionSent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) GetReportSubscriptionDeliveries(ctx context.Context, subscriptionId int, periodEnd time.Time) ([]int, error) {
+	query := `
+	select employee_id from "ReportSubscriptionDelivery"
+	where subscription_id = $1 and period_end = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, subscriptionId, periodEnd)
+	employeeIds, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return []int{}, fmt.Errorf("GetReportSubscriptionDeliveries: %w", err)
+	}
+
+	return employeeIds, nil
+}
+
+func (r *reportRepository) NewReportSubscriptionDelivery(ctx context.Context, subscriptionId int, employeeId int, periodEnd time.Time) error {
+	query := `
+	insert into "ReportSubscriptionDelivery" (subscription_id, employee_id, period_end)
+	values ($1, $2, $3)
+	on conflict do nothing
+	`
+
+	_, err := r.db.Exec(ctx, query, subscriptionId, employeeId, periodEnd)
+	if err != nil {
+		return fmt.Errorf("NewReportSubscriptionDelivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) NewReportExport(ctx context.Context, export domain.NewReportExport) (int, error) {
+	query := `
+	insert into "ReportExport" (merchant_id, employee_id, dataset, format, start_day, end_day, include_pii)
+	values ($1, $2, $3, $4, $5, $6, $7)
+	returning id
+	`
+
+	var exportId int
+	err := r.db.QueryRow(ctx, query, export.MerchantId, export.EmployeeId, export.Dataset, export.Format, export.StartDay,
+		export.EndDay, export.IncludePii).Scan(&exportId)
+	if err != nil {
+		return 0, fmt.Errorf("NewReportExport: %w", err)
+	}
+
+	return exportId, nil
+}
+
+const reportExportColumns = `id, merchant_id, employee_id, dataset, format, start_day, end_day, include_pii, status, error, created_at,
+	finished_at, expires_at`
+
+func (r *reportRepository) GetReportExport(ctx context.Context, merchantId uuid.UUID, exportId int) (domain.ReportExport, error) {
+	query := `
+	select ` + reportExportColumns + `
+	from "ReportExport"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, exportId)
+	export, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.ReportExport])
+	if err != nil {
+		return domain.ReportExport{}, fmt.Errorf("GetReportExport: %w", err)
+	}
+
+	return export, nil
+}
+
+func (r *reportRepository) GetReportExports(ctx context.Context, merchantId uuid.UUID) ([]domain.ReportExport, error) {
+	query := `
+	select ` + reportExportColumns + `
+	from "ReportExport"
+	where merchant_id = $1
+	order by created_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	exports, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ReportExport])
+	if err != nil {
+		return []domain.ReportExport{}, fmt.Errorf("GetReportExports: %w", err)
+	}
+
+	return exports, nil
+}
+
+func (r *reportRepository) GetReportExportFile(ctx context.Context, merchantId uuid.UUID, exportId int) ([]byte, error) {
+	query := `
+	select file
+	from "ReportExport"
+	where merchant_id = $1 and id = $2 and status = 'completed' and expires_at > now()
+	`
+
+	var file []byte
+	err := r.db.QueryRow(ctx, query, merchantId, exportId).Scan(&file)
+	if err != nil {
+		return nil, fmt.Errorf("GetReportExportFile: %w", err)
+	}
+
+	return file, nil
+}
+
+func (r *reportRepository) UpdateReportExport(ctx context.Context, exportId int, result domain.DataExportResult) error {
+	query := `
+	update "ReportExport"
+	set status = $2, file = $3, error = $4, expires_at = $5,
+		finished_at = case when $2 in ('completed', 'failed') then now() end
+	where id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, exportId, result.Status, result.File, result.Error, result.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("UpdateReportExport: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) DeleteExpiredReportExports(ctx context.Context, now time.Time) error {
+	query := `
+	delete from "ReportExport"
+	where expires_at < $1
+	`
+
+	_, err := r.db.Exec(ctx, query, now)
+	if err != nil {
+		return fmt.Errorf("DeleteExpiredReportExports: %w", err)
+	}
+
+	return nil
+}
+
+func (r *reportRepository) GetExportBookings(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time) ([]domain.ExportBooking, error) {
+	query := `
+	select b.id, b.status, b.booking_type, b.from_date, b.to_date, b.service_name,
+		nullif(concat_ws(' ', coalesce(e.first_name, u.first_name), coalesce(e.last_name, u.last_name)), '') as employee_name,
+		b.formatted_location, b.current_participants, b.price_per_person, b.total_price,
+		b.cancelled_by_merchant_on is not null as cancelled_by_merchant
+	from "Booking" b
+	left join "Employee" e on e.id = b.employee_id
+	left join "User" u on u.id = e.user_id
+	where b.merchant_id = $1 and b.from_date >= $2 and b.from_date < $3
+	order by b.from_date, b.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, from, to)
+	bookings, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ExportBooking])
+	if err != nil {
+		return []domain.ExportBooking{}, fmt.Errorf("GetExportBookings: %w", err)
+	}
+
+	return bookings, nil
+}
+
+func (r *reportRepository) GetExportParticipants(ctx context.Context, merchantId uuid.UUID, from time.Time, to time.Time,
+	includePii bool) ([]domain.ExportParticipant, error) {
+	query := `
+	select b.id as booking_id, b.from_date, b.service_name, coalesce(bp.transferred_to, bp.customer_id) as customer_id,
+		coalesce(c.first_name, u.first_name) as first_name, coalesce(c.last_name, u.last_name) as last_name,
+		case when $4 then coalesce(c.email, u.email) end as email,
+		case when $4 then coalesce(c.phone_number, u.phone_number) end as phone_number,
+		bp.status, coalesce(bp.price_per_person, b.price_per_person) as price_per_person, bp.cancelled_on
+	from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	left join "Customer" c on c.id = coalesce(bp.transferred_to, bp.customer_id)
+	left join "User" u on u.id = c.user_id
+	where b.merchant_id = $1 and b.from_date >= $2 and b.from_date < $3
+	order by b.from_date, b.id, bp.id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, from, to, includePii)
+	participants, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.ExportParticipant])
+	if err != nil {
+		return []domain.ExportParticipant{}, fmt.Errorf("GetExportParticipants: %w", err)
+	}
+
+	return participants, nil
+}
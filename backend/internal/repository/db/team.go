@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 )
 
@@ -24,11 +25,11 @@ func (r *teamRepository) WithTx(tx db.DBTX) domain.TeamRepository {
 
 func (r *teamRepository) NewEmployee(ctx context.Context, merchantId uuid.UUID, emp domain.PublicEmployee) error {
 	query := `
-	insert into "Employee" (user_id, merchant_id, role, first_name, last_name, email, phone_number, is_active)
-	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	insert into "Employee" (user_id, merchant_id, role, role_id, first_name, last_name, email, phone_number, is_active)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err := r.db.Exec(ctx, query, emp.UserId, merchantId, emp.Role, emp.FirstName, emp.LastName, emp.Email, emp.PhoneNumber, emp.IsActive)
+	_, err := r.db.Exec(ctx, query, emp.UserId, merchantId, emp.Role, emp.RoleId, emp.FirstName, emp.LastName, emp.Email, emp.PhoneNumber, emp.IsActive)
 	if err != nil {
 		return fmt.Errorf("NewEmployee: %w", err)
 	}
@@ -39,16 +40,20 @@ func (r *teamRepository) NewEmployee(ctx context.Context, merchantId uuid.UUID,
 func (r *teamRepository) UpdateEmployee(ctx context.Context, merchantId uuid.UUID, employee domain.PublicEmployee) error {
 	query := `
 	update "Employee"
-	set role = $3, first_name = $4, last_name = $5, email = $6, phone_number = $7, is_active = $8
-	where merchant_id = $1 and id = $2
+	set role = $3, role_id = $4, first_name = $5, last_name = $6, email = $7, phone_number = $8, is_active = $9
+	where merchant_id = $1 and id = $2 and role <> 'owner'
 	`
 
-	_, err := r.db.Exec(ctx, query, merchantId, employee.Id, employee.Role, employee.FirstName, employee.LastName, employee.Email,
+	tag, err := r.db.Exec(ctx, query, merchantId, employee.Id, employee.Role, employee.RoleId, employee.FirstName, employee.LastName, employee.Email,
 		employee.PhoneNumber, employee.IsActive)
 	if err != nil {
 		return fmt.Errorf("UpdateEmployee: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UpdateEmployee: %w", pgx.ErrNoRows)
+	}
+
 	return nil
 }
 
@@ -58,17 +63,21 @@ func (r *teamRepository) DeleteEmployee(ctx context.Context, merchantId uuid.UUI
 	where merchant_id = $1 and id = $2 and role not in ('owner')
 	`
 
-	_, err := r.db.Exec(ctx, query, merchantId, employeeId)
+	tag, err := r.db.Exec(ctx, query, merchantId, employeeId)
 	if err != nil {
 		return fmt.Errorf("DeleteEmployee: %w", err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteEmployee: %w", pgx.ErrNoRows)
+	}
+
 	return nil
 }
 
 func (r *teamRepository) GetEmployee(ctx context.Context, merchantId uuid.UUID, memberId int) (domain.PublicEmployee, error) {
 	query := `
-	select e.id, e.user_id, e.role, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
+	select e.id, e.user_id, e.role, e.role_id, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
 		coalesce(e.email, u.email) as email, coalesce(e.phone_number, u.phone_number) as phone_number, e.is_active
 	from "Employee" e
 	left join "User" u on u.id = e.user_id
@@ -86,7 +95,7 @@ func (r *teamRepository) GetEmployee(ctx context.Context, merchantId uuid.UUID,
 
 func (r *teamRepository) GetEmployees(ctx context.Context, merchantId uuid.UUID) ([]domain.PublicEmployee, error) {
 	query := `
-	select e.id, e.user_id, e.role, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
+	select e.id, e.user_id, e.role, e.role_id, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
 		coalesce(e.email, u.email) as email, coalesce(e.phone_number, u.phone_number) as phone_number, e.is_active
 	from "Employee" e
 	left join "User" u on u.id = e.user_id
@@ -103,7 +112,7 @@ func (r *teamRepository) GetEmployees(ctx context.Context, merchantId uuid.UUID)
 
 func (r *teamRepository) GetActiveEmployees(ctx context.Context, merchantId uuid.UUID) ([]domain.PublicEmployee, error) {
 	query := `
-	select e.id, e.user_id, e.role, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
+	select e.id, e.user_id, e.role, e.role_id, coalesce(e.first_name, u.first_name) as first_name, coalesce(e.last_name, u.last_name) as last_name,
 		coalesce(e.email, u.email) as email, coalesce(e.phone_number, u.phone_number) as phone_number, e.is_active
 	from "Employee" e
 	left join "User" u on u.id = e.user_id
@@ -134,3 +143,131 @@ func (r *teamRepository) GetMerchantIdByEmployee(ctx context.Context, employeeId
 
 	return merchantId, nil
 }
+
+func (r *teamRepository) NewRole(ctx context.Context, merchantId uuid.UUID, role domain.Role) (int, error) {
+	query := `
+	insert into "Role" (merchant_id, name, permissions)
+	values ($1, $2, $3)
+	returning id
+	`
+
+	var roleId int
+	err := r.db.QueryRow(ctx, query, merchantId, role.Name, permissionsToStrings(role.Permissions)).Scan(&roleId)
+	if err != nil {
+		return 0, fmt.Errorf("NewRole: %w", err)
+	}
+
+	return roleId, nil
+}
+
+func (r *teamRepository) UpdateRole(ctx context.Context, merchantId uuid.UUID, role domain.Role) error {
+	query := `
+	update "Role"
+	set name = $3, permissions = $4
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, role.Id, role.Name, permissionsToStrings(role.Permissions))
+	if err != nil {
+		return fmt.Errorf("UpdateRole: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UpdateRole: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *teamRepository) DeleteRole(ctx context.Context, merchantId uuid.UUID, roleId int) error {
+	query := `
+	delete from "Role" r
+	where r.merchant_id = $1 and r.id = $2 and not exists (
+		select 1 from "Employee" e where e.role_id = r.id
+	)
+	`
+
+	tag, err := r.db.Exec(ctx, query, merchantId, roleId)
+	if err != nil {
+		return fmt.Errorf("DeleteRole: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("DeleteRole: the role is still assigned to employees")
+	}
+
+	return nil
+}
+
+func (r *teamRepository) GetRole(ctx context.Context, merchantId uuid.UUID, roleId int) (domain.Role, error) {
+	query := `
+	select id, merchant_id, name, permissions
+	from "Role"
+	where merchant_id = $1 and id = $2
+	`
+
+	var role domain.Role
+	var permissions []string
+
+	err := r.db.QueryRow(ctx, query, merchantId, roleId).Scan(&role.Id, &role.MerchantId, &role.Name, &permissions)
+	if err != nil {
+		return domain.Role{}, fmt.Errorf("GetRole: %w", err)
+	}
+
+	role.Permissions = stringsToPermissions(permissions)
+
+	return role, nil
+}
+
+func (r *teamRepository) GetRoles(ctx context.Context, merchantId uuid.UUID) ([]domain.Role, error) {
+	query := `
+	select id, merchant_id, name, permissions
+	from "Role"
+	where merchant_id = $1
+	order by name
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	roles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Role, error) {
+		var role domain.Role
+		var permissions []string
+
+		err := row.Scan(&role.Id, &role.MerchantId, &role.Name, &permissions)
+		role.Permissions = stringsToPermissions(permissions)
+
+		return role, err
+	})
+	if err != nil {
+		return []domain.Role{}, fmt.Errorf("GetRoles: %w", err)
+	}
+
+	if len(roles) == 0 {
+		roles = []domain.Role{}
+	}
+
+	return roles, nil
+}
+
+func permissionsToStrings(permissions []types.Permission) []string {
+	result := make([]string, len(permissions))
+	for i, p := range permissions {
+		result[i] = p.String()
+	}
+
+	return result
+}
+
+// unknown permissions are skipped, so removing a permission does not break existing roles
+func stringsToPermissions(permissions []string) []types.Permission {
+	result := []types.Permission{}
+	for _, p := range permissions {
+		permission, err := types.NewPermission(p)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, permission)
+	}
+
+	return result
+}
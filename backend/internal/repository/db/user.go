@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -111,10 +112,11 @@ func (r *userRepository) GetUserLanguage(ctx context.Context, userId uuid.UUID)
 
 func (r *userRepository) GetEmployeeByUser(ctx context.Context, merchantId uuid.UUID, userId uuid.UUID) (domain.EmployeeAuthInfo, error) {
 	query := `
-	select e.id, l.id as location_id, e.merchant_id, e.role
+	select e.id, l.id as location_id, e.merchant_id, e.role, e.role_id, r.permissions as custom_permissions
 	from "Employee" e
 	join "Location" l on l.merchant_id = e.merchant_id
-	where e.merchant_id = $1 and user_id = $2
+	left join "Role" r on r.id = e.role_id
+	where e.merchant_id = $1 and e.user_id = $2
 	`
 
 	rows, _ := r.db.Query(ctx, query, merchantId, userId)
@@ -128,10 +130,11 @@ func (r *userRepository) GetEmployeeByUser(ctx context.Context, merchantId uuid.
 
 func (r *userRepository) GetEmployeesByUser(ctx context.Context, userId uuid.UUID) ([]domain.EmployeeAuthInfo, error) {
 	query := `
-	select e.id, l.id as location_id, e.merchant_id, e.role
+	select e.id, l.id as location_id, e.merchant_id, e.role, e.role_id, r.permissions as custom_permissions
 	from "Employee" e
 	join "Location" l on l.merchant_id = e.merchant_id
-	where user_id = $1
+	left join "Role" r on r.id = e.role_id
+	where e.user_id = $1
 	`
 
 	rows, _ := r.db.Query(ctx, query, userId)
@@ -255,3 +258,279 @@ func (r *userRepository) FindOauthUser(ctx context.Context, provider types.AuthP
 
 	return id, nil
 }
+
+func (r *userRepository) NewSession(ctx context.Context, session domain.Session) error {
+	query := `
+	insert into "Session" (id, user_id, refresh_token_hash, device_name, ip_address, user_agent, expires_at)
+	values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Exec(ctx, query, session.Id, session.UserId, session.RefreshTokenHash, session.DeviceName, session.IpAddress,
+		session.UserAgent, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("NewSession: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetSession(ctx context.Context, sessionId uuid.UUID) (domain.Session, error) {
+	query := `
+	select *
+	from "Session"
+	where id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, sessionId)
+	session, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Session])
+	if err != nil {
+		return domain.Session{}, fmt.Errorf("GetSession: %w", err)
+	}
+
+	return session, nil
+}
+
+func (r *userRepository) GetActiveSessions(ctx context.Context, userId uuid.UUID) ([]domain.Session, error) {
+	query := `
+	select *
+	from "Session"
+	where user_id = $1 and revoked_on is null and expires_at > now()
+	order by last_seen_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId)
+	sessions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Session])
+	if err != nil {
+		return []domain.Session{}, fmt.Errorf("GetActiveSessions: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		sessions = []domain.Session{}
+	}
+
+	return sessions, nil
+}
+
+func (r *userRepository) RotateSession(ctx context.Context, sessionId uuid.UUID, oldHash string, newHash string, ipAddress string,
+	userAgent string, expiresAt time.Time) (bool, error) {
+	query := `
+	update "Session"
+	set previous_token_hash = refresh_token_hash, refresh_token_hash = $3, ip_address = $4, user_agent = $5, expires_at = $6,
+		rotated_at = now(), last_seen_at = now()
+	where id = $1 and refresh_token_hash = $2 and revoked_on is null and expires_at > now()
+	`
+
+	tag, err := r.db.Exec(ctx, query, sessionId, oldHash, newHash, ipAddress, userAgent, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("RotateSession: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *userRepository) TouchSession(ctx context.Context, sessionId uuid.UUID, ipAddress string, userAgent string) error {
+	query := `
+	update "Session"
+	set ip_address = $2, user_agent = $3, last_seen_at = now()
+	where id = $1 and revoked_on is null
+	`
+
+	_, err := r.db.Exec(ctx, query, sessionId, ipAddress, userAgent)
+	if err != nil {
+		return fmt.Errorf("TouchSession: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) RevokeSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error {
+	query := `
+	update "Session"
+	set revoked_on = now()
+	where user_id = $1 and id = $2 and revoked_on is null
+	`
+
+	tag, err := r.db.Exec(ctx, query, userId, sessionId)
+	if err != nil {
+		return fmt.Errorf("RevokeSession: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("RevokeSession: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *userRepository) RevokeAllSessions(ctx context.Context, userId uuid.UUID) error {
+	query := `
+	update "Session"
+	set revoked_on = now()
+	where user_id = $1 and revoked_on is null
+	`
+
+	_, err := r.db.Exec(ctx, query, userId)
+	if err != nil {
+		return fmt.Errorf("RevokeAllSessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) DeleteExpiredSessions(ctx context.Context, before time.Time) error {
+	query := `
+	delete from "Session"
+	where expires_at < $1 or revoked_on < $1
+	`
+
+	_, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return fmt.Errorf("DeleteExpiredSessions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetUserDataCustomers(ctx context.Context, userId uuid.UUID) ([]domain.UserDataCustomer, error) {
+	query := `
+	select c.id, m.name as merchant_name, c.first_name, c.last_name, c.email, c.phone_number, c.birthday, c.note,
+		c.is_blacklisted, c.blacklist_reason, c.tags,
+		(select coalesce(jsonb_object_agg(cf.name, c.custom_fields -> cf.id::text), '{}')
+		 from "CustomerField" cf
+		 where cf.merchant_id = c.merchant_id and c.custom_fields ? cf.id::text) as custom_fields
+	from "Customer" c
+	join "Merchant" m on c.merchant_id = m.id
+	where c.user_id = $1
+	order by m.name
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId)
+	customers, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.UserDataCustomer])
+	if err != nil {
+		return []domain.UserDataCustomer{}, fmt.Errorf("GetUserDataCustomers: %w", err)
+	}
+
+	return customers, nil
+}
+
+func (r *userRepository) GetUserDataBookings(ctx context.Context, userId uuid.UUID) ([]domain.UserDataBooking, error) {
+	query := `
+	select b.id as booking_id, bp.customer_id, m.name as merchant_name, b.service_name, b.booking_type, b.status as booking_status,
+		bp.status, b.from_date, b.to_date, coalesce(bp.price_per_person, b.price_per_person) as price_per_person, b.formatted_location, bp.customer_note, bp.cancelled_on,
+		bp.cancellation_reason, coalesce((
+			select jsonb_agg(jsonb_build_object('form_name', ir.form_name, 'questions', ir.questions, 'answers', ir.answers,
+				'signature', ir.signature, 'signed_at', ir.signed_at) order by ir.id)
+			from "IntakeResponse" ir
+			where ir.participant_id = bp.id
+		), '[]') as intake_responses
+	from "BookingParticipant" bp
+	join "Booking" b on bp.booking_id = b.id
+	join "Customer" c on bp.customer_id = c.id
+	join "Merchant" m on b.merchant_id = m.id
+	where c.user_id = $1
+	order by b.from_date desc, b.id desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId)
+	bookings, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.UserDataBooking])
+	if err != nil {
+		return []domain.UserDataBooking{}, fmt.Errorf("GetUserDataBookings: %w", err)
+	}
+
+	return bookings, nil
+}
+
+func (r *userRepository) NewDataExport(ctx context.Context, userId uuid.UUID) (int, error) {
+	query := `
+	insert into "DataExport" (user_id)
+	values ($1)
+	returning id
+	`
+
+	var exportId int
+	err := r.db.QueryRow(ctx, query, userId).Scan(&exportId)
+	if err != nil {
+		return 0, fmt.Errorf("NewDataExport: %w", err)
+	}
+
+	return exportId, nil
+}
+
+func (r *userRepository) GetDataExport(ctx context.Context, userId uuid.UUID, exportId int) (domain.DataExport, error) {
+	query := `
+	select id, user_id, status, error, created_at, finished_at, expires_at
+	from "DataExport"
+	where user_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId, exportId)
+	export, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.DataExport])
+	if err != nil {
+		return domain.DataExport{}, fmt.Errorf("GetDataExport: %w", err)
+	}
+
+	return export, nil
+}
+
+func (r *userRepository) GetDataExports(ctx context.Context, userId uuid.UUID) ([]domain.DataExport, error) {
+	query := `
+	select id, user_id, status, error, created_at, finished_at, expires_at
+	from "DataExport"
+	where user_id = $1
+	order by created_at desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, userId)
+	exports, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.DataExport])
+	if err != nil {
+		return []domain.DataExport{}, fmt.Errorf("GetDataExports: %w", err)
+	}
+
+	return exports, nil
+}
+
+func (r *userRepository) GetDataExportFile(ctx context.Context, userId uuid.UUID, exportId int) ([]byte, error) {
+	query := `
+	select file
+	from "DataExport"
+	where user_id = $1 and id = $2 and status = 'completed' and expires_at > now()
+	`
+
+	var file []byte
+	err := r.db.QueryRow(ctx, query, userId, exportId).Scan(&file)
+	if err != nil {
+		return nil, fmt.Errorf("GetDataExportFile: %w", err)
+	}
+
+	return file, nil
+}
+
+func (r *userRepository) UpdateDataExport(ctx context.Context, exportId int, result domain.DataExportResult) error {
+	query := `
+	update "DataExport"
+	set status = $2, file = $3, error = $4, expires_at = $5,
+		finished_at = case when $2 in ('completed', 'failed') then now() end
+	where id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, exportId, result.Status, result.File, result.Error, result.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("UpdateDataExport: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) DeleteExpiredDataExports(ctx context.Context, now time.Time) error {
+	query := `
+	delete from "DataExport"
+	where expires_at < $1
+	`
+
+	_, err := r.db.Exec(ctx, query, now)
+	if err != nil {
+		return fmt.Errorf("DeleteExpiredDataExports: %w", err)
+	}
+
+	return nil
+}
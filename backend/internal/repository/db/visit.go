@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type visitRepository struct {
+	db db.DBTX
+}
+
+func NewVisitRepository(db db.DBTX) domain.VisitRepository {
+	return &visitRepository{db: db}
+}
+
+func (r *visitRepository) WithTx(tx db.DBTX) domain.VisitRepository {
+	return &visitRepository{db: tx}
+}
+
+const visitColumns = `bp.id as participant_id, b.id as booking_id, coalesce(bp.transferred_to, bp.customer_id) as customer_id,
+	b.employee_id, b.service_name, bp.status, b.from_date, b.to_date`
+
+func (r *visitRepository) GetCustomerVisits(ctx context.Context, merchantId uuid.UUID, customerId uuid.UUID) ([]domain.Visit, error) {
+	query := `
+	select ` + visitColumns + `
+	from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	where b.merchant_id = $1 and coalesce(bp.transferred_to, bp.customer_id) = $2 and b.from_date <= now()
+		and bp.status <> 'cancelled' and b.cancelled_by_merchant_on is null
+	order by b.from_date desc
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, customerId)
+	visits, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Visit])
+	if err != nil {
+		return []domain.Visit{}, fmt.Errorf("GetCustomerVisits: %w", err)
+	}
+
+	return visits, nil
+}
+
+func (r *visitRepository) GetVisit(ctx context.Context, merchantId uuid.UUID, participantId int) (domain.Visit, error) {
+	query := `
+	select ` + visitColumns + `
+	from "BookingParticipant" bp
+	join "Booking" b on b.id = bp.booking_id
+	where b.merchant_id = $1 and bp.id = $2 and bp.customer_id is not null
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, participantId)
+	visit, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Visit])
+	if err != nil {
+		return domain.Visit{}, fmt.Errorf("GetVisit: %w", err)
+	}
+
+	return visit, nil
+}
+
+func (r *visitRepository) NewVisitNote(ctx context.Context, note domain.VisitNote) (int, error) {
+	query := `
+	insert into "VisitNote" (merchant_id, participant_id, employee_id, note)
+	values ($1, $2, $3, $4)
+	returning id
+	`
+
+	var noteId int
+	err := r.db.QueryRow(ctx, query, note.MerchantId, note.ParticipantId, note.EmployeeId, note.Note).Scan(&noteId)
+	if err != nil {
+		return 0, fmt.Errorf("NewVisitNote: %w", err)
+	}
+
+	return noteId, nil
+}
+
+func (r *visitRepository) UpdateVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int, note string) error {
+	query := `
+	update "VisitNote"
+	set note = $3, updated_at = now()
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, noteId, note)
+	if err != nil {
+		return fmt.Errorf("UpdateVisitNote: %w", err)
+	}
+
+	return nil
+}
+
+func (r *visitRepository) DeleteVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int) error {
+	query := `
+	delete from "VisitNote"
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, noteId)
+	if err != nil {
+		return fmt.Errorf("DeleteVisitNote: %w", err)
+	}
+
+	return nil
+}
+
+func (r *visitRepository) GetVisitNote(ctx context.Context, merchantId uuid.UUID, noteId int) (domain.VisitNote, error) {
+	query := `
+	select * from "VisitNote"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, noteId)
+	note, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.VisitNote])
+	if err != nil {
+		return domain.VisitNote{}, fmt.Errorf("GetVisitNote: %w", err)
+	}
+
+	return note, nil
+}
+
+func (r *visitRepository) GetVisitNotes(ctx context.Context, merchantId uuid.UUID, participantIds []int) ([]domain.VisitNote, error) {
+	query := `
+	select * from "VisitNote"
+	where merchant_id = $1 and participant_id = any($2)
+	order by created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, participantIds)
+	notes, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.VisitNote])
+	if err != nil {
+		return []domain.VisitNote{}, fmt.Errorf("GetVisitNotes: %w", err)
+	}
+
+	return notes, nil
+}
+
+func (r *visitRepository) NewVisitAttachment(ctx context.Context, attachment domain.VisitAttachment) (int, error) {
+	query := `
+	insert into "VisitAttachment" (merchant_id, participant_id, employee_id, file_name, content_type, size, storage_key)
+	values ($1, $2, $3, $4, $5, $6, $7)
+	returning id
+	`
+
+	var attachmentId int
+	err := r.db.QueryRow(ctx, query, attachment.MerchantId, attachment.ParticipantId, attachment.EmployeeId, attachment.FileName,
+		attachment.ContentType, attachment.Size, attachment.StorageKey).Scan(&attachmentId)
+	if err != nil {
+		return 0, fmt.Errorf("NewVisitAttachment: %w", err)
+	}
+
+	return attachmentId, nil
+}
+
+const visitAttachmentColumns = `id, merchant_id, participant_id, employee_id, file_name, content_type, size, storage_key, created_at`
+
+func (r *visitRepository) GetVisitAttachment(ctx context.Context, merchantId uuid.UUID, attachmentId int) (domain.VisitAttachment, error) {
+	query := `
+	select ` + visitAttachmentColumns + ` from "VisitAttachment"
+	where merchant_id = $1 and id = $2 and deleted_at is null
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, attachmentId)
+	attachment, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.VisitAttachment])
+	if err != nil {
+		return domain.VisitAttachment{}, fmt.Errorf("GetVisitAttachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func (r *visitRepository) GetVisitAttachments(ctx context.Context, merchantId uuid.UUID, participantIds []int) ([]domain.VisitAttachment, error) {
+	query := `
+	select ` + visitAttachmentColumns + ` from "VisitAttachment"
+	where merchant_id = $1 and participant_id = any($2) and deleted_at is null
+	order by created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, participantIds)
+	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.VisitAttachment])
+	if err != nil {
+		return []domain.VisitAttachment{}, fmt.Errorf("GetVisitAttachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *visitRepository) CountVisitAttachments(ctx context.Context, merchantId uuid.UUID, participantId int) (int, error) {
+	query := `
+	select count(*) from "VisitAttachment"
+	where merchant_id = $1 and participant_id = $2 and deleted_at is null
+	`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, merchantId, participantId).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("CountVisitAttachments: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *visitRepository) DeleteVisitAttachment(ctx context.Context, merchantId uuid.UUID, attachmentId int) error {
+	query := `
+	update "VisitAttachment"
+	set deleted_at = now()
+	where merchant_id = $1 and id = $2 and deleted_at is null
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, attachmentId)
+	if err != nil {
+		return fmt.Errorf("DeleteVisitAttachment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *visitRepository) GetDeletedVisitAttachments(ctx context.Context, limit int) ([]domain.VisitAttachment, error) {
+	query := `
+	select ` + visitAttachmentColumns + ` from "VisitAttachment"
+	where deleted_at is not null or participant_id is null
+	order by id
+	limit $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, limit)
+	attachments, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.VisitAttachment])
+	if err != nil {
+		return []domain.VisitAttachment{}, fmt.Errorf("GetDeletedVisitAttachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *visitRepository) PurgeVisitAttachments(ctx context.Context, attachmentIds []int) error {
+	query := `
+	delete from "VisitAttachment"
+	where id = any($1)
+	`
+
+	_, err := r.db.Exec(ctx, query, attachmentIds)
+	if err != nil {
+		return fmt.Errorf("PurgeVisitAttachments: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type webhookRepository struct {
+	db db.DBTX
+}
+
+func NewWebhookRepository(db db.DBTX) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) WithTx(tx db.DBTX) domain.WebhookRepository {
+	return &webhookRepository{db: tx}
+}
+
+func (r *webhookRepository) NewWebhookEndpoint(ctx context.Context, endpoint domain.WebhookEndpoint) (int, error) {
+	query := `
+	insert into "WebhookEndpoint" (merchant_id, url, description, secret, events, is_active)
+	values ($1, $2, $3, $4, $5, $6)
+	returning id
+	`
+
+	var endpointId int
+	err := r.db.QueryRow(ctx, query, endpoint.MerchantId, endpoint.Url, endpoint.Description, endpoint.Secret,
+		webhookEventsToStrings(endpoint.Events), endpoint.IsActive).Scan(&endpointId)
+	if err != nil {
+		return 0, fmt.Errorf("NewWebhookEndpoint: %w", err)
+	}
+
+	return endpointId, nil
+}
+
+func (r *webhookRepository) UpdateWebhookEndpoint(ctx context.Context, endpoint domain.WebhookEndpoint) error {
+	query := `
+	update "WebhookEndpoint"
+	set url = $3, description = $4, events = $5, is_active = $6
+	where merchant_id = $1 and id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, endpoint.MerchantId, endpoint.Id, endpoint.Url, endpoint.Description,
+		webhookEventsToStrings(endpoint.Events), endpoint.IsActive)
+	if err != nil {
+		return fmt.Errorf("UpdateWebhookEndpoint: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("UpdateWebhookEndpoint: %w", pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteWebhookEndpoint(ctx context.Context, merchantId uuid.UUID, endpointId int) error {
+	query := `
+	delete from "WebhookEndpoint"
+	where merchant_id = $1 and id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, merchantId, endpointId)
+	if err != nil {
+		return fmt.Errorf("DeleteWebhookEndpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetWebhookEndpoint(ctx context.Context, merchantId uuid.UUID, endpointId int) (domain.WebhookEndpoint, error) {
+	query := `
+	select id, merchant_id, url, description, secret, events, is_active, created_at
+	from "WebhookEndpoint"
+	where merchant_id = $1 and id = $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, endpointId)
+	endpoint, err := pgx.CollectExactlyOneRow(rows, scanWebhookEndpoint)
+	if err != nil {
+		return domain.WebhookEndpoint{}, fmt.Errorf("GetWebhookEndpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) GetWebhookEndpointById(ctx context.Context, endpointId int) (domain.WebhookEndpoint, error) {
+	query := `
+	select id, merchant_id, url, description, secret, events, is_active, created_at
+	from "WebhookEndpoint"
+	where id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, endpointId)
+	endpoint, err := pgx.CollectExactlyOneRow(rows, scanWebhookEndpoint)
+	if err != nil {
+		return domain.WebhookEndpoint{}, fmt.Errorf("GetWebhookEndpointById: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) GetWebhookEndpoints(ctx context.Context, merchantId uuid.UUID) ([]domain.WebhookEndpoint, error) {
+	query := `
+	select id, merchant_id, url, description, secret, events, is_active, created_at
+	from "WebhookEndpoint"
+	where merchant_id = $1
+	order by id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId)
+	endpoints, err := pgx.CollectRows(rows, scanWebhookEndpoint)
+	if err != nil {
+		return []domain.WebhookEndpoint{}, fmt.Errorf("GetWebhookEndpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		endpoints = []domain.WebhookEndpoint{}
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) GetSubscribedWebhookEndpoints(ctx context.Context, merchantId uuid.UUID, eventType types.WebhookEventType) ([]domain.WebhookEndpoint, error) {
+	query := `
+	select id, merchant_id, url, description, secret, events, is_active, created_at
+	from "WebhookEndpoint"
+	where merchant_id = $1 and is_active = true and $2 = any(events)
+	order by id
+	`
+
+	rows, _ := r.db.Query(ctx, query, merchantId, eventType.String())
+	endpoints, err := pgx.CollectRows(rows, scanWebhookEndpoint)
+	if err != nil {
+		return []domain.WebhookEndpoint{}, fmt.Errorf("GetSubscribedWebhookEndpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		endpoints = []domain.WebhookEndpoint{}
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) NewWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) (int64, error) {
+	query := `
+	insert into "WebhookDelivery" (webhook_endpoint_id, event_id, event_type, payload, status)
+	values ($1, $2, $3, $4, $5)
+	returning id
+	`
+
+	var deliveryId int64
+	err := r.db.QueryRow(ctx, query, delivery.WebhookEndpointId, delivery.EventId, delivery.EventType, delivery.Payload,
+		types.WebhookDeliveryPending).Scan(&deliveryId)
+	if err != nil {
+		return 0, fmt.Errorf("NewWebhookDelivery: %w", err)
+	}
+
+	return deliveryId, nil
+}
+
+func (r *webhookRepository) GetWebhookDelivery(ctx context.Context, deliveryId int64) (domain.WebhookDelivery, error) {
+	query := `
+	select id, webhook_endpoint_id, event_id, event_type, payload, status, attempts, response_status, error,
+		created_at, last_attempt_at, delivered_at
+	from "WebhookDelivery"
+	where id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, deliveryId)
+	delivery, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.WebhookDelivery])
+	if err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("GetWebhookDelivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (r *webhookRepository) GetWebhookDeliveries(ctx context.Context, endpointId int, limit int, cursorId *int64) ([]domain.WebhookDelivery, error) {
+	query := `
+	select id, webhook_endpoint_id, event_id, event_type, payload, status, attempts, response_status, error,
+		created_at, last_attempt_at, delivered_at
+	from "WebhookDelivery"
+	where webhook_endpoint_id = $1 and ($3::bigint is null or id < $3)
+	order by id desc
+	limit $2
+	`
+
+	rows, _ := r.db.Query(ctx, query, endpointId, limit, cursorId)
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.WebhookDelivery])
+	if err != nil {
+		return []domain.WebhookDelivery{}, fmt.Errorf("GetWebhookDeliveries: %w", err)
+	}
+
+	if len(deliveries) == 0 {
+		deliveries = []domain.WebhookDelivery{}
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateWebhookDeliveryAttempt(ctx context.Context, deliveryId int64, attempt domain.WebhookDeliveryAttempt) error {
+	query := `
+	update "WebhookDelivery"
+	set status = $2, attempts = attempts + 1, response_status = $3, error = $4, last_attempt_at = $5,
+		delivered_at = case when $2 = 'succeeded' then $5 else delivered_at end
+	where id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, deliveryId, attempt.Status, attempt.ResponseStatus, attempt.Error, attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("UpdateWebhookDeliveryAttempt: %w", err)
+	}
+
+	return nil
+}
+
+func scanWebhookEndpoint(row pgx.CollectableRow) (domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	var events []string
+
+	err := row.Scan(&endpoint.Id, &endpoint.MerchantId, &endpoint.Url, &endpoint.Description, &endpoint.Secret, &events,
+		&endpoint.IsActive, &endpoint.CreatedAt)
+	endpoint.Events = stringsToWebhookEvents(events)
+
+	return endpoint, err
+}
+
+func webhookEventsToStrings(events []types.WebhookEventType) []string {
+	result := make([]string, len(events))
+	for i, e := range events {
+		result[i] = e.String()
+	}
+
+	return result
+}
+
+// unknown events are skipped, so removing an event type does not break existing endpoints
+func stringsToWebhookEvents(events []string) []types.WebhookEventType {
+	result := []types.WebhookEventType{}
+	for _, e := range events {
+		event, err := types.NewWebhookEventType(e)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, event)
+	}
+
+	return result
+}
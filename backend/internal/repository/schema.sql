@@ -23,6 +23,21 @@ create table if not exists "User" (
     provider_id              text
 );
 
+create table if not exists "Session" (
+    ID                       uuid            primary key unique not null,
+    user_id                  uuid            references "User" (ID) on delete cascade not null,
+    refresh_token_hash       text            not null,
+    previous_token_hash      text,
+    device_name              text            not null,
+    ip_address               text            not null,
+    user_agent               text            not null,
+    created_at               timestamptz     not null default now(),
+    last_seen_at             timestamptz     not null default now(),
+    rotated_at               timestamptz     not null default now(),
+    expires_at               timestamptz     not null,
+    revoked_on               timestamptz
+);
+
 create table if not exists "Merchant" (
     ID                       uuid            primary key unique not null,
     name                     varchar(30)     not null,
@@ -57,11 +72,21 @@ create table if not exists "Location" (
     is_active                boolean          not null default true
 );
 
+-- custom roles of a merchant, permissions are validated in the backend
+create table if not exists "Role" (
+    ID                       serial          primary key unique not null,
+    merchant_id              uuid            references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)     not null,
+    permissions              text[]          not null default '{}',
+    constraint unique_merchant_role_name unique (merchant_id, name)
+);
+
 create table if not exists "Employee" (
     ID                       serial          primary key unique not null,
     user_id                  uuid            references "User" (ID) on delete set null,
     merchant_id              uuid            references "Merchant" (ID) on delete cascade not null,
     role                     text            default 'staff' check (role in ('owner', 'admin', 'staff')) not null,
+    role_id                  integer         references "Role" (ID) on delete set null,
     first_name               varchar(30),
     last_name                varchar(30),
     email                    varchar(320),
@@ -81,6 +106,15 @@ create table if not exists "ServiceCategory" (
     sequence                 integer         not null default 0
 );
 
+create table if not exists "TaxRate" (
+    ID                       serial          primary key unique not null,
+    merchant_id              uuid            references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(30)     not null,
+    percent                  numeric(4, 2)   check (percent >= 0) not null,
+    -- inclusive rates are already part of the price, exclusive ones are added on top of it
+    is_inclusive             boolean         not null
+);
+
 create table if not exists "Service" (
     ID                       serial          primary key unique not null,
     merchant_id              uuid            references "Merchant" (ID) on delete cascade not null,
@@ -100,7 +134,8 @@ create table if not exists "Service" (
     booking_window_min       integer,
     booking_window_max       integer,
     buffer_time              integer,
-    approval_policy          text            check (approval_policy in ('auto', 'manual', 'manual_for_new'))
+    approval_policy          text            check (approval_policy in ('auto', 'manual', 'manual_for_new')),
+    tax_rate_id              integer         references "TaxRate" (ID) on delete set null
 );
 
 create table if not exists "ServicePhase" (
@@ -127,6 +162,14 @@ create table if not exists "Customer" (
     note                    text,
     is_blacklisted          boolean default false not null,
     blacklist_reason        text,
+    tags                    text[] default '{}' not null,
+    -- values of the merchant's CustomerFields keyed by the field's id
+    custom_fields           jsonb default '{}' not null,
+    -- set when the personal data was erased, the row is kept for the booking statistics
+    anonymized_on           timestamptz,
+    -- set by the merchant's policy rules, the bookings of the customer need approval regardless of the approval policy
+    requires_approval       boolean default false not null,
+    approval_reason         text,
 
     constraint unique_merchant_user unique (merchant_id, user_id)
 );
@@ -151,7 +194,10 @@ create table if not exists "BookingSeries" (
     min_participants         integer         not null,
     max_participants         integer         not null,
     current_participants     integer         not null,
-    version                  integer         default 1 not null
+    version                  integer         default 1 not null,
+    -- the tax rate of the service when the series was created, the prices already include it
+    tax_name                 varchar(30),
+    tax_percent              numeric(4, 2)
 );
 
 create table if not exists "BookingSeriesParticipant" (
@@ -176,6 +222,49 @@ create table if not exists "BookingSeriesPhase" (
     constraint unique_booking_series_phase_sequence unique (booking_series_id, sequence)
 );
 
+-- a discount of the merchant, redeemed with its code or applied automatically if it has none, like happy hour pricing
+create table if not exists "Promotion" (
+    ID                       serial          primary key unique not null,
+    merchant_id              uuid            references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)     not null,
+    -- null for automatic rules
+    code                     varchar(30),
+    -- exactly one of them is set
+    percent                  numeric(5, 2)   check (percent > 0 and percent <= 100),
+    amount                   price           check ((amount).number > 0),
+    first_visit_only         boolean         not null default false,
+    -- null if the promotion can be used any number of times
+    max_uses                 integer         check (max_uses > 0),
+    max_uses_per_customer    integer         check (max_uses_per_customer > 0),
+    -- the time the promotion can be used in, open ended if null
+    valid_from               timestamptz,
+    valid_until              timestamptz,
+    -- the slots the promotion applies to in the timezone of the merchant, 0 is sunday and an empty list means every day
+    weekdays                 smallint[]      not null default '{}',
+    -- minutes since midnight, every time of the day if null
+    start_minute             integer         check (start_minute between 0 and 1439),
+    end_minute               integer         check (end_minute between 1 and 1440),
+    is_active                boolean         not null default true,
+    created_at               timestamptz     not null default now(),
+
+    constraint unique_promotion_code unique (merchant_id, code),
+    constraint promotion_one_discount check ((percent is null) <> (amount is null)),
+    constraint promotion_slot check ((start_minute is null) = (end_minute is null) and start_minute < end_minute)
+);
+
+-- the services a promotion applies to, a promotion without services and categories applies to every service
+create table if not exists "PromotionService" (
+    promotion_id             integer references "Promotion" (ID) on delete cascade not null,
+    service_id               integer references "Service" (ID) on delete cascade not null,
+    primary key (promotion_id, service_id)
+);
+
+create table if not exists "PromotionCategory" (
+    promotion_id             integer references "Promotion" (ID) on delete cascade not null,
+    category_id              integer references "ServiceCategory" (ID) on delete cascade not null,
+    primary key (promotion_id, category_id)
+);
+
 create table if not exists "Booking" (
     ID                       serial          primary key unique not null,
     status                   text            default 'booked' check (status in ('booked', 'confirmed', 'completed', 'cancelled', 'no-show')) not null,
@@ -201,7 +290,10 @@ create table if not exists "Booking" (
     cancelled_by_merchant_on timestamptz,
     cancellation_reason      text,
     occurrence_index         integer,
-    series_version           integer
+    series_version           integer,
+    -- the tax rate of the service when the booking was made, the prices already include it
+    tax_name                 varchar(30),
+    tax_percent              numeric(4, 2)
 );
 
 create table if not exists "BookingPhase" (
@@ -222,6 +314,10 @@ create table if not exists "BookingParticipant" (
     cancelled_on             timestamptz,
     cancellation_reason      text,
     transferred_to           uuid,
+    -- the promotion the participant got a discount from, the discounted price is in price_per_person
+    promotion_id             integer         references "Promotion" (ID) on delete set null,
+    -- null if the participant pays the price_per_person of the booking
+    price_per_person         price,
 
     constraint unique_booking_participant unique (booking_id, customer_id)
 );
@@ -247,7 +343,8 @@ create table if not exists "Product" (
     unit                     varchar(10)     check (unit in ('ml', 'g', 'pcs')) not null,
     max_amount               bigint          not null,
     current_amount           bigint          not null,
-    deleted_on               timestamptz
+    deleted_on               timestamptz,
+    tax_rate_id              integer         references "TaxRate" (ID) on delete set null
 );
 
 create table if not exists "ServiceProduct" (
@@ -324,4 +421,451 @@ create table if not exists "ExternalCalendarEvent" (
     last_synced_at           timestamptz         not null default now(),
 
     unique (external_calendar_id, external_event_id)
-);
\ No newline at end of file
+);
+create table if not exists "AuditLog" (
+    ID                       bigserial           primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    action                   text                not null,
+    entity_type              text                check (entity_type in ('booking', 'booking_participant', 'customer', 'service', 'merchant_settings', 'employee')) not null,
+    entity_id                text                not null,
+    before                   jsonb,
+    after                    jsonb,
+    request_id               text,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "WebhookEndpoint" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    url                      text                not null,
+    description              text,
+    secret                   text                not null,
+    events                   text[]              not null default '{}',
+    is_active                boolean             not null default true,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "WebhookDelivery" (
+    ID                       bigserial           primary key unique not null,
+    webhook_endpoint_id      integer             references "WebhookEndpoint" (ID) on delete cascade not null,
+    event_id                 uuid                not null,
+    event_type               text                not null,
+    payload                  jsonb               not null,
+    status                   text                default 'pending' check (status in ('pending', 'succeeded', 'failed')) not null,
+    attempts                 integer             not null default 0,
+    -- only the status of the response is kept, its body could leak the internals of the endpoint
+    response_status          integer,
+    error                    text,
+    created_at               timestamptz         not null default now(),
+    last_attempt_at          timestamptz,
+    delivered_at             timestamptz
+);
+
+create table if not exists "ApiKey" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    -- requests made with the key act as this employee
+    employee_id              integer             references "Employee" (ID) on delete cascade not null,
+    name                     varchar(50)         not null,
+    prefix                   varchar(16)         not null,
+    key_hash                 text                unique not null,
+    scopes                   text[]              not null default '{}',
+    rate_limit               integer             not null default 120,
+    last_used_at             timestamptz,
+    expires_at               timestamptz,
+    revoked_at               timestamptz,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "CustomerImport" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    status                   text                default 'pending' check (status in ('pending', 'running', 'completed', 'failed')) not null,
+    -- the uploaded csv, it's cleared when the import finishes
+    content                  text                not null,
+    options                  jsonb               not null,
+    total_rows               integer             not null,
+    processed_rows           integer             not null default 0,
+    created_count            integer             not null default 0,
+    duplicate_count          integer             not null default 0,
+    invalid_count            integer             not null default 0,
+    row_errors               jsonb               not null default '[]',
+    error                    text,
+    created_at               timestamptz         not null default now(),
+    finished_at              timestamptz
+);
+
+create table if not exists "CustomerMerge" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    -- the customer the duplicate was merged into
+    customer_id              uuid                references "Customer" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    -- snapshot of the deleted duplicate
+    merged_customer          jsonb               not null,
+    moved_participants       integer             not null,
+    moved_series_participants integer            not null,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "CustomerField" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)         not null,
+    field_type               text                check (field_type in ('text', 'number', 'date', 'select')) not null,
+    -- the choices of select fields
+    options                  text[]              not null default '{}',
+    is_required              boolean             not null default false,
+
+    constraint unique_customer_field_name unique (merchant_id, name)
+);
+
+create table if not exists "CustomerSegment" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)         not null,
+    filter                   jsonb               not null,
+    created_at               timestamptz         not null default now(),
+
+    constraint unique_customer_segment_name unique (merchant_id, name)
+);
+
+create table if not exists "DataExport" (
+    ID                       serial              primary key unique not null,
+    user_id                  uuid                references "User" (ID) on delete cascade not null,
+    status                   text                default 'pending' check (status in ('pending', 'running', 'completed', 'failed')) not null,
+    -- zip archive of the user's data, the export is deleted once it expires
+    file                     bytea,
+    error                    text,
+    created_at               timestamptz         not null default now(),
+    finished_at              timestamptz,
+    expires_at               timestamptz
+);
+
+create table if not exists "MarketingConsent" (
+    ID                       serial              primary key unique not null,
+    customer_id              uuid                references "Customer" (ID) on delete cascade not null,
+    channel                  text                check (channel in ('email', 'sms')) not null,
+    is_granted               boolean             not null,
+    source                   text                check (source in ('booking', 'merchant', 'unsubscribe_link')) not null,
+    -- the employee who recorded it if the source is merchant
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    updated_at               timestamptz         not null default now(),
+
+    constraint unique_customer_consent_channel unique (customer_id, channel)
+);
+
+create table if not exists "Campaign" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    name                     varchar(50)         not null,
+    subject                  varchar(200)        not null,
+    body                     text                not null,
+    status                   text                default 'pending' check (status in ('pending', 'sending', 'completed', 'cancelled')) not null,
+    -- the customer filter the recipients were selected with
+    filter                   jsonb               not null,
+    created_at               timestamptz         not null default now(),
+    finished_at              timestamptz
+);
+
+create table if not exists "CampaignRecipient" (
+    ID                       bigserial           primary key unique not null,
+    campaign_id              integer             references "Campaign" (ID) on delete cascade not null,
+    customer_id              uuid                references "Customer" (ID) on delete cascade not null,
+    unsubscribe_token        text                unique not null,
+    status                   text                default 'pending' check (status in ('pending', 'sent', 'skipped', 'failed')) not null,
+    error                    text,
+    sent_at                  timestamptz
+);
+
+create table if not exists "CustomerPolicyRule" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    event                    text                check (event in ('no-show', 'cancellation')) not null,
+    -- the action applies once the customer has this many events in the period
+    threshold                integer             check (threshold > 0) not null,
+    period_days              integer             check (period_days > 0) not null,
+    action                   text                check (action in ('require_approval', 'blacklist')) not null,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "VisitNote" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    participant_id           integer             references "BookingParticipant" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    note                     text                not null,
+    created_at               timestamptz         not null default now(),
+    updated_at               timestamptz         not null default now()
+);
+
+create table if not exists "VisitAttachment" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    -- set to null with the participant, so the cleanup job still removes the blob
+    participant_id           integer             references "BookingParticipant" (ID) on delete set null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    file_name                varchar(255)        not null,
+    content_type             text                not null,
+    size                     integer             not null,
+    storage_key              text                unique not null,
+    created_at               timestamptz         not null default now(),
+    -- deleted attachments are kept until the cleanup job removes their blob
+    deleted_at               timestamptz
+);
+
+create table if not exists "IntakeForm" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)         not null,
+    mode                     text                check (mode in ('once', 'every_time')) not null,
+    -- the questions in the order they are asked
+    questions                jsonb               not null default '[]',
+    created_at               timestamptz         not null default now(),
+    updated_at               timestamptz         not null default now()
+);
+
+create table if not exists "ServiceIntakeForm" (
+    -- a service can only have one form
+    service_id               integer             primary key references "Service" (ID) on delete cascade not null,
+    form_id                  integer             references "IntakeForm" (ID) on delete cascade not null
+);
+
+create table if not exists "IntakeResponse" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    form_id                  integer             references "IntakeForm" (ID) on delete set null,
+    participant_id           integer             references "BookingParticipant" (ID) on delete cascade not null,
+    -- the form as it was answered, later changes of the form do not affect it
+    form_name                varchar(50)         not null,
+    questions                jsonb               not null,
+    -- answers keyed by the id of the question
+    answers                  jsonb               not null default '{}',
+    -- the full name typed by the customer to sign the consents of the form
+    signature                varchar(100),
+    signed_at                timestamptz,
+    created_at               timestamptz         not null default now()
+);
+
+create table if not exists "BookingDailyRollup" (
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    -- the day of the start of the bookings in the timezone of the merchant
+    day                      date                not null,
+    service_id               integer             references "Service" (ID) on delete set null,
+    category_id              integer             references "ServiceCategory" (ID) on delete set null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    location_id              integer             references "Location" (ID) on delete cascade not null,
+    currency                 char(3)             not null,
+    bookings                 integer             not null,
+    cancellations            integer             not null,
+    no_shows                 integer             not null,
+    participants             integer             not null,
+    -- the cancelled and no-show bookings are not counted towards the revenue and the duration
+    revenue                  numeric             not null,
+    tax_name                 varchar(30),
+    tax_percent              numeric(4, 2),
+    -- the tax included in the revenue, not rounded
+    tax                      numeric             not null default 0,
+    booked_minutes           integer             not null,
+    refreshed_at             timestamptz         not null default now()
+);
+
+create index if not exists booking_daily_rollup_merchant_day_idx on "BookingDailyRollup" (merchant_id, day);
+
+create table if not exists "ReportSubscription" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    frequency                text                check (frequency in ('daily', 'weekly', 'monthly')) not null,
+    is_active                boolean             not null default true,
+    -- the last day of the last period which was sent, in the timezone of the merchant
+    last_sent_until          date,
+    created_at               timestamptz         not null default now(),
+
+    constraint unique_report_subscription_frequency unique (merchant_id, frequency)
+);
+
+create table if not exists "ReportSubscriptionRecipient" (
+    subscription_id          integer             references "ReportSubscription" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete cascade not null,
+    primary key (subscription_id, employee_id)
+);
+
+-- recipients who already got the report of a period, so a retried job does not email them again
+create table if not exists "ReportSubscriptionDelivery" (
+    subscription_id          integer             references "ReportSubscription" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete cascade not null,
+    period_end               date                not null,
+    sent_at                  timestamptz         not null default now(),
+    primary key (subscription_id, employee_id, period_end)
+);
+
+create table if not exists "ReportExport" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    dataset                  text                check (dataset in ('bookings', 'participants', 'revenue')) not null,
+    format                   text                check (format in ('csv', 'xlsx')) not null,
+    -- inclusive days in the timezone of the merchant
+    start_day                date                not null,
+    end_day                  date                not null,
+    -- whether the contact details of the customers are exported, only if the employee could see them
+    include_pii              boolean             not null default false,
+    status                   text                default 'pending' check (status in ('pending', 'running', 'completed', 'failed')) not null,
+    -- the export is deleted once it expires
+    file                     bytea,
+    error                    text,
+    created_at               timestamptz         not null default now(),
+    finished_at              timestamptz,
+    expires_at               timestamptz
+);
+
+-- legal details of the merchant printed on the invoices, with the sequence of the invoice numbers
+create table if not exists "InvoiceSettings" (
+    merchant_id              uuid                primary key references "Merchant" (ID) on delete cascade not null,
+    legal_name               varchar(100),
+    tax_number               varchar(30),
+    registration_number      varchar(30),
+    address                  varchar(200),
+    prefix                   varchar(10)         not null default 'INV',
+    footer                   varchar(300),
+    next_sequence            integer             not null default 1
+);
+
+-- a receipt of a completed participation, the details are copied so the document never changes
+create table if not exists "Invoice" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    booking_id               integer             references "Booking" (ID) on delete set null,
+    participant_id           integer             references "BookingParticipant" (ID) on delete set null unique,
+    customer_id              uuid                references "Customer" (ID) on delete set null,
+    sequence                 integer             not null,
+    invoice_number           varchar(30)         not null,
+    issued_at                timestamptz         not null default now(),
+    service_date             timestamptz         not null,
+    seller_name              text                not null,
+    seller_tax_number        text,
+    seller_registration_number text,
+    seller_address           text,
+    seller_email             text                not null,
+    buyer_name               text                not null,
+    buyer_email              text,
+    net_total                price               not null,
+    tax_total                price               not null,
+    total                    price               not null,
+    footer                   text,
+    file                     bytea               not null,
+    emailed_at               timestamptz,
+
+    constraint unique_invoice_sequence unique (merchant_id, sequence)
+);
+
+create table if not exists "InvoiceLine" (
+    ID                       serial              primary key unique not null,
+    invoice_id               integer             references "Invoice" (ID) on delete cascade not null,
+    description              text                not null,
+    quantity                 integer             not null,
+    unit_price               price               not null,
+    net_amount               price               not null,
+    -- null if the line is not taxed
+    tax_name                 text,
+    tax_percent              numeric,
+    tax_amount               price               not null,
+    gross_amount             price               not null
+);
+
+-- a prepaid voucher of the merchant, sold over the counter by an employee
+create table if not exists "GiftCard" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    code                     varchar(19)         not null,
+    initial_value            price               not null,
+    -- what remains of the initial value, always the sum of the transactions of the card
+    balance                  price               not null,
+    expires_at               timestamptz,
+    -- null if the employee who sold the card was removed
+    issued_by                integer             references "Employee" (ID) on delete set null,
+    recipient_name           varchar(60),
+    recipient_email          varchar(320),
+    message                  varchar(300),
+    created_at               timestamptz         not null default now(),
+
+    constraint unique_gift_card_code unique (merchant_id, code),
+    constraint gift_card_balance_not_negative check ((balance).number >= 0)
+);
+
+-- the ledger of a gift card, positive when the card is issued and negative when it is redeemed
+create table if not exists "GiftCardTransaction" (
+    ID                       serial              primary key unique not null,
+    gift_card_id             integer             references "GiftCard" (ID) on delete cascade not null,
+    amount                   price               not null,
+    booking_id               integer             references "Booking" (ID) on delete set null,
+    -- null if the customer redeemed the card while booking online
+    employee_id              integer             references "Employee" (ID) on delete set null,
+    -- the customer whose participation was paid, null if an employee redeemed the card for the whole booking
+    customer_id              uuid                references "Customer" (ID) on delete set null,
+    created_at               timestamptz         not null default now()
+);
+
+create index if not exists gift_card_transaction_booking_idx on "GiftCardTransaction" (booking_id);
+
+-- a class pass or membership the merchant sells, like 10 classes or a month of unlimited classes
+create table if not exists "PassProduct" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    name                     varchar(50)         not null,
+    -- null if the pass is unlimited
+    credits                  integer             check (credits > 0),
+    validity_days            integer             check (validity_days > 0) not null,
+    price                    price               not null,
+    -- products are archived instead of deleted, as the passes of customers refer to them
+    is_active                boolean             not null default true,
+    created_at               timestamptz         not null default now()
+);
+
+-- the services a pass can be used for
+create table if not exists "PassProductService" (
+    pass_product_id          integer references "PassProduct" (ID) on delete cascade not null,
+    service_id               integer references "Service" (ID) on delete cascade not null,
+    primary key (pass_product_id, service_id)
+);
+
+-- the pass can be used for every service of these categories, including the ones added later
+create table if not exists "PassProductCategory" (
+    pass_product_id          integer references "PassProduct" (ID) on delete cascade not null,
+    category_id              integer references "ServiceCategory" (ID) on delete cascade not null,
+    primary key (pass_product_id, category_id)
+);
+
+-- a pass assigned to a customer, the name and credits are copied so changing the product does not affect it
+create table if not exists "CustomerPass" (
+    ID                       serial              primary key unique not null,
+    merchant_id              uuid                references "Merchant" (ID) on delete cascade not null,
+    customer_id              uuid                references "Customer" (ID) on delete cascade not null,
+    pass_product_id          integer             references "PassProduct" (ID) not null,
+    name                     varchar(50)         not null,
+    -- both are null if the pass is unlimited
+    credits                  integer,
+    remaining_credits        integer             check (remaining_credits >= 0),
+    valid_from               timestamptz         not null,
+    valid_until              timestamptz         not null,
+    -- set by the expiry job once valid_until has passed
+    expired_at               timestamptz,
+    assigned_by              integer             references "Employee" (ID) on delete set null,
+    created_at               timestamptz         not null default now()
+);
+
+create index if not exists customer_pass_customer_idx on "CustomerPass" (customer_id);
+
+-- a credit used for a booking, refunded_at is set when the participation is cancelled in time
+create table if not exists "PassUsage" (
+    ID                       serial              primary key unique not null,
+    customer_pass_id         integer             references "CustomerPass" (ID) on delete cascade not null,
+    booking_id               integer             references "Booking" (ID) on delete cascade not null,
+    used_at                  timestamptz         not null default now(),
+    refunded_at              timestamptz
+);
+
+create index if not exists pass_usage_booking_idx on "PassUsage" (booking_id);
@@ -0,0 +1,96 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	apiKeyMiddleware "github.com/miketsu-inc/reservations/backend/internal/api/middleware/apikey"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+const (
+	// requests per minute
+	defaultRateLimit = 120
+	maxRateLimit     = 1200
+)
+
+type Service struct {
+	apiKeyRepo domain.ApiKeyRepository
+}
+
+func NewService(apiKey domain.ApiKeyRepository) *Service {
+	return &Service{
+		apiKeyRepo: apiKey,
+	}
+}
+
+type NewKeyInput struct {
+	Name      string
+	Scopes    []types.ApiKeyScope
+	RateLimit *int
+	ExpiresAt *time.Time
+}
+
+type NewKeyResult struct {
+	Id int
+	// Only returned once, when the key is created
+	Key string
+}
+
+// Creates a new api key which acts as the employee creating it
+func (s *Service) NewKey(ctx context.Context, input NewKeyInput) (NewKeyResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	if len(input.Scopes) == 0 {
+		return NewKeyResult{}, fmt.Errorf("api key needs at least one scope")
+	}
+
+	rateLimit := defaultRateLimit
+	if input.RateLimit != nil {
+		rateLimit = *input.RateLimit
+	}
+
+	if rateLimit < 1 || rateLimit > maxRateLimit {
+		return NewKeyResult{}, fmt.Errorf("rate limit has to be between 1 and %d requests per minute", maxRateLimit)
+	}
+
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		return NewKeyResult{}, fmt.Errorf("expiry date has to be in the future")
+	}
+
+	key, err := apiKeyMiddleware.New()
+	if err != nil {
+		return NewKeyResult{}, err
+	}
+
+	apiKeyId, err := s.apiKeyRepo.NewApiKey(ctx, domain.ApiKey{
+		MerchantId: actor.MerchantId,
+		EmployeeId: actor.EmployeeId,
+		Name:       input.Name,
+		Prefix:     apiKeyMiddleware.DisplayPrefix(key),
+		KeyHash:    apiKeyMiddleware.Hash(key),
+		Scopes:     input.Scopes,
+		RateLimit:  rateLimit,
+		ExpiresAt:  input.ExpiresAt,
+	})
+	if err != nil {
+		return NewKeyResult{}, err
+	}
+
+	return NewKeyResult{Id: apiKeyId, Key: key}, nil
+}
+
+func (s *Service) GetKeys(ctx context.Context) ([]domain.ApiKey, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.apiKeyRepo.GetApiKeys(ctx, actor.MerchantId)
+}
+
+func (s *Service) RevokeKey(ctx context.Context, apiKeyId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.apiKeyRepo.RevokeApiKey(ctx, actor.MerchantId, apiKeyId)
+}
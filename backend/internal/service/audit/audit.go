@@ -0,0 +1,230 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+type Service struct {
+	auditLogRepo domain.AuditLogRepository
+}
+
+func NewService(auditLog domain.AuditLogRepository) *Service {
+	return &Service{
+		auditLogRepo: auditLog,
+	}
+}
+
+// Describes a single change made by an employee
+type Change struct {
+	Action     string
+	EntityType types.AuditEntityType
+	EntityId   string
+	// State of the entity before the change, nil if it was created
+	Before any
+	// State of the entity after the change, nil if it was deleted
+	After any
+}
+
+// Records the change made by the employee in the request's context. The repository
+// should belong to the transaction of the change, so the entry is only saved if the change is committed.
+// Updates which did not change any field are not recorded
+func Record(ctx context.Context, repo domain.AuditLogRepository, change Change) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	before, after, err := Diff(change.Before, change.After)
+	if err != nil {
+		return fmt.Errorf("could not create audit log diff: %s", err.Error())
+	}
+
+	// an update which did not change anything is not worth recording
+	if change.Before != nil && change.After != nil && before == nil && after == nil {
+		return nil
+	}
+
+	var requestId *string
+	if reqId := chiMiddleware.GetReqID(ctx); reqId != "" {
+		requestId = &reqId
+	}
+
+	return repo.NewAuditLogEntry(ctx, domain.AuditLogEntry{
+		MerchantId: employee.MerchantId,
+		EmployeeId: &employee.EmployeeId,
+		Action:     change.Action,
+		EntityType: change.EntityType,
+		EntityId:   change.EntityId,
+		Before:     before,
+		After:      after,
+		RequestId:  requestId,
+	})
+}
+
+// Returns the json encoded top level fields of before and after which are different.
+// If one of them is nil the other one is returned as a whole
+func Diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields == nil || afterFields == nil {
+		beforeJson, err := marshalFields(beforeFields)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		afterJson, err := marshalFields(afterFields)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return beforeJson, afterJson, nil
+	}
+
+	changedBefore := map[string]json.RawMessage{}
+	changedAfter := map[string]json.RawMessage{}
+
+	for key, beforeValue := range beforeFields {
+		afterValue, ok := afterFields[key]
+		if !ok {
+			changedBefore[key] = beforeValue
+			continue
+		}
+
+		if !bytes.Equal(beforeValue, afterValue) {
+			changedBefore[key] = beforeValue
+			changedAfter[key] = afterValue
+		}
+	}
+
+	for key, afterValue := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changedAfter[key] = afterValue
+		}
+	}
+
+	beforeJson, err := marshalFields(changedBefore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterJson, err := marshalFields(changedAfter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJson, afterJson, nil
+}
+
+func toFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("audited value has to be a json object: %s", err.Error())
+	}
+
+	// the compact form makes the byte comparison independent of formatting
+	for key, value := range fields {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return nil, err
+		}
+
+		fields[key] = buf.Bytes()
+	}
+
+	return fields, nil
+}
+
+func marshalFields(fields map[string]json.RawMessage) (json.RawMessage, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(fields)
+}
+
+type GetEntriesInput struct {
+	Filter   domain.AuditLogFilter
+	Cursor   string
+	PageSize int
+}
+
+type GetEntriesResult struct {
+	Entries     []domain.AuditLogEntryWithEmployee
+	NextCursor  *string
+	HasNextPage bool
+}
+
+func (s *Service) GetEntries(ctx context.Context, input GetEntriesInput) (GetEntriesResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	filter := input.Filter
+
+	if input.Cursor != "" {
+		cursorId, err := decodeCursor(input.Cursor)
+		if err != nil {
+			return GetEntriesResult{}, fmt.Errorf("error during cursor decoding: %s", err.Error())
+		}
+
+		filter.CursorId = &cursorId
+	}
+
+	// +1 so we can check if there is another page
+	entries, err := s.auditLogRepo.GetAuditLogEntries(ctx, actor.MerchantId, filter, input.PageSize+1)
+	if err != nil {
+		return GetEntriesResult{}, err
+	}
+
+	var nextCursor *string
+
+	hasNextPage := len(entries) > input.PageSize
+
+	if hasNextPage {
+		entries = entries[:input.PageSize]
+
+		cursorValue := encodeCursor(entries[len(entries)-1].Id)
+		nextCursor = &cursorValue
+	}
+
+	return GetEntriesResult{
+		Entries:     entries,
+		NextCursor:  nextCursor,
+		HasNextPage: hasNextPage,
+	}, nil
+}
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(decoded), 10, 64)
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntity struct {
+	Name  string  `json:"name"`
+	Price int     `json:"price"`
+	Note  *string `json:"note"`
+}
+
+func TestDiff(t *testing.T) {
+	note := "note"
+
+	t.Run("only changed fields are kept", func(t *testing.T) {
+		before, after, err := Diff(testEntity{Name: "Haircut", Price: 10}, testEntity{Name: "Haircut", Price: 12, Note: &note})
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, `{"price": 10, "note": null}`, string(before))
+		assert.JSONEq(t, `{"price": 12, "note": "note"}`, string(after))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		before, after, err := Diff(testEntity{Name: "Haircut"}, testEntity{Name: "Haircut"})
+		assert.NoError(t, err)
+
+		assert.Nil(t, before)
+		assert.Nil(t, after)
+	})
+
+	t.Run("created entity", func(t *testing.T) {
+		before, after, err := Diff(nil, testEntity{Name: "Haircut", Price: 10})
+		assert.NoError(t, err)
+
+		assert.Nil(t, before)
+		assert.JSONEq(t, `{"name": "Haircut", "price": 10, "note": null}`, string(after))
+	})
+
+	t.Run("deleted entity", func(t *testing.T) {
+		before, after, err := Diff(testEntity{Name: "Haircut", Price: 10}, nil)
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, `{"name": "Haircut", "price": 10, "note": null}`, string(before))
+		assert.Nil(t, after)
+	})
+
+	t.Run("fields missing on one side", func(t *testing.T) {
+		before, after, err := Diff(map[string]any{"a": 1, "b": 2}, map[string]any{"b": 2, "c": 3})
+		assert.NoError(t, err)
+
+		assert.JSONEq(t, `{"a": 1}`, string(before))
+		assert.JSONEq(t, `{"c": 3}`, string(after))
+	})
+
+	t.Run("non object values are rejected", func(t *testing.T) {
+		_, _, err := Diff(1, 2)
+		assert.Error(t, err)
+	})
+}
@@ -5,12 +5,15 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/ratelimit"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
 	"github.com/miketsu-inc/reservations/backend/internal/keys"
@@ -31,17 +34,19 @@ type Service struct {
 	userRepo     domain.UserRepository
 	teamRepo     domain.TeamRepository
 	kv           *redis.Client
+	limiter      ratelimit.Store
 	enqueuer     queue.Enqueuer
 	txManager    db.TransactionManager
 }
 
 func NewService(merchant domain.MerchantRepository, user domain.UserRepository, team domain.TeamRepository,
-	kv *redis.Client, enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	kv *redis.Client, limiter ratelimit.Store, enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
 	return &Service{
 		merchantRepo: merchant,
 		userRepo:     user,
 		teamRepo:     team,
 		kv:           kv,
+		limiter:      limiter,
 		enqueuer:     enqueuer,
 		txManager:    txManager,
 	}
@@ -73,37 +78,66 @@ func hashCompare(password, hash string) error {
 	return nil
 }
 
-func newJwtTokens(userId uuid.UUID, refreshVersion int) (jwt.TokenPair, error) {
-	accessToken, err := jwt.NewAccessToken(userId)
-	if err != nil {
-		return jwt.TokenPair{}, err
-	}
+const (
+	// an account gets locked for an ip address after this many failed logins from it in failedLoginWindow
+	maxFailedLogins   = 5
+	failedLoginWindow = 15 * time.Minute
+	// and for every address after this many failed logins from anywhere, high enough
+	// that someone else failing on purpose does not easily lock out the owner
+	maxFailedAccountLogins = 50
+)
 
-	refreshToken, err := jwt.NewRefreshToken(userId, refreshVersion)
-	if err != nil {
-		return jwt.TokenPair{}, err
-	}
+type ErrAccountLocked struct {
+	RetryAfter time.Duration
+}
 
-	return jwt.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+func (e ErrAccountLocked) Error() string {
+	return "too many failed login attempts, please try again later"
 }
 
 type LoginInput struct {
 	Email    string
 	Password string
+	Metadata SessionMetadata
 }
 
 func (s *Service) Login(ctx context.Context, input LoginInput) (jwt.TokenPair, error) {
+	email := strings.ToLower(input.Email)
+	failuresKey := keys.LoginFailures{Email: email, IpAddress: input.Metadata.IpAddress}.String()
+	accountFailuresKey := keys.AccountLoginFailures{Email: email}.String()
+
+	lockout, err := s.limiter.Peek(ctx, failuresKey, maxFailedLogins, failedLoginWindow)
+	if err == nil && !lockout.Allowed {
+		return jwt.TokenPair{}, ErrAccountLocked{RetryAfter: lockout.RetryAfter}
+	}
+
+	lockout, err = s.limiter.Peek(ctx, accountFailuresKey, maxFailedAccountLogins, failedLoginWindow)
+	if err == nil && !lockout.Allowed {
+		return jwt.TokenPair{}, ErrAccountLocked{RetryAfter: lockout.RetryAfter}
+	}
+
 	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
 	if err != nil {
+		s.recordFailedLogin(ctx, failuresKey, accountFailuresKey)
 		return jwt.TokenPair{}, err
 	}
 
+	if user.IsOauthUser() {
+		return jwt.TokenPair{}, fmt.Errorf("oauth users must login with the auth provider")
+	}
+
 	err = hashCompare(input.Password, *user.PasswordHash)
 	if err != nil {
+		s.recordFailedLogin(ctx, failuresKey, accountFailuresKey)
 		return jwt.TokenPair{}, err
 	}
 
-	tokens, err := newJwtTokens(user.Id, user.JwtRefreshVersion)
+	// nolint:errcheck
+	s.limiter.Reset(ctx, failuresKey)
+	// nolint:errcheck
+	s.limiter.Reset(ctx, accountFailuresKey)
+
+	tokens, err := s.newSession(ctx, user.Id, user.JwtRefreshVersion, input.Metadata)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
@@ -111,12 +145,26 @@ func (s *Service) Login(ctx context.Context, input LoginInput) (jwt.TokenPair, e
 	return tokens, nil
 }
 
+// The lockout is best effort, failing to record an attempt should not prevent logging in
+func (s *Service) recordFailedLogin(ctx context.Context, failuresKey string, accountFailuresKey string) {
+	_, err := s.limiter.Hit(ctx, failuresKey, maxFailedLogins, failedLoginWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "could not record failed login", "error", err)
+	}
+
+	_, err = s.limiter.Hit(ctx, accountFailuresKey, maxFailedAccountLogins, failedLoginWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "could not record failed login", "error", err)
+	}
+}
+
 type UserSignupInput struct {
 	FirstName   string
 	LastName    string
 	Email       string
 	PhoneNumber string
 	Password    string
+	Metadata    SessionMetadata
 }
 
 func (s *Service) UserSignup(ctx context.Context, input UserSignupInput) (jwt.TokenPair, error) {
@@ -154,7 +202,7 @@ func (s *Service) UserSignup(ctx context.Context, input UserSignupInput) (jwt.To
 		return jwt.TokenPair{}, err
 	}
 
-	tokens, err := newJwtTokens(userID, 0)
+	tokens, err := s.newSession(ctx, userID, 0, input.Metadata)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
@@ -166,6 +214,8 @@ type MerchantSignupInput struct {
 	Name         string
 	ContactEmail string
 	Timezone     string
+	// guessed from the language of the user if it is not given
+	Currency *string
 }
 
 func ctBH(timeStr string) time.Time {
@@ -197,8 +247,18 @@ func (s *Service) MerchantSignup(ctx context.Context, input MerchantSignupInput)
 		return fmt.Errorf("unexpected error during creating merchant id: %s", err.Error())
 	}
 
-	language := lang.LangFromContext(ctx)
-	curr := currencyx.FindBest(language)
+	var curr string
+	if input.Currency != nil {
+		if _, err := currencyx.Precision(*input.Currency); err != nil {
+			return err
+		}
+		curr = *input.Currency
+	} else {
+		curr, err = currencyx.FindBest(lang.LangFromContext(ctx))
+		if err != nil {
+			return fmt.Errorf("the currency of the merchant has to be chosen: %w", err)
+		}
+	}
 
 	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
 		err := s.merchantRepo.WithTx(tx).NewMerchant(ctx, userID, domain.Merchant{
@@ -265,12 +325,18 @@ func (s *Service) LogoutAllDevices(ctx context.Context) error {
 		return err
 	}
 
+	err = s.userRepo.RevokeAllSessions(ctx, userId)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 type UpdatePasswordInput struct {
 	OldPassword string
 	NewPassword string
+	Metadata    SessionMetadata
 }
 
 func (s *Service) UpdatePassword(ctx context.Context, in UpdatePasswordInput) (jwt.TokenPair, error) {
@@ -305,7 +371,12 @@ func (s *Service) UpdatePassword(ctx context.Context, in UpdatePasswordInput) (j
 		return jwt.TokenPair{}, err
 	}
 
-	tokens, err := newJwtTokens(userId, refreshVersion)
+	err = s.userRepo.RevokeAllSessions(ctx, userId)
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	tokens, err := s.newSession(ctx, userId, refreshVersion, in.Metadata)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
@@ -358,6 +429,7 @@ func (s *Service) ForgotPassword(ctx context.Context, in ForgotPasswordInput) er
 type ResetPasswordInput struct {
 	Token    string
 	Password string
+	Metadata SessionMetadata
 }
 
 func (s *Service) ResetPassword(ctx context.Context, in ResetPasswordInput) (jwt.TokenPair, error) {
@@ -392,12 +464,28 @@ func (s *Service) ResetPassword(ctx context.Context, in ResetPasswordInput) (jwt
 		return jwt.TokenPair{}, fmt.Errorf("error deleting key: %w", err)
 	}
 
+	// a successful password reset lifts the failed login lockout of the account and of the address it was reset from
+	user, err := s.userRepo.GetUser(ctx, userId)
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	// nolint:errcheck
+	s.limiter.Reset(ctx, keys.LoginFailures{Email: strings.ToLower(user.Email), IpAddress: in.Metadata.IpAddress}.String())
+	// nolint:errcheck
+	s.limiter.Reset(ctx, keys.AccountLoginFailures{Email: strings.ToLower(user.Email)}.String())
+
 	refreshVersion, err := s.userRepo.IncrementUserJwtRefreshVersion(ctx, userId)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
 
-	tokens, err := newJwtTokens(userId, refreshVersion)
+	err = s.userRepo.RevokeAllSessions(ctx, userId)
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	tokens, err := s.newSession(ctx, userId, refreshVersion, in.Metadata)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
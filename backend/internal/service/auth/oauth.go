@@ -40,7 +40,7 @@ func (s *Service) GoogleLogin(ctx context.Context) (string, string, error) {
 
 // TODO: if not unique the user already registered without oauth
 // we should probably show a prompt to login with the original method
-func (s *Service) GoogleCallback(ctx context.Context, code string) (jwt.TokenPair, error) {
+func (s *Service) GoogleCallback(ctx context.Context, code string, meta SessionMetadata) (jwt.TokenPair, error) {
 	token, err := googleConf.Exchange(ctx, code)
 	if err != nil {
 		return jwt.TokenPair{}, fmt.Errorf("error during google oauth exchange: %s", err.Error())
@@ -100,17 +100,12 @@ func (s *Service) GoogleCallback(ctx context.Context, code string) (jwt.TokenPai
 		}
 	}
 
-	accessToken, err := jwt.NewAccessToken(userId)
+	refreshVersion, err := s.userRepo.GetUserJwtRefreshVersion(ctx, userId)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
 
-	refreshToken, err := jwt.NewRefreshToken(userId, 0)
-	if err != nil {
-		return jwt.TokenPair{}, err
-	}
-
-	return jwt.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	return s.newSession(ctx, userId, refreshVersion, meta)
 }
 
 var facebookConf = &oauth2.Config{
@@ -134,7 +129,7 @@ func (s *Service) FacebookLogin(ctx context.Context) (string, string, error) {
 
 // TODO: if not unique the user already registered without oauth
 // we should probably show a prompt to login with the original method
-func (s *Service) FacebookCallback(ctx context.Context, code string) (jwt.TokenPair, error) {
+func (s *Service) FacebookCallback(ctx context.Context, code string, meta SessionMetadata) (jwt.TokenPair, error) {
 	token, err := facebookConf.Exchange(ctx, code)
 	if err != nil {
 		return jwt.TokenPair{}, fmt.Errorf("error during facebook oauth exchange: %s", err.Error())
@@ -197,15 +192,10 @@ func (s *Service) FacebookCallback(ctx context.Context, code string) (jwt.TokenP
 		}
 	}
 
-	accessToken, err := jwt.NewAccessToken(userId)
-	if err != nil {
-		return jwt.TokenPair{}, err
-	}
-
-	refreshToken, err := jwt.NewRefreshToken(userId, 0)
+	refreshVersion, err := s.userRepo.GetUserJwtRefreshVersion(ctx, userId)
 	if err != nil {
 		return jwt.TokenPair{}, err
 	}
 
-	return jwt.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+	return s.newSession(ctx, userId, refreshVersion, meta)
 }
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+)
+
+// Information about the device which started the session
+type SessionMetadata struct {
+	IpAddress string
+	UserAgent string
+}
+
+// Create a new session for the device and issue the first token pair for it
+func (s *Service) newSession(ctx context.Context, userId uuid.UUID, refreshVersion int, meta SessionMetadata) (jwt.TokenPair, error) {
+	sessionId, err := uuid.NewV7()
+	if err != nil {
+		return jwt.TokenPair{}, fmt.Errorf("unexpected error during creating session id: %s", err.Error())
+	}
+
+	tokenId, err := jwt.NewTokenId()
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	err = s.userRepo.NewSession(ctx, domain.Session{
+		Id:               sessionId,
+		UserId:           userId,
+		RefreshTokenHash: jwt.HashTokenId(tokenId),
+		DeviceName:       deviceNameFromUserAgent(meta.UserAgent),
+		IpAddress:        meta.IpAddress,
+		UserAgent:        meta.UserAgent,
+		ExpiresAt:        jwt.RefreshTokenExpiry(),
+	})
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	accessToken, err := jwt.NewAccessToken(userId, sessionId)
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	refreshToken, err := jwt.NewRefreshToken(userId, refreshVersion, sessionId, tokenId)
+	if err != nil {
+		return jwt.TokenPair{}, err
+	}
+
+	return jwt.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *Service) GetSessions(ctx context.Context) ([]domain.Session, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	return s.userRepo.GetActiveSessions(ctx, userId)
+}
+
+func (s *Service) RevokeSession(ctx context.Context, sessionId uuid.UUID) error {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	return s.userRepo.RevokeSession(ctx, userId, sessionId)
+}
+
+// Revoke the session of the current request if there is one
+func (s *Service) Logout(ctx context.Context) error {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	sessionId, ok := jwt.GetSessionIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	// the session was already revoked or it expired, the user is logged out either way
+	err := s.userRepo.RevokeSession(ctx, userId, sessionId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("could not revoke session: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Creates a human readable device name like "Firefox on Windows"
+// from the user agent, it does not need to be accurate
+func deviceNameFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	os := "unknown device"
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	return fmt.Sprintf("%s on %s", browser, os)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/internal/utils"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
 	"github.com/miketsu-inc/reservations/backend/pkg/queue"
@@ -44,9 +45,27 @@ type NewInput struct {
 	AllDay        bool
 }
 
+// Employees without the blocked_times.manage_all permission can only block their own time
+func canManageBlockedTime(employee actor.EmployeeContext, employeeIds []int) error {
+	if employee.HasPermission(types.PermissionBlockedTimesManageAll) {
+		return nil
+	}
+
+	if employee.HasPermission(types.PermissionBlockedTimesManageOwn) && len(employeeIds) == 1 && employeeIds[0] == employee.EmployeeId {
+		return nil
+	}
+
+	return fmt.Errorf("you can only manage your own blocked times")
+}
+
 func (s *Service) New(ctx context.Context, input NewInput) error {
 	actor := actor.MustGetFromContext(ctx)
 
+	err := canManageBlockedTime(actor, input.EmployeeIds)
+	if err != nil {
+		return err
+	}
+
 	if !input.ToDate.After(input.FromDate) {
 		return fmt.Errorf("toDate must be after fromDate")
 	}
@@ -162,6 +181,16 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) error {
 		return fmt.Errorf("blocked time with id %d not found for merchant", blockedTime.Id)
 	}
 
+	err = canManageBlockedTime(actor, blockedTime.EmployeeIds)
+	if err != nil {
+		return err
+	}
+
+	err = canManageBlockedTime(actor, input.EmployeeIds)
+	if err != nil {
+		return err
+	}
+
 	if !input.ToDate.After(input.FromDate) {
 		return fmt.Errorf("toDate must be after fromDate")
 	}
@@ -0,0 +1,41 @@
+package booking
+
+import (
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Fields of a booking which are recorded in the audit log
+type bookingAuditState struct {
+	Status              types.BookingStatus `json:"status"`
+	EmployeeId          *int                `json:"employee_id"`
+	FromDate            time.Time           `json:"from_date"`
+	ToDate              time.Time           `json:"to_date"`
+	PricePerPerson      currencyx.Price     `json:"price_per_person"`
+	TotalPrice          currencyx.Price     `json:"total_price"`
+	MerchantNote        *string             `json:"merchant_note"`
+	CurrentParticipants int                 `json:"current_participants"`
+	CancellationReason  *string             `json:"cancellation_reason"`
+}
+
+func newBookingAuditState(booking domain.Booking) bookingAuditState {
+	return bookingAuditState{
+		Status:              booking.Status,
+		EmployeeId:          booking.EmployeeId,
+		FromDate:            booking.FromDate,
+		ToDate:              booking.ToDate,
+		PricePerPerson:      booking.PricePerPerson,
+		TotalPrice:          booking.TotalPrice,
+		MerchantNote:        booking.MerchantNote,
+		CurrentParticipants: booking.CurrentParticipants,
+		CancellationReason:  booking.CancellationReason,
+	}
+}
+
+type participantAuditState struct {
+	BookingId int                 `json:"booking_id"`
+	Status    types.BookingStatus `json:"status"`
+}
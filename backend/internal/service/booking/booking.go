@@ -14,12 +14,16 @@ import (
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
 	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/service/intake"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/internal/utils"
 	"github.com/miketsu-inc/reservations/backend/pkg/assert"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/pricing"
 	"github.com/miketsu-inc/reservations/backend/pkg/queue"
 	"github.com/riverqueue/river"
 	"github.com/teambition/rrule-go"
@@ -32,6 +36,11 @@ type Service struct {
 	userRepo        domain.UserRepository
 	customerRepo    domain.CustomerRepository
 	blockedTimeRepo domain.BlockedTimeRepository
+	intakeRepo      domain.IntakeRepository
+	giftCardRepo    domain.GiftCardRepository
+	passRepo        domain.PassRepository
+	promotionRepo   domain.PromotionRepository
+	auditLogRepo    domain.AuditLogRepository
 	mailer          *email.Service
 	enqueuer        queue.Enqueuer
 	txManager       db.TransactionManager
@@ -39,7 +48,9 @@ type Service struct {
 
 func NewService(booking domain.BookingRepository, catalog domain.CatalogRepository, merchant domain.MerchantRepository,
 	user domain.UserRepository, customer domain.CustomerRepository, blockedTime domain.BlockedTimeRepository,
-	mailer *email.Service, enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	intake domain.IntakeRepository, giftCard domain.GiftCardRepository, pass domain.PassRepository,
+	promotion domain.PromotionRepository, auditLog domain.AuditLogRepository, mailer *email.Service, enqueuer queue.Enqueuer,
+	txManager db.TransactionManager) *Service {
 	return &Service{
 		bookingRepo:     booking,
 		catalogRepo:     catalog,
@@ -47,6 +58,11 @@ func NewService(booking domain.BookingRepository, catalog domain.CatalogReposito
 		userRepo:        user,
 		customerRepo:    customer,
 		blockedTimeRepo: blockedTime,
+		intakeRepo:      intake,
+		giftCardRepo:    giftCard,
+		passRepo:        pass,
+		promotionRepo:   promotion,
+		auditLogRepo:    auditLog,
 		mailer:          mailer,
 		enqueuer:        enqueuer,
 		txManager:       txManager,
@@ -104,7 +120,8 @@ func enforceBookingWindow(fromDate time.Time, now time.Time, windowMin, windowMa
 	return nil
 }
 
-func getNewBookingStatus(approvalPolicy types.ApprovalType, isNewCustomer bool) (types.BookingStatus, error) {
+// Customers flagged by the merchant's policy rules always need approval
+func getNewBookingStatus(approvalPolicy types.ApprovalType, isNewCustomer bool, requiresApproval bool) (types.BookingStatus, error) {
 	var status types.BookingStatus
 
 	switch approvalPolicy {
@@ -122,6 +139,10 @@ func getNewBookingStatus(approvalPolicy types.ApprovalType, isNewCustomer bool)
 		return types.BookingStatus{}, fmt.Errorf("invalid approval policy for merchant")
 	}
 
+	if requiresApproval {
+		status = types.BookingStatusBooked
+	}
+
 	return status, nil
 }
 
@@ -133,6 +154,14 @@ type CreateByCustomerInput struct {
 	CustomerNote string
 	// only present on group bookings
 	BookingId *int
+	// nil if the customer was not asked about marketing emails
+	MarketingConsent *bool
+	// answers to the intake form of the service, nil if it has none
+	Intake *intake.ResponseInput
+	// pays the price of the booking as far as the balance of the card allows
+	GiftCardCode *string
+	// the best automatic promotion is applied if there is no code
+	PromoCode *string
 }
 
 func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerInput) error {
@@ -169,21 +198,41 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 	isGroupBooking := input.BookingId != nil
 
 	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
-		customerId, isBlacklisted, isNewCustomer, err := s.customerRepo.WithTx(tx).NewCustomerFromUser(ctx, customerId, merchantId, userId)
+		customer, err := s.customerRepo.WithTx(tx).NewCustomerFromUser(ctx, customerId, merchantId, userId)
 		if err != nil {
 			return err
 		}
 
-		if isBlacklisted {
+		customerId := customer.Id
+
+		if customer.IsBlacklisted {
 			return fmt.Errorf("you are blacklisted, please contact the merchant by email or phone to make a booking")
 		}
 
-		bookingStatus, err := getNewBookingStatus(bookingSettings.ApprovalPolicy, isNewCustomer)
+		if input.MarketingConsent != nil {
+			err = s.customerRepo.WithTx(tx).UpsertMarketingConsent(ctx, domain.MarketingConsent{
+				CustomerId: customerId,
+				Channel:    types.MarketingChannelEmail,
+				IsGranted:  *input.MarketingConsent,
+				Source:     types.ConsentSourceBooking,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		bookingStatus, err := getNewBookingStatus(bookingSettings.ApprovalPolicy, customer.IsNew, customer.RequiresApproval)
 		if err != nil {
 			return err
 		}
 
 		var bookingId int
+		// the price the customer pays for their participation
+		var share currencyx.Price
+		// the class is already paid, there is nothing left for a gift card
+		var paidWithPass bool
+		// nil if no promotion was applied to the share of the customer
+		var promotionId *int
 
 		if isGroupBooking {
 			bookingId = *input.BookingId
@@ -203,7 +252,27 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 				return err
 			}
 
-			newTotalPrice, err := booking.TotalPrice.Add(booking.PricePerPerson.Amount)
+			share = booking.PricePerPerson
+
+			paidWithPass, err = s.usePass(ctx, tx, merchantId, bookingId, booking, customerId)
+			if err != nil {
+				return err
+			}
+
+			if !paidWithPass && booking.ServiceId != nil {
+				service, err := s.catalogRepo.GetServiceWithPhases(ctx, *booking.ServiceId, merchantId)
+				if err != nil {
+					return err
+				}
+
+				share, promotionId, err = s.applyPromotion(ctx, tx, merchantId, customerId, service.Id, service.CategoryId,
+					booking.FromDate.In(merchantTz), booking.PricePerPerson, input.PromoCode)
+				if err != nil {
+					return err
+				}
+			}
+
+			newTotalPrice, err := booking.TotalPrice.Add(share.Amount)
 			if err != nil {
 				return err
 			}
@@ -225,6 +294,20 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 				return err
 			}
 
+			updatedBooking := booking
+			updatedBooking.CurrentParticipants++
+			updatedBooking.TotalPrice = currencyx.Price{Amount: newTotalPrice}
+
+			err = s.enqueueWebhookEvent(ctx, tx, merchantId, types.WebhookEventBookingUpdated, webhook.NewBookingData(bookingId, updatedBooking))
+			if err != nil {
+				return err
+			}
+
+			err = s.enqueueRollupRefresh(ctx, tx, merchantId, booking.FromDate)
+			if err != nil {
+				return err
+			}
+
 		} else {
 			service, err := s.catalogRepo.GetServiceWithPhases(ctx, input.ServiceId, merchantId)
 			if err != nil {
@@ -234,7 +317,15 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 			duration := time.Duration(service.TotalDuration) * time.Minute
 			toDate := fromDate.Add(duration)
 
-			price, err := s.preventNilBookingPrice(ctx, merchantId, service.Price)
+			price, taxRate, err := s.bookingPrice(ctx, merchantId, service)
+			if err != nil {
+				return err
+			}
+
+			taxName, taxPercent := taxSnapshot(taxRate)
+
+			price, promotionId, err = s.applyPromotion(ctx, tx, merchantId, customerId, service.Id, service.CategoryId,
+				fromDate.In(merchantTz), price, input.PromoCode)
 			if err != nil {
 				return err
 			}
@@ -261,6 +352,8 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 				MinParticipants:     1,
 				MaxParticipants:     1,
 				CurrentParticipants: 1,
+				TaxName:             taxName,
+				TaxPercent:          taxPercent,
 			}
 
 			participants := []domain.BookingParticipant{{
@@ -273,6 +366,49 @@ func (s *Service) CreateByCustomer(ctx context.Context, input CreateByCustomerIn
 			if err != nil {
 				return err
 			}
+
+			share = price
+
+			err = s.enqueueWebhookEvent(ctx, tx, merchantId, types.WebhookEventBookingCreated, webhook.NewBookingData(bookingId, booking))
+			if err != nil {
+				return err
+			}
+
+			err = s.enqueueRollupRefresh(ctx, tx, merchantId, booking.FromDate)
+			if err != nil {
+				return err
+			}
+		}
+
+		if promotionId != nil {
+			err = s.promotionRepo.WithTx(tx).SetParticipantPromotion(ctx, bookingId, customerId, *promotionId, share)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.saveIntakeResponse(ctx, tx, userId, merchantId, input.ServiceId, bookingId, customerId, input.Intake)
+		if err != nil {
+			return err
+		}
+
+		if input.GiftCardCode != nil && !paidWithPass {
+			err = s.redeemGiftCard(ctx, tx, merchantId, *input.GiftCardCode, bookingId, customerId, share)
+			if err != nil {
+				return err
+			}
+		}
+
+		if customer.IsNew {
+			customer, err := s.customerRepo.WithTx(tx).GetCustomerInfo(ctx, merchantId, customerId)
+			if err != nil {
+				return err
+			}
+
+			err = s.enqueueWebhookEvent(ctx, tx, merchantId, types.WebhookEventCustomerCreated, webhook.NewCustomerData(customer.Customer))
+			if err != nil {
+				return err
+			}
 		}
 
 		err = s.scheduleNewBookingEmails(ctx, tx, []uuid.UUID{customerId}, []types.BookingStatus{bookingStatus}, bookingId, fromDate)
@@ -330,8 +466,31 @@ func (s *Service) CancelByCustomer(ctx context.Context, input CancelByCustomerIn
 			return err
 		}
 
+		err = s.applyCustomerPolicies(ctx, tx, booking.MerchantId, bookingParticipant, types.BookingStatusCancelled)
+		if err != nil {
+			return err
+		}
+
+		// customers can only cancel before the cancel deadline, so they always get their credit back
+		if bookingParticipant.CustomerId != nil {
+			err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, []int{booking.Id}, []uuid.UUID{*bookingParticipant.CustomerId})
+			if err != nil {
+				return err
+			}
+
+			err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, []int{booking.Id}, []uuid.UUID{*bookingParticipant.CustomerId})
+			if err != nil {
+				return err
+			}
+		}
+
 		if booking.IsGroupBooking() {
-			newTotalPrice, err := booking.TotalPrice.Sub(booking.PricePerPerson.Amount)
+			share := booking.PricePerPerson
+			if bookingParticipant.PricePerPerson != nil {
+				share = *bookingParticipant.PricePerPerson
+			}
+
+			newTotalPrice, err := booking.TotalPrice.Sub(share.Amount)
 			if err != nil {
 				return fmt.Errorf("failed to calculate total price: %w", err)
 			}
@@ -346,6 +505,17 @@ func (s *Service) CancelByCustomer(ctx context.Context, input CancelByCustomerIn
 				return err
 			}
 
+			err = s.enqueueWebhookEvent(ctx, tx, booking.MerchantId, types.WebhookEventParticipantStatusChanged, webhook.ParticipantStatusData{
+				BookingId:      booking.Id,
+				ParticipantId:  bookingParticipant.Id,
+				CustomerId:     bookingParticipant.CustomerId,
+				Status:         types.BookingStatusCancelled,
+				PreviousStatus: bookingParticipant.Status,
+			})
+			if err != nil {
+				return err
+			}
+
 		} else {
 			err = s.bookingRepo.WithTx(tx).UpdateBookingStatus(ctx, booking.MerchantId, booking.Id, types.BookingStatusCancelled)
 			if err != nil {
@@ -358,9 +528,17 @@ func (s *Service) CancelByCustomer(ctx context.Context, input CancelByCustomerIn
 			if err != nil {
 				return err
 			}
+
+			cancelledBooking := booking
+			cancelledBooking.Status = types.BookingStatusCancelled
+
+			err = s.enqueueWebhookEvent(ctx, tx, booking.MerchantId, types.WebhookEventBookingCancelled, webhook.NewBookingData(booking.Id, cancelledBooking))
+			if err != nil {
+				return err
+			}
 		}
 
-		return nil
+		return s.enqueueRollupRefresh(ctx, tx, booking.MerchantId, booking.FromDate)
 	})
 }
 
@@ -398,6 +576,39 @@ func (s *Service) preventNilBookingPrice(ctx context.Context, merchantId uuid.UU
 	return outPrice, nil
 }
 
+// bookingPrice returns the gross price of the service together with the tax rate included in it
+func (s *Service) bookingPrice(ctx context.Context, merchantId uuid.UUID, service domain.Service) (currencyx.Price, *domain.TaxRate, error) {
+	price, err := s.preventNilBookingPrice(ctx, merchantId, service.Price)
+	if err != nil {
+		return currencyx.Price{}, nil, err
+	}
+
+	if service.TaxRateId == nil {
+		return price, nil, nil
+	}
+
+	taxRate, err := s.catalogRepo.GetTaxRate(ctx, merchantId, *service.TaxRateId)
+	if err != nil {
+		return currencyx.Price{}, nil, err
+	}
+
+	breakdown, err := pricing.Calculate(price, taxRate.Rate())
+	if err != nil {
+		return currencyx.Price{}, nil, err
+	}
+
+	return breakdown.Gross, &taxRate, nil
+}
+
+// the rate is copied onto the booking so later changes to it do not alter past bookings
+func taxSnapshot(taxRate *domain.TaxRate) (*string, *string) {
+	if taxRate == nil {
+		return nil, nil
+	}
+
+	return &taxRate.Name, &taxRate.Percent
+}
+
 func parseRrule(rruleInput RecurringRuleInput, dStart time.Time) (*rrule.RRule, error) {
 	var freq rrule.Frequency
 
@@ -515,6 +726,19 @@ func (s *Service) scheduleNewBookingEmails(ctx context.Context, tx pgx.Tx, custo
 	return nil
 }
 
+// Employees without the bookings.manage_all permission can only manage the bookings assigned to them
+func canManageBooking(employee actor.EmployeeContext, employeeId *int) error {
+	if employee.HasPermission(types.PermissionBookingsManageAll) {
+		return nil
+	}
+
+	if employee.HasPermission(types.PermissionBookingsManageOwn) && employeeId != nil && *employeeId == employee.EmployeeId {
+		return nil
+	}
+
+	return fmt.Errorf("you can only manage your own bookings")
+}
+
 type CreateByMerchantInput struct {
 	Customers    []CustomerInput
 	ServiceId    int
@@ -543,6 +767,11 @@ type RecurringRuleInput struct {
 func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantInput) error {
 	actor := actor.MustGetFromContext(ctx)
 
+	err := canManageBooking(actor, &input.EmployeeId)
+	if err != nil {
+		return err
+	}
+
 	service, err := s.catalogRepo.GetServiceWithPhases(ctx, input.ServiceId, actor.MerchantId)
 	if err != nil {
 		return err
@@ -561,11 +790,13 @@ func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantIn
 		return err
 	}
 
-	price, err := s.preventNilBookingPrice(ctx, actor.MerchantId, service.Price)
+	price, taxRate, err := s.bookingPrice(ctx, actor.MerchantId, service)
 	if err != nil {
 		return err
 	}
 
+	taxName, taxPercent := taxSnapshot(taxRate)
+
 	var incomingCustomerIds []uuid.UUID
 	var participantCount int
 
@@ -650,6 +881,8 @@ func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantIn
 				MinParticipants:     service.MinParticipants,
 				MaxParticipants:     service.MaxParticipants,
 				CurrentParticipants: participantCount,
+				TaxName:             taxName,
+				TaxPercent:          taxPercent,
 			})
 			if err != nil {
 				return err
@@ -725,6 +958,8 @@ func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantIn
 			CurrentParticipants: participantCount,
 			OccurrenceIndex:     occurrenceIndex,
 			SeriesVersion:       seriesVersion,
+			TaxName:             taxName,
+			TaxPercent:          taxPercent,
 		}
 
 		participants := make([]domain.BookingParticipant, len(incomingCustomerIds))
@@ -741,6 +976,12 @@ func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantIn
 			return fmt.Errorf("error during new booking creation: %s", err.Error())
 		}
 
+		// the later occurrences of a series use the passes when they are generated
+		err = s.usePasses(ctx, tx, actor.MerchantId, bookingId, booking, incomingCustomerIds)
+		if err != nil {
+			return err
+		}
+
 		if !isWalkIn {
 			statuses := utils.RepeatSlice([]types.BookingStatus{types.BookingStatusConfirmed}, len(incomingCustomerIds))
 
@@ -750,7 +991,23 @@ func (s *Service) CreateByMerchant(ctx context.Context, input CreateByMerchantIn
 			}
 		}
 
-		return nil
+		err = s.enqueueWebhookEvent(ctx, tx, actor.MerchantId, types.WebhookEventBookingCreated, webhook.NewBookingData(bookingId, booking))
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueRollupRefresh(ctx, tx, actor.MerchantId, booking.FromDate)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "booking.created",
+			EntityType: types.AuditEntityBooking,
+			EntityId:   strconv.Itoa(bookingId),
+			Before:     nil,
+			After:      newBookingAuditState(booking),
+		})
 	})
 }
 
@@ -889,6 +1146,17 @@ func (s *Service) UpdateByMerchant(ctx context.Context, bookingId int, input Upd
 		return fmt.Errorf("booking not found for this merchant")
 	}
 
+	err = canManageBooking(actor, booking.EmployeeId)
+	if err != nil {
+		return err
+	}
+
+	// prevents reassigning the booking to someone else without permission
+	err = canManageBooking(actor, &input.EmployeeId)
+	if err != nil {
+		return err
+	}
+
 	if input.UpdateAllFuture && !booking.IsRecurring {
 		return fmt.Errorf("cannot update future occurrences of non-recurring booking")
 	}
@@ -1239,6 +1507,45 @@ func (s *Service) UpdateByMerchant(ctx context.Context, bookingId int, input Upd
 			}
 		}
 
+		if statusChanged && bookingStatus == types.BookingStatusCancelled {
+			err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, []int{booking.Id}, nil)
+			if err != nil {
+				return err
+			}
+
+			err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, []int{booking.Id}, nil)
+			if err != nil {
+				return err
+			}
+		} else if participantsChanged {
+			if len(participantChanges.ToDelete) > 0 {
+				err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, []int{booking.Id}, participantChanges.ToDelete)
+				if err != nil {
+					return err
+				}
+
+				err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, []int{booking.Id}, participantChanges.ToDelete)
+				if err != nil {
+					return err
+				}
+			}
+
+			passBooking := booking
+			passBooking.FromDate = fromDate
+
+			err = s.usePasses(ctx, tx, actor.MerchantId, booking.Id, passBooking, participantChanges.ToInsert)
+			if err != nil {
+				return err
+			}
+		}
+
+		if statusChanged && bookingStatus == types.BookingStatusCompleted {
+			_, err = s.enqueuer.InsertTx(ctx, tx, args.IssueInvoices{BookingId: booking.Id}, nil)
+			if err != nil {
+				return fmt.Errorf("could not schedule issue invoices job: %w", err)
+			}
+		}
+
 		if timeStampChanged {
 			// TODO: don't forget to change this when we will consider employee changes in this
 			if booking.EmployeeId != nil {
@@ -1320,7 +1627,33 @@ func (s *Service) UpdateByMerchant(ctx context.Context, bookingId int, input Upd
 			}
 		}
 
-		return nil
+		updatedBooking := booking
+		updatedBooking.Status = bookingStatus
+		updatedBooking.EmployeeId = &input.EmployeeId
+		updatedBooking.FromDate = fromDate
+		updatedBooking.ToDate = toDate
+		updatedBooking.MerchantNote = merchantNote
+		updatedBooking.PricePerPerson = pricePerPerson
+		updatedBooking.TotalPrice = currencyx.Price{Amount: totalPrice}
+		updatedBooking.CurrentParticipants = participantCount
+
+		err = s.enqueueWebhookEvent(ctx, tx, actor.MerchantId, types.WebhookEventBookingUpdated, webhook.NewBookingData(booking.Id, updatedBooking))
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueRollupRefresh(ctx, tx, actor.MerchantId, booking.FromDate, updatedBooking.FromDate)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "booking.updated",
+			EntityType: types.AuditEntityBooking,
+			EntityId:   strconv.Itoa(booking.Id),
+			Before:     newBookingAuditState(booking),
+			After:      newBookingAuditState(updatedBooking),
+		})
 	})
 }
 
@@ -1338,6 +1671,15 @@ func (s *Service) CancelByMerchant(ctx context.Context, bookingId int, input Can
 		return err
 	}
 
+	if !booking.IsOwnedByMerchant(actor.MerchantId) {
+		return fmt.Errorf("booking not found for this merchant")
+	}
+
+	err = canManageBooking(actor, booking.EmployeeId)
+	if err != nil {
+		return err
+	}
+
 	if input.CancelFuture && !booking.IsRecurring {
 		return fmt.Errorf("cannot cancel future occurrences of non-recurring booking")
 	}
@@ -1358,6 +1700,41 @@ func (s *Service) CancelByMerchant(ctx context.Context, bookingId int, input Can
 			return err
 		}
 
+		err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, []int{booking.Id}, nil)
+		if err != nil {
+			return err
+		}
+
+		err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, []int{booking.Id}, nil)
+		if err != nil {
+			return err
+		}
+
+		cancelledBooking := booking
+		cancelledBooking.Status = types.BookingStatusCancelled
+		cancelledBooking.CancellationReason = &input.CancellationReason
+
+		err = audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "booking.cancelled",
+			EntityType: types.AuditEntityBooking,
+			EntityId:   strconv.Itoa(booking.Id),
+			Before:     newBookingAuditState(booking),
+			After:      newBookingAuditState(cancelledBooking),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueWebhookEvent(ctx, tx, actor.MerchantId, types.WebhookEventBookingCancelled, webhook.NewBookingData(booking.Id, cancelledBooking))
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueRollupRefresh(ctx, tx, actor.MerchantId, booking.FromDate)
+		if err != nil {
+			return err
+		}
+
 		if input.CancelFuture {
 			seriesParticipants, err := s.bookingRepo.WithTx(tx).GetBookingSeriesParticipants(ctx, *booking.BookingSeriesId)
 			if err != nil {
@@ -1419,10 +1796,15 @@ func (s *Service) UpdateParticipantStatus(ctx context.Context, bookingId int, pa
 		return err
 	}
 
-	if booking.IsOwnedByMerchant(actor.MerchantId) {
+	if !booking.IsOwnedByMerchant(actor.MerchantId) {
 		return fmt.Errorf("booking could not be found for this merchant")
 	}
 
+	err = canManageBooking(actor, booking.EmployeeId)
+	if err != nil {
+		return err
+	}
+
 	err = booking.CanModify()
 	if err != nil {
 		return err
@@ -1438,10 +1820,69 @@ func (s *Service) UpdateParticipantStatus(ctx context.Context, bookingId int, pa
 		return err
 	}
 
-	err = s.bookingRepo.UpdateParticipantStatus(ctx, bookingId, participantId, input.Status)
-	if err != nil {
-		return err
+	refundPass := false
+
+	if input.Status == types.BookingStatusCancelled && bookingParticipant.CustomerId != nil {
+		cancelDeadline, err := s.bookingRepo.GetBookingCancelDeadline(ctx, booking.Id)
+		if err != nil {
+			return err
+		}
+
+		refundPass = isTimelyCancellation(booking.FromDate, cancelDeadline, time.Now())
 	}
 
-	return nil
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		err := s.bookingRepo.WithTx(tx).UpdateParticipantStatus(ctx, bookingId, participantId, input.Status)
+		if err != nil {
+			return err
+		}
+
+		if refundPass {
+			err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, []int{bookingId}, []uuid.UUID{*bookingParticipant.CustomerId})
+			if err != nil {
+				return err
+			}
+
+			err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, []int{bookingId}, []uuid.UUID{*bookingParticipant.CustomerId})
+			if err != nil {
+				return err
+			}
+		}
+
+		if input.Status == types.BookingStatusCompleted {
+			_, err = s.enqueuer.InsertTx(ctx, tx, args.IssueInvoices{BookingId: bookingId}, nil)
+			if err != nil {
+				return fmt.Errorf("could not schedule issue invoices job: %w", err)
+			}
+		}
+
+		err = s.applyCustomerPolicies(ctx, tx, actor.MerchantId, bookingParticipant, input.Status)
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueWebhookEvent(ctx, tx, actor.MerchantId, types.WebhookEventParticipantStatusChanged, webhook.ParticipantStatusData{
+			BookingId:      bookingId,
+			ParticipantId:  participantId,
+			CustomerId:     bookingParticipant.CustomerId,
+			Status:         input.Status,
+			PreviousStatus: bookingParticipant.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.enqueueRollupRefresh(ctx, tx, actor.MerchantId, booking.FromDate)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "participant.status_changed",
+			EntityType: types.AuditEntityBookingParticipant,
+			EntityId:   strconv.Itoa(participantId),
+			Before:     participantAuditState{BookingId: bookingId, Status: bookingParticipant.Status},
+			After:      participantAuditState{BookingId: bookingId, Status: input.Status},
+		})
+	})
 }
@@ -0,0 +1,67 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/giftcard"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Pays the share of the customer in the booking with the gift card, as far as its balance allows
+func (s *Service) redeemGiftCard(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, code string, bookingId int,
+	customerId uuid.UUID, share currencyx.Price) error {
+	code, err := giftcard.NormalizeCode(code)
+	if err != nil {
+		return err
+	}
+
+	if !share.IsPositive() {
+		return fmt.Errorf("there is nothing to pay with the gift card")
+	}
+
+	// the booking is always locked before the card, so concurrent redemptions can not deadlock
+	booking, err := s.giftCardRepo.WithTx(tx).GetGiftCardBookingForUpdate(ctx, merchantId, bookingId)
+	if err != nil {
+		return err
+	}
+
+	due, err := giftcard.BookingDue(booking)
+	if err != nil {
+		return err
+	}
+
+	cmp, err := due.Cmp(share.Amount)
+	if err != nil {
+		return err
+	}
+
+	if cmp > 0 {
+		due = share
+	}
+
+	giftCard, err := s.giftCardRepo.WithTx(tx).GetGiftCardByCodeForUpdate(ctx, merchantId, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("gift card not found")
+		}
+		return err
+	}
+
+	amount, err := giftcard.RedemptionAmount(giftCard, due, nil, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.giftCardRepo.WithTx(tx).RedeemGiftCard(ctx, domain.GiftCardRedemption{
+		GiftCardId: giftCard.Id,
+		Amount:     amount,
+		BookingId:  booking.Id,
+		CustomerId: &customerId,
+	})
+}
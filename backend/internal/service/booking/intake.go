@@ -0,0 +1,72 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/intake"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Returns the intake form the user has to fill out to book the service, nil if there is none.
+// Forms asked only once are not returned after the user answered them at an earlier booking
+func (s *Service) GetIntakeFormForBooking(ctx context.Context, merchantName string, serviceId int) (*domain.IntakeForm, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	merchantId, err := s.merchantRepo.GetMerchantIdByUrlName(ctx, merchantName)
+	if err != nil {
+		return nil, err
+	}
+
+	return intakeFormToAsk(ctx, s.intakeRepo, merchantId, serviceId, userId)
+}
+
+func intakeFormToAsk(ctx context.Context, intakeRepo domain.IntakeRepository, merchantId uuid.UUID, serviceId int,
+	userId uuid.UUID) (*domain.IntakeForm, error) {
+	form, err := intakeRepo.GetIntakeFormForService(ctx, merchantId, serviceId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if form.Mode == types.IntakeFormOnce {
+		answered, err := intakeRepo.HasUserAnsweredIntakeForm(ctx, form.Id, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if answered {
+			return nil, nil
+		}
+	}
+
+	return &form, nil
+}
+
+// Validates and stores the answers to the intake form of the service, if it has to be filled out
+func (s *Service) saveIntakeResponse(ctx context.Context, tx pgx.Tx, userId uuid.UUID, merchantId uuid.UUID, serviceId int,
+	bookingId int, customerId uuid.UUID, input *intake.ResponseInput) error {
+	form, err := intakeFormToAsk(ctx, s.intakeRepo.WithTx(tx), merchantId, serviceId, userId)
+	if err != nil || form == nil {
+		return err
+	}
+
+	if input == nil {
+		input = &intake.ResponseInput{}
+	}
+
+	response, err := intake.NewResponse(*form, *input, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.intakeRepo.WithTx(tx).NewIntakeResponse(ctx, bookingId, customerId, response)
+}
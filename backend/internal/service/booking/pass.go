@@ -0,0 +1,71 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Pays the participation of the customer in a class with one of their passes, if they have a usable one.
+// Reports whether a pass was used, the customer pays the price of the booking otherwise
+func (s *Service) usePass(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, bookingId int, booking domain.Booking,
+	customerId uuid.UUID) (bool, error) {
+	if booking.BookingType != types.BookingTypeClass || booking.ServiceId == nil {
+		return false, nil
+	}
+
+	pass, err := s.passRepo.WithTx(tx).GetUsablePassForUpdate(ctx, merchantId, customerId, *booking.ServiceId, booking.FromDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = s.passRepo.WithTx(tx).UsePass(ctx, pass.Id, bookingId)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Service) usePasses(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, bookingId int, booking domain.Booking,
+	customerIds []uuid.UUID) error {
+	for _, customerId := range customerIds {
+		_, err := s.usePass(ctx, tx, merchantId, bookingId, booking, customerId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Consumes a credit for every participant of the occurrences who has a usable pass,
+// the bookings are looked up by the participant's booking id
+func (s *Service) useOccurrencePasses(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, bookings map[int]domain.Booking,
+	participants []domain.BookingParticipant) error {
+	for _, p := range participants {
+		if p.CustomerId == nil {
+			continue
+		}
+
+		_, err := s.usePass(ctx, tx, merchantId, p.BookingId, bookings[p.BookingId], *p.CustomerId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The credit of a pass is only given back if the participation is cancelled before the cancel deadline of the booking
+func isTimelyCancellation(fromDate time.Time, cancelDeadline int, now time.Time) bool {
+	return now.Before(fromDate.Add(-time.Duration(cancelDeadline) * time.Minute))
+}
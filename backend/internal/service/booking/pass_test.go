@@ -0,0 +1,19 @@
+package booking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTimelyCancellation(t *testing.T) {
+	fromDate := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	assert.True(t, isTimelyCancellation(fromDate, 120, fromDate.Add(-3*time.Hour)))
+	assert.False(t, isTimelyCancellation(fromDate, 120, fromDate.Add(-2*time.Hour)), "the deadline itself is already late")
+	assert.False(t, isTimelyCancellation(fromDate, 120, fromDate.Add(-time.Hour)))
+
+	assert.True(t, isTimelyCancellation(fromDate, 0, fromDate.Add(-time.Minute)))
+	assert.False(t, isTimelyCancellation(fromDate, 0, fromDate))
+}
@@ -0,0 +1,36 @@
+package booking
+
+import (
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanManageBooking(t *testing.T) {
+	own := 4
+	other := 7
+
+	t.Run("Manage all", func(t *testing.T) {
+		employee := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionBookingsManageAll}}
+
+		assert.Nil(t, canManageBooking(employee, &own))
+		assert.Nil(t, canManageBooking(employee, &other))
+		assert.Nil(t, canManageBooking(employee, nil), "unassigned bookings shall be manageable")
+	})
+
+	t.Run("Manage own", func(t *testing.T) {
+		employee := actor.EmployeeContext{EmployeeId: own, Permissions: types.EmployeeRoleStaff.DefaultPermissions()}
+
+		assert.Nil(t, canManageBooking(employee, &own))
+		assert.NotNil(t, canManageBooking(employee, &other))
+		assert.NotNil(t, canManageBooking(employee, nil))
+	})
+
+	t.Run("No permission", func(t *testing.T) {
+		employee := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionCatalogEdit}}
+
+		assert.NotNil(t, canManageBooking(employee, &own))
+	})
+}
@@ -0,0 +1,169 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Returns the policy event the participant status counts as, if any
+func policyEventForStatus(status types.BookingStatus) (types.CustomerPolicyEvent, bool) {
+	switch status {
+	case types.BookingStatusNoShow:
+		return types.CustomerPolicyEventNoShow, true
+	case types.BookingStatusCancelled:
+		return types.CustomerPolicyEventCancellation, true
+	default:
+		return types.CustomerPolicyEvent{}, false
+	}
+}
+
+// Evaluates the merchant's policy rules for the event after the participant got its new status.
+// Rules only ever restrict a customer, lifting a restriction is left to the merchant
+func (s *Service) applyCustomerPolicies(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, participant domain.BookingParticipant, status types.BookingStatus) error {
+	event, ok := policyEventForStatus(status)
+	if !ok {
+		return nil
+	}
+
+	customerId := participant.CustomerId
+	if participant.TransferredTo != nil {
+		customerId = participant.TransferredTo
+	}
+
+	if customerId == nil {
+		return nil
+	}
+
+	rules, err := s.customerRepo.WithTx(tx).GetCustomerPolicyRules(ctx, merchantId)
+	if err != nil {
+		return err
+	}
+
+	// rules with the same period share the count
+	counts := map[int]int{}
+	now := time.Now().UTC()
+
+	for _, rule := range rules {
+		if rule.Event != event {
+			continue
+		}
+
+		if _, ok := counts[rule.PeriodDays]; ok {
+			continue
+		}
+
+		count, err := s.customerRepo.WithTx(tx).CountCustomerPolicyEvents(ctx, merchantId, *customerId, event, now.AddDate(0, 0, -rule.PeriodDays))
+		if err != nil {
+			return err
+		}
+
+		counts[rule.PeriodDays] = count
+	}
+
+	rule, ok := strictestMatchingRule(rules, event, counts)
+	if !ok {
+		return nil
+	}
+
+	reason := policyRuleReason(rule)
+
+	switch rule.Action {
+	case types.CustomerPolicyActionBlacklist:
+		return s.policyBlacklist(ctx, tx, merchantId, *customerId, reason)
+	case types.CustomerPolicyActionRequireApproval:
+		return s.policyRequireApproval(ctx, tx, merchantId, *customerId, reason)
+	default:
+		return fmt.Errorf("invalid customer policy action: %s", rule.Action.String())
+	}
+}
+
+// Blacklisting is stricter than requiring approval, from rules with the same action the first one wins
+func strictestMatchingRule(rules []domain.CustomerPolicyRule, event types.CustomerPolicyEvent, counts map[int]int) (domain.CustomerPolicyRule, bool) {
+	var matched domain.CustomerPolicyRule
+	found := false
+
+	for _, rule := range rules {
+		if rule.Event != event || counts[rule.PeriodDays] < rule.Threshold {
+			continue
+		}
+
+		if !found || (matched.Action != types.CustomerPolicyActionBlacklist && rule.Action == types.CustomerPolicyActionBlacklist) {
+			matched = rule
+			found = true
+		}
+	}
+
+	return matched, found
+}
+
+func policyRuleReason(rule domain.CustomerPolicyRule) string {
+	events := "no-shows"
+	if rule.Event == types.CustomerPolicyEventCancellation {
+		events = "cancellations"
+	}
+
+	return fmt.Sprintf("Automatically applied after %d or more %s in %d days", rule.Threshold, events, rule.PeriodDays)
+}
+
+func (s *Service) policyBlacklist(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customerId uuid.UUID, reason string) error {
+	before, err := s.customerRepo.WithTx(tx).GetCustomerBlacklistStatus(ctx, merchantId, customerId)
+	if err != nil {
+		return err
+	}
+
+	if before.IsBlacklisted {
+		return nil
+	}
+
+	after := domain.CustomerBlacklistStatus{IsBlacklisted: true, BlacklistReason: &reason}
+
+	err = s.customerRepo.WithTx(tx).SetBlacklistStatusForCustomer(ctx, merchantId, customerId, after.IsBlacklisted, after.BlacklistReason)
+	if err != nil {
+		return err
+	}
+
+	return s.recordPolicyChange(ctx, tx, "customer.auto_blacklisted", customerId, before, after)
+}
+
+func (s *Service) policyRequireApproval(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customerId uuid.UUID, reason string) error {
+	before, err := s.customerRepo.WithTx(tx).GetCustomerApprovalStatus(ctx, merchantId, customerId)
+	if err != nil {
+		return err
+	}
+
+	if before.RequiresApproval {
+		return nil
+	}
+
+	after := domain.CustomerApprovalStatus{RequiresApproval: true, ApprovalReason: &reason}
+
+	err = s.customerRepo.WithTx(tx).SetApprovalStatusForCustomer(ctx, merchantId, customerId, after.RequiresApproval, after.ApprovalReason)
+	if err != nil {
+		return err
+	}
+
+	return s.recordPolicyChange(ctx, tx, "customer.approval_required", customerId, before, after)
+}
+
+// Cancellations made by the customers themselves have no employee to record the change for
+func (s *Service) recordPolicyChange(ctx context.Context, tx pgx.Tx, action string, customerId uuid.UUID, before any, after any) error {
+	if _, ok := actor.GetFromContext(ctx); !ok {
+		return nil
+	}
+
+	return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+		Action:     action,
+		EntityType: types.AuditEntityCustomer,
+		EntityId:   customerId.String(),
+		Before:     before,
+		After:      after,
+	})
+}
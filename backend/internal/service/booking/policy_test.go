@@ -0,0 +1,70 @@
+package booking
+
+import (
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictestMatchingRule(t *testing.T) {
+	requireApproval := domain.CustomerPolicyRule{Id: 1, Event: types.CustomerPolicyEventNoShow, Threshold: 3, PeriodDays: 180,
+		Action: types.CustomerPolicyActionRequireApproval}
+	blacklist := domain.CustomerPolicyRule{Id: 2, Event: types.CustomerPolicyEventNoShow, Threshold: 5, PeriodDays: 180,
+		Action: types.CustomerPolicyActionBlacklist}
+	cancellations := domain.CustomerPolicyRule{Id: 3, Event: types.CustomerPolicyEventCancellation, Threshold: 1, PeriodDays: 30,
+		Action: types.CustomerPolicyActionBlacklist}
+
+	rules := []domain.CustomerPolicyRule{requireApproval, blacklist, cancellations}
+
+	_, ok := strictestMatchingRule(rules, types.CustomerPolicyEventNoShow, map[int]int{180: 2})
+	assert.False(t, ok)
+
+	rule, ok := strictestMatchingRule(rules, types.CustomerPolicyEventNoShow, map[int]int{180: 3})
+	assert.True(t, ok)
+	assert.Equal(t, requireApproval.Id, rule.Id)
+
+	rule, ok = strictestMatchingRule(rules, types.CustomerPolicyEventNoShow, map[int]int{180: 6})
+	assert.True(t, ok)
+	assert.Equal(t, blacklist.Id, rule.Id)
+
+	// the count of another period does not apply
+	_, ok = strictestMatchingRule(rules, types.CustomerPolicyEventCancellation, map[int]int{180: 6})
+	assert.False(t, ok)
+}
+
+func TestPolicyEventForStatus(t *testing.T) {
+	event, ok := policyEventForStatus(types.BookingStatusNoShow)
+	assert.True(t, ok)
+	assert.Equal(t, types.CustomerPolicyEventNoShow, event)
+
+	event, ok = policyEventForStatus(types.BookingStatusCancelled)
+	assert.True(t, ok)
+	assert.Equal(t, types.CustomerPolicyEventCancellation, event)
+
+	_, ok = policyEventForStatus(types.BookingStatusCompleted)
+	assert.False(t, ok)
+}
+
+func TestGetNewBookingStatus(t *testing.T) {
+	status, err := getNewBookingStatus(types.ApprovalTypeAuto, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.BookingStatusConfirmed, status)
+
+	status, err = getNewBookingStatus(types.ApprovalTypeManualForNew, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.BookingStatusConfirmed, status)
+
+	status, err = getNewBookingStatus(types.ApprovalTypeManualForNew, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.BookingStatusBooked, status)
+
+	// flagged customers need approval regardless of the policy
+	status, err = getNewBookingStatus(types.ApprovalTypeAuto, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, types.BookingStatusBooked, status)
+
+	_, err = getNewBookingStatus(types.ApprovalType{}, false, false)
+	assert.Error(t, err)
+}
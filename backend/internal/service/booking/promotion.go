@@ -0,0 +1,183 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/promotion"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Applies the promo code to the price of the customer's participation, or the best automatic promotion if there is no code.
+// The slot is the start of the booking in the timezone of the merchant. Returns the discounted price and the id of the
+// applied promotion, which is nil if none of them applies. Has to be called before the participant is saved, otherwise
+// the booking itself would count as a previous visit
+func (s *Service) applyPromotion(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customerId uuid.UUID, serviceId int,
+	categoryId *int, slot time.Time, price currencyx.Price, code *string) (currencyx.Price, *int, error) {
+	now := time.Now()
+
+	if code != nil {
+		normalized, err := promotion.NormalizeCode(*code)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		promo, err := s.promotionRepo.WithTx(tx).GetPromotionByCodeForUpdate(ctx, merchantId, normalized)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return currencyx.Price{}, nil, fmt.Errorf("promo code not found")
+			}
+			return currencyx.Price{}, nil, err
+		}
+
+		err = promotion.CheckApplicable(promo, serviceId, categoryId, slot, now)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		customerUses, hasVisited, err := s.getPromotionUsage(ctx, tx, merchantId, customerId, promo)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		err = promotion.CheckLimits(promo, customerUses, hasVisited)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		discounted, err := promotion.DiscountedPrice(promo, price)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		return discounted, &promo.Id, nil
+	}
+
+	promotions, err := s.promotionRepo.WithTx(tx).GetAutomaticPromotions(ctx, merchantId)
+	if err != nil {
+		return currencyx.Price{}, nil, err
+	}
+
+	type candidate struct {
+		promotion  domain.Promotion
+		discounted currencyx.Price
+	}
+
+	// the promotions are filtered without locks, only the chosen one is locked and checked again.
+	// Promotions which do not apply are skipped, but database errors are returned right away as they abort the transaction
+	var candidates []candidate
+
+	for _, promo := range promotions {
+		applies, err := s.promotionApplies(ctx, tx, merchantId, customerId, serviceId, categoryId, slot, now, price, promo)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		if !applies {
+			continue
+		}
+
+		discounted, err := promotion.DiscountedPrice(promo, price)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		cmp, err := discounted.Cmp(price.Amount)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		if cmp >= 0 {
+			continue
+		}
+
+		candidates = append(candidates, candidate{promotion: promo, discounted: discounted})
+	}
+
+	// the best discount first, the older promotion wins a tie
+	slices.SortStableFunc(candidates, func(a candidate, b candidate) int {
+		cmp, _ := a.discounted.Cmp(b.discounted.Amount)
+		return cmp
+	})
+
+	for _, c := range candidates {
+		promo, err := s.promotionRepo.WithTx(tx).GetPromotionForUpdate(ctx, merchantId, c.promotion.Id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return currencyx.Price{}, nil, err
+		}
+
+		// a concurrent booking could have used it up or the merchant could have changed it since it was read
+		applies, err := s.promotionApplies(ctx, tx, merchantId, customerId, serviceId, categoryId, slot, now, price, promo)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		if !applies {
+			continue
+		}
+
+		discounted, err := promotion.DiscountedPrice(promo, price)
+		if err != nil {
+			return currencyx.Price{}, nil, err
+		}
+
+		return discounted, &promo.Id, nil
+	}
+
+	return price, nil, nil
+}
+
+// Whether the automatic promotion can be applied to the participation, the error is only set if the usage could not be read
+func (s *Service) promotionApplies(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customerId uuid.UUID, serviceId int,
+	categoryId *int, slot time.Time, now time.Time, price currencyx.Price, promo domain.Promotion) (bool, error) {
+	if promotion.CheckApplicable(promo, serviceId, categoryId, slot, now) != nil {
+		return false, nil
+	}
+
+	// a fixed discount in an other currency does not apply to the price
+	if promo.Amount != nil && promo.Amount.CurrencyCode() != price.CurrencyCode() {
+		return false, nil
+	}
+
+	customerUses, hasVisited, err := s.getPromotionUsage(ctx, tx, merchantId, customerId, promo)
+	if err != nil {
+		return false, err
+	}
+
+	return promotion.CheckLimits(promo, customerUses, hasVisited) == nil, nil
+}
+
+// The previous uses of the promotion by the customer and whether they visited the merchant before,
+// only read if the promotion limits them
+func (s *Service) getPromotionUsage(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customerId uuid.UUID,
+	promo domain.Promotion) (int, bool, error) {
+	var customerUses int
+	var err error
+
+	if promo.MaxUsesPerCustomer != nil {
+		customerUses, err = s.promotionRepo.WithTx(tx).GetCustomerPromotionUses(ctx, promo.Id, customerId)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	var hasVisited bool
+
+	if promo.FirstVisitOnly {
+		hasVisited, err = s.promotionRepo.WithTx(tx).HasCustomerVisited(ctx, merchantId, customerId)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	return customerUses, hasVisited, nil
+}
@@ -103,6 +103,8 @@ func (s *Service) GenerateRecurringBookings(ctx context.Context, series domain.B
 			CurrentParticipants: series.CurrentParticipants,
 			OccurrenceIndex:     &occurrenceIndex,
 			SeriesVersion:       &series.Version,
+			TaxName:             series.TaxName,
+			TaxPercent:          series.TaxPercent,
 		})
 	}
 
@@ -154,6 +156,16 @@ func (s *Service) GenerateRecurringBookings(ctx context.Context, series domain.B
 			return err
 		}
 
+		bookingsById := make(map[int]domain.Booking, len(bookingIds))
+		for i, id := range bookingIds {
+			bookingsById[id] = bookings[i]
+		}
+
+		err = s.useOccurrencePasses(ctx, tx, series.MerchantId, bookingsById, participants)
+		if err != nil {
+			return err
+		}
+
 		err = s.bookingRepo.WithTx(tx).UpdateBookingSeriesGeneratedUntil(ctx, series.Id, occurrences[len(occurrences)-1])
 		if err != nil {
 			return err
@@ -583,6 +595,16 @@ func (s *Service) UpdateFutureBookingOccurrences(ctx context.Context, series dom
 					return err
 				}
 
+				err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, bookingsToCancel, nil)
+				if err != nil {
+					return err
+				}
+
+				err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, bookingsToCancel, nil)
+				if err != nil {
+					return err
+				}
+
 				customerIdsByBooking, err := s.bookingRepo.WithTx(tx).GetParticipantCustomerIdsForBookings(ctx, bookingsToCancel)
 				if err != nil {
 					return err
@@ -697,6 +719,16 @@ func (s *Service) UpdateFutureBookingOccurrences(ctx context.Context, series dom
 							return err
 						}
 
+						err = s.passRepo.WithTx(tx).RefundPassUsages(ctx, futureBookingIds, requestedParticipantsToDelete)
+						if err != nil {
+							return err
+						}
+
+						err = s.giftCardRepo.WithTx(tx).RefundGiftCardRedemptions(ctx, futureBookingIds, requestedParticipantsToDelete)
+						if err != nil {
+							return err
+						}
+
 						customerIdsToDeleteByBooking := make(map[int][]uuid.UUID, len(futureBookingIds))
 						for _, bid := range futureBookingIds {
 							customerIdsToDeleteByBooking[bid] = requestedParticipantsToDelete
@@ -721,6 +753,17 @@ func (s *Service) UpdateFutureBookingOccurrences(ctx context.Context, series dom
 								return err
 							}
 
+							passBookings := make(map[int]domain.Booking, len(futureBookingsMap))
+							for id, b := range futureBookingsMap {
+								b.FromDate = fromDateByBooking[id]
+								passBookings[id] = b
+							}
+
+							err = s.useOccurrencePasses(ctx, tx, series.MerchantId, passBookings, participantsToInsert)
+							if err != nil {
+								return err
+							}
+
 							reminderParams := buildNewParticipantReminderEmailParams(participantsToInsert, fromDateByBooking)
 							if len(reminderParams) > 0 {
 								_, err := s.enqueuer.InsertManyFastTx(ctx, tx, reminderParams)
@@ -0,0 +1,31 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/riverqueue/river"
+)
+
+// Schedules the refresh of the rollups of the days the changed booking was and is on,
+// so the dashboard does not wait for the periodic refresh
+func (s *Service) enqueueRollupRefresh(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, dates ...time.Time) error {
+	params := make([]river.InsertManyParams, 0, len(dates))
+	for _, date := range dates {
+		params = append(params, river.InsertManyParams{Args: args.RefreshBookingDayRollups{
+			MerchantId: merchantId,
+			Date:       date,
+		}})
+	}
+
+	_, err := s.enqueuer.InsertManyFastTx(ctx, tx, params)
+	if err != nil {
+		return fmt.Errorf("could not schedule booking rollup refresh job: %w", err)
+	}
+
+	return nil
+}
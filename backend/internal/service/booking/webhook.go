@@ -0,0 +1,26 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Schedules the webhook event in the transaction of the change it describes
+func (s *Service) enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, eventType types.WebhookEventType, data any) error {
+	event, err := webhook.NewEvent(merchantId, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.enqueuer.InsertTx(ctx, tx, event, nil)
+	if err != nil {
+		return fmt.Errorf("could not schedule webhook event job: %w", err)
+	}
+
+	return nil
+}
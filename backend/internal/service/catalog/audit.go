@@ -0,0 +1,46 @@
+package catalog
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Fields of a service which are recorded in the audit log
+type serviceAuditState struct {
+	Name             string              `json:"name"`
+	CategoryId       *int                `json:"category_id"`
+	Description      *string             `json:"description"`
+	TotalDuration    int                 `json:"total_duration"`
+	Price            *currencyx.Price    `json:"price"`
+	PriceType        types.PriceType     `json:"price_type"`
+	IsActive         bool                `json:"is_active"`
+	MinParticipants  int                 `json:"min_participants"`
+	MaxParticipants  int                 `json:"max_participants"`
+	CancelDeadline   *int                `json:"cancel_deadline"`
+	BookingWindowMin *int                `json:"booking_window_min"`
+	BookingWindowMax *int                `json:"booking_window_max"`
+	BufferTime       *int                `json:"buffer_time"`
+	ApprovalPolicy   *types.ApprovalType `json:"approval_policy"`
+	TaxRateId        *int                `json:"tax_rate_id"`
+}
+
+func newServiceAuditState(service domain.Service) serviceAuditState {
+	return serviceAuditState{
+		Name:             service.Name,
+		CategoryId:       service.CategoryId,
+		Description:      service.Description,
+		TotalDuration:    service.TotalDuration,
+		Price:            service.Price,
+		PriceType:        service.PriceType,
+		IsActive:         service.IsActive,
+		MinParticipants:  service.MinParticipants,
+		MaxParticipants:  service.MaxParticipants,
+		CancelDeadline:   service.CancelDeadline,
+		BookingWindowMin: service.BookingWindowMin,
+		BookingWindowMax: service.BookingWindowMax,
+		BufferTime:       service.BufferTime,
+		ApprovalPolicy:   service.ApprovalPolicy,
+		TaxRateId:        service.TaxRateId,
+	}
+}
@@ -3,10 +3,12 @@ package catalog
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
@@ -15,13 +17,16 @@ import (
 type Service struct {
 	catalogRepo  domain.CatalogRepository
 	merchantRepo domain.MerchantRepository
+	auditLogRepo domain.AuditLogRepository
 	txManager    db.TransactionManager
 }
 
-func NewService(catalog domain.CatalogRepository, merchant domain.MerchantRepository, txManager db.TransactionManager) *Service {
+func NewService(catalog domain.CatalogRepository, merchant domain.MerchantRepository, auditLog domain.AuditLogRepository,
+	txManager db.TransactionManager) *Service {
 	return &Service{
 		catalogRepo:  catalog,
 		merchantRepo: merchant,
+		auditLogRepo: auditLog,
 		txManager:    txManager,
 	}
 }
@@ -55,6 +60,7 @@ type NewInput struct {
 	MinParticipants *int
 	MaxParticipants *int
 	IsActive        bool
+	TaxRateId       *int
 	Settings        ServiceSettingsInput
 	Phases          []NewPhasesInput
 	UsedProducts    []ConnectedProductsInput
@@ -132,6 +138,10 @@ func (s *Service) New(ctx context.Context, input NewInput) error {
 		}
 	}
 
+	if err := s.validateTaxRate(ctx, actor.MerchantId, input.TaxRateId); err != nil {
+		return err
+	}
+
 	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
 		serviceId, err := s.catalogRepo.WithTx(tx).NewService(ctx, domain.Service{
 			Id:            0,
@@ -154,6 +164,7 @@ func (s *Service) New(ctx context.Context, input NewInput) error {
 			BookingWindowMax: input.Settings.BookingWindowMax,
 			BufferTime:       input.Settings.BufferTime,
 			ApprovalPolicy:   input.Settings.ApprovalPolicy,
+			TaxRateId:        input.TaxRateId,
 		})
 		if err != nil {
 			return err
@@ -256,6 +267,7 @@ type UpdateInput struct {
 	MinParticipants *int
 	MaxParticipants *int
 	IsActive        bool
+	TaxRateId       *int
 	Settings        ServiceSettingsInput
 	Phases          []PhasesInput
 }
@@ -312,7 +324,16 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) error {
 		}
 	}
 
+	if err := s.validateTaxRate(ctx, actor.MerchantId, input.TaxRateId); err != nil {
+		return err
+	}
+
 	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		serviceBefore, err := s.catalogRepo.WithTx(tx).GetServiceWithPhases(ctx, input.Id, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
 		existingPhases, err := s.catalogRepo.WithTx(tx).GetServicePhases(ctx, input.Id)
 		if err != nil {
 			return err
@@ -341,7 +362,7 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) error {
 			}
 		}
 
-		oldCategoryId, err := s.catalogRepo.WithTx(tx).UpdateService(ctx, domain.Service{
+		service := domain.Service{
 			Id:            input.Id,
 			MerchantId:    actor.MerchantId,
 			CategoryId:    input.CategoryId,
@@ -361,7 +382,10 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) error {
 			BookingWindowMax: input.Settings.BookingWindowMax,
 			BufferTime:       input.Settings.BufferTime,
 			ApprovalPolicy:   input.Settings.ApprovalPolicy,
-		})
+			TaxRateId:        input.TaxRateId,
+		}
+
+		oldCategoryId, err := s.catalogRepo.WithTx(tx).UpdateService(ctx, service)
 		if err != nil {
 			return err
 		}
@@ -379,7 +403,13 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) error {
 			}
 		}
 
-		return nil
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "service.updated",
+			EntityType: types.AuditEntityService,
+			EntityId:   strconv.Itoa(input.Id),
+			Before:     newServiceAuditState(serviceBefore),
+			After:      newServiceAuditState(service),
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("error while updating service for merchant: %w", err)
@@ -392,7 +422,12 @@ func (s *Service) Delete(ctx context.Context, serviceId int) error {
 	actor := actor.MustGetFromContext(ctx)
 
 	err := s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
-		err := s.catalogRepo.WithTx(tx).DeleteService(ctx, actor.MerchantId, serviceId)
+		serviceBefore, err := s.catalogRepo.WithTx(tx).GetServiceWithPhases(ctx, serviceId, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		err = s.catalogRepo.WithTx(tx).DeleteService(ctx, actor.MerchantId, serviceId)
 		if err != nil {
 			return err
 		}
@@ -402,7 +437,13 @@ func (s *Service) Delete(ctx context.Context, serviceId int) error {
 			return err
 		}
 
-		return nil
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "service.deleted",
+			EntityType: types.AuditEntityService,
+			EntityId:   strconv.Itoa(serviceId),
+			Before:     newServiceAuditState(serviceBefore),
+			After:      nil,
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("error while deleting service for merchant: %s", err.Error())
@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/pricing"
+)
+
+func (s *Service) validateTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId *int) error {
+	if taxRateId == nil {
+		return nil
+	}
+
+	_, err := s.catalogRepo.GetTaxRate(ctx, merchantId, *taxRateId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("tax rate not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+type TaxRateInput struct {
+	Name        string
+	Percent     string
+	IsInclusive bool
+}
+
+func (input TaxRateInput) validate() error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("the name of the tax rate can not be empty")
+	}
+
+	return pricing.ValidatePercent(input.Percent)
+}
+
+func (s *Service) NewTaxRate(ctx context.Context, input TaxRateInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	if err := input.validate(); err != nil {
+		return 0, err
+	}
+
+	return s.catalogRepo.NewTaxRate(ctx, actor.MerchantId, domain.TaxRate{
+		Name:        strings.TrimSpace(input.Name),
+		Percent:     input.Percent,
+		IsInclusive: input.IsInclusive,
+	})
+}
+
+// Changing a rate only affects the bookings made after it, the earlier ones keep the rate they were made with
+func (s *Service) UpdateTaxRate(ctx context.Context, taxRateId int, input TaxRateInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	err := s.catalogRepo.UpdateTaxRate(ctx, actor.MerchantId, domain.TaxRate{
+		Id:          taxRateId,
+		Name:        strings.TrimSpace(input.Name),
+		Percent:     input.Percent,
+		IsInclusive: input.IsInclusive,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("tax rate not found")
+	}
+
+	return err
+}
+
+func (s *Service) DeleteTaxRate(ctx context.Context, taxRateId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	err := s.catalogRepo.DeleteTaxRate(ctx, actor.MerchantId, taxRateId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("tax rate not found")
+	}
+
+	return err
+}
+
+func (s *Service) GetTaxRates(ctx context.Context) ([]domain.TaxRate, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.catalogRepo.GetTaxRates(ctx, actor.MerchantId)
+}
@@ -0,0 +1,376 @@
+package customer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"golang.org/x/text/language"
+)
+
+const (
+	// campaign emails a merchant can send in a day
+	maxDailyCampaignEmails = 1000
+	// campaign emails a merchant can send in a minute, one batch is at most this big
+	campaignEmailsPerMinute = 100
+	campaignBatchInterval   = time.Minute
+)
+
+type ConsentInput struct {
+	Channel   types.MarketingChannel
+	IsGranted bool
+}
+
+func (s *Service) SetMarketingConsent(ctx context.Context, customerId uuid.UUID, input ConsentInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		// makes sure the customer belongs to the merchant
+		_, err := s.customerRepo.WithTx(tx).GetCustomerInfo(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		consents, err := s.customerRepo.WithTx(tx).GetMarketingConsents(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		var before *domain.MarketingConsent
+		if idx := slices.IndexFunc(consents, func(c domain.MarketingConsent) bool { return c.Channel == input.Channel }); idx != -1 {
+			before = &consents[idx]
+		}
+
+		after := domain.MarketingConsent{
+			CustomerId: customerId,
+			Channel:    input.Channel,
+			IsGranted:  input.IsGranted,
+			Source:     types.ConsentSourceMerchant,
+			EmployeeId: &actor.EmployeeId,
+		}
+
+		err = s.customerRepo.WithTx(tx).UpsertMarketingConsent(ctx, after)
+		if err != nil {
+			return err
+		}
+
+		change := audit.Change{
+			Action:     "customer.consent_updated",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			After:      after,
+		}
+
+		if before != nil {
+			change.Before = *before
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), change)
+	})
+}
+
+func (s *Service) GetMarketingConsents(ctx context.Context, customerId uuid.UUID) ([]domain.MarketingConsent, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetMarketingConsents(ctx, actor.MerchantId, customerId)
+}
+
+// Returns the merchant of the campaign the unsubscribe link was sent in
+func (s *Service) GetUnsubscribeTarget(ctx context.Context, token string) (domain.UnsubscribeTarget, error) {
+	target, err := s.customerRepo.GetUnsubscribeTarget(ctx, token)
+	if err != nil {
+		return domain.UnsubscribeTarget{}, fmt.Errorf("invalid unsubscribe link")
+	}
+
+	return target, nil
+}
+
+// Withdraws the email marketing consent of the customer the unsubscribe link was sent to
+func (s *Service) Unsubscribe(ctx context.Context, token string) error {
+	target, err := s.GetUnsubscribeTarget(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return s.customerRepo.UpsertMarketingConsent(ctx, domain.MarketingConsent{
+		CustomerId: target.CustomerId,
+		Channel:    types.MarketingChannelEmail,
+		IsGranted:  false,
+		Source:     types.ConsentSourceUnsubscribeLink,
+	})
+}
+
+type CampaignInput struct {
+	Name    string
+	Subject string
+	Body    string
+	Filter  domain.CustomerFilter
+	// The filter of the segment is combined with the filter of the input
+	SegmentId *int
+}
+
+type NewCampaignResult struct {
+	Id             int
+	RecipientCount int
+}
+
+// Sends the email to the customers matching the filter who agreed to receive marketing emails.
+// The emails are sent in the background in batches
+func (s *Service) NewCampaign(ctx context.Context, input CampaignInput) (NewCampaignResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	if len(splitParagraphs(input.Body)) == 0 {
+		return NewCampaignResult{}, fmt.Errorf("the campaign email can not be empty")
+	}
+
+	filter, err := s.resolveCustomerFilter(ctx, actor.MerchantId, input.Filter, input.SegmentId)
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	customers, _, err := s.customerRepo.GetCustomers(ctx, actor.MerchantId, domain.CustomerQuery{Filter: filter})
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	consented, err := s.customerRepo.GetConsentedCustomerIds(ctx, actor.MerchantId, types.MarketingChannelEmail)
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	recipients, err := selectCampaignRecipients(customers, consented)
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	if len(recipients) == 0 {
+		return NewCampaignResult{}, fmt.Errorf("none of the matching customers agreed to receive marketing emails")
+	}
+
+	queued, err := s.customerRepo.CountCampaignEmails(ctx, actor.MerchantId, time.Now().UTC().Add(-24*time.Hour))
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	if queued+len(recipients) > maxDailyCampaignEmails {
+		return NewCampaignResult{}, fmt.Errorf("at most %d campaign emails can be sent in a day, %d can still be sent",
+			maxDailyCampaignEmails, max(maxDailyCampaignEmails-queued, 0))
+	}
+
+	var campaignId int
+
+	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		campaignId, err = s.customerRepo.WithTx(tx).NewCampaign(ctx, domain.Campaign{
+			MerchantId: actor.MerchantId,
+			EmployeeId: &actor.EmployeeId,
+			Name:       strings.TrimSpace(input.Name),
+			Subject:    strings.TrimSpace(input.Subject),
+			Body:       input.Body,
+			Filter:     filter,
+		}, recipients)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.SendCampaign{
+			MerchantId: actor.MerchantId,
+			CampaignId: campaignId,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule campaign job: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return NewCampaignResult{}, err
+	}
+
+	return NewCampaignResult{Id: campaignId, RecipientCount: len(recipients)}, nil
+}
+
+func (s *Service) GetCampaigns(ctx context.Context) ([]domain.CampaignWithStats, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCampaigns(ctx, actor.MerchantId)
+}
+
+func (s *Service) GetCampaign(ctx context.Context, campaignId int) (domain.CampaignWithStats, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCampaign(ctx, actor.MerchantId, campaignId)
+}
+
+// Stops sending the campaign, the emails sent already can not be taken back
+func (s *Service) CancelCampaign(ctx context.Context, campaignId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	campaign, err := s.customerRepo.GetCampaign(ctx, actor.MerchantId, campaignId)
+	if err != nil {
+		return err
+	}
+
+	if campaign.Status != types.CampaignPending && campaign.Status != types.CampaignSending {
+		return fmt.Errorf("only campaigns which are still sending can be cancelled")
+	}
+
+	return s.customerRepo.UpdateCampaignStatus(ctx, campaignId, types.CampaignCancelled)
+}
+
+// Sends the next batch of the campaign respecting the merchant's send limit.
+// Returns how long to wait before the next batch, zero if the campaign is finished
+func (s *Service) SendCampaignBatch(ctx context.Context, merchantId uuid.UUID, campaignId int) (time.Duration, error) {
+	campaign, err := s.customerRepo.GetCampaign(ctx, merchantId, campaignId)
+	if err != nil {
+		return 0, err
+	}
+
+	if campaign.Status == types.CampaignCompleted || campaign.Status == types.CampaignCancelled {
+		return 0, nil
+	}
+
+	// other campaigns of the merchant may be sending at the same time
+	sent, err := s.customerRepo.CountSentCampaignEmails(ctx, merchantId, time.Now().UTC().Add(-campaignBatchInterval))
+	if err != nil {
+		return 0, err
+	}
+
+	available := campaignEmailsPerMinute - sent
+	if available <= 0 {
+		return campaignBatchInterval, nil
+	}
+
+	recipients, err := s.customerRepo.GetPendingCampaignRecipients(ctx, campaignId, available)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(recipients) == 0 {
+		return 0, s.customerRepo.UpdateCampaignStatus(ctx, campaignId, types.CampaignCompleted)
+	}
+
+	if campaign.Status == types.CampaignPending {
+		err = s.customerRepo.UpdateCampaignStatus(ctx, campaignId, types.CampaignSending)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	paragraphs := splitParagraphs(campaign.Body)
+	results := make([]domain.CampaignRecipientResult, len(recipients))
+
+	for i, recipient := range recipients {
+		results[i] = domain.CampaignRecipientResult{Id: recipient.Id, Status: types.CampaignRecipientSent}
+
+		// the customer could have unsubscribed or removed the email since the campaign was created
+		if recipient.Email == nil || !recipient.HasConsent {
+			results[i].Status = types.CampaignRecipientSkipped
+			continue
+		}
+
+		err := s.mailer.Campaign(ctx, recipientLanguage(recipient), *recipient.Email, campaign.Subject, email.CampaignData{
+			MerchantName:            campaign.MerchantName,
+			FirstName:               valueOrEmpty(recipient.FirstName),
+			Paragraphs:              paragraphs,
+			UnsubscribeLink:         fmt.Sprintf("http://reservations.local:3000/unsubscribe?token=%s", recipient.UnsubscribeToken),
+			OneClickUnsubscribeLink: fmt.Sprintf("http://reservations.local:3000/api/v1/public/unsubscribe/%s", recipient.UnsubscribeToken),
+		})
+		if err != nil {
+			message := err.Error()
+			results[i].Status = types.CampaignRecipientFailed
+			results[i].Error = &message
+		}
+	}
+
+	err = s.customerRepo.UpdateCampaignRecipients(ctx, results)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(recipients) < available {
+		return 0, s.customerRepo.UpdateCampaignStatus(ctx, campaignId, types.CampaignCompleted)
+	}
+
+	return campaignBatchInterval, nil
+}
+
+// Customers with an email who agreed to receive marketing emails, each with their own unsubscribe token
+func selectCampaignRecipients(customers []domain.PublicCustomer, consented []uuid.UUID) ([]domain.NewCampaignRecipient, error) {
+	recipients := []domain.NewCampaignRecipient{}
+
+	for _, customer := range customers {
+		if customer.Email == nil || strings.TrimSpace(*customer.Email) == "" || !slices.Contains(consented, customer.Id) {
+			continue
+		}
+
+		token, err := newUnsubscribeToken()
+		if err != nil {
+			return nil, err
+		}
+
+		recipients = append(recipients, domain.NewCampaignRecipient{
+			CustomerId:       customer.Id,
+			UnsubscribeToken: token,
+		})
+	}
+
+	return recipients, nil
+}
+
+func newUnsubscribeToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("unexpected error during creating unsubscribe token: %s", err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// Splits the text at the empty lines, the whitespace around the paragraphs is removed
+func splitParagraphs(text string) []string {
+	paragraphs := []string{}
+	lines := []string{}
+
+	flush := func() {
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+			lines = lines[:0]
+		}
+	}
+
+	for line := range strings.SplitSeq(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	flush()
+
+	return paragraphs
+}
+
+func recipientLanguage(recipient domain.CampaignRecipient) language.Tag {
+	if recipient.UserLanguage != nil {
+		if tag, err := language.Parse(*recipient.UserLanguage); err == nil {
+			return tag
+		}
+	}
+
+	return lang.GetDefaultLang()
+}
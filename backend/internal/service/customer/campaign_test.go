@@ -0,0 +1,45 @@
+package customer
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitParagraphs(t *testing.T) {
+	assert.Equal(t, []string{}, splitParagraphs(" \n\n "))
+	assert.Equal(t, []string{"Hello"}, splitParagraphs("  Hello  "))
+	assert.Equal(t,
+		[]string{"Summer sale!\nEvery haircut is 20% off", "See you soon"},
+		splitParagraphs("Summer sale!\r\n  Every haircut is 20% off\r\n\r\n\n  See you soon\n"),
+	)
+}
+
+func TestSelectCampaignRecipients(t *testing.T) {
+	customer := func(email *string) domain.PublicCustomer {
+		return domain.PublicCustomer{Customer: domain.Customer{Id: uuid.New(), Email: email}}
+	}
+	email := "eva@example.com"
+	blank := " "
+
+	withConsent := customer(&email)
+	withoutConsent := customer(&email)
+	noEmail := customer(nil)
+	blankEmail := customer(&blank)
+	another := customer(&email)
+
+	recipients, err := selectCampaignRecipients(
+		[]domain.PublicCustomer{withConsent, withoutConsent, noEmail, blankEmail, another},
+		[]uuid.UUID{withConsent.Id, noEmail.Id, blankEmail.Id, another.Id},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 2)
+
+	assert.Equal(t, withConsent.Id, recipients[0].CustomerId)
+	assert.Equal(t, another.Id, recipients[1].CustomerId)
+
+	assert.NotEmpty(t, recipients[0].UnsubscribeToken)
+	assert.NotEqual(t, recipients[0].UnsubscribeToken, recipients[1].UnsubscribeToken)
+}
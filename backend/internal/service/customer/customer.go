@@ -9,23 +9,44 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
 )
 
 type Service struct {
 	customerRepo domain.CustomerRepository
 	bookingRepo  domain.BookingRepository
+	passRepo     domain.PassRepository
+	invoiceRepo  domain.InvoiceRepository
+	giftCardRepo domain.GiftCardRepository
+	auditLogRepo domain.AuditLogRepository
+	mailer       *email.Service
+	enqueuer     queue.Enqueuer
 	txManager    db.TransactionManager
 }
 
-func NewService(customer domain.CustomerRepository, booking domain.BookingRepository, txManager db.TransactionManager) *Service {
+func NewService(customer domain.CustomerRepository, booking domain.BookingRepository, pass domain.PassRepository,
+	invoice domain.InvoiceRepository, giftCard domain.GiftCardRepository, auditLog domain.AuditLogRepository, mailer *email.Service, txManager db.TransactionManager) *Service {
 	return &Service{
 		customerRepo: customer,
 		bookingRepo:  booking,
+		passRepo:     pass,
+		invoiceRepo:  invoice,
+		giftCardRepo: giftCard,
+		auditLogRepo: auditLog,
+		mailer:       mailer,
 		txManager:    txManager,
 	}
 }
 
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
 type NewInput struct {
 	FirstName   *string
 	LastName    *string
@@ -33,6 +54,9 @@ type NewInput struct {
 	PhoneNumber *string
 	Birthday    *time.Time
 	Note        *string
+	Tags        []string
+	// Keyed by the id of the custom field
+	CustomFields domain.CustomFieldValues
 }
 
 func (s *Service) New(ctx context.Context, input NewInput) error {
@@ -43,19 +67,56 @@ func (s *Service) New(ctx context.Context, input NewInput) error {
 
 	actor := actor.MustGetFromContext(ctx)
 
-	if err := s.customerRepo.NewCustomer(ctx, actor.MerchantId, domain.Customer{
-		Id:          customerId,
-		FirstName:   input.FirstName,
-		LastName:    input.LastName,
-		Email:       input.Email,
-		PhoneNumber: input.PhoneNumber,
-		Birthday:    input.Birthday,
-		Note:        input.Note,
-	}); err != nil {
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	fields, err := s.customerRepo.GetCustomerFields(ctx, actor.MerchantId)
+	if err != nil {
+		return err
+	}
+
+	customFields, err := validateCustomFields(fields, input.CustomFields, true)
+	if err != nil {
+		return err
+	}
+
+	customer := domain.Customer{
+		Id:           customerId,
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		Email:        input.Email,
+		PhoneNumber:  input.PhoneNumber,
+		Birthday:     input.Birthday,
+		Note:         input.Note,
+		Tags:         tags,
+		CustomFields: customFields,
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err := s.customerRepo.WithTx(tx).NewCustomer(ctx, actor.MerchantId, customer); err != nil {
+			return err
+		}
+
+		event, err := webhook.NewEvent(actor.MerchantId, types.WebhookEventCustomerCreated, webhook.NewCustomerData(customer))
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, event, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule webhook event job: %w", err)
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.created",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     nil,
+			After:      customer,
+		})
+	})
 }
 
 type UpdateInput struct {
@@ -66,6 +127,9 @@ type UpdateInput struct {
 	PhoneNumber *string
 	Birthday    *time.Time
 	Note        *string
+	// The tags and custom fields are replaced as a whole, they are left unchanged if nil
+	Tags         []string
+	CustomFields domain.CustomFieldValues
 }
 
 func (s *Service) Update(ctx context.Context, customerId uuid.UUID, input UpdateInput) error {
@@ -75,7 +139,7 @@ func (s *Service) Update(ctx context.Context, customerId uuid.UUID, input Update
 
 	actor := actor.MustGetFromContext(ctx)
 
-	err := s.customerRepo.UpdateCustomer(ctx, actor.MerchantId, domain.Customer{
+	update := domain.Customer{
 		Id:          input.Id,
 		FirstName:   input.FirstName,
 		LastName:    input.LastName,
@@ -83,12 +147,85 @@ func (s *Service) Update(ctx context.Context, customerId uuid.UUID, input Update
 		PhoneNumber: input.PhoneNumber,
 		Birthday:    input.Birthday,
 		Note:        input.Note,
+	}
+
+	if input.Tags != nil {
+		tags, err := normalizeTags(input.Tags)
+		if err != nil {
+			return err
+		}
+
+		update.Tags = tags
+	}
+
+	if input.CustomFields != nil {
+		fields, err := s.customerRepo.GetCustomerFields(ctx, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		update.CustomFields, err = validateCustomFields(fields, input.CustomFields, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		before, err := s.customerRepo.WithTx(tx).GetCustomerInfo(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).UpdateCustomer(ctx, actor.MerchantId, update)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.updated",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     before.Customer,
+			After:      applyCustomerUpdate(before.Customer, update),
+		})
 	})
-	if err != nil {
-		return err
+}
+
+// Returns the customer after the update, fields which are nil in the update are left unchanged
+func applyCustomerUpdate(customer domain.Customer, update domain.Customer) domain.Customer {
+	if update.FirstName != nil {
+		customer.FirstName = update.FirstName
 	}
 
-	return nil
+	if update.LastName != nil {
+		customer.LastName = update.LastName
+	}
+
+	if update.Email != nil {
+		customer.Email = update.Email
+	}
+
+	if update.PhoneNumber != nil {
+		customer.PhoneNumber = update.PhoneNumber
+	}
+
+	if update.Birthday != nil {
+		customer.Birthday = update.Birthday
+	}
+
+	if update.Note != nil {
+		customer.Note = update.Note
+	}
+
+	if update.Tags != nil {
+		customer.Tags = update.Tags
+	}
+
+	if update.CustomFields != nil {
+		customer.CustomFields = update.CustomFields
+	}
+
+	return customer
 }
 
 // TODO: we should ask if they want to delete their booking history as well or not
@@ -98,27 +235,66 @@ func (s *Service) Delete(ctx context.Context, customerId uuid.UUID) error {
 	actor := actor.MustGetFromContext(ctx)
 
 	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
-		err := s.bookingRepo.DeleteAppointmentsByCustomer(ctx, customerId, actor.MerchantId)
+		before, err := s.customerRepo.WithTx(tx).GetCustomerInfo(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		err = s.bookingRepo.WithTx(tx).DeleteAppointmentsByCustomer(ctx, customerId, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		err = s.bookingRepo.WithTx(tx).DecrementEveryParticipantCountForCustomer(ctx, customerId, actor.MerchantId)
 		if err != nil {
 			return err
 		}
 
-		err = s.bookingRepo.DecrementEveryParticipantCountForCustomer(ctx, customerId, actor.MerchantId)
+		err = s.bookingRepo.WithTx(tx).DeleteParticipantByCustomer(ctx, customerId, actor.MerchantId)
 		if err != nil {
 			return err
 		}
 
-		err = s.bookingRepo.DeleteParticipantByCustomer(ctx, customerId, actor.MerchantId)
+		err = s.customerRepo.WithTx(tx).DeleteCustomer(ctx, customerId, actor.MerchantId)
 		if err != nil {
 			return err
 		}
 
-		err = s.customerRepo.DeleteCustomer(ctx, customerId, actor.MerchantId)
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.deleted",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     before.Customer,
+			After:      nil,
+		})
+	})
+}
+
+// Erases the personal data of the customer instead of deleting it, so its bookings still count in the statistics.
+// Customers with an account can also be anonymized, the account is unlinked from the merchant
+func (s *Service) Anonymize(ctx context.Context, customerId uuid.UUID) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		// makes sure the customer belongs to the merchant
+		_, err := s.customerRepo.WithTx(tx).GetCustomerInfo(ctx, actor.MerchantId, customerId)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		err = s.customerRepo.WithTx(tx).AnonymizeCustomers(ctx, []uuid.UUID{customerId})
+		if err != nil {
+			return err
+		}
+
+		// the erased data must not be kept in the audit log either
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.anonymized",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     nil,
+			After:      nil,
+		})
 	})
 }
 
@@ -130,6 +306,10 @@ func (s *Service) Get(ctx context.Context, customerId uuid.UUID) (domain.Custome
 		return domain.CustomerInfo{}, err
 	}
 
+	if !actor.HasPermission(types.PermissionCustomersViewPii) {
+		customer.Customer = customer.WithoutPii()
+	}
+
 	return customer, nil
 }
 
@@ -141,6 +321,10 @@ func (s *Service) GetStats(ctx context.Context, customerId uuid.UUID) (domain.Cu
 		return domain.CustomerStatistics{}, err
 	}
 
+	if !actor.HasPermission(types.PermissionCustomersViewPii) {
+		customerStats.Customer = customerStats.WithoutPii()
+	}
+
 	return customerStats, nil
 }
 
@@ -154,36 +338,46 @@ func (s *Service) Blacklist(ctx context.Context, customerId uuid.UUID, input Bla
 		return fmt.Errorf("invalid customer id")
 	}
 
-	actor := actor.MustGetFromContext(ctx)
-
-	err := s.customerRepo.SetBlacklistStatusForCustomer(ctx, actor.MerchantId, customerId, true, input.BlacklistReason)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return s.setBlacklistStatus(ctx, customerId, domain.CustomerBlacklistStatus{
+		IsBlacklisted:   true,
+		BlacklistReason: input.BlacklistReason,
+	})
 }
 
 func (s *Service) UnBlacklist(ctx context.Context, customerId uuid.UUID) error {
-	actor := actor.MustGetFromContext(ctx)
-
-	err := s.customerRepo.SetBlacklistStatusForCustomer(ctx, actor.MerchantId, customerId, false, nil)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return s.setBlacklistStatus(ctx, customerId, domain.CustomerBlacklistStatus{
+		IsBlacklisted:   false,
+		BlacklistReason: nil,
+	})
 }
 
-func (s *Service) GetAll(ctx context.Context) ([]domain.PublicCustomer, error) {
+func (s *Service) setBlacklistStatus(ctx context.Context, customerId uuid.UUID, status domain.CustomerBlacklistStatus) error {
 	actor := actor.MustGetFromContext(ctx)
 
-	customers, err := s.customerRepo.GetCustomers(ctx, actor.MerchantId, false)
-	if err != nil {
-		return []domain.PublicCustomer{}, err
+	action := "customer.unblacklisted"
+	if status.IsBlacklisted {
+		action = "customer.blacklisted"
 	}
 
-	return customers, nil
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		before, err := s.customerRepo.WithTx(tx).GetCustomerBlacklistStatus(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).SetBlacklistStatusForCustomer(ctx, actor.MerchantId, customerId, status.IsBlacklisted, status.BlacklistReason)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     action,
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     before,
+			After:      status,
+		})
+	})
 }
 
 type TransferBookingsInput struct {
@@ -203,12 +397,24 @@ func (s *Service) TransferBookings(ctx context.Context, input TransferBookingsIn
 }
 
 func (s *Service) GetAllBlacklisted(ctx context.Context) ([]domain.PublicCustomer, error) {
-	actor := actor.MustGetFromContext(ctx)
+	isBlacklisted := true
 
-	blacklistedCustomers, err := s.customerRepo.GetCustomers(ctx, actor.MerchantId, true)
+	result, err := s.GetAll(ctx, GetAllInput{Filter: domain.CustomerFilter{IsBlacklisted: &isBlacklisted}})
 	if err != nil {
 		return []domain.PublicCustomer{}, err
 	}
 
-	return blacklistedCustomers, nil
+	return result.Customers, nil
+}
+
+func hidePublicCustomersPii(employee actor.EmployeeContext, customers []domain.PublicCustomer) []domain.PublicCustomer {
+	if employee.HasPermission(types.PermissionCustomersViewPii) {
+		return customers
+	}
+
+	for i := range customers {
+		customers[i].Customer = customers[i].WithoutPii()
+	}
+
+	return customers
 }
@@ -0,0 +1,250 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+const (
+	maxTags                  = 20
+	maxTagLength             = 30
+	maxCustomFieldTextLength = 500
+	maxSelectOptions         = 50
+	customFieldDateLayout    = "2006-01-02"
+)
+
+type CustomFieldInput struct {
+	Name       string
+	FieldType  types.CustomFieldType
+	Options    []string
+	IsRequired bool
+}
+
+func (s *Service) NewCustomField(ctx context.Context, input CustomFieldInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	options, err := normalizeFieldOptions(input.FieldType, input.Options)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.customerRepo.NewCustomerField(ctx, domain.CustomerField{
+		MerchantId: actor.MerchantId,
+		Name:       strings.TrimSpace(input.Name),
+		FieldType:  input.FieldType,
+		Options:    options,
+		IsRequired: input.IsRequired,
+	})
+}
+
+// The type of the field can not be changed
+func (s *Service) UpdateCustomField(ctx context.Context, fieldId int, input CustomFieldInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	fields, err := s.customerRepo.GetCustomerFields(ctx, actor.MerchantId)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(fields, func(f domain.CustomerField) bool { return f.Id == fieldId })
+	if idx == -1 {
+		return fmt.Errorf("custom field not found")
+	}
+
+	field := fields[idx]
+	if field.FieldType != input.FieldType {
+		return fmt.Errorf("the type of a custom field can not be changed")
+	}
+
+	options, err := normalizeFieldOptions(field.FieldType, input.Options)
+	if err != nil {
+		return err
+	}
+
+	field.Name = strings.TrimSpace(input.Name)
+	field.Options = options
+	field.IsRequired = input.IsRequired
+
+	return s.customerRepo.UpdateCustomerField(ctx, field)
+}
+
+// Deletes the field together with its values stored on the customers
+func (s *Service) DeleteCustomField(ctx context.Context, fieldId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.DeleteCustomerField(ctx, actor.MerchantId, fieldId)
+}
+
+func (s *Service) GetCustomFields(ctx context.Context) ([]domain.CustomerField, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCustomerFields(ctx, actor.MerchantId)
+}
+
+func (s *Service) GetTags(ctx context.Context) ([]domain.CustomerTag, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCustomerTags(ctx, actor.MerchantId)
+}
+
+func normalizeFieldOptions(fieldType types.CustomFieldType, options []string) ([]string, error) {
+	if fieldType != types.CustomFieldSelect {
+		if len(options) != 0 {
+			return nil, fmt.Errorf("only select fields can have options")
+		}
+
+		return []string{}, nil
+	}
+
+	normalized := []string{}
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+
+		if option == "" || slices.Contains(normalized, option) {
+			continue
+		}
+
+		normalized = append(normalized, option)
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("select fields need at least one option")
+	}
+
+	if len(normalized) > maxSelectOptions {
+		return nil, fmt.Errorf("select fields can have at most %d options", maxSelectOptions)
+	}
+
+	return normalized, nil
+}
+
+// Lowercases and trims the tags and removes the empty and repeated ones
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags can be at most %d characters long: %s", maxTagLength, tag)
+		}
+
+		if slices.Contains(normalized, tag) {
+			continue
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("a customer can have at most %d tags", maxTags)
+	}
+
+	return normalized, nil
+}
+
+// Checks the values against the merchant's fields and converts them to the type of the field.
+// Empty values are removed, missing required fields are only reported if checkRequired is set
+func validateCustomFields(fields []domain.CustomerField, values domain.CustomFieldValues, checkRequired bool) (domain.CustomFieldValues, error) {
+	validated := domain.CustomFieldValues{}
+
+	for key, value := range values {
+		idx := slices.IndexFunc(fields, func(f domain.CustomerField) bool { return strconv.Itoa(f.Id) == key })
+		if idx == -1 {
+			return nil, fmt.Errorf("unknown custom field: %s", key)
+		}
+
+		parsed, err := parseCustomFieldValue(fields[idx], value)
+		if err != nil {
+			return nil, err
+		}
+
+		if parsed != nil {
+			validated[key] = parsed
+		}
+	}
+
+	if checkRequired {
+		for _, field := range fields {
+			if _, ok := validated[strconv.Itoa(field.Id)]; field.IsRequired && !ok {
+				return nil, fmt.Errorf("%s is required", field.Name)
+			}
+		}
+	}
+
+	return validated, nil
+}
+
+// Converts the value to the type of the field, numbers and dates can also be given as strings.
+// Returns nil for empty values
+func parseCustomFieldValue(field domain.CustomerField, value any) (any, error) {
+	var str string
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+
+	case float64:
+		if field.FieldType != types.CustomFieldNumber {
+			return nil, fmt.Errorf("%s should be a %s", field.Name, field.FieldType.String())
+		}
+
+		return v, nil
+
+	case string:
+		str = strings.TrimSpace(v)
+		if str == "" {
+			return nil, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid value of %s: %v", field.Name, value)
+	}
+
+	switch field.FieldType {
+	case types.CustomFieldText:
+		if utf8.RuneCountInString(str) > maxCustomFieldTextLength {
+			return nil, fmt.Errorf("%s can be at most %d characters long", field.Name, maxCustomFieldTextLength)
+		}
+
+		return str, nil
+
+	case types.CustomFieldNumber:
+		number, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a number", field.Name)
+		}
+
+		return number, nil
+
+	case types.CustomFieldDate:
+		date, err := time.Parse(customFieldDateLayout, str)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a date like 2006-01-02", field.Name)
+		}
+
+		return date.Format(customFieldDateLayout), nil
+
+	case types.CustomFieldSelect:
+		if !slices.Contains(field.Options, str) {
+			return nil, fmt.Errorf("%s should be one of: %s", field.Name, strings.Join(field.Options, ", "))
+		}
+
+		return str, nil
+
+	default:
+		return nil, fmt.Errorf("unknown custom field type: %s", field.FieldType.String())
+	}
+}
@@ -0,0 +1,118 @@
+package customer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" VIP ", "vip", "", "Prefers   mornings"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vip", "prefers mornings"}, tags)
+
+	tags, err = normalizeTags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, tags)
+
+	_, err = normalizeTags([]string{strings.Repeat("a", maxTagLength+1)})
+	assert.Error(t, err)
+
+	tooMany := make([]string, maxTags+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("a", i+1)
+	}
+
+	_, err = normalizeTags(tooMany)
+	assert.Error(t, err)
+}
+
+func TestNormalizeFieldOptions(t *testing.T) {
+	options, err := normalizeFieldOptions(types.CustomFieldSelect, []string{" gold", "silver", "gold", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gold", "silver"}, options)
+
+	_, err = normalizeFieldOptions(types.CustomFieldSelect, []string{" "})
+	assert.Error(t, err)
+
+	_, err = normalizeFieldOptions(types.CustomFieldText, []string{"gold"})
+	assert.Error(t, err)
+
+	options, err = normalizeFieldOptions(types.CustomFieldNumber, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, options)
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	fields := []domain.CustomerField{
+		{Id: 1, Name: "Membership", FieldType: types.CustomFieldSelect, Options: []string{"gold", "silver"}, IsRequired: true},
+		{Id: 2, Name: "Shoe size", FieldType: types.CustomFieldNumber},
+		{Id: 3, Name: "First visit", FieldType: types.CustomFieldDate},
+		{Id: 4, Name: "Allergies", FieldType: types.CustomFieldText},
+	}
+
+	values, err := validateCustomFields(fields, domain.CustomFieldValues{
+		"1": "gold", "2": "42.5", "3": " 2024-03-01 ", "4": "",
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CustomFieldValues{"1": "gold", "2": 42.5, "3": "2024-03-01"}, values)
+
+	values, err = validateCustomFields(fields, domain.CustomFieldValues{"1": "silver", "2": 38.0}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CustomFieldValues{"1": "silver", "2": 38.0}, values)
+
+	invalid := []domain.CustomFieldValues{
+		{"1": "bronze"},
+		{"1": "gold", "2": "large"},
+		{"1": "gold", "3": "03/01/2024"},
+		{"1": "gold", "4": 12.0},
+		{"1": "gold", "4": strings.Repeat("a", maxCustomFieldTextLength+1)},
+		{"1": "gold", "5": "unknown"},
+		{"1": "gold", "2": true},
+		{"2": 38.0},
+	}
+
+	for _, v := range invalid {
+		_, err := validateCustomFields(fields, v, true)
+		assert.Error(t, err, v)
+	}
+
+	// required fields are not checked when filtering
+	values, err = validateCustomFields(fields, domain.CustomFieldValues{"2": "38"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CustomFieldValues{"2": 38.0}, values)
+}
+
+func TestCombineCustomerFilters(t *testing.T) {
+	ninety := 90
+	three := 3
+	blacklisted := true
+
+	segment := domain.CustomerFilter{
+		NoVisitInDays: &ninety,
+		Tags:          []string{"vip"},
+		CustomFields:  domain.CustomFieldValues{"1": "gold"},
+	}
+
+	combined := combineCustomerFilters(segment, domain.CustomerFilter{
+		IsBlacklisted:     &blacklisted,
+		MinNoShows:        &three,
+		BirthdayThisMonth: true,
+		Tags:              []string{"regular"},
+		CustomFields:      domain.CustomFieldValues{"2": 42.0},
+	})
+
+	assert.Equal(t, &ninety, combined.NoVisitInDays)
+	assert.Equal(t, &three, combined.MinNoShows)
+	assert.Equal(t, &blacklisted, combined.IsBlacklisted)
+	assert.True(t, combined.BirthdayThisMonth)
+	assert.Equal(t, []string{"vip", "regular"}, combined.Tags)
+	assert.Equal(t, domain.CustomFieldValues{"1": "gold", "2": 42.0}, combined.CustomFields)
+
+	// the segment itself is left unchanged
+	assert.Equal(t, []string{"vip"}, segment.Tags)
+	assert.Equal(t, domain.CustomFieldValues{"1": "gold"}, segment.CustomFields)
+}
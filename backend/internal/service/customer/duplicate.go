@@ -0,0 +1,517 @@
+package customer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// Names at least this similar are reported even if the contact details differ
+	nameSimilarityThreshold = 0.9
+	// Phone numbers are compared by their last digits, so the national and
+	// international formats of the same number match
+	phoneKeyLength = 9
+	minPhoneDigits = 7
+	maxDuplicates  = 500
+
+	nameWeight  = 0.4
+	emailWeight = 0.3
+	phoneWeight = 0.3
+)
+
+// Reasons why two customers are considered duplicates
+const (
+	DuplicateName  = "name"
+	DuplicateEmail = "email"
+	DuplicatePhone = "phone_number"
+)
+
+// Two customers who are probably the same person. Customer is the one the duplicate
+// should be merged into, it's the one with an account if there is one
+type Duplicate struct {
+	Customer  domain.DuplicateCandidate
+	Duplicate domain.DuplicateCandidate
+	// Between 0 and 1, higher means more likely to be the same person
+	Score   float64
+	Reasons []string
+}
+
+func (s *Service) FindDuplicates(ctx context.Context) ([]Duplicate, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	candidates, err := s.customerRepo.GetDuplicateCandidates(ctx, actor.MerchantId)
+	if err != nil {
+		return []Duplicate{}, err
+	}
+
+	duplicates := findDuplicateCustomers(candidates)
+
+	if !actor.HasPermission(types.PermissionCustomersViewPii) {
+		for i := range duplicates {
+			duplicates[i].Customer = duplicateCandidateWithoutPii(duplicates[i].Customer)
+			duplicates[i].Duplicate = duplicateCandidateWithoutPii(duplicates[i].Duplicate)
+		}
+	}
+
+	return duplicates, nil
+}
+
+func duplicateCandidateWithoutPii(c domain.DuplicateCandidate) domain.DuplicateCandidate {
+	c.Email = nil
+	c.PhoneNumber = nil
+
+	return c
+}
+
+type comparableCustomer struct {
+	candidate domain.DuplicateCandidate
+	name      string
+	// the name with the first and last name swapped, as they are often mixed up
+	swappedName string
+	email       string
+	phone       string
+}
+
+// Compares the customers who share a blocking key, which is the email, the phone number or the initials,
+// so every customer does not have to be compared with every other one
+func findDuplicateCustomers(candidates []domain.DuplicateCandidate) []Duplicate {
+	customers := make([]comparableCustomer, len(candidates))
+	blocks := map[string][]int{}
+
+	for i, c := range candidates {
+		first := normalizeName(valueOrEmpty(c.FirstName))
+		last := normalizeName(valueOrEmpty(c.LastName))
+
+		customers[i] = comparableCustomer{
+			candidate:   c,
+			name:        strings.TrimSpace(first + " " + last),
+			swappedName: strings.TrimSpace(last + " " + first),
+			email:       emailKey(c.Email),
+			phone:       phoneKey(c.PhoneNumber),
+		}
+
+		if customers[i].email != "" {
+			blocks["e:"+customers[i].email] = append(blocks["e:"+customers[i].email], i)
+		}
+
+		if customers[i].phone != "" {
+			blocks["p:"+customers[i].phone] = append(blocks["p:"+customers[i].phone], i)
+		}
+
+		if first != "" && last != "" {
+			initials := []string{string([]rune(first)[0]), string([]rune(last)[0])}
+			slices.Sort(initials)
+
+			key := "n:" + strings.Join(initials, "")
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	compared := map[[2]int]bool{}
+	duplicates := []Duplicate{}
+
+	for _, block := range blocks {
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				pair := [2]int{min(block[x], block[y]), max(block[x], block[y])}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				duplicate, ok := compareCustomers(customers[pair[0]], customers[pair[1]])
+				if ok {
+					duplicates = append(duplicates, duplicate)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(duplicates, func(a, b Duplicate) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Customer.Id.String(), b.Customer.Id.String())
+	})
+
+	if len(duplicates) > maxDuplicates {
+		duplicates = duplicates[:maxDuplicates]
+	}
+
+	return duplicates
+}
+
+func compareCustomers(a, b comparableCustomer) (Duplicate, bool) {
+	// two accounts are different people, and they could not be merged anyway
+	if !a.candidate.IsDummy && !b.candidate.IsDummy {
+		return Duplicate{}, false
+	}
+
+	var score float64
+	reasons := []string{}
+
+	nameSimilarity := max(jaroWinkler(a.name, b.name), jaroWinkler(a.name, b.swappedName))
+	if nameSimilarity >= nameSimilarityThreshold {
+		reasons = append(reasons, DuplicateName)
+	}
+	score += nameWeight * nameSimilarity
+
+	if a.email != "" && a.email == b.email {
+		reasons = append(reasons, DuplicateEmail)
+		score += emailWeight
+	}
+
+	if a.phone != "" && a.phone == b.phone {
+		reasons = append(reasons, DuplicatePhone)
+		score += phoneWeight
+	}
+
+	if len(reasons) == 0 {
+		return Duplicate{}, false
+	}
+
+	customer, duplicate := a.candidate, b.candidate
+	if customer.IsDummy && !duplicate.IsDummy {
+		customer, duplicate = duplicate, customer
+	}
+
+	return Duplicate{
+		Customer:  customer,
+		Duplicate: duplicate,
+		Score:     min(score, 1),
+		Reasons:   reasons,
+	}, true
+}
+
+// Lowercases the name and removes the accents and punctuation, e.g. "Kovács-Nagy  Éva" becomes "kovacs nagy eva"
+func normalizeName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	result, _, err := transform.String(t, strings.ToLower(name))
+	if err != nil {
+		result = strings.ToLower(name)
+	}
+
+	return strings.Join(strings.FieldsFunc(result, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}), " ")
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func emailKey(email *string) string {
+	if email == nil {
+		return ""
+	}
+
+	normalized, err := normalizeEmail(*email)
+	if err != nil {
+		return ""
+	}
+
+	return normalized
+}
+
+func phoneKey(phoneNumber *string) string {
+	if phoneNumber == nil {
+		return ""
+	}
+
+	var digits strings.Builder
+	for _, r := range *phoneNumber {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	key := digits.String()
+	if len(key) < minPhoneDigits {
+		return ""
+	}
+
+	if len(key) > phoneKeyLength {
+		key = key[len(key)-phoneKeyLength:]
+	}
+
+	return key
+}
+
+// Similarity of two strings between 0 and 1, which favours strings with a common prefix
+func jaroWinkler(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+
+	if a == b {
+		return 1
+	}
+
+	s1, s2 := []rune(a), []rune(b)
+
+	matchDistance := max(len(s1), len(s2))/2 - 1
+	matchDistance = max(matchDistance, 0)
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+
+	matches := 0
+	for i := range s1 {
+		start := max(0, i-matchDistance)
+		end := min(len(s2), i+matchDistance+1)
+
+		for j := start; j < end; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+
+			matched1[i] = true
+			matched2[j] = true
+			matches++
+			break
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+
+		for !matched2[j] {
+			j++
+		}
+
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+type MergeInput struct {
+	DuplicateId uuid.UUID
+}
+
+// Merges the duplicate into the customer. The customer keeps its own details and custom field values and only
+// takes the missing ones from the duplicate, the notes, tags and blacklist reasons of the two are joined.
+// The bookings, series and passes of the duplicate are moved to the customer and the duplicate is deleted
+func (s *Service) Merge(ctx context.Context, customerId uuid.UUID, input MergeInput) error {
+	if customerId == input.DuplicateId {
+		return fmt.Errorf("a customer can not be merged into itself")
+	}
+
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		customer, err := s.customerRepo.WithTx(tx).GetCustomerForMergeWithLock(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		duplicate, err := s.customerRepo.WithTx(tx).GetCustomerForMergeWithLock(ctx, actor.MerchantId, input.DuplicateId)
+		if err != nil {
+			return err
+		}
+
+		if duplicate.UserId != nil {
+			if customer.UserId != nil {
+				return fmt.Errorf("customers who both have an account can not be merged")
+			}
+
+			return fmt.Errorf("the duplicate has an account, merge the customer into the duplicate instead")
+		}
+
+		merged := mergeCustomerFields(customer, duplicate)
+
+		err = s.customerRepo.WithTx(tx).UpdateMergedCustomer(ctx, actor.MerchantId, merged)
+		if err != nil {
+			return err
+		}
+
+		// has to happen before the participations are merged
+		err = s.passRepo.WithTx(tx).MergeCustomerPasses(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		movedParticipants, err := s.bookingRepo.WithTx(tx).MergeCustomerParticipants(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		movedSeriesParticipants, err := s.bookingRepo.WithTx(tx).MergeCustomerSeriesParticipants(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).MoveCustomerMerges(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).MoveMarketingConsents(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		err = s.invoiceRepo.WithTx(tx).MergeCustomerInvoices(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		err = s.giftCardRepo.WithTx(tx).MergeCustomerGiftCardTransactions(ctx, actor.MerchantId, duplicate.Id, customer.Id)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).DeleteCustomer(ctx, duplicate.Id, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		err = s.customerRepo.WithTx(tx).NewCustomerMerge(ctx, domain.CustomerMerge{
+			MerchantId:              actor.MerchantId,
+			CustomerId:              customer.Id,
+			EmployeeId:              &actor.EmployeeId,
+			MergedCustomer:          duplicate,
+			MovedParticipants:       movedParticipants,
+			MovedSeriesParticipants: movedSeriesParticipants,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.deleted",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   duplicate.Id.String(),
+			Before:     duplicate,
+			After:      nil,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.merged",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customer.Id.String(),
+			Before:     customer,
+			After:      merged,
+		})
+	})
+}
+
+// Returns the customer completed with the details of the duplicate
+func mergeCustomerFields(customer, duplicate domain.CustomerForMerge) domain.CustomerForMerge {
+	merged := customer
+
+	merged.FirstName = firstNonEmpty(customer.FirstName, duplicate.FirstName)
+	merged.LastName = firstNonEmpty(customer.LastName, duplicate.LastName)
+	merged.Email = firstNonEmpty(customer.Email, duplicate.Email)
+	merged.PhoneNumber = firstNonEmpty(customer.PhoneNumber, duplicate.PhoneNumber)
+
+	if merged.Birthday == nil {
+		merged.Birthday = duplicate.Birthday
+	}
+
+	merged.Note = joinTexts(customer.Note, duplicate.Note)
+
+	merged.Tags = append([]string{}, customer.Tags...)
+	for _, tag := range duplicate.Tags {
+		if !slices.Contains(merged.Tags, tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+
+	merged.CustomFields = domain.CustomFieldValues{}
+	for key, value := range duplicate.CustomFields {
+		merged.CustomFields[key] = value
+	}
+	for key, value := range customer.CustomFields {
+		merged.CustomFields[key] = value
+	}
+
+	merged.IsBlacklisted = customer.IsBlacklisted || duplicate.IsBlacklisted
+	if merged.IsBlacklisted {
+		merged.BlacklistReason = joinTexts(customer.BlacklistReason, duplicate.BlacklistReason)
+	}
+
+	return merged
+}
+
+func firstNonEmpty(values ...*string) *string {
+	for _, v := range values {
+		if v != nil && strings.TrimSpace(*v) != "" {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func joinTexts(a, b *string) *string {
+	if firstNonEmpty(a) == nil {
+		return firstNonEmpty(b)
+	}
+
+	if firstNonEmpty(b) == nil || strings.TrimSpace(*a) == strings.TrimSpace(*b) {
+		return a
+	}
+
+	joined := *a + "\n\n" + *b
+	return &joined
+}
+
+func (s *Service) GetMerges(ctx context.Context, customerId uuid.UUID) ([]domain.CustomerMerge, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	merges, err := s.customerRepo.GetCustomerMerges(ctx, actor.MerchantId, customerId)
+	if err != nil {
+		return []domain.CustomerMerge{}, err
+	}
+
+	if !actor.HasPermission(types.PermissionCustomersViewPii) {
+		for i := range merges {
+			merges[i].MergedCustomer.Customer = merges[i].MergedCustomer.WithoutPii()
+		}
+	}
+
+	return merges, nil
+}
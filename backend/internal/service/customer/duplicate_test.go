@@ -0,0 +1,140 @@
+package customer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "kovacs nagy eva", normalizeName("  Kovács-Nagy  Éva "))
+	assert.Equal(t, "o connor", normalizeName("O'Connor"))
+	assert.Equal(t, "", normalizeName(" . "))
+}
+
+func TestJaroWinkler(t *testing.T) {
+	assert.Equal(t, 1.0, jaroWinkler("martha", "martha"))
+	assert.Equal(t, 0.0, jaroWinkler("", "martha"))
+	assert.Equal(t, 0.0, jaroWinkler("abc", "xyz"))
+	assert.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	assert.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	assert.Less(t, jaroWinkler("kovacs eva", "szabo peter"), nameSimilarityThreshold)
+	assert.GreaterOrEqual(t, jaroWinkler("kovacs eva", "kovacs evi"), nameSimilarityThreshold)
+}
+
+func TestPhoneKey(t *testing.T) {
+	national := "06 30 123 4567"
+	international := "+36301234567"
+	short := "12-34"
+
+	assert.Equal(t, "301234567", phoneKey(&national))
+	assert.Equal(t, phoneKey(&national), phoneKey(&international))
+	assert.Equal(t, "", phoneKey(&short))
+	assert.Equal(t, "", phoneKey(nil))
+}
+
+func TestFindDuplicateCustomers(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	account := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Éva"), LastName: str("Kovács"), Email: str("eva@example.com"), IsDummy: false,
+	}
+	typo := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Eva"), LastName: str("Kovacs"), PhoneNumber: str("06301234567"), IsDummy: true,
+	}
+	sameEmail := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("E."), LastName: str("K."), Email: str("EVA@example.com "), IsDummy: true,
+	}
+	otherAccount := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Eva"), LastName: str("Kovacs"), IsDummy: false,
+	}
+	swapped := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Szabó"), LastName: str("Péter"), PhoneNumber: str("+36 20 765 4321"), IsDummy: true,
+	}
+	samePhone := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Peter"), LastName: str("Szabo"), PhoneNumber: str("06207654321"), IsDummy: true,
+	}
+	stranger := domain.DuplicateCandidate{
+		Id: uuid.New(), FirstName: str("Anna"), LastName: str("Tóth"), IsDummy: true,
+	}
+
+	duplicates := findDuplicateCustomers([]domain.DuplicateCandidate{account, typo, sameEmail, otherAccount, swapped, samePhone, stranger})
+
+	type pair struct {
+		customer  uuid.UUID
+		duplicate uuid.UUID
+	}
+
+	found := map[pair][]string{}
+	for _, d := range duplicates {
+		found[pair{d.Customer.Id, d.Duplicate.Id}] = d.Reasons
+		assert.LessOrEqual(t, d.Score, 1.0)
+	}
+
+	// the customer with an account is the one to merge into
+	assert.Equal(t, []string{DuplicateName}, found[pair{account.Id, typo.Id}])
+	assert.Equal(t, []string{DuplicateEmail}, found[pair{account.Id, sameEmail.Id}])
+	assert.Equal(t, []string{DuplicateName}, found[pair{otherAccount.Id, typo.Id}])
+	assert.Equal(t, []string{DuplicateName, DuplicatePhone}, found[pair{swapped.Id, samePhone.Id}])
+
+	// two accounts are never duplicates
+	assert.NotContains(t, found, pair{account.Id, otherAccount.Id})
+	assert.NotContains(t, found, pair{otherAccount.Id, account.Id})
+
+	for p := range found {
+		assert.NotEqual(t, stranger.Id, p.customer)
+		assert.NotEqual(t, stranger.Id, p.duplicate)
+	}
+
+	// the pair matching on the most details comes first
+	assert.Equal(t, swapped.Id, duplicates[0].Customer.Id)
+	assert.Len(t, duplicates, 4)
+}
+
+func TestMergeCustomerFields(t *testing.T) {
+	str := func(s string) *string { return &s }
+	birthday := time.Date(1990, 5, 21, 0, 0, 0, 0, time.UTC)
+
+	customer := domain.CustomerForMerge{
+		Customer: domain.Customer{
+			Id: uuid.New(), FirstName: str("Eva"), LastName: str("Kovacs"), Email: str("eva@example.com"), PhoneNumber: str(" "), Note: str("Prefers mornings"),
+			Tags: []string{"vip"}, CustomFields: domain.CustomFieldValues{"1": "gold"},
+		},
+	}
+	duplicate := domain.CustomerForMerge{
+		Customer: domain.Customer{
+			Id: uuid.New(), FirstName: str("Évi"), LastName: str("Kovács"), Email: str("other@example.com"), PhoneNumber: str("+36301234567"),
+			Birthday: &birthday, Note: str("Allergic to latex"),
+			Tags: []string{"vip", "regular"}, CustomFields: domain.CustomFieldValues{"1": "silver", "2": 3.0},
+		},
+		IsBlacklisted:   true,
+		BlacklistReason: str("No-show twice"),
+	}
+
+	merged := mergeCustomerFields(customer, duplicate)
+
+	assert.Equal(t, customer.Id, merged.Id)
+	assert.Equal(t, "Eva", *merged.FirstName)
+	assert.Equal(t, "Kovacs", *merged.LastName)
+	assert.Equal(t, "eva@example.com", *merged.Email)
+	assert.Equal(t, "+36301234567", *merged.PhoneNumber)
+	assert.Equal(t, &birthday, merged.Birthday)
+	assert.Equal(t, "Prefers mornings\n\nAllergic to latex", *merged.Note)
+	assert.Equal(t, []string{"vip", "regular"}, merged.Tags)
+	assert.Equal(t, domain.CustomFieldValues{"1": "gold", "2": 3.0}, merged.CustomFields)
+	assert.True(t, merged.IsBlacklisted)
+	assert.Equal(t, "No-show twice", *merged.BlacklistReason)
+
+	// the same note is not repeated
+	duplicate.Note = str("Prefers mornings ")
+	assert.Equal(t, "Prefers mornings", *mergeCustomerFields(customer, duplicate).Note)
+
+	duplicate.IsBlacklisted = false
+	duplicate.BlacklistReason = nil
+	merged = mergeCustomerFields(customer, duplicate)
+	assert.False(t, merged.IsBlacklisted)
+	assert.Nil(t, merged.BlacklistReason)
+}
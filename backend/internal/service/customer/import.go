@@ -0,0 +1,577 @@
+package customer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/service/webhook"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/riverqueue/river"
+)
+
+const (
+	maxImportSize = 5 << 20
+	maxImportRows = 10000
+	// rows imported in one transaction, the progress is saved after every batch
+	importBatchSize = 100
+	// errors of the rows after this many are only counted
+	maxImportRowErrors = 1000
+	// length of the name columns in the database
+	maxNameLength = 30
+)
+
+type ImportInput struct {
+	Content string
+	Options domain.CustomerImportOptions
+}
+
+type ImportDuplicate struct {
+	// Existing customer with the same email or phone number
+	CustomerId *uuid.UUID
+	// Earlier row of the file with the same email or phone number
+	Row *int
+}
+
+type ImportPreviewRow struct {
+	Row       int
+	Customer  domain.Customer
+	Duplicate *ImportDuplicate
+	Errors    []domain.CustomerImportRowError
+}
+
+type ImportPreview struct {
+	Columns        []string
+	TotalRows      int
+	ValidCount     int
+	DuplicateCount int
+	InvalidCount   int
+	Rows           []ImportPreviewRow
+}
+
+// Dry run of the import, returns what would happen to each row without creating any customer
+func (s *Service) PreviewImport(ctx context.Context, input ImportInput) (ImportPreview, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	columns, rows, err := parseImport(input.Content, input.Options, time.Now())
+	if err != nil {
+		return ImportPreview{}, err
+	}
+
+	contacts, err := s.customerRepo.GetCustomerContacts(ctx, actor.MerchantId)
+	if err != nil {
+		return ImportPreview{}, err
+	}
+
+	findDuplicates(rows, contacts, input.Options.DefaultCountryCode)
+
+	preview := ImportPreview{
+		Columns:   columns,
+		TotalRows: len(rows),
+		Rows:      make([]ImportPreviewRow, len(rows)),
+	}
+
+	for i, row := range rows {
+		switch {
+		case len(row.errors) > 0:
+			preview.InvalidCount++
+		case row.duplicate != nil:
+			preview.DuplicateCount++
+		default:
+			preview.ValidCount++
+		}
+
+		preview.Rows[i] = ImportPreviewRow{
+			Row:       row.line,
+			Customer:  row.customer,
+			Duplicate: row.duplicate,
+			Errors:    row.errors,
+		}
+	}
+
+	return preview, nil
+}
+
+// Saves the file and schedules the import job, the errors of the whole file are returned immediately
+func (s *Service) StartImport(ctx context.Context, input ImportInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	_, rows, err := parseImport(input.Content, input.Options, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var importId int
+
+	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		importId, err = s.customerRepo.WithTx(tx).NewCustomerImport(ctx, domain.CustomerImport{
+			MerchantId: actor.MerchantId,
+			EmployeeId: &actor.EmployeeId,
+			Status:     types.CustomerImportPending,
+			Content:    input.Content,
+			Options:    input.Options,
+			TotalRows:  len(rows),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.ImportCustomers{
+			MerchantId: actor.MerchantId,
+			ImportId:   importId,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule customer import job: %w", err)
+		}
+
+		return nil
+	})
+
+	return importId, err
+}
+
+func (s *Service) GetImport(ctx context.Context, importId int) (domain.CustomerImport, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCustomerImport(ctx, actor.MerchantId, importId)
+}
+
+// Creates the customers of the import's valid rows. The progress is saved after every batch,
+// so a retried job continues after the last imported batch
+func (s *Service) RunImport(ctx context.Context, merchantId uuid.UUID, importId int) error {
+	customerImport, err := s.customerRepo.GetCustomerImport(ctx, merchantId, importId)
+	if err != nil {
+		return err
+	}
+
+	if customerImport.Status == types.CustomerImportCompleted || customerImport.Status == types.CustomerImportFailed {
+		return nil
+	}
+
+	progress := domain.CustomerImportProgress{
+		Status:         types.CustomerImportRunning,
+		ProcessedRows:  customerImport.ProcessedRows,
+		CreatedCount:   customerImport.CreatedCount,
+		DuplicateCount: customerImport.DuplicateCount,
+		InvalidCount:   customerImport.InvalidCount,
+		RowErrors:      customerImport.RowErrors,
+	}
+
+	_, rows, err := parseImport(customerImport.Content, customerImport.Options, customerImport.CreatedAt)
+	if err != nil {
+		// the file was valid when the import was started, retrying would not help
+		progress.Status = types.CustomerImportFailed
+		message := err.Error()
+		progress.Error = &message
+
+		if err := s.customerRepo.UpdateCustomerImportProgress(ctx, importId, progress); err != nil {
+			return err
+		}
+
+		return river.JobCancel(err)
+	}
+
+	if err := s.customerRepo.UpdateCustomerImportProgress(ctx, importId, progress); err != nil {
+		return err
+	}
+
+	contacts, err := s.customerRepo.GetCustomerContacts(ctx, merchantId)
+	if err != nil {
+		return err
+	}
+
+	findDuplicates(rows, contacts, customerImport.Options.DefaultCountryCode)
+
+	for start := progress.ProcessedRows; start < len(rows); start += importBatchSize {
+		end := min(start+importBatchSize, len(rows))
+
+		var customers []domain.Customer
+
+		for _, row := range rows[start:end] {
+			switch {
+			case len(row.errors) > 0:
+				progress.InvalidCount++
+				addImportRowErrors(&progress, row.errors...)
+
+			case row.duplicate != nil:
+				progress.DuplicateCount++
+				addImportRowErrors(&progress, domain.CustomerImportRowError{Row: row.line, Message: row.duplicate.message()})
+
+			default:
+				customer := row.customer
+
+				customer.Id, err = uuid.NewV7()
+				if err != nil {
+					return fmt.Errorf("unexpected error during creating customer id: %s", err.Error())
+				}
+
+				customers = append(customers, customer)
+			}
+		}
+
+		progress.ProcessedRows = end
+		progress.CreatedCount += len(customers)
+
+		if end == len(rows) {
+			progress.Status = types.CustomerImportCompleted
+		}
+
+		if err := s.saveImportProgress(ctx, customerImport, progress, customers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Creates the customers of a batch and saves the progress in one transaction.
+// The finished import is recorded in the audit log as a single entry, on behalf of the employee who started it
+func (s *Service) saveImportProgress(ctx context.Context, customerImport domain.CustomerImport, progress domain.CustomerImportProgress,
+	customers []domain.Customer) error {
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if len(customers) > 0 {
+			if err := s.customerRepo.WithTx(tx).NewCustomers(ctx, customerImport.MerchantId, customers); err != nil {
+				return err
+			}
+
+			if err := s.enqueueCustomersCreated(ctx, tx, customerImport.MerchantId, customers); err != nil {
+				return err
+			}
+		}
+
+		if err := s.customerRepo.WithTx(tx).UpdateCustomerImportProgress(ctx, customerImport.Id, progress); err != nil {
+			return err
+		}
+
+		if progress.Status != types.CustomerImportCompleted {
+			return nil
+		}
+
+		_, after, err := audit.Diff(nil, map[string]int{
+			"total_rows":      customerImport.TotalRows,
+			"created_count":   progress.CreatedCount,
+			"duplicate_count": progress.DuplicateCount,
+			"invalid_count":   progress.InvalidCount,
+		})
+		if err != nil {
+			return fmt.Errorf("could not create audit log diff: %s", err.Error())
+		}
+
+		return s.auditLogRepo.WithTx(tx).NewAuditLogEntry(ctx, domain.AuditLogEntry{
+			MerchantId: customerImport.MerchantId,
+			EmployeeId: customerImport.EmployeeId,
+			Action:     "customer.imported",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   "import:" + strconv.Itoa(customerImport.Id),
+			After:      after,
+		})
+	})
+}
+
+func (s *Service) enqueueCustomersCreated(ctx context.Context, tx pgx.Tx, merchantId uuid.UUID, customers []domain.Customer) error {
+	params := make([]river.InsertManyParams, len(customers))
+
+	for i, customer := range customers {
+		event, err := webhook.NewEvent(merchantId, types.WebhookEventCustomerCreated, webhook.NewCustomerData(customer))
+		if err != nil {
+			return err
+		}
+
+		params[i] = river.InsertManyParams{Args: event}
+	}
+
+	_, err := s.enqueuer.InsertManyFastTx(ctx, tx, params)
+	if err != nil {
+		return fmt.Errorf("could not schedule webhook event jobs: %w", err)
+	}
+
+	return nil
+}
+
+type importRow struct {
+	line      int
+	customer  domain.Customer
+	duplicate *ImportDuplicate
+	errors    []domain.CustomerImportRowError
+}
+
+func (d ImportDuplicate) message() string {
+	if d.Row != nil {
+		return fmt.Sprintf("same email or phone number as row %d", *d.Row)
+	}
+
+	return "a customer with the same email or phone number already exists"
+}
+
+func addRowError(row *importRow, field string, err error) {
+	row.errors = append(row.errors, domain.CustomerImportRowError{Row: row.line, Field: &field, Message: err.Error()})
+}
+
+func addImportRowErrors(progress *domain.CustomerImportProgress, rowErrors ...domain.CustomerImportRowError) {
+	for _, rowError := range rowErrors {
+		if len(progress.RowErrors) >= maxImportRowErrors {
+			return
+		}
+
+		progress.RowErrors = append(progress.RowErrors, rowError)
+	}
+}
+
+// Fields of the customer in the order of the mapping, by their json names
+func mappedColumns(mapping domain.CustomerImportMapping) []struct{ field, column string } {
+	return []struct{ field, column string }{
+		{"first_name", mapping.FirstName},
+		{"last_name", mapping.LastName},
+		{"email", mapping.Email},
+		{"phone_number", mapping.PhoneNumber},
+		{"birthday", mapping.Birthday},
+		{"note", mapping.Note},
+	}
+}
+
+// Reads the rows of the csv file and normalizes their values. Errors of the file itself are returned,
+// the invalid values are reported in the errors of their row
+func parseImport(content string, options domain.CustomerImportOptions, now time.Time) ([]string, []importRow, error) {
+	if len(content) > maxImportSize {
+		return nil, nil, fmt.Errorf("the file can not be larger than %d MB", maxImportSize>>20)
+	}
+
+	if options.DefaultCountryCode != "" && !countryCodeRegexp.MatchString(options.DefaultCountryCode) {
+		return nil, nil, fmt.Errorf("invalid default country code: %s", options.DefaultCountryCode)
+	}
+
+	// spreadsheet programs often start the file with a byte order mark
+	content = strings.TrimPrefix(content, "\uFEFF")
+
+	if !utf8.ValidString(content) {
+		return nil, nil, fmt.Errorf("the file has to be utf-8 encoded")
+	}
+
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comma = detectDelimiter(content)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("the file is empty")
+		}
+
+		return nil, nil, fmt.Errorf("invalid csv file: %s", err.Error())
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	columnIndexes := map[string]int{}
+
+	for _, mapped := range mappedColumns(options.Mapping) {
+		column := mapped.column
+		if column == "" {
+			column = mapped.field
+		}
+
+		index := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(h, column) })
+		if index == -1 {
+			if mapped.column != "" {
+				return nil, nil, fmt.Errorf("column %s of %s is not in the file", mapped.column, mapped.field)
+			}
+
+			continue
+		}
+
+		columnIndexes[mapped.field] = index
+	}
+
+	for _, field := range []string{"first_name", "last_name"} {
+		if _, ok := columnIndexes[field]; !ok {
+			return nil, nil, fmt.Errorf("the file has no column for %s", field)
+		}
+	}
+
+	var rows []importRow
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid csv file: %s", err.Error())
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		values := map[string]string{}
+		for field, index := range columnIndexes {
+			if index < len(record) {
+				values[field] = strings.TrimSpace(record[index])
+			}
+		}
+
+		if isEmptyRecord(values) {
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, nil, fmt.Errorf("the file can not have more than %d rows", maxImportRows)
+		}
+
+		rows = append(rows, parseImportRow(line, values, options.DefaultCountryCode, now))
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("the file has no customers")
+	}
+
+	return header, rows, nil
+}
+
+func parseImportRow(line int, values map[string]string, defaultCountryCode string, now time.Time) importRow {
+	row := importRow{line: line}
+
+	for _, field := range []string{"first_name", "last_name"} {
+		value := values[field]
+
+		switch {
+		case value == "":
+			addRowError(&row, field, fmt.Errorf("%s is required", strings.ReplaceAll(field, "_", " ")))
+		case utf8.RuneCountInString(value) > maxNameLength:
+			addRowError(&row, field, fmt.Errorf("%s can not be longer than %d characters", strings.ReplaceAll(field, "_", " "), maxNameLength))
+		}
+	}
+
+	row.customer.FirstName = optionalString(values["first_name"])
+	row.customer.LastName = optionalString(values["last_name"])
+	row.customer.Note = optionalString(values["note"])
+
+	if value := values["email"]; value != "" {
+		email, err := normalizeEmail(value)
+		if err != nil {
+			addRowError(&row, "email", err)
+		} else {
+			row.customer.Email = &email
+		}
+	}
+
+	if value := values["phone_number"]; value != "" {
+		phoneNumber, err := normalizePhoneNumber(value, defaultCountryCode)
+		if err != nil {
+			addRowError(&row, "phone_number", err)
+		} else {
+			row.customer.PhoneNumber = &phoneNumber
+		}
+	}
+
+	if value := values["birthday"]; value != "" {
+		birthday, err := parseBirthday(value, now)
+		if err != nil {
+			addRowError(&row, "birthday", err)
+		} else {
+			row.customer.Birthday = &birthday
+		}
+	}
+
+	return row
+}
+
+// Excel uses semicolons in locales where the comma is the decimal separator
+func detectDelimiter(content string) rune {
+	header, _, _ := strings.Cut(content, "\n")
+
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		return ';'
+	}
+
+	return ','
+}
+
+func isEmptyRecord(values map[string]string) bool {
+	for _, value := range values {
+		if value != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// Marks the valid rows whose email or phone number belongs to an existing customer or to an earlier row
+func findDuplicates(rows []importRow, contacts []domain.CustomerContact, defaultCountryCode string) {
+	customersByContact := map[string]uuid.UUID{}
+
+	for _, contact := range contacts {
+		if contact.Email != nil {
+			if email, err := normalizeEmail(*contact.Email); err == nil {
+				customersByContact[email] = contact.Id
+			}
+		}
+
+		if contact.PhoneNumber != nil {
+			if phoneNumber, err := normalizePhoneNumber(*contact.PhoneNumber, defaultCountryCode); err == nil {
+				customersByContact[phoneNumber] = contact.Id
+			}
+		}
+	}
+
+	rowsByContact := map[string]int{}
+
+	for i := range rows {
+		row := &rows[i]
+
+		if len(row.errors) > 0 {
+			continue
+		}
+
+		var keys []string
+		if row.customer.Email != nil {
+			keys = append(keys, *row.customer.Email)
+		}
+		if row.customer.PhoneNumber != nil {
+			keys = append(keys, *row.customer.PhoneNumber)
+		}
+
+		for _, key := range keys {
+			if customerId, ok := customersByContact[key]; ok {
+				row.duplicate = &ImportDuplicate{CustomerId: &customerId}
+				break
+			}
+
+			if line, ok := rowsByContact[key]; ok {
+				row.duplicate = &ImportDuplicate{Row: &line}
+				break
+			}
+		}
+
+		if row.duplicate != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			rowsByContact[key] = row.line
+		}
+	}
+}
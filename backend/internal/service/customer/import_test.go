@@ -0,0 +1,167 @@
+package customer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw                string
+		defaultCountryCode string
+		expected           string
+		isValid            bool
+	}{
+		{"+36 30 123 4567", "", "+36301234567", true},
+		{"0036 (30) 123-4567", "", "+36301234567", true},
+		{"06 30 123 4567", "+36", "+36301234567", true},
+		{"030 1234567", "+49", "+49301234567", true},
+		{"30 123 4567", "", "", false},
+		{"+36 30 123 456a", "", "", false},
+		{"36+301234567", "", "", false},
+		{"+3612", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			phoneNumber, err := normalizePhoneNumber(tt.raw, tt.defaultCountryCode)
+
+			if tt.isValid {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, phoneNumber)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	email, err := normalizeEmail("  Jane.Doe@Example.com ")
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe@example.com", email)
+
+	for _, raw := range []string{"jane", "Jane <jane@example.com>", "jane@"} {
+		_, err := normalizeEmail(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestParseBirthday(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	expected := time.Date(1990, 5, 21, 0, 0, 0, 0, time.UTC)
+
+	for _, raw := range []string{"1990-05-21", "1990.05.21", "1990. 05. 21.", "1990/05/21"} {
+		birthday, err := parseBirthday(raw, now)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, expected, birthday, raw)
+	}
+
+	_, err := parseBirthday("21/05/1990", now)
+	assert.Error(t, err)
+
+	_, err = parseBirthday("2030-01-01", now)
+	assert.Error(t, err)
+}
+
+func TestParseImport(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Mapping and normalization", func(t *testing.T) {
+		content := "\uFEFFVezetéknév;Keresztnév;Telefon;E-mail\n" +
+			"Kovács;János;06 30 123 4567;Janos@Example.com\n" +
+			";;;\n" +
+			"Nagy;;+36 20 765 4321;not-an-email\n"
+
+		options := domain.CustomerImportOptions{
+			Mapping: domain.CustomerImportMapping{
+				FirstName:   "keresztnév",
+				LastName:    "Vezetéknév",
+				PhoneNumber: "Telefon",
+				Email:       "E-mail",
+			},
+			DefaultCountryCode: "+36",
+		}
+
+		columns, rows, err := parseImport(content, options, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Vezetéknév", "Keresztnév", "Telefon", "E-mail"}, columns)
+
+		// the empty row is skipped
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, 2, rows[0].line)
+			assert.Empty(t, rows[0].errors)
+			assert.Equal(t, "János", *rows[0].customer.FirstName)
+			assert.Equal(t, "Kovács", *rows[0].customer.LastName)
+			assert.Equal(t, "+36301234567", *rows[0].customer.PhoneNumber)
+			assert.Equal(t, "janos@example.com", *rows[0].customer.Email)
+
+			assert.Equal(t, 4, rows[1].line)
+			if assert.Len(t, rows[1].errors, 2) {
+				assert.Equal(t, "first_name", *rows[1].errors[0].Field)
+				assert.Equal(t, "email", *rows[1].errors[1].Field)
+			}
+		}
+	})
+
+	t.Run("Columns named after the fields", func(t *testing.T) {
+		_, rows, err := parseImport("first_name,last_name,birthday\nJane,Doe,1990-05-21\n", domain.CustomerImportOptions{}, now)
+		assert.NoError(t, err)
+
+		if assert.Len(t, rows, 1) {
+			assert.Equal(t, time.Date(1990, 5, 21, 0, 0, 0, 0, time.UTC), *rows[0].customer.Birthday)
+		}
+	})
+
+	t.Run("File errors", func(t *testing.T) {
+		_, _, err := parseImport("", domain.CustomerImportOptions{}, now)
+		assert.Error(t, err)
+
+		_, _, err = parseImport("first_name,last_name\n", domain.CustomerImportOptions{}, now)
+		assert.Error(t, err, "no rows")
+
+		_, _, err = parseImport("name,email\nJane Doe,jane@example.com\n", domain.CustomerImportOptions{}, now)
+		assert.Error(t, err, "no name columns")
+
+		_, _, err = parseImport("first_name,last_name\nJane,Doe\n", domain.CustomerImportOptions{
+			Mapping: domain.CustomerImportMapping{Email: "Email"},
+		}, now)
+		assert.Error(t, err, "mapped column is missing")
+
+		_, _, err = parseImport("first_name,last_name\nJane,Doe\n", domain.CustomerImportOptions{DefaultCountryCode: "36"}, now)
+		assert.Error(t, err, "invalid country code")
+	})
+}
+
+func TestFindDuplicates(t *testing.T) {
+	existingId := uuid.New()
+	existingPhone := "06 30 123 4567"
+
+	content := "first_name,last_name,email,phone_number\n" +
+		"Jane,Doe,jane@example.com,\n" +
+		"John,Doe,john@example.com,+36301234567\n" +
+		"Jim,Doe,JANE@example.com,\n" +
+		"Joe,Doe,,\n"
+
+	_, rows, err := parseImport(content, domain.CustomerImportOptions{DefaultCountryCode: "+36"}, time.Now())
+	assert.NoError(t, err)
+
+	findDuplicates(rows, []domain.CustomerContact{{Id: existingId, PhoneNumber: &existingPhone}}, "+36")
+
+	assert.Nil(t, rows[0].duplicate)
+
+	if assert.NotNil(t, rows[1].duplicate) {
+		assert.Equal(t, existingId, *rows[1].duplicate.CustomerId)
+	}
+
+	if assert.NotNil(t, rows[2].duplicate) {
+		assert.Equal(t, 2, *rows[2].duplicate.Row)
+	}
+
+	// rows without contact details can not be matched
+	assert.Nil(t, rows[3].duplicate)
+}
@@ -0,0 +1,100 @@
+package customer
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Lowercases the email address and checks if it's valid
+func normalizeEmail(raw string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("invalid email address: %s", raw)
+	}
+
+	return email, nil
+}
+
+var (
+	e164Regexp        = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	countryCodeRegexp = regexp.MustCompile(`^\+[1-9][0-9]{0,3}$`)
+)
+
+// National prefixes of phone numbers which are replaced by the country code,
+// the ones of unlisted countries are a single 0
+var trunkPrefixes = map[string]string{
+	"+36": "06",
+	"+1":  "1",
+}
+
+// Converts the phone number to the E.164 format. Numbers without a country code
+// are treated as national numbers of the default country code
+func normalizePhoneNumber(raw string, defaultCountryCode string) (string, error) {
+	var digits strings.Builder
+
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && digits.Len() == 0:
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -./()", r):
+			continue
+		default:
+			return "", fmt.Errorf("invalid phone number: %s", raw)
+		}
+	}
+
+	number := digits.String()
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+
+	case strings.HasPrefix(number, "00"):
+		number = "+" + number[2:]
+
+	case defaultCountryCode != "":
+		trunkPrefix, ok := trunkPrefixes[defaultCountryCode]
+		if !ok {
+			trunkPrefix = "0"
+		}
+
+		number = defaultCountryCode + strings.TrimPrefix(number, trunkPrefix)
+
+	default:
+		return "", fmt.Errorf("phone number without a country code: %s", raw)
+	}
+
+	if !e164Regexp.MatchString(number) {
+		return "", fmt.Errorf("invalid phone number: %s", raw)
+	}
+
+	return number, nil
+}
+
+var birthdayLayouts = []string{"2006-01-02", "2006.01.02", "2006/01/02"}
+
+// Parses the date formats spreadsheets usually export, e.g. 1990-05-21 or 1990. 05. 21.
+func parseBirthday(raw string, now time.Time) (time.Time, error) {
+	value := strings.TrimSuffix(strings.ReplaceAll(strings.TrimSpace(raw), " ", ""), ".")
+
+	for _, layout := range birthdayLayouts {
+		birthday, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+
+		if birthday.After(now) {
+			return time.Time{}, fmt.Errorf("birthday is in the future: %s", raw)
+		}
+
+		return birthday, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid birthday, expected a date like 1990-05-21: %s", raw)
+}
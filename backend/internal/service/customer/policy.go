@@ -0,0 +1,107 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+const maxPolicyRules = 10
+
+type PolicyRuleInput struct {
+	Event      types.CustomerPolicyEvent
+	Threshold  int
+	PeriodDays int
+	Action     types.CustomerPolicyAction
+}
+
+// The rules are evaluated when a participant is marked as no-show or gets cancelled
+func (s *Service) NewPolicyRule(ctx context.Context, input PolicyRuleInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	rules, err := s.customerRepo.GetCustomerPolicyRules(ctx, actor.MerchantId)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rules) >= maxPolicyRules {
+		return 0, fmt.Errorf("a merchant can have at most %d policy rules", maxPolicyRules)
+	}
+
+	return s.customerRepo.NewCustomerPolicyRule(ctx, domain.CustomerPolicyRule{
+		MerchantId: actor.MerchantId,
+		Event:      input.Event,
+		Threshold:  input.Threshold,
+		PeriodDays: input.PeriodDays,
+		Action:     input.Action,
+	})
+}
+
+// Customers who were already restricted by the rule stay restricted
+func (s *Service) UpdatePolicyRule(ctx context.Context, ruleId int, input PolicyRuleInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	rules, err := s.customerRepo.GetCustomerPolicyRules(ctx, actor.MerchantId)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(rules, func(r domain.CustomerPolicyRule) bool { return r.Id == ruleId }) {
+		return fmt.Errorf("policy rule not found")
+	}
+
+	return s.customerRepo.UpdateCustomerPolicyRule(ctx, domain.CustomerPolicyRule{
+		Id:         ruleId,
+		MerchantId: actor.MerchantId,
+		Event:      input.Event,
+		Threshold:  input.Threshold,
+		PeriodDays: input.PeriodDays,
+		Action:     input.Action,
+	})
+}
+
+func (s *Service) DeletePolicyRule(ctx context.Context, ruleId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.DeleteCustomerPolicyRule(ctx, actor.MerchantId, ruleId)
+}
+
+func (s *Service) GetPolicyRules(ctx context.Context) ([]domain.CustomerPolicyRule, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCustomerPolicyRules(ctx, actor.MerchantId)
+}
+
+// Lets the bookings of the customer follow the approval policy again
+func (s *Service) RemoveApprovalRequirement(ctx context.Context, customerId uuid.UUID) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		before, err := s.customerRepo.WithTx(tx).GetCustomerApprovalStatus(ctx, actor.MerchantId, customerId)
+		if err != nil {
+			return err
+		}
+
+		after := domain.CustomerApprovalStatus{RequiresApproval: false, ApprovalReason: nil}
+
+		err = s.customerRepo.WithTx(tx).SetApprovalStatusForCustomer(ctx, actor.MerchantId, customerId, after.RequiresApproval, after.ApprovalReason)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "customer.approval_requirement_removed",
+			EntityType: types.AuditEntityCustomer,
+			EntityId:   customerId.String(),
+			Before:     before,
+			After:      after,
+		})
+	})
+}
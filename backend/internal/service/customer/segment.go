@@ -0,0 +1,211 @@
+package customer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+)
+
+const maxPageSize = 500
+
+type GetAllInput struct {
+	Filter domain.CustomerFilter
+	// The filter of the segment is combined with the filter of the input,
+	// the conditions set in the input take precedence
+	SegmentId  *int
+	SortBy     string
+	Descending bool
+	// Every matching customer is returned if nil
+	Limit  *int
+	Offset int
+}
+
+type GetAllResult struct {
+	Customers []domain.PublicCustomer
+	// Number of customers matching the filter on every page
+	Total int
+}
+
+// Blacklisted customers are only returned if they are asked for, as they have their own list
+func (s *Service) GetAll(ctx context.Context, input GetAllInput) (GetAllResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	if input.Limit != nil && (*input.Limit < 1 || *input.Limit > maxPageSize) {
+		return GetAllResult{}, fmt.Errorf("page size should be between 1 and %d", maxPageSize)
+	}
+
+	if input.Offset < 0 {
+		return GetAllResult{}, fmt.Errorf("offset can not be negative")
+	}
+
+	filter, err := s.resolveCustomerFilter(ctx, actor.MerchantId, input.Filter, input.SegmentId)
+	if err != nil {
+		return GetAllResult{}, err
+	}
+
+	customers, total, err := s.customerRepo.GetCustomers(ctx, actor.MerchantId, domain.CustomerQuery{
+		Filter:     filter,
+		SortBy:     input.SortBy,
+		Descending: input.Descending,
+		Limit:      input.Limit,
+		Offset:     input.Offset,
+	})
+	if err != nil {
+		return GetAllResult{}, err
+	}
+
+	return GetAllResult{
+		Customers: hidePublicCustomersPii(actor, customers),
+		Total:     total,
+	}, nil
+}
+
+// Combines the filter with the segment's filter and validates it, blacklisted customers are left out unless asked for
+func (s *Service) resolveCustomerFilter(ctx context.Context, merchantId uuid.UUID, filter domain.CustomerFilter, segmentId *int) (domain.CustomerFilter, error) {
+	if segmentId != nil {
+		segment, err := s.customerRepo.GetCustomerSegment(ctx, merchantId, *segmentId)
+		if err != nil {
+			return domain.CustomerFilter{}, err
+		}
+
+		filter = combineCustomerFilters(segment.Filter, filter)
+	}
+
+	filter, err := s.validateCustomerFilter(ctx, merchantId, filter)
+	if err != nil {
+		return domain.CustomerFilter{}, err
+	}
+
+	if filter.IsBlacklisted == nil {
+		notBlacklisted := false
+		filter.IsBlacklisted = &notBlacklisted
+	}
+
+	return filter, nil
+}
+
+// Returns the base filter with the conditions set in the override, the tags and custom fields of both are required
+func combineCustomerFilters(base, override domain.CustomerFilter) domain.CustomerFilter {
+	combined := base
+
+	if override.Search != nil {
+		combined.Search = override.Search
+	}
+
+	if override.IsBlacklisted != nil {
+		combined.IsBlacklisted = override.IsBlacklisted
+	}
+
+	if override.NoVisitInDays != nil {
+		combined.NoVisitInDays = override.NoVisitInDays
+	}
+
+	if override.MinNoShows != nil {
+		combined.MinNoShows = override.MinNoShows
+	}
+
+	if override.MinTimesBooked != nil {
+		combined.MinTimesBooked = override.MinTimesBooked
+	}
+
+	combined.BirthdayThisMonth = base.BirthdayThisMonth || override.BirthdayThisMonth
+	combined.Tags = append(append([]string{}, base.Tags...), override.Tags...)
+
+	if len(override.CustomFields) != 0 {
+		combined.CustomFields = domain.CustomFieldValues{}
+
+		for key, value := range base.CustomFields {
+			combined.CustomFields[key] = value
+		}
+
+		for key, value := range override.CustomFields {
+			combined.CustomFields[key] = value
+		}
+	}
+
+	return combined
+}
+
+type SegmentInput struct {
+	Name   string
+	Filter domain.CustomerFilter
+}
+
+func (s *Service) NewSegment(ctx context.Context, input SegmentInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	filter, err := s.validateCustomerFilter(ctx, actor.MerchantId, input.Filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.customerRepo.NewCustomerSegment(ctx, domain.CustomerSegment{
+		MerchantId: actor.MerchantId,
+		Name:       strings.TrimSpace(input.Name),
+		Filter:     filter,
+	})
+}
+
+func (s *Service) UpdateSegment(ctx context.Context, segmentId int, input SegmentInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	filter, err := s.validateCustomerFilter(ctx, actor.MerchantId, input.Filter)
+	if err != nil {
+		return err
+	}
+
+	return s.customerRepo.UpdateCustomerSegment(ctx, domain.CustomerSegment{
+		Id:         segmentId,
+		MerchantId: actor.MerchantId,
+		Name:       strings.TrimSpace(input.Name),
+		Filter:     filter,
+	})
+}
+
+func (s *Service) DeleteSegment(ctx context.Context, segmentId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.DeleteCustomerSegment(ctx, actor.MerchantId, segmentId)
+}
+
+func (s *Service) GetSegments(ctx context.Context) ([]domain.CustomerSegment, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.customerRepo.GetCustomerSegments(ctx, actor.MerchantId)
+}
+
+func (s *Service) validateCustomerFilter(ctx context.Context, merchantId uuid.UUID, filter domain.CustomerFilter) (domain.CustomerFilter, error) {
+	for _, value := range []*int{filter.NoVisitInDays, filter.MinNoShows, filter.MinTimesBooked} {
+		if value != nil && *value < 0 {
+			return domain.CustomerFilter{}, fmt.Errorf("filter values can not be negative")
+		}
+	}
+
+	if filter.Search != nil && strings.TrimSpace(*filter.Search) == "" {
+		filter.Search = nil
+	}
+
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return domain.CustomerFilter{}, err
+	}
+	filter.Tags = tags
+
+	if len(filter.CustomFields) != 0 {
+		fields, err := s.customerRepo.GetCustomerFields(ctx, merchantId)
+		if err != nil {
+			return domain.CustomerFilter{}, err
+		}
+
+		filter.CustomFields, err = validateCustomFields(fields, filter.CustomFields, false)
+		if err != nil {
+			return domain.CustomerFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
@@ -102,18 +102,47 @@ func (s *Service) getSubject(templateName string, lang language.Tag) string {
 }
 
 func (s *Service) send(ctx context.Context, to string, body string, subjectText string) error {
-	if !s.enabled {
-		return nil
-	}
+	return s.sendWithHeaders(ctx, to, body, subjectText, nil)
+}
 
-	//todo: sending from our own domain, replace resend test email with address parameter of the function
+func (s *Service) sendWithHeaders(ctx context.Context, to string, body string, subjectText string, headers map[string]string) error {
+	return s.sendRequest(ctx, to, &resend.SendEmailRequest{
+		Html:    body,
+		Subject: subjectText,
+		Headers: headers,
+	})
+}
+
+type Attachment struct {
+	FileName string
+	Content  []byte
+}
+
+func (s *Service) sendWithAttachments(ctx context.Context, to string, body string, subjectText string, attachments []Attachment) error {
 	params := &resend.SendEmailRequest{
-		From:    "Acme <onboarding@resend.dev>",
-		To:      []string{"delivered@resend.dev"},
 		Html:    body,
 		Subject: subjectText,
 	}
 
+	for _, attachment := range attachments {
+		params.Attachments = append(params.Attachments, &resend.Attachment{
+			Filename: attachment.FileName,
+			Content:  attachment.Content,
+		})
+	}
+
+	return s.sendRequest(ctx, to, params)
+}
+
+func (s *Service) sendRequest(ctx context.Context, to string, params *resend.SendEmailRequest) error {
+	if !s.enabled {
+		return nil
+	}
+
+	//todo: sending from our own domain, replace resend test email with address parameter of the function
+	params.From = "Acme <onboarding@resend.dev>"
+	params.To = []string{"delivered@resend.dev"}
+
 	_, err := s.client.Emails.SendWithContext(ctx, params)
 	if err != nil {
 		return err
@@ -240,3 +269,109 @@ func (s *Service) BookingModification(ctx context.Context, lang language.Tag, to
 
 	return nil
 }
+
+type CampaignData struct {
+	MerchantName string `json:"merchant_name"`
+	FirstName    string `json:"first_name"`
+	// the text written by the merchant split into paragraphs
+	Paragraphs []string `json:"paragraphs"`
+	// page where the customer can unsubscribe
+	UnsubscribeLink string `json:"unsubscribe_link"`
+	// endpoint which unsubscribes the customer on a POST request without any confirmation
+	OneClickUnsubscribeLink string `json:"one_click_unsubscribe_link"`
+}
+
+// Marketing email of a merchant, it can only be sent with working unsubscribe links.
+// The one-click link is offered to the email clients in the headers (RFC 8058)
+func (s *Service) Campaign(ctx context.Context, lang language.Tag, to string, subject string, data CampaignData) error {
+	if data.UnsubscribeLink == "" || data.OneClickUnsubscribeLink == "" {
+		return fmt.Errorf("marketing emails can not be sent without unsubscribe links")
+	}
+
+	templateName := "Campaign"
+	body := s.executeTemplate(templateName, lang, data)
+
+	err := s.sendWithHeaders(ctx, to, body, subject, map[string]string{
+		"List-Unsubscribe":      fmt.Sprintf("<%s>", data.OneClickUnsubscribeLink),
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type ReportSummaryMetric struct {
+	// key of the translated name of the metric
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Change string `json:"change"`
+}
+
+type ReportSummaryData struct {
+	MerchantName   string                `json:"merchant_name"`
+	Period         string                `json:"period"`
+	PreviousPeriod string                `json:"previous_period"`
+	Metrics        []ReportSummaryMetric `json:"metrics"`
+	ReportLink     string                `json:"report_link"`
+}
+
+func (s *Service) ReportSummary(ctx context.Context, lang language.Tag, to string, data ReportSummaryData) error {
+	templateName := "ReportSummary"
+	subject := s.getSubject(templateName, lang)
+	body := s.executeTemplate(templateName, lang, data)
+
+	err := s.send(ctx, to, body, subject)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type InvoiceData struct {
+	MerchantName  string `json:"merchant_name"`
+	InvoiceNumber string `json:"invoice_number"`
+	Date          string `json:"date"`
+	Total         string `json:"total"`
+}
+
+func (s *Service) Invoice(ctx context.Context, lang language.Tag, to string, data InvoiceData, attachment Attachment) error {
+	templateName := "Invoice"
+	subject := s.getSubject(templateName, lang)
+	body := s.executeTemplate(templateName, lang, data)
+
+	err := s.sendWithAttachments(ctx, to, body, subject, []Attachment{attachment})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type GiftCardData struct {
+	MerchantName  string `json:"merchant_name"`
+	RecipientName string `json:"recipient_name"`
+	// empty if the card was sold by the merchant
+	SenderName string `json:"sender_name"`
+	Message    string `json:"message"`
+	Code       string `json:"code"`
+	Value      string `json:"value"`
+	// empty if the card never expires
+	ExpiresAt   string `json:"expires_at"`
+	BookingLink string `json:"booking_link"`
+}
+
+func (s *Service) GiftCard(ctx context.Context, lang language.Tag, to string, data GiftCardData) error {
+	templateName := "GiftCard"
+	subject := s.getSubject(templateName, lang)
+	body := s.executeTemplate(templateName, lang, data)
+
+	err := s.send(ctx, to, body, subject)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
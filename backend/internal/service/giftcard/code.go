@@ -0,0 +1,59 @@
+package giftcard
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// Letters and digits which can not be mistaken for each other when read out loud or from paper
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	codeLength    = 16
+	codeGroupSize = 4
+)
+
+// Creates a new random code like ABCD-EFGH-JKLM-NPQR. The codes are random enough
+// that guessing a valid one is not feasible
+func NewCode() (string, error) {
+	bytes := make([]byte, codeLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("unexpected error during creating gift card code: %s", err.Error())
+	}
+
+	chars := make([]byte, codeLength)
+	for i, b := range bytes {
+		// the alphabet has 32 characters so every one of them is equally likely
+		chars[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+
+	return groupCode(string(chars)), nil
+}
+
+// Brings a code typed in by someone to the form it is stored in, ignoring the case, spaces and dashes
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+
+	if len(code) != codeLength {
+		return "", fmt.Errorf("invalid gift card code")
+	}
+
+	for _, c := range code {
+		if !strings.ContainsRune(codeAlphabet, c) {
+			return "", fmt.Errorf("invalid gift card code")
+		}
+	}
+
+	return groupCode(code), nil
+}
+
+func groupCode(code string) string {
+	groups := make([]string, 0, len(code)/codeGroupSize)
+	for i := 0; i < len(code); i += codeGroupSize {
+		groups = append(groups, code[i:i+codeGroupSize])
+	}
+
+	return strings.Join(groups, "-")
+}
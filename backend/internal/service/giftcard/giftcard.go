@@ -0,0 +1,343 @@
+package giftcard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
+)
+
+type Service struct {
+	giftCardRepo domain.GiftCardRepository
+	merchantRepo domain.MerchantRepository
+	mailer       *email.Service
+	enqueuer     queue.Enqueuer
+	txManager    db.TransactionManager
+}
+
+func NewService(giftCard domain.GiftCardRepository, merchant domain.MerchantRepository, mailer *email.Service,
+	enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	return &Service{
+		giftCardRepo: giftCard,
+		merchantRepo: merchant,
+		mailer:       mailer,
+		enqueuer:     enqueuer,
+		txManager:    txManager,
+	}
+}
+
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
+// The value has to be in the currency of the merchant, without more decimals than the currency has
+func validateValue(value currencyx.Price, merchantCurrency string) error {
+	if value.CurrencyCode() != merchantCurrency {
+		return fmt.Errorf("the value of the gift card has to be in the currency of the merchant")
+	}
+
+	if !value.IsPositive() {
+		return fmt.Errorf("the value of the gift card has to be positive")
+	}
+
+	rounded, err := currencyx.Round(value.Amount)
+	if err != nil {
+		return err
+	}
+
+	if !rounded.Equal(value.Amount) {
+		return fmt.Errorf("the value of the gift card has too many decimals for its currency")
+	}
+
+	return nil
+}
+
+// Inserts the card with a new code and sends it to the recipient, if they have an email
+func (s *Service) newGiftCard(ctx context.Context, giftCard domain.NewGiftCard) (domain.GiftCard, error) {
+	code, err := NewCode()
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+
+	giftCard.Code = code
+
+	var created domain.GiftCard
+
+	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		giftCardId, err := s.giftCardRepo.WithTx(tx).NewGiftCard(ctx, giftCard)
+		if err != nil {
+			return err
+		}
+
+		created, err = s.giftCardRepo.WithTx(tx).GetGiftCard(ctx, giftCard.MerchantId, giftCardId)
+		if err != nil {
+			return err
+		}
+
+		if giftCard.RecipientEmail == nil {
+			return nil
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.GiftCardEmail{
+			GiftCardId: giftCardId,
+		}, nil)
+		return err
+	})
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("error creating gift card: %s", err.Error())
+	}
+
+	return created, nil
+}
+
+type IssueInput struct {
+	Value          currencyx.Price
+	ExpiresAt      *time.Time
+	RecipientName  *string
+	RecipientEmail *string
+	Message        *string
+}
+
+// A gift card sold over the counter
+func (s *Service) Issue(ctx context.Context, input IssueInput) (domain.GiftCard, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	if !employee.HasPermission(types.PermissionGiftCardsManage) {
+		return domain.GiftCard{}, fmt.Errorf("you do not have permission to issue gift cards")
+	}
+
+	merchantCurrency, err := s.merchantRepo.GetMerchantCurrency(ctx, employee.MerchantId)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+
+	err = validateValue(input.Value, merchantCurrency)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return domain.GiftCard{}, fmt.Errorf("the expiry of the gift card has to be in the future")
+	}
+
+	return s.newGiftCard(ctx, domain.NewGiftCard{
+		MerchantId:     employee.MerchantId,
+		InitialValue:   input.Value,
+		ExpiresAt:      input.ExpiresAt,
+		IssuedBy:       &employee.EmployeeId,
+		RecipientName:  input.RecipientName,
+		RecipientEmail: input.RecipientEmail,
+		Message:        input.Message,
+	})
+}
+
+// The balance of a gift card of the merchant looked up by its code
+func (s *Service) GetBalance(ctx context.Context, merchantName string, code string) (domain.GiftCard, error) {
+	merchantId, err := s.merchantRepo.GetMerchantIdByUrlName(ctx, merchantName)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+
+	code, err = NormalizeCode(code)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+
+	giftCard, err := s.giftCardRepo.GetGiftCardByCode(ctx, merchantId, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.GiftCard{}, fmt.Errorf("gift card not found")
+		}
+		return domain.GiftCard{}, err
+	}
+
+	return giftCard, nil
+}
+
+// The codes can be redeemed by anyone who knows them, so only the employees who can manage gift cards see them
+func (s *Service) GetGiftCards(ctx context.Context) ([]domain.GiftCard, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	giftCards, err := s.giftCardRepo.GetGiftCards(ctx, employee.MerchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !employee.HasPermission(types.PermissionGiftCardsManage) {
+		for i := range giftCards {
+			giftCards[i] = giftCards[i].WithoutCode()
+		}
+	}
+
+	return giftCards, nil
+}
+
+type GiftCardWithTransactions struct {
+	domain.GiftCard
+	Transactions []domain.GiftCardTransaction
+}
+
+func (s *Service) GetGiftCard(ctx context.Context, giftCardId int) (GiftCardWithTransactions, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	giftCard, err := s.giftCardRepo.GetGiftCard(ctx, employee.MerchantId, giftCardId)
+	if err != nil {
+		return GiftCardWithTransactions{}, err
+	}
+
+	transactions, err := s.giftCardRepo.GetGiftCardTransactions(ctx, giftCardId)
+	if err != nil {
+		return GiftCardWithTransactions{}, err
+	}
+
+	if !employee.HasPermission(types.PermissionGiftCardsManage) {
+		giftCard = giftCard.WithoutCode()
+	}
+
+	return GiftCardWithTransactions{GiftCard: giftCard, Transactions: transactions}, nil
+}
+
+type RedeemInput struct {
+	Code      string
+	BookingId int
+	// nil to redeem as much as the balance and the booking allow
+	Amount *currencyx.Price
+}
+
+// Employees without the bookings.manage_all permission can only redeem for the bookings assigned to them
+func canRedeemForBooking(employee actor.EmployeeContext, employeeId *int) error {
+	if employee.HasPermission(types.PermissionBookingsManageAll) {
+		return nil
+	}
+
+	if employee.HasPermission(types.PermissionBookingsManageOwn) && employeeId != nil && *employeeId == employee.EmployeeId {
+		return nil
+	}
+
+	return fmt.Errorf("you can only redeem gift cards for your own bookings")
+}
+
+// Redeems the gift card for a booking at the counter. Returns the redeemed amount
+func (s *Service) Redeem(ctx context.Context, input RedeemInput) (currencyx.Price, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	code, err := NormalizeCode(input.Code)
+	if err != nil {
+		return currencyx.Price{}, err
+	}
+
+	if input.Amount != nil {
+		rounded, err := currencyx.Round(input.Amount.Amount)
+		if err != nil {
+			return currencyx.Price{}, err
+		}
+
+		if !rounded.Equal(input.Amount.Amount) {
+			return currencyx.Price{}, fmt.Errorf("the amount has too many decimals for its currency")
+		}
+	}
+
+	var redeemed currencyx.Price
+
+	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		// the booking is always locked before the card, so concurrent redemptions can not deadlock
+		booking, err := s.giftCardRepo.WithTx(tx).GetGiftCardBookingForUpdate(ctx, employee.MerchantId, input.BookingId)
+		if err != nil {
+			return err
+		}
+
+		err = canRedeemForBooking(employee, booking.EmployeeId)
+		if err != nil {
+			return err
+		}
+
+		due, err := BookingDue(booking)
+		if err != nil {
+			return err
+		}
+
+		giftCard, err := s.giftCardRepo.WithTx(tx).GetGiftCardByCodeForUpdate(ctx, employee.MerchantId, code)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("gift card not found")
+			}
+			return err
+		}
+
+		redeemed, err = RedemptionAmount(giftCard, due, input.Amount, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return s.giftCardRepo.WithTx(tx).RedeemGiftCard(ctx, domain.GiftCardRedemption{
+			GiftCardId: giftCard.Id,
+			Amount:     redeemed,
+			BookingId:  booking.Id,
+			EmployeeId: &employee.EmployeeId,
+		})
+	})
+	if err != nil {
+		return currencyx.Price{}, fmt.Errorf("error redeeming gift card: %s", err.Error())
+	}
+
+	return redeemed, nil
+}
+
+func (s *Service) SendGiftCard(ctx context.Context, giftCardId int) error {
+	giftCard, err := s.giftCardRepo.GetGiftCardForEmail(ctx, giftCardId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if giftCard.RecipientEmail == nil {
+		return nil
+	}
+
+	userLang := lang.GetDefaultLang()
+
+	value, err := currencyx.Format(giftCard.InitialValue.Amount, userLang)
+	if err != nil {
+		return err
+	}
+
+	data := email.GiftCardData{
+		MerchantName: giftCard.MerchantName,
+		Code:         giftCard.Code,
+		Value:        value,
+		BookingLink:  fmt.Sprintf("http://reservations.local:3000/m/%s", giftCard.MerchantUrlName),
+	}
+
+	if giftCard.RecipientName != nil {
+		data.RecipientName = *giftCard.RecipientName
+	}
+
+	if giftCard.Message != nil {
+		data.Message = strings.TrimSpace(*giftCard.Message)
+	}
+
+	if giftCard.ExpiresAt != nil {
+		merchantTz, err := time.LoadLocation(giftCard.Timezone)
+		if err != nil {
+			merchantTz = time.UTC
+		}
+
+		data.ExpiresAt = giftCard.ExpiresAt.In(merchantTz).Format(time.DateOnly)
+	}
+
+	return s.mailer.GiftCard(ctx, userLang, *giftCard.RecipientEmail, data)
+}
@@ -0,0 +1,136 @@
+package giftcard
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCode(t *testing.T) {
+	code, err := NewCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 19)
+	assert.Equal(t, 3, strings.Count(code, "-"))
+
+	normalized, err := NormalizeCode(code)
+	assert.NoError(t, err)
+	assert.Equal(t, code, normalized)
+
+	other, err := NewCode()
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{"already normalized", "ABCD-EFGH-JKLM-NPQR", "ABCD-EFGH-JKLM-NPQR"},
+		{"lowercase without dashes", "abcdefghjklmnpqr", "ABCD-EFGH-JKLM-NPQR"},
+		{"spaces", " abcd efgh jklm npqr ", "ABCD-EFGH-JKLM-NPQR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := NormalizeCode(tt.code)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+
+	t.Run("invalid codes", func(t *testing.T) {
+		for _, code := range []string{"", "ABCD-EFGH-JKLM", "ABCD-EFGH-JKLM-NPQRS", "ABCD-EFGH-JKLM-NPQ0", "ABCD-EFGH-JKLM-NPQ!"} {
+			_, err := NormalizeCode(code)
+			assert.Error(t, err, code)
+		}
+	})
+}
+
+func TestValidateValue(t *testing.T) {
+	assert.NoError(t, validateValue(currencyxtest.Price(t, "10000", "HUF"), "HUF"))
+	assert.NoError(t, validateValue(currencyxtest.Price(t, "25.50", "EUR"), "EUR"))
+
+	assert.Error(t, validateValue(currencyxtest.Price(t, "25", "EUR"), "HUF"))
+	assert.Error(t, validateValue(currencyxtest.Price(t, "0", "HUF"), "HUF"))
+	assert.Error(t, validateValue(currencyxtest.Price(t, "-100", "HUF"), "HUF"))
+	assert.Error(t, validateValue(currencyxtest.Price(t, "100.5", "HUF"), "HUF"))
+	assert.Error(t, validateValue(currencyxtest.Price(t, "25.555", "EUR"), "EUR"))
+}
+
+func TestBookingDue(t *testing.T) {
+	booking := domain.GiftCardBooking{
+		Status:     types.BookingStatusConfirmed,
+		TotalPrice: currencyxtest.Price(t, "10000", "HUF"),
+		Redeemed:   currencyxtest.Price(t, "4000", "HUF"),
+	}
+
+	due, err := BookingDue(booking)
+	assert.NoError(t, err)
+	assert.Equal(t, "6000", due.Number())
+
+	booking.Redeemed = currencyxtest.Price(t, "10000", "HUF")
+	_, err = BookingDue(booking)
+	assert.Error(t, err)
+
+	booking.Redeemed = currencyxtest.Price(t, "0", "HUF")
+	booking.Status = types.BookingStatusCancelled
+	_, err = BookingDue(booking)
+	assert.Error(t, err)
+}
+
+func TestRedemptionAmount(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.AddDate(0, 1, 0)
+
+	card := domain.GiftCard{Balance: currencyxtest.Price(t, "5000", "HUF"), ExpiresAt: &expiresAt}
+
+	t.Run("balance is less than the due price", func(t *testing.T) {
+		amount, err := RedemptionAmount(card, currencyxtest.Price(t, "8000", "HUF"), nil, now)
+		assert.NoError(t, err)
+		assert.Equal(t, "5000", amount.Number())
+	})
+
+	t.Run("balance is more than the due price", func(t *testing.T) {
+		amount, err := RedemptionAmount(card, currencyxtest.Price(t, "3000", "HUF"), nil, now)
+		assert.NoError(t, err)
+		assert.Equal(t, "3000", amount.Number())
+	})
+
+	t.Run("requested amount", func(t *testing.T) {
+		requested := currencyxtest.Price(t, "1500", "HUF")
+		amount, err := RedemptionAmount(card, currencyxtest.Price(t, "8000", "HUF"), &requested, now)
+		assert.NoError(t, err)
+		assert.Equal(t, "1500", amount.Number())
+
+		requested = currencyxtest.Price(t, "6000", "HUF")
+		_, err = RedemptionAmount(card, currencyxtest.Price(t, "8000", "HUF"), &requested, now)
+		assert.Error(t, err)
+
+		requested = currencyxtest.Price(t, "0", "HUF")
+		_, err = RedemptionAmount(card, currencyxtest.Price(t, "8000", "HUF"), &requested, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("expired card", func(t *testing.T) {
+		_, err := RedemptionAmount(card, currencyxtest.Price(t, "8000", "HUF"), nil, expiresAt)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty card", func(t *testing.T) {
+		empty := domain.GiftCard{Balance: currencyxtest.Price(t, "0", "HUF")}
+		_, err := RedemptionAmount(empty, currencyxtest.Price(t, "8000", "HUF"), nil, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("different currency", func(t *testing.T) {
+		_, err := RedemptionAmount(card, currencyxtest.Price(t, "20", "EUR"), nil, now)
+		assert.Error(t, err)
+	})
+}
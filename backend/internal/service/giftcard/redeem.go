@@ -0,0 +1,77 @@
+package giftcard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// The part of the total price of the booking which is not paid with gift cards yet
+func BookingDue(booking domain.GiftCardBooking) (currencyx.Price, error) {
+	if booking.Status == types.BookingStatusCancelled {
+		return currencyx.Price{}, fmt.Errorf("a gift card can not be redeemed for a cancelled booking")
+	}
+
+	due, err := booking.TotalPrice.Sub(booking.Redeemed.Amount)
+	if err != nil {
+		return currencyx.Price{}, err
+	}
+
+	if !due.IsPositive() {
+		return currencyx.Price{}, fmt.Errorf("the booking is already paid with gift cards")
+	}
+
+	return currencyx.Price{Amount: due}, nil
+}
+
+// The amount the card pays from the due price. Without a requested amount as much is
+// redeemed as the balance allows, the rest has to be paid some other way
+func RedemptionAmount(card domain.GiftCard, due currencyx.Price, requested *currencyx.Price, now time.Time) (currencyx.Price, error) {
+	if card.IsExpired(now) {
+		return currencyx.Price{}, fmt.Errorf("the gift card has expired")
+	}
+
+	if card.Balance.CurrencyCode() != due.CurrencyCode() {
+		return currencyx.Price{}, fmt.Errorf("the currency of the gift card does not match the currency of the booking")
+	}
+
+	if !card.Balance.IsPositive() {
+		return currencyx.Price{}, fmt.Errorf("the gift card has no balance left")
+	}
+
+	cmp, err := card.Balance.Cmp(due.Amount)
+	if err != nil {
+		return currencyx.Price{}, err
+	}
+
+	amount := card.Balance
+	if cmp > 0 {
+		amount = due
+	}
+
+	if requested == nil {
+		return amount, nil
+	}
+
+	if requested.CurrencyCode() != due.CurrencyCode() {
+		return currencyx.Price{}, fmt.Errorf("the currency of the amount does not match the currency of the booking")
+	}
+
+	if !requested.IsPositive() {
+		return currencyx.Price{}, fmt.Errorf("the amount to redeem has to be positive")
+	}
+
+	cmp, err = requested.Cmp(amount.Amount)
+	if err != nil {
+		return currencyx.Price{}, err
+	}
+
+	if cmp > 0 {
+		return currencyx.Price{}, fmt.Errorf("at most %s can be redeemed from the gift card", amount.Number())
+	}
+
+	return *requested, nil
+}
@@ -0,0 +1,271 @@
+package intake
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+const (
+	maxTextAnswerLength     = 500
+	maxLongTextAnswerLength = 5000
+	answerDateLayout        = "2006-01-02"
+)
+
+type ResponseInput struct {
+	Answers domain.IntakeAnswers
+	// The full name of the customer, required if they agreed to a consent question
+	Signature string
+}
+
+// Validates the answers to the form and returns the response to store.
+// Answers to hidden questions are dropped, the form is copied so later changes of it do not affect the response
+func NewResponse(form domain.IntakeForm, input ResponseInput, now time.Time) (domain.IntakeResponse, error) {
+	answers, err := validateAnswers(form.Questions, input.Answers)
+	if err != nil {
+		return domain.IntakeResponse{}, err
+	}
+
+	response := domain.IntakeResponse{
+		MerchantId: form.MerchantId,
+		FormId:     &form.Id,
+		FormName:   form.Name,
+		Questions:  form.Questions,
+		Answers:    answers,
+	}
+
+	if !hasConsent(form.Questions, answers) {
+		return response, nil
+	}
+
+	signature := strings.Join(strings.Fields(input.Signature), " ")
+	if err := validate.Var(signature, "required,max=100"); err != nil {
+		return domain.IntakeResponse{}, fmt.Errorf("'signature': %s", err.Error())
+	}
+
+	signedAt := now.UTC()
+	response.Signature = &signature
+	response.SignedAt = &signedAt
+
+	return response, nil
+}
+
+func validateAnswers(questions []domain.IntakeQuestion, answers domain.IntakeAnswers) (domain.IntakeAnswers, error) {
+	for id := range answers {
+		if !slices.ContainsFunc(questions, func(q domain.IntakeQuestion) bool { return q.Id == id }) {
+			return nil, fmt.Errorf("unknown question: %s", id)
+		}
+	}
+
+	validated := domain.IntakeAnswers{}
+
+	// conditions only refer to earlier questions, so their answers are already validated
+	for _, question := range questions {
+		if !isVisible(question, validated) {
+			continue
+		}
+
+		answer, err := parseAnswer(question, answers[question.Id])
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %s", question.Label, err.Error())
+		}
+
+		if answer == nil {
+			if question.IsRequired {
+				if question.Type == types.IntakeQuestionConsent {
+					return nil, fmt.Errorf("'%s': You have to agree to continue", question.Label)
+				}
+
+				return nil, fmt.Errorf("'%s': This field is required", question.Label)
+			}
+
+			continue
+		}
+
+		validated[question.Id] = answer
+	}
+
+	return validated, nil
+}
+
+func isVisible(question domain.IntakeQuestion, answers domain.IntakeAnswers) bool {
+	if question.VisibleIf == nil {
+		return true
+	}
+
+	answer, ok := answers[question.VisibleIf.QuestionId]
+	if !ok {
+		return false
+	}
+
+	for _, value := range answerValues(answer) {
+		if slices.Contains(question.VisibleIf.Values, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The values of a validated answer the conditions are compared to
+func answerValues(answer any) []string {
+	switch v := answer.(type) {
+	case bool:
+		if v {
+			return []string{"yes"}
+		}
+		return []string{"no"}
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return []string{}
+	}
+}
+
+func hasConsent(questions []domain.IntakeQuestion, answers domain.IntakeAnswers) bool {
+	for _, question := range questions {
+		if question.Type == types.IntakeQuestionConsent && answers[question.Id] == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Converts the answer to the type of the question, returns nil for empty answers
+func parseAnswer(question domain.IntakeQuestion, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch question.Type {
+	case types.IntakeQuestionText, types.IntakeQuestionLongText:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("Must be a text")
+		}
+
+		str = strings.TrimSpace(str)
+		if str == "" {
+			return nil, nil
+		}
+
+		maxLength := maxTextAnswerLength
+		if question.Type == types.IntakeQuestionLongText {
+			maxLength = maxLongTextAnswerLength
+		}
+
+		if err := validate.Var(str, fmt.Sprintf("max=%d", maxLength)); err != nil {
+			return nil, err
+		}
+
+		return str, nil
+
+	case types.IntakeQuestionNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return nil, nil
+			}
+
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("Must be a number")
+			}
+
+			return number, nil
+		default:
+			return nil, fmt.Errorf("Must be a number")
+		}
+
+	case types.IntakeQuestionDate:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("Must be a date like %s", answerDateLayout)
+		}
+
+		if str == "" {
+			return nil, nil
+		}
+
+		if err := validate.Var(str, "datetime="+answerDateLayout); err != nil {
+			return nil, err
+		}
+
+		return str, nil
+
+	case types.IntakeQuestionYesNo:
+		answer, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Must be yes or no")
+		}
+
+		return answer, nil
+
+	case types.IntakeQuestionConsent:
+		agreed, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("Must be agreed to or left empty")
+		}
+
+		// not agreeing is the same as not answering
+		if !agreed {
+			return nil, nil
+		}
+
+		return true, nil
+
+	case types.IntakeQuestionSingleChoice:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("Must be one of: %s", strings.Join(question.Options, ", "))
+		}
+
+		if str == "" {
+			return nil, nil
+		}
+
+		if !slices.Contains(question.Options, str) {
+			return nil, fmt.Errorf("Must be one of: %s", strings.Join(question.Options, ", "))
+		}
+
+		return str, nil
+
+	case types.IntakeQuestionMultipleChoice:
+		values, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("Must be a list of choices")
+		}
+
+		choices := []string{}
+		for _, v := range values {
+			choice, ok := v.(string)
+			if !ok || !slices.Contains(question.Options, choice) {
+				return nil, fmt.Errorf("Must only contain: %s", strings.Join(question.Options, ", "))
+			}
+
+			if !slices.Contains(choices, choice) {
+				choices = append(choices, choice)
+			}
+		}
+
+		if len(choices) == 0 {
+			return nil, nil
+		}
+
+		return choices, nil
+
+	default:
+		return nil, fmt.Errorf("unknown question type: %s", question.Type.String())
+	}
+}
@@ -0,0 +1,248 @@
+package intake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/validate"
+)
+
+const (
+	maxForms     = 50
+	maxQuestions = 50
+	maxOptions   = 50
+)
+
+type Service struct {
+	intakeRepo domain.IntakeRepository
+}
+
+func NewService(intake domain.IntakeRepository) *Service {
+	return &Service{
+		intakeRepo: intake,
+	}
+}
+
+type FormInput struct {
+	Name      string
+	Mode      types.IntakeFormMode
+	Questions []domain.IntakeQuestion
+	// The services the form is asked for, they are detached from their previous form
+	ServiceIds []int
+}
+
+func (s *Service) NewForm(ctx context.Context, input FormInput) (int, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	forms, err := s.intakeRepo.GetIntakeForms(ctx, employee.MerchantId)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(forms) >= maxForms {
+		return 0, fmt.Errorf("a merchant can have at most %d intake forms", maxForms)
+	}
+
+	questions, err := normalizeQuestions(input.Questions)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.intakeRepo.NewIntakeForm(ctx, domain.IntakeForm{
+		MerchantId: employee.MerchantId,
+		Name:       strings.TrimSpace(input.Name),
+		Mode:       input.Mode,
+		Questions:  questions,
+		ServiceIds: input.ServiceIds,
+	})
+}
+
+// Responses given before keep the questions they were answered to
+func (s *Service) UpdateForm(ctx context.Context, formId int, input FormInput) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	form, err := s.intakeRepo.GetIntakeForm(ctx, employee.MerchantId, formId)
+	if err != nil {
+		return fmt.Errorf("intake form not found: %w", err)
+	}
+
+	questions, err := normalizeQuestions(input.Questions)
+	if err != nil {
+		return err
+	}
+
+	form.Name = strings.TrimSpace(input.Name)
+	form.Mode = input.Mode
+	form.Questions = questions
+	form.ServiceIds = input.ServiceIds
+
+	return s.intakeRepo.UpdateIntakeForm(ctx, form)
+}
+
+func (s *Service) DeleteForm(ctx context.Context, formId int) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.intakeRepo.DeleteIntakeForm(ctx, employee.MerchantId, formId)
+}
+
+func (s *Service) GetForm(ctx context.Context, formId int) (domain.IntakeForm, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.intakeRepo.GetIntakeForm(ctx, employee.MerchantId, formId)
+}
+
+func (s *Service) GetForms(ctx context.Context) ([]domain.IntakeForm, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.intakeRepo.GetIntakeForms(ctx, employee.MerchantId)
+}
+
+// Answers can hold health details, so they are visible to the same employees as the visit notes
+func canViewResponse(employee actor.EmployeeContext, employeeId *int) bool {
+	if employee.HasPermission(types.PermissionCustomersViewNotes) || employee.HasPermission(types.PermissionBookingsManageAll) {
+		return true
+	}
+
+	return employee.HasPermission(types.PermissionBookingsManageOwn) && employeeId != nil && *employeeId == employee.EmployeeId
+}
+
+// Responses of the customer to the forms of the bookings the employee can see, the latest booking first
+func (s *Service) GetCustomerResponses(ctx context.Context, customerId uuid.UUID) ([]domain.CustomerIntakeResponse, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	responses, err := s.intakeRepo.GetCustomerIntakeResponses(ctx, employee.MerchantId, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(responses, func(r domain.CustomerIntakeResponse) bool {
+		return !canViewResponse(employee, r.EmployeeId)
+	}), nil
+}
+
+// Trims the questions and checks that their options and conditions are consistent
+func normalizeQuestions(questions []domain.IntakeQuestion) ([]domain.IntakeQuestion, error) {
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("a form needs at least one question")
+	}
+
+	if len(questions) > maxQuestions {
+		return nil, fmt.Errorf("a form can have at most %d questions", maxQuestions)
+	}
+
+	normalized := make([]domain.IntakeQuestion, 0, len(questions))
+
+	for _, question := range questions {
+		question.Id = strings.TrimSpace(question.Id)
+		question.Label = strings.TrimSpace(question.Label)
+
+		if question.Id == "" || question.Label == "" {
+			return nil, fmt.Errorf("every question needs an id and a label")
+		}
+
+		if err := validate.Var(question.Id, "max=36"); err != nil {
+			return nil, fmt.Errorf("'id' of %s: %s", question.Label, err.Error())
+		}
+
+		if err := validate.Var(question.Label, "max=2000"); err != nil {
+			return nil, fmt.Errorf("'label' of %s: %s", question.Id, err.Error())
+		}
+
+		if question.Type == (types.IntakeQuestionType{}) {
+			return nil, fmt.Errorf("%s: every question needs a type", question.Label)
+		}
+
+		if slices.ContainsFunc(normalized, func(q domain.IntakeQuestion) bool { return q.Id == question.Id }) {
+			return nil, fmt.Errorf("the id of the questions has to be unique: %s", question.Id)
+		}
+
+		options, err := normalizeOptions(question)
+		if err != nil {
+			return nil, err
+		}
+		question.Options = options
+
+		if question.VisibleIf != nil {
+			if err := validateCondition(*question.VisibleIf, normalized); err != nil {
+				return nil, fmt.Errorf("%s: %s", question.Label, err.Error())
+			}
+		}
+
+		normalized = append(normalized, question)
+	}
+
+	return normalized, nil
+}
+
+func isChoice(questionType types.IntakeQuestionType) bool {
+	return questionType == types.IntakeQuestionSingleChoice || questionType == types.IntakeQuestionMultipleChoice
+}
+
+func normalizeOptions(question domain.IntakeQuestion) ([]string, error) {
+	if !isChoice(question.Type) {
+		if len(question.Options) != 0 {
+			return nil, fmt.Errorf("%s: only choice questions can have options", question.Label)
+		}
+
+		return []string{}, nil
+	}
+
+	options := []string{}
+	for _, option := range question.Options {
+		option = strings.TrimSpace(option)
+
+		if option == "" || slices.Contains(options, option) {
+			continue
+		}
+
+		options = append(options, option)
+	}
+
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%s: choice questions need at least one option", question.Label)
+	}
+
+	if len(options) > maxOptions {
+		return nil, fmt.Errorf("%s: choice questions can have at most %d options", question.Label, maxOptions)
+	}
+
+	return options, nil
+}
+
+// Conditions can only depend on the answer of an earlier yes/no or choice question
+func validateCondition(condition domain.IntakeCondition, earlier []domain.IntakeQuestion) error {
+	idx := slices.IndexFunc(earlier, func(q domain.IntakeQuestion) bool { return q.Id == condition.QuestionId })
+	if idx == -1 {
+		return fmt.Errorf("the condition has to refer to an earlier question")
+	}
+
+	if len(condition.Values) == 0 {
+		return fmt.Errorf("the condition needs at least one value")
+	}
+
+	parent := earlier[idx]
+
+	var allowed []string
+	switch {
+	case parent.Type == types.IntakeQuestionYesNo:
+		allowed = []string{"yes", "no"}
+	case isChoice(parent.Type):
+		allowed = parent.Options
+	default:
+		return fmt.Errorf("the condition can only refer to a yes/no or choice question")
+	}
+
+	for _, value := range condition.Values {
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("the condition value should be one of: %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package intake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testQuestions() []domain.IntakeQuestion {
+	return []domain.IntakeQuestion{
+		{Id: "allergic", Label: "Do you have allergies?", Type: types.IntakeQuestionYesNo, IsRequired: true},
+		{Id: "allergies", Label: "Which ones?", Type: types.IntakeQuestionMultipleChoice, IsRequired: true,
+			Options:   []string{"latex", "nickel", "other"},
+			VisibleIf: &domain.IntakeCondition{QuestionId: "allergic", Values: []string{"yes"}}},
+		{Id: "other", Label: "Other allergies", Type: types.IntakeQuestionText, IsRequired: true,
+			VisibleIf: &domain.IntakeCondition{QuestionId: "allergies", Values: []string{"other"}}},
+		{Id: "hair", Label: "Hair length", Type: types.IntakeQuestionSingleChoice, Options: []string{"short", "long"}},
+		{Id: "birthday", Label: "Birthday", Type: types.IntakeQuestionDate},
+		{Id: "consent", Label: "I agree to the treatment", Type: types.IntakeQuestionConsent, IsRequired: true},
+	}
+}
+
+func TestNormalizeQuestions(t *testing.T) {
+	questions, err := normalizeQuestions(testQuestions())
+	assert.NoError(t, err)
+	assert.Len(t, questions, 6)
+	assert.Equal(t, []string{}, questions[0].Options)
+
+	_, err = normalizeQuestions([]domain.IntakeQuestion{})
+	assert.Error(t, err)
+
+	duplicate := testQuestions()
+	duplicate[1].Id = "allergic"
+	_, err = normalizeQuestions(duplicate)
+	assert.Error(t, err)
+
+	// conditions can only refer to earlier questions
+	forward := testQuestions()
+	forward[0].VisibleIf = &domain.IntakeCondition{QuestionId: "hair", Values: []string{"short"}}
+	_, err = normalizeQuestions(forward)
+	assert.Error(t, err)
+
+	// nor to a question which has no fixed answers
+	textCondition := testQuestions()
+	textCondition[3].VisibleIf = &domain.IntakeCondition{QuestionId: "other", Values: []string{"x"}}
+	_, err = normalizeQuestions(textCondition)
+	assert.Error(t, err)
+
+	invalidValue := testQuestions()
+	invalidValue[1].VisibleIf.Values = []string{"maybe"}
+	_, err = normalizeQuestions(invalidValue)
+	assert.Error(t, err)
+
+	noOptions := testQuestions()
+	noOptions[3].Options = []string{" ", ""}
+	_, err = normalizeQuestions(noOptions)
+	assert.Error(t, err)
+
+	textOptions := testQuestions()
+	textOptions[2].Options = []string{"a"}
+	_, err = normalizeQuestions(textOptions)
+	assert.Error(t, err)
+
+	noType := testQuestions()
+	noType[4].Type = types.IntakeQuestionType{}
+	_, err = normalizeQuestions(noType)
+	assert.Error(t, err)
+}
+
+func TestValidateAnswers(t *testing.T) {
+	questions := testQuestions()
+
+	// the hidden questions are not required and their answers are dropped
+	answers, err := validateAnswers(questions, domain.IntakeAnswers{
+		"allergic": false, "allergies": []any{"latex"}, "hair": "long", "consent": true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IntakeAnswers{"allergic": false, "hair": "long", "consent": true}, answers)
+
+	// the conditional questions become required once they are shown
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": true, "consent": true})
+	assert.ErrorContains(t, err, "Which ones?")
+
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": true, "allergies": []any{"other"}, "consent": true})
+	assert.ErrorContains(t, err, "Other allergies")
+
+	answers, err = validateAnswers(questions, domain.IntakeAnswers{
+		"allergic": true, "allergies": []any{"other", "latex", "other"}, "other": "  pollen ", "consent": true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other", "latex"}, answers["allergies"])
+	assert.Equal(t, "pollen", answers["other"])
+
+	// not agreeing to a required consent
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": false, "consent": false})
+	assert.ErrorContains(t, err, "You have to agree")
+
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": false, "consent": true, "hair": "medium"})
+	assert.Error(t, err)
+
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": false, "consent": true, "birthday": "05/21/1990"})
+	assert.ErrorContains(t, err, "Must be a date")
+
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": "yes", "consent": true})
+	assert.Error(t, err)
+
+	_, err = validateAnswers(questions, domain.IntakeAnswers{"allergic": false, "consent": true, "unknown": "x"})
+	assert.Error(t, err)
+}
+
+func TestNewResponse(t *testing.T) {
+	form := domain.IntakeForm{Id: 3, Name: "Treatment", Mode: types.IntakeFormOnce, Questions: testQuestions()}
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// agreeing to a consent needs a signature
+	_, err := NewResponse(form, ResponseInput{Answers: domain.IntakeAnswers{"allergic": false, "consent": true}}, now)
+	assert.ErrorContains(t, err, "signature")
+
+	response, err := NewResponse(form, ResponseInput{
+		Answers:   domain.IntakeAnswers{"allergic": false, "consent": true},
+		Signature: "  Eva   Kovacs ",
+	}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *response.FormId)
+	assert.Equal(t, "Treatment", response.FormName)
+	assert.Equal(t, "Eva Kovacs", *response.Signature)
+	assert.Equal(t, now, *response.SignedAt)
+
+	// without consent questions there is nothing to sign
+	form.Questions = form.Questions[:1]
+	response, err = NewResponse(form, ResponseInput{Answers: domain.IntakeAnswers{"allergic": true}, Signature: "Eva"}, now)
+	assert.NoError(t, err)
+	assert.Nil(t, response.Signature)
+}
+
+func TestCanViewResponse(t *testing.T) {
+	own, other := 1, 2
+
+	staff := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionBookingsManageOwn}}
+	assert.True(t, canViewResponse(staff, &own))
+	assert.False(t, canViewResponse(staff, &other))
+	assert.False(t, canViewResponse(staff, nil))
+
+	notesViewer := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionCustomersViewNotes}}
+	assert.True(t, canViewResponse(notesViewer, nil))
+}
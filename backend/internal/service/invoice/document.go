@@ -0,0 +1,182 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/pdf"
+	"golang.org/x/text/language"
+)
+
+const (
+	marginLeft  = 50.0
+	marginRight = pdf.PageWidth - 50
+	buyerLeft   = 320.0
+	lineHeight  = 14.0
+)
+
+// x positions of the columns of the line items
+var columns = [...]float64{marginLeft, 290, 330, 420, 480}
+
+// The amount of tax per rate, in the order the rates first appear on the lines
+type taxSummary struct {
+	name   string
+	amount currency.Amount
+}
+
+func summarizeTaxes(lines []domain.InvoiceLine) ([]taxSummary, error) {
+	summaries := []taxSummary{}
+
+	for _, line := range lines {
+		if line.TaxName == nil {
+			continue
+		}
+
+		name := *line.TaxName
+		if line.TaxPercent != nil {
+			name = fmt.Sprintf("%s %s%%", name, *line.TaxPercent)
+		}
+
+		idx := -1
+		for i, summary := range summaries {
+			if summary.name == name {
+				idx = i
+				break
+			}
+		}
+
+		if idx == -1 {
+			summaries = append(summaries, taxSummary{name: name, amount: line.TaxAmount.Amount})
+			continue
+		}
+
+		var err error
+		summaries[idx].amount, err = summaries[idx].amount.Add(line.TaxAmount.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summaries, nil
+}
+
+// the document is written in english, so are the amounts on it
+var documentLocale = language.English
+
+func renderInvoice(invoice domain.NewInvoice, tz *time.Location) ([]byte, error) {
+	var formatErr error
+	format := func(amount currency.Amount) string {
+		formatted, err := currencyx.Format(amount, documentLocale)
+		if err != nil {
+			formatErr = err
+		}
+		return formatted
+	}
+
+	doc := pdf.New(fmt.Sprintf("Receipt %s", invoice.InvoiceNumber))
+	page := doc.AddPage()
+
+	page.Text(marginLeft, 70, pdf.Bold, 22, "Receipt")
+	page.Text(buyerLeft, 62, pdf.Regular, 10, fmt.Sprintf("Number: %s", invoice.InvoiceNumber))
+	page.Text(buyerLeft, 62+lineHeight, pdf.Regular, 10, fmt.Sprintf("Issued: %s", invoice.IssuedAt.In(tz).Format(time.DateOnly)))
+	page.Text(buyerLeft, 62+2*lineHeight, pdf.Regular, 10, fmt.Sprintf("Date of service: %s", invoice.ServiceDate.In(tz).Format(time.DateOnly)))
+
+	y := 140.0
+	page.Text(marginLeft, y, pdf.Bold, 11, invoice.Seller.Name)
+	page.Text(buyerLeft, y, pdf.Bold, 11, "Billed to")
+
+	sellerLines := []string{}
+	if invoice.Seller.Address != nil {
+		sellerLines = append(sellerLines, *invoice.Seller.Address)
+	}
+	if invoice.Seller.TaxNumber != nil {
+		sellerLines = append(sellerLines, fmt.Sprintf("Tax number: %s", *invoice.Seller.TaxNumber))
+	}
+	if invoice.Seller.RegistrationNumber != nil {
+		sellerLines = append(sellerLines, fmt.Sprintf("Registration number: %s", *invoice.Seller.RegistrationNumber))
+	}
+	sellerLines = append(sellerLines, invoice.Seller.Email)
+
+	buyerLines := []string{invoice.BuyerName}
+	if invoice.BuyerEmail != nil {
+		buyerLines = append(buyerLines, *invoice.BuyerEmail)
+	}
+
+	for i, text := range sellerLines {
+		page.Text(marginLeft, y+float64(i+1)*lineHeight, pdf.Regular, 10, text)
+	}
+
+	for i, text := range buyerLines {
+		page.Text(buyerLeft, y+float64(i+1)*lineHeight, pdf.Regular, 10, text)
+	}
+
+	y += float64(max(len(sellerLines), len(buyerLines))+3) * lineHeight
+
+	headers := []string{"Description", "Qty", "Unit price", "Tax", "Amount"}
+	for i, header := range headers {
+		page.Text(columns[i], y, pdf.Bold, 10, header)
+	}
+	page.Line(marginLeft, y+5, marginRight, y+5)
+
+	for _, line := range invoice.Lines {
+		y += lineHeight + 4
+
+		tax := "-"
+		if line.TaxPercent != nil {
+			tax = *line.TaxPercent + "%"
+		}
+
+		page.Text(columns[0], y, pdf.Regular, 10, line.Description)
+		page.Text(columns[1], y, pdf.Regular, 10, strconv.Itoa(line.Quantity))
+		page.Text(columns[2], y, pdf.Regular, 10, format(line.UnitPrice.Amount))
+		page.Text(columns[3], y, pdf.Regular, 10, tax)
+		page.Text(columns[4], y, pdf.Regular, 10, format(line.GrossAmount.Amount))
+	}
+
+	y += 10
+	page.Line(marginLeft, y, marginRight, y)
+
+	taxes, err := summarizeTaxes(invoice.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	y += lineHeight + 4
+	page.Text(columns[2], y, pdf.Regular, 10, "Net total")
+	page.Text(columns[4], y, pdf.Regular, 10, format(invoice.NetTotal.Amount))
+
+	for _, tax := range taxes {
+		y += lineHeight
+		page.Text(columns[2], y, pdf.Regular, 10, tax.name)
+		page.Text(columns[4], y, pdf.Regular, 10, format(tax.amount))
+	}
+
+	y += lineHeight
+	page.Text(columns[2], y, pdf.Regular, 10, "Tax total")
+	page.Text(columns[4], y, pdf.Regular, 10, format(invoice.TaxTotal.Amount))
+
+	y += lineHeight + 4
+	page.Text(columns[2], y, pdf.Bold, 12, "Total")
+	page.Text(columns[4], y, pdf.Bold, 12, format(invoice.Total.Amount))
+
+	if invoice.Footer != nil {
+		page.Line(marginLeft, pdf.PageHeight-70, marginRight, pdf.PageHeight-70)
+		page.Text(marginLeft, pdf.PageHeight-55, pdf.Regular, 9, *invoice.Footer)
+	}
+
+	if formatErr != nil {
+		return nil, formatErr
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
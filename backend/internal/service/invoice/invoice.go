@@ -0,0 +1,396 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/pricing"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
+	"golang.org/x/text/language"
+)
+
+// An export of the invoices can cover at most a year
+const maxInvoicePeriodDays = 366
+
+type Service struct {
+	invoiceRepo  domain.InvoiceRepository
+	merchantRepo domain.MerchantRepository
+	mailer       *email.Service
+	enqueuer     queue.Enqueuer
+	txManager    db.TransactionManager
+}
+
+func NewService(invoice domain.InvoiceRepository, merchant domain.MerchantRepository, mailer *email.Service,
+	enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	return &Service{
+		invoiceRepo:  invoice,
+		merchantRepo: merchant,
+		mailer:       mailer,
+		enqueuer:     enqueuer,
+		txManager:    txManager,
+	}
+}
+
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
+func (s *Service) GetSettings(ctx context.Context) (domain.InvoiceSettings, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.invoiceRepo.GetInvoiceSettings(ctx, employee.MerchantId)
+}
+
+type UpdateSettingsInput struct {
+	LegalName          *string
+	TaxNumber          *string
+	RegistrationNumber *string
+	Address            *string
+	Prefix             string
+	Footer             *string
+}
+
+func (s *Service) UpdateSettings(ctx context.Context, input UpdateSettingsInput) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	if strings.TrimSpace(input.Prefix) == "" {
+		return fmt.Errorf("the prefix of the invoice numbers can not be empty")
+	}
+
+	return s.invoiceRepo.UpdateInvoiceSettings(ctx, employee.MerchantId, domain.UpdateInvoiceSettings{
+		LegalName:          input.LegalName,
+		TaxNumber:          input.TaxNumber,
+		RegistrationNumber: input.RegistrationNumber,
+		Address:            input.Address,
+		Prefix:             strings.TrimSpace(input.Prefix),
+		Footer:             input.Footer,
+	})
+}
+
+type GetInvoicesInput struct {
+	// Inclusive days in the timezone of the merchant
+	Start time.Time
+	End   time.Time
+}
+
+func (s *Service) GetInvoices(ctx context.Context, input GetInvoicesInput) ([]domain.Invoice, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	if input.End.Before(input.Start) {
+		return nil, fmt.Errorf("the end of the period can not be before its start")
+	}
+
+	if input.End.Sub(input.Start).Hours()/24 >= maxInvoicePeriodDays {
+		return nil, fmt.Errorf("the period can cover at most %d days", maxInvoicePeriodDays)
+	}
+
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, employee.MerchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(input.Start.Year(), input.Start.Month(), input.Start.Day(), 0, 0, 0, 0, merchantTz)
+	to := time.Date(input.End.Year(), input.End.Month(), input.End.Day()+1, 0, 0, 0, 0, merchantTz)
+
+	return s.invoiceRepo.GetInvoices(ctx, employee.MerchantId, from, to)
+}
+
+type InvoiceWithLines struct {
+	domain.Invoice
+	Lines []domain.InvoiceLine
+}
+
+func (s *Service) GetInvoice(ctx context.Context, invoiceId int) (InvoiceWithLines, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	invoice, err := s.invoiceRepo.GetInvoice(ctx, employee.MerchantId, invoiceId)
+	if err != nil {
+		return InvoiceWithLines{}, err
+	}
+
+	lines, err := s.invoiceRepo.GetInvoiceLines(ctx, invoiceId)
+	if err != nil {
+		return InvoiceWithLines{}, err
+	}
+
+	return InvoiceWithLines{Invoice: invoice, Lines: lines}, nil
+}
+
+func (s *Service) DownloadInvoice(ctx context.Context, invoiceId int) (domain.Invoice, []byte, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	invoice, err := s.invoiceRepo.GetInvoice(ctx, employee.MerchantId, invoiceId)
+	if err != nil {
+		return domain.Invoice{}, nil, err
+	}
+
+	file, err := s.invoiceRepo.GetInvoiceFile(ctx, employee.MerchantId, invoiceId)
+	if err != nil {
+		return domain.Invoice{}, nil, err
+	}
+
+	return invoice, file, nil
+}
+
+// The invoice of the participation of the logged in user in the booking
+func (s *Service) DownloadForUser(ctx context.Context, bookingId int) (domain.Invoice, []byte, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	invoice, err := s.invoiceRepo.GetInvoiceForUser(ctx, userId, bookingId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Invoice{}, nil, fmt.Errorf("there is no invoice for this booking")
+		}
+		return domain.Invoice{}, nil, err
+	}
+
+	file, err := s.invoiceRepo.GetInvoiceFile(ctx, invoice.MerchantId, invoice.Id)
+	if err != nil {
+		return domain.Invoice{}, nil, err
+	}
+
+	return invoice, file, nil
+}
+
+func FileName(invoiceNumber string) string {
+	return fmt.Sprintf("receipt-%s.pdf", invoiceNumber)
+}
+
+func invoiceNumber(prefix string, sequence int) string {
+	return fmt.Sprintf("%s-%06d", prefix, sequence)
+}
+
+func buyerName(participant domain.InvoiceableParticipant) string {
+	parts := []string{}
+	if participant.FirstName != nil {
+		parts = append(parts, *participant.FirstName)
+	}
+
+	if participant.LastName != nil {
+		parts = append(parts, *participant.LastName)
+	}
+
+	if len(parts) == 0 && participant.Email != nil {
+		return *participant.Email
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// The items the participant pays for
+// the price of the booking already includes the tax it was made with
+func invoiceLines(participant domain.InvoiceableParticipant) ([]domain.InvoiceLine, error) {
+	var rate *pricing.Rate
+	if participant.TaxPercent != nil {
+		rate = &pricing.Rate{Percent: *participant.TaxPercent, Inclusive: true}
+	}
+
+	breakdown, err := pricing.Calculate(participant.PricePerPerson, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return []domain.InvoiceLine{{
+		Description: participant.ServiceName,
+		Quantity:    1,
+		UnitPrice:   breakdown.Net,
+		NetAmount:   breakdown.Net,
+		TaxName:     participant.TaxName,
+		TaxPercent:  participant.TaxPercent,
+		TaxAmount:   breakdown.Tax,
+		GrossAmount: breakdown.Gross,
+	}}, nil
+}
+
+type invoiceTotals struct {
+	net   currency.Amount
+	tax   currency.Amount
+	gross currency.Amount
+}
+
+func sumLines(lines []domain.InvoiceLine, currencyCode string) (invoiceTotals, error) {
+	zero, err := currency.NewAmount("0", currencyCode)
+	if err != nil {
+		return invoiceTotals{}, err
+	}
+
+	totals := invoiceTotals{net: zero, tax: zero, gross: zero}
+
+	for _, line := range lines {
+		if totals.net, err = totals.net.Add(line.NetAmount.Amount); err != nil {
+			return invoiceTotals{}, err
+		}
+
+		if totals.tax, err = totals.tax.Add(line.TaxAmount.Amount); err != nil {
+			return invoiceTotals{}, err
+		}
+
+		if totals.gross, err = totals.gross.Add(line.GrossAmount.Amount); err != nil {
+			return invoiceTotals{}, err
+		}
+	}
+
+	return totals, nil
+}
+
+// Issues an invoice for every completed participant of the booking who did not get one yet
+func (s *Service) IssueForBooking(ctx context.Context, bookingId int) error {
+	participants, err := s.invoiceRepo.GetUninvoicedParticipants(ctx, bookingId)
+	if err != nil {
+		return err
+	}
+
+	for _, participant := range participants {
+		// there is nothing to pay for
+		if participant.PriceType == types.PriceTypeFree || participant.PricePerPerson.IsZero() {
+			continue
+		}
+
+		err := s.issue(ctx, participant)
+		if err != nil {
+			return fmt.Errorf("could not issue invoice for participant %d: %w", participant.ParticipantId, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) issue(ctx context.Context, participant domain.InvoiceableParticipant) error {
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, participant.MerchantId)
+	if err != nil {
+		return err
+	}
+
+	lines, err := invoiceLines(participant)
+	if err != nil {
+		return err
+	}
+
+	totals, err := sumLines(lines, participant.PricePerPerson.CurrencyCode())
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		sequence, err := s.invoiceRepo.WithTx(tx).NextInvoiceSequence(ctx, participant.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		settings, err := s.invoiceRepo.WithTx(tx).GetInvoiceSettings(ctx, participant.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		seller := domain.InvoiceSeller{
+			Name:               settings.MerchantName,
+			TaxNumber:          settings.TaxNumber,
+			RegistrationNumber: settings.RegistrationNumber,
+			Address:            settings.Address,
+			Email:              settings.ContactEmail,
+		}
+
+		if settings.LegalName != nil && *settings.LegalName != "" {
+			seller.Name = *settings.LegalName
+		}
+
+		invoice := domain.NewInvoice{
+			MerchantId:    participant.MerchantId,
+			BookingId:     participant.BookingId,
+			ParticipantId: participant.ParticipantId,
+			CustomerId:    participant.CustomerId,
+			Sequence:      sequence,
+			InvoiceNumber: invoiceNumber(settings.Prefix, sequence),
+			IssuedAt:      time.Now().UTC(),
+			ServiceDate:   participant.FromDate,
+			Seller:        seller,
+			BuyerName:     buyerName(participant),
+			BuyerEmail:    participant.Email,
+			NetTotal:      currencyx.Price{Amount: totals.net},
+			TaxTotal:      currencyx.Price{Amount: totals.tax},
+			Total:         currencyx.Price{Amount: totals.gross},
+			Footer:        settings.Footer,
+			Lines:         lines,
+		}
+
+		invoice.File, err = renderInvoice(invoice, merchantTz)
+		if err != nil {
+			return fmt.Errorf("could not render invoice: %w", err)
+		}
+
+		invoiceId, err := s.invoiceRepo.WithTx(tx).NewInvoice(ctx, invoice)
+		if err != nil {
+			return err
+		}
+
+		if participant.Email != nil {
+			_, err = s.enqueuer.InsertTx(ctx, tx, args.InvoiceEmail{InvoiceId: invoiceId}, nil)
+			if err != nil {
+				return fmt.Errorf("could not schedule invoice email job: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Emails the invoice to the customer, an invoice is only sent once
+func (s *Service) SendInvoice(ctx context.Context, invoiceId int) error {
+	invoice, err := s.invoiceRepo.GetInvoiceForEmail(ctx, invoiceId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if invoice.BuyerEmail == nil || invoice.EmailedAt != nil {
+		return nil
+	}
+
+	merchantTz, err := time.LoadLocation(invoice.Timezone)
+	if err != nil {
+		merchantTz = time.UTC
+	}
+
+	userLang := lang.GetDefaultLang()
+	if invoice.UserLanguage != nil {
+		if tag, err := language.Parse(*invoice.UserLanguage); err == nil {
+			userLang = tag
+		}
+	}
+
+	total, err := currencyx.Format(invoice.Total.Amount, userLang)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Invoice(ctx, userLang, *invoice.BuyerEmail, email.InvoiceData{
+		MerchantName:  invoice.SellerName,
+		InvoiceNumber: invoice.InvoiceNumber,
+		Date:          invoice.ServiceDate.In(merchantTz).Format(time.DateOnly),
+		Total:         total,
+	}, email.Attachment{
+		FileName: FileName(invoice.InvoiceNumber),
+		Content:  invoice.File,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.invoiceRepo.SetInvoiceEmailed(ctx, invoiceId, time.Now().UTC())
+}
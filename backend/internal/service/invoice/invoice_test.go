@@ -0,0 +1,175 @@
+package invoice
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func price(t *testing.T, number string) currencyx.Price {
+	t.Helper()
+
+	return currencyxtest.Price(t, number, "HUF")
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var (
+	cmapPattern = regexp.MustCompile(`(?s)begincmap.*?endcmap`)
+	charPattern = regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]{4})>`)
+	textPattern = regexp.MustCompile(`/F(\d) \S+ Tf \S+ \S+ Td <([0-9A-F]*)> Tj`)
+)
+
+// Decodes the texts of the document with the character maps of its fonts, which are written in the order of the fonts
+func pdfText(t *testing.T, content string) string {
+	t.Helper()
+
+	var fonts []map[string]rune
+	for _, cmap := range cmapPattern.FindAllString(content, -1) {
+		chars := map[string]rune{}
+		for _, match := range charPattern.FindAllStringSubmatch(cmap, -1) {
+			char, err := strconv.ParseUint(match[2], 16, 16)
+			assert.NoError(t, err)
+
+			chars[match[1]] = rune(char)
+		}
+		fonts = append(fonts, chars)
+	}
+
+	var texts []string
+	for _, match := range textPattern.FindAllStringSubmatch(content, -1) {
+		font, err := strconv.Atoi(match[1])
+		if !assert.NoError(t, err) || !assert.Less(t, font-1, len(fonts)) {
+			return ""
+		}
+
+		var b strings.Builder
+		for i := 0; i+4 <= len(match[2]); i += 4 {
+			b.WriteRune(fonts[font-1][match[2][i:i+4]])
+		}
+		texts = append(texts, b.String())
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-000001", invoiceNumber("INV", 1))
+	assert.Equal(t, "2026-1234567", invoiceNumber("2026", 1234567))
+}
+
+func TestBuyerName(t *testing.T) {
+	t.Run("full name", func(t *testing.T) {
+		assert.Equal(t, "Jane Doe", buyerName(domain.InvoiceableParticipant{FirstName: ptr("Jane"), LastName: ptr("Doe"), Email: ptr("jane@example.com")}))
+	})
+
+	t.Run("falls back to the email", func(t *testing.T) {
+		assert.Equal(t, "jane@example.com", buyerName(domain.InvoiceableParticipant{Email: ptr("jane@example.com")}))
+	})
+}
+
+func TestInvoiceLines(t *testing.T) {
+	lines, err := invoiceLines(domain.InvoiceableParticipant{ServiceName: "Haircut", PricePerPerson: price(t, "8000")})
+	assert.NoError(t, err)
+	if !assert.Len(t, lines, 1) {
+		return
+	}
+
+	line := lines[0]
+	assert.Equal(t, "Haircut", line.Description)
+	assert.Equal(t, 1, line.Quantity)
+	assert.Equal(t, "8000", line.GrossAmount.Number())
+	assert.Equal(t, "8000", line.NetAmount.Number())
+	assert.True(t, line.TaxAmount.IsZero())
+
+	lines, err = invoiceLines(domain.InvoiceableParticipant{ServiceName: "Haircut", PricePerPerson: price(t, "10000"),
+		TaxName: ptr("VAT"), TaxPercent: ptr("27.00")})
+	assert.NoError(t, err)
+	if !assert.Len(t, lines, 1) {
+		return
+	}
+
+	line = lines[0]
+	assert.Equal(t, "VAT", *line.TaxName)
+	assert.Equal(t, "10000", line.GrossAmount.Number())
+	assert.Equal(t, "2126", line.TaxAmount.Number())
+	assert.Equal(t, "7874", line.NetAmount.Number())
+	assert.Equal(t, "7874", line.UnitPrice.Number())
+}
+
+func TestSumLines(t *testing.T) {
+	lines := []domain.InvoiceLine{
+		{NetAmount: price(t, "7874"), TaxAmount: price(t, "2126"), GrossAmount: price(t, "10000")},
+		{NetAmount: price(t, "1000"), TaxAmount: price(t, "50"), GrossAmount: price(t, "1050")},
+	}
+
+	totals, err := sumLines(lines, "HUF")
+	assert.NoError(t, err)
+	assert.Equal(t, "8874", totals.net.Number())
+	assert.Equal(t, "2176", totals.tax.Number())
+	assert.Equal(t, "11050", totals.gross.Number())
+}
+
+func TestSummarizeTaxes(t *testing.T) {
+	lines := []domain.InvoiceLine{
+		{TaxName: ptr("VAT"), TaxPercent: ptr("27"), TaxAmount: price(t, "2126")},
+		{TaxAmount: price(t, "0")},
+		{TaxName: ptr("VAT"), TaxPercent: ptr("5"), TaxAmount: price(t, "50")},
+		{TaxName: ptr("VAT"), TaxPercent: ptr("27"), TaxAmount: price(t, "270")},
+	}
+
+	summaries, err := summarizeTaxes(lines)
+	assert.NoError(t, err)
+	if !assert.Len(t, summaries, 2) {
+		return
+	}
+	assert.Equal(t, "VAT 27%", summaries[0].name)
+	assert.Equal(t, "2396", summaries[0].amount.Number())
+	assert.Equal(t, "VAT 5%", summaries[1].name)
+	assert.Equal(t, "50", summaries[1].amount.Number())
+}
+
+func TestRenderInvoice(t *testing.T) {
+	issuedAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	file, err := renderInvoice(domain.NewInvoice{
+		InvoiceNumber: "INV-000042",
+		IssuedAt:      issuedAt,
+		ServiceDate:   issuedAt,
+		Seller:        domain.InvoiceSeller{Name: "Studio Kft.", TaxNumber: ptr("12345678-2-42"), Email: "studio@example.com"},
+		BuyerName:     "Kőrösi Gyűrű",
+		NetTotal:      price(t, "8000"),
+		TaxTotal:      price(t, "0"),
+		Total:         price(t, "8000"),
+		Footer:        ptr("Thank you for your visit"),
+		Lines: []domain.InvoiceLine{{
+			Description: "Haircut",
+			Quantity:    1,
+			UnitPrice:   price(t, "8000"),
+			NetAmount:   price(t, "8000"),
+			TaxAmount:   price(t, "0"),
+			GrossAmount: price(t, "8000"),
+		}},
+	}, time.UTC)
+	assert.NoError(t, err)
+
+	content := string(file)
+	assert.True(t, strings.HasPrefix(content, "%PDF-"))
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(content), "%%EOF"))
+
+	text := pdfText(t, content)
+	assert.Contains(t, text, "INV-000042")
+	assert.Contains(t, text, "Studio Kft.")
+	assert.Contains(t, text, "Kőrösi Gyűrű")
+	assert.Contains(t, text, "12345678-2-42")
+	assert.Contains(t, text, "Thank you for your visit")
+}
@@ -0,0 +1,31 @@
+package merchant
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+)
+
+// Settings of a merchant which are recorded in the audit log
+type settingsAuditState struct {
+	domain.MerchantSettingFields
+	// time slots are formatted as "15:04-18:00"
+	BusinessHours map[int][]string `json:"business_hours"`
+}
+
+func newSettingsAuditState(fields domain.MerchantSettingFields, businessHours domain.BusinessHours) settingsAuditState {
+	formattedHours := make(map[int][]string, len(businessHours))
+
+	for day, slots := range businessHours {
+		formattedSlots := make([]string, len(slots))
+
+		for i, slot := range slots {
+			formattedSlots[i] = slot.StartTime.Format("15:04") + "-" + slot.EndTime.Format("15:04")
+		}
+
+		formattedHours[day] = formattedSlots
+	}
+
+	return settingsAuditState{
+		MerchantSettingFields: fields,
+		BusinessHours:         formattedHours,
+	}
+}
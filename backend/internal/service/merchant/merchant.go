@@ -3,11 +3,14 @@ package merchant
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
+	"github.com/miketsu-inc/reservations/backend/internal/service/report"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
 	"github.com/miketsu-inc/reservations/backend/internal/utils"
 	"github.com/miketsu-inc/reservations/backend/pkg/db"
@@ -22,12 +25,15 @@ type Service struct {
 	blockedTimeRepo domain.BlockedTimeRepository
 	teamRepo        domain.TeamRepository
 	productRepo     domain.ProductRepository
+	auditLogRepo    domain.AuditLogRepository
+	reportService   *report.Service
 	txManager       db.TransactionManager
 }
 
 func NewService(booking domain.BookingRepository, catalog domain.CatalogRepository, merchant domain.MerchantRepository,
 	customer domain.CustomerRepository, blockedTime domain.BlockedTimeRepository, team domain.TeamRepository,
-	product domain.ProductRepository, txManager db.TransactionManager) *Service {
+	product domain.ProductRepository, auditLog domain.AuditLogRepository, reportService *report.Service,
+	txManager db.TransactionManager) *Service {
 	return &Service{
 		bookingRepo:     booking,
 		catalogRepo:     catalog,
@@ -36,6 +42,8 @@ func NewService(booking domain.BookingRepository, catalog domain.CatalogReposito
 		blockedTimeRepo: blockedTime,
 		teamRepo:        team,
 		productRepo:     product,
+		auditLogRepo:    auditLog,
+		reportService:   reportService,
 		txManager:       txManager,
 	}
 }
@@ -107,26 +115,94 @@ func (s *Service) GetDashboard(ctx context.Context, date time.Time, period int)
 		return domain.DashboardData{}, err
 	}
 
-	// -1 because the last is the current day
-	currPeriodStart := utils.TruncateToDay(utcDate.AddDate(0, 0, -(period - 1)))
-	prevPeriodStart := utils.TruncateToDay(currPeriodStart.AddDate(0, 0, -(period - 1)))
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, actor.MerchantId)
+	if err != nil {
+		return domain.DashboardData{}, err
+	}
 
-	dashboard.PeriodStart = currPeriodStart
-	dashboard.PeriodEnd = utils.TruncateToDay(utcDate)
+	// the days are counted in the timezone of the merchant, -1 because the last is the current day
+	periodEnd := utils.TruncateToDay(date.In(merchantTz))
+	periodStart := periodEnd.AddDate(0, 0, -(period - 1))
 
-	dashboard.Statistics, err = s.merchantRepo.GetDashboardStats(ctx, actor.MerchantId, currPeriodStart, utcDate, prevPeriodStart)
+	dashboard.PeriodStart = periodStart
+	dashboard.PeriodEnd = periodEnd
+
+	stats, err := s.reportService.GetMerchantReport(ctx, actor.MerchantId, report.ReportInput{
+		Start:       periodStart,
+		End:         periodEnd,
+		Granularity: types.ReportGranularityDay,
+	})
 	if err != nil {
 		return domain.DashboardData{}, err
 	}
 
-	dashboard.Statistics.Revenue, err = s.merchantRepo.GetRevenueStats(ctx, actor.MerchantId, currPeriodStart, utcDate)
+	dashboard.Statistics = dashboardStatistics(stats, merchantTz)
+
+	utilization, err := s.reportService.GetMerchantUtilization(ctx, actor.MerchantId, report.UtilizationInput{
+		Start: periodStart,
+		End:   periodEnd,
+	})
 	if err != nil {
 		return domain.DashboardData{}, err
 	}
 
+	dashboard.Utilization = dashboardUtilization(utilization)
+
 	return dashboard, nil
 }
 
+func dashboardUtilization(utilization domain.Utilization) domain.DashboardUtilization {
+	employees := make([]domain.DashboardEmployeeUtilization, len(utilization.Employees))
+	for i, e := range utilization.Employees {
+		employees[i] = domain.DashboardEmployeeUtilization{
+			EmployeeId:  e.EmployeeId,
+			Name:        e.Name,
+			Utilization: e.Utilization,
+		}
+	}
+
+	return domain.DashboardUtilization{
+		Utilization:    utilization.Utilization,
+		RevenuePerHour: utilization.RevenuePerHour,
+		IdleGapMinutes: utilization.IdleGapMinutes,
+		Employees:      employees,
+	}
+}
+
+// The days of the revenue are returned as the midnight of the merchant like the period itself
+func dashboardStatistics(stats domain.Report, merchantTz *time.Location) domain.DashboardStatistics {
+	revenue := make([]domain.RevenueStat, 0, len(stats.Series))
+	for _, bucket := range stats.Series {
+		value, _ := strconv.ParseFloat(bucket.Metrics.Revenue.Number(), 64)
+
+		day := time.Date(bucket.Period.Start.Year(), bucket.Period.Start.Month(), bucket.Period.Start.Day(), 0, 0, 0, 0, merchantTz)
+
+		revenue = append(revenue, domain.RevenueStat{Value: value, Day: day})
+	}
+
+	taxes := make([]domain.TaxStat, len(stats.Taxes))
+	for i, tax := range stats.Taxes {
+		taxes[i] = domain.TaxStat{
+			Name:    tax.Name,
+			Percent: tax.Percent,
+			Amount:  tax.Amount,
+		}
+	}
+
+	return domain.DashboardStatistics{
+		Revenue:               revenue,
+		RevenueSum:            stats.Current.Revenue,
+		RevenueChange:         stats.Changes.Revenue,
+		Taxes:                 taxes,
+		Bookings:              stats.Current.Bookings,
+		BookingsChange:        stats.Changes.Bookings,
+		Cancellations:         stats.Current.Cancellations,
+		CancellationsChange:   stats.Changes.Cancellations * -1,
+		AverageDuration:       stats.Current.AverageDuration(),
+		AverageDurationChange: stats.Changes.AverageDuration,
+	}
+}
+
 type ErrMerchantUrlNotUnique struct {
 	URL string
 }
@@ -185,19 +261,26 @@ type UpdateSettingsInput struct {
 func (s *Service) UpdateSettings(ctx context.Context, input UpdateSettingsInput) error {
 	actor := actor.MustGetFromContext(ctx)
 
+	fields := domain.MerchantSettingFields{
+		Introduction:     input.Introduction,
+		Announcement:     input.Announcement,
+		AboutUs:          input.AboutUs,
+		ParkingInfo:      input.ParkingInfo,
+		PaymentInfo:      input.PaymentInfo,
+		CancelDeadline:   input.CancelDeadline,
+		BookingWindowMin: input.BookingWindowMin,
+		BookingWindowMax: input.BookingWindowMax,
+		BufferTime:       input.BufferTime,
+		ApprovalPolicy:   input.ApprovalPolicy,
+	}
+
 	err := s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
-		err := s.merchantRepo.WithTx(tx).UpdateMerchantFields(ctx, actor.MerchantId, domain.MerchantSettingFields{
-			Introduction:     input.Introduction,
-			Announcement:     input.Announcement,
-			AboutUs:          input.AboutUs,
-			ParkingInfo:      input.ParkingInfo,
-			PaymentInfo:      input.PaymentInfo,
-			CancelDeadline:   input.CancelDeadline,
-			BookingWindowMin: input.BookingWindowMin,
-			BookingWindowMax: input.BookingWindowMax,
-			BufferTime:       input.BufferTime,
-			ApprovalPolicy:   input.ApprovalPolicy,
-		})
+		before, err := s.merchantRepo.WithTx(tx).GetMerchantSettingsInfo(ctx, actor.MerchantId)
+		if err != nil {
+			return err
+		}
+
+		err = s.merchantRepo.WithTx(tx).UpdateMerchantFields(ctx, actor.MerchantId, fields)
 		if err != nil {
 			return err
 		}
@@ -212,7 +295,26 @@ func (s *Service) UpdateSettings(ctx context.Context, input UpdateSettingsInput)
 			return err
 		}
 
-		return nil
+		fieldsBefore := domain.MerchantSettingFields{
+			Introduction:     before.Introduction,
+			Announcement:     before.Announcement,
+			AboutUs:          before.AboutUs,
+			ParkingInfo:      before.ParkingInfo,
+			PaymentInfo:      before.PaymentInfo,
+			CancelDeadline:   before.CancelDeadline,
+			BookingWindowMin: before.BookingWindowMin,
+			BookingWindowMax: before.BookingWindowMax,
+			BufferTime:       before.BufferTime,
+			ApprovalPolicy:   before.ApprovalPolicy,
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "merchant_settings.updated",
+			EntityType: types.AuditEntityMerchantSettings,
+			EntityId:   actor.MerchantId.String(),
+			Before:     newSettingsAuditState(fieldsBefore, before.BusinessHours),
+			After:      newSettingsAuditState(fields, input.BusinessHours),
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("error while updating reservation fileds for merchant: %s", err.Error())
@@ -321,6 +423,12 @@ func (s *Service) GetCustomersForCalendar(ctx context.Context) ([]domain.Custome
 		return []domain.CustomerForCalendar{}, err
 	}
 
+	if !actor.HasPermission(types.PermissionCustomersViewPii) {
+		for i := range customers {
+			customers[i] = customers[i].WithoutPii()
+		}
+	}
+
 	return customers, nil
 }
 
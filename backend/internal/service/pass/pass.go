@@ -0,0 +1,207 @@
+package pass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type Service struct {
+	passRepo     domain.PassRepository
+	merchantRepo domain.MerchantRepository
+	txManager    db.TransactionManager
+}
+
+func NewService(pass domain.PassRepository, merchant domain.MerchantRepository, txManager db.TransactionManager) *Service {
+	return &Service{
+		passRepo:     pass,
+		merchantRepo: merchant,
+		txManager:    txManager,
+	}
+}
+
+// Saves the product and checks that every service and category of it belongs to the merchant
+func (s *Service) saveProduct(ctx context.Context, product domain.PassProduct, save func(tx pgx.Tx) (int, error)) (int, error) {
+	var productId int
+
+	err := s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+
+		productId, err = save(tx)
+		if err != nil {
+			return err
+		}
+
+		saved, err := s.passRepo.WithTx(tx).GetPassProduct(ctx, product.MerchantId, productId)
+		if err != nil {
+			return err
+		}
+
+		if len(saved.ServiceIds) != len(product.ServiceIds) {
+			return fmt.Errorf("service not found")
+		}
+
+		if len(saved.CategoryIds) != len(product.CategoryIds) {
+			return fmt.Errorf("category not found")
+		}
+
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("pass not found")
+	}
+
+	return productId, err
+}
+
+func (s *Service) newProduct(ctx context.Context, input ProductInput) (domain.PassProduct, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	merchantCurrency, err := s.merchantRepo.GetMerchantCurrency(ctx, employee.MerchantId)
+	if err != nil {
+		return domain.PassProduct{}, err
+	}
+
+	if err := input.validate(merchantCurrency); err != nil {
+		return domain.PassProduct{}, err
+	}
+
+	return domain.PassProduct{
+		MerchantId:   employee.MerchantId,
+		Name:         strings.TrimSpace(input.Name),
+		Credits:      input.Credits,
+		ValidityDays: input.ValidityDays,
+		Price:        input.Price,
+		IsActive:     input.IsActive,
+		ServiceIds:   uniqueIds(input.ServiceIds),
+		CategoryIds:  uniqueIds(input.CategoryIds),
+	}, nil
+}
+
+func (s *Service) NewProduct(ctx context.Context, input ProductInput) (int, error) {
+	product, err := s.newProduct(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.saveProduct(ctx, product, func(tx pgx.Tx) (int, error) {
+		return s.passRepo.WithTx(tx).NewPassProduct(ctx, product)
+	})
+}
+
+// Changing a product only affects the passes assigned after it, the earlier ones keep their credits and validity.
+// Products are archived by making them inactive, as the passes of customers refer to them
+func (s *Service) UpdateProduct(ctx context.Context, productId int, input ProductInput) error {
+	product, err := s.newProduct(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	product.Id = productId
+
+	_, err = s.saveProduct(ctx, product, func(tx pgx.Tx) (int, error) {
+		return productId, s.passRepo.WithTx(tx).UpdatePassProduct(ctx, product)
+	})
+
+	return err
+}
+
+func (s *Service) GetProducts(ctx context.Context) ([]domain.PassProduct, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.passRepo.GetPassProducts(ctx, employee.MerchantId)
+}
+
+type AssignInput struct {
+	CustomerId    uuid.UUID
+	PassProductId int
+	// the pass is valid from now if it's missing
+	ValidFrom *time.Time
+}
+
+func (s *Service) Assign(ctx context.Context, input AssignInput) (domain.CustomerPass, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	product, err := s.passRepo.GetPassProduct(ctx, employee.MerchantId, input.PassProductId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.CustomerPass{}, fmt.Errorf("pass not found")
+		}
+		return domain.CustomerPass{}, err
+	}
+
+	if !product.IsActive {
+		return domain.CustomerPass{}, fmt.Errorf("the pass is archived and can not be assigned anymore")
+	}
+
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, employee.MerchantId)
+	if err != nil {
+		return domain.CustomerPass{}, err
+	}
+
+	validFrom := time.Now()
+	if input.ValidFrom != nil {
+		validFrom = *input.ValidFrom
+	}
+
+	validFrom, validUntil := validityPeriod(validFrom, product.ValidityDays, merchantTz)
+
+	if !validUntil.After(time.Now()) {
+		return domain.CustomerPass{}, fmt.Errorf("the pass would already be expired")
+	}
+
+	passId, err := s.passRepo.NewCustomerPass(ctx, domain.NewCustomerPass{
+		MerchantId:    employee.MerchantId,
+		CustomerId:    input.CustomerId,
+		PassProductId: product.Id,
+		Name:          product.Name,
+		Credits:       product.Credits,
+		ValidFrom:     validFrom,
+		ValidUntil:    validUntil,
+		AssignedBy:    &employee.EmployeeId,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.CustomerPass{}, fmt.Errorf("customer not found")
+		}
+		return domain.CustomerPass{}, err
+	}
+
+	return s.passRepo.GetCustomerPass(ctx, employee.MerchantId, passId)
+}
+
+type CustomerPasses struct {
+	Passes []domain.CustomerPass
+	Usages []domain.PassUsage
+}
+
+func (s *Service) GetCustomerPasses(ctx context.Context, customerId uuid.UUID) (CustomerPasses, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	passes, err := s.passRepo.GetCustomerPasses(ctx, employee.MerchantId, customerId)
+	if err != nil {
+		return CustomerPasses{}, err
+	}
+
+	usages, err := s.passRepo.GetCustomerPassUsages(ctx, employee.MerchantId, customerId)
+	if err != nil {
+		return CustomerPasses{}, err
+	}
+
+	return CustomerPasses{Passes: passes, Usages: usages}, nil
+}
+
+// Marks the passes whose validity has ended as expired, their remaining credits can not be used anymore
+func (s *Service) ExpirePasses(ctx context.Context) error {
+	_, err := s.passRepo.ExpirePasses(ctx, time.Now())
+
+	return err
+}
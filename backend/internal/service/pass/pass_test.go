@@ -0,0 +1,85 @@
+package pass
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductInputValidate(t *testing.T) {
+	credits := 10
+	zero := 0
+
+	valid := ProductInput{
+		Name:         "10 classes",
+		Credits:      &credits,
+		ValidityDays: 90,
+		Price:        currencyxtest.Price(t, "30000", "HUF"),
+		ServiceIds:   []int{1},
+	}
+
+	assert.NoError(t, valid.validate("HUF"))
+
+	unlimited := valid
+	unlimited.Credits = nil
+	unlimited.ServiceIds = nil
+	unlimited.CategoryIds = []int{2}
+	assert.NoError(t, unlimited.validate("HUF"))
+
+	tests := []struct {
+		name   string
+		modify func(input *ProductInput)
+	}{
+		{"empty name", func(input *ProductInput) { input.Name = "  " }},
+		{"zero credits", func(input *ProductInput) { input.Credits = &zero }},
+		{"zero validity", func(input *ProductInput) { input.ValidityDays = 0 }},
+		{"too long validity", func(input *ProductInput) { input.ValidityDays = maxValidityDays + 1 }},
+		{"other currency", func(input *ProductInput) { input.Price = currencyxtest.Price(t, "80", "EUR") }},
+		{"negative price", func(input *ProductInput) { input.Price = currencyxtest.Price(t, "-1", "HUF") }},
+		{"too many decimals", func(input *ProductInput) { input.Price = currencyxtest.Price(t, "100.5", "HUF") }},
+		{"no services or categories", func(input *ProductInput) { input.ServiceIds = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+
+			assert.Error(t, input.validate("HUF"))
+		})
+	}
+}
+
+func TestUniqueIds(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 5}, uniqueIds([]int{5, 1, 2, 1, 5}))
+
+	ids := uniqueIds(nil)
+	assert.NotNil(t, ids)
+	assert.Empty(t, ids)
+}
+
+func TestValidityPeriod(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("same time on the last day", func(t *testing.T) {
+		validFrom := time.Date(2026, 5, 1, 18, 30, 0, 0, budapest)
+
+		from, until := validityPeriod(validFrom, 30, budapest)
+		assert.Equal(t, time.UTC, from.Location())
+		assert.True(t, from.Equal(validFrom))
+		assert.True(t, until.Equal(time.Date(2026, 5, 31, 18, 30, 0, 0, budapest)))
+	})
+
+	t.Run("across a DST change", func(t *testing.T) {
+		validFrom := time.Date(2026, 3, 20, 18, 0, 0, 0, budapest)
+
+		_, until := validityPeriod(validFrom, 30, budapest)
+		assert.True(t, until.Equal(time.Date(2026, 4, 19, 18, 0, 0, 0, budapest)))
+		assert.Equal(t, 30*24*time.Hour-time.Hour, until.Sub(validFrom))
+	})
+}
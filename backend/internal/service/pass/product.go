@@ -0,0 +1,77 @@
+package pass
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Passes can not be valid for more than 10 years
+const maxValidityDays = 3660
+
+type ProductInput struct {
+	Name string
+	// nil if the pass is unlimited
+	Credits      *int
+	ValidityDays int
+	Price        currencyx.Price
+	IsActive     bool
+	ServiceIds   []int
+	CategoryIds  []int
+}
+
+func (input ProductInput) validate(merchantCurrency string) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("the name of the pass can not be empty")
+	}
+
+	if input.Credits != nil && *input.Credits <= 0 {
+		return fmt.Errorf("the number of credits has to be positive, leave it empty for an unlimited pass")
+	}
+
+	if input.ValidityDays <= 0 || input.ValidityDays > maxValidityDays {
+		return fmt.Errorf("the validity of the pass has to be between 1 and %d days", maxValidityDays)
+	}
+
+	if input.Price.CurrencyCode() != merchantCurrency {
+		return fmt.Errorf("the price of the pass has to be in the currency of the merchant")
+	}
+
+	if input.Price.IsNegative() {
+		return fmt.Errorf("the price of the pass can not be negative")
+	}
+
+	rounded, err := currencyx.Round(input.Price.Amount)
+	if err != nil {
+		return err
+	}
+
+	if !rounded.Equal(input.Price.Amount) {
+		return fmt.Errorf("the price of the pass has too many decimals for its currency")
+	}
+
+	if len(input.ServiceIds) == 0 && len(input.CategoryIds) == 0 {
+		return fmt.Errorf("the pass has to be valid for at least one service or category")
+	}
+
+	return nil
+}
+
+// Sorted ids without duplicates, never nil so an empty list clears every id when saved
+func uniqueIds(ids []int) []int {
+	unique := append([]int{}, ids...)
+	slices.Sort(unique)
+
+	return slices.Compact(unique)
+}
+
+// The pass is valid from the given time for the given number of days in the timezone of the merchant,
+// so a pass bought in the evening is still valid in the evening of its last day across DST changes
+func validityPeriod(validFrom time.Time, validityDays int, merchantTz *time.Location) (time.Time, time.Time) {
+	local := validFrom.In(merchantTz)
+
+	return validFrom.UTC(), local.AddDate(0, 0, validityDays).UTC()
+}
@@ -2,8 +2,11 @@ package product
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
 	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
@@ -12,15 +15,33 @@ import (
 type Service struct {
 	productRepo  domain.ProductRepository
 	merchantRepo domain.MerchantRepository
+	catalogRepo  domain.CatalogRepository
 }
 
-func NewService(product domain.ProductRepository, merchant domain.MerchantRepository) *Service {
+func NewService(product domain.ProductRepository, merchant domain.MerchantRepository, catalog domain.CatalogRepository) *Service {
 	return &Service{
 		productRepo:  product,
 		merchantRepo: merchant,
+		catalogRepo:  catalog,
 	}
 }
 
+func (s *Service) validateTaxRate(ctx context.Context, merchantId uuid.UUID, taxRateId *int) error {
+	if taxRateId == nil {
+		return nil
+	}
+
+	_, err := s.catalogRepo.GetTaxRate(ctx, merchantId, *taxRateId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("tax rate not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
 type NewInput struct {
 	Name          string
 	Description   string
@@ -28,6 +49,7 @@ type NewInput struct {
 	Unit          string
 	MaxAmount     int
 	CurrentAmount int
+	TaxRateId     *int
 }
 
 func (s *Service) New(ctx context.Context, input NewInput) error {
@@ -44,6 +66,10 @@ func (s *Service) New(ctx context.Context, input NewInput) error {
 		}
 	}
 
+	if err := s.validateTaxRate(ctx, actor.MerchantId, input.TaxRateId); err != nil {
+		return err
+	}
+
 	if err := s.productRepo.NewProduct(ctx, domain.Product{
 		Id:            0,
 		MerchantId:    actor.MerchantId,
@@ -53,6 +79,7 @@ func (s *Service) New(ctx context.Context, input NewInput) error {
 		Unit:          input.Unit,
 		MaxAmount:     input.MaxAmount,
 		CurrentAmount: input.CurrentAmount,
+		TaxRateId:     input.TaxRateId,
 	}); err != nil {
 		return err
 	}
@@ -68,6 +95,7 @@ type UpdateInput struct {
 	Unit          string
 	MaxAmount     int
 	CurrentAmount int
+	TaxRateId     *int
 }
 
 func (s *Service) Update(ctx context.Context, productId int, input UpdateInput) error {
@@ -77,6 +105,10 @@ func (s *Service) Update(ctx context.Context, productId int, input UpdateInput)
 
 	actor := actor.MustGetFromContext(ctx)
 
+	if err := s.validateTaxRate(ctx, actor.MerchantId, input.TaxRateId); err != nil {
+		return err
+	}
+
 	err := s.productRepo.UpdateProduct(ctx, domain.Product{
 		Id:            input.Id,
 		MerchantId:    actor.MerchantId,
@@ -86,6 +118,7 @@ func (s *Service) Update(ctx context.Context, productId int, input UpdateInput)
 		Unit:          input.Unit,
 		MaxAmount:     input.MaxAmount,
 		CurrentAmount: input.CurrentAmount,
+		TaxRateId:     input.TaxRateId,
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,131 @@
+package promotion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// between 3 and 30 letters, digits, dashes and underscores, like SUMMER-25
+var codeRegex = regexp.MustCompile(`^[A-Z0-9_-]{3,30}$`)
+
+// Codes are matched ignoring the case and the surrounding spaces
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	if !codeRegex.MatchString(code) {
+		return "", fmt.Errorf("invalid promo code")
+	}
+
+	return code, nil
+}
+
+// between 0 and 100 with at most two decimals, like the numeric(5, 2) column of the promotions
+var percentRegex = regexp.MustCompile(`^(100(\.0{1,2})?|\d{1,2}(\.\d{1,2})?)$`)
+
+func validatePercent(percent string) error {
+	if !percentRegex.MatchString(percent) {
+		return fmt.Errorf("the percent of the discount has to be between 0 and 100 with at most two decimals")
+	}
+
+	amount, err := currency.NewAmount(percent, "EUR")
+	if err != nil || !amount.IsPositive() {
+		return fmt.Errorf("the percent of the discount has to be positive")
+	}
+
+	return nil
+}
+
+// Why the promotion can not be used for a slot of the service, nil if it can
+func CheckApplicable(promotion domain.Promotion, serviceId int, categoryId *int, slot time.Time, now time.Time) error {
+	if !promotion.IsActive {
+		return fmt.Errorf("the promo code is not active")
+	}
+
+	if !promotion.IsValidAt(now) {
+		return fmt.Errorf("the promo code is not valid at this time")
+	}
+
+	if !promotion.AppliesToService(serviceId, categoryId) {
+		return fmt.Errorf("the promo code is not valid for this service")
+	}
+
+	if !promotion.AppliesToSlot(slot) {
+		return fmt.Errorf("the promo code is not valid for this time slot")
+	}
+
+	return nil
+}
+
+// Why the customer can not use the promotion anymore, nil if they can
+func CheckLimits(promotion domain.Promotion, customerUses int, hasVisited bool) error {
+	if promotion.FirstVisitOnly && hasVisited {
+		return fmt.Errorf("the promo code is only valid for the first visit")
+	}
+
+	if promotion.MaxUses != nil && promotion.Uses >= *promotion.MaxUses {
+		return fmt.Errorf("the promo code has been used up")
+	}
+
+	if promotion.MaxUsesPerCustomer != nil && customerUses >= *promotion.MaxUsesPerCustomer {
+		return fmt.Errorf("you have already used this promo code")
+	}
+
+	return nil
+}
+
+// The price after the discount, rounded to the precision of its currency. A fixed
+// discount larger than the price makes it free, the price never goes below zero
+func DiscountedPrice(promotion domain.Promotion, price currencyx.Price) (currencyx.Price, error) {
+	var discount currency.Amount
+	var err error
+
+	switch {
+	case promotion.Percent != nil:
+		discount, err = price.Mul(*promotion.Percent)
+		if err != nil {
+			return currencyx.Price{}, err
+		}
+
+		discount, err = discount.Div("100")
+		if err != nil {
+			return currencyx.Price{}, err
+		}
+
+		discount, err = currencyx.Round(discount)
+		if err != nil {
+			return currencyx.Price{}, err
+		}
+
+	case promotion.Amount != nil:
+		if promotion.Amount.CurrencyCode() != price.CurrencyCode() {
+			return currencyx.Price{}, fmt.Errorf("the currency of the discount does not match the currency of the price")
+		}
+
+		discount = promotion.Amount.Amount
+
+	default:
+		return currencyx.Price{}, fmt.Errorf("the promotion has no discount")
+	}
+
+	discounted, err := price.Sub(discount)
+	if err != nil {
+		return currencyx.Price{}, err
+	}
+
+	if discounted.IsNegative() {
+		zero, err := currency.NewAmount("0", price.CurrencyCode())
+		if err != nil {
+			return currencyx.Price{}, err
+		}
+
+		return currencyx.Price{Amount: zero}, nil
+	}
+
+	return currencyx.Price{Amount: discounted}, nil
+}
@@ -0,0 +1,293 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+)
+
+type Service struct {
+	promotionRepo domain.PromotionRepository
+	merchantRepo  domain.MerchantRepository
+	txManager     db.TransactionManager
+}
+
+func NewService(promotion domain.PromotionRepository, merchant domain.MerchantRepository, txManager db.TransactionManager) *Service {
+	return &Service{
+		promotionRepo: promotion,
+		merchantRepo:  merchant,
+		txManager:     txManager,
+	}
+}
+
+type Input struct {
+	Name string
+	// nil for automatic rules, which are applied when the customer books without a code
+	Code *string
+	// exactly one of them has to be set
+	Percent            *string
+	Amount             *currencyx.Price
+	FirstVisitOnly     bool
+	MaxUses            *int
+	MaxUsesPerCustomer *int
+	ValidFrom          *time.Time
+	ValidUntil         *time.Time
+	// 0 is sunday, every day if it's empty
+	Weekdays []int
+	// like 14:00, in the timezone of the merchant. The whole day if they are missing
+	StartTime   *string
+	EndTime     *string
+	IsActive    bool
+	ServiceIds  []int
+	CategoryIds []int
+}
+
+// Parses a time of the day like 14:00 into minutes since midnight, 24:00 is the end of the day
+func parseMinute(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of the day: %s", value)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// Formats the minutes since midnight of a promotion like 14:00
+func FormatMinute(minute *int) *string {
+	if minute == nil {
+		return nil
+	}
+
+	formatted := formatMinute(*minute)
+	return &formatted
+}
+
+// Validates the input and turns it into a promotion of the merchant
+func (input Input) toPromotion(merchantCurrency string) (domain.Promotion, error) {
+	promotion := domain.Promotion{
+		Name:               strings.TrimSpace(input.Name),
+		Percent:            input.Percent,
+		Amount:             input.Amount,
+		FirstVisitOnly:     input.FirstVisitOnly,
+		MaxUses:            input.MaxUses,
+		MaxUsesPerCustomer: input.MaxUsesPerCustomer,
+		ValidFrom:          input.ValidFrom,
+		ValidUntil:         input.ValidUntil,
+		IsActive:           input.IsActive,
+		ServiceIds:         uniqueIds(input.ServiceIds),
+		CategoryIds:        uniqueIds(input.CategoryIds),
+		Weekdays:           uniqueIds(input.Weekdays),
+	}
+
+	if promotion.Name == "" {
+		return domain.Promotion{}, fmt.Errorf("the name of the promotion can not be empty")
+	}
+
+	if input.Code != nil {
+		code, err := NormalizeCode(*input.Code)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+
+		promotion.Code = &code
+	}
+
+	if (input.Percent == nil) == (input.Amount == nil) {
+		return domain.Promotion{}, fmt.Errorf("the discount has to be either a percent or a fixed amount")
+	}
+
+	if input.Percent != nil {
+		if err := validatePercent(*input.Percent); err != nil {
+			return domain.Promotion{}, err
+		}
+	}
+
+	if input.Amount != nil {
+		if input.Amount.CurrencyCode() != merchantCurrency {
+			return domain.Promotion{}, fmt.Errorf("the discount has to be in the currency of the merchant")
+		}
+
+		if !input.Amount.IsPositive() {
+			return domain.Promotion{}, fmt.Errorf("the discount has to be positive")
+		}
+
+		rounded, err := currencyx.Round(input.Amount.Amount)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+
+		if !rounded.Equal(input.Amount.Amount) {
+			return domain.Promotion{}, fmt.Errorf("the discount has too many decimals for its currency")
+		}
+	}
+
+	if input.MaxUses != nil && *input.MaxUses <= 0 {
+		return domain.Promotion{}, fmt.Errorf("the usage limit has to be positive")
+	}
+
+	if input.MaxUsesPerCustomer != nil && *input.MaxUsesPerCustomer <= 0 {
+		return domain.Promotion{}, fmt.Errorf("the usage limit per customer has to be positive")
+	}
+
+	if input.ValidFrom != nil && input.ValidUntil != nil && !input.ValidUntil.After(*input.ValidFrom) {
+		return domain.Promotion{}, fmt.Errorf("the end of the validity has to be after its start")
+	}
+
+	for _, day := range promotion.Weekdays {
+		if day < 0 || day > 6 {
+			return domain.Promotion{}, fmt.Errorf("invalid day of the week: %d", day)
+		}
+	}
+
+	if (input.StartTime == nil) != (input.EndTime == nil) {
+		return domain.Promotion{}, fmt.Errorf("both the start and the end of the time slot have to be given")
+	}
+
+	if input.StartTime != nil && input.EndTime != nil {
+		start, err := parseMinute(*input.StartTime)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+
+		end, err := parseMinute(*input.EndTime)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+
+		if start >= end {
+			return domain.Promotion{}, fmt.Errorf("the end of the time slot has to be after its start")
+		}
+
+		promotion.StartMinute = &start
+		promotion.EndMinute = &end
+	}
+
+	return promotion, nil
+}
+
+// Sorted ids without duplicates, never nil so an empty list clears every id when saved
+func uniqueIds(ids []int) []int {
+	unique := append([]int{}, ids...)
+	slices.Sort(unique)
+
+	return slices.Compact(unique)
+}
+
+// Saves the promotion and checks that every service and category of it belongs to the merchant
+func (s *Service) savePromotion(ctx context.Context, promotion domain.Promotion, save func(tx pgx.Tx) (int, error)) (int, error) {
+	var promotionId int
+
+	err := s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+
+		promotionId, err = save(tx)
+		if err != nil {
+			return err
+		}
+
+		saved, err := s.promotionRepo.WithTx(tx).GetPromotion(ctx, promotion.MerchantId, promotionId)
+		if err != nil {
+			return err
+		}
+
+		if len(saved.ServiceIds) != len(promotion.ServiceIds) {
+			return fmt.Errorf("service not found")
+		}
+
+		if len(saved.CategoryIds) != len(promotion.CategoryIds) {
+			return fmt.Errorf("category not found")
+		}
+
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("promotion not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "unique_promotion_code" {
+		return 0, fmt.Errorf("a promotion with this code already exists")
+	}
+
+	return promotionId, err
+}
+
+func (s *Service) newPromotion(ctx context.Context, input Input) (domain.Promotion, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	merchantCurrency, err := s.merchantRepo.GetMerchantCurrency(ctx, employee.MerchantId)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	promotion, err := input.toPromotion(merchantCurrency)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	promotion.MerchantId = employee.MerchantId
+
+	return promotion, nil
+}
+
+func (s *Service) New(ctx context.Context, input Input) (int, error) {
+	promotion, err := s.newPromotion(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.savePromotion(ctx, promotion, func(tx pgx.Tx) (int, error) {
+		return s.promotionRepo.WithTx(tx).NewPromotion(ctx, promotion)
+	})
+}
+
+// Changing a promotion does not change the price of the bookings it was already used for
+func (s *Service) Update(ctx context.Context, promotionId int, input Input) error {
+	promotion, err := s.newPromotion(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	promotion.Id = promotionId
+
+	_, err = s.savePromotion(ctx, promotion, func(tx pgx.Tx) (int, error) {
+		return promotionId, s.promotionRepo.WithTx(tx).UpdatePromotion(ctx, promotion)
+	})
+
+	return err
+}
+
+func (s *Service) GetAll(ctx context.Context) ([]domain.Promotion, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.promotionRepo.GetPromotions(ctx, employee.MerchantId)
+}
+
+func (s *Service) Get(ctx context.Context, promotionId int) (domain.Promotion, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	promotion, err := s.promotionRepo.GetPromotion(ctx, employee.MerchantId, promotionId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Promotion{}, fmt.Errorf("promotion not found")
+	}
+
+	return promotion, err
+}
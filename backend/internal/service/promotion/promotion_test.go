@@ -0,0 +1,200 @@
+package promotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestNormalizeCode(t *testing.T) {
+	code, err := NormalizeCode("  summer-25 ")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "SUMMER-25", code)
+
+	for _, invalid := range []string{"", "AB", "SUMMER 25", "NYÁR", "THIS-CODE-IS-WAY-TOO-LONG-TO-BE-VALID"} {
+		_, err := NormalizeCode(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestValidatePercent(t *testing.T) {
+	for _, valid := range []string{"10", "12.5", "99.99", "100", "100.00", "0.01"} {
+		assert.NoError(t, validatePercent(valid), valid)
+	}
+
+	for _, invalid := range []string{"0", "0.00", "-5", "100.01", "150", "12.345", "abc", ""} {
+		assert.Error(t, validatePercent(invalid), invalid)
+	}
+}
+
+func TestInputToPromotion(t *testing.T) {
+	valid := Input{
+		Name:       "Happy hour",
+		Percent:    ptr("20"),
+		Weekdays:   []int{5, 1, 1},
+		StartTime:  ptr("14:00"),
+		EndTime:    ptr("24:00"),
+		IsActive:   true,
+		ServiceIds: []int{3},
+	}
+
+	promotion, err := valid.toPromotion("HUF")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, promotion.Code)
+	assert.Equal(t, []int{1, 5}, promotion.Weekdays)
+	assert.Equal(t, 14*60, *promotion.StartMinute)
+	assert.Equal(t, 24*60, *promotion.EndMinute)
+	assert.NotNil(t, promotion.CategoryIds)
+
+	withCode := valid
+	withCode.Code = ptr(" welcome10 ")
+	withCode.Percent = nil
+	withCode.Amount = ptr(currencyxtest.Price(t, "1000", "HUF"))
+
+	promotion, err = withCode.toPromotion("HUF")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "WELCOME10", *promotion.Code)
+
+	validFrom := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := validFrom.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		modify func(input *Input)
+	}{
+		{"empty name", func(input *Input) { input.Name = " " }},
+		{"invalid code", func(input *Input) { input.Code = ptr("a b") }},
+		{"no discount", func(input *Input) { input.Percent = nil }},
+		{"both discounts", func(input *Input) { input.Amount = ptr(currencyxtest.Price(t, "1000", "HUF")) }},
+		{"too large percent", func(input *Input) { input.Percent = ptr("120") }},
+		{"other currency", func(input *Input) { input.Percent = nil; input.Amount = ptr(currencyxtest.Price(t, "10", "EUR")) }},
+		{"negative amount", func(input *Input) { input.Percent = nil; input.Amount = ptr(currencyxtest.Price(t, "-10", "HUF")) }},
+		{"too many decimals", func(input *Input) { input.Percent = nil; input.Amount = ptr(currencyxtest.Price(t, "10.5", "HUF")) }},
+		{"zero max uses", func(input *Input) { input.MaxUses = ptr(0) }},
+		{"zero max uses per customer", func(input *Input) { input.MaxUsesPerCustomer = ptr(0) }},
+		{"validity ends before start", func(input *Input) { input.ValidFrom = &validFrom; input.ValidUntil = &validUntil }},
+		{"invalid weekday", func(input *Input) { input.Weekdays = []int{7} }},
+		{"missing end time", func(input *Input) { input.EndTime = nil }},
+		{"invalid start time", func(input *Input) { input.StartTime = ptr("25:00") }},
+		{"end before start", func(input *Input) { input.StartTime = ptr("18:00"); input.EndTime = ptr("10:00") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+
+			_, err := input.toPromotion("HUF")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCheckApplicable(t *testing.T) {
+	now := time.Date(2026, 6, 10, 9, 0, 0, 0, time.UTC)
+	// a wednesday afternoon
+	slot := time.Date(2026, 6, 17, 15, 30, 0, 0, time.UTC)
+	validUntil := now.Add(-time.Hour)
+	categoryId := 7
+
+	promotion := domain.Promotion{
+		Percent:     ptr("20"),
+		Weekdays:    []int{1, 2, 3},
+		StartMinute: ptr(14 * 60),
+		EndMinute:   ptr(17 * 60),
+		IsActive:    true,
+		ServiceIds:  []int{3},
+		CategoryIds: []int{categoryId},
+	}
+
+	assert.NoError(t, CheckApplicable(promotion, 3, nil, slot, now))
+	assert.NoError(t, CheckApplicable(promotion, 4, &categoryId, slot, now))
+
+	inactive := promotion
+	inactive.IsActive = false
+	assert.Error(t, CheckApplicable(inactive, 3, nil, slot, now))
+
+	expired := promotion
+	expired.ValidUntil = &validUntil
+	assert.Error(t, CheckApplicable(expired, 3, nil, slot, now))
+
+	assert.Error(t, CheckApplicable(promotion, 4, nil, slot, now), "other service")
+	assert.Error(t, CheckApplicable(promotion, 3, nil, slot.AddDate(0, 0, 3), now), "saturday")
+	assert.Error(t, CheckApplicable(promotion, 3, nil, slot.Add(2*time.Hour), now), "after the end minute")
+
+	everything := domain.Promotion{Amount: ptr(currencyxtest.Price(t, "1000", "HUF")), IsActive: true}
+	assert.NoError(t, CheckApplicable(everything, 4, nil, slot.AddDate(0, 0, 3), now))
+}
+
+func TestCheckLimits(t *testing.T) {
+	promotion := domain.Promotion{
+		FirstVisitOnly:     true,
+		MaxUses:            ptr(10),
+		MaxUsesPerCustomer: ptr(1),
+		Uses:               9,
+	}
+
+	assert.NoError(t, CheckLimits(promotion, 0, false))
+	assert.Error(t, CheckLimits(promotion, 0, true), "returning customer")
+	assert.Error(t, CheckLimits(promotion, 1, false), "used by the customer")
+
+	usedUp := promotion
+	usedUp.Uses = 10
+	assert.Error(t, CheckLimits(usedUp, 0, false))
+
+	unlimited := domain.Promotion{Uses: 1000}
+	assert.NoError(t, CheckLimits(unlimited, 1000, true))
+}
+
+func TestDiscountedPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion domain.Promotion
+		price     currencyx.Price
+		expected  currencyx.Price
+	}{
+		{"percent", domain.Promotion{Percent: ptr("20")}, currencyxtest.Price(t, "12000", "HUF"), currencyxtest.Price(t, "9600", "HUF")},
+		{"percent rounded", domain.Promotion{Percent: ptr("15")}, currencyxtest.Price(t, "19.99", "EUR"), currencyxtest.Price(t, "16.99", "EUR")},
+		{"whole price", domain.Promotion{Percent: ptr("100")}, currencyxtest.Price(t, "5000", "HUF"), currencyxtest.Price(t, "0", "HUF")},
+		{"fixed", domain.Promotion{Amount: ptr(currencyxtest.Price(t, "2.50", "EUR"))}, currencyxtest.Price(t, "20", "EUR"), currencyxtest.Price(t, "17.50", "EUR")},
+		{"fixed larger than price", domain.Promotion{Amount: ptr(currencyxtest.Price(t, "5000", "HUF"))}, currencyxtest.Price(t, "3000", "HUF"), currencyxtest.Price(t, "0", "HUF")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discounted, err := DiscountedPrice(tt.promotion, tt.price)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.True(t, discounted.Equal(tt.expected.Amount), "expected %s, got %s", tt.expected, discounted)
+			assert.Equal(t, tt.price.CurrencyCode(), discounted.CurrencyCode())
+		})
+	}
+
+	_, err := DiscountedPrice(domain.Promotion{Amount: ptr(currencyxtest.Price(t, "10", "EUR"))}, currencyxtest.Price(t, "3000", "HUF"))
+	assert.Error(t, err, "currency mismatch")
+
+	_, err = DiscountedPrice(domain.Promotion{}, currencyxtest.Price(t, "3000", "HUF"))
+	assert.Error(t, err, "no discount")
+}
+
+func TestFormatMinute(t *testing.T) {
+	assert.Nil(t, FormatMinute(nil))
+	assert.Equal(t, "09:05", *FormatMinute(ptr(9*60 + 5)))
+	assert.Equal(t, "24:00", *FormatMinute(ptr(24 * 60)))
+}
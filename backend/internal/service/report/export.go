@@ -0,0 +1,432 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/cmd/config"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/csvx"
+	"github.com/miketsu-inc/reservations/backend/pkg/xlsx"
+	"github.com/riverqueue/river"
+)
+
+// how long the file can be downloaded after it was made
+const reportExportExpiry = 7 * 24 * time.Hour
+
+type ExportInput struct {
+	Dataset types.ReportExportDataset
+	Format  types.ReportExportFormat
+	// Inclusive days in the timezone of the merchant
+	Start time.Time
+	End   time.Time
+}
+
+// Schedules the export of the dataset, returns the id of the export
+func (s *Service) RequestExport(ctx context.Context, input ExportInput) (int, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	period := domain.ReportPeriod{Start: toDay(input.Start), End: toDay(input.End)}
+
+	if period.End.Before(period.Start) {
+		return 0, fmt.Errorf("the end of the export can not be before its start")
+	}
+
+	if days(period) > maxReportDays {
+		return 0, fmt.Errorf("an export can cover at most %d days", maxReportDays)
+	}
+
+	var exportId int
+
+	err := s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+
+		exportId, err = s.reportRepo.WithTx(tx).NewReportExport(ctx, domain.NewReportExport{
+			MerchantId: employee.MerchantId,
+			EmployeeId: employee.EmployeeId,
+			Dataset:    input.Dataset,
+			Format:     input.Format,
+			StartDay:   period.Start,
+			EndDay:     period.End,
+			IncludePii: employee.HasPermission(types.PermissionCustomersViewPii),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.ReportExport{
+			MerchantId: employee.MerchantId,
+			ExportId:   exportId,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule report export job: %w", err)
+		}
+
+		return nil
+	})
+
+	return exportId, err
+}
+
+// Exports with the contact details of the customers are only visible to employees who can see them
+func canSeeExport(employee actor.EmployeeContext, export domain.ReportExport) bool {
+	return !export.IncludePii || employee.HasPermission(types.PermissionCustomersViewPii)
+}
+
+func (s *Service) GetExports(ctx context.Context) ([]domain.ReportExport, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	exports, err := s.reportRepo.GetReportExports(ctx, employee.MerchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []domain.ReportExport{}
+	for _, export := range exports {
+		if canSeeExport(employee, export) {
+			visible = append(visible, export)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *Service) GetExport(ctx context.Context, exportId int) (domain.ReportExport, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	export, err := s.reportRepo.GetReportExport(ctx, employee.MerchantId, exportId)
+	if err != nil {
+		return domain.ReportExport{}, err
+	}
+
+	if !canSeeExport(employee, export) {
+		return domain.ReportExport{}, fmt.Errorf("export not found")
+	}
+
+	return export, nil
+}
+
+// an empty secret would let anyone sign a download link
+var errMissingLinkSecret = errors.New("download links are not configured")
+
+type exportToken struct {
+	MerchantId uuid.UUID `json:"merchant_id"`
+	ExportId   int       `json:"export_id"`
+	ExpiresAt  int64     `json:"expires_at"`
+}
+
+func signToken(payload []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// The token of the download link of a completed export, it is valid until the export expires
+func newExportToken(export domain.ReportExport, secret string) (string, error) {
+	if secret == "" {
+		return "", errMissingLinkSecret
+	}
+
+	if export.Status != types.DataExportCompleted || export.ExpiresAt == nil {
+		return "", fmt.Errorf("the export is not ready yet")
+	}
+
+	payload, err := json.Marshal(exportToken{
+		MerchantId: export.MerchantId,
+		ExportId:   export.Id,
+		ExpiresAt:  export.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(append(payload, signToken(payload, secret)...)), nil
+}
+
+func parseExportToken(encoded string, secret string, now time.Time) (exportToken, error) {
+	if secret == "" {
+		return exportToken{}, errMissingLinkSecret
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < sha256.Size {
+		return exportToken{}, fmt.Errorf("invalid download link")
+	}
+
+	payload := data[:len(data)-sha256.Size]
+	signature := data[len(data)-sha256.Size:]
+
+	if !hmac.Equal(signature, signToken(payload, secret)) {
+		return exportToken{}, fmt.Errorf("invalid download link")
+	}
+
+	var token exportToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return exportToken{}, fmt.Errorf("invalid download link")
+	}
+
+	if now.Unix() > token.ExpiresAt {
+		return exportToken{}, fmt.Errorf("the download link has expired")
+	}
+
+	return token, nil
+}
+
+// Signed link which downloads the file of the export without logging in
+func (s *Service) ExportDownloadLink(export domain.ReportExport) (string, error) {
+	token, err := newExportToken(export, config.LoadEnvVars().DOWNLOAD_LINK_SECRET)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("http://reservations.local:3000/api/v1/public/report-exports/%s", token), nil
+}
+
+// Returns the file of the export the signed token belongs to, if it did not expire yet
+func (s *Service) DownloadExport(ctx context.Context, token string) (domain.ReportExport, []byte, error) {
+	parsed, err := parseExportToken(token, config.LoadEnvVars().DOWNLOAD_LINK_SECRET, time.Now())
+	if err != nil {
+		return domain.ReportExport{}, nil, err
+	}
+
+	export, err := s.reportRepo.GetReportExport(ctx, parsed.MerchantId, parsed.ExportId)
+	if err != nil {
+		return domain.ReportExport{}, nil, err
+	}
+
+	file, err := s.reportRepo.GetReportExportFile(ctx, parsed.MerchantId, parsed.ExportId)
+	if err != nil {
+		return domain.ReportExport{}, nil, fmt.Errorf("the export is not ready or it has expired: %s", err.Error())
+	}
+
+	return export, file, nil
+}
+
+// Writes the dataset of the export into a file, a retried job starts over
+func (s *Service) RunExport(ctx context.Context, merchantId uuid.UUID, exportId int) error {
+	export, err := s.reportRepo.GetReportExport(ctx, merchantId, exportId)
+	if err != nil {
+		return err
+	}
+
+	if export.Status == types.DataExportCompleted || export.Status == types.DataExportFailed {
+		return nil
+	}
+
+	err = s.reportRepo.UpdateReportExport(ctx, exportId, domain.DataExportResult{Status: types.DataExportRunning})
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.exportRows(ctx, export)
+	if err != nil {
+		return err
+	}
+
+	file, err := writeExportFile(export, rows)
+	if err != nil {
+		// the same data would fail again
+		message := err.Error()
+
+		if err := s.reportRepo.UpdateReportExport(ctx, exportId, domain.DataExportResult{
+			Status: types.DataExportFailed,
+			Error:  &message,
+		}); err != nil {
+			return err
+		}
+
+		return river.JobCancel(err)
+	}
+
+	expiresAt := time.Now().UTC().Add(reportExportExpiry)
+
+	return s.reportRepo.UpdateReportExport(ctx, exportId, domain.DataExportResult{
+		Status:    types.DataExportCompleted,
+		File:      file,
+		ExpiresAt: &expiresAt,
+	})
+}
+
+func (s *Service) DeleteExpiredExports(ctx context.Context) error {
+	return s.reportRepo.DeleteExpiredReportExports(ctx, time.Now().UTC())
+}
+
+func (s *Service) exportRows(ctx context.Context, export domain.ReportExport) ([][]string, error) {
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, export.MerchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	period := domain.ReportPeriod{Start: toDay(export.StartDay), End: toDay(export.EndDay)}
+	from, to := localBounds(period, merchantTz)
+
+	switch export.Dataset {
+	case types.ReportExportDatasetBookings:
+		bookings, err := s.reportRepo.GetExportBookings(ctx, export.MerchantId, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		return bookingExportRows(bookings, merchantTz), nil
+	case types.ReportExportDatasetParticipants:
+		participants, err := s.reportRepo.GetExportParticipants(ctx, export.MerchantId, from, to, export.IncludePii)
+		if err != nil {
+			return nil, err
+		}
+
+		return participantExportRows(participants, merchantTz, export.IncludePii), nil
+	case types.ReportExportDatasetRevenue:
+		currencyCode, err := s.merchantRepo.GetMerchantCurrency(ctx, export.MerchantId)
+		if err != nil {
+			return nil, err
+		}
+
+		groupBy := types.ReportGroupByService
+		reportRows, err := s.reportRepo.GetReportRows(ctx, export.MerchantId, currencyCode, period.Start, period.End, &groupBy)
+		if err != nil {
+			return nil, err
+		}
+
+		return revenueExportRows(reportRows, currencyCode), nil
+	default:
+		return nil, fmt.Errorf("unknown export dataset: %s", export.Dataset.String())
+	}
+}
+
+func writeExportFile(export domain.ReportExport, rows [][]string) ([]byte, error) {
+	var file bytes.Buffer
+	var err error
+
+	if export.Format == types.ReportExportFormatXlsx {
+		err = xlsx.Write(&file, export.Dataset.String(), rows)
+	} else {
+		err = csvx.Write(&file, rows)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create export file: %s", err.Error())
+	}
+
+	return file.Bytes(), nil
+}
+
+// The name the file of the export is downloaded as
+func ExportFileName(export domain.ReportExport) string {
+	return fmt.Sprintf("%s-%s-%s.%s", export.Dataset.String(), export.StartDay.Format(time.DateOnly),
+		export.EndDay.Format(time.DateOnly), export.Format.String())
+}
+
+const exportTimeLayout = "2006-01-02 15:04"
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func formatOptionalTime(t *time.Time, tz *time.Location) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.In(tz).Format(exportTimeLayout)
+}
+
+func bookingExportRows(bookings []domain.ExportBooking, tz *time.Location) [][]string {
+	rows := [][]string{{"Booking ID", "Status", "Type", "Start", "End", "Service", "Employee", "Location", "Participants",
+		"Price per person", "Total price", "Currency", "Cancelled by merchant"}}
+
+	for _, booking := range bookings {
+		rows = append(rows, []string{
+			strconv.Itoa(booking.Id),
+			booking.Status.String(),
+			booking.BookingType.String(),
+			booking.FromDate.In(tz).Format(exportTimeLayout),
+			booking.ToDate.In(tz).Format(exportTimeLayout),
+			booking.ServiceName,
+			valueOrEmpty(booking.EmployeeName),
+			booking.Location,
+			strconv.Itoa(booking.Participants),
+			booking.PricePerPerson.Number(),
+			booking.TotalPrice.Number(),
+			booking.TotalPrice.CurrencyCode(),
+			strconv.FormatBool(booking.CancelledByMerchant),
+		})
+	}
+
+	return rows
+}
+
+// The email and phone number columns are left out unless includePii is set
+func participantExportRows(participants []domain.ExportParticipant, tz *time.Location, includePii bool) [][]string {
+	header := []string{"Booking ID", "Start", "Service", "Customer ID", "First name", "Last name"}
+	if includePii {
+		header = append(header, "Email", "Phone number")
+	}
+
+	rows := [][]string{append(header, "Status", "Price", "Currency", "Cancelled on")}
+
+	for _, participant := range participants {
+		customerId := ""
+		if participant.CustomerId != nil {
+			customerId = participant.CustomerId.String()
+		}
+
+		row := []string{
+			strconv.Itoa(participant.BookingId),
+			participant.FromDate.In(tz).Format(exportTimeLayout),
+			participant.ServiceName,
+			customerId,
+			valueOrEmpty(participant.FirstName),
+			valueOrEmpty(participant.LastName),
+		}
+
+		if includePii {
+			row = append(row, valueOrEmpty(participant.Email), valueOrEmpty(participant.PhoneNumber))
+		}
+
+		rows = append(rows, append(row,
+			participant.Status.String(),
+			participant.Price.Number(),
+			participant.Price.CurrencyCode(),
+			formatOptionalTime(participant.CancelledOn, tz),
+		))
+	}
+
+	return rows
+}
+
+func revenueExportRows(reportRows []domain.ReportRow, currencyCode string) [][]string {
+	rows := [][]string{{"Day", "Service", "Bookings", "Cancellations", "No-shows", "Participants", "Revenue", "Currency", "Booked minutes"}}
+
+	for _, row := range reportRows {
+		rows = append(rows, []string{
+			row.Day.Format(time.DateOnly),
+			valueOrEmpty(row.GroupName),
+			strconv.Itoa(row.Bookings),
+			strconv.Itoa(row.Cancellations),
+			strconv.Itoa(row.NoShows),
+			strconv.Itoa(row.Participants),
+			row.Revenue,
+			currencyCode,
+			strconv.Itoa(row.BookedMinutes),
+		})
+	}
+
+	return rows
+}
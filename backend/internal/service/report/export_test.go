@@ -0,0 +1,110 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportToken(t *testing.T) {
+	expiresAt := day(2026, 3, 8)
+	export := domain.ReportExport{
+		Id:         7,
+		MerchantId: uuid.New(),
+		Status:     types.DataExportCompleted,
+		ExpiresAt:  &expiresAt,
+	}
+
+	token, err := newExportToken(export, "secret")
+	assert.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		parsed, err := parseExportToken(token, "secret", day(2026, 3, 2))
+		assert.NoError(t, err)
+		assert.Equal(t, export.MerchantId, parsed.MerchantId)
+		assert.Equal(t, export.Id, parsed.ExportId)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := parseExportToken(token, "secret", day(2026, 3, 9))
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := parseExportToken(token, "other", day(2026, 3, 2))
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := []byte(token)
+		tampered[5] ^= 1
+
+		_, err := parseExportToken(string(tampered), "secret", day(2026, 3, 2))
+		assert.Error(t, err)
+	})
+
+	t.Run("not completed", func(t *testing.T) {
+		_, err := newExportToken(domain.ReportExport{Id: 7, Status: types.DataExportRunning}, "secret")
+		assert.Error(t, err)
+	})
+
+	t.Run("empty secret", func(t *testing.T) {
+		_, err := newExportToken(export, "")
+		assert.ErrorIs(t, err, errMissingLinkSecret)
+
+		_, err = parseExportToken(token, "", day(2026, 3, 2))
+		assert.ErrorIs(t, err, errMissingLinkSecret)
+	})
+}
+
+func TestRevenueExportRows(t *testing.T) {
+	cut := "Haircut"
+	rows := revenueExportRows([]domain.ReportRow{
+		{Day: day(2026, 3, 1), GroupName: &cut, Bookings: 3, Participants: 3, Revenue: "60.00", BookedMinutes: 90},
+	}, "EUR")
+
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []string{"2026-03-01", "Haircut", "3", "0", "0", "3", "60.00", "EUR", "90"}, rows[1])
+}
+
+func TestParticipantExportRows(t *testing.T) {
+	first, last, email, phone := "Anna", "Kiss", "anna@example.com", "+36301234567"
+	participants := []domain.ExportParticipant{{
+		BookingId:   7,
+		FromDate:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		ServiceName: "Haircut",
+		FirstName:   &first,
+		LastName:    &last,
+		Email:       &email,
+		PhoneNumber: &phone,
+		Status:      types.BookingStatusBooked,
+		Price:       currencyxtest.Price(t, "20", "EUR"),
+	}}
+
+	withPii := participantExportRows(participants, time.UTC, true)
+	assert.Contains(t, withPii[0], "Email")
+	assert.Contains(t, withPii[1], email)
+	assert.Len(t, withPii[1], len(withPii[0]))
+
+	withoutPii := participantExportRows(participants, time.UTC, false)
+	assert.NotContains(t, withoutPii[0], "Email")
+	assert.NotContains(t, withoutPii[0], "Phone number")
+	assert.NotContains(t, withoutPii[1], email)
+	assert.NotContains(t, withoutPii[1], phone)
+	assert.Len(t, withoutPii[1], len(withoutPii[0]))
+}
+
+func TestCanSeeExport(t *testing.T) {
+	viewer := actor.EmployeeContext{Permissions: []types.Permission{types.PermissionReportsView}}
+	piiViewer := actor.EmployeeContext{Permissions: []types.Permission{types.PermissionReportsView, types.PermissionCustomersViewPii}}
+
+	assert.True(t, canSeeExport(viewer, domain.ReportExport{}))
+	assert.False(t, canSeeExport(viewer, domain.ReportExport{IncludePii: true}))
+	assert.True(t, canSeeExport(piiViewer, domain.ReportExport{IncludePii: true}))
+}
@@ -0,0 +1,377 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/internal/utils"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
+)
+
+// A report can cover two years, so it can be compared to the two years before
+const maxReportDays = 2 * 366
+
+type Service struct {
+	reportRepo   domain.ReportRepository
+	merchantRepo domain.MerchantRepository
+	teamRepo     domain.TeamRepository
+	mailer       *email.Service
+	enqueuer     queue.Enqueuer
+	txManager    db.TransactionManager
+}
+
+func NewService(report domain.ReportRepository, merchant domain.MerchantRepository, team domain.TeamRepository, mailer *email.Service,
+	enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	return &Service{
+		reportRepo:   report,
+		merchantRepo: merchant,
+		teamRepo:     team,
+		mailer:       mailer,
+		enqueuer:     enqueuer,
+		txManager:    txManager,
+	}
+}
+
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
+type ReportInput struct {
+	// Inclusive days in the timezone of the merchant
+	Start       time.Time
+	End         time.Time
+	Granularity types.ReportGranularity
+	GroupBy     *types.ReportGroupBy
+}
+
+func (s *Service) GetReport(ctx context.Context, input ReportInput) (domain.Report, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.GetMerchantReport(ctx, employee.MerchantId, input)
+}
+
+// The report of the merchant compared to the period right before it
+func (s *Service) GetMerchantReport(ctx context.Context, merchantId uuid.UUID, input ReportInput) (domain.Report, error) {
+	period := domain.ReportPeriod{Start: toDay(input.Start), End: toDay(input.End)}
+
+	if period.End.Before(period.Start) {
+		return domain.Report{}, fmt.Errorf("the end of the report can not be before its start")
+	}
+
+	if days(period) > maxReportDays {
+		return domain.Report{}, fmt.Errorf("a report can cover at most %d days", maxReportDays)
+	}
+
+	if input.Granularity == (types.ReportGranularity{}) {
+		return domain.Report{}, fmt.Errorf("the granularity of the report is required")
+	}
+
+	currencyCode, err := s.merchantRepo.GetMerchantCurrency(ctx, merchantId)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	previous := previousPeriod(period)
+
+	// the previous period ends right before the current one, so they can be read at once
+	rows, err := s.reportRepo.GetReportRows(ctx, merchantId, currencyCode, previous.Start, period.End, input.GroupBy)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	refreshedAt, err := s.reportRepo.GetRollupRefreshedAt(ctx, merchantId, previous.Start, period.End)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	report, err := buildReport(rows, period, input.Granularity, input.GroupBy, currencyCode, refreshedAt)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	taxRows, err := s.reportRepo.GetReportTaxes(ctx, merchantId, currencyCode, period.Start, period.End)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	report.Taxes, err = reportTaxes(taxRows, currencyCode)
+	if err != nil {
+		return domain.Report{}, err
+	}
+
+	return report, nil
+}
+
+// the tax of each booking is summed up unrounded, so the total is only rounded once per rate
+func reportTaxes(rows []domain.ReportTaxRow, currencyCode string) ([]domain.ReportTax, error) {
+	taxes := make([]domain.ReportTax, len(rows))
+
+	for i, row := range rows {
+		amount, err := currency.NewAmount(row.Tax, currencyCode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tax in report: %w", err)
+		}
+
+		rounded, err := currencyx.Round(amount)
+		if err != nil {
+			return nil, err
+		}
+
+		taxes[i] = domain.ReportTax{
+			Name:    row.Name,
+			Percent: row.Percent,
+			Amount:  rounded,
+		}
+	}
+
+	return taxes, nil
+}
+
+func buildReport(rows []domain.ReportRow, period domain.ReportPeriod, granularity types.ReportGranularity, groupBy *types.ReportGroupBy,
+	currencyCode string, refreshedAt *time.Time) (domain.Report, error) {
+	previous := previousPeriod(period)
+
+	report := domain.Report{
+		Period:         period,
+		PreviousPeriod: previous,
+		Granularity:    granularity,
+		GroupBy:        groupBy,
+		Currency:       currencyCode,
+		Current:        emptyMetrics(currencyCode),
+		Previous:       emptyMetrics(currencyCode),
+		Series:         emptySeries(period, granularity, currencyCode),
+		PreviousSeries: emptySeries(previous, granularity, currencyCode),
+		Groups:         []domain.ReportGroup{},
+		RefreshedAt:    refreshedAt,
+	}
+
+	for _, row := range rows {
+		metrics, err := rowMetrics(row, currencyCode)
+		if err != nil {
+			return domain.Report{}, err
+		}
+
+		isCurrent := !row.Day.Before(period.Start)
+
+		total, series := &report.Previous, report.PreviousSeries
+		if isCurrent {
+			total, series = &report.Current, report.Series
+		}
+
+		if err := addMetrics(total, metrics); err != nil {
+			return domain.Report{}, err
+		}
+
+		idx := slices.IndexFunc(series, func(b domain.ReportBucket) bool {
+			return !row.Day.Before(b.Period.Start) && !row.Day.After(b.Period.End)
+		})
+		if idx != -1 {
+			if err := addMetrics(&series[idx].Metrics, metrics); err != nil {
+				return domain.Report{}, err
+			}
+		}
+
+		if groupBy == nil {
+			continue
+		}
+
+		groupIdx := slices.IndexFunc(report.Groups, func(g domain.ReportGroup) bool { return equalIds(g.Id, row.GroupId) })
+		if groupIdx == -1 {
+			name := ""
+			if row.GroupName != nil {
+				name = *row.GroupName
+			}
+
+			report.Groups = append(report.Groups, domain.ReportGroup{
+				Id:       row.GroupId,
+				Name:     name,
+				Current:  emptyMetrics(currencyCode),
+				Previous: emptyMetrics(currencyCode),
+			})
+			groupIdx = len(report.Groups) - 1
+		}
+
+		group := &report.Groups[groupIdx]
+		groupTotal := &group.Previous
+		if isCurrent {
+			groupTotal = &group.Current
+		}
+
+		if err := addMetrics(groupTotal, metrics); err != nil {
+			return domain.Report{}, err
+		}
+	}
+
+	report.Changes = compareMetrics(report.Previous, report.Current)
+
+	for i := range report.Groups {
+		report.Groups[i].Changes = compareMetrics(report.Groups[i].Previous, report.Groups[i].Current)
+	}
+
+	slices.SortStableFunc(report.Groups, func(a, b domain.ReportGroup) int {
+		// every amount is in the currency of the report
+		if c, _ := a.Current.Revenue.Cmp(b.Current.Revenue); c != 0 {
+			return -c
+		}
+
+		return cmp.Or(cmp.Compare(b.Current.Bookings, a.Current.Bookings), cmp.Compare(a.Name, b.Name))
+	})
+
+	return report, nil
+}
+
+func equalIds(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+// Drops the time of the date, the date itself is kept as it is in its own location
+func toDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func days(period domain.ReportPeriod) int {
+	return int(period.End.Sub(period.Start).Hours()/24) + 1
+}
+
+func isWholeMonths(period domain.ReportPeriod) bool {
+	return period.Start.Day() == 1 && period.End.AddDate(0, 0, 1).Day() == 1
+}
+
+// The period of the same length right before the given one.
+// Whole calendar months are compared to the same number of months before them
+func previousPeriod(period domain.ReportPeriod) domain.ReportPeriod {
+	if isWholeMonths(period) {
+		months := (period.End.Year()-period.Start.Year())*12 + int(period.End.Month()-period.Start.Month()) + 1
+
+		return domain.ReportPeriod{
+			Start: period.Start.AddDate(0, -months, 0),
+			End:   period.Start.AddDate(0, 0, -1),
+		}
+	}
+
+	return domain.ReportPeriod{
+		Start: period.Start.AddDate(0, 0, -days(period)),
+		End:   period.Start.AddDate(0, 0, -1),
+	}
+}
+
+// The first day of the bucket the day belongs to, weeks start on monday
+func bucketStart(day time.Time, granularity types.ReportGranularity) time.Time {
+	switch granularity {
+	case types.ReportGranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case types.ReportGranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucketStart(start time.Time, granularity types.ReportGranularity) time.Time {
+	switch granularity {
+	case types.ReportGranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case types.ReportGranularityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Buckets covering the whole period, the first and the last one are cut to the period
+func emptySeries(period domain.ReportPeriod, granularity types.ReportGranularity, currencyCode string) []domain.ReportBucket {
+	series := []domain.ReportBucket{}
+
+	for start := bucketStart(period.Start, granularity); !start.After(period.End); start = nextBucketStart(start, granularity) {
+		bucket := domain.ReportPeriod{Start: start, End: nextBucketStart(start, granularity).AddDate(0, 0, -1)}
+
+		if bucket.Start.Before(period.Start) {
+			bucket.Start = period.Start
+		}
+
+		if bucket.End.After(period.End) {
+			bucket.End = period.End
+		}
+
+		series = append(series, domain.ReportBucket{Period: bucket, Metrics: emptyMetrics(currencyCode)})
+	}
+
+	return series
+}
+
+func emptyMetrics(currencyCode string) domain.ReportMetrics {
+	revenue, _ := currency.NewAmount("0", currencyCode)
+
+	return domain.ReportMetrics{Revenue: revenue}
+}
+
+func rowMetrics(row domain.ReportRow, currencyCode string) (domain.ReportMetrics, error) {
+	revenue, err := currency.NewAmount(row.Revenue, currencyCode)
+	if err != nil {
+		return domain.ReportMetrics{}, fmt.Errorf("invalid revenue in report: %w", err)
+	}
+
+	return domain.ReportMetrics{
+		Bookings:      row.Bookings,
+		Cancellations: row.Cancellations,
+		NoShows:       row.NoShows,
+		Participants:  row.Participants,
+		Revenue:       revenue,
+		BookedMinutes: row.BookedMinutes,
+	}, nil
+}
+
+func addMetrics(total *domain.ReportMetrics, metrics domain.ReportMetrics) error {
+	revenue, err := total.Revenue.Add(metrics.Revenue)
+	if err != nil {
+		return fmt.Errorf("could not sum the revenue of the report: %w", err)
+	}
+
+	total.Bookings += metrics.Bookings
+	total.Cancellations += metrics.Cancellations
+	total.NoShows += metrics.NoShows
+	total.Participants += metrics.Participants
+	total.Revenue = revenue
+	total.BookedMinutes += metrics.BookedMinutes
+
+	return nil
+}
+
+// whole units are precise enough for a percent change
+func revenueUnits(amount currency.Amount) int {
+	value, err := strconv.ParseFloat(amount.Number(), 64)
+	if err != nil {
+		return 0
+	}
+
+	return int(math.Round(value))
+}
+
+func compareMetrics(previous, current domain.ReportMetrics) domain.ReportChanges {
+	return domain.ReportChanges{
+		Bookings:        utils.CalculatePercentChange(previous.Bookings, current.Bookings),
+		Cancellations:   utils.CalculatePercentChange(previous.Cancellations, current.Cancellations),
+		NoShows:         utils.CalculatePercentChange(previous.NoShows, current.NoShows),
+		Participants:    utils.CalculatePercentChange(previous.Participants, current.Participants),
+		Revenue:         utils.CalculatePercentChange(revenueUnits(previous.Revenue), revenueUnits(current.Revenue)),
+		AverageDuration: utils.CalculatePercentChange(previous.AverageDuration(), current.AverageDuration()),
+	}
+}
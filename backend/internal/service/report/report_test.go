@@ -0,0 +1,105 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	// same number of days right before the period
+	previous := previousPeriod(domain.ReportPeriod{Start: day(2026, 3, 10), End: day(2026, 3, 16)})
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 3, 3), End: day(2026, 3, 9)}, previous)
+
+	// whole months are compared to whole months, even if they are shorter
+	previous = previousPeriod(domain.ReportPeriod{Start: day(2026, 3, 1), End: day(2026, 3, 31)})
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 2, 1), End: day(2026, 2, 28)}, previous)
+
+	previous = previousPeriod(domain.ReportPeriod{Start: day(2026, 1, 1), End: day(2026, 3, 31)})
+	assert.Equal(t, domain.ReportPeriod{Start: day(2025, 10, 1), End: day(2025, 12, 31)}, previous)
+}
+
+func TestEmptySeries(t *testing.T) {
+	period := domain.ReportPeriod{Start: day(2026, 3, 4), End: day(2026, 3, 17)}
+
+	assert.Len(t, emptySeries(period, types.ReportGranularityDay, "EUR"), 14)
+
+	// the weeks start on monday, the first and last are cut to the period
+	weeks := emptySeries(period, types.ReportGranularityWeek, "EUR")
+	assert.Len(t, weeks, 3)
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 3, 4), End: day(2026, 3, 8)}, weeks[0].Period)
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 3, 9), End: day(2026, 3, 15)}, weeks[1].Period)
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 3, 16), End: day(2026, 3, 17)}, weeks[2].Period)
+
+	months := emptySeries(domain.ReportPeriod{Start: day(2026, 1, 15), End: day(2026, 3, 2)}, types.ReportGranularityMonth, "EUR")
+	assert.Len(t, months, 3)
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 2, 1), End: day(2026, 2, 28)}, months[1].Period)
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 3, 1), End: day(2026, 3, 2)}, months[2].Period)
+}
+
+func TestBuildReport(t *testing.T) {
+	cut, color := 1, 2
+	cutName, colorName := "Cut", "Color"
+
+	period := domain.ReportPeriod{Start: day(2026, 3, 9), End: day(2026, 3, 22)}
+	groupBy := types.ReportGroupByService
+
+	rows := []domain.ReportRow{
+		{Day: day(2026, 3, 2), GroupId: &cut, GroupName: &cutName, Bookings: 2, Participants: 2, Revenue: "20", BookedMinutes: 60},
+		{Day: day(2026, 3, 9), GroupId: &cut, GroupName: &cutName, Bookings: 3, Cancellations: 1, Participants: 3, Revenue: "30.5", BookedMinutes: 90},
+		{Day: day(2026, 3, 16), GroupId: &color, GroupName: &colorName, Bookings: 2, NoShows: 1, Participants: 1, Revenue: "60", BookedMinutes: 120},
+		{Day: day(2026, 3, 18), GroupId: nil, Bookings: 1, Participants: 1, Revenue: "5", BookedMinutes: 30},
+	}
+
+	report, err := buildReport(rows, period, types.ReportGranularityWeek, &groupBy, "EUR", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.ReportPeriod{Start: day(2026, 2, 23), End: day(2026, 3, 8)}, report.PreviousPeriod)
+
+	assert.Equal(t, 6, report.Current.Bookings)
+	assert.Equal(t, "95.5", report.Current.Revenue.Number())
+	assert.Equal(t, 48, report.Current.AverageDuration())
+	assert.Equal(t, 2, report.Previous.Bookings)
+	assert.Equal(t, 200, report.Changes.Bookings)
+	assert.Equal(t, 380, report.Changes.Revenue)
+
+	assert.Len(t, report.Series, 2)
+	assert.Equal(t, "30.5", report.Series[0].Metrics.Revenue.Number())
+	assert.Equal(t, "65", report.Series[1].Metrics.Revenue.Number())
+	assert.Equal(t, "0", report.PreviousSeries[0].Metrics.Revenue.Number())
+	assert.Equal(t, "20", report.PreviousSeries[1].Metrics.Revenue.Number())
+
+	// the groups are ordered by their revenue, deleted services are grouped together
+	assert.Len(t, report.Groups, 3)
+	assert.Equal(t, "Color", report.Groups[0].Name)
+	assert.Equal(t, "Cut", report.Groups[1].Name)
+	assert.Equal(t, 50, report.Groups[1].Changes.Bookings)
+	assert.Nil(t, report.Groups[2].Id)
+}
+
+func TestReportTaxes(t *testing.T) {
+	rows := []domain.ReportTaxRow{
+		{Name: "VAT", Percent: "27.00", Tax: "212.5984251968503937"},
+		{Name: "Reduced", Percent: "5.00", Tax: "0.004"},
+	}
+
+	taxes, err := reportTaxes(rows, "EUR")
+	if !assert.NoError(t, err) || !assert.Len(t, taxes, 2) {
+		return
+	}
+
+	assert.Equal(t, "VAT", taxes[0].Name)
+	assert.Equal(t, "27.00", taxes[0].Percent)
+	assert.Equal(t, "212.60", taxes[0].Amount.Number())
+	assert.Equal(t, "0.00", taxes[1].Amount.Number())
+
+	_, err = reportTaxes([]domain.ReportTaxRow{{Name: "VAT", Percent: "27.00", Tax: "abc"}}, "EUR")
+	assert.Error(t, err)
+}
@@ -0,0 +1,359 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// A customer is at risk if it has been this many times longer than their usual interval since their last visit
+const churnRiskFactor = 1.5
+
+const maxChurnRiskCustomers = 50
+
+type RetentionInput struct {
+	// Inclusive days in the timezone of the merchant
+	Start       time.Time
+	End         time.Time
+	Granularity types.ReportGranularity
+}
+
+func (s *Service) GetRetention(ctx context.Context, input RetentionInput) (domain.CustomerRetention, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.GetMerchantRetention(ctx, employee.MerchantId, input)
+}
+
+// How the customers of the merchant come back, based on the history of their participations
+func (s *Service) GetMerchantRetention(ctx context.Context, merchantId uuid.UUID, input RetentionInput) (domain.CustomerRetention, error) {
+	period := domain.ReportPeriod{Start: toDay(input.Start), End: toDay(input.End)}
+
+	if period.End.Before(period.Start) {
+		return domain.CustomerRetention{}, fmt.Errorf("the end of the period can not be before its start")
+	}
+
+	if days(period) > maxReportDays {
+		return domain.CustomerRetention{}, fmt.Errorf("a report can cover at most %d days", maxReportDays)
+	}
+
+	if input.Granularity == (types.ReportGranularity{}) {
+		return domain.CustomerRetention{}, fmt.Errorf("the granularity of the report is required")
+	}
+
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, merchantId)
+	if err != nil {
+		return domain.CustomerRetention{}, err
+	}
+
+	currencyCode, err := s.merchantRepo.GetMerchantCurrency(ctx, merchantId)
+	if err != nil {
+		return domain.CustomerRetention{}, err
+	}
+
+	_, to := localBounds(period, merchantTz)
+
+	visits, err := s.reportRepo.GetCustomerVisits(ctx, merchantId, currencyCode, to)
+	if err != nil {
+		return domain.CustomerRetention{}, err
+	}
+
+	return calculateRetention(period, input.Granularity, merchantTz, time.Now(), currencyCode, visits)
+}
+
+type customerHistory struct {
+	id        uuid.UUID
+	name      string
+	completed []domain.CustomerVisit
+	// completed visits as days in the timezone of the merchant
+	days          []time.Time
+	hasUpcoming   bool
+	lifetimeValue currency.Amount
+}
+
+// Groups the visits per customer, the visits have to be ordered by their start
+func customerHistories(visits []domain.CustomerVisit, tz *time.Location, now time.Time, currencyCode string) ([]*customerHistory, error) {
+	histories := []*customerHistory{}
+	byId := map[uuid.UUID]*customerHistory{}
+
+	for _, visit := range visits {
+		history, ok := byId[visit.CustomerId]
+		if !ok {
+			history = &customerHistory{
+				id:            visit.CustomerId,
+				name:          customerName(visit),
+				lifetimeValue: emptyMetrics(currencyCode).Revenue,
+			}
+			byId[visit.CustomerId] = history
+			histories = append(histories, history)
+		}
+
+		switch visit.Status {
+		case types.BookingStatusCompleted:
+			price, err := currency.NewAmount(visit.Price, currencyCode)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price of visit: %w", err)
+			}
+
+			history.lifetimeValue, err = history.lifetimeValue.Add(price)
+			if err != nil {
+				return nil, err
+			}
+
+			history.completed = append(history.completed, visit)
+			history.days = append(history.days, toDay(visit.FromDate.In(tz)))
+		case types.BookingStatusBooked, types.BookingStatusConfirmed:
+			if visit.FromDate.After(now) {
+				history.hasUpcoming = true
+			}
+		}
+	}
+
+	return histories, nil
+}
+
+func customerName(visit domain.CustomerVisit) string {
+	parts := []string{}
+	if visit.FirstName != nil {
+		parts = append(parts, *visit.FirstName)
+	}
+
+	if visit.LastName != nil {
+		parts = append(parts, *visit.LastName)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func inPeriod(day time.Time, period domain.ReportPeriod) bool {
+	return !day.Before(period.Start) && !day.After(period.End)
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func calculateRetention(period domain.ReportPeriod, granularity types.ReportGranularity, tz *time.Location, now time.Time,
+	currencyCode string, visits []domain.CustomerVisit) (domain.CustomerRetention, error) {
+	histories, err := customerHistories(visits, tz, now, currencyCode)
+	if err != nil {
+		return domain.CustomerRetention{}, err
+	}
+
+	retention := domain.CustomerRetention{
+		Period:               period,
+		Granularity:          granularity,
+		Currency:             currencyCode,
+		Series:               []domain.RetentionBucket{},
+		Cohorts:              []domain.CohortRetention{},
+		RebookingIntervals:   []domain.RebookingInterval{},
+		ChurnRisk:            []domain.ChurnRiskCustomer{},
+		AverageLifetimeValue: emptyMetrics(currencyCode).Revenue,
+	}
+
+	for _, bucket := range emptySeries(period, granularity, currencyCode) {
+		retention.Series = append(retention.Series, domain.RetentionBucket{Period: bucket.Period})
+	}
+
+	firstMonth := bucketStart(period.Start, types.ReportGranularityMonth)
+	for month := firstMonth; !month.After(period.End); month = month.AddDate(0, 1, 0) {
+		retained := make([]int, monthsBetween(month, period.End)+1)
+
+		retention.Cohorts = append(retention.Cohorts, domain.CohortRetention{
+			Month:     month,
+			Retained:  retained,
+			Retention: make([]int, len(retained)),
+		})
+	}
+
+	participations, noShows, cancellations := 0, 0, 0
+	for _, visit := range visits {
+		if !inPeriod(toDay(visit.FromDate.In(tz)), period) {
+			continue
+		}
+
+		switch visit.Status {
+		case types.BookingStatusNoShow:
+			noShows++
+		case types.BookingStatusCancelled:
+			cancellations++
+		}
+		participations++
+	}
+
+	retention.NoShowRate = percentOf(noShows, participations)
+	retention.CancellationRate = percentOf(cancellations, participations)
+
+	type intervalSum struct {
+		serviceId  *int
+		name       string
+		rebookings int
+		days       int
+	}
+	intervals := []*intervalSum{}
+
+	totalValue := emptyMetrics(currencyCode).Revenue
+	totalVisits := 0
+
+	today := toDay(now.In(tz))
+
+	for _, history := range histories {
+		if len(history.completed) == 0 {
+			continue
+		}
+
+		retention.Customers++
+		totalVisits += len(history.completed)
+
+		totalValue, err = totalValue.Add(history.lifetimeValue)
+		if err != nil {
+			return domain.CustomerRetention{}, err
+		}
+
+		first := history.days[0]
+
+		visitedInPeriod := slices.ContainsFunc(history.days, func(d time.Time) bool { return inPeriod(d, period) })
+		if visitedInPeriod {
+			if inPeriod(first, period) {
+				retention.NewCustomers++
+			} else {
+				retention.ReturningCustomers++
+			}
+		}
+
+		for i := range retention.Series {
+			bucket := &retention.Series[i]
+
+			if !slices.ContainsFunc(history.days, func(d time.Time) bool { return inPeriod(d, bucket.Period) }) {
+				continue
+			}
+
+			if first.Before(bucket.Period.Start) {
+				bucket.ReturningCustomers++
+			} else {
+				bucket.NewCustomers++
+			}
+		}
+
+		if cohortIdx := monthsBetween(firstMonth, first); cohortIdx >= 0 && cohortIdx < len(retention.Cohorts) && inPeriod(first, period) {
+			cohort := &retention.Cohorts[cohortIdx]
+			cohort.Customers++
+
+			visitedMonths := map[int]bool{}
+			for _, d := range history.days {
+				offset := monthsBetween(cohort.Month, d)
+				if offset < len(cohort.Retained) && !visitedMonths[offset] {
+					visitedMonths[offset] = true
+					cohort.Retained[offset]++
+				}
+			}
+		}
+
+		// the rebookings of the same service which happened in the period
+		lastVisitOfService := map[string]time.Time{}
+		for i, visit := range history.completed {
+			key := visit.ServiceName
+			if visit.ServiceId != nil {
+				key = fmt.Sprint(*visit.ServiceId)
+			}
+
+			previous, ok := lastVisitOfService[key]
+			lastVisitOfService[key] = history.days[i]
+
+			if !ok || !inPeriod(history.days[i], period) {
+				continue
+			}
+
+			idx := slices.IndexFunc(intervals, func(s *intervalSum) bool {
+				if s.serviceId == nil || visit.ServiceId == nil {
+					return s.serviceId == nil && visit.ServiceId == nil && s.name == visit.ServiceName
+				}
+				return *s.serviceId == *visit.ServiceId
+			})
+			if idx == -1 {
+				intervals = append(intervals, &intervalSum{serviceId: visit.ServiceId, name: visit.ServiceName})
+				idx = len(intervals) - 1
+			}
+
+			intervals[idx].rebookings++
+			intervals[idx].days += daysBetween(previous, history.days[i])
+		}
+
+		if len(history.days) < 2 || history.hasUpcoming {
+			continue
+		}
+
+		last := history.days[len(history.days)-1]
+		usual := float64(daysBetween(first, last)) / float64(len(history.days)-1)
+		since := daysBetween(last, today)
+
+		if usual >= 1 && float64(since) > usual*churnRiskFactor {
+			retention.ChurnRisk = append(retention.ChurnRisk, domain.ChurnRiskCustomer{
+				CustomerId:         history.id,
+				Name:               history.name,
+				LastVisit:          history.completed[len(history.completed)-1].FromDate,
+				Visits:             len(history.completed),
+				UsualIntervalDays:  int(math.Round(usual)),
+				DaysSinceLastVisit: since,
+				LifetimeValue:      history.lifetimeValue,
+			})
+		}
+	}
+
+	for i := range retention.Cohorts {
+		cohort := &retention.Cohorts[i]
+		for offset, retained := range cohort.Retained {
+			cohort.Retention[offset] = percentOf(retained, cohort.Customers)
+		}
+	}
+
+	for _, s := range intervals {
+		retention.RebookingIntervals = append(retention.RebookingIntervals, domain.RebookingInterval{
+			ServiceId:   s.serviceId,
+			ServiceName: s.name,
+			Rebookings:  s.rebookings,
+			AverageDays: int(math.Round(float64(s.days) / float64(s.rebookings))),
+		})
+	}
+
+	slices.SortFunc(retention.RebookingIntervals, func(a, b domain.RebookingInterval) int {
+		return cmp.Or(cmp.Compare(b.Rebookings, a.Rebookings), cmp.Compare(a.ServiceName, b.ServiceName))
+	})
+
+	// the most overdue customers compared to their own interval come first
+	slices.SortFunc(retention.ChurnRisk, func(a, b domain.ChurnRiskCustomer) int {
+		return cmp.Or(
+			cmp.Compare(float64(b.DaysSinceLastVisit)/float64(b.UsualIntervalDays), float64(a.DaysSinceLastVisit)/float64(a.UsualIntervalDays)),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	if len(retention.ChurnRisk) > maxChurnRiskCustomers {
+		retention.ChurnRisk = retention.ChurnRisk[:maxChurnRiskCustomers]
+	}
+
+	if retention.Customers > 0 {
+		retention.AverageVisits = math.Round(float64(totalVisits)/float64(retention.Customers)*10) / 10
+
+		average, err := totalValue.Div(fmt.Sprint(retention.Customers))
+		if err != nil {
+			return domain.CustomerRetention{}, err
+		}
+		retention.AverageLifetimeValue = average.Round()
+	}
+
+	return retention, nil
+}
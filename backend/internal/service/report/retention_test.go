@@ -0,0 +1,73 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateRetention(t *testing.T) {
+	cut, color := 1, 2
+	anna, bence, csilla, dora := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	doraName := "Dora"
+
+	visit := func(customerId uuid.UUID, serviceId *int, date time.Time, status types.BookingStatus, price string) domain.CustomerVisit {
+		return domain.CustomerVisit{CustomerId: customerId, ServiceId: serviceId, FromDate: date.Add(10 * time.Hour), Status: status, Price: price}
+	}
+
+	visits := []domain.CustomerVisit{
+		{CustomerId: dora, FirstName: &doraName, ServiceId: &cut, FromDate: day(2026, 1, 1), Status: types.BookingStatusCompleted, Price: "15"},
+		visit(anna, &cut, day(2026, 1, 10), types.BookingStatusCompleted, "20"),
+		visit(dora, &cut, day(2026, 1, 11), types.BookingStatusCompleted, "15"),
+		visit(csilla, &cut, day(2026, 3, 1), types.BookingStatusCompleted, "10"),
+		visit(bence, &color, day(2026, 3, 5), types.BookingStatusCompleted, "50"),
+		visit(anna, &cut, day(2026, 3, 10), types.BookingStatusCompleted, "20"),
+		visit(csilla, &cut, day(2026, 3, 15), types.BookingStatusCompleted, "10"),
+		visit(bence, &color, day(2026, 4, 2), types.BookingStatusNoShow, "0"),
+		visit(anna, &cut, day(2026, 4, 9), types.BookingStatusCompleted, "20"),
+		visit(bence, &color, day(2026, 4, 20), types.BookingStatusCancelled, "0"),
+		visit(csilla, &cut, day(2026, 6, 1), types.BookingStatusBooked, "0"),
+	}
+
+	period := domain.ReportPeriod{Start: day(2026, 3, 1), End: day(2026, 4, 30)}
+	now := day(2026, 5, 20)
+
+	retention, err := calculateRetention(period, types.ReportGranularityMonth, time.UTC, now, "EUR", visits)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, retention.NewCustomers)
+	assert.Equal(t, 1, retention.ReturningCustomers)
+	assert.Equal(t, 14, retention.NoShowRate)
+	assert.Equal(t, 14, retention.CancellationRate)
+
+	assert.Len(t, retention.Series, 2)
+	assert.Equal(t, domain.RetentionBucket{Period: domain.ReportPeriod{Start: day(2026, 3, 1), End: day(2026, 3, 31)}, NewCustomers: 2, ReturningCustomers: 1}, retention.Series[0])
+	assert.Equal(t, 0, retention.Series[1].NewCustomers)
+	assert.Equal(t, 1, retention.Series[1].ReturningCustomers)
+
+	// the customers who first visited in march did not come back in april
+	assert.Len(t, retention.Cohorts, 2)
+	assert.Equal(t, 2, retention.Cohorts[0].Customers)
+	assert.Equal(t, []int{2, 0}, retention.Cohorts[0].Retained)
+	assert.Equal(t, []int{100, 0}, retention.Cohorts[0].Retention)
+	assert.Equal(t, []int{0}, retention.Cohorts[1].Retained)
+
+	// only the rebookings in the period are counted, 59, 30 and 14 days
+	assert.Equal(t, []domain.RebookingInterval{{ServiceId: &cut, Rebookings: 3, AverageDays: 34}}, retention.RebookingIntervals)
+
+	// customers with an upcoming booking or only one visit are not at risk
+	assert.Len(t, retention.ChurnRisk, 1)
+	assert.Equal(t, dora, retention.ChurnRisk[0].CustomerId)
+	assert.Equal(t, "Dora", retention.ChurnRisk[0].Name)
+	assert.Equal(t, 10, retention.ChurnRisk[0].UsualIntervalDays)
+	assert.Equal(t, 129, retention.ChurnRisk[0].DaysSinceLastVisit)
+	assert.Equal(t, "30", retention.ChurnRisk[0].LifetimeValue.Number())
+
+	assert.Equal(t, 4, retention.Customers)
+	assert.Equal(t, 2.0, retention.AverageVisits)
+	assert.Equal(t, "40.00", retention.AverageLifetimeValue.Number())
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/riverqueue/river"
+)
+
+// Schedules the refresh of the rollups of every merchant which has bookings around the current day
+func (s *Service) ScheduleRollupRefresh(ctx context.Context, pastDays int, futureDays int) error {
+	now := time.Now().UTC()
+
+	// one extra day on both ends covers every timezone
+	merchantIds, err := s.reportRepo.GetMerchantsWithBookings(ctx, now.AddDate(0, 0, -pastDays-1), now.AddDate(0, 0, futureDays+2))
+	if err != nil {
+		return err
+	}
+
+	if len(merchantIds) == 0 {
+		return nil
+	}
+
+	params := make([]river.InsertManyParams, 0, len(merchantIds))
+	for _, merchantId := range merchantIds {
+		params = append(params, river.InsertManyParams{Args: args.RefreshBookingRollups{
+			MerchantId: merchantId,
+			PastDays:   pastDays,
+			FutureDays: futureDays,
+		}})
+	}
+
+	_, err = s.enqueuer.InsertManyFast(ctx, params)
+	if err != nil {
+		return fmt.Errorf("could not schedule booking rollup refresh jobs: %w", err)
+	}
+
+	return nil
+}
+
+// Recomputes the rollups of the merchant around the current day in the timezone of the merchant
+func (s *Service) RefreshRollups(ctx context.Context, merchantId uuid.UUID, pastDays int, futureDays int) error {
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, merchantId)
+	if err != nil {
+		return err
+	}
+
+	today := toDay(time.Now().In(merchantTz))
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		return s.reportRepo.WithTx(tx).RefreshBookingRollups(ctx, merchantId, today.AddDate(0, 0, -pastDays), today.AddDate(0, 0, futureDays))
+	})
+}
+
+// Recomputes the rollups of the day the date falls on in the timezone of the merchant
+func (s *Service) RefreshDayRollups(ctx context.Context, merchantId uuid.UUID, date time.Time) error {
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, merchantId)
+	if err != nil {
+		return err
+	}
+
+	day := toDay(date.In(merchantTz))
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		return s.reportRepo.WithTx(tx).RefreshBookingRollups(ctx, merchantId, day, day)
+	})
+}
@@ -0,0 +1,281 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/service/email"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/riverqueue/river"
+	"golang.org/x/text/language"
+)
+
+// The reports are sent in the morning after their period ended, in the timezone of the merchant
+const reportSendHour = 7
+
+type ReportSubscriptionInput struct {
+	Frequency   types.ReportFrequency
+	EmployeeIds []int
+}
+
+func (s *Service) NewSubscription(ctx context.Context, input ReportSubscriptionInput) (int, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	if len(input.EmployeeIds) == 0 {
+		return 0, fmt.Errorf("a report subscription needs at least one recipient")
+	}
+
+	subscriptions, err := s.reportRepo.GetReportSubscriptions(ctx, employee.MerchantId)
+	if err != nil {
+		return 0, err
+	}
+
+	if slices.ContainsFunc(subscriptions, func(sub domain.ReportSubscription) bool { return sub.Frequency == input.Frequency }) {
+		return 0, fmt.Errorf("there is already a %s report subscription", input.Frequency.String())
+	}
+
+	return s.reportRepo.NewReportSubscription(ctx, employee.MerchantId, domain.NewReportSubscription{
+		Frequency:   input.Frequency,
+		EmployeeIds: input.EmployeeIds,
+	})
+}
+
+type UpdateSubscriptionInput struct {
+	IsActive    bool
+	EmployeeIds []int
+}
+
+func (s *Service) UpdateSubscription(ctx context.Context, subscriptionId int, input UpdateSubscriptionInput) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	if len(input.EmployeeIds) == 0 {
+		return fmt.Errorf("a report subscription needs at least one recipient")
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		return s.reportRepo.WithTx(tx).UpdateReportSubscription(ctx, employee.MerchantId, subscriptionId, domain.UpdateReportSubscription{
+			IsActive:    input.IsActive,
+			EmployeeIds: input.EmployeeIds,
+		})
+	})
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, subscriptionId int) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.reportRepo.DeleteReportSubscription(ctx, employee.MerchantId, subscriptionId)
+}
+
+func (s *Service) GetSubscriptions(ctx context.Context) ([]domain.ReportSubscription, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.reportRepo.GetReportSubscriptions(ctx, employee.MerchantId)
+}
+
+// The last whole period of the frequency which ended before the given day, weeks start on monday
+func lastCompletedPeriod(today time.Time, frequency types.ReportFrequency) domain.ReportPeriod {
+	switch frequency {
+	case types.ReportFrequencyWeekly:
+		thisWeek := bucketStart(today, types.ReportGranularityWeek)
+		return domain.ReportPeriod{Start: thisWeek.AddDate(0, 0, -7), End: thisWeek.AddDate(0, 0, -1)}
+	case types.ReportFrequencyMonthly:
+		thisMonth := bucketStart(today, types.ReportGranularityMonth)
+		return domain.ReportPeriod{Start: thisMonth.AddDate(0, -1, 0), End: thisMonth.AddDate(0, 0, -1)}
+	default:
+		yesterday := today.AddDate(0, 0, -1)
+		return domain.ReportPeriod{Start: yesterday, End: yesterday}
+	}
+}
+
+// The period the subscription should be sent for, false if it is not due yet or it was already sent
+func duePeriod(subscription domain.ActiveReportSubscription, now time.Time) (domain.ReportPeriod, bool) {
+	tz, err := time.LoadLocation(subscription.Timezone)
+	if err != nil {
+		tz = time.UTC
+	}
+
+	local := now.In(tz)
+	if local.Hour() < reportSendHour {
+		return domain.ReportPeriod{}, false
+	}
+
+	period := lastCompletedPeriod(toDay(local), subscription.Frequency)
+
+	if subscription.LastSentUntil != nil && !toDay(*subscription.LastSentUntil).Before(period.End) {
+		return domain.ReportPeriod{}, false
+	}
+
+	return period, true
+}
+
+// Schedules the email of every subscription whose period ended since it was last sent
+func (s *Service) ScheduleSubscriptionReports(ctx context.Context) error {
+	subscriptions, err := s.reportRepo.GetActiveReportSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	params := []river.InsertManyParams{}
+
+	for _, subscription := range subscriptions {
+		period, ok := duePeriod(subscription, now)
+		if !ok {
+			continue
+		}
+
+		params = append(params, river.InsertManyParams{Args: args.SendReportSubscription{
+			SubscriptionId: subscription.Id,
+			Start:          period.Start,
+			End:            period.End,
+		}})
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	_, err = s.enqueuer.InsertManyFast(ctx, params)
+	if err != nil {
+		return fmt.Errorf("could not schedule report subscription jobs: %w", err)
+	}
+
+	return nil
+}
+
+// Emails the report of the period to every recipient of the subscription
+func (s *Service) SendSubscriptionReport(ctx context.Context, subscriptionId int, start time.Time, end time.Time) error {
+	subscription, err := s.reportRepo.GetReportSubscription(ctx, subscriptionId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if !subscription.IsActive || (subscription.LastSentUntil != nil && !toDay(*subscription.LastSentUntil).Before(toDay(end))) {
+		return nil
+	}
+
+	report, err := s.GetMerchantReport(ctx, subscription.MerchantId, ReportInput{
+		Start:       start,
+		End:         end,
+		Granularity: types.ReportGranularityDay,
+	})
+	if err != nil {
+		return err
+	}
+
+	delivered, err := s.reportRepo.GetReportSubscriptionDeliveries(ctx, subscriptionId, report.Period.End)
+	if err != nil {
+		return err
+	}
+
+	// recipients who got the report before a retry are recorded, so only the failed ones are emailed again
+	var sendErrors []error
+	for _, recipient := range reportRecipients(subscription.Recipients, delivered) {
+		language := recipientLanguage(recipient)
+
+		data, err := reportSummaryData(subscription.MerchantName, report, language)
+		if err != nil {
+			return err
+		}
+
+		err = s.mailer.ReportSummary(ctx, language, *recipient.Email, data)
+		if err != nil {
+			sendErrors = append(sendErrors, fmt.Errorf("could not send report to employee %d: %w", recipient.EmployeeId, err))
+			continue
+		}
+
+		err = s.reportRepo.NewReportSubscriptionDelivery(ctx, subscriptionId, recipient.EmployeeId, report.Period.End)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(sendErrors) > 0 {
+		return errors.Join(sendErrors...)
+	}
+
+	return s.reportRepo.SetReportSubscriptionSent(ctx, subscriptionId, report.Period.End)
+}
+
+// Recipients who should get the report: they have an email, can still view reports
+// and did not get this period's report yet
+func reportRecipients(recipients []domain.ReportSubscriptionRecipient, delivered []int) []domain.ReportSubscriptionRecipient {
+	result := []domain.ReportSubscriptionRecipient{}
+
+	for _, recipient := range recipients {
+		if recipient.Email == nil || *recipient.Email == "" || slices.Contains(delivered, recipient.EmployeeId) {
+			continue
+		}
+
+		if !slices.Contains(recipient.Permissions(), types.PermissionReportsView) {
+			continue
+		}
+
+		result = append(result, recipient)
+	}
+
+	return result
+}
+
+func recipientLanguage(recipient domain.ReportSubscriptionRecipient) language.Tag {
+	if recipient.UserLanguage != nil {
+		if tag, err := language.Parse(*recipient.UserLanguage); err == nil {
+			return tag
+		}
+	}
+
+	return lang.GetDefaultLang()
+}
+
+func formatPeriod(period domain.ReportPeriod) string {
+	if period.Start.Equal(period.End) {
+		return period.Start.Format(time.DateOnly)
+	}
+
+	return fmt.Sprintf("%s - %s", period.Start.Format(time.DateOnly), period.End.Format(time.DateOnly))
+}
+
+func formatChange(change int) string {
+	if change > 0 {
+		return fmt.Sprintf("+%d%%", change)
+	}
+
+	return fmt.Sprintf("%d%%", change)
+}
+
+func reportSummaryData(merchantName string, report domain.Report, locale language.Tag) (email.ReportSummaryData, error) {
+	current, changes := report.Current, report.Changes
+
+	revenue, err := currencyx.Format(current.Revenue, locale)
+	if err != nil {
+		return email.ReportSummaryData{}, err
+	}
+
+	return email.ReportSummaryData{
+		MerchantName:   merchantName,
+		Period:         formatPeriod(report.Period),
+		PreviousPeriod: formatPeriod(report.PreviousPeriod),
+		Metrics: []email.ReportSummaryMetric{
+			{Key: "ReportSummary.bookings", Value: strconv.Itoa(current.Bookings), Change: formatChange(changes.Bookings)},
+			{Key: "ReportSummary.participants", Value: strconv.Itoa(current.Participants), Change: formatChange(changes.Participants)},
+			{Key: "ReportSummary.revenue", Value: revenue, Change: formatChange(changes.Revenue)},
+			{Key: "ReportSummary.cancellations", Value: strconv.Itoa(current.Cancellations), Change: formatChange(changes.Cancellations)},
+			{Key: "ReportSummary.no_shows", Value: strconv.Itoa(current.NoShows), Change: formatChange(changes.NoShows)},
+			{Key: "ReportSummary.average_duration", Value: strconv.Itoa(current.AverageDuration()), Change: formatChange(changes.AverageDuration)},
+		},
+		ReportLink: "http://reservations.local:3000/dashboard",
+	}, nil
+}
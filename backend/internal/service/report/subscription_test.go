@@ -0,0 +1,96 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastCompletedPeriod(t *testing.T) {
+	// a wednesday
+	today := day(2026, 3, 4)
+
+	tests := []struct {
+		name      string
+		frequency types.ReportFrequency
+		expected  domain.ReportPeriod
+	}{
+		{"daily", types.ReportFrequencyDaily, domain.ReportPeriod{Start: day(2026, 3, 3), End: day(2026, 3, 3)}},
+		{"weekly", types.ReportFrequencyWeekly, domain.ReportPeriod{Start: day(2026, 2, 23), End: day(2026, 3, 1)}},
+		{"monthly", types.ReportFrequencyMonthly, domain.ReportPeriod{Start: day(2026, 2, 1), End: day(2026, 2, 28)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lastCompletedPeriod(today, tt.frequency))
+		})
+	}
+}
+
+func TestDuePeriod(t *testing.T) {
+	sentUntil := day(2026, 3, 3)
+	sentBefore := day(2026, 3, 2)
+
+	tests := []struct {
+		name          string
+		timezone      string
+		lastSentUntil *time.Time
+		now           time.Time
+		due           bool
+	}{
+		{"before the send hour", "UTC", nil, day(2026, 3, 4).Add(6 * time.Hour), false},
+		{"never sent", "UTC", nil, day(2026, 3, 4).Add(8 * time.Hour), true},
+		{"already sent", "UTC", &sentUntil, day(2026, 3, 4).Add(8 * time.Hour), false},
+		{"sent for an earlier period", "UTC", &sentBefore, day(2026, 3, 4).Add(8 * time.Hour), true},
+		// 05:30 in UTC is already 07:30 in Budapest
+		{"in the timezone of the merchant", "Europe/Budapest", nil, day(2026, 6, 4).Add(5*time.Hour + 30*time.Minute), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := domain.ActiveReportSubscription{
+				Frequency:     types.ReportFrequencyDaily,
+				Timezone:      tt.timezone,
+				LastSentUntil: tt.lastSentUntil,
+			}
+
+			period, due := duePeriod(subscription, tt.now)
+			assert.Equal(t, tt.due, due)
+
+			if due {
+				yesterday := toDay(tt.now).AddDate(0, 0, -1)
+				assert.Equal(t, domain.ReportPeriod{Start: yesterday, End: yesterday}, period)
+			}
+		})
+	}
+}
+
+func TestReportRecipients(t *testing.T) {
+	email := "someone@example.com"
+	empty := ""
+	customRole := 3
+
+	recipients := []domain.ReportSubscriptionRecipient{
+		{EmployeeId: 1, Email: &email, Role: types.EmployeeRoleOwner},
+		{EmployeeId: 2, Email: &email, Role: types.EmployeeRoleAdmin},
+		{EmployeeId: 3, Email: &email, Role: types.EmployeeRoleStaff},
+		{EmployeeId: 4, Email: &email, Role: types.EmployeeRoleStaff, RoleId: &customRole, CustomPermissions: []string{types.PermissionReportsView.String()}},
+		{EmployeeId: 5, Email: &email, Role: types.EmployeeRoleAdmin, RoleId: &customRole, CustomPermissions: []string{}},
+		{EmployeeId: 6, Email: &empty, Role: types.EmployeeRoleOwner},
+		{EmployeeId: 7, Role: types.EmployeeRoleOwner},
+	}
+
+	employeeIds := func(recipients []domain.ReportSubscriptionRecipient) []int {
+		ids := []int{}
+		for _, r := range recipients {
+			ids = append(ids, r.EmployeeId)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{1, 2, 4}, employeeIds(reportRecipients(recipients, nil)))
+	assert.Equal(t, []int{4}, employeeIds(reportRecipients(recipients, []int{1, 2})))
+}
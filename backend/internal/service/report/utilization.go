@@ -0,0 +1,453 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+)
+
+// Utilization is computed from the bookings themselves, so its period is kept shorter than the one of the reports
+const maxUtilizationDays = 93
+
+type UtilizationInput struct {
+	// Inclusive days in the timezone of the merchant
+	Start time.Time
+	End   time.Time
+}
+
+func (s *Service) GetUtilization(ctx context.Context, input UtilizationInput) (domain.Utilization, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	return s.GetMerchantUtilization(ctx, employee.MerchantId, input)
+}
+
+// How busy the employees of the merchant were during their working hours in the period
+func (s *Service) GetMerchantUtilization(ctx context.Context, merchantId uuid.UUID, input UtilizationInput) (domain.Utilization, error) {
+	period := domain.ReportPeriod{Start: toDay(input.Start), End: toDay(input.End)}
+
+	if period.End.Before(period.Start) {
+		return domain.Utilization{}, fmt.Errorf("the end of the period can not be before its start")
+	}
+
+	if days(period) > maxUtilizationDays {
+		return domain.Utilization{}, fmt.Errorf("the utilization can be calculated for at most %d days", maxUtilizationDays)
+	}
+
+	merchantTz, err := s.merchantRepo.GetMerchantTimezone(ctx, merchantId)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	currencyCode, err := s.merchantRepo.GetMerchantCurrency(ctx, merchantId)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	businessHours, err := s.merchantRepo.GetBusinessHours(ctx, merchantId)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	employees, err := s.teamRepo.GetActiveEmployees(ctx, merchantId)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	from, to := localBounds(period, merchantTz)
+
+	bookings, err := s.reportRepo.GetUtilizationBookings(ctx, merchantId, currencyCode, from, to)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	// all day blocked times can end on the day they start
+	blockedTimes, err := s.reportRepo.GetEmployeeBlockedTimes(ctx, merchantId, from.AddDate(0, 0, -1), to)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	return calculateUtilization(period, merchantTz, currencyCode, businessHours, employees, bookings, blockedTimes)
+}
+
+type interval struct {
+	from time.Time
+	to   time.Time
+}
+
+func (i interval) minutes() int {
+	return int(i.to.Sub(i.from).Minutes())
+}
+
+func totalMinutes(intervals []interval) int {
+	total := 0
+	for _, i := range intervals {
+		total += i.minutes()
+	}
+
+	return total
+}
+
+// Sorts the intervals and joins the overlapping ones
+func mergeIntervals(intervals []interval) []interval {
+	sorted := slices.Clone(intervals)
+	slices.SortFunc(sorted, func(a, b interval) int { return a.from.Compare(b.from) })
+
+	merged := []interval{}
+	for _, i := range sorted {
+		if !i.from.Before(i.to) {
+			continue
+		}
+
+		if last := len(merged) - 1; last >= 0 && !i.from.After(merged[last].to) {
+			if i.to.After(merged[last].to) {
+				merged[last].to = i.to
+			}
+			continue
+		}
+
+		merged = append(merged, i)
+	}
+
+	return merged
+}
+
+// The parts of the intervals which are not covered by the removed ones, both have to be merged
+func subtractIntervals(intervals []interval, removed []interval) []interval {
+	result := []interval{}
+
+	for _, i := range intervals {
+		start := i.from
+
+		for _, r := range removed {
+			if !r.to.After(start) || !r.from.Before(i.to) {
+				continue
+			}
+
+			if r.from.After(start) {
+				result = append(result, interval{from: start, to: r.from})
+			}
+
+			if r.to.After(start) {
+				start = r.to
+			}
+		}
+
+		if start.Before(i.to) {
+			result = append(result, interval{from: start, to: i.to})
+		}
+	}
+
+	return result
+}
+
+// The parts of the intervals which are covered by the others, both have to be merged
+func intersectIntervals(intervals []interval, others []interval) []interval {
+	result := []interval{}
+
+	for _, i := range intervals {
+		for _, o := range others {
+			from, to := maxTime(i.from, o.from), minTime(i.to, o.to)
+			if from.Before(to) {
+				result = append(result, interval{from: from, to: to})
+			}
+		}
+	}
+
+	return result
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// The first and the last moment of the period in the timezone of the merchant
+func localBounds(period domain.ReportPeriod, tz *time.Location) (time.Time, time.Time) {
+	from := time.Date(period.Start.Year(), period.Start.Month(), period.Start.Day(), 0, 0, 0, 0, tz)
+	to := time.Date(period.End.Year(), period.End.Month(), period.End.Day()+1, 0, 0, 0, 0, tz)
+
+	return from, to
+}
+
+// The business hours of every day of the period as absolute times
+func openIntervals(period domain.ReportPeriod, tz *time.Location, businessHours domain.BusinessHours) []interval {
+	intervals := []interval{}
+
+	for day := period.Start; !day.After(period.End); day = day.AddDate(0, 0, 1) {
+		for _, slot := range businessHours[int(day.Weekday())] {
+			intervals = append(intervals, interval{
+				from: time.Date(day.Year(), day.Month(), day.Day(), slot.StartTime.Hour(), slot.StartTime.Minute(), 0, 0, tz),
+				to:   time.Date(day.Year(), day.Month(), day.Day(), slot.EndTime.Hour(), slot.EndTime.Minute(), 0, 0, tz),
+			})
+		}
+	}
+
+	return mergeIntervals(intervals)
+}
+
+// All day blocked times cover every day they touch in the timezone of the merchant
+func blockedInterval(blocked domain.EmployeeBlockedTime, tz *time.Location) interval {
+	if !blocked.AllDay {
+		return interval{from: blocked.FromDate, to: blocked.ToDate}
+	}
+
+	from := blocked.FromDate.In(tz)
+	to := blocked.ToDate.In(tz)
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, tz)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, tz)
+	if !end.After(start) || !end.Equal(to) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return interval{from: start, to: end}
+}
+
+// Free time between two bookings during the same working hours
+func idleGaps(working []interval, booked []interval) []interval {
+	gaps := []interval{}
+
+	for _, w := range working {
+		inside := intersectIntervals([]interval{w}, booked)
+
+		for i := 1; i < len(inside); i++ {
+			if inside[i-1].to.Before(inside[i].from) {
+				gaps = append(gaps, interval{from: inside[i-1].to, to: inside[i].from})
+			}
+		}
+	}
+
+	return gaps
+}
+
+type heatmap [7][24]struct {
+	working int
+	booked  int
+}
+
+// Splits the interval at the hours of the merchant and adds its minutes to the hours of the week
+func (h *heatmap) add(i interval, tz *time.Location, booked bool) {
+	for start := i.from.In(tz); start.Before(i.to); {
+		nextHour := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, tz).Add(time.Hour)
+		end := minTime(nextHour, i.to)
+
+		cell := &h[int(start.Weekday())][start.Hour()]
+		if booked {
+			cell.booked += int(end.Sub(start).Minutes())
+		} else {
+			cell.working += int(end.Sub(start).Minutes())
+		}
+
+		start = end.In(tz)
+	}
+}
+
+func percentOf(part int, whole int) int {
+	if whole <= 0 {
+		return 0
+	}
+
+	return part * 100 / whole
+}
+
+// Revenue of one working hour rounded to the precision of the currency
+func revenuePerHour(revenue currency.Amount, workingMinutes int) (currency.Amount, error) {
+	if workingMinutes <= 0 {
+		return currency.NewAmount("0", revenue.CurrencyCode())
+	}
+
+	perMinute, err := revenue.Div(strconv.Itoa(workingMinutes))
+	if err != nil {
+		return currency.Amount{}, err
+	}
+
+	perHour, err := perMinute.Mul("60")
+	if err != nil {
+		return currency.Amount{}, err
+	}
+
+	return perHour.Round(), nil
+}
+
+func calculateUtilization(period domain.ReportPeriod, tz *time.Location, currencyCode string, businessHours domain.BusinessHours,
+	employees []domain.PublicEmployee, bookings []domain.UtilizationBooking, blockedTimes []domain.EmployeeBlockedTime) (domain.Utilization, error) {
+	open := openIntervals(period, tz, businessHours)
+	from, to := localBounds(period, tz)
+
+	utilization := domain.Utilization{
+		Period:    period,
+		Currency:  currencyCode,
+		Revenue:   emptyMetrics(currencyCode).Revenue,
+		Employees: []domain.EmployeeUtilization{},
+		Locations: []domain.LocationOccupancy{},
+		Heatmap:   []domain.HeatmapCell{},
+	}
+
+	var cells heatmap
+
+	for _, employee := range employees {
+		blocked := []interval{}
+		for _, b := range blockedTimes {
+			if b.EmployeeId == employee.Id {
+				blocked = append(blocked, blockedInterval(b, tz))
+			}
+		}
+
+		working := subtractIntervals(open, mergeIntervals(blocked))
+
+		revenue := emptyMetrics(currencyCode).Revenue
+		booked := []interval{}
+		bookedByLocation := map[int][]interval{}
+
+		for _, booking := range bookings {
+			if booking.EmployeeId == nil || *booking.EmployeeId != employee.Id {
+				continue
+			}
+
+			// revenue belongs to the period the booking starts in
+			if !booking.FromDate.Before(from) && booking.FromDate.Before(to) {
+				amount, err := currency.NewAmount(booking.Revenue, currencyCode)
+				if err != nil {
+					return domain.Utilization{}, fmt.Errorf("invalid revenue of booking %d: %w", booking.Id, err)
+				}
+
+				revenue, err = revenue.Add(amount)
+				if err != nil {
+					return domain.Utilization{}, err
+				}
+			}
+
+			for _, phase := range booking.ActivePhases {
+				phaseInterval := interval{from: phase.FromDate, to: phase.ToDate}
+
+				booked = append(booked, phaseInterval)
+				bookedByLocation[booking.LocationId] = append(bookedByLocation[booking.LocationId], phaseInterval)
+			}
+		}
+
+		// overtime is not counted, an employee can be at most fully booked
+		bookedWorking := intersectIntervals(working, mergeIntervals(booked))
+		gaps := idleGaps(working, bookedWorking)
+
+		perHour, err := revenuePerHour(revenue, totalMinutes(working))
+		if err != nil {
+			return domain.Utilization{}, err
+		}
+
+		employeeUtilization := domain.EmployeeUtilization{
+			EmployeeId:     employee.Id,
+			Name:           employeeName(employee),
+			WorkingMinutes: totalMinutes(working),
+			BookedMinutes:  totalMinutes(bookedWorking),
+			IdleGaps:       len(gaps),
+			IdleGapMinutes: totalMinutes(gaps),
+			Revenue:        revenue,
+			RevenuePerHour: perHour,
+		}
+		employeeUtilization.Utilization = percentOf(employeeUtilization.BookedMinutes, employeeUtilization.WorkingMinutes)
+
+		utilization.Employees = append(utilization.Employees, employeeUtilization)
+
+		utilization.WorkingMinutes += employeeUtilization.WorkingMinutes
+		utilization.BookedMinutes += employeeUtilization.BookedMinutes
+		utilization.IdleGaps += employeeUtilization.IdleGaps
+		utilization.IdleGapMinutes += employeeUtilization.IdleGapMinutes
+
+		utilization.Revenue, err = utilization.Revenue.Add(revenue)
+		if err != nil {
+			return domain.Utilization{}, err
+		}
+
+		for _, w := range working {
+			cells.add(w, tz, false)
+		}
+
+		for _, b := range bookedWorking {
+			cells.add(b, tz, true)
+		}
+
+		for locationId, locationBooked := range bookedByLocation {
+			idx := slices.IndexFunc(utilization.Locations, func(l domain.LocationOccupancy) bool { return l.LocationId == locationId })
+			if idx == -1 {
+				utilization.Locations = append(utilization.Locations, domain.LocationOccupancy{LocationId: locationId, Name: locationName(bookings, locationId)})
+				idx = len(utilization.Locations) - 1
+			}
+
+			utilization.Locations[idx].BookedMinutes += totalMinutes(intersectIntervals(working, mergeIntervals(locationBooked)))
+			utilization.Locations[idx].CapacityMinutes += employeeUtilization.WorkingMinutes
+		}
+	}
+
+	var err error
+
+	utilization.Utilization = percentOf(utilization.BookedMinutes, utilization.WorkingMinutes)
+	utilization.RevenuePerHour, err = revenuePerHour(utilization.Revenue, utilization.WorkingMinutes)
+	if err != nil {
+		return domain.Utilization{}, err
+	}
+
+	for i := range utilization.Locations {
+		utilization.Locations[i].Occupancy = percentOf(utilization.Locations[i].BookedMinutes, utilization.Locations[i].CapacityMinutes)
+	}
+
+	slices.SortFunc(utilization.Employees, func(a, b domain.EmployeeUtilization) int {
+		return cmp.Or(cmp.Compare(b.Utilization, a.Utilization), cmp.Compare(a.Name, b.Name))
+	})
+
+	slices.SortFunc(utilization.Locations, func(a, b domain.LocationOccupancy) int { return cmp.Compare(a.Name, b.Name) })
+
+	for dayOfWeek := range cells {
+		for hour, cell := range cells[dayOfWeek] {
+			utilization.Heatmap = append(utilization.Heatmap, domain.HeatmapCell{
+				DayOfWeek:      dayOfWeek,
+				Hour:           hour,
+				WorkingMinutes: cell.working,
+				BookedMinutes:  cell.booked,
+				Occupancy:      percentOf(cell.booked, cell.working),
+			})
+		}
+	}
+
+	return utilization, nil
+}
+
+func employeeName(employee domain.PublicEmployee) string {
+	parts := []string{}
+	if employee.FirstName != nil {
+		parts = append(parts, *employee.FirstName)
+	}
+
+	if employee.LastName != nil {
+		parts = append(parts, *employee.LastName)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func locationName(bookings []domain.UtilizationBooking, locationId int) string {
+	for _, booking := range bookings {
+		if booking.LocationId == locationId {
+			return booking.LocationName
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(hour int, minute int) time.Time {
+	return time.Date(2026, 3, 9, hour, minute, 0, 0, time.UTC)
+}
+
+func TestIntervals(t *testing.T) {
+	merged := mergeIntervals([]interval{
+		{from: at(13, 0), to: at(14, 0)},
+		{from: at(9, 0), to: at(10, 0)},
+		{from: at(9, 30), to: at(11, 0)},
+		{from: at(11, 0), to: at(12, 0)},
+	})
+	assert.Equal(t, []interval{{from: at(9, 0), to: at(12, 0)}, {from: at(13, 0), to: at(14, 0)}}, merged)
+
+	subtracted := subtractIntervals(merged, []interval{{from: at(10, 0), to: at(10, 30)}, {from: at(11, 30), to: at(13, 30)}})
+	assert.Equal(t, []interval{
+		{from: at(9, 0), to: at(10, 0)},
+		{from: at(10, 30), to: at(11, 30)},
+		{from: at(13, 30), to: at(14, 0)},
+	}, subtracted)
+
+	gaps := idleGaps(merged, []interval{{from: at(9, 0), to: at(9, 30)}, {from: at(10, 0), to: at(11, 0)}, {from: at(13, 0), to: at(13, 30)}})
+	assert.Equal(t, []interval{{from: at(9, 30), to: at(10, 0)}}, gaps)
+}
+
+func TestCalculateUtilization(t *testing.T) {
+	employeeId := 1
+	firstName := "Anna"
+
+	// 2026-03-09 is a monday
+	period := domain.ReportPeriod{Start: day(2026, 3, 9), End: day(2026, 3, 9)}
+	businessHours := domain.BusinessHours{1: {{StartTime: at(9, 0), EndTime: at(17, 0)}}}
+	employees := []domain.PublicEmployee{{Id: employeeId, FirstName: &firstName}}
+
+	bookings := []domain.UtilizationBooking{
+		{Id: 1, EmployeeId: &employeeId, LocationId: 1, LocationName: "Main street", FromDate: at(9, 0), Revenue: "30",
+			ActivePhases: []domain.TimeRange{{FromDate: at(9, 0), ToDate: at(10, 0)}}},
+		{Id: 2, EmployeeId: &employeeId, LocationId: 1, LocationName: "Main street", FromDate: at(11, 0), Revenue: "20",
+			ActivePhases: []domain.TimeRange{{FromDate: at(11, 0), ToDate: at(12, 30)}}},
+	}
+
+	blockedTimes := []domain.EmployeeBlockedTime{{EmployeeId: employeeId, FromDate: at(12, 0), ToDate: at(13, 0)}}
+
+	utilization, err := calculateUtilization(period, time.UTC, "EUR", businessHours, employees, bookings, blockedTimes)
+	assert.NoError(t, err)
+
+	// the blocked lunch break is not working time, so the part of the booking during it is not counted either
+	assert.Equal(t, 420, utilization.WorkingMinutes)
+	assert.Equal(t, 120, utilization.BookedMinutes)
+	assert.Equal(t, 28, utilization.Utilization)
+	assert.Equal(t, 1, utilization.IdleGaps)
+	assert.Equal(t, 60, utilization.IdleGapMinutes)
+	assert.Equal(t, "50", utilization.Revenue.Number())
+	assert.Equal(t, "7.14", utilization.RevenuePerHour.Number())
+
+	assert.Len(t, utilization.Employees, 1)
+	assert.Equal(t, "Anna", utilization.Employees[0].Name)
+
+	assert.Len(t, utilization.Locations, 1)
+	assert.Equal(t, 420, utilization.Locations[0].CapacityMinutes)
+	assert.Equal(t, 28, utilization.Locations[0].Occupancy)
+
+	assert.Len(t, utilization.Heatmap, 7*24)
+	monday9 := utilization.Heatmap[1*24+9]
+	assert.Equal(t, domain.HeatmapCell{DayOfWeek: 1, Hour: 9, WorkingMinutes: 60, BookedMinutes: 60, Occupancy: 100}, monday9)
+	assert.Equal(t, 0, utilization.Heatmap[1*24+12].WorkingMinutes)
+}
@@ -0,0 +1,29 @@
+package team
+
+import (
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Fields of a team member which are recorded in the audit log
+type employeeAuditState struct {
+	Role        types.EmployeeRole `json:"role"`
+	RoleId      *int               `json:"role_id"`
+	FirstName   *string            `json:"first_name"`
+	LastName    *string            `json:"last_name"`
+	Email       *string            `json:"email"`
+	PhoneNumber *string            `json:"phone_number"`
+	IsActive    bool               `json:"is_active"`
+}
+
+func newEmployeeAuditState(employee domain.PublicEmployee) employeeAuditState {
+	return employeeAuditState{
+		Role:        employee.Role,
+		RoleId:      employee.RoleId,
+		FirstName:   employee.FirstName,
+		LastName:    employee.LastName,
+		Email:       employee.Email,
+		PhoneNumber: employee.PhoneNumber,
+		IsActive:    employee.IsActive,
+	}
+}
@@ -0,0 +1,131 @@
+package team
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+// Employees can only grant permissions they have themselves,
+// so a team manager can not create a role more powerful than their own
+func checkGrantablePermissions(employee actor.EmployeeContext, permissions []types.Permission) error {
+	for _, p := range permissions {
+		if !p.IsAssignable() {
+			return fmt.Errorf("permission %s can not be assigned to a role", p)
+		}
+
+		if !employee.HasPermission(p) {
+			return fmt.Errorf("you can not grant the %s permission", p)
+		}
+	}
+
+	return nil
+}
+
+// Both the built in role and the custom role are checked, the built in one applies
+// whenever the employee has no custom role, for example after it gets deleted
+func (s *Service) checkRoleAssignable(ctx context.Context, employee actor.EmployeeContext, role types.EmployeeRole, roleId *int) error {
+	if role == types.EmployeeRoleOwner {
+		return fmt.Errorf("error there can only be 1 owner")
+	}
+
+	err := checkGrantablePermissions(employee, role.DefaultPermissions())
+	if err != nil {
+		return err
+	}
+
+	if roleId == nil {
+		return nil
+	}
+
+	customRole, err := s.teamRepo.GetRole(ctx, employee.MerchantId, *roleId)
+	if err != nil {
+		return fmt.Errorf("role could not be found for this merchant")
+	}
+
+	return checkGrantablePermissions(employee, customRole.Permissions)
+}
+
+// Sorted permissions without duplicates
+func uniquePermissions(permissions []types.Permission) []types.Permission {
+	unique := slices.Clone(permissions)
+	slices.SortFunc(unique, func(a, b types.Permission) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return slices.Compact(unique)
+}
+
+type RoleInput struct {
+	Name        string
+	Permissions []types.Permission
+}
+
+func (s *Service) NewRole(ctx context.Context, input RoleInput) (int, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	err := checkGrantablePermissions(actor, input.Permissions)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.teamRepo.NewRole(ctx, actor.MerchantId, domain.Role{
+		Name:        input.Name,
+		Permissions: uniquePermissions(input.Permissions),
+	})
+}
+
+func (s *Service) UpdateRole(ctx context.Context, roleId int, input RoleInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	err := checkGrantablePermissions(actor, input.Permissions)
+	if err != nil {
+		return err
+	}
+
+	// the role might already hold permissions that the employee can not revoke
+	existing, err := s.teamRepo.GetRole(ctx, actor.MerchantId, roleId)
+	if err != nil {
+		return err
+	}
+
+	err = checkGrantablePermissions(actor, existing.Permissions)
+	if err != nil {
+		return err
+	}
+
+	return s.teamRepo.UpdateRole(ctx, actor.MerchantId, domain.Role{
+		Id:          roleId,
+		Name:        input.Name,
+		Permissions: uniquePermissions(input.Permissions),
+	})
+}
+
+// Roles which are still assigned have to be taken away from the employees first
+func (s *Service) DeleteRole(ctx context.Context, roleId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	// the role might hold permissions that the employee can not revoke
+	existing, err := s.teamRepo.GetRole(ctx, actor.MerchantId, roleId)
+	if err != nil {
+		return err
+	}
+
+	err = checkGrantablePermissions(actor, existing.Permissions)
+	if err != nil {
+		return err
+	}
+
+	return s.teamRepo.DeleteRole(ctx, actor.MerchantId, roleId)
+}
+
+func (s *Service) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.teamRepo.GetRoles(ctx, actor.MerchantId)
+}
@@ -0,0 +1,51 @@
+package team
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRoleAssignable(t *testing.T) {
+	s := &Service{}
+	ctx := context.Background()
+
+	manager := actor.EmployeeContext{Permissions: []types.Permission{
+		types.PermissionTeamManage,
+		types.PermissionBookingsManageOwn,
+		types.PermissionCustomersView,
+		types.PermissionBlockedTimesManageOwn,
+	}}
+
+	assert.NoError(t, s.checkRoleAssignable(ctx, manager, types.EmployeeRoleStaff, nil))
+	assert.Error(t, s.checkRoleAssignable(ctx, manager, types.EmployeeRoleAdmin, nil), "admin escalation")
+	assert.Error(t, s.checkRoleAssignable(ctx, manager, types.EmployeeRoleOwner, nil), "second owner")
+
+	admin := actor.EmployeeContext{Permissions: types.EmployeeRoleAdmin.DefaultPermissions()}
+	assert.NoError(t, s.checkRoleAssignable(ctx, admin, types.EmployeeRoleAdmin, nil))
+}
+
+func TestUniquePermissions(t *testing.T) {
+	permissions := uniquePermissions([]types.Permission{
+		types.PermissionTeamManage,
+		types.PermissionCatalogEdit,
+		types.PermissionTeamManage,
+		types.PermissionCatalogEdit,
+		types.PermissionTeamManage,
+	})
+
+	assert.Equal(t, []types.Permission{types.PermissionCatalogEdit, types.PermissionTeamManage}, permissions)
+}
+
+func TestSameRoleId(t *testing.T) {
+	one, otherOne, two := 1, 1, 2
+
+	assert.True(t, sameRoleId(nil, nil))
+	assert.True(t, sameRoleId(&one, &otherOne))
+	assert.False(t, sameRoleId(&one, &two))
+	assert.False(t, sameRoleId(&one, nil))
+	assert.False(t, sameRoleId(nil, &two))
+}
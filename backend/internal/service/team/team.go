@@ -3,22 +3,31 @@ package team
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/service/audit"
 	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
 )
 
 type Service struct {
-	teamRepo domain.TeamRepository
-	userRepo domain.UserRepository
+	teamRepo     domain.TeamRepository
+	userRepo     domain.UserRepository
+	auditLogRepo domain.AuditLogRepository
+	txManager    db.TransactionManager
 }
 
-func NewService(team domain.TeamRepository, user domain.UserRepository) *Service {
+func NewService(team domain.TeamRepository, user domain.UserRepository, auditLog domain.AuditLogRepository,
+	txManager db.TransactionManager) *Service {
 	return &Service{
-		teamRepo: team,
-		userRepo: user,
+		teamRepo:     team,
+		userRepo:     user,
+		auditLogRepo: auditLog,
+		txManager:    txManager,
 	}
 }
 
@@ -48,6 +57,7 @@ func (s *Service) Me(ctx context.Context) (MeResult, error) {
 
 type NewMemberInput struct {
 	Role        types.EmployeeRole
+	RoleId      *int
 	FirstName   string
 	LastName    string
 	Email       *string
@@ -56,14 +66,16 @@ type NewMemberInput struct {
 }
 
 func (s *Service) NewMember(ctx context.Context, input NewMemberInput) error {
-	if input.Role == types.EmployeeRoleOwner {
-		return fmt.Errorf("error there can only be 1 owner")
-	}
-
 	actor := actor.MustGetFromContext(ctx)
 
-	err := s.teamRepo.NewEmployee(ctx, actor.MerchantId, domain.PublicEmployee{
+	err := s.checkRoleAssignable(ctx, actor, input.Role, input.RoleId)
+	if err != nil {
+		return err
+	}
+
+	err = s.teamRepo.NewEmployee(ctx, actor.MerchantId, domain.PublicEmployee{
 		Role:        input.Role,
+		RoleId:      input.RoleId,
 		FirstName:   &input.FirstName,
 		LastName:    &input.LastName,
 		Email:       input.Email,
@@ -79,6 +91,7 @@ func (s *Service) NewMember(ctx context.Context, input NewMemberInput) error {
 
 type UpdateMemberInput struct {
 	Role        types.EmployeeRole
+	RoleId      *int
 	FirstName   string
 	LastName    string
 	Email       *string
@@ -86,38 +99,112 @@ type UpdateMemberInput struct {
 	IsActive    bool
 }
 
-func (s *Service) UpdateMember(ctx context.Context, memberId int, input UpdateMemberInput) error {
-	if input.Role == types.EmployeeRoleOwner {
-		return fmt.Errorf("error there can only be 1 owner")
+func sameRoleId(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
 
+	return *a == *b
+}
+
+// The owner is managed through their own account, and employees can edit their own details but not their role
+func (s *Service) UpdateMember(ctx context.Context, memberId int, input UpdateMemberInput) error {
 	actor := actor.MustGetFromContext(ctx)
 
-	err := s.teamRepo.UpdateEmployee(ctx, actor.MerchantId, domain.PublicEmployee{
+	member := domain.PublicEmployee{
 		Id:          memberId,
 		Role:        input.Role,
+		RoleId:      input.RoleId,
 		FirstName:   &input.FirstName,
 		LastName:    &input.LastName,
 		Email:       input.Email,
 		PhoneNumber: input.PhoneNumber,
 		IsActive:    input.IsActive,
-	})
-	if err != nil {
-		return err
 	}
 
-	return nil
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		before, err := s.teamRepo.WithTx(tx).GetEmployee(ctx, actor.MerchantId, memberId)
+		if err != nil {
+			return err
+		}
+
+		if before.Role == types.EmployeeRoleOwner {
+			return fmt.Errorf("the owner can not be changed")
+		}
+
+		if memberId == actor.EmployeeId {
+			if member.Role != before.Role || !sameRoleId(member.RoleId, before.RoleId) {
+				return fmt.Errorf("you can not change your own role")
+			}
+
+			if !member.IsActive {
+				return fmt.Errorf("you can not deactivate yourself")
+			}
+		} else {
+			// the member might already hold permissions that the employee can not revoke
+			err = s.checkRoleAssignable(ctx, actor, before.Role, before.RoleId)
+			if err != nil {
+				return err
+			}
+
+			err = s.checkRoleAssignable(ctx, actor, member.Role, member.RoleId)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.teamRepo.WithTx(tx).UpdateEmployee(ctx, actor.MerchantId, member)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "employee.updated",
+			EntityType: types.AuditEntityEmployee,
+			EntityId:   strconv.Itoa(memberId),
+			Before:     newEmployeeAuditState(before),
+			After:      newEmployeeAuditState(member),
+		})
+	})
 }
 
+// Same as updating, the owner and the employee themselves can not be removed
 func (s *Service) DeleteMember(ctx context.Context, memberId int) error {
 	actor := actor.MustGetFromContext(ctx)
 
-	err := s.teamRepo.DeleteEmployee(ctx, actor.MerchantId, memberId)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		before, err := s.teamRepo.WithTx(tx).GetEmployee(ctx, actor.MerchantId, memberId)
+		if err != nil {
+			return err
+		}
+
+		if before.Role == types.EmployeeRoleOwner {
+			return fmt.Errorf("the owner can not be removed")
+		}
+
+		if memberId == actor.EmployeeId {
+			return fmt.Errorf("you can not remove yourself")
+		}
+
+		// the member might hold permissions that the employee can not revoke
+		err = s.checkRoleAssignable(ctx, actor, before.Role, before.RoleId)
+		if err != nil {
+			return err
+		}
+
+		err = s.teamRepo.WithTx(tx).DeleteEmployee(ctx, actor.MerchantId, memberId)
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(ctx, s.auditLogRepo.WithTx(tx), audit.Change{
+			Action:     "employee.deleted",
+			EntityType: types.AuditEntityEmployee,
+			EntityId:   strconv.Itoa(memberId),
+			Before:     newEmployeeAuditState(before),
+			After:      nil,
+		})
+	})
 }
 
 func (s *Service) GetMember(ctx context.Context, memberId int) (domain.PublicEmployee, error) {
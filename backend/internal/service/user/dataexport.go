@@ -0,0 +1,351 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/lang"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/riverqueue/river"
+	"golang.org/x/text/language"
+)
+
+const (
+	// how long the archive can be downloaded after it was made
+	dataExportExpiry = 7 * 24 * time.Hour
+	// a new export can only be requested once this much time passed since the last one
+	dataExportInterval = 24 * time.Hour
+)
+
+// Schedules the export of every data stored about the user, returns the id of the export
+func (s *Service) RequestDataExport(ctx context.Context) (int, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	exports, err := s.userRepo.GetDataExports(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if export.Status != types.DataExportFailed && time.Since(export.CreatedAt) < dataExportInterval {
+			return 0, fmt.Errorf("a data export can only be requested once a day")
+		}
+	}
+
+	var exportId int
+
+	err = s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		exportId, err = s.userRepo.WithTx(tx).NewDataExport(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.UserDataExport{
+			UserId:   userId,
+			ExportId: exportId,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule data export job: %w", err)
+		}
+
+		return nil
+	})
+
+	return exportId, err
+}
+
+func (s *Service) GetDataExports(ctx context.Context) ([]domain.DataExport, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	return s.userRepo.GetDataExports(ctx, userId)
+}
+
+func (s *Service) GetDataExport(ctx context.Context, exportId int) (domain.DataExport, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	return s.userRepo.GetDataExport(ctx, userId, exportId)
+}
+
+// Returns the zip archive of a completed export which did not expire yet
+func (s *Service) DownloadDataExport(ctx context.Context, exportId int) ([]byte, error) {
+	userId := jwt.MustGetUserIDFromContext(ctx)
+
+	file, err := s.userRepo.GetDataExportFile(ctx, userId, exportId)
+	if err != nil {
+		return nil, fmt.Errorf("the data export is not ready or it has expired: %s", err.Error())
+	}
+
+	return file, nil
+}
+
+// Collects the data of the user into a zip archive, a retried job starts over
+func (s *Service) RunDataExport(ctx context.Context, userId uuid.UUID, exportId int) error {
+	export, err := s.userRepo.GetDataExport(ctx, userId, exportId)
+	if err != nil {
+		return err
+	}
+
+	if export.Status == types.DataExportCompleted || export.Status == types.DataExportFailed {
+		return nil
+	}
+
+	err = s.userRepo.UpdateDataExport(ctx, exportId, domain.DataExportResult{Status: types.DataExportRunning})
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := s.userRepo.GetActiveSessions(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	customers, err := s.userRepo.GetUserDataCustomers(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := s.userRepo.GetUserDataBookings(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	file, err := buildDataExport(user, sessions, customers, bookings)
+	if err != nil {
+		// the same data would fail again
+		message := err.Error()
+
+		if err := s.userRepo.UpdateDataExport(ctx, exportId, domain.DataExportResult{
+			Status: types.DataExportFailed,
+			Error:  &message,
+		}); err != nil {
+			return err
+		}
+
+		return river.JobCancel(err)
+	}
+
+	expiresAt := time.Now().UTC().Add(dataExportExpiry)
+
+	return s.userRepo.UpdateDataExport(ctx, exportId, domain.DataExportResult{
+		Status:    types.DataExportCompleted,
+		File:      file,
+		ExpiresAt: &expiresAt,
+	})
+}
+
+type exportedUser struct {
+	Id           uuid.UUID `json:"id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Email        string    `json:"email"`
+	PhoneNumber  *string   `json:"phone_number"`
+	Language     string    `json:"language"`
+	AuthProvider *string   `json:"auth_provider"`
+}
+
+type exportedSession struct {
+	DeviceName string    `json:"device_name"`
+	IpAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// The data a merchant stores about the user
+type exportedCustomer struct {
+	Id              uuid.UUID      `json:"id"`
+	MerchantName    string         `json:"merchant_name"`
+	FirstName       *string        `json:"first_name"`
+	LastName        *string        `json:"last_name"`
+	Email           *string        `json:"email"`
+	PhoneNumber     *string        `json:"phone_number"`
+	Birthday        *string        `json:"birthday"`
+	Note            *string        `json:"note"`
+	IsBlacklisted   bool           `json:"is_blacklisted"`
+	BlacklistReason *string        `json:"blacklist_reason"`
+	Tags            []string       `json:"tags"`
+	CustomFields    map[string]any `json:"custom_fields"`
+}
+
+type exportedBooking struct {
+	BookingId          int                      `json:"booking_id"`
+	CustomerId         uuid.UUID                `json:"customer_id"`
+	MerchantName       string                   `json:"merchant_name"`
+	ServiceName        string                   `json:"service_name"`
+	BookingType        types.BookingType        `json:"booking_type"`
+	BookingStatus      types.BookingStatus      `json:"booking_status"`
+	Status             types.BookingStatus      `json:"status"`
+	FromDate           time.Time                `json:"from_date"`
+	ToDate             time.Time                `json:"to_date"`
+	Price              currencyx.FormattedPrice `json:"price"`
+	Location           string                   `json:"location"`
+	Note               *string                  `json:"note"`
+	CancelledOn        *time.Time               `json:"cancelled_on"`
+	CancellationReason *string                  `json:"cancellation_reason"`
+	IntakeForms        []exportedIntakeForm     `json:"intake_forms"`
+}
+
+type exportedIntakeAnswer struct {
+	Question string `json:"question"`
+	Answer   any    `json:"answer"`
+}
+
+type exportedIntakeForm struct {
+	FormName  string                 `json:"form_name"`
+	Answers   []exportedIntakeAnswer `json:"answers"`
+	Signature *string                `json:"signature"`
+	SignedAt  *time.Time             `json:"signed_at"`
+}
+
+// Pairs the answers with the questions they were given to, in the order of the form
+func exportIntakeForms(responses []domain.UserDataIntakeResponse) []exportedIntakeForm {
+	forms := make([]exportedIntakeForm, len(responses))
+
+	for i, response := range responses {
+		answers := []exportedIntakeAnswer{}
+		for _, question := range response.Questions {
+			if answer, ok := response.Answers[question.Id]; ok {
+				answers = append(answers, exportedIntakeAnswer{Question: question.Label, Answer: answer})
+			}
+		}
+
+		forms[i] = exportedIntakeForm{
+			FormName:  response.FormName,
+			Answers:   answers,
+			Signature: response.Signature,
+			SignedAt:  response.SignedAt,
+		}
+	}
+
+	return forms
+}
+
+// Builds a zip archive with a json file for every kind of data
+func buildDataExport(user domain.User, sessions []domain.Session, customers []domain.UserDataCustomer,
+	bookings []domain.UserDataBooking) ([]byte, error) {
+	exportUser := exportedUser{
+		Id:          user.Id,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Language:    user.Language,
+	}
+
+	if user.AuthProvider != nil {
+		provider := user.AuthProvider.String()
+		exportUser.AuthProvider = &provider
+	}
+
+	exportSessions := make([]exportedSession, len(sessions))
+	for i, session := range sessions {
+		exportSessions[i] = exportedSession{
+			DeviceName: session.DeviceName,
+			IpAddress:  session.IpAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+
+	exportCustomers := make([]exportedCustomer, len(customers))
+	for i, customer := range customers {
+		var birthday *string
+		if customer.Birthday != nil {
+			date := customer.Birthday.Format(time.DateOnly)
+			birthday = &date
+		}
+
+		exportCustomers[i] = exportedCustomer{
+			Id:              customer.Id,
+			MerchantName:    customer.MerchantName,
+			FirstName:       customer.FirstName,
+			LastName:        customer.LastName,
+			Email:           customer.Email,
+			PhoneNumber:     customer.PhoneNumber,
+			Birthday:        birthday,
+			Note:            customer.Note,
+			IsBlacklisted:   customer.IsBlacklisted,
+			BlacklistReason: customer.BlacklistReason,
+			Tags:            customer.Tags,
+			CustomFields:    customer.CustomFields,
+		}
+	}
+
+	// prices are formatted the way the user sees them in the app
+	locale, err := language.Parse(user.Language)
+	if err != nil {
+		locale = lang.GetDefaultLang()
+	}
+
+	exportBookings := make([]exportedBooking, len(bookings))
+	for i, booking := range bookings {
+		exportBookings[i] = exportedBooking{
+			BookingId:          booking.BookingId,
+			CustomerId:         booking.CustomerId,
+			MerchantName:       booking.MerchantName,
+			ServiceName:        booking.ServiceName,
+			BookingType:        booking.BookingType,
+			BookingStatus:      booking.BookingStatus,
+			Status:             booking.Status,
+			FromDate:           booking.FromDate,
+			ToDate:             booking.ToDate,
+			Price:              booking.PricePerPerson.ToFormatted(locale),
+			Location:           booking.FormattedLocation,
+			Note:               booking.CustomerNote,
+			CancelledOn:        booking.CancelledOn,
+			CancellationReason: booking.CancellationReason,
+			IntakeForms:        exportIntakeForms(booking.IntakeResponses),
+		}
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"user.json", exportUser},
+		{"sessions.json", exportSessions},
+		{"customers.json", exportCustomers},
+		{"bookings.json", exportBookings},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("could not add %s to the archive: %s", file.name, err.Error())
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, fmt.Errorf("could not encode %s: %s", file.name, err.Error())
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("could not create the archive: %s", err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,99 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bojanz/currency"
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDataExport(t *testing.T) {
+	str := func(s string) *string { return &s }
+	birthday := time.Date(1990, 5, 21, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	price, _ := currency.NewAmount("4500", "HUF")
+
+	user := domain.User{
+		Id: uuid.New(), FirstName: "Eva", LastName: "Kovacs", Email: "eva@example.com", Language: "hu",
+		PasswordHash: str("secret-hash"), AuthProvider: &types.AuthProviderTypeGoogle,
+	}
+	customerId := uuid.New()
+
+	file, err := buildDataExport(user,
+		[]domain.Session{{DeviceName: "Firefox on Linux", IpAddress: "127.0.0.1", RefreshTokenHash: "secret-token"}},
+		[]domain.UserDataCustomer{{
+			Id: customerId, MerchantName: "Hair Studio", Note: str("Prefers mornings"), Birthday: &birthday,
+			Tags: []string{"vip"}, CustomFields: map[string]any{"Allergies": "latex"},
+		}},
+		[]domain.UserDataBooking{{
+			BookingId: 12, CustomerId: customerId, MerchantName: "Hair Studio", ServiceName: "Haircut",
+			BookingType: types.BookingTypeAppointment, BookingStatus: types.BookingStatusCompleted, Status: types.BookingStatusCompleted,
+			FromDate: from, ToDate: from.Add(time.Hour), PricePerPerson: currencyx.Price{Amount: price}, FormattedLocation: "Budapest",
+			IntakeResponses: []domain.UserDataIntakeResponse{{
+				FormName: "Allergies",
+				Questions: []domain.IntakeQuestion{
+					{Id: "q1", Label: "Any allergies?", Type: types.IntakeQuestionYesNo},
+					{Id: "q2", Label: "Which ones?", Type: types.IntakeQuestionText},
+				},
+				Answers: domain.IntakeAnswers{"q1": false},
+			}},
+		}},
+	)
+	assert.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(file), int64(len(file)))
+	assert.NoError(t, err)
+
+	contents := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+
+		content, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		r.Close()
+
+		contents[f.Name] = content
+	}
+
+	assert.Len(t, contents, 4)
+
+	var exportUser map[string]any
+	assert.NoError(t, json.Unmarshal(contents["user.json"], &exportUser))
+	assert.Equal(t, "eva@example.com", exportUser["email"])
+	assert.Equal(t, "google", exportUser["auth_provider"])
+
+	// secrets are not part of the export
+	assert.NotContains(t, string(contents["user.json"]), "secret-hash")
+	assert.NotContains(t, string(contents["sessions.json"]), "secret-token")
+
+	var customers []map[string]any
+	assert.NoError(t, json.Unmarshal(contents["customers.json"], &customers))
+	assert.Len(t, customers, 1)
+	assert.Equal(t, "1990-05-21", customers[0]["birthday"])
+	assert.Equal(t, map[string]any{"Allergies": "latex"}, customers[0]["custom_fields"])
+
+	var bookings []map[string]any
+	assert.NoError(t, json.Unmarshal(contents["bookings.json"], &bookings))
+	assert.Len(t, bookings, 1)
+	assert.Equal(t, "Haircut", bookings[0]["service_name"])
+	assert.Equal(t, "completed", bookings[0]["status"])
+	assert.Equal(t, customerId.String(), bookings[0]["customer_id"])
+
+	// only the answered questions are exported, with their label
+	assert.Equal(t, []any{map[string]any{
+		"form_name": "Allergies",
+		"answers":   []any{map[string]any{"question": "Any allergies?", "answer": false}},
+		"signature": nil,
+		"signed_at": nil,
+	}}, bookings[0]["intake_forms"])
+}
@@ -3,20 +3,32 @@ package user
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/jwt"
 	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
 )
 
 type Service struct {
-	userRepo domain.UserRepository
+	userRepo     domain.UserRepository
+	customerRepo domain.CustomerRepository
+	enqueuer     queue.Enqueuer
+	txManager    db.TransactionManager
 }
 
-func NewService(user domain.UserRepository) *Service {
+func NewService(user domain.UserRepository, customer domain.CustomerRepository, txManager db.TransactionManager) *Service {
 	return &Service{
-		userRepo: user,
+		userRepo:     user,
+		customerRepo: customer,
+		txManager:    txManager,
 	}
 }
 
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
 type EditInput struct {
 	FirstName   string
 	LastName    string
@@ -41,14 +53,24 @@ func (s *Service) Edit(ctx context.Context, input EditInput) error {
 	return nil
 }
 
-// TODO: this should be tested at some point.
+// The customer records of the user are anonymized instead of deleted,
+// so the merchants keep their bookings for the statistics
 func (s *Service) Delete(ctx context.Context) error {
 	userId := jwt.MustGetUserIDFromContext(ctx)
 
-	err := s.userRepo.DeleteUser(ctx, userId)
-	if err != nil {
-		return err
-	}
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		customerIds, err := s.customerRepo.WithTx(tx).GetCustomerIdsByUser(ctx, userId)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if len(customerIds) > 0 {
+			err = s.customerRepo.WithTx(tx).AnonymizeCustomers(ctx, customerIds)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.userRepo.WithTx(tx).DeleteUser(ctx, userId)
+	})
 }
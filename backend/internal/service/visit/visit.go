@@ -0,0 +1,405 @@
+package visit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+)
+
+const (
+	MaxAttachmentSize      = 10 << 20
+	maxAttachmentsPerVisit = 20
+	maxFileNameLength      = 255
+	maxNoteLength          = 5000
+	// number of deleted attachments the cleanup removes at once
+	attachmentCleanupBatch = 100
+)
+
+// The content type is detected from the file itself, the one sent by the client is not trusted
+var allowedContentTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif", "application/pdf"}
+
+type Service struct {
+	visitRepo domain.VisitRepository
+	blobStore domain.BlobStore
+}
+
+func NewService(visit domain.VisitRepository, blobStore domain.BlobStore) *Service {
+	return &Service{
+		visitRepo: visit,
+		blobStore: blobStore,
+	}
+}
+
+// Employees who can see every visit note, or the ones of the bookings they can manage
+func canViewVisit(employee actor.EmployeeContext, employeeId *int) error {
+	if employee.HasPermission(types.PermissionCustomersViewNotes) {
+		return nil
+	}
+
+	return canEditVisit(employee, employeeId)
+}
+
+func canEditVisit(employee actor.EmployeeContext, employeeId *int) error {
+	if employee.HasPermission(types.PermissionBookingsManageAll) {
+		return nil
+	}
+
+	if employee.HasPermission(types.PermissionBookingsManageOwn) && employeeId != nil && *employeeId == employee.EmployeeId {
+		return nil
+	}
+
+	return fmt.Errorf("you can only access the notes of your own bookings")
+}
+
+// Notes and attachments can only be changed by their author, or by someone who manages every booking
+func canChangeRecord(employee actor.EmployeeContext, authorId *int) error {
+	if employee.HasPermission(types.PermissionBookingsManageAll) {
+		return nil
+	}
+
+	if authorId != nil && *authorId == employee.EmployeeId {
+		return nil
+	}
+
+	return fmt.Errorf("you can only change your own notes and attachments")
+}
+
+// Visits of the customer, only the ones the employee can see have their notes and attachments
+func (s *Service) GetCustomerVisits(ctx context.Context, customerId uuid.UUID) ([]domain.VisitRecord, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	visits, err := s.visitRepo.GetCustomerVisits(ctx, employee.MerchantId, customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []int{}
+	for _, visit := range visits {
+		if canViewVisit(employee, visit.EmployeeId) == nil {
+			visible = append(visible, visit.ParticipantId)
+		}
+	}
+
+	notes, err := s.visitRepo.GetVisitNotes(ctx, employee.MerchantId, visible)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.visitRepo.GetVisitAttachments(ctx, employee.MerchantId, visible)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupVisitRecords(visits, notes, attachments), nil
+}
+
+func groupVisitRecords(visits []domain.Visit, notes []domain.VisitNote, attachments []domain.VisitAttachment) []domain.VisitRecord {
+	records := make([]domain.VisitRecord, len(visits))
+	indexes := make(map[int]int, len(visits))
+
+	for i, visit := range visits {
+		records[i] = domain.VisitRecord{Visit: visit, Notes: []domain.VisitNote{}, Attachments: []domain.VisitAttachment{}}
+		indexes[visit.ParticipantId] = i
+	}
+
+	for _, note := range notes {
+		if i, ok := indexes[note.ParticipantId]; ok {
+			records[i].Notes = append(records[i].Notes, note)
+		}
+	}
+
+	for _, attachment := range attachments {
+		if attachment.ParticipantId == nil {
+			continue
+		}
+
+		if i, ok := indexes[*attachment.ParticipantId]; ok {
+			records[i].Attachments = append(records[i].Attachments, attachment)
+		}
+	}
+
+	return records
+}
+
+func (s *Service) getEditableVisit(ctx context.Context, participantId int) (domain.Visit, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	visit, err := s.visitRepo.GetVisit(ctx, employee.MerchantId, participantId)
+	if err != nil {
+		return domain.Visit{}, fmt.Errorf("visit could not be found for this merchant")
+	}
+
+	return visit, canEditVisit(employee, visit.EmployeeId)
+}
+
+func (s *Service) NewNote(ctx context.Context, participantId int, note string) (int, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	note, err := normalizeNote(note)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.getEditableVisit(ctx, participantId); err != nil {
+		return 0, err
+	}
+
+	return s.visitRepo.NewVisitNote(ctx, domain.VisitNote{
+		MerchantId:    employee.MerchantId,
+		ParticipantId: participantId,
+		EmployeeId:    &employee.EmployeeId,
+		Note:          note,
+	})
+}
+
+func (s *Service) UpdateNote(ctx context.Context, participantId int, noteId int, note string) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	note, err := normalizeNote(note)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.getChangeableNote(ctx, participantId, noteId)
+	if err != nil {
+		return err
+	}
+
+	return s.visitRepo.UpdateVisitNote(ctx, employee.MerchantId, existing.Id, note)
+}
+
+func (s *Service) DeleteNote(ctx context.Context, participantId int, noteId int) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	existing, err := s.getChangeableNote(ctx, participantId, noteId)
+	if err != nil {
+		return err
+	}
+
+	return s.visitRepo.DeleteVisitNote(ctx, employee.MerchantId, existing.Id)
+}
+
+func (s *Service) getChangeableNote(ctx context.Context, participantId int, noteId int) (domain.VisitNote, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	if _, err := s.getEditableVisit(ctx, participantId); err != nil {
+		return domain.VisitNote{}, err
+	}
+
+	note, err := s.visitRepo.GetVisitNote(ctx, employee.MerchantId, noteId)
+	if err != nil || note.ParticipantId != participantId {
+		return domain.VisitNote{}, fmt.Errorf("note could not be found for this visit")
+	}
+
+	return note, canChangeRecord(employee, note.EmployeeId)
+}
+
+func normalizeNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+
+	if note == "" {
+		return "", fmt.Errorf("the note can not be empty")
+	}
+
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return "", fmt.Errorf("the note can be at most %d characters long", maxNoteLength)
+	}
+
+	return note, nil
+}
+
+type AttachmentInput struct {
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
+
+func (s *Service) NewAttachment(ctx context.Context, participantId int, input AttachmentInput) (int, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	if input.Size <= 0 || input.Size > MaxAttachmentSize {
+		return 0, fmt.Errorf("attachments can be at most %d MB", MaxAttachmentSize>>20)
+	}
+
+	if _, err := s.getEditableVisit(ctx, participantId); err != nil {
+		return 0, err
+	}
+
+	count, err := s.visitRepo.CountVisitAttachments(ctx, employee.MerchantId, participantId)
+	if err != nil {
+		return 0, err
+	}
+
+	if count >= maxAttachmentsPerVisit {
+		return 0, fmt.Errorf("a visit can have at most %d attachments", maxAttachmentsPerVisit)
+	}
+
+	content, contentType, err := detectContentType(input.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	storageKey := fmt.Sprintf("visits/%s/%d/%s", employee.MerchantId, participantId, uuid.New())
+
+	err = s.blobStore.Put(ctx, storageKey, content, input.Size, contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	attachmentId, err := s.visitRepo.NewVisitAttachment(ctx, domain.VisitAttachment{
+		MerchantId:    employee.MerchantId,
+		ParticipantId: &participantId,
+		EmployeeId:    &employee.EmployeeId,
+		FileName:      sanitizeFileName(input.FileName),
+		ContentType:   contentType,
+		Size:          input.Size,
+		StorageKey:    storageKey,
+	})
+	if err != nil {
+		if deleteErr := s.blobStore.Delete(ctx, storageKey); deleteErr != nil {
+			slog.ErrorContext(ctx, "could not delete blob of failed attachment", "key", storageKey, "error", deleteErr)
+		}
+
+		return 0, err
+	}
+
+	return attachmentId, nil
+}
+
+// Detects the type from the first bytes of the content, the returned reader still yields the whole content
+func detectContentType(content io.Reader) (io.Reader, string, error) {
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, "", fmt.Errorf("could not read the attachment: %s", err.Error())
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil || !slices.Contains(allowedContentTypes, contentType) {
+		return nil, "", fmt.Errorf("only jpeg, png, webp, gif images and pdf files can be attached")
+	}
+
+	return io.MultiReader(bytes.NewReader(head[:n]), content), contentType, nil
+}
+
+// Keeps only the base name of the file without control characters
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+
+	if utf8.RuneCountInString(name) > maxFileNameLength {
+		name = string([]rune(name)[:maxFileNameLength])
+	}
+
+	return name
+}
+
+// Returns the attachment with its content, the caller has to close it
+func (s *Service) GetAttachment(ctx context.Context, participantId int, attachmentId int) (domain.VisitAttachment, io.ReadCloser, error) {
+	employee := actor.MustGetFromContext(ctx)
+
+	visit, err := s.visitRepo.GetVisit(ctx, employee.MerchantId, participantId)
+	if err != nil {
+		return domain.VisitAttachment{}, nil, fmt.Errorf("visit could not be found for this merchant")
+	}
+
+	if err := canViewVisit(employee, visit.EmployeeId); err != nil {
+		return domain.VisitAttachment{}, nil, err
+	}
+
+	attachment, err := s.visitRepo.GetVisitAttachment(ctx, employee.MerchantId, attachmentId)
+	if err != nil || attachment.ParticipantId == nil || *attachment.ParticipantId != participantId {
+		return domain.VisitAttachment{}, nil, fmt.Errorf("attachment could not be found for this visit")
+	}
+
+	content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return domain.VisitAttachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// The file itself is removed by the cleanup job
+func (s *Service) DeleteAttachment(ctx context.Context, participantId int, attachmentId int) error {
+	employee := actor.MustGetFromContext(ctx)
+
+	if _, err := s.getEditableVisit(ctx, participantId); err != nil {
+		return err
+	}
+
+	attachment, err := s.visitRepo.GetVisitAttachment(ctx, employee.MerchantId, attachmentId)
+	if err != nil || attachment.ParticipantId == nil || *attachment.ParticipantId != participantId {
+		return fmt.Errorf("attachment could not be found for this visit")
+	}
+
+	if err := canChangeRecord(employee, attachment.EmployeeId); err != nil {
+		return err
+	}
+
+	return s.visitRepo.DeleteVisitAttachment(ctx, employee.MerchantId, attachment.Id)
+}
+
+// Removes the files of the deleted attachments, the ones which could not be removed are retried on the next run
+func (s *Service) CleanupAttachments(ctx context.Context) error {
+	for {
+		attachments, err := s.visitRepo.GetDeletedVisitAttachments(ctx, attachmentCleanupBatch)
+		if err != nil {
+			return err
+		}
+
+		removed := []int{}
+		var firstErr error
+
+		for _, attachment := range attachments {
+			if err := s.blobStore.Delete(ctx, attachment.StorageKey); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+
+			removed = append(removed, attachment.Id)
+		}
+
+		if len(removed) > 0 {
+			if err := s.visitRepo.PurgeVisitAttachments(ctx, removed); err != nil {
+				return err
+			}
+		}
+
+		if firstErr != nil {
+			return firstErr
+		}
+
+		if len(attachments) < attachmentCleanupBatch {
+			return nil
+		}
+	}
+}
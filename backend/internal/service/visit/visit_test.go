@@ -0,0 +1,82 @@
+package visit
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, "scan.pdf", sanitizeFileName("scan.pdf"))
+	assert.Equal(t, "passwd", sanitizeFileName("../../etc/passwd"))
+	assert.Equal(t, "photo.png", sanitizeFileName("C:\\Users\\me\\photo.png"))
+	assert.Equal(t, "ab.jpg", sanitizeFileName("a\"\nb.jpg"))
+	assert.Equal(t, "attachment", sanitizeFileName(""))
+	assert.Equal(t, "attachment", sanitizeFileName("/"))
+	assert.Len(t, []rune(sanitizeFileName(strings.Repeat("á", 300))), maxFileNameLength)
+}
+
+func TestDetectContentType(t *testing.T) {
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 1000)...)
+
+	content, contentType, err := detectContentType(bytes.NewReader(png))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	read, err := io.ReadAll(content)
+	assert.NoError(t, err)
+	assert.Equal(t, png, read)
+
+	pdf := []byte("%PDF-1.7\n")
+	_, contentType, err = detectContentType(bytes.NewReader(pdf))
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+
+	_, _, err = detectContentType(strings.NewReader("<html><script>alert(1)</script></html>"))
+	assert.Error(t, err)
+
+	_, _, err = detectContentType(strings.NewReader("just some text"))
+	assert.Error(t, err)
+}
+
+func TestGroupVisitRecords(t *testing.T) {
+	first, second := 1, 2
+	visits := []domain.Visit{{ParticipantId: first}, {ParticipantId: second}}
+	notes := []domain.VisitNote{{Id: 10, ParticipantId: second}, {Id: 11, ParticipantId: 99}}
+	attachments := []domain.VisitAttachment{{Id: 20, ParticipantId: &first}, {Id: 21, ParticipantId: nil}}
+
+	records := groupVisitRecords(visits, notes, attachments)
+
+	assert.Len(t, records, 2)
+	assert.Empty(t, records[0].Notes)
+	assert.Len(t, records[0].Attachments, 1)
+	assert.Equal(t, 20, records[0].Attachments[0].Id)
+	assert.Len(t, records[1].Notes, 1)
+	assert.Equal(t, 10, records[1].Notes[0].Id)
+	assert.Empty(t, records[1].Attachments)
+}
+
+func TestVisitPermissions(t *testing.T) {
+	own, other := 1, 2
+
+	staff := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionBookingsManageOwn}}
+	assert.NoError(t, canViewVisit(staff, &own))
+	assert.Error(t, canViewVisit(staff, &other))
+	assert.Error(t, canViewVisit(staff, nil))
+	assert.NoError(t, canChangeRecord(staff, &own))
+	assert.Error(t, canChangeRecord(staff, &other))
+
+	notesViewer := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionCustomersViewNotes}}
+	assert.NoError(t, canViewVisit(notesViewer, &other))
+	assert.Error(t, canEditVisit(notesViewer, &other))
+
+	manager := actor.EmployeeContext{EmployeeId: own, Permissions: []types.Permission{types.PermissionBookingsManageAll}}
+	assert.NoError(t, canEditVisit(manager, &other))
+	assert.NoError(t, canChangeRecord(manager, &other))
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	for _, public := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"} {
+		assert.True(t, isPublicAddr(netip.MustParseAddr(public)), public)
+	}
+
+	for _, internal := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1",
+		"::1", "::", "fe80::1", "fc00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254", "224.0.0.1",
+	} {
+		assert.False(t, isPublicAddr(netip.MustParseAddr(internal)), internal)
+	}
+}
+
+func TestValidateEndpointUrl(t *testing.T) {
+	assert.NoError(t, validateEndpointUrl("https://example.com/webhooks"))
+
+	for _, invalid := range []string{
+		"http://example.com/webhooks",
+		"https://localhost/webhooks",
+		"https://api.localhost/webhooks",
+		"https://127.0.0.1/webhooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]:8443/webhooks",
+		"https://10.0.0.5/webhooks",
+		"/webhooks",
+	} {
+		assert.Error(t, validateEndpointUrl(invalid), invalid)
+	}
+}
+
+func TestHttpClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// nolint:bodyclose
+	_, err = newHttpClient().Do(req)
+	assert.True(t, errors.Is(err, errAddressNotAllowed), "got %v", err)
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// Body of every webhook request
+type Event struct {
+	Id        uuid.UUID              `json:"id"`
+	Type      types.WebhookEventType `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      json.RawMessage        `json:"data"`
+}
+
+// Creates the job which dispatches the event to the merchant's endpoints. It should be
+// inserted in the same transaction as the change, so no event is sent for rolled back changes
+func NewEvent(merchantId uuid.UUID, eventType types.WebhookEventType, data any) (args.DispatchWebhookEvent, error) {
+	eventId, err := uuid.NewV7()
+	if err != nil {
+		return args.DispatchWebhookEvent{}, fmt.Errorf("unexpected error during creating webhook event id: %s", err.Error())
+	}
+
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		return args.DispatchWebhookEvent{}, fmt.Errorf("could not encode webhook event data: %s", err.Error())
+	}
+
+	return args.DispatchWebhookEvent{
+		MerchantId: merchantId,
+		EventId:    eventId,
+		EventType:  eventType.String(),
+		OccurredAt: time.Now().UTC(),
+		Data:       encodedData,
+	}, nil
+}
+
+type BookingData struct {
+	Id                  int                 `json:"id"`
+	Status              types.BookingStatus `json:"status"`
+	BookingType         types.BookingType   `json:"booking_type"`
+	IsRecurring         bool                `json:"is_recurring"`
+	ServiceId           *int                `json:"service_id"`
+	ServiceName         string              `json:"service_name"`
+	EmployeeId          *int                `json:"employee_id"`
+	LocationId          int                 `json:"location_id"`
+	FromDate            time.Time           `json:"from_date"`
+	ToDate              time.Time           `json:"to_date"`
+	PricePerPerson      currencyx.Price     `json:"price_per_person"`
+	TotalPrice          currencyx.Price     `json:"total_price"`
+	CurrentParticipants int                 `json:"current_participants"`
+	MaxParticipants     int                 `json:"max_participants"`
+	CancellationReason  *string             `json:"cancellation_reason"`
+}
+
+// The id is passed separately as new bookings do not have it set
+func NewBookingData(bookingId int, booking domain.Booking) BookingData {
+	return BookingData{
+		Id:                  bookingId,
+		Status:              booking.Status,
+		BookingType:         booking.BookingType,
+		IsRecurring:         booking.IsRecurring,
+		ServiceId:           booking.ServiceId,
+		ServiceName:         booking.ServiceName,
+		EmployeeId:          booking.EmployeeId,
+		LocationId:          booking.LocationId,
+		FromDate:            booking.FromDate,
+		ToDate:              booking.ToDate,
+		PricePerPerson:      booking.PricePerPerson,
+		TotalPrice:          booking.TotalPrice,
+		CurrentParticipants: booking.CurrentParticipants,
+		MaxParticipants:     booking.MaxParticipants,
+		CancellationReason:  booking.CancellationReason,
+	}
+}
+
+type ParticipantStatusData struct {
+	BookingId      int                 `json:"booking_id"`
+	ParticipantId  int                 `json:"participant_id"`
+	CustomerId     *uuid.UUID          `json:"customer_id"`
+	Status         types.BookingStatus `json:"status"`
+	PreviousStatus types.BookingStatus `json:"previous_status"`
+}
+
+type CustomerData struct {
+	Id          uuid.UUID `json:"id"`
+	FirstName   *string   `json:"first_name"`
+	LastName    *string   `json:"last_name"`
+	Email       *string   `json:"email"`
+	PhoneNumber *string   `json:"phone_number"`
+}
+
+func NewCustomerData(customer domain.Customer) CustomerData {
+	return CustomerData{
+		Id:          customer.Id,
+		FirstName:   customer.FirstName,
+		LastName:    customer.LastName,
+		Email:       customer.Email,
+		PhoneNumber: customer.PhoneNumber,
+	}
+}
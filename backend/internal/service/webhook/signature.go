@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventIdHeader   = "X-Webhook-Id"
+	EventTypeHeader = "X-Webhook-Event"
+)
+
+// Signs the timestamp and the body with HMAC-SHA256. The receiver can verify it by computing
+// the same signature from the t value of the header and the raw request body.
+// The header has the format: t=<unix timestamp>,v1=<hex signature>
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 12 * time.Hour
+)
+
+// Exponential backoff for failed deliveries: 30s, 1m, 2m, 4m ... capped at 12 hours
+func RetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(retryBaseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(retryMaxDelay) {
+		return retryMaxDelay
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1","type":"booking.created"}`)
+
+	t.Run("matches precomputed signature", func(t *testing.T) {
+		// echo -n '1700000000.{"id":"1","type":"booking.created"}' | openssl dgst -sha256 -hmac whsec_test
+		expected := "t=1700000000,v1=dc7a10e9b8fce55f763a307157abf048b5afd159b8188406e9f07e0b4ad1cefa"
+
+		assert.Equal(t, expected, Sign("whsec_test", timestamp, body))
+	})
+
+	t.Run("different secret gives different signature", func(t *testing.T) {
+		assert.NotEqual(t, Sign("whsec_test", timestamp, body), Sign("whsec_other", timestamp, body))
+	})
+
+	t.Run("timestamp is part of the signature", func(t *testing.T) {
+		assert.NotEqual(t, Sign("whsec_test", timestamp, body), Sign("whsec_test", timestamp.Add(time.Second), body))
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{20, 12 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, RetryDelay(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
@@ -0,0 +1,386 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/miketsu-inc/reservations/backend/internal/api/middleware/actor"
+	"github.com/miketsu-inc/reservations/backend/internal/domain"
+	"github.com/miketsu-inc/reservations/backend/internal/jobs/args"
+	"github.com/miketsu-inc/reservations/backend/internal/types"
+	"github.com/miketsu-inc/reservations/backend/pkg/db"
+	"github.com/miketsu-inc/reservations/backend/pkg/queue"
+)
+
+// the rest of the response body is not read, it is never stored
+const maxDrainedBodySize = 4096
+
+type Service struct {
+	webhookRepo domain.WebhookRepository
+	enqueuer    queue.Enqueuer
+	txManager   db.TransactionManager
+	httpClient  *http.Client
+}
+
+func NewService(webhook domain.WebhookRepository, enqueuer queue.Enqueuer, txManager db.TransactionManager) *Service {
+	return &Service{
+		webhookRepo: webhook,
+		enqueuer:    enqueuer,
+		txManager:   txManager,
+		httpClient:  newHttpClient(),
+	}
+}
+
+func (s *Service) SetEnqueuer(client queue.Enqueuer) {
+	s.enqueuer = client
+}
+
+type EndpointInput struct {
+	Url         string
+	Description *string
+	Events      []types.WebhookEventType
+	IsActive    bool
+}
+
+func validateEndpointUrl(endpointUrl string) error {
+	u, err := url.Parse(endpointUrl)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %s", err.Error())
+	}
+
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook url has to be an absolute https url")
+	}
+
+	// hostnames are checked when they are resolved before every delivery
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return errAddressNotAllowed
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return errAddressNotAllowed
+	}
+
+	return nil
+}
+
+func newSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("unexpected error during creating webhook secret: %s", err.Error())
+	}
+
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+type NewEndpointResult struct {
+	Id int
+	// Only returned once, when the endpoint is created
+	Secret string
+}
+
+func (s *Service) NewEndpoint(ctx context.Context, input EndpointInput) (NewEndpointResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	if err := validateEndpointUrl(input.Url); err != nil {
+		return NewEndpointResult{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return NewEndpointResult{}, err
+	}
+
+	endpointId, err := s.webhookRepo.NewWebhookEndpoint(ctx, domain.WebhookEndpoint{
+		MerchantId:  actor.MerchantId,
+		Url:         input.Url,
+		Description: input.Description,
+		Secret:      secret,
+		Events:      input.Events,
+		IsActive:    input.IsActive,
+	})
+	if err != nil {
+		return NewEndpointResult{}, err
+	}
+
+	return NewEndpointResult{Id: endpointId, Secret: secret}, nil
+}
+
+func (s *Service) UpdateEndpoint(ctx context.Context, endpointId int, input EndpointInput) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	if err := validateEndpointUrl(input.Url); err != nil {
+		return err
+	}
+
+	return s.webhookRepo.UpdateWebhookEndpoint(ctx, domain.WebhookEndpoint{
+		Id:          endpointId,
+		MerchantId:  actor.MerchantId,
+		Url:         input.Url,
+		Description: input.Description,
+		Events:      input.Events,
+		IsActive:    input.IsActive,
+	})
+}
+
+func (s *Service) DeleteEndpoint(ctx context.Context, endpointId int) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.webhookRepo.DeleteWebhookEndpoint(ctx, actor.MerchantId, endpointId)
+}
+
+func (s *Service) GetEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	return s.webhookRepo.GetWebhookEndpoints(ctx, actor.MerchantId)
+}
+
+type GetDeliveriesResult struct {
+	Deliveries  []domain.WebhookDelivery
+	NextCursor  *string
+	HasNextPage bool
+}
+
+func (s *Service) GetDeliveries(ctx context.Context, endpointId int, cursor string, pageSize int) (GetDeliveriesResult, error) {
+	actor := actor.MustGetFromContext(ctx)
+
+	// makes sure the endpoint belongs to the merchant
+	_, err := s.webhookRepo.GetWebhookEndpoint(ctx, actor.MerchantId, endpointId)
+	if err != nil {
+		return GetDeliveriesResult{}, err
+	}
+
+	var cursorId *int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return GetDeliveriesResult{}, fmt.Errorf("invalid cursor")
+		}
+
+		cursorId = &id
+	}
+
+	// +1 so we can check if there is another page
+	deliveries, err := s.webhookRepo.GetWebhookDeliveries(ctx, endpointId, pageSize+1, cursorId)
+	if err != nil {
+		return GetDeliveriesResult{}, err
+	}
+
+	var nextCursor *string
+
+	hasNextPage := len(deliveries) > pageSize
+
+	if hasNextPage {
+		deliveries = deliveries[:pageSize]
+
+		cursorValue := strconv.FormatInt(deliveries[len(deliveries)-1].Id, 10)
+		nextCursor = &cursorValue
+	}
+
+	return GetDeliveriesResult{
+		Deliveries:  deliveries,
+		NextCursor:  nextCursor,
+		HasNextPage: hasNextPage,
+	}, nil
+}
+
+// Sends the event of a previous delivery again as a new delivery.
+// The event id stays the same, so receivers can deduplicate it
+func (s *Service) ReplayDelivery(ctx context.Context, endpointId int, deliveryId int64) error {
+	actor := actor.MustGetFromContext(ctx)
+
+	endpoint, err := s.webhookRepo.GetWebhookEndpoint(ctx, actor.MerchantId, endpointId)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := s.webhookRepo.GetWebhookDelivery(ctx, deliveryId)
+	if err != nil {
+		return err
+	}
+
+	if delivery.WebhookEndpointId != endpoint.Id {
+		return fmt.Errorf("delivery not found for this webhook endpoint")
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		newDeliveryId, err := s.webhookRepo.WithTx(tx).NewWebhookDelivery(ctx, domain.WebhookDelivery{
+			WebhookEndpointId: endpoint.Id,
+			EventId:           delivery.EventId,
+			EventType:         delivery.EventType,
+			Payload:           delivery.Payload,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.enqueuer.InsertTx(ctx, tx, args.DeliverWebhook{
+			DeliveryId: newDeliveryId,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("could not schedule webhook delivery job: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Creates a delivery for every endpoint which is subscribed to the event
+func (s *Service) Dispatch(ctx context.Context, event args.DispatchWebhookEvent) error {
+	eventType, err := types.NewWebhookEventType(event.EventType)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := s.webhookRepo.GetSubscribedWebhookEndpoints(ctx, event.MerchantId, eventType)
+	if err != nil {
+		return err
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(Event{
+		Id:        event.EventId,
+		Type:      eventType,
+		CreatedAt: event.OccurredAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode webhook event: %s", err.Error())
+	}
+
+	return s.txManager.WithTransaction(ctx, func(tx pgx.Tx) error {
+		for _, endpoint := range endpoints {
+			deliveryId, err := s.webhookRepo.WithTx(tx).NewWebhookDelivery(ctx, domain.WebhookDelivery{
+				WebhookEndpointId: endpoint.Id,
+				EventId:           event.EventId,
+				EventType:         eventType,
+				Payload:           payload,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = s.enqueuer.InsertTx(ctx, tx, args.DeliverWebhook{
+				DeliveryId: deliveryId,
+			}, nil)
+			if err != nil {
+				return fmt.Errorf("could not schedule webhook delivery job: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Sends the delivery to its endpoint and records the outcome. An error is returned
+// if the delivery failed so it can be retried, after the last attempt it is marked as failed
+func (s *Service) Deliver(ctx context.Context, deliveryId int64, isLastAttempt bool) error {
+	delivery, err := s.webhookRepo.GetWebhookDelivery(ctx, deliveryId)
+	if err != nil {
+		return err
+	}
+
+	if delivery.Status == types.WebhookDeliverySucceeded {
+		return nil
+	}
+
+	endpoint, err := s.webhookRepo.GetWebhookEndpointById(ctx, delivery.WebhookEndpointId)
+	if err != nil {
+		return err
+	}
+
+	// deliveries of disabled endpoints are not retried
+	if !endpoint.IsActive {
+		errStr := "webhook endpoint is disabled"
+
+		return s.webhookRepo.UpdateWebhookDeliveryAttempt(ctx, delivery.Id, domain.WebhookDeliveryAttempt{
+			Status:      types.WebhookDeliveryFailed,
+			Error:       &errStr,
+			AttemptedAt: time.Now(),
+		})
+	}
+
+	attempt := s.send(ctx, endpoint, delivery)
+
+	if attempt.Status != types.WebhookDeliverySucceeded && !isLastAttempt {
+		attempt.Status = types.WebhookDeliveryPending
+	}
+
+	err = s.webhookRepo.UpdateWebhookDeliveryAttempt(ctx, delivery.Id, attempt)
+	if err != nil {
+		return err
+	}
+
+	if attempt.Status != types.WebhookDeliverySucceeded {
+		if attempt.Error != nil {
+			return fmt.Errorf("webhook delivery %d failed: %s", delivery.Id, *attempt.Error)
+		}
+
+		return fmt.Errorf("webhook delivery %d failed with status %d", delivery.Id, *attempt.ResponseStatus)
+	}
+
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, endpoint domain.WebhookEndpoint, delivery domain.WebhookDelivery) domain.WebhookDeliveryAttempt {
+	now := time.Now()
+
+	failed := func(err error) domain.WebhookDeliveryAttempt {
+		errStr := err.Error()
+
+		return domain.WebhookDeliveryAttempt{
+			Status:      types.WebhookDeliveryFailed,
+			Error:       &errStr,
+			AttemptedAt: now,
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return failed(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIdHeader, delivery.EventId.String())
+	req.Header.Set(EventTypeHeader, delivery.EventType.String())
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, now, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return failed(err)
+	}
+	// nolint:errcheck
+	defer resp.Body.Close()
+
+	// nolint:errcheck
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBodySize))
+
+	statusCode := resp.StatusCode
+
+	status := types.WebhookDeliveryFailed
+	if statusCode >= 200 && statusCode < 300 {
+		status = types.WebhookDeliverySucceeded
+	}
+
+	return domain.WebhookDeliveryAttempt{
+		Status:         status,
+		ResponseStatus: &statusCode,
+		AttemptedAt:    now,
+	}
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ApiKeyScope struct {
+	scope string
+}
+
+func (a ApiKeyScope) String() string {
+	return a.scope
+}
+
+var (
+	ApiKeyScopeBookingsWrite  = ApiKeyScope{"bookings:write"}
+	ApiKeyScopeCustomersRead  = ApiKeyScope{"customers:read"}
+	ApiKeyScopeCustomersWrite = ApiKeyScope{"customers:write"}
+	ApiKeyScopeServicesRead   = ApiKeyScope{"services:read"}
+)
+
+// Every scope an api key can be created with
+var ApiKeyScopes = []ApiKeyScope{
+	ApiKeyScopeBookingsWrite,
+	ApiKeyScopeCustomersRead,
+	ApiKeyScopeCustomersWrite,
+	ApiKeyScopeServicesRead,
+}
+
+// Permissions granted to the requests made with the scope
+func (a ApiKeyScope) Permissions() []Permission {
+	switch a {
+	case ApiKeyScopeBookingsWrite:
+		return []Permission{PermissionBookingsManageAll}
+	case ApiKeyScopeCustomersRead:
+		return []Permission{PermissionCustomersView, PermissionCustomersViewPii}
+	case ApiKeyScopeCustomersWrite:
+		return []Permission{PermissionCustomersManage}
+	default:
+		return []Permission{}
+	}
+}
+
+func NewApiKeyScope(scopeStr string) (ApiKeyScope, error) {
+	for _, s := range ApiKeyScopes {
+		if s.scope == strings.ToLower(scopeStr) {
+			return s, nil
+		}
+	}
+
+	return ApiKeyScope{}, fmt.Errorf("invalid api key scope: %s", scopeStr)
+}
+
+func (a ApiKeyScope) Value() (driver.Value, error) {
+	return a.scope, nil
+}
+
+func (a *ApiKeyScope) Scan(src any) error {
+	scopeStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	scope, err := NewApiKeyScope(scopeStr)
+	if err != nil {
+		return err
+	}
+
+	*a = scope
+	return nil
+}
+
+func (a ApiKeyScope) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.scope)
+}
+
+func (a *ApiKeyScope) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	scope, err := NewApiKeyScope(s)
+	if err != nil {
+		return err
+	}
+
+	*a = scope
+	return nil
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type AuditEntityType struct {
+	entityType string
+}
+
+func (a AuditEntityType) String() string {
+	return a.entityType
+}
+
+var (
+	AuditEntityBooking            = AuditEntityType{"booking"}
+	AuditEntityBookingParticipant = AuditEntityType{"booking_participant"}
+	AuditEntityCustomer           = AuditEntityType{"customer"}
+	AuditEntityService            = AuditEntityType{"service"}
+	AuditEntityMerchantSettings   = AuditEntityType{"merchant_settings"}
+	AuditEntityEmployee           = AuditEntityType{"employee"}
+)
+
+func NewAuditEntityType(typeStr string) (AuditEntityType, error) {
+	switch strings.ToLower(typeStr) {
+	case "booking":
+		return AuditEntityBooking, nil
+	case "booking_participant":
+		return AuditEntityBookingParticipant, nil
+	case "customer":
+		return AuditEntityCustomer, nil
+	case "service":
+		return AuditEntityService, nil
+	case "merchant_settings":
+		return AuditEntityMerchantSettings, nil
+	case "employee":
+		return AuditEntityEmployee, nil
+	default:
+		return AuditEntityType{}, fmt.Errorf("invalid audit entity type: %s", typeStr)
+	}
+}
+
+func (a AuditEntityType) Value() (driver.Value, error) {
+	return a.entityType, nil
+}
+
+func (a *AuditEntityType) Scan(src any) error {
+	typeStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	if len(typeStr) == 0 {
+		return nil
+	}
+
+	entityType, err := NewAuditEntityType(typeStr)
+	if err != nil {
+		return err
+	}
+
+	*a = entityType
+	return nil
+}
+
+func (a AuditEntityType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.entityType)
+}
+
+func (a *AuditEntityType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	entityType, err := NewAuditEntityType(s)
+	if err != nil {
+		return err
+	}
+
+	*a = entityType
+	return nil
+}
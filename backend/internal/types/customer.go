@@ -0,0 +1,533 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type CustomerImportStatus struct {
+	status string
+}
+
+func (c CustomerImportStatus) String() string {
+	return c.status
+}
+
+var (
+	CustomerImportPending   = CustomerImportStatus{"pending"}
+	CustomerImportRunning   = CustomerImportStatus{"running"}
+	CustomerImportCompleted = CustomerImportStatus{"completed"}
+	CustomerImportFailed    = CustomerImportStatus{"failed"}
+)
+
+func NewCustomerImportStatus(statusStr string) (CustomerImportStatus, error) {
+	switch strings.ToLower(statusStr) {
+	case "pending":
+		return CustomerImportPending, nil
+	case "running":
+		return CustomerImportRunning, nil
+	case "completed":
+		return CustomerImportCompleted, nil
+	case "failed":
+		return CustomerImportFailed, nil
+	default:
+		return CustomerImportStatus{}, fmt.Errorf("invalid customer import status: %s", statusStr)
+	}
+}
+
+func (c CustomerImportStatus) Value() (driver.Value, error) {
+	return c.status, nil
+}
+
+func (c *CustomerImportStatus) Scan(src any) error {
+	statusStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	status, err := NewCustomerImportStatus(statusStr)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+func (c CustomerImportStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.status)
+}
+
+func (c *CustomerImportStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	status, err := NewCustomerImportStatus(s)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+type CustomFieldType struct {
+	fieldType string
+}
+
+func (c CustomFieldType) String() string {
+	return c.fieldType
+}
+
+var (
+	CustomFieldText   = CustomFieldType{"text"}
+	CustomFieldNumber = CustomFieldType{"number"}
+	CustomFieldDate   = CustomFieldType{"date"}
+	CustomFieldSelect = CustomFieldType{"select"}
+)
+
+func NewCustomFieldType(typeStr string) (CustomFieldType, error) {
+	switch strings.ToLower(typeStr) {
+	case "text":
+		return CustomFieldText, nil
+	case "number":
+		return CustomFieldNumber, nil
+	case "date":
+		return CustomFieldDate, nil
+	case "select":
+		return CustomFieldSelect, nil
+	default:
+		return CustomFieldType{}, fmt.Errorf("invalid custom field type: %s", typeStr)
+	}
+}
+
+func (c CustomFieldType) Value() (driver.Value, error) {
+	return c.fieldType, nil
+}
+
+func (c *CustomFieldType) Scan(src any) error {
+	typeStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	fieldType, err := NewCustomFieldType(typeStr)
+	if err != nil {
+		return err
+	}
+
+	*c = fieldType
+	return nil
+}
+
+func (c CustomFieldType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.fieldType)
+}
+
+func (c *CustomFieldType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	fieldType, err := NewCustomFieldType(s)
+	if err != nil {
+		return err
+	}
+
+	*c = fieldType
+	return nil
+}
+
+type MarketingChannel struct {
+	channel string
+}
+
+func (m MarketingChannel) String() string {
+	return m.channel
+}
+
+var (
+	MarketingChannelEmail = MarketingChannel{"email"}
+	MarketingChannelSms   = MarketingChannel{"sms"}
+)
+
+func NewMarketingChannel(channelStr string) (MarketingChannel, error) {
+	switch strings.ToLower(channelStr) {
+	case "email":
+		return MarketingChannelEmail, nil
+	case "sms":
+		return MarketingChannelSms, nil
+	default:
+		return MarketingChannel{}, fmt.Errorf("invalid marketing channel: %s", channelStr)
+	}
+}
+
+func (m MarketingChannel) Value() (driver.Value, error) {
+	return m.channel, nil
+}
+
+func (m *MarketingChannel) Scan(src any) error {
+	channelStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	channel, err := NewMarketingChannel(channelStr)
+	if err != nil {
+		return err
+	}
+
+	*m = channel
+	return nil
+}
+
+func (m MarketingChannel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.channel)
+}
+
+func (m *MarketingChannel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	channel, err := NewMarketingChannel(s)
+	if err != nil {
+		return err
+	}
+
+	*m = channel
+	return nil
+}
+
+type ConsentSource struct {
+	source string
+}
+
+func (c ConsentSource) String() string {
+	return c.source
+}
+
+var (
+	ConsentSourceBooking         = ConsentSource{"booking"}
+	ConsentSourceMerchant        = ConsentSource{"merchant"}
+	ConsentSourceUnsubscribeLink = ConsentSource{"unsubscribe_link"}
+)
+
+func NewConsentSource(sourceStr string) (ConsentSource, error) {
+	switch strings.ToLower(sourceStr) {
+	case "booking":
+		return ConsentSourceBooking, nil
+	case "merchant":
+		return ConsentSourceMerchant, nil
+	case "unsubscribe_link":
+		return ConsentSourceUnsubscribeLink, nil
+	default:
+		return ConsentSource{}, fmt.Errorf("invalid consent source: %s", sourceStr)
+	}
+}
+
+func (c ConsentSource) Value() (driver.Value, error) {
+	return c.source, nil
+}
+
+func (c *ConsentSource) Scan(src any) error {
+	sourceStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	source, err := NewConsentSource(sourceStr)
+	if err != nil {
+		return err
+	}
+
+	*c = source
+	return nil
+}
+
+func (c ConsentSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.source)
+}
+
+func (c *ConsentSource) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	source, err := NewConsentSource(s)
+	if err != nil {
+		return err
+	}
+
+	*c = source
+	return nil
+}
+
+type CampaignStatus struct {
+	status string
+}
+
+func (c CampaignStatus) String() string {
+	return c.status
+}
+
+var (
+	CampaignPending   = CampaignStatus{"pending"}
+	CampaignSending   = CampaignStatus{"sending"}
+	CampaignCompleted = CampaignStatus{"completed"}
+	CampaignCancelled = CampaignStatus{"cancelled"}
+)
+
+func NewCampaignStatus(statusStr string) (CampaignStatus, error) {
+	switch strings.ToLower(statusStr) {
+	case "pending":
+		return CampaignPending, nil
+	case "sending":
+		return CampaignSending, nil
+	case "completed":
+		return CampaignCompleted, nil
+	case "cancelled":
+		return CampaignCancelled, nil
+	default:
+		return CampaignStatus{}, fmt.Errorf("invalid campaign status: %s", statusStr)
+	}
+}
+
+func (c CampaignStatus) Value() (driver.Value, error) {
+	return c.status, nil
+}
+
+func (c *CampaignStatus) Scan(src any) error {
+	statusStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	status, err := NewCampaignStatus(statusStr)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+func (c CampaignStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.status)
+}
+
+func (c *CampaignStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	status, err := NewCampaignStatus(s)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+type CampaignRecipientStatus struct {
+	status string
+}
+
+func (c CampaignRecipientStatus) String() string {
+	return c.status
+}
+
+var (
+	CampaignRecipientPending = CampaignRecipientStatus{"pending"}
+	CampaignRecipientSent    = CampaignRecipientStatus{"sent"}
+	CampaignRecipientSkipped = CampaignRecipientStatus{"skipped"}
+	CampaignRecipientFailed  = CampaignRecipientStatus{"failed"}
+)
+
+func NewCampaignRecipientStatus(statusStr string) (CampaignRecipientStatus, error) {
+	switch strings.ToLower(statusStr) {
+	case "pending":
+		return CampaignRecipientPending, nil
+	case "sent":
+		return CampaignRecipientSent, nil
+	case "skipped":
+		return CampaignRecipientSkipped, nil
+	case "failed":
+		return CampaignRecipientFailed, nil
+	default:
+		return CampaignRecipientStatus{}, fmt.Errorf("invalid campaign recipient status: %s", statusStr)
+	}
+}
+
+func (c CampaignRecipientStatus) Value() (driver.Value, error) {
+	return c.status, nil
+}
+
+func (c *CampaignRecipientStatus) Scan(src any) error {
+	statusStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	status, err := NewCampaignRecipientStatus(statusStr)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+func (c CampaignRecipientStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.status)
+}
+
+func (c *CampaignRecipientStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	status, err := NewCampaignRecipientStatus(s)
+	if err != nil {
+		return err
+	}
+
+	*c = status
+	return nil
+}
+
+// What a customer policy rule counts
+type CustomerPolicyEvent struct {
+	event string
+}
+
+func (c CustomerPolicyEvent) String() string {
+	return c.event
+}
+
+var (
+	CustomerPolicyEventNoShow       = CustomerPolicyEvent{"no-show"}
+	CustomerPolicyEventCancellation = CustomerPolicyEvent{"cancellation"}
+)
+
+func NewCustomerPolicyEvent(eventStr string) (CustomerPolicyEvent, error) {
+	switch strings.ToLower(eventStr) {
+	case "no-show":
+		return CustomerPolicyEventNoShow, nil
+	case "cancellation":
+		return CustomerPolicyEventCancellation, nil
+	default:
+		return CustomerPolicyEvent{}, fmt.Errorf("invalid customer policy event: %s", eventStr)
+	}
+}
+
+func (c CustomerPolicyEvent) Value() (driver.Value, error) {
+	return c.event, nil
+}
+
+func (c *CustomerPolicyEvent) Scan(src any) error {
+	eventStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	event, err := NewCustomerPolicyEvent(eventStr)
+	if err != nil {
+		return err
+	}
+
+	*c = event
+	return nil
+}
+
+func (c CustomerPolicyEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.event)
+}
+
+func (c *CustomerPolicyEvent) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	event, err := NewCustomerPolicyEvent(s)
+	if err != nil {
+		return err
+	}
+
+	*c = event
+	return nil
+}
+
+// What happens to a customer who reached the threshold of a policy rule
+type CustomerPolicyAction struct {
+	action string
+}
+
+func (c CustomerPolicyAction) String() string {
+	return c.action
+}
+
+var (
+	CustomerPolicyActionRequireApproval = CustomerPolicyAction{"require_approval"}
+	CustomerPolicyActionBlacklist       = CustomerPolicyAction{"blacklist"}
+)
+
+func NewCustomerPolicyAction(actionStr string) (CustomerPolicyAction, error) {
+	switch strings.ToLower(actionStr) {
+	case "require_approval":
+		return CustomerPolicyActionRequireApproval, nil
+	case "blacklist":
+		return CustomerPolicyActionBlacklist, nil
+	default:
+		return CustomerPolicyAction{}, fmt.Errorf("invalid customer policy action: %s", actionStr)
+	}
+}
+
+func (c CustomerPolicyAction) Value() (driver.Value, error) {
+	return c.action, nil
+}
+
+func (c *CustomerPolicyAction) Scan(src any) error {
+	actionStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	action, err := NewCustomerPolicyAction(actionStr)
+	if err != nil {
+		return err
+	}
+
+	*c = action
+	return nil
+}
+
+func (c CustomerPolicyAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.action)
+}
+
+func (c *CustomerPolicyAction) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	action, err := NewCustomerPolicyAction(s)
+	if err != nil {
+		return err
+	}
+
+	*c = action
+	return nil
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type IntakeQuestionType struct {
+	questionType string
+}
+
+func (i IntakeQuestionType) String() string {
+	return i.questionType
+}
+
+var (
+	IntakeQuestionText           = IntakeQuestionType{"text"}
+	IntakeQuestionLongText       = IntakeQuestionType{"long_text"}
+	IntakeQuestionNumber         = IntakeQuestionType{"number"}
+	IntakeQuestionDate           = IntakeQuestionType{"date"}
+	IntakeQuestionYesNo          = IntakeQuestionType{"yes_no"}
+	IntakeQuestionSingleChoice   = IntakeQuestionType{"single_choice"}
+	IntakeQuestionMultipleChoice = IntakeQuestionType{"multiple_choice"}
+	IntakeQuestionConsent        = IntakeQuestionType{"consent"}
+)
+
+func NewIntakeQuestionType(typeStr string) (IntakeQuestionType, error) {
+	switch strings.ToLower(typeStr) {
+	case "text":
+		return IntakeQuestionText, nil
+	case "long_text":
+		return IntakeQuestionLongText, nil
+	case "number":
+		return IntakeQuestionNumber, nil
+	case "date":
+		return IntakeQuestionDate, nil
+	case "yes_no":
+		return IntakeQuestionYesNo, nil
+	case "single_choice":
+		return IntakeQuestionSingleChoice, nil
+	case "multiple_choice":
+		return IntakeQuestionMultipleChoice, nil
+	case "consent":
+		return IntakeQuestionConsent, nil
+	default:
+		return IntakeQuestionType{}, fmt.Errorf("invalid intake question type: %s", typeStr)
+	}
+}
+
+func (i IntakeQuestionType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.questionType)
+}
+
+func (i *IntakeQuestionType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	questionType, err := NewIntakeQuestionType(s)
+	if err != nil {
+		return err
+	}
+
+	*i = questionType
+	return nil
+}
+
+type IntakeFormMode struct {
+	mode string
+}
+
+func (i IntakeFormMode) String() string {
+	return i.mode
+}
+
+var (
+	// the form is only asked on the first booking of the customer
+	IntakeFormOnce = IntakeFormMode{"once"}
+	// the form is asked on every booking
+	IntakeFormEveryTime = IntakeFormMode{"every_time"}
+)
+
+func NewIntakeFormMode(modeStr string) (IntakeFormMode, error) {
+	switch strings.ToLower(modeStr) {
+	case "once":
+		return IntakeFormOnce, nil
+	case "every_time":
+		return IntakeFormEveryTime, nil
+	default:
+		return IntakeFormMode{}, fmt.Errorf("invalid intake form mode: %s", modeStr)
+	}
+}
+
+func (i IntakeFormMode) Value() (driver.Value, error) {
+	return i.mode, nil
+}
+
+func (i *IntakeFormMode) Scan(src any) error {
+	modeStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	mode, err := NewIntakeFormMode(modeStr)
+	if err != nil {
+		return err
+	}
+
+	*i = mode
+	return nil
+}
+
+func (i IntakeFormMode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.mode)
+}
+
+func (i *IntakeFormMode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	mode, err := NewIntakeFormMode(s)
+	if err != nil {
+		return err
+	}
+
+	*i = mode
+	return nil
+}
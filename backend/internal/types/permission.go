@@ -0,0 +1,125 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+type Permission struct {
+	permission string
+}
+
+func (p Permission) String() string {
+	return p.permission
+}
+
+var (
+	PermissionBookingsManageOwn     = Permission{"bookings.manage_own"}
+	PermissionBookingsManageAll     = Permission{"bookings.manage_all"}
+	PermissionCustomersView         = Permission{"customers.view"}
+	PermissionCustomersViewPii      = Permission{"customers.view_pii"}
+	PermissionCustomersManage       = Permission{"customers.manage"}
+	PermissionCustomersViewNotes    = Permission{"customers.view_notes"}
+	PermissionCatalogEdit           = Permission{"catalog.edit"}
+	PermissionReportsView           = Permission{"reports.view"}
+	PermissionBlockedTimesManageOwn = Permission{"blocked_times.manage_own"}
+	PermissionBlockedTimesManageAll = Permission{"blocked_times.manage_all"}
+	PermissionSettingsManage        = Permission{"settings.manage"}
+	PermissionTeamManage            = Permission{"team.manage"}
+	PermissionAuditLogView          = Permission{"audit_log.view"}
+	PermissionGiftCardsView         = Permission{"gift_cards.view"}
+	PermissionGiftCardsManage       = Permission{"gift_cards.manage"}
+	// can only be held by the owner, it's not assignable to custom roles
+	PermissionMerchantManage = Permission{"merchant.manage"}
+)
+
+// Every permission that can be assigned to a custom role
+var AssignablePermissions = []Permission{
+	PermissionBookingsManageOwn,
+	PermissionBookingsManageAll,
+	PermissionCustomersView,
+	PermissionCustomersViewPii,
+	PermissionCustomersManage,
+	PermissionCustomersViewNotes,
+	PermissionCatalogEdit,
+	PermissionReportsView,
+	PermissionBlockedTimesManageOwn,
+	PermissionBlockedTimesManageAll,
+	PermissionSettingsManage,
+	PermissionTeamManage,
+	PermissionAuditLogView,
+	PermissionGiftCardsView,
+	PermissionGiftCardsManage,
+}
+
+func NewPermission(permissionStr string) (Permission, error) {
+	for _, p := range append(AssignablePermissions, PermissionMerchantManage) {
+		if p.permission == permissionStr {
+			return p, nil
+		}
+	}
+
+	return Permission{}, fmt.Errorf("invalid permission: %s", permissionStr)
+}
+
+func (p Permission) IsAssignable() bool {
+	return slices.Contains(AssignablePermissions, p)
+}
+
+// Permissions of the built in roles, used when the employee does not have a custom role
+func (r EmployeeRole) DefaultPermissions() []Permission {
+	switch r {
+	case EmployeeRoleOwner:
+		return append(slices.Clone(AssignablePermissions), PermissionMerchantManage)
+	case EmployeeRoleAdmin:
+		return slices.Clone(AssignablePermissions)
+	case EmployeeRoleStaff:
+		return []Permission{
+			PermissionBookingsManageOwn,
+			PermissionCustomersView,
+			PermissionBlockedTimesManageOwn,
+		}
+	default:
+		return []Permission{}
+	}
+}
+
+func (p Permission) Value() (driver.Value, error) {
+	return p.permission, nil
+}
+
+func (p *Permission) Scan(src any) error {
+	permissionStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	permission, err := NewPermission(permissionStr)
+	if err != nil {
+		return err
+	}
+
+	*p = permission
+	return nil
+}
+
+func (p Permission) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.permission)
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	permission, err := NewPermission(s)
+	if err != nil {
+		return err
+	}
+
+	*p = permission
+	return nil
+}
@@ -0,0 +1,333 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ReportGranularity struct {
+	granularity string
+}
+
+func (r ReportGranularity) String() string {
+	return r.granularity
+}
+
+var (
+	ReportGranularityDay   = ReportGranularity{"day"}
+	ReportGranularityWeek  = ReportGranularity{"week"}
+	ReportGranularityMonth = ReportGranularity{"month"}
+)
+
+func NewReportGranularity(granularityStr string) (ReportGranularity, error) {
+	switch strings.ToLower(granularityStr) {
+	case "day":
+		return ReportGranularityDay, nil
+	case "week":
+		return ReportGranularityWeek, nil
+	case "month":
+		return ReportGranularityMonth, nil
+	default:
+		return ReportGranularity{}, fmt.Errorf("invalid report granularity: %s", granularityStr)
+	}
+}
+
+func (r ReportGranularity) Value() (driver.Value, error) {
+	return r.granularity, nil
+}
+
+func (r *ReportGranularity) Scan(src any) error {
+	granularityStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	granularity, err := NewReportGranularity(granularityStr)
+	if err != nil {
+		return err
+	}
+
+	*r = granularity
+	return nil
+}
+
+func (r ReportGranularity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.granularity)
+}
+
+func (r *ReportGranularity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	granularity, err := NewReportGranularity(s)
+	if err != nil {
+		return err
+	}
+
+	*r = granularity
+	return nil
+}
+
+type ReportGroupBy struct {
+	groupBy string
+}
+
+func (r ReportGroupBy) String() string {
+	return r.groupBy
+}
+
+var (
+	ReportGroupByService  = ReportGroupBy{"service"}
+	ReportGroupByCategory = ReportGroupBy{"category"}
+	ReportGroupByEmployee = ReportGroupBy{"employee"}
+	ReportGroupByLocation = ReportGroupBy{"location"}
+)
+
+func NewReportGroupBy(groupByStr string) (ReportGroupBy, error) {
+	switch strings.ToLower(groupByStr) {
+	case "service":
+		return ReportGroupByService, nil
+	case "category":
+		return ReportGroupByCategory, nil
+	case "employee":
+		return ReportGroupByEmployee, nil
+	case "location":
+		return ReportGroupByLocation, nil
+	default:
+		return ReportGroupBy{}, fmt.Errorf("invalid report group by: %s", groupByStr)
+	}
+}
+
+func (r ReportGroupBy) Value() (driver.Value, error) {
+	return r.groupBy, nil
+}
+
+func (r *ReportGroupBy) Scan(src any) error {
+	groupByStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	groupBy, err := NewReportGroupBy(groupByStr)
+	if err != nil {
+		return err
+	}
+
+	*r = groupBy
+	return nil
+}
+
+func (r ReportGroupBy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.groupBy)
+}
+
+func (r *ReportGroupBy) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	groupBy, err := NewReportGroupBy(s)
+	if err != nil {
+		return err
+	}
+
+	*r = groupBy
+	return nil
+}
+
+type ReportFrequency struct {
+	frequency string
+}
+
+func (r ReportFrequency) String() string {
+	return r.frequency
+}
+
+var (
+	ReportFrequencyDaily   = ReportFrequency{"daily"}
+	ReportFrequencyWeekly  = ReportFrequency{"weekly"}
+	ReportFrequencyMonthly = ReportFrequency{"monthly"}
+)
+
+func NewReportFrequency(frequencyStr string) (ReportFrequency, error) {
+	switch strings.ToLower(frequencyStr) {
+	case "daily":
+		return ReportFrequencyDaily, nil
+	case "weekly":
+		return ReportFrequencyWeekly, nil
+	case "monthly":
+		return ReportFrequencyMonthly, nil
+	default:
+		return ReportFrequency{}, fmt.Errorf("invalid report frequency: %s", frequencyStr)
+	}
+}
+
+func (r ReportFrequency) Value() (driver.Value, error) {
+	return r.frequency, nil
+}
+
+func (r *ReportFrequency) Scan(src any) error {
+	frequencyStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	frequency, err := NewReportFrequency(frequencyStr)
+	if err != nil {
+		return err
+	}
+
+	*r = frequency
+	return nil
+}
+
+func (r ReportFrequency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.frequency)
+}
+
+func (r *ReportFrequency) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	frequency, err := NewReportFrequency(s)
+	if err != nil {
+		return err
+	}
+
+	*r = frequency
+	return nil
+}
+
+type ReportExportDataset struct {
+	dataset string
+}
+
+func (r ReportExportDataset) String() string {
+	return r.dataset
+}
+
+var (
+	ReportExportDatasetBookings     = ReportExportDataset{"bookings"}
+	ReportExportDatasetParticipants = ReportExportDataset{"participants"}
+	ReportExportDatasetRevenue      = ReportExportDataset{"revenue"}
+)
+
+func NewReportExportDataset(datasetStr string) (ReportExportDataset, error) {
+	switch strings.ToLower(datasetStr) {
+	case "bookings":
+		return ReportExportDatasetBookings, nil
+	case "participants":
+		return ReportExportDatasetParticipants, nil
+	case "revenue":
+		return ReportExportDatasetRevenue, nil
+	default:
+		return ReportExportDataset{}, fmt.Errorf("invalid report export dataset: %s", datasetStr)
+	}
+}
+
+func (r ReportExportDataset) Value() (driver.Value, error) {
+	return r.dataset, nil
+}
+
+func (r *ReportExportDataset) Scan(src any) error {
+	datasetStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	dataset, err := NewReportExportDataset(datasetStr)
+	if err != nil {
+		return err
+	}
+
+	*r = dataset
+	return nil
+}
+
+func (r ReportExportDataset) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.dataset)
+}
+
+func (r *ReportExportDataset) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	dataset, err := NewReportExportDataset(s)
+	if err != nil {
+		return err
+	}
+
+	*r = dataset
+	return nil
+}
+
+type ReportExportFormat struct {
+	format string
+}
+
+func (r ReportExportFormat) String() string {
+	return r.format
+}
+
+var (
+	ReportExportFormatCsv  = ReportExportFormat{"csv"}
+	ReportExportFormatXlsx = ReportExportFormat{"xlsx"}
+)
+
+func NewReportExportFormat(formatStr string) (ReportExportFormat, error) {
+	switch strings.ToLower(formatStr) {
+	case "csv":
+		return ReportExportFormatCsv, nil
+	case "xlsx":
+		return ReportExportFormatXlsx, nil
+	default:
+		return ReportExportFormat{}, fmt.Errorf("invalid report export format: %s", formatStr)
+	}
+}
+
+func (r ReportExportFormat) Value() (driver.Value, error) {
+	return r.format, nil
+}
+
+func (r *ReportExportFormat) Scan(src any) error {
+	formatStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	format, err := NewReportExportFormat(formatStr)
+	if err != nil {
+		return err
+	}
+
+	*r = format
+	return nil
+}
+
+func (r ReportExportFormat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.format)
+}
+
+func (r *ReportExportFormat) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	format, err := NewReportExportFormat(s)
+	if err != nil {
+		return err
+	}
+
+	*r = format
+	return nil
+}
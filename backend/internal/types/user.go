@@ -72,3 +72,71 @@ func (a *AuthProviderType) UnmarshalJSON(data []byte) error {
 	*a = aptype
 	return nil
 }
+
+type DataExportStatus struct {
+	status string
+}
+
+func (d DataExportStatus) String() string {
+	return d.status
+}
+
+var (
+	DataExportPending   = DataExportStatus{"pending"}
+	DataExportRunning   = DataExportStatus{"running"}
+	DataExportCompleted = DataExportStatus{"completed"}
+	DataExportFailed    = DataExportStatus{"failed"}
+)
+
+func NewDataExportStatus(statusStr string) (DataExportStatus, error) {
+	switch strings.ToLower(statusStr) {
+	case "pending":
+		return DataExportPending, nil
+	case "running":
+		return DataExportRunning, nil
+	case "completed":
+		return DataExportCompleted, nil
+	case "failed":
+		return DataExportFailed, nil
+	default:
+		return DataExportStatus{}, fmt.Errorf("invalid data export status: %s", statusStr)
+	}
+}
+
+func (d DataExportStatus) Value() (driver.Value, error) {
+	return d.status, nil
+}
+
+func (d *DataExportStatus) Scan(src any) error {
+	statusStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	status, err := NewDataExportStatus(statusStr)
+	if err != nil {
+		return err
+	}
+
+	*d = status
+	return nil
+}
+
+func (d DataExportStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.status)
+}
+
+func (d *DataExportStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	status, err := NewDataExportStatus(s)
+	if err != nil {
+		return err
+	}
+
+	*d = status
+	return nil
+}
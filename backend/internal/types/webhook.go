@@ -0,0 +1,146 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type WebhookEventType struct {
+	eventType string
+}
+
+func (w WebhookEventType) String() string {
+	return w.eventType
+}
+
+var (
+	WebhookEventBookingCreated           = WebhookEventType{"booking.created"}
+	WebhookEventBookingUpdated           = WebhookEventType{"booking.updated"}
+	WebhookEventBookingCancelled         = WebhookEventType{"booking.cancelled"}
+	WebhookEventParticipantStatusChanged = WebhookEventType{"participant.status_changed"}
+	WebhookEventCustomerCreated          = WebhookEventType{"customer.created"}
+)
+
+// Every event a webhook endpoint can subscribe to
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventBookingCreated,
+	WebhookEventBookingUpdated,
+	WebhookEventBookingCancelled,
+	WebhookEventParticipantStatusChanged,
+	WebhookEventCustomerCreated,
+}
+
+func NewWebhookEventType(typeStr string) (WebhookEventType, error) {
+	for _, t := range WebhookEventTypes {
+		if t.eventType == strings.ToLower(typeStr) {
+			return t, nil
+		}
+	}
+
+	return WebhookEventType{}, fmt.Errorf("invalid webhook event type: %s", typeStr)
+}
+
+func (w WebhookEventType) Value() (driver.Value, error) {
+	return w.eventType, nil
+}
+
+func (w *WebhookEventType) Scan(src any) error {
+	typeStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	eventType, err := NewWebhookEventType(typeStr)
+	if err != nil {
+		return err
+	}
+
+	*w = eventType
+	return nil
+}
+
+func (w WebhookEventType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.eventType)
+}
+
+func (w *WebhookEventType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	eventType, err := NewWebhookEventType(s)
+	if err != nil {
+		return err
+	}
+
+	*w = eventType
+	return nil
+}
+
+type WebhookDeliveryStatus struct {
+	status string
+}
+
+func (w WebhookDeliveryStatus) String() string {
+	return w.status
+}
+
+var (
+	WebhookDeliveryPending   = WebhookDeliveryStatus{"pending"}
+	WebhookDeliverySucceeded = WebhookDeliveryStatus{"succeeded"}
+	WebhookDeliveryFailed    = WebhookDeliveryStatus{"failed"}
+)
+
+func NewWebhookDeliveryStatus(statusStr string) (WebhookDeliveryStatus, error) {
+	switch strings.ToLower(statusStr) {
+	case "pending":
+		return WebhookDeliveryPending, nil
+	case "succeeded":
+		return WebhookDeliverySucceeded, nil
+	case "failed":
+		return WebhookDeliveryFailed, nil
+	default:
+		return WebhookDeliveryStatus{}, fmt.Errorf("invalid webhook delivery status: %s", statusStr)
+	}
+}
+
+func (w WebhookDeliveryStatus) Value() (driver.Value, error) {
+	return w.status, nil
+}
+
+func (w *WebhookDeliveryStatus) Scan(src any) error {
+	statusStr, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("value is not a string: %v", src)
+	}
+
+	status, err := NewWebhookDeliveryStatus(statusStr)
+	if err != nil {
+		return err
+	}
+
+	*w = status
+	return nil
+}
+
+func (w WebhookDeliveryStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.status)
+}
+
+func (w *WebhookDeliveryStatus) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	status, err := NewWebhookDeliveryStatus(s)
+	if err != nil {
+		return err
+	}
+
+	*w = status
+	return nil
+}
@@ -0,0 +1,25 @@
+// Package blob stores the content of uploaded files by key, on the local filesystem or in an S3 compatible bucket.
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Keys are slash separated paths, they can not be absolute or point outside the store
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	for segment := range strings.SplitSeq(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key: %q", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stores the blobs as files under a directory, meant for development and single server setups
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("could not create blob directory: %s", err.Error())
+	}
+
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// The content is written to a temporary file first, so a failed upload never leaves a partial blob behind
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("could not create blob directory: %s", err.Error())
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("could not create blob file: %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write blob: %s", err.Error())
+	}
+
+	if written != size {
+		return fmt.Errorf("blob size mismatch: expected %d bytes, got %d", size, written)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write blob: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not open blob: %s", err.Error())
+	}
+
+	return file, nil
+}
+
+// Deleting a missing blob is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not delete blob: %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Payloads are not hashed, the uploads are streamed and the connection is expected to be https
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+type S3Config struct {
+	// Url of the S3 compatible service, e.g. https://s3.eu-central-1.amazonaws.com or the address of a MinIO server
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+// Stores the blobs in an S3 compatible bucket using path style urls and signature version 4
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", config.Endpoint)
+	}
+
+	if config.Region == "" || config.Bucket == "" || config.AccessKeyId == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 region, bucket and credentials are required")
+	}
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.config.Bucket + "/" + key
+	u.RawPath = s.endpoint.EscapedPath() + "/" + escapeSegment(s.config.Bucket) + "/" + escapeKey(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("could not create s3 request: %s", err.Error())
+	}
+
+	return req, nil
+}
+
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	signV4(req, s.config.AccessKeyId, s.config.SecretAccessKey, s.config.Region, unsignedPayload, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %s", err.Error())
+	}
+
+	return resp, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", resp)
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, responseError("get", resp)
+	}
+}
+
+// Deleting a missing object is not an error
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError("delete", resp)
+	}
+
+	return nil
+}
+
+func responseError(operation string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return fmt.Errorf("s3 %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// Signs the request with AWS signature version 4. The host and every header already set on the request are signed
+func signV4(req *http.Request, accessKeyId, secretAccessKey, region, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSha256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyId, scope, signedHeaders, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := []string{}
+	for name, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, escapeSegment(name)+"="+escapeSegment(v))
+		}
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSegment(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Escapes everything except the unreserved characters, as the signature requires
+func escapeSegment(s string) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,38 @@
+// Package csvx writes csv files which are safe to open in spreadsheet applications
+package csvx
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const ContentType = "text/csv; charset=utf-8"
+
+// Writes the rows with every cell escaped
+func Write(w io.Writer, rows [][]string) error {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = Escape(cell)
+		}
+	}
+
+	return csv.NewWriter(w).WriteAll(escaped)
+}
+
+// Prefixes the cells that spreadsheet applications would run as formulas with a quote so they stay text,
+// numbers like negative amounts can not be formulas so they are kept as they are
+func Escape(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+
+	return "'" + cell
+}
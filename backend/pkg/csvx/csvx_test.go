@@ -0,0 +1,30 @@
+package csvx_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/miketsu-inc/reservations/backend/pkg/csvx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	assert := assert.New(t)
+
+	testcases := []string{"", "Anna", "=HYPERLINK(\"x\")", "+1+1", "-1+1", "@SUM(A1)", "\tcmd", "\rcmd", "-60.00", "+36301234567", "a=b"}
+	results := []string{"", "Anna", "'=HYPERLINK(\"x\")", "'+1+1", "'-1+1", "'@SUM(A1)", "'\tcmd", "'\rcmd", "-60.00", "+36301234567", "a=b"}
+
+	for i, cell := range testcases {
+		assert.Equal(results[i], Escape(cell), "cell %q", cell)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	err := Write(&buf, [][]string{{"Name", "Amount"}, {"=1+2", "-5"}})
+	assert.Nil(err)
+
+	assert.Equal("Name,Amount\n'=1+2,-5\n", buf.String())
+}
@@ -3,62 +3,92 @@ package currencyx
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/bojanz/currency"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/miketsu-inc/reservations/backend/pkg/assert"
 	curr "golang.org/x/text/currency"
 	"golang.org/x/text/language"
 )
 
-var currencyLocale = map[string]string{
-	"HUF": "hu",
-	"EUR": "en",
-	"USD": "en",
-	"GBP": "en",
+// CLDR lists two fraction digits for the forint, but the filler coins were
+// withdrawn in 1999 so prices are always whole forints
+var precisionOverrides = map[string]uint8{
+	"HUF": 0,
 }
 
-var currencyPrecision = map[string]int{
-	"HUF": 0,
-	"EUR": 2,
-	"USD": 2,
-	"GBP": 2,
+type formatterKey struct {
+	locale       string
+	currencyCode string
 }
 
-var formatters = make(map[string]*currency.Formatter)
+var formatters sync.Map
+
+// The number of fraction digits the currency is displayed and rounded with
+func Precision(currencyCode string) (uint8, error) {
+	if currencyCode == "" || !currency.IsValid(currencyCode) {
+		return 0, fmt.Errorf("unsupported currency: %q", currencyCode)
+	}
+
+	if prec, ok := precisionOverrides[currencyCode]; ok {
+		return prec, nil
+	}
+
+	prec, _ := currency.GetDigits(currencyCode)
+	return prec, nil
+}
 
-func init() {
-	for currencyCode, loc := range currencyLocale {
-		locale := currency.NewLocale(loc)
-		formatter := currency.NewFormatter(locale)
+func formatter(locale language.Tag, currencyCode string) (*currency.Formatter, error) {
+	key := formatterKey{locale: locale.String(), currencyCode: currencyCode}
 
-		prec, ok := currencyPrecision[currencyCode]
-		assert.True(ok, "Precision for this currency code is not defined", currencyCode, currencyPrecision, currencyLocale)
+	if f, ok := formatters.Load(key); ok {
+		return f.(*currency.Formatter), nil
+	}
 
-		formatter.MaxDigits = uint8(prec)
-		formatters[currencyCode] = formatter
+	prec, err := Precision(currencyCode)
+	if err != nil {
+		return nil, err
 	}
+
+	f := currency.NewFormatter(currency.NewLocale(key.locale))
+	f.MinDigits = prec
+	f.MaxDigits = prec
+
+	actual, _ := formatters.LoadOrStore(key, f)
+	return actual.(*currency.Formatter), nil
 }
 
-func Format(amount currency.Amount) string {
-	formatter, ok := formatters[amount.CurrencyCode()]
-	assert.True(ok, "Currency code does not exist in currencyLocale map", amount.CurrencyCode(), currencyLocale)
+// Formats the amount according to the conventions of the locale, falls back
+// on the parent locales and english if CLDR has no data for it
+func Format(amount currency.Amount, locale language.Tag) (string, error) {
+	f, err := formatter(locale, amount.CurrencyCode())
+	if err != nil {
+		return "", err
+	}
 
-	return formatter.Format(amount)
+	return f.Format(amount), nil
+}
+
+// Rounds the amount to the precision the currency is displayed with
+func Round(amount currency.Amount) (currency.Amount, error) {
+	prec, err := Precision(amount.CurrencyCode())
+	if err != nil {
+		return currency.Amount{}, err
+	}
+
+	return amount.RoundTo(prec, currency.RoundHalfUp), nil
 }
 
 // finds the most likely currency based on the user's language
-// falls back on HUF if currency is not supported
-func FindBest(lang language.Tag) string {
-	currencyUnit, _ := curr.FromTag(lang)
-	currency := currencyUnit.String()
-
-	_, ok := currencyLocale[currency]
-	if !ok {
-		currency = "HUF"
+func FindBest(lang language.Tag) (string, error) {
+	currencyUnit, confidence := curr.FromTag(lang)
+	currencyCode := currencyUnit.String()
+
+	if confidence == language.No || !currency.IsValid(currencyCode) {
+		return "", fmt.Errorf("could not determine the currency for language: %s", lang)
 	}
 
-	return currency
+	return currencyCode, nil
 }
 
 type Price struct {
@@ -66,8 +96,10 @@ type Price struct {
 	scanState *priceScanState
 }
 
+// Price which is formatted in the locale when marshalled to json
 type FormattedPrice struct {
 	currency.Amount
+	Locale language.Tag
 }
 
 // Used for assembling the currency (Price struct)
@@ -77,19 +109,24 @@ type priceScanState struct {
 }
 
 func (f FormattedPrice) MarshalJSON() ([]byte, error) {
-	return json.Marshal(Format(f.Amount))
+	formatted, err := Format(f.Amount, f.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(formatted)
 }
 
-func (p Price) ToFormatted() FormattedPrice {
-	return FormattedPrice{Amount: p.Amount}
+func (p Price) ToFormatted(locale language.Tag) FormattedPrice {
+	return FormattedPrice{Amount: p.Amount, Locale: locale}
 }
 
-func FormatPrice(price *Price) *FormattedPrice {
+func FormatPrice(price *Price, locale language.Tag) *FormattedPrice {
 	if price == nil {
 		return nil
 	}
 
-	fp := price.ToFormatted()
+	fp := price.ToFormatted(locale)
 	return &fp
 }
 
@@ -0,0 +1,120 @@
+package currencyx_test
+
+import (
+	"testing"
+
+	"github.com/bojanz/currency"
+	. "github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func amount(t *testing.T, number string, currencyCode string) currency.Amount {
+	t.Helper()
+
+	a, err := currency.NewAmount(number, currencyCode)
+	assert.NoError(t, err)
+
+	return a
+}
+
+func TestPrecision(t *testing.T) {
+	tests := []struct {
+		currencyCode string
+		expected     uint8
+	}{
+		{"EUR", 2},
+		{"JPY", 0},
+		{"KWD", 3},
+		{"HUF", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currencyCode, func(t *testing.T) {
+			prec, err := Precision(tt.currencyCode)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, prec)
+		})
+	}
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		_, err := Precision("XYZ")
+		assert.Error(t, err)
+
+		_, err = Precision("")
+		assert.Error(t, err)
+	})
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   currency.Amount
+		locale   language.Tag
+		expected string
+	}{
+		{"english euro", amount(t, "1234.5", "EUR"), language.English, "€1,234.50"},
+		{"german euro", amount(t, "1234.5", "EUR"), language.German, "1.234,50\u00a0€"},
+		{"hungarian forint", amount(t, "12000", "HUF"), language.Hungarian, "12\u00a0000\u00a0Ft"},
+		{"forint is rounded", amount(t, "8000.6", "HUF"), language.English, "HUF\u00a08,001"},
+		{"three digit currency", amount(t, "1.2345", "KWD"), language.English, "KWD\u00a01.235"},
+		{"unknown locale falls back", amount(t, "5", "USD"), language.Und, "$5.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted, err := Format(tt.amount, tt.locale)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, formatted)
+		})
+	}
+
+	t.Run("missing currency", func(t *testing.T) {
+		_, err := Format(currency.Amount{}, language.English)
+		assert.Error(t, err)
+	})
+}
+
+func TestRound(t *testing.T) {
+	rounded, err := Round(amount(t, "10.005", "EUR"))
+	assert.NoError(t, err)
+	assert.Equal(t, "10.01", rounded.Number())
+
+	rounded, err = Round(amount(t, "2125.5", "HUF"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2126", rounded.Number())
+
+	_, err = Round(currency.Amount{})
+	assert.Error(t, err)
+}
+
+func TestFindBest(t *testing.T) {
+	tests := []struct {
+		lang     language.Tag
+		expected string
+	}{
+		{language.Hungarian, "HUF"},
+		{language.German, "EUR"},
+		{language.MustParse("de-CH"), "CHF"},
+		{language.Japanese, "JPY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang.String(), func(t *testing.T) {
+			currencyCode, err := FindBest(tt.lang)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, currencyCode)
+		})
+	}
+}
+
+func TestFormattedPriceMarshalJSON(t *testing.T) {
+	price := Price{Amount: amount(t, "1234.5", "EUR")}
+
+	data, err := price.ToFormatted(language.German).MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, "\"1.234,50\u00a0€\"", string(data))
+
+	_, err = FormattedPrice{Locale: language.English}.MarshalJSON()
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+// Package currencyxtest provides helpers for the tests which work with prices
+package currencyxtest
+
+import (
+	"testing"
+
+	"github.com/bojanz/currency"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates the price or fails the test if the number or the currency code is invalid
+func Price(t testing.TB, number string, currencyCode string) currencyx.Price {
+	t.Helper()
+
+	amount, err := currency.NewAmount(number, currencyCode)
+	assert.NoError(t, err)
+
+	return currencyx.Price{Amount: amount}
+}
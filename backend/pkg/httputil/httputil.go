@@ -3,8 +3,12 @@ package httputil
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 
+	"github.com/miketsu-inc/reservations/backend/cmd/config"
 	"github.com/miketsu-inc/reservations/backend/pkg/assert"
 )
 
@@ -50,3 +54,62 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	// for debug, let's see if we should handle this
 	assert.Nil(err, "Could not be encoded to json", v, err)
 }
+
+// Returns the client's ip address. The headers set by a reverse proxy are only
+// used if the request came from one of the configured trusted proxies
+func ClientIP(r *http.Request) string {
+	return clientIP(r, config.LoadEnvVars().TRUSTED_PROXIES)
+}
+
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote := remoteIP(r)
+
+	if !isTrusted(remote, trustedProxies) {
+		return remote
+	}
+
+	// every proxy appends the address it got the request from, so the first
+	// untrusted one from the right is the client, the ones before it can be spoofed
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+
+		if !isTrusted(ip, trustedProxies) {
+			return ip
+		}
+	}
+
+	if realIp := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIp != "" {
+		return realIp
+	}
+
+	return remote
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func isTrusted(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package httputil
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("127.0.0.1/32")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIp     string
+		expected   string
+	}{
+		{"no proxy", "203.0.113.7:5123", "", "", "203.0.113.7"},
+		{"spoofed by the client", "203.0.113.7:5123", "1.2.3.4", "5.6.7.8", "203.0.113.7"},
+		{"trusted proxy", "127.0.0.1:5123", "198.51.100.2", "", "198.51.100.2"},
+		{"spoofed before the proxy", "127.0.0.1:5123", "1.2.3.4, 198.51.100.2", "", "198.51.100.2"},
+		{"chain of trusted proxies", "127.0.0.1:5123", "198.51.100.2, 10.1.2.3", "", "198.51.100.2"},
+		{"real ip header", "127.0.0.1:5123", "", "198.51.100.2", "198.51.100.2"},
+		{"trusted proxy without headers", "127.0.0.1:5123", "", "", "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIp != "" {
+				r.Header.Set("X-Real-IP", tt.realIp)
+			}
+
+			assert.Equal(t, tt.expected, clientIP(r, trusted))
+		})
+	}
+
+	t.Run("no trusted proxies", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "127.0.0.1:5123"
+		r.Header.Set("X-Forwarded-For", "198.51.100.2")
+
+		assert.Equal(t, "127.0.0.1", clientIP(r, nil))
+	})
+}
@@ -0,0 +1,407 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenApi    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type SecurityRequirement map[string][]string
+
+// Operations of a path by lowercase http method
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []ParameterObject     `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type ParamLocation string
+
+const (
+	InPath   ParamLocation = "path"
+	InQuery  ParamLocation = "query"
+	InHeader ParamLocation = "header"
+)
+
+type Param struct {
+	Name        string
+	In          ParamLocation
+	Description string
+	Required    bool
+	// Go value of the parameter's type, string if nil
+	Type any
+	// Allowed values of the parameter
+	Enum []string
+}
+
+// Describes the handler of a route, the method and the path come from the router
+type Operation struct {
+	Handler http.HandlerFunc
+	Summary string
+	Tags    []string
+	// Value of the json request body's type, nil if the operation does not have a body
+	Request any
+	// Name of the form field of a multipart file upload, empty if the operation does not upload a file
+	Upload string
+	// Value of the type in the data field of a successful response, nil if it only returns a status
+	Response any
+	// Media types of a successful response which is a file instead of json
+	Files []string
+	// Status of a successful response, 200 by default
+	Status int
+	// Query parameters and path parameters which are not strings
+	Params []Param
+}
+
+// Adds the tag to every operation
+func WithTag(tag string, ops ...Operation) []Operation {
+	for i := range ops {
+		ops[i].Tags = append(ops[i].Tags, tag)
+	}
+
+	return ops
+}
+
+type Config struct {
+	Info Info
+	// Security schemes of the document by name
+	SecuritySchemes map[string]*SecurityScheme
+	// Returns the security requirements of a route from the names of it's middlewares, see FuncName
+	Security func(method, path string, middlewares []string) []SecurityRequirement
+}
+
+// Returns the name of the function behind the handler, it identifies the operation of a route
+func handlerName(h http.Handler) string {
+	if hf, ok := h.(http.HandlerFunc); ok {
+		return FuncName(hf)
+	}
+
+	return reflect.TypeOf(h).String()
+}
+
+// Returns the full name of a function, method values of the same method share the name
+func FuncName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return ""
+	}
+
+	return runtime.FuncForPC(v.Pointer()).Name()
+}
+
+var paramRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Converts a chi route pattern to an openapi path
+func toPath(route string) string {
+	route = paramRegexp.ReplaceAllString(route, "{$1}")
+
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+
+	return route
+}
+
+// Generates the document from the routes of the router. Every route needs an operation
+// with it's handler, the routes without one are returned in the error.
+func Generate(router chi.Routes, config Config, ops ...Operation) (*Document, error) {
+	// the document describes the route serving it too
+	ops = append(ops, Operation{Handler: (&server{}).serveHTTP, Summary: "OpenAPI document of the api", Tags: []string{"Meta"}})
+
+	byHandler := make(map[string]Operation, len(ops))
+	for _, op := range ops {
+		byHandler[handlerName(op.Handler)] = op
+	}
+
+	doc := &Document{
+		OpenApi: Version,
+		Info:    config.Info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": errorSchema(),
+			},
+			SecuritySchemes: config.SecuritySchemes,
+		},
+	}
+
+	var missing []string
+
+	err := chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		path := toPath(route)
+
+		op, ok := byHandler[handlerName(handler)]
+		if !ok {
+			missing = append(missing, method+" "+path)
+			return nil
+		}
+
+		operation, err := newOperationObject(method, path, op)
+		if err != nil {
+			return err
+		}
+
+		if config.Security != nil {
+			names := make([]string, len(middlewares))
+			for i, mw := range middlewares {
+				names[i] = FuncName(mw)
+			}
+
+			operation.Security = config.Security(method, path, names)
+		}
+
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = PathItem{}
+		}
+
+		doc.Paths[path][strings.ToLower(method)] = operation
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return doc, fmt.Errorf("routes without an openapi operation: %s", strings.Join(missing, ", "))
+	}
+
+	return doc, nil
+}
+
+func newOperationObject(method, path string, op Operation) (*OperationObject, error) {
+	operation := &OperationObject{
+		OperationId: operationId(method, path),
+		Summary:     op.Summary,
+		Tags:        op.Tags,
+		Responses:   map[string]Response{},
+	}
+
+	pathParams := paramRegexp.FindAllStringSubmatch(path, -1)
+	for _, match := range pathParams {
+		param := Param{Name: match[1], In: InPath, Required: true}
+
+		for _, p := range op.Params {
+			if p.In == InPath && p.Name == param.Name {
+				param = p
+				param.Required = true
+			}
+		}
+
+		operation.Parameters = append(operation.Parameters, newParameterObject(param))
+	}
+
+	for _, p := range op.Params {
+		if p.In == InPath {
+			if !slices.ContainsFunc(pathParams, func(m []string) bool { return m[1] == p.Name }) {
+				return nil, fmt.Errorf("%s %s: path parameter %s is not in the route", method, path, p.Name)
+			}
+
+			continue
+		}
+
+		operation.Parameters = append(operation.Parameters, newParameterObject(p))
+	}
+
+	if op.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: SchemaOf(op.Request)},
+			},
+		}
+	}
+
+	if op.Upload != "" {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"multipart/form-data": {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{op.Upload: {Type: "string", Format: "binary"}},
+					Required:   []string{op.Upload},
+				}},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := Response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = map[string]MediaType{
+			"application/json": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": ResponseSchemaOf(op.Response)},
+				Required:   []string{"data"},
+			}},
+		}
+	}
+
+	for _, mediaType := range op.Files {
+		if success.Content == nil {
+			success.Content = map[string]MediaType{}
+		}
+
+		success.Content[mediaType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+
+	operation.Responses[fmt.Sprint(status)] = success
+	operation.Responses["default"] = Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+
+	return operation, nil
+}
+
+func newParameterObject(p Param) ParameterObject {
+	schema := &Schema{Type: "string"}
+	if p.Type != nil {
+		schema = SchemaOf(p.Type)
+	}
+
+	for _, v := range p.Enum {
+		schema.Enum = append(schema.Enum, v)
+	}
+
+	return ParameterObject{
+		Name:        p.Name,
+		In:          string(p.In),
+		Description: p.Description,
+		Required:    p.Required,
+		Schema:      schema,
+	}
+}
+
+var nonAlphanumericRegexp = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// Unique id of the operation built from the method and the path,
+// e.g. GET /api/v1/merchants/{merchantId}/team becomes get_api_v1_merchants_merchantId_team
+func operationId(method, path string) string {
+	id := nonAlphanumericRegexp.ReplaceAllString(path, "_")
+
+	return strings.ToLower(method) + strings.TrimSuffix(id, "_")
+}
+
+// Body of every error response, see httputil.Error
+func errorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"message": {Type: "string"},
+				},
+				Required: []string{"message"},
+			},
+		},
+		Required: []string{"error"},
+	}
+}
+
+// Serves the document of the router as json. The document is generated on the first request,
+// so every route of the router is registered by then, including this one
+func Handler(router chi.Routes, config Config, ops ...Operation) http.HandlerFunc {
+	s := &server{router: router, config: config, ops: ops}
+
+	return s.serveHTTP
+}
+
+type server struct {
+	router chi.Routes
+	config Config
+	ops    []Operation
+
+	once sync.Once
+	doc  []byte
+	err  error
+}
+
+func (s *server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.once.Do(func() {
+		// routes without an operation are left out, the document is still usable without them
+		doc, _ := Generate(s.router, s.config, s.ops...)
+
+		s.doc, s.err = json.Marshal(doc)
+	})
+
+	if s.err != nil {
+		http.Error(w, s.err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(s.doc) // nolint:errcheck
+}
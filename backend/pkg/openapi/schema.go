@@ -0,0 +1,376 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JSON Schema of the OpenAPI 3.1 document, only the keywords used by the generator
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+}
+
+var (
+	customSchemasMu sync.RWMutex
+	customSchemas   = map[reflect.Type]func() *Schema{
+		reflect.TypeFor[time.Time]():       func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+		reflect.TypeFor[uuid.UUID]():       func() *Schema { return &Schema{Type: "string", Format: "uuid"} },
+		reflect.TypeFor[json.RawMessage](): func() *Schema { return &Schema{} },
+	}
+)
+
+// Sets the schema of a type which has a custom json encoding.
+// Types implementing json.Marshaler without a registered schema are described as strings
+func RegisterSchema(v any, schema func() *Schema) {
+	customSchemasMu.Lock()
+	defer customSchemasMu.Unlock()
+
+	customSchemas[reflect.TypeOf(v)] = schema
+}
+
+// Registers a type which is encoded as one of the values
+func RegisterEnum[T any](values ...T) {
+	var enum []any
+	for _, v := range values {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(encoded, &s); err == nil {
+			enum = append(enum, s)
+		}
+	}
+
+	RegisterSchema(*new(T), func() *Schema {
+		return &Schema{Type: "string", Enum: slices.Clone(enum)}
+	})
+}
+
+func customSchema(t reflect.Type) (*Schema, bool) {
+	customSchemasMu.RLock()
+	defer customSchemasMu.RUnlock()
+
+	fn, ok := customSchemas[t]
+	if !ok {
+		return nil, false
+	}
+
+	return fn(), true
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+type generator struct {
+	// responses always contain the fields without omitempty,
+	// requests only need the ones with the required validator tag
+	isResponse bool
+	visiting   map[reflect.Type]bool
+}
+
+// Returns the schema of a request body's type as it is decoded by encoding/json,
+// with the constraints of it's validator tags
+func SchemaOf(v any) *Schema {
+	g := generator{visiting: map[reflect.Type]bool{}}
+
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// Returns the schema of a response's type as it is encoded by encoding/json
+func ResponseSchemaOf(v any) *Schema {
+	g := generator{isResponse: true, visiting: map[reflect.Type]bool{}}
+
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+func (g generator) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(g.schemaOf(t.Elem()))
+	}
+
+	if schema, ok := customSchema(t); ok {
+		return schema
+	}
+
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		// nil slices are encoded as null
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+
+	case reflect.Struct:
+		// recursive types are not expanded again
+		if g.visiting[t] {
+			return &Schema{Type: "object"}
+		}
+
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		g.addStructFields(schema, t)
+
+		return schema
+
+	default:
+		return &Schema{}
+	}
+}
+
+func (g generator) addStructFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(jsonTag, ",")
+
+		// fields of embedded structs are promoted to the parent, like encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct && !embedded.Implements(jsonMarshalerType) {
+				g.addStructFields(schema, embedded)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.schemaOf(field.Type)
+		if slices.Contains(strings.Split(opts, ","), "string") {
+			fieldSchema = &Schema{Type: "string"}
+		}
+
+		required := applyValidateTag(fieldSchema, field.Tag.Get("validate"))
+		if g.isResponse {
+			required = !slices.Contains(strings.Split(opts, ","), "omitempty")
+		}
+
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// Adds the constraints of the validator tag to the schema and reports whether the field is required
+func applyValidateTag(schema *Schema, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	required := false
+
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		// the rules after dive apply to the items, they are not described
+		case "dive":
+			return required
+
+		case "required":
+			required = true
+
+		case "email":
+			schema.Format = "email"
+
+		case "url", "http_url", "uri":
+			schema.Format = "uri"
+
+		case "uuid", "uuid4", "uuid7":
+			schema.Format = "uuid"
+
+		case "e164":
+			schema.Format = "e164"
+
+		case "oneof":
+			schema.Enum = nil
+			for v := range strings.FieldsSeq(param) {
+				schema.Enum = append(schema.Enum, v)
+			}
+
+			if isNullable(schema) {
+				schema.Enum = append(schema.Enum, nil)
+			}
+
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+
+			applyLength(schema, name, n)
+
+		case "gte", "lte", "gt", "lt":
+			f, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+
+			// on strings and arrays these limit the length
+			if t := primaryType(schema); t == "string" || t == "array" {
+				switch name {
+				case "gte":
+					applyLength(schema, "min", int(f))
+				case "lte":
+					applyLength(schema, "max", int(f))
+				}
+
+				continue
+			}
+
+			switch name {
+			case "gte":
+				schema.Minimum = &f
+			case "lte":
+				schema.Maximum = &f
+			case "gt":
+				schema.ExclusiveMinimum = &f
+			case "lt":
+				schema.ExclusiveMaximum = &f
+			}
+		}
+	}
+
+	return required
+}
+
+// min, max and len mean the length of strings and arrays but the value of numbers
+func applyLength(schema *Schema, rule string, n int) {
+	setMin := rule == "min" || rule == "len"
+	setMax := rule == "max" || rule == "len"
+
+	switch primaryType(schema) {
+	case "string":
+		if setMin {
+			schema.MinLength = &n
+		}
+		if setMax {
+			schema.MaxLength = &n
+		}
+
+	case "array":
+		if setMin {
+			schema.MinItems = &n
+		}
+		if setMax {
+			schema.MaxItems = &n
+		}
+
+	case "integer", "number":
+		f := float64(n)
+		if setMin {
+			schema.Minimum = &f
+		}
+		if setMax {
+			schema.Maximum = &f
+		}
+	}
+}
+
+// OpenAPI 3.1 has no nullable keyword, null is added to the allowed types instead
+func nullable(schema *Schema) *Schema {
+	switch t := schema.Type.(type) {
+	case string:
+		schema.Type = []string{t, "null"}
+		if len(schema.Enum) > 0 {
+			schema.Enum = append(schema.Enum, nil)
+		}
+
+		return schema
+	case nil:
+		// schemas without a type allow null already
+		if schema.Ref == "" {
+			return schema
+		}
+	}
+
+	return &Schema{OneOf: []*Schema{schema, {Type: "null"}}}
+}
+
+func isNullable(schema *Schema) bool {
+	t, ok := schema.Type.([]string)
+
+	return ok && slices.Contains(t, "null")
+}
+
+// Returns the type of the schema's non null values
+func primaryType(schema *Schema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []string:
+		if len(t) > 0 {
+			return t[0]
+		}
+	}
+
+	return ""
+}
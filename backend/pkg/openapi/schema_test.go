@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testReq struct {
+	Name     string    `json:"name" validate:"required,min=1,max=255"`
+	Email    *string   `json:"email" validate:"omitempty,email"`
+	Kind     string    `json:"kind" validate:"required,oneof=a b"`
+	Count    int       `json:"count" validate:"gte=0,lte=10"`
+	Tags     []string  `json:"tags" validate:"max=3,dive,max=20"`
+	At       time.Time `json:"at"`
+	Internal string    `json:"-"`
+}
+
+func TestSchemaOfRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := SchemaOf(testReq{})
+
+	assert.Equal("object", schema.Type)
+	assert.Equal([]string{"name", "kind"}, schema.Required)
+	assert.NotContains(schema.Properties, "Internal")
+
+	name := schema.Properties["name"]
+	assert.Equal("string", name.Type)
+	assert.Equal(1, *name.MinLength)
+	assert.Equal(255, *name.MaxLength)
+
+	email := schema.Properties["email"]
+	assert.Equal([]string{"string", "null"}, email.Type)
+	assert.Equal("email", email.Format)
+
+	assert.Equal([]any{"a", "b"}, schema.Properties["kind"].Enum)
+
+	count := schema.Properties["count"]
+	assert.Equal(0.0, *count.Minimum)
+	assert.Equal(10.0, *count.Maximum)
+
+	tags := schema.Properties["tags"]
+	assert.Equal(3, *tags.MaxItems)
+	// the rules after dive are not applied to the array
+	assert.Nil(tags.MaxLength)
+	assert.Nil(tags.Items.MaxLength)
+
+	assert.Equal("date-time", schema.Properties["at"].Format)
+}
+
+type testResp struct {
+	Id       int        `json:"id"`
+	Note     *string    `json:"note,omitempty"`
+	Children []testResp `json:"children"`
+}
+
+func TestResponseSchemaOf(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := ResponseSchemaOf(testResp{})
+
+	assert.Equal([]string{"id", "children"}, schema.Required)
+
+	// recursive types are not expanded again
+	assert.Equal(&Schema{Type: "object"}, schema.Properties["children"].Items)
+}
+
+type testEnum struct{ v string }
+
+func (e testEnum) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + e.v + `"`), nil
+}
+
+func TestRegisterEnum(t *testing.T) {
+	assert := assert.New(t)
+
+	RegisterEnum(testEnum{"first"}, testEnum{"second"})
+
+	schema := SchemaOf(struct {
+		Value    testEnum  `json:"value"`
+		Optional *testEnum `json:"optional"`
+	}{})
+
+	assert.Equal(&Schema{Type: "string", Enum: []any{"first", "second"}}, schema.Properties["value"])
+	assert.Equal(&Schema{Type: []string{"string", "null"}, Enum: []any{"first", "second", nil}}, schema.Properties["optional"])
+}
+
+func TestToPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/api/v1/customers", toPath("/api/v1/customers/"))
+	assert.Equal("/api/v1/services/{id}", toPath("/api/v1/services/{id:[0-9]+}"))
+	assert.Equal("/", toPath("/"))
+}
@@ -0,0 +1,110 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Glyph metrics are measured in thousandths of the font size like the widths of PDF fonts
+var unitsPerEm = fixed.I(1000)
+
+// An embedded TrueType font, the whole font file is embedded so every character it has can be drawn
+type face struct {
+	font      *sfnt.Font
+	name      string
+	file      []byte
+	length    int
+	bbox      [4]int
+	ascent    int
+	descent   int
+	capHeight int
+}
+
+var faces = sync.OnceValues(func() (map[Font]*face, error) {
+	regular, err := newFace(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+
+	bold, err := newFace(gobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[Font]*face{Regular: regular, Bold: bold}, nil
+})
+
+func newFace(ttf []byte) (*face, error) {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse font: %w", err)
+	}
+
+	var b sfnt.Buffer
+
+	name, err := f.Name(&b, sfnt.NameIDPostScript)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font name: %w", err)
+	}
+
+	bounds, err := f.Bounds(&b, unitsPerEm, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font bounds: %w", err)
+	}
+
+	metrics, err := f.Metrics(&b, unitsPerEm, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("could not read font metrics: %w", err)
+	}
+
+	var file bytes.Buffer
+	zw := zlib.NewWriter(&file)
+	_, err = zw.Write(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("could not compress font: %w", err)
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not compress font: %w", err)
+	}
+
+	// the y axis of sfnt points down while the one of PDF points up
+	return &face{
+		font:      f,
+		name:      name,
+		file:      file.Bytes(),
+		length:    len(ttf),
+		bbox:      [4]int{bounds.Min.X.Round(), -bounds.Max.Y.Round(), bounds.Max.X.Round(), -bounds.Min.Y.Round()},
+		ascent:    metrics.Ascent.Round(),
+		descent:   -metrics.Descent.Round(),
+		capHeight: metrics.CapHeight.Round(),
+	}, nil
+}
+
+// Looks up the glyph of the character and its width, characters missing from the font become question marks
+func (f *face) glyph(b *sfnt.Buffer, r rune) (sfnt.GlyphIndex, int, rune, error) {
+	index, err := f.font.GlyphIndex(b, r)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not look up glyph: %w", err)
+	}
+
+	if index == 0 && r != '?' {
+		return f.glyph(b, '?')
+	}
+
+	advance, err := f.font.GlyphAdvance(b, index, unitsPerEm, font.HintingNone)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not read glyph width: %w", err)
+	}
+
+	return index, advance.Round(), r, nil
+}
@@ -0,0 +1,240 @@
+// Package pdf writes simple documents of text and lines in the Portable Document Format,
+// the text uses the embedded Go fonts so every language can be written
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+const ContentType = "application/pdf"
+
+// Size of an A4 page in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Names of the font resources in the content streams
+var fontNames = map[Font]string{
+	Regular: "F1",
+	Bold:    "F2",
+}
+
+type Document struct {
+	title  string
+	pages  []*Page
+	glyphs map[Font]map[sfnt.GlyphIndex]glyph
+	buffer sfnt.Buffer
+	err    error
+}
+
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// A glyph drawn in the document with the character it stands for
+type glyph struct {
+	char  rune
+	width int
+}
+
+func New(title string) *Document {
+	return &Document{title: title, glyphs: map[Font]map[sfnt.GlyphIndex]glyph{Regular: {}, Bold: {}}}
+}
+
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+
+	return page
+}
+
+// Draws the text with its baseline starting at x and y, measured from the top left corner of the page
+func (p *Page) Text(x float64, y float64, font Font, size float64, text string) {
+	encoded, err := p.doc.encode(font, text)
+	if err != nil {
+		// the error is returned when the document is written
+		if p.doc.err == nil {
+			p.doc.err = err
+		}
+
+		return
+	}
+
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td <%s> Tj ET\n",
+		fontNames[font], number(size), number(x), number(PageHeight-y), encoded)
+}
+
+// Draws a gray line between the two points, measured from the top left corner of the page
+func (p *Page) Line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	fmt.Fprintf(&p.content, "0.75 G 0.5 w %s %s m %s %s l S 0 G\n",
+		number(x1), number(PageHeight-y1), number(x2), number(PageHeight-y2))
+}
+
+// Writes the document, a document without pages gets an empty one
+func (d *Document) Write(w io.Writer) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	faces, err := faces()
+	if err != nil {
+		return err
+	}
+
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var b bytes.Buffer
+	offsets := []int{}
+
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	stream := func(dict string, data []byte) {
+		object(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+	}
+
+	// the first page object comes after the catalog, pages, fonts and info, every page has a content stream
+	firstPage := 6
+	// every font has a descendant font, a descriptor, a font file and a character map after the pages
+	firstFont := firstPage + 2*len(d.pages)
+	fonts := []Font{Regular, Bold}
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	b.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for i, font := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			faces[font].name, firstFont+4*i, firstFont+4*i+3))
+	}
+	object(fmt.Sprintf("<< /Title %s /Producer (Reservations) >>", textString(d.title)))
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			number(PageWidth), number(PageHeight), firstPage+2*i+1))
+		stream("", page.content.Bytes())
+	}
+
+	for i, font := range fonts {
+		face := faces[font]
+		glyphs := d.glyphs[font]
+		indexes := slices.Sorted(maps.Keys(glyphs))
+
+		widths := make([]string, len(indexes))
+		for j, index := range indexes {
+			widths[j] = fmt.Sprintf("%d [%d]", index, glyphs[index].width)
+		}
+
+		object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>", face.name, firstFont+4*i+1, strings.Join(widths, " ")))
+		object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 "+
+			"/Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			face.name, face.bbox[0], face.bbox[1], face.bbox[2], face.bbox[3], face.ascent, face.descent, face.capHeight, firstFont+4*i+2))
+		stream(fmt.Sprintf("/Length1 %d /Filter /FlateDecode", face.length), face.file)
+		stream("", toUnicode(indexes, glyphs))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Converts the text to the glyph indexes of the font as hexadecimal, remembering the glyphs for the font resources
+func (d *Document) encode(font Font, text string) (string, error) {
+	faces, err := faces()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for _, r := range text {
+		index, width, char, err := faces[font].glyph(&d.buffer, r)
+		if err != nil {
+			return "", err
+		}
+
+		if _, ok := d.glyphs[font][index]; !ok {
+			d.glyphs[font][index] = glyph{char: char, width: width}
+		}
+
+		fmt.Fprintf(&b, "%04X", uint16(index))
+	}
+
+	return b.String(), nil
+}
+
+// Maps the glyphs back to their characters so the text of the document can be copied and searched
+func toUnicode(indexes []sfnt.GlyphIndex, glyphs map[sfnt.GlyphIndex]glyph) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// a block can have at most 100 entries
+	for chunk := range slices.Chunk(indexes, 100) {
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, index := range chunk {
+			fmt.Fprintf(&b, "<%04X> <%s>\n", uint16(index), utf16Hex(string(glyphs[index].char)))
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+
+	return b.Bytes()
+}
+
+// Encodes the text as a hexadecimal UTF-16 string with a byte order mark, which can hold any character
+func textString(text string) string {
+	return "<FEFF" + utf16Hex(text) + ">"
+}
+
+func utf16Hex(text string) string {
+	var b strings.Builder
+
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/miketsu-inc/reservations/backend/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := New("Invoice (1)")
+	page := doc.AddPage()
+	page.Text(50, 60, Bold, 18, "Számla (copy)")
+	page.Text(50, 80, Regular, 10, `Kőrösi Gyűrű \ 100 €`)
+	page.Line(50, 90, 545, 90)
+
+	var buf bytes.Buffer
+	err := doc.Write(&buf)
+	assert.Nil(err)
+
+	out := buf.Bytes()
+	assert.True(bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(bytes.HasSuffix(out, []byte("%%EOF\n")))
+
+	// the title is written in UTF-16 and the texts as glyphs of the embedded fonts
+	assert.Contains(string(out), "/Title <FEFF0049006E0076006F0069006300650020002800310029>")
+	assert.Equal(2, bytes.Count(out, []byte("/FontFile2")))
+	assert.Len(regexp.MustCompile(`<[0-9A-F]+> Tj`).FindAll(out, -1), 2)
+	assert.Contains(string(out), "/Count 1")
+
+	// the characters missing from Windows-1252 keep their own glyphs
+	for _, char := range []string{"<0151>", "<0171>", "<00F6>", "<20AC>", "<005C>", "<00E1>"} {
+		assert.Contains(string(out), char)
+	}
+
+	// every entry of the cross reference table points to its object
+	xrefStart := bytes.LastIndex(out, []byte("startxref\n"))
+	xref, err := strconv.Atoi(string(bytes.Fields(out[xrefStart:])[1]))
+	assert.Nil(err)
+	assert.True(bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	assert.Len(entries, 15)
+
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		assert.Nil(err)
+		assert.True(bytes.HasPrefix(out[offset:], fmt.Appendf(nil, "%d 0 obj", i+1)))
+	}
+}
+
+func TestWriteWithoutPages(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	err := New("Empty").Write(&buf)
+	assert.Nil(err)
+
+	assert.Contains(buf.String(), "/Count 1")
+}
+
+func TestWriteMissingCharacter(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := New("Missing")
+	page := doc.AddPage()
+	page.Text(50, 60, Regular, 10, "?")
+	page.Text(50, 80, Regular, 10, "\U0001F600")
+
+	var buf bytes.Buffer
+	err := doc.Write(&buf)
+	assert.Nil(err)
+
+	// characters missing from the font are drawn as question marks
+	texts := regexp.MustCompile(`<([0-9A-F]+)> Tj`).FindAllSubmatch(buf.Bytes(), -1)
+	if !assert.Len(texts, 2) {
+		return
+	}
+
+	assert.Equal(texts[0][1], texts[1][1])
+}
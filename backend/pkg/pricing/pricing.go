@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bojanz/currency"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+)
+
+// A tax rate in percent. The tax of an inclusive rate is already part of the price
+// it is applied to, the tax of an exclusive rate is added on top of it.
+type Rate struct {
+	Percent   string
+	Inclusive bool
+}
+
+// The parts of a price, each rounded to the precision of its currency
+type Breakdown struct {
+	Net   currencyx.Price
+	Tax   currencyx.Price
+	Gross currencyx.Price
+}
+
+// between 0 and 100 with at most two decimals, like the numeric(5, 2) column of the rates
+var percentRegex = regexp.MustCompile(`^\d{1,2}(\.\d{1,2})?$`)
+
+func ValidatePercent(percent string) error {
+	if !percentRegex.MatchString(percent) {
+		return fmt.Errorf("tax percent has to be between 0 and 100 with at most two decimals")
+	}
+
+	return nil
+}
+
+// Splits the price into its net, tax and gross parts. A price without a rate is not taxed.
+func Calculate(price currencyx.Price, rate *Rate) (Breakdown, error) {
+	if rate == nil {
+		zero, err := currency.NewAmount("0", price.CurrencyCode())
+		if err != nil {
+			return Breakdown{}, err
+		}
+
+		return Breakdown{Net: price, Tax: currencyx.Price{Amount: zero}, Gross: price}, nil
+	}
+
+	if rate.Inclusive {
+		return FromGross(price, rate.Percent)
+	}
+
+	return FromNet(price, rate.Percent)
+}
+
+// The tax is included in the gross price: tax = gross * percent / (100 + percent)
+func FromGross(gross currencyx.Price, percent string) (Breakdown, error) {
+	percentAmount, err := currency.NewAmount(percent, gross.CurrencyCode())
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("invalid tax percent: %s", percent)
+	}
+
+	hundred, err := currency.NewAmount("100", gross.CurrencyCode())
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	divisor, err := percentAmount.Add(hundred)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	tax, err := gross.Mul(percent)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	tax, err = tax.Div(divisor.Number())
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	tax, err = currencyx.Round(tax)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	net, err := gross.Sub(tax)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	return Breakdown{
+		Net:   currencyx.Price{Amount: net},
+		Tax:   currencyx.Price{Amount: tax},
+		Gross: gross,
+	}, nil
+}
+
+// The tax is added on top of the net price: tax = net * percent / 100
+func FromNet(net currencyx.Price, percent string) (Breakdown, error) {
+	tax, err := net.Mul(percent)
+	if err != nil {
+		return Breakdown{}, fmt.Errorf("invalid tax percent: %s", percent)
+	}
+
+	tax, err = tax.Div("100")
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	tax, err = currencyx.Round(tax)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	gross, err := net.Add(tax)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	return Breakdown{
+		Net:   net,
+		Tax:   currencyx.Price{Amount: tax},
+		Gross: currencyx.Price{Amount: gross},
+	}, nil
+}
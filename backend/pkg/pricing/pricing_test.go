@@ -0,0 +1,50 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx"
+	"github.com/miketsu-inc/reservations/backend/pkg/currencyx/currencyxtest"
+	. "github.com/miketsu-inc/reservations/backend/pkg/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name  string
+		price currencyx.Price
+		rate  *Rate
+		net   string
+		tax   string
+		gross string
+	}{
+		{"without rate", currencyxtest.Price(t, "8000", "HUF"), nil, "8000", "0", "8000"},
+		{"inclusive", currencyxtest.Price(t, "10000", "HUF"), &Rate{Percent: "27", Inclusive: true}, "7874", "2126", "10000"},
+		{"exclusive", currencyxtest.Price(t, "10000", "HUF"), &Rate{Percent: "27", Inclusive: false}, "10000", "2700", "12700"},
+		{"inclusive with cents", currencyxtest.Price(t, "12.99", "EUR"), &Rate{Percent: "19", Inclusive: true}, "10.92", "2.07", "12.99"},
+		{"exclusive with cents", currencyxtest.Price(t, "10.99", "EUR"), &Rate{Percent: "7.5", Inclusive: false}, "10.99", "0.82", "11.81"},
+		{"zero percent", currencyxtest.Price(t, "50", "USD"), &Rate{Percent: "0", Inclusive: false}, "50", "0.00", "50.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown, err := Calculate(tt.price, tt.rate)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.net, breakdown.Net.Number())
+			assert.Equal(t, tt.tax, breakdown.Tax.Number())
+			assert.Equal(t, tt.gross, breakdown.Gross.Number())
+			assert.Equal(t, tt.price.CurrencyCode(), breakdown.Tax.CurrencyCode())
+		})
+	}
+}
+
+func TestValidatePercent(t *testing.T) {
+	for _, percent := range []string{"0", "5", "27", "7.5", "99.99"} {
+		assert.NoError(t, ValidatePercent(percent), percent)
+	}
+
+	for _, percent := range []string{"", "-5", "100", "27.125", "abc", "1e2"} {
+		assert.Error(t, ValidatePercent(percent), percent)
+	}
+}
@@ -30,6 +30,7 @@ func NewClient[T any](dbConn *pgxpool.Pool, deps T, registerWorkersFunc Register
 		Queues: map[string]river.QueueConfig{
 			river.QueueDefault: {MaxWorkers: 100},
 			"email":            {MaxWorkers: 100},
+			"webhook":          {MaxWorkers: 50},
 		},
 		Workers:      riverWorkers,
 		PeriodicJobs: periodicJobs,
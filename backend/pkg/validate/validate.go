@@ -51,6 +51,20 @@ func Struct(s any) error {
 	return nil
 }
 
+// Validate a single value against the tags and return nil or the first error
+func Var(value any, tag string) error {
+	err := validate.Var(value, tag)
+	if err != nil {
+
+		errors := err.(validator.ValidationErrors)
+		for _, err := range errors {
+			return fmt.Errorf("%s", errorMessageForTag(err))
+		}
+	}
+
+	return nil
+}
+
 func errorMessageForTag(err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
@@ -65,6 +79,8 @@ func errorMessageForTag(err validator.FieldError) string {
 		return fmt.Sprintf("This field should be at least %s long", err.Param())
 	case "max":
 		return fmt.Sprintf("This field should be at most %s long", err.Param())
+	case "datetime":
+		return fmt.Sprintf("Must be a date like %s", err.Param())
 	}
 	return err.Error()
 }
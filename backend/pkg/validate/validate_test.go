@@ -23,3 +23,13 @@ func TestMerchantNameToUrlName(t *testing.T) {
 	_, err := MerchantNameToUrlName("*(^&)")
 	assert.NotNil(err)
 }
+
+func TestVar(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(Var("short", "max=10"))
+	assert.EqualError(Var("far too long", "max=10"), "This field should be at most 10 long")
+	assert.EqualError(Var("", "required"), "This field is required")
+	assert.Nil(Var("2025-03-01", "datetime=2006-01-02"))
+	assert.EqualError(Var("03/01/2025", "datetime=2006-01-02"), "Must be a date like 2006-01-02")
+}
@@ -0,0 +1,120 @@
+// Package xlsx writes simple spreadsheets in the Office Open XML format,
+// every cell is written as text
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+const contentTypesXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const relsXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelsXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+const workbookXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+// Writes a workbook with a single sheet containing the rows
+func Write(w io.Writer, sheetName string, rows [][]string) error {
+	zw := zip.NewWriter(w)
+
+	var escapedName strings.Builder
+	if err := xml.EscapeText(&escapedName, []byte(sheetName)); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXml},
+		{"_rels/.rels", relsXml},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXml},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXml, escapedName.String())},
+	}
+
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(f, file.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	if err := writeSheet(sheet, rows); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeSheet(w io.Writer, rows [][]string) error {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	for i, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, ColumnName(j), i+1)
+			if err := xml.EscapeText(&b, []byte(value)); err != nil {
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Returns the letters of the zero based column index, e.g. 0 is A and 26 is AA
+func ColumnName(index int) string {
+	name := ""
+
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+
+	return name
+}
@@ -0,0 +1,55 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/miketsu-inc/reservations/backend/pkg/xlsx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnName(t *testing.T) {
+	assert := assert.New(t)
+
+	testcases := []int{0, 25, 26, 27, 51, 52, 701, 702}
+	results := []string{"A", "Z", "AA", "AB", "AZ", "BA", "ZZ", "AAA"}
+
+	for i, index := range testcases {
+		assert.Equal(results[i], ColumnName(index))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	err := Write(&buf, "Customers & co", [][]string{
+		{"first_name", "note"},
+		{"Jane", "<likes> \"tea\""},
+		{"", "only note"},
+	})
+	assert.Nil(err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.Nil(err)
+
+		content, err := io.ReadAll(rc)
+		assert.Nil(err)
+
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(files, "[Content_Types].xml")
+	assert.Contains(files["xl/workbook.xml"], `name="Customers &amp; co"`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;likes&gt; &#34;tea&#34;</t></is></c>`)
+	assert.Contains(sheet, `<row r="3"><c r="B3"`)
+}
@@ -0,0 +1,55 @@
+import { Card } from "@reservations/components";
+
+function utilizationColor(percent) {
+  if (percent >= 85) return "bg-green-600";
+  if (percent >= 60) return "bg-green-500";
+  if (percent >= 35) return "bg-amber-400";
+  return "bg-red-400";
+}
+
+export default function UtilizationCard({ utilization }) {
+  return (
+    <Card styles="p-0!">
+      <div
+        className="border-border_color flex items-center justify-between
+          border-b p-4"
+      >
+        <div className="flex flex-col">
+          <p className="text-lg">Team utilization</p>
+          <p className="text-text_color/70 text-sm">
+            Booked time compared to working hours
+          </p>
+        </div>
+        <div className="flex flex-col items-end">
+          <p className="text-2xl font-semibold">
+            {utilization.utilization}%
+          </p>
+          <p className="text-text_color/70 text-sm">
+            {utilization.revenue_per_hour} / hour
+          </p>
+        </div>
+      </div>
+      <div className="flex max-h-48 flex-col gap-3 overflow-y-auto p-4">
+        {utilization.employees.length === 0 ? (
+          <p className="text-text_color/70 text-sm">No active employees</p>
+        ) : (
+          utilization.employees.map((employee) => (
+            <div key={employee.employee_id} className="flex flex-col gap-1">
+              <div className="flex justify-between text-sm">
+                <span>{employee.name}</span>
+                <span>{employee.utilization}%</span>
+              </div>
+              <div className="bg-hvr_gray h-2 w-full rounded-full">
+                <div
+                  className={`h-2 rounded-full
+                    ${utilizationColor(employee.utilization)}`}
+                  style={{ width: `${Math.min(employee.utilization, 100)}%` }}
+                />
+              </div>
+            </div>
+          ))
+        )}
+      </div>
+    </Card>
+  );
+}
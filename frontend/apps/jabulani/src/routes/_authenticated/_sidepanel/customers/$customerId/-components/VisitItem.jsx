@@ -0,0 +1,198 @@
+import {
+  Attachment01Icon,
+  Calendar02Icon,
+  Delete02Icon,
+} from "@hugeicons/core-free-icons";
+import { Button, Icon, Textarea } from "@reservations/components";
+import { useAuth } from "@reservations/jabulani/lib";
+import { invalidateLocalStorageAuth } from "@reservations/lib";
+import { useRef, useState } from "react";
+
+function monthDateFormat(date) {
+  return date.toLocaleDateString([], {
+    weekday: "short",
+    month: "short",
+    day: "numeric",
+  });
+}
+
+function formatFileSize(size) {
+  if (size < 1024 * 1024) {
+    return `${Math.ceil(size / 1024)} KB`;
+  }
+
+  return `${(size / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+export default function VisitItem({ visit, onChange, onError }) {
+  const { merchantId } = useAuth();
+  const [note, setNote] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+  const fileInputRef = useRef(null);
+
+  const visitUrl = `/api/v1/merchants/${merchantId}/visits/${visit.id}`;
+
+  async function send(url, options) {
+    setIsLoading(true);
+
+    try {
+      const response = await fetch(url, options);
+
+      if (!response.ok) {
+        invalidateLocalStorageAuth(response.status);
+        const result = await response.json();
+        onError(result.error.message);
+        return false;
+      }
+
+      onError();
+      await onChange();
+      return true;
+    } catch (err) {
+      onError(err.message);
+      return false;
+    } finally {
+      setIsLoading(false);
+    }
+  }
+
+  async function addNote(e) {
+    e.preventDefault();
+
+    const ok = await send(`${visitUrl}/notes`, {
+      method: "POST",
+      headers: {
+        Accept: "application/json",
+        "content-type": "application/json",
+      },
+      body: JSON.stringify({ note: note }),
+    });
+
+    if (ok) {
+      setNote("");
+    }
+  }
+
+  async function uploadAttachment(e) {
+    const file = e.target.files[0];
+    if (!file) return;
+
+    const body = new FormData();
+    body.append("file", file);
+
+    // the browser sets the multipart boundary itself
+    await send(`${visitUrl}/attachments`, {
+      method: "POST",
+      headers: {
+        Accept: "application/json",
+      },
+      body: body,
+    });
+
+    fileInputRef.current.value = "";
+  }
+
+  return (
+    <div className="flex flex-col gap-3 p-4">
+      <div className="flex items-center justify-between gap-4">
+        <h4 className="text-text_color font-semibold">{visit.service_name}</h4>
+        <span
+          className="flex items-center gap-2 text-sm text-gray-500
+            dark:text-gray-400"
+        >
+          <Icon
+            icon={Calendar02Icon}
+            styles="size-4 text-gray-500 dark:text-gray-400"
+          />
+          {monthDateFormat(new Date(visit.from_date))}
+        </span>
+      </div>
+
+      {visit.notes.map((n) => (
+        <div
+          key={n.id}
+          className="text-text_color/80 flex items-start justify-between gap-3
+            text-sm"
+        >
+          <p className="whitespace-pre-wrap">{n.note}</p>
+          <button
+            className="hover:bg-hvr_gray shrink-0 cursor-pointer rounded-lg p-1"
+            onClick={() =>
+              send(`${visitUrl}/notes/${n.id}`, { method: "DELETE" })
+            }
+          >
+            <Icon icon={Delete02Icon} styles="size-4 text-red-600" />
+          </button>
+        </div>
+      ))}
+
+      {visit.attachments.length > 0 && (
+        <div className="flex flex-wrap gap-2">
+          {visit.attachments.map((a) => (
+            <div
+              key={a.id}
+              className="border-border_color flex items-center gap-2 rounded-lg
+                border px-2 py-1 text-sm"
+            >
+              <Icon icon={Attachment01Icon} styles="size-4" />
+              <a
+                className="text-primary hover:underline"
+                href={`${visitUrl}/attachments/${a.id}`}
+                target="_blank"
+                rel="noreferrer"
+              >
+                {a.file_name}
+              </a>
+              <span className="text-text_color/60 text-xs">
+                {formatFileSize(a.size)}
+              </span>
+              <button
+                className="cursor-pointer"
+                onClick={() =>
+                  send(`${visitUrl}/attachments/${a.id}`, { method: "DELETE" })
+                }
+              >
+                <Icon icon={Delete02Icon} styles="size-4 text-red-600" />
+              </button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      <form className="flex flex-col gap-2" onSubmit={addNote}>
+        <Textarea
+          id={`visit-note-${visit.id}`}
+          name="note"
+          styles="p-2 text-sm min-h-16"
+          placeholder="Treatment details, formulas used..."
+          maxLength={5000}
+          value={note}
+          inputData={(data) => setNote(data.value)}
+        />
+        <div className="flex justify-end gap-2">
+          <input
+            ref={fileInputRef}
+            type="file"
+            className="hidden"
+            accept="image/jpeg,image/png,image/webp,image/gif,application/pdf"
+            onChange={uploadAttachment}
+          />
+          <Button
+            type="button"
+            variant="tertiary"
+            styles="px-3 py-1 text-sm"
+            buttonText="Attach file"
+            disabled={isLoading}
+            onClick={() => fileInputRef.current.click()}
+          />
+          <Button
+            type="submit"
+            styles="px-3 py-1 text-sm"
+            buttonText="Add note"
+            isLoading={isLoading}
+          />
+        </div>
+      </form>
+    </div>
+  );
+}
@@ -37,6 +37,7 @@ import BookingItem from "./-components/BookingItem";
 import CustomerStats from "./-components/CustomerStats";
 import ExpandableNote from "./-components/ExpandableNote";
 import PaginatedList from "./-components/PaginatedList";
+import VisitItem from "./-components/VisitItem";
 
 async function fetchCustomerInfo(merchantId, customerId) {
   const response = await fetch(
@@ -59,6 +60,34 @@ async function fetchCustomerInfo(merchantId, customerId) {
   }
 }
 
+async function fetchCustomerVisits(merchantId, customerId) {
+  const response = await fetch(
+    `/api/v1/merchants/${merchantId}/visits?customer_id=${customerId}`,
+    {
+      method: "GET",
+      headers: {
+        Accept: "application/json",
+        "content-type": "application/json",
+      },
+    }
+  );
+
+  const result = await response.json();
+  if (!response.ok) {
+    invalidateLocalStorageAuth(response.status);
+    throw result.error;
+  } else {
+    return result.data;
+  }
+}
+
+function customerVisitsQueryOptions(merchantId, id) {
+  return queryOptions({
+    queryKey: [merchantId, "customer-visits", id],
+    queryFn: () => fetchCustomerVisits(merchantId, id),
+  });
+}
+
 function customerInfoQueryOptions(merchantId, id) {
   return queryOptions({
     queryKey: [merchantId, "customer-info", id],
@@ -97,6 +126,9 @@ export const Route = createFileRoute(
       customerInfoQueryOptions(merchantId, params.customerId)
     );
     await queryClient.ensureQueryData(customersQueryOptions(merchantId));
+    await queryClient.ensureQueryData(
+      customerVisitsQueryOptions(merchantId, params.customerId)
+    );
   },
   errorComponent: ({ error }) => {
     return <ServerError error={error.mesage} />;
@@ -120,6 +152,7 @@ function CustomerDetailsPage() {
     queries: [
       customerInfoQueryOptions(merchantId, customerId),
       customersQueryOptions(merchantId),
+      customerVisitsQueryOptions(merchantId, customerId),
     ],
   });
 
@@ -483,6 +516,24 @@ function CustomerDetailsPage() {
             />
           )}
         />
+
+        <PaginatedList
+          data={queryResults[2].data}
+          itemsPerPage={5}
+          title="Visit Notes"
+          emptyMessage="No visits found for this customer"
+          renderItem={(visit) => (
+            <VisitItem
+              visit={visit}
+              onError={setServerError}
+              onChange={() =>
+                queryClient.invalidateQueries({
+                  queryKey: [merchantId, "customer-visits", customerId],
+                })
+              }
+            />
+          )}
+        />
       </div>
     </div>
   );
@@ -16,6 +16,7 @@ import BookingsList from "./-components/BookingsList";
 import LowStockProductsAlert from "./-components/LowStockProductsAlert";
 import RevenueChart from "./-components/RevenueChart";
 import StatisticsCard from "./-components/StatisticsCard";
+import UtilizationCard from "./-components/UtilizationCard";
 
 async function fetchDashboardData(merchantId, period) {
   const date = new Date().toJSON();
@@ -159,6 +160,7 @@ function DashboardPage() {
               products={data.low_stock_products}
               route={Route}
             />
+            <UtilizationCard utilization={data.utilization} />
           </div>
         </div>
         <div className="flex h-full flex-1 flex-col gap-4 lg:max-w-1/2">
@@ -0,0 +1,232 @@
+import { CheckBox, Input, Textarea } from "@reservations/components";
+import { invalidateLocalStorageAuth } from "@reservations/lib";
+import { queryOptions, useQuery } from "@tanstack/react-query";
+import { useEffect } from "react";
+
+async function fetchIntakeForm(merchantName, serviceId) {
+  const response = await fetch(
+    `/api/v1/public/bookings/intake-form?merchant_name=${merchantName}&service_id=${serviceId}`,
+    {
+      method: "GET",
+      headers: {
+        Accept: "application/json",
+        "content-type": "application/json",
+      },
+    }
+  );
+
+  // the form is loaded again after logging in before booking
+  if (response.status === 401) {
+    return { form: null };
+  }
+
+  const result = await response.json();
+  if (!response.ok) {
+    invalidateLocalStorageAuth(response.status);
+    throw result.error;
+  } else {
+    return result.data;
+  }
+}
+
+function intakeFormQueryOptions(merchantName, serviceId) {
+  return queryOptions({
+    queryKey: ["intake-form", merchantName, serviceId],
+    queryFn: () => fetchIntakeForm(merchantName, serviceId),
+  });
+}
+
+// mirrors the backend, yes/no answers are compared as "yes" and "no"
+function isVisible(question, answers) {
+  if (!question.visible_if) return true;
+
+  const answer = answers[question.visible_if.question_id];
+  if (answer === undefined || answer === null) return false;
+
+  let values;
+  if (typeof answer === "boolean") {
+    values = [answer ? "yes" : "no"];
+  } else if (Array.isArray(answer)) {
+    values = answer;
+  } else {
+    values = [answer];
+  }
+
+  return values.some((v) => question.visible_if.values.includes(v));
+}
+
+function QuestionInput({ question, value, onChange }) {
+  const id = `intake-${question.id}`;
+
+  switch (question.type) {
+    case "text":
+    case "number":
+    case "date":
+      return (
+        <Input
+          id={id}
+          name={question.id}
+          type={question.type}
+          styles="rounded-lg"
+          labelText={question.label}
+          required={question.is_required}
+          value={value ?? ""}
+          inputData={(data) =>
+            onChange(
+              question.type === "number" && data.value !== ""
+                ? Number(data.value)
+                : data.value
+            )
+          }
+        />
+      );
+
+    case "long_text":
+      return (
+        <Textarea
+          id={id}
+          name={question.id}
+          styles="p-2 min-h-24"
+          labelText={question.label}
+          required={question.is_required}
+          value={value ?? ""}
+          inputData={(data) => onChange(data.value)}
+        />
+      );
+
+    case "yes_no":
+      return (
+        <fieldset className="flex flex-col gap-2">
+          <legend className="pb-1 text-sm">{question.label}</legend>
+          <div className="flex gap-6">
+            {[
+              ["Yes", true],
+              ["No", false],
+            ].map(([label, option]) => (
+              <label key={label} className="flex items-center gap-2">
+                <input
+                  type="radio"
+                  name={id}
+                  checked={value === option}
+                  onChange={() => onChange(option)}
+                />
+                {label}
+              </label>
+            ))}
+          </div>
+        </fieldset>
+      );
+
+    case "single_choice":
+      return (
+        <fieldset className="flex flex-col gap-2">
+          <legend className="pb-1 text-sm">{question.label}</legend>
+          {question.options.map((option) => (
+            <label key={option} className="flex items-center gap-2">
+              <input
+                type="radio"
+                name={id}
+                checked={value === option}
+                onChange={() => onChange(option)}
+              />
+              {option}
+            </label>
+          ))}
+        </fieldset>
+      );
+
+    case "multiple_choice":
+      return (
+        <fieldset className="flex flex-col gap-2">
+          <legend className="pb-1 text-sm">{question.label}</legend>
+          {question.options.map((option) => {
+            const selected = value ?? [];
+
+            return (
+              <label key={option} className="flex items-center gap-2">
+                <CheckBox
+                  checked={selected.includes(option)}
+                  onChange={(e) =>
+                    onChange(
+                      e.target.checked
+                        ? [...selected, option]
+                        : selected.filter((o) => o !== option)
+                    )
+                  }
+                />
+                {option}
+              </label>
+            );
+          })}
+        </fieldset>
+      );
+
+    case "consent":
+      return (
+        <label className="flex items-start gap-3 text-sm">
+          <CheckBox
+            checked={value === true}
+            onChange={(e) => onChange(e.target.checked)}
+          />
+          <span className="whitespace-pre-wrap">{question.label}</span>
+        </label>
+      );
+
+    default:
+      return null;
+  }
+}
+
+export default function IntakeFormStep({
+  merchantName,
+  serviceId,
+  value,
+  onChange,
+}) {
+  const { data } = useQuery(intakeFormQueryOptions(merchantName, serviceId));
+  const form = data?.form;
+
+  useEffect(() => {
+    onChange({ form: form ?? null, answers: {}, signature: "" });
+    // the answers only have to be reset when the form changes
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [form?.id]);
+
+  if (!form) return null;
+
+  const answers = value.answers;
+  const needsSignature = form.questions.some(
+    (q) => q.type === "consent" && answers[q.id] === true
+  );
+
+  function setAnswer(questionId, answer) {
+    onChange({ ...value, answers: { ...answers, [questionId]: answer } });
+  }
+
+  return (
+    <div className="flex flex-col gap-6">
+      <h2 className="text-xl font-semibold">{form.name}</h2>
+      {form.questions
+        .filter((q) => isVisible(q, answers))
+        .map((question) => (
+          <QuestionInput
+            key={question.id}
+            question={question}
+            value={answers[question.id]}
+            onChange={(answer) => setAnswer(question.id, answer)}
+          />
+        ))}
+      {needsSignature && (
+        <Input
+          id="intake-signature"
+          name="signature"
+          type="text"
+          styles="rounded-lg"
+          labelText="Type your full name to sign"
+          value={value.signature}
+          inputData={(data) => onChange({ ...value, signature: data.value })}
+        />
+      )}
+    </div>
+  );
+}
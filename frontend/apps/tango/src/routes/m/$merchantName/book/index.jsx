@@ -14,6 +14,7 @@ import { useEffect, useState } from "react";
 import AppointmentTimeSelectionStep from "./-components/AppointmentTimeSelectionStep";
 import BookingSummary from "./-components/BookingSummary";
 import EmployeeSelectionStep from "./-components/EmployeeSelectionStep";
+import IntakeFormStep from "./-components/IntakeFormStep";
 import ServiceSelectionStep from "./-components/ServiceSelectionStep";
 
 function validateSearch(search) {
@@ -95,6 +96,11 @@ function BookingFLow() {
     employee: null,
     time: null,
   });
+  const [intake, setIntake] = useState({
+    form: null,
+    answers: {},
+    signature: "",
+  });
   const [isSubmitting, setIsSubmitting] = useState(false);
   const { isWindowSmall } = useWindowSize();
 
@@ -210,6 +216,8 @@ function BookingFLow() {
           location_id: search.locationId,
           timeStamp: timeStamp,
           customer_note: selectedSummary.time.customer_note,
+          intake_answers: intake.form ? intake.answers : undefined,
+          intake_signature: intake.form ? intake.signature : undefined,
         }),
       });
 
@@ -331,6 +339,17 @@ function BookingFLow() {
               />
             )}
 
+            {currentStep === "time" && (
+              <div className="pt-10">
+                <IntakeFormStep
+                  merchantName={merchantName}
+                  serviceId={search.serviceId}
+                  value={intake}
+                  onChange={setIntake}
+                />
+              </div>
+            )}
+
             {currentStep === "time" && search.type === "class" && (
               <div className="">
                 <h1 className="text-3xl font-bold">Select a Class</h1>
@@ -0,0 +1,102 @@
+import { Button } from "@reservations/components";
+import { useToast } from "@reservations/lib";
+import { createFileRoute } from "@tanstack/react-router";
+import { useEffect, useState } from "react";
+
+export const Route = createFileRoute("/unsubscribe")({
+  component: RouteComponent,
+});
+
+function RouteComponent() {
+  const { token } = Route.useSearch();
+  const [merchantName, setMerchantName] = useState("");
+  const [isInvalid, setIsInvalid] = useState(false);
+  const [isUnsubscribed, setIsUnsubscribed] = useState(false);
+  const { showToast } = useToast();
+
+  useEffect(() => {
+    if (!token) {
+      return;
+    }
+
+    async function fetchTarget() {
+      const response = await fetch(
+        `/api/v1/public/unsubscribe/${encodeURIComponent(token)}`,
+        {
+          method: "GET",
+          headers: {
+            Accept: "application/json",
+          },
+        }
+      );
+
+      if (!response.ok) {
+        setIsInvalid(true);
+      } else {
+        const result = await response.json();
+        setMerchantName(result.data.merchant_name);
+      }
+    }
+
+    fetchTarget();
+  }, [token]);
+
+  async function unsubscribeHandler() {
+    const response = await fetch(
+      `/api/v1/public/unsubscribe/${encodeURIComponent(token)}`,
+      {
+        method: "POST",
+        headers: {
+          Accept: "application/json",
+        },
+      }
+    );
+
+    if (!response.ok) {
+      const result = await response.json();
+      showToast({ message: result.error.message, variant: "error" });
+    } else {
+      setIsUnsubscribed(true);
+    }
+  }
+
+  if (!token || isInvalid) {
+    return (
+      <div className="flex h-screen items-center justify-center px-4">
+        <div className="flex w-full max-w-xl flex-col">
+          <div className="pt-4 pb-12">
+            <p className="text-2xl">Invalid unsubscribe link</p>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className="flex h-screen items-center justify-center px-4">
+      <div className="flex w-full max-w-xl flex-col">
+        <div className="pt-4 pb-12">
+          <p className="text-2xl">Unsubscribe</p>
+          {isUnsubscribed ? (
+            <p className="text-text_color/60">
+              You will not receive marketing emails from {merchantName}{" "}
+              anymore
+            </p>
+          ) : (
+            <p className="text-text_color/60">
+              Stop receiving marketing emails from {merchantName}
+            </p>
+          )}
+        </div>
+        {!isUnsubscribed && (
+          <Button
+            styles="p-2"
+            buttonText="Unsubscribe"
+            disabled={!merchantName}
+            onClick={unsubscribeHandler}
+          />
+        )}
+      </div>
+    </div>
+  );
+}
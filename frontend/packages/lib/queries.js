@@ -67,7 +67,7 @@ async function fetchCustomers(merchantId) {
     invalidateLocalStorageAuth(response.status);
     throw result.error;
   } else {
-    return result.data;
+    return result.data.customers;
   }
 }
 